@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"webtracker-bot/internal/archive"
+	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/database"
+	"webtracker-bot/internal/database/db"
+)
+
+const usage = `Usage:
+  archive list [company-id]              List archive files (optionally for one company)
+  archive restore <key> [tracking-id...] Restore shipments from an archive file
+
+Keys look like <company-id>/2026-01.jsonl.gz, as printed by "archive list".
+Shipments that already exist in the database are skipped.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	if cfg.ArchiveDir == "" {
+		log.Fatal("ARCHIVE_DIR is not set in environment or .env file")
+	}
+	store := archive.NewLocalStorage(cfg.ArchiveDir)
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "list":
+		prefix := ""
+		if len(os.Args) > 2 {
+			prefix = os.Args[2]
+		}
+		list(ctx, store, prefix)
+	case "restore":
+		if len(os.Args) < 3 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		restore(ctx, cfg, store, os.Args[2], os.Args[3:])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func list(ctx context.Context, store archive.Storage, prefix string) {
+	objects, err := store.List(ctx, prefix)
+	if err != nil {
+		log.Fatalf("Failed to list archives: %v", err)
+	}
+	if len(objects) == 0 {
+		fmt.Println("No archives found.")
+		return
+	}
+	for _, o := range objects {
+		fmt.Printf("%-60s %10.1f KB  %s\n", o.Key, float64(o.Size)/1024, o.ModTime.Format("2006-01-02 15:04"))
+	}
+}
+
+func restore(ctx context.Context, cfg *config.Config, store archive.Storage, key string, ids []string) {
+	if cfg.DatabaseURL == "" {
+		log.Fatal("DATABASE_URL is not set in environment or .env file")
+	}
+	sqlDB, err := database.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Unable to connect to database: %v\n", err)
+	}
+	defer sqlDB.Close()
+	queries := db.New(sqlDB)
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[strings.ToUpper(id)] = true
+	}
+
+	var restored, skipped int
+	err = archive.Read(ctx, store, key, func(r archive.Record) error {
+		if len(wanted) > 0 && !wanted[r.Shipment.TrackingID] {
+			return nil
+		}
+		tx, err := sqlDB.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := archive.RestoreRecord(ctx, queries.WithTx(tx), r); err != nil {
+			tx.Rollback()
+			if errors.Is(err, archive.ErrExists) {
+				skipped++
+				fmt.Printf("Skipping %s: already in database\n", r.Shipment.TrackingID)
+				return nil
+			}
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		restored++
+		fmt.Printf("Restored %s\n", r.Shipment.TrackingID)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Restore failed after %d shipments: %v", restored, err)
+	}
+	fmt.Printf("Done: %d restored, %d skipped.\n", restored, skipped)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/shipment"
+)
+
+// DeliveryPolicyHandler manages how often a failed delivery is retried
+type DeliveryPolicyHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewDeliveryPolicyHandler injects the Usecase
+func NewDeliveryPolicyHandler(shipmentUC *shipment.Usecase) *DeliveryPolicyHandler {
+	return &DeliveryPolicyHandler{shipmentUC: shipmentUC}
+}
+
+func (h *DeliveryPolicyHandler) RegisterRoutes(router fiber.Router) {
+	policy := router.Group("/api/admin/delivery_policy")
+	policy.Get("/", h.Get)
+	policy.Put("/", h.Update)
+}
+
+// Get - GET /api/admin/delivery_policy
+func (h *DeliveryPolicyHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	policy, err := h.shipmentUC.AttemptPolicy(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Get delivery policy error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load delivery policy"})
+	}
+	return c.JSON(fiber.Map{"policy": policy, "reasons": shipment.AttemptReasons})
+}
+
+// Update - PUT /api/admin/delivery_policy
+func (h *DeliveryPolicyHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var policy shipment.AttemptPolicy
+	if err := c.BodyParser(&policy); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	if err := h.shipmentUC.SetAttemptPolicy(c.Context(), companyID, policy); err != nil {
+		if errors.Is(err, shipment.ErrInvalidAttemptPolicy) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Update delivery policy error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save delivery policy"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_delivery_policy_update", nil)
+	return c.JSON(fiber.Map{"policy": policy, "reasons": shipment.AttemptReasons})
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/shipment"
+)
+
+// CodHandler manages cash-on-delivery amounts and the remittance ledger
+type CodHandler struct {
+	shipmentUC *shipment.Usecase
+	validate   *validator.Validate
+}
+
+// NewCodHandler injects the Usecase
+func NewCodHandler(shipmentUC *shipment.Usecase) *CodHandler {
+	return &CodHandler{shipmentUC: shipmentUC, validate: validator.New()}
+}
+
+func (h *CodHandler) RegisterRoutes(router fiber.Router) {
+	cod := router.Group("/api/admin/cod")
+	cod.Get("/summary", h.Summary)
+	cod.Get("/ledger", h.Ledger)
+	cod.Get("/uncollected", h.Uncollected)
+	cod.Post("/collections", h.Collect)
+	cod.Post("/remittances", h.Remit)
+	cod.Post("/adjustments", h.Adjust)
+
+	router.Put("/api/admin/shipments/:id/cod", h.SetAmount)
+}
+
+// SetCODRequest changes the amount to collect on a shipment
+type SetCODRequest struct {
+	Amount   float64 `json:"amount" validate:"gte=0"`
+	Currency string  `json:"currency" validate:"omitempty,len=3"`
+}
+
+// CollectRequest marks a shipment's COD as collected; Amount 0 collects the full amount
+type CollectRequest struct {
+	TrackingID string  `json:"trackingId" validate:"required"`
+	Amount     float64 `json:"amount" validate:"gte=0"`
+	Reference  string  `json:"reference"`
+}
+
+// LedgerEntryRequest books a remittance or adjustment
+type LedgerEntryRequest struct {
+	Amount    float64 `json:"amount" validate:"required"`
+	Currency  string  `json:"currency" validate:"omitempty,len=3"`
+	Reference string  `json:"reference"`
+	Note      string  `json:"note"`
+}
+
+func (h *CodHandler) validationError(c *fiber.Ctx, err error) error {
+	var errs []string
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		for _, e := range validationErrors {
+			errs = append(errs, fmt.Sprintf("'%s' is %s", e.Field(), e.Tag()))
+		}
+	} else {
+		errs = append(errs, err.Error())
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Validation failed: " + strings.Join(errs, ", ")})
+}
+
+// Summary - GET /api/admin/cod/summary
+func (h *CodHandler) Summary(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	summary, err := h.shipmentUC.CodSummary(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("COD summary error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reconcile COD"})
+	}
+	return c.JSON(summary)
+}
+
+// Ledger - GET /api/admin/cod/ledger?limit=50&offset=0
+func (h *CodHandler) Ledger(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := h.shipmentUC.CodLedger(c.Context(), companyID, int32(limit), int32(offset))
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("COD ledger error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load COD ledger"})
+	}
+	return c.JSON(fiber.Map{"entries": entries, "limit": limit, "offset": offset})
+}
+
+// Uncollected - GET /api/admin/cod/uncollected?limit=100
+// Delivered shipments whose cash has not been booked yet.
+func (h *CodHandler) Uncollected(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "100"))
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	rows, err := h.shipmentUC.UncollectedCOD(c.Context(), companyID, int32(limit))
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Uncollected COD error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list uncollected COD"})
+	}
+
+	shipments := make([]fiber.Map, 0, len(rows))
+	for _, r := range rows {
+		shipments = append(shipments, fiber.Map{
+			"trackingId":    r.TrackingID,
+			"status":        r.Status.String,
+			"recipientName": r.RecipientName.String,
+			"destination":   r.Destination.String,
+			"amount":        r.CodAmount,
+			"currency":      r.CodCurrency.String,
+			"createdAt":     r.CreatedAt.Time,
+		})
+	}
+	return c.JSON(fiber.Map{"shipments": shipments})
+}
+
+// Collect - POST /api/admin/cod/collections
+func (h *CodHandler) Collect(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req CollectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return h.validationError(c, err)
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, req.TrackingID)
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	entry, err := h.shipmentUC.CollectCOD(sourceContext(c), companyID, id, req.Amount, req.Reference)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+		case errors.Is(err, shipment.ErrNoCOD), errors.Is(err, shipment.ErrInvalidCOD):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, shipment.ErrCODCollected):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("id", req.TrackingID).Msg("COD collection error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to record collection"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_cod_collect", nil)
+	return c.Status(fiber.StatusCreated).JSON(entry)
+}
+
+// Remit - POST /api/admin/cod/remittances
+func (h *CodHandler) Remit(c *fiber.Ctx) error {
+	return h.book(c, shipment.CodRemittance)
+}
+
+// Adjust - POST /api/admin/cod/adjustments
+func (h *CodHandler) Adjust(c *fiber.Ctx) error {
+	return h.book(c, shipment.CodAdjustment)
+}
+
+func (h *CodHandler) book(c *fiber.Ctx, entryType string) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req LedgerEntryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if err := h.validate.Struct(req); err != nil {
+		return h.validationError(c, err)
+	}
+
+	ctx := sourceContext(c)
+	record := h.shipmentUC.RecordRemittance
+	if entryType == shipment.CodAdjustment {
+		record = h.shipmentUC.RecordAdjustment
+	}
+	entry, err := record(ctx, companyID, req.Amount, req.Currency, req.Reference, req.Note)
+	if err != nil {
+		if errors.Is(err, shipment.ErrInvalidCOD) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Str("type", entryType).Msg("COD ledger entry error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to record " + entryType})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_cod_"+entryType, nil)
+	return c.Status(fiber.StatusCreated).JSON(entry)
+}
+
+// SetAmount - PUT /api/admin/shipments/:id/cod
+func (h *CodHandler) SetAmount(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	var req SetCODRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	if err := h.validate.Struct(req); err != nil {
+		return h.validationError(c, err)
+	}
+
+	if _, err := h.shipmentUC.Track(c.Context(), companyID, id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	if err := h.shipmentUC.SetCOD(c.Context(), companyID, id, req.Amount, req.Currency); err != nil {
+		switch {
+		case errors.Is(err, shipment.ErrInvalidCOD):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, shipment.ErrCODCollected):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, shipment.ErrVersionConflict):
+			return versionConflict(c, 0)
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Set COD error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update COD"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_cod_update", nil)
+
+	// Re-read so the response carries the defaulted currency
+	ship, err := h.shipmentUC.Track(c.Context(), companyID, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	return c.JSON(fiber.Map{"tracking_id": id, "amount": ship.CodAmount, "currency": ship.CodCurrency.String})
+}
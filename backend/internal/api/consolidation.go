@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/notif"
+	"webtracker-bot/internal/shipment"
+)
+
+// ConsolidationHandler manages bags (master AWBs) of shipments
+type ConsolidationHandler struct {
+	shipmentUC *shipment.Usecase
+	configUC   *config.Usecase
+	cfg        *config.Config
+	bots       models.BotProvider
+}
+
+// NewConsolidationHandler injects the Usecase
+func NewConsolidationHandler(shipmentUC *shipment.Usecase, configUC *config.Usecase, cfg *config.Config, bots models.BotProvider) *ConsolidationHandler {
+	return &ConsolidationHandler{shipmentUC: shipmentUC, configUC: configUC, cfg: cfg, bots: bots}
+}
+
+func (h *ConsolidationHandler) RegisterRoutes(router fiber.Router) {
+	bags := router.Group("/api/admin/bags")
+	bags.Get("/", h.List)
+	bags.Post("/", h.Create)
+	bags.Get("/:awb", h.Get)
+	bags.Post("/:awb/shipments", h.Add)
+	bags.Delete("/:awb/shipments/:id", h.Remove)
+	bags.Post("/:awb/close", h.Close)
+	bags.Post("/:awb/depart", h.Depart)
+	bags.Patch("/:awb/status", h.SetStatus)
+}
+
+// List - GET /api/admin/bags?limit=50
+func (h *ConsolidationHandler) List(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	bags, err := h.shipmentUC.ListConsolidations(c.Context(), companyID, int32(c.QueryInt("limit", shipment.DefaultBagListLimit)))
+	if err != nil {
+		logger.Error().Err(err).Msg("List consolidations error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list bags"})
+	}
+	return c.JSON(fiber.Map{"bags": bags})
+}
+
+// CreateBagRequest is the optional label of a new bag, e.g. the flight
+type CreateBagRequest struct {
+	Label string `json:"label"`
+}
+
+// Create - POST /api/admin/bags
+func (h *ConsolidationHandler) Create(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req CreateBagRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+		}
+	}
+	company, err := h.configUC.GetCompanyByID(c.Context(), companyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to look up company"})
+	}
+
+	bag, err := h.shipmentUC.CreateConsolidation(sourceContext(c), companyID, company.TrackingPrefix.String, req.Label)
+	if err != nil {
+		return bagError(c, "", err)
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_bag_create", nil)
+	return c.Status(fiber.StatusCreated).JSON(bag)
+}
+
+// Get - GET /api/admin/bags/:awb
+func (h *ConsolidationHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	bag, err := h.shipmentUC.Consolidation(c.Context(), companyID, c.Params("awb"))
+	if err != nil {
+		return bagError(c, c.Params("awb"), err)
+	}
+	return c.JSON(bag)
+}
+
+// AddToBagRequest lists the shipments to put in a bag
+type AddToBagRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// Add - POST /api/admin/bags/:awb/shipments
+// All shipments are added, or none when one can't be.
+func (h *ConsolidationHandler) Add(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req AddToBagRequest
+	if err := c.BodyParser(&req); err != nil || len(req.IDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+	ids := make([]string, 0, len(req.IDs))
+	for _, raw := range req.IDs {
+		id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, raw)
+		if err != nil {
+			return trackingIDError(c, err)
+		}
+		ids = append(ids, id)
+	}
+
+	bag, err := h.shipmentUC.AddToConsolidation(sourceContext(c), companyID, c.Params("awb"), ids)
+	if err != nil {
+		return bagError(c, c.Params("awb"), err)
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_bag_add", []byte(fmt.Sprintf(`{"count": %d}`, len(ids))))
+	return c.JSON(bag)
+}
+
+// Remove - DELETE /api/admin/bags/:awb/shipments/:id
+func (h *ConsolidationHandler) Remove(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	if err := h.shipmentUC.RemoveFromConsolidation(sourceContext(c), companyID, c.Params("awb"), id); err != nil {
+		return bagError(c, c.Params("awb"), err)
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_bag_remove", nil)
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// Close - POST /api/admin/bags/:awb/close
+func (h *ConsolidationHandler) Close(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	bag, err := h.shipmentUC.CloseConsolidation(sourceContext(c), companyID, c.Params("awb"))
+	if err != nil {
+		return bagError(c, c.Params("awb"), err)
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_bag_close", nil)
+	return c.JSON(bag)
+}
+
+// Depart - POST /api/admin/bags/:awb/depart
+// Sends every shipment in a closed bag in transit.
+func (h *ConsolidationHandler) Depart(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	res, err := h.shipmentUC.DepartConsolidation(sourceContext(c), companyID, c.Params("awb"))
+	if err != nil {
+		return bagError(c, c.Params("awb"), err)
+	}
+	return h.moved(c, companyID, "admin_bag_depart", res)
+}
+
+// BagStatusRequest is the status to apply to everything in a bag
+type BagStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// SetStatus - PATCH /api/admin/bags/:awb/status
+// Moves every shipment in a departed bag; shipments that can't make the move
+// are listed under "skipped".
+func (h *ConsolidationHandler) SetStatus(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req BagStatusRequest
+	if err := c.BodyParser(&req); err != nil || req.Status == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	res, err := h.shipmentUC.SetConsolidationStatus(sourceContext(c), companyID, c.Params("awb"), req.Status)
+	if err != nil {
+		return bagError(c, c.Params("awb"), err)
+	}
+	return h.moved(c, companyID, "admin_bag_status", res)
+}
+
+// moved alerts the customer of every shipment a status change on a bag
+// moved, in the background like BulkUpdateStatus.
+func (h *ConsolidationHandler) moved(c *fiber.Ctx, companyID uuid.UUID, event string, res *models.ConsolidationResult) error {
+	h.shipmentUC.RecordEvent(c.Context(), companyID, event, []byte(fmt.Sprintf(`{"count": %d, "status": %q}`, len(res.Moved), res.Consolidation.Status)))
+
+	if h.bots != nil && len(res.Moved) > 0 {
+		if bot, err := h.bots.GetBot(companyID); err == nil {
+			moved := res.Moved
+			cfg := h.cfg
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+				defer cancel()
+				for _, ship := range moved {
+					notif.SendStatusAlert(ctx, bot.GetWAClient(), cfg, bot.GetCompanyName(), ship.UserJid, ship.TrackingID, ship.Status.String, ship.RecipientEmail.String, shipment.FormatCOD(ship.CodAmount, ship.CodCurrency.String))
+				}
+			}()
+		}
+	}
+
+	moved := make([]string, 0, len(res.Moved))
+	for _, ship := range res.Moved {
+		moved = append(moved, ship.TrackingID)
+	}
+	return c.JSON(fiber.Map{"bag": res.Consolidation, "moved": moved, "skipped": res.Skipped})
+}
+
+func bagError(c *fiber.Ctx, awb string, err error) error {
+	var te *shipment.TransitionError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Not found"})
+	case errors.Is(err, shipment.ErrNotConsolidated):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment is not in this bag"})
+	case errors.Is(err, shipment.ErrInvalidConsolidation), errors.Is(err, shipment.ErrAlreadyConsolidated):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, shipment.ErrVersionConflict):
+		return versionConflict(c, 0)
+	case errors.As(err, &te):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": te.Error(), "from": te.From, "to": te.To, "allowed": te.Allowed()})
+	}
+	logger.Error().Err(err).Str("master_awb", awb).Msg("Consolidation error")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update bag"})
+}
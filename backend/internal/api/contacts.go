@@ -0,0 +1,178 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+)
+
+// autocompleteLimit caps suggestions; a dropdown doesn't need more.
+const autocompleteLimit = 10
+
+// ContactHandler manages the per-company address book
+type ContactHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewContactHandler injects the Usecase
+func NewContactHandler(shipmentUC *shipment.Usecase) *ContactHandler {
+	return &ContactHandler{shipmentUC: shipmentUC}
+}
+
+func (h *ContactHandler) RegisterRoutes(router fiber.Router) {
+	contacts := router.Group("/api/admin/contacts")
+	contacts.Get("/", h.List)
+	contacts.Get("/autocomplete", h.Autocomplete)
+	contacts.Post("/", h.Create)
+	contacts.Get("/:id", h.Get)
+	contacts.Put("/:id", h.Update)
+	contacts.Delete("/:id", h.Delete)
+}
+
+func contactError(c *fiber.Ctx, companyID uuid.UUID, err error, action string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Contact not found"})
+	case errors.Is(err, shipment.ErrInvalidContact):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, shipment.ErrContactExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Another contact already uses this alias or phone number"})
+	}
+	logger.Error().Err(err).Str("company_id", companyID.String()).Msg(action + " contact error")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to " + action + " contact"})
+}
+
+// List - GET /api/admin/contacts?q=&limit=&offset=
+func (h *ContactHandler) List(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	contacts, err := h.shipmentUC.ListContacts(c.Context(), companyID, c.Query("q"), int32(limit), int32(offset))
+	if err != nil {
+		return contactError(c, companyID, err, "list")
+	}
+	return c.JSON(fiber.Map{"contacts": contacts, "limit": limit, "offset": offset})
+}
+
+// Autocomplete - GET /api/admin/contacts/autocomplete?q= suggests contacts
+// for a partially typed name, alias or phone number
+func (h *ContactHandler) Autocomplete(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	q := c.Query("q")
+	if q == "" {
+		return c.JSON(fiber.Map{"contacts": []models.Contact{}})
+	}
+	contacts, err := h.shipmentUC.ListContacts(c.Context(), companyID, q, autocompleteLimit, 0)
+	if err != nil {
+		return contactError(c, companyID, err, "search")
+	}
+	return c.JSON(fiber.Map{"contacts": contacts})
+}
+
+// Get - GET /api/admin/contacts/:id
+func (h *ContactHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid contact id"})
+	}
+
+	contact, err := h.shipmentUC.GetContact(c.Context(), companyID, int32(id))
+	if err != nil {
+		return contactError(c, companyID, err, "load")
+	}
+	return c.JSON(contact)
+}
+
+// Create - POST /api/admin/contacts
+func (h *ContactHandler) Create(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req models.Contact
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	contact, err := h.shipmentUC.CreateContact(c.Context(), companyID, req)
+	if err != nil {
+		return contactError(c, companyID, err, "create")
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_contact_create", nil)
+	return c.Status(fiber.StatusCreated).JSON(contact)
+}
+
+// Update - PUT /api/admin/contacts/:id
+func (h *ContactHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid contact id"})
+	}
+
+	var req models.Contact
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	contact, err := h.shipmentUC.UpdateContact(c.Context(), companyID, int32(id), req)
+	if err != nil {
+		return contactError(c, companyID, err, "update")
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_contact_update", nil)
+	return c.JSON(contact)
+}
+
+// Delete - DELETE /api/admin/contacts/:id
+func (h *ContactHandler) Delete(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid contact id"})
+	}
+
+	if err := h.shipmentUC.DeleteContact(c.Context(), companyID, int32(id)); err != nil {
+		return contactError(c, companyID, err, "delete")
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_contact_delete", nil)
+	return c.JSON(fiber.Map{"success": true})
+}
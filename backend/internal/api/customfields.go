@@ -0,0 +1,92 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+)
+
+// CustomFieldHandler manages the extra fields a company tracks on its shipments
+type CustomFieldHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewCustomFieldHandler injects the Usecase
+func NewCustomFieldHandler(shipmentUC *shipment.Usecase) *CustomFieldHandler {
+	return &CustomFieldHandler{shipmentUC: shipmentUC}
+}
+
+func (h *CustomFieldHandler) RegisterRoutes(router fiber.Router) {
+	fields := router.Group("/api/admin/custom_fields")
+	fields.Get("/", h.List)
+	fields.Put("/:name", h.Save)
+	fields.Delete("/:name", h.Delete)
+}
+
+// List - GET /api/admin/custom_fields
+func (h *CustomFieldHandler) List(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	defs, err := h.shipmentUC.CustomFields(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("List custom fields error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load custom fields"})
+	}
+	return c.JSON(fiber.Map{"fields": defs})
+}
+
+// Save - PUT /api/admin/custom_fields/:name
+// Creates the field or replaces its definition; the name comes from the path.
+func (h *CustomFieldHandler) Save(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var field models.CustomField
+	if err := c.BodyParser(&field); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+	field.Name = c.Params("name")
+
+	saved, err := h.shipmentUC.SaveCustomField(c.Context(), companyID, field)
+	if err != nil {
+		if errors.Is(err, shipment.ErrInvalidCustomField) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Save custom field error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save custom field"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_custom_field_save", nil)
+	return c.JSON(saved)
+}
+
+// Delete - DELETE /api/admin/custom_fields/:name
+// Values already stored on shipments are kept.
+func (h *CustomFieldHandler) Delete(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	if err := h.shipmentUC.DeleteCustomField(c.Context(), companyID, c.Params("name")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Custom field not found"})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Delete custom field error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete custom field"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_custom_field_delete", nil)
+	return c.JSON(fiber.Map{"success": true})
+}
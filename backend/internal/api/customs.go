@@ -0,0 +1,171 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/receipt"
+	"webtracker-bot/internal/shipment"
+)
+
+// CustomsHandler manages customs declarations and commercial invoices
+type CustomsHandler struct {
+	shipmentUC *shipment.Usecase
+	bots       models.BotProvider
+	validate   *validator.Validate
+}
+
+// NewCustomsHandler injects the Usecase
+func NewCustomsHandler(shipmentUC *shipment.Usecase, bots models.BotProvider) *CustomsHandler {
+	return &CustomsHandler{shipmentUC: shipmentUC, bots: bots, validate: validator.New()}
+}
+
+func (h *CustomsHandler) RegisterRoutes(router fiber.Router) {
+	shipments := router.Group("/api/admin/shipments")
+	shipments.Get("/:id/customs", h.Get)
+	shipments.Put("/:id/customs", h.Update)
+	shipments.Delete("/:id/customs", h.Delete)
+	shipments.Get("/:id/invoice", h.Invoice)
+}
+
+// Get - GET /api/admin/shipments/:id/customs
+func (h *CustomsHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	decl, err := h.shipmentUC.Customs(c.Context(), companyID, id)
+	if err != nil {
+		if errors.Is(err, shipment.ErrNoCustoms) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No customs declaration"})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Get customs error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load customs declaration"})
+	}
+	return c.JSON(decl)
+}
+
+// Update - PUT /api/admin/shipments/:id/customs
+func (h *CustomsHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	var decl models.CustomsDeclaration
+	if err := c.BodyParser(&decl); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+	// Normalize first so " usd " passes the currency check
+	shipment.NormalizeCustoms(&decl)
+	if err := h.validate.Struct(decl); err != nil {
+		var errs []string
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			for _, e := range validationErrors {
+				errs = append(errs, fmt.Sprintf("'%s' is %s", e.Namespace(), e.Tag()))
+			}
+		} else {
+			errs = append(errs, err.Error())
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Validation failed: " + strings.Join(errs, ", ")})
+	}
+
+	if _, err := h.shipmentUC.Track(c.Context(), companyID, id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+
+	if err := h.shipmentUC.SetCustoms(c.Context(), companyID, id, &decl); err != nil {
+		if errors.Is(err, shipment.ErrInvalidCustoms) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Update customs error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save customs declaration"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_customs_update", nil)
+	return c.JSON(decl)
+}
+
+// Delete - DELETE /api/admin/shipments/:id/customs
+func (h *CustomsHandler) Delete(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	deleted, err := h.shipmentUC.DeleteCustoms(c.Context(), companyID, id)
+	if err != nil {
+		logger.Error().Err(err).Str("id", id).Msg("Delete customs error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete customs declaration"})
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No customs declaration"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Invoice - GET /api/admin/shipments/:id/invoice
+// Renders the commercial invoice / CN23 as a PDF download.
+func (h *CustomsHandler) Invoice(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	dbShip, err := h.shipmentUC.Track(c.Context(), companyID, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	decl, err := h.shipmentUC.Customs(c.Context(), companyID, id)
+	if err != nil {
+		if errors.Is(err, shipment.ErrNoCustoms) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No customs declaration"})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Get customs error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load customs declaration"})
+	}
+
+	s := shipment.ToDomain(*dbShip)
+	s.Pieces, _ = h.shipmentUC.ListPieces(c.Context(), companyID, id)
+
+	companyName := ""
+	if h.bots != nil {
+		if bot, err := h.bots.GetBot(companyID); err == nil {
+			companyName = bot.GetCompanyName()
+		}
+	}
+
+	pdf, err := receipt.RenderInvoice(s, *decl, companyName)
+	if err != nil {
+		logger.Error().Err(err).Str("id", id).Msg("Render invoice error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to render invoice"})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="invoice-%s.pdf"`, s.TrackingID))
+	return c.Send(pdf)
+}
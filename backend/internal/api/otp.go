@@ -0,0 +1,115 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/notif"
+	"webtracker-bot/internal/shipment"
+)
+
+// DeliveryCodeHandler confirms deliveries with the recipient's code
+type DeliveryCodeHandler struct {
+	shipmentUC *shipment.Usecase
+	cfg        *config.Config
+	bots       models.BotProvider
+}
+
+// NewDeliveryCodeHandler injects the Usecase
+func NewDeliveryCodeHandler(shipmentUC *shipment.Usecase, cfg *config.Config, bots models.BotProvider) *DeliveryCodeHandler {
+	return &DeliveryCodeHandler{shipmentUC: shipmentUC, cfg: cfg, bots: bots}
+}
+
+func (h *DeliveryCodeHandler) RegisterRoutes(router fiber.Router) {
+	shipments := router.Group("/api/admin/shipments")
+	shipments.Post("/:id/deliver", h.Deliver)
+	shipments.Post("/:id/otp", h.Resend)
+}
+
+// DeliverRequest carries the code the recipient was sent, if any
+type DeliverRequest struct {
+	Code string `json:"code"`
+}
+
+// Deliver - POST /api/admin/shipments/:id/deliver
+// Marks the shipment delivered; shipments that were issued a delivery code
+// need it in the body.
+func (h *DeliveryCodeHandler) Deliver(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	var req DeliverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	ship, err := h.shipmentUC.RiderDeliver(sourceContext(c), companyID, nil, id, req.Code)
+	if err != nil {
+		var te *shipment.TransitionError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+		case errors.Is(err, shipment.ErrOTPRequired), errors.Is(err, shipment.ErrWrongOTP):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, shipment.ErrOTPLocked):
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, shipment.ErrVersionConflict):
+			return versionConflict(c, 0)
+		case errors.As(err, &te):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": te.Error(), "from": te.From, "to": te.To, "allowed": te.Allowed()})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Deliver error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to mark delivered"})
+	}
+
+	if h.bots != nil {
+		if bot, err := h.bots.GetBot(companyID); err == nil {
+			notif.SendStatusAlertAsync(bot.GetWAClient(), h.cfg, bot.GetCompanyName(), ship.UserJid, ship.TrackingID, ship.Status.String, ship.RecipientEmail.String, shipment.FormatCOD(ship.CodAmount, ship.CodCurrency.String))
+		}
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_deliver", nil)
+	c.Set(fiber.HeaderETag, etag(ship.Version))
+	return c.JSON(fiber.Map{"success": true, "status": ship.Status.String, "version": ship.Version})
+}
+
+// Resend - POST /api/admin/shipments/:id/otp
+// Sends the recipient a new delivery code, which also unlocks a shipment
+// after too many wrong codes.
+func (h *DeliveryCodeHandler) Resend(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	if err := h.shipmentUC.ResendDeliveryOTP(sourceContext(c), companyID, id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment has no pending delivery code"})
+		case errors.Is(err, shipment.ErrOTPDisabled):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Resend delivery code error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send delivery code"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_otp_resend", nil)
+	return c.JSON(fiber.Map{"success": true})
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/notif"
+	"webtracker-bot/internal/shipment"
+)
+
+// PODHandler serves and accepts proof-of-delivery images
+type PODHandler struct {
+	shipmentUC *shipment.Usecase
+	cfg        *config.Config
+	bots       models.BotProvider
+}
+
+// NewPODHandler injects the Usecase
+func NewPODHandler(shipmentUC *shipment.Usecase, cfg *config.Config, bots models.BotProvider) *PODHandler {
+	return &PODHandler{shipmentUC: shipmentUC, cfg: cfg, bots: bots}
+}
+
+func (h *PODHandler) RegisterRoutes(router fiber.Router) {
+	shipments := router.Group("/api/admin/shipments")
+	shipments.Get("/:id/pod", h.List)
+	shipments.Post("/:id/pod", h.Upload)
+	shipments.Get("/:id/pod/:pod", h.Image)
+}
+
+// List - GET /api/admin/shipments/:id/pod
+func (h *PODHandler) List(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	pods, err := h.shipmentUC.ListPODs(c.Context(), companyID, id)
+	if err != nil {
+		logger.Error().Err(err).Str("id", id).Msg("List proof of delivery error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load proof of delivery"})
+	}
+	return c.JSON(fiber.Map{"tracking_id": id, "pods": pods})
+}
+
+// Image - GET /api/admin/shipments/:id/pod/:pod returns the image itself
+func (h *PODHandler) Image(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	podID, err := c.ParamsInt("pod")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid proof of delivery id"})
+	}
+
+	pod, r, err := h.shipmentUC.OpenPOD(c.Context(), companyID, id, int32(podID))
+	if err != nil {
+		return podError(c, id, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		logger.Error().Err(err).Str("id", id).Msg("Read proof of delivery error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load proof of delivery"})
+	}
+
+	c.Set(fiber.HeaderContentType, pod.ContentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return c.Send(data)
+}
+
+// Upload - POST /api/admin/shipments/:id/pod
+// Multipart "file" field (or the raw image as the body); ?kind=signature for
+// a signature, photo otherwise. Marks the shipment delivered like a POD sent
+// on WhatsApp.
+func (h *PODHandler) Upload(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	data := c.Body()
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read uploaded file"})
+		}
+		defer f.Close()
+		buf := make([]byte, file.Size)
+		if _, err := io.ReadFull(f, buf); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read uploaded file"})
+		}
+		data = buf
+	}
+	kind := c.Query("kind", c.FormValue("kind"))
+
+	res, err := h.shipmentUC.RecordPOD(sourceContext(c), companyID, nil, id, kind, data)
+	if err != nil {
+		return podError(c, id, err)
+	}
+
+	if res.Delivered && h.bots != nil {
+		if bot, err := h.bots.GetBot(companyID); err == nil {
+			notif.SendPODAlertAsync(bot.GetWAClient(), h.cfg, bot.GetCompanyName(), &res.Shipment, &notif.InlineImage{ContentType: res.POD.ContentType, Data: data})
+		}
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_pod_upload", []byte(fmt.Sprintf(`{"kind": %q}`, res.POD.Kind)))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"pod": res.POD, "delivered": res.Delivered, "status": res.Shipment.Status.String})
+}
+
+func podError(c *fiber.Ctx, id string, err error) error {
+	var te *shipment.TransitionError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Not found"})
+	case errors.Is(err, shipment.ErrInvalidPOD):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, shipment.ErrPODDisabled):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, shipment.ErrVersionConflict):
+		return versionConflict(c, 0)
+	case errors.Is(err, shipment.ErrOTPRequired):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	case errors.As(err, &te):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": te.Error(), "from": te.From, "to": te.To, "allowed": te.Allowed()})
+	}
+	logger.Error().Err(err).Str("id", id).Msg("Proof of delivery error")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to process proof of delivery"})
+}
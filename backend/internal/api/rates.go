@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+)
+
+// RateHandler manages the per-company rate card and price quotes
+type RateHandler struct {
+	shipmentUC *shipment.Usecase
+	validate   *validator.Validate
+}
+
+// NewRateHandler injects the Usecase
+func NewRateHandler(shipmentUC *shipment.Usecase) *RateHandler {
+	return &RateHandler{shipmentUC: shipmentUC, validate: validator.New()}
+}
+
+func (h *RateHandler) RegisterRoutes(router fiber.Router) {
+	router.Get("/api/admin/rates", h.Get)
+	router.Put("/api/admin/rates", h.Update)
+	router.Post("/api/admin/quote", h.Quote)
+}
+
+// Get - GET /api/admin/rates
+func (h *RateHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	card, err := h.shipmentUC.RateCard(c.Context(), companyID)
+	if err != nil {
+		if errors.Is(err, shipment.ErrNoRateCard) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No rate card configured"})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Get rate card error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load rate card"})
+	}
+	return c.JSON(card)
+}
+
+// Update - PUT /api/admin/rates
+func (h *RateHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var card shipment.RateCard
+	if err := c.BodyParser(&card); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	if err := h.shipmentUC.SetRateCard(c.Context(), companyID, &card); err != nil {
+		if errors.Is(err, shipment.ErrInvalidRateCard) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Update rate card error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save rate card"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_rates_update", nil)
+	return c.JSON(card)
+}
+
+// Quote - POST /api/admin/quote
+func (h *RateHandler) Quote(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req models.QuoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+	if err := h.validate.Struct(req); err != nil {
+		var errs []string
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			for _, e := range validationErrors {
+				errs = append(errs, fmt.Sprintf("'%s' is %s", e.Field(), e.Tag()))
+			}
+		} else {
+			errs = append(errs, err.Error())
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Validation failed: " + strings.Join(errs, ", ")})
+	}
+
+	quote, err := h.shipmentUC.Quote(c.Context(), companyID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, shipment.ErrNoRateCard):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": "No rate card configured"})
+		case errors.Is(err, shipment.ErrNoRate):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": fmt.Sprintf("No rate for %s -> %s", req.Origin, req.Destination)})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Quote error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to price shipment"})
+	}
+	return c.JSON(quote)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/shipment"
+)
+
+// RetentionHandler manages how long each company keeps its shipments
+type RetentionHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewRetentionHandler injects the Usecase
+func NewRetentionHandler(shipmentUC *shipment.Usecase) *RetentionHandler {
+	return &RetentionHandler{shipmentUC: shipmentUC}
+}
+
+func (h *RetentionHandler) RegisterRoutes(router fiber.Router) {
+	retention := router.Group("/api/admin/retention")
+	retention.Get("/", h.Get)
+	retention.Put("/", h.Update)
+	retention.Delete("/", h.Reset)
+}
+
+// Get - GET /api/admin/retention
+// Returns the effective policy, the plan default and the bounds the plan allows.
+func (h *RetentionHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	settings, err := h.shipmentUC.Retention(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Get retention policy error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load retention policy"})
+	}
+	return c.JSON(settings)
+}
+
+// Update - PUT /api/admin/retention
+func (h *RetentionHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var policy shipment.RetentionPolicy
+	if err := c.BodyParser(&policy); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	settings, err := h.shipmentUC.SetRetention(c.Context(), companyID, policy)
+	if err != nil {
+		if errors.Is(err, shipment.ErrInvalidRetention) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Update retention policy error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save retention policy"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_retention_update", nil)
+	return c.JSON(settings)
+}
+
+// Reset - DELETE /api/admin/retention returns the company to its plan's default
+func (h *RetentionHandler) Reset(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	settings, err := h.shipmentUC.ResetRetention(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Reset retention policy error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reset retention policy"})
+	}
+	return c.JSON(settings)
+}
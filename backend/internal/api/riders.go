@@ -0,0 +1,257 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+)
+
+// RiderHandler manages dispatch riders and which shipments they carry
+type RiderHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewRiderHandler injects the Usecase
+func NewRiderHandler(shipmentUC *shipment.Usecase) *RiderHandler {
+	return &RiderHandler{shipmentUC: shipmentUC}
+}
+
+func (h *RiderHandler) RegisterRoutes(router fiber.Router) {
+	riders := router.Group("/api/admin/riders")
+	riders.Get("/", h.List)
+	riders.Post("/", h.Create)
+	riders.Get("/:id", h.Get)
+	riders.Put("/:id", h.Update)
+	riders.Delete("/:id", h.Delete)
+
+	router.Get("/api/admin/shipments/:id/rider", h.Assignment)
+	router.Put("/api/admin/shipments/:id/rider", h.Assign)
+	router.Delete("/api/admin/shipments/:id/rider", h.Unassign)
+}
+
+// RiderRequest creates or edits a rider; Active defaults to true on create
+// and to the current value on update
+type RiderRequest struct {
+	Name   string `json:"name"`
+	Phone  string `json:"phone"`
+	Active *bool  `json:"active"`
+}
+
+// AssignRiderRequest gives a shipment to a rider
+type AssignRiderRequest struct {
+	RiderID int32 `json:"rider_id"`
+}
+
+func riderError(c *fiber.Ctx, companyID uuid.UUID, err error, action string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Rider not found"})
+	case errors.Is(err, shipment.ErrInvalidRider):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, shipment.ErrRiderExists):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Another rider already uses this phone number"})
+	}
+	logger.Error().Err(err).Str("company_id", companyID.String()).Msg(action + " rider error")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to " + action + " rider"})
+}
+
+// List - GET /api/admin/riders
+func (h *RiderHandler) List(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	riders, err := h.shipmentUC.ListRiders(c.Context(), companyID)
+	if err != nil {
+		return riderError(c, companyID, err, "list")
+	}
+	return c.JSON(fiber.Map{"riders": riders})
+}
+
+// Get - GET /api/admin/riders/:id
+func (h *RiderHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rider id"})
+	}
+
+	rider, err := h.shipmentUC.GetRider(c.Context(), companyID, int32(id))
+	if err != nil {
+		return riderError(c, companyID, err, "load")
+	}
+	return c.JSON(rider)
+}
+
+// Create - POST /api/admin/riders
+func (h *RiderHandler) Create(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var req RiderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	rider, err := h.shipmentUC.CreateRider(c.Context(), companyID, models.Rider{Name: req.Name, Phone: req.Phone})
+	if err != nil {
+		return riderError(c, companyID, err, "create")
+	}
+	if req.Active != nil && !*req.Active {
+		rider.Active = false
+		if rider, err = h.shipmentUC.UpdateRider(c.Context(), companyID, rider.ID, *rider); err != nil {
+			return riderError(c, companyID, err, "create")
+		}
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_rider_create", nil)
+	return c.Status(fiber.StatusCreated).JSON(rider)
+}
+
+// Update - PUT /api/admin/riders/:id
+func (h *RiderHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rider id"})
+	}
+
+	var req RiderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	current, err := h.shipmentUC.GetRider(c.Context(), companyID, int32(id))
+	if err != nil {
+		return riderError(c, companyID, err, "update")
+	}
+	active := current.Active
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	rider, err := h.shipmentUC.UpdateRider(c.Context(), companyID, int32(id), models.Rider{Name: req.Name, Phone: req.Phone, Active: active})
+	if err != nil {
+		return riderError(c, companyID, err, "update")
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_rider_update", nil)
+	return c.JSON(rider)
+}
+
+// Delete - DELETE /api/admin/riders/:id
+// Shipments the rider carried go back to the pulse.
+func (h *RiderHandler) Delete(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid rider id"})
+	}
+
+	if err := h.shipmentUC.DeleteRider(c.Context(), companyID, int32(id)); err != nil {
+		return riderError(c, companyID, err, "delete")
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_rider_delete", nil)
+	return c.JSON(fiber.Map{"success": true})
+}
+
+// Assignment - GET /api/admin/shipments/:id/rider
+func (h *RiderHandler) Assignment(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	rider, err := h.shipmentUC.ShipmentRider(c.Context(), companyID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.JSON(fiber.Map{"tracking_id": id, "rider": nil})
+	}
+	if err != nil {
+		return riderError(c, companyID, err, "load")
+	}
+	return c.JSON(fiber.Map{"tracking_id": id, "rider": rider})
+}
+
+// Assign - PUT /api/admin/shipments/:id/rider
+// While assigned, the rider (not the pulse) takes the shipment out for
+// delivery and marks it delivered.
+func (h *RiderHandler) Assign(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	var req AssignRiderRequest
+	if err := c.BodyParser(&req); err != nil || req.RiderID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "rider_id is required"})
+	}
+	if _, err := h.shipmentUC.Track(c.Context(), companyID, id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+
+	rider, err := h.shipmentUC.AssignRider(sourceContext(c), companyID, id, req.RiderID)
+	switch {
+	case errors.Is(err, shipment.ErrInvalidAssignment), errors.Is(err, shipment.ErrRiderInactive):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return riderError(c, companyID, err, "assign")
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_rider_assign", nil)
+	return c.JSON(fiber.Map{"tracking_id": id, "rider": rider})
+}
+
+// Unassign - DELETE /api/admin/shipments/:id/rider hands the shipment back to the pulse
+func (h *RiderHandler) Unassign(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+
+	if err := h.shipmentUC.UnassignRider(c.Context(), companyID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment has no rider"})
+		}
+		return riderError(c, companyID, err, "unassign")
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_rider_unassign", nil)
+	return c.JSON(fiber.Map{"success": true})
+}
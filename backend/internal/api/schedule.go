@@ -0,0 +1,212 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/shipment"
+)
+
+// ScheduleHandler manages the per-company scheduling profile
+type ScheduleHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewScheduleHandler injects the Usecase
+func NewScheduleHandler(shipmentUC *shipment.Usecase) *ScheduleHandler {
+	return &ScheduleHandler{shipmentUC: shipmentUC}
+}
+
+func (h *ScheduleHandler) RegisterRoutes(router fiber.Router) {
+	schedule := router.Group("/api/admin/schedule")
+	schedule.Get("/", h.Get)
+	schedule.Put("/", h.Update)
+	schedule.Delete("/", h.Reset)
+
+	schedule.Get("/holidays", h.ListHolidays)
+	schedule.Post("/holidays", h.AddHoliday)
+	schedule.Post("/holidays/import", h.ImportHolidays)
+	schedule.Delete("/holidays/:id", h.DeleteHoliday)
+}
+
+// Get - GET /api/admin/schedule
+func (h *ScheduleHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	profile, err := h.shipmentUC.ScheduleProfile(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Get scheduling profile error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load scheduling profile"})
+	}
+	return c.JSON(profile)
+}
+
+// Update - PUT /api/admin/schedule
+func (h *ScheduleHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var profile shipment.ScheduleProfile
+	if err := c.BodyParser(&profile); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	if err := h.shipmentUC.SetScheduleProfile(c.Context(), companyID, &profile); err != nil {
+		if errors.Is(err, shipment.ErrInvalidScheduleProfile) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Update scheduling profile error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save scheduling profile"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_schedule_update", nil)
+	return c.JSON(profile)
+}
+
+// Reset - DELETE /api/admin/schedule restores the default warehouse hours
+func (h *ScheduleHandler) Reset(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	profile := shipment.DefaultScheduleProfile()
+	if err := h.shipmentUC.SetScheduleProfile(c.Context(), companyID, profile); err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Reset scheduling profile error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reset scheduling profile"})
+	}
+	return c.JSON(profile)
+}
+
+// ListHolidays - GET /api/admin/schedule/holidays?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *ScheduleHandler) ListHolidays(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	from := time.Now().UTC().Truncate(24 * time.Hour)
+	to := from.AddDate(1, 0, 0)
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse("2006-01-02", v); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid 'from' date, expected YYYY-MM-DD"})
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid 'to' date, expected YYYY-MM-DD"})
+		}
+	}
+
+	holidays, err := h.shipmentUC.ListHolidays(c.Context(), companyID, from, to)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("List holidays error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list holidays"})
+	}
+
+	out := make([]fiber.Map, 0, len(holidays))
+	for _, hol := range holidays {
+		out = append(out, fiber.Map{
+			"id":      hol.ID,
+			"date":    hol.HolidayDate.Format("2006-01-02"),
+			"name":    hol.Name.String,
+			"country": hol.Country,
+		})
+	}
+	return c.JSON(fiber.Map{"holidays": out})
+}
+
+// AddHoliday - POST /api/admin/schedule/holidays
+func (h *ScheduleHandler) AddHoliday(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var entry shipment.HolidayEntry
+	if err := c.BodyParser(&entry); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	if err := h.shipmentUC.AddHoliday(c.Context(), companyID, entry); err != nil {
+		if errors.Is(err, shipment.ErrInvalidHoliday) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Add holiday error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save holiday"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(entry)
+}
+
+// ImportHolidays - POST /api/admin/schedule/holidays/import?country=nigeria&replace=true
+// The body is an ICS calendar or a JSON list of {date, name, country}.
+func (h *ScheduleHandler) ImportHolidays(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	body := c.Body()
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read uploaded file"})
+		}
+		defer f.Close()
+		buf := make([]byte, file.Size)
+		if _, err := io.ReadFull(f, buf); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Failed to read uploaded file"})
+		}
+		body = buf
+	}
+	if len(body) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Empty calendar"})
+	}
+
+	imported, err := h.shipmentUC.ImportHolidays(c.Context(), companyID, c.Query("country"), body, c.QueryBool("replace"))
+	if err != nil {
+		if errors.Is(err, shipment.ErrInvalidHoliday) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Import holidays error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to import holidays"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_holidays_import", []byte(fmt.Sprintf(`{"imported": %d}`, imported)))
+	return c.JSON(fiber.Map{"success": true, "imported": imported})
+}
+
+// DeleteHoliday - DELETE /api/admin/schedule/holidays/:id
+func (h *ScheduleHandler) DeleteHoliday(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid holiday id"})
+	}
+
+	deleted, err := h.shipmentUC.DeleteHoliday(c.Context(), companyID, int32(id))
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Delete holiday error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete holiday"})
+	}
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Holiday not found"})
+	}
+	return c.JSON(fiber.Map{"success": true})
+}
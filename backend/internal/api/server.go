@@ -107,6 +107,48 @@ func (s *Server) SetupRoutes() {
 	shipmentHandler := NewShipmentHandler(s.shipmentUC, s.configUC, s.cfg, s.bots)
 	shipmentHandler.RegisterRoutes(s.app)
 
+	trackingHandler := NewTrackingHandler(s.shipmentUC)
+	trackingHandler.RegisterRoutes(s.app)
+
+	scheduleHandler := NewScheduleHandler(s.shipmentUC)
+	scheduleHandler.RegisterRoutes(s.app)
+
+	rateHandler := NewRateHandler(s.shipmentUC)
+	rateHandler.RegisterRoutes(s.app)
+
+	customsHandler := NewCustomsHandler(s.shipmentUC, s.bots)
+	customsHandler.RegisterRoutes(s.app)
+
+	codHandler := NewCodHandler(s.shipmentUC)
+	codHandler.RegisterRoutes(s.app)
+
+	retentionHandler := NewRetentionHandler(s.shipmentUC)
+	retentionHandler.RegisterRoutes(s.app)
+
+	trackingFormatHandler := NewTrackingFormatHandler(s.shipmentUC)
+	trackingFormatHandler.RegisterRoutes(s.app)
+
+	contactHandler := NewContactHandler(s.shipmentUC)
+	contactHandler.RegisterRoutes(s.app)
+
+	customFieldHandler := NewCustomFieldHandler(s.shipmentUC)
+	customFieldHandler.RegisterRoutes(s.app)
+
+	deliveryPolicyHandler := NewDeliveryPolicyHandler(s.shipmentUC)
+	deliveryPolicyHandler.RegisterRoutes(s.app)
+
+	riderHandler := NewRiderHandler(s.shipmentUC)
+	riderHandler.RegisterRoutes(s.app)
+
+	podHandler := NewPODHandler(s.shipmentUC, s.cfg, s.bots)
+	podHandler.RegisterRoutes(s.app)
+
+	deliveryCodeHandler := NewDeliveryCodeHandler(s.shipmentUC, s.cfg, s.bots)
+	deliveryCodeHandler.RegisterRoutes(s.app)
+
+	consolidationHandler := NewConsolidationHandler(s.shipmentUC, s.configUC, s.cfg, s.bots)
+	consolidationHandler.RegisterRoutes(s.app)
+
 	companyHandler := NewCompanyHandler(s.cfg, s.configUC, s.bots)
 	companyHandler.RegisterRoutes(s.app)
 
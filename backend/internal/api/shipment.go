@@ -2,7 +2,10 @@ package api
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	"webtracker-bot/internal/notif"
 	"webtracker-bot/internal/parser"
 	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/trackingid"
 	"webtracker-bot/internal/utils"
 
 	"github.com/go-playground/validator/v10"
@@ -53,23 +57,58 @@ func getCompanyID(c *fiber.Ctx) uuid.UUID {
 	return uuid.Nil
 }
 
+// trackingIDError answers a request whose tracking ID failed ParseTrackingID
+func trackingIDError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, trackingid.ErrInvalid) || errors.Is(err, trackingid.ErrCheckDigit) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	logger.Error().Err(err).Msg("Tracking ID validation error")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to validate tracking ID"})
+}
+
+// sourceContext tags the request context so shipment history records the admin as the actor
+func sourceContext(c *fiber.Ctx) context.Context {
+	return sourceContextAs(c, utils.SourceAPI)
+}
+
+// sourceContextAs is sourceContext for a more specific channel, e.g. CSV imports.
+func sourceContextAs(c *fiber.Ctx, source string) context.Context {
+	actor := ""
+	if user, ok := c.Locals("user").(*auth.JWTClaims); ok && user != nil {
+		actor = user.Email
+	}
+	return utils.WithSource(c.Context(), source, actor)
+}
+
 func (h *ShipmentHandler) RegisterRoutes(router fiber.Router) {
 	// Admin Routes (Next.js protects these via Supabase Auth before calling Go)
 	admin := router.Group("/api/admin")
 
-	// Shipments (write operations only) — rate-limited to 30 req/min per IP to prevent abuse
+	// Shipments — writes are rate-limited to 30 req/min per IP to prevent abuse;
+	// reads are not, so paging through the list doesn't lock the dashboard out
 	shipments := admin.Group("/shipments", limiter.New(limiter.Config{
+		Next:              func(c *fiber.Ctx) bool { return c.Method() == fiber.MethodGet },
 		Max:               30,
 		Expiration:        1 * time.Minute,
 		LimiterMiddleware: limiter.SlidingWindow{},
 	}))
+	shipments.Get("/", h.List)
 	shipments.Post("/parse", h.ParseText)
 	shipments.Post("/bulk_csv", h.BulkCreateCSV)
 	shipments.Post("/", h.Create)
 	shipments.Delete("/cleanup", h.DeleteDelivered)
 	shipments.Patch("/bulk_status", h.BulkUpdateStatus)
 	shipments.Delete("/bulk_delete", h.BulkDelete)
+	shipments.Get("/trash", h.Trash)
+	shipments.Get("/:id/timeline", h.Timeline)
+	shipments.Get("/:id/changes", h.Changes)
+	shipments.Get("/:id", h.Get)
+	shipments.Post("/:id/restore", h.Restore)
+	shipments.Post("/:id/return", h.Return)
+	shipments.Get("/:id/attempts", h.Attempts)
+	shipments.Post("/:id/attempts", h.RecordAttempt)
 	shipments.Patch("/:id", h.UpdateStatus)
+	shipments.Patch("/:id/custom_fields", h.UpdateExtras)
 	shipments.Delete("/:id", h.Delete)
 }
 
@@ -87,6 +126,15 @@ type CreateRequest struct {
 	Weight          float64 `json:"weight" validate:"required,gt=0"`
 	Cost            float64 `json:"cost"`
 	TransitTime     int     `json:"transitTime"`
+	ServiceLevel    string  `json:"serviceLevel"`
+	// Pieces lists the individual boxes; Weight defaults to their total.
+	Pieces []models.Piece `json:"pieces" validate:"dive"`
+	// Cash to collect from the receiver; the currency defaults to the rate card's.
+	CodAmount   float64 `json:"codAmount" validate:"gte=0"`
+	CodCurrency string  `json:"codCurrency" validate:"omitempty,len=3"`
+	// Values for the company's custom fields, keyed by field name
+	CustomFields map[string]any `json:"customFields"`
+	Tags         []string       `json:"tags"`
 }
 
 // Create - POST /api/admin/shipments
@@ -116,6 +164,11 @@ func (h *ShipmentHandler) Create(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
 	}
+	if req.Weight == 0 {
+		req.Weight = models.TotalWeight(req.Pieces)
+	}
+	models.FillPieceWeights(req.Pieces, req.Weight)
+	req.CodCurrency = strings.ToUpper(strings.TrimSpace(req.CodCurrency))
 
 	if err := h.validate.Struct(req); err != nil {
 		var errs []string
@@ -136,25 +189,58 @@ func (h *ShipmentHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
+	customFields, err := h.shipmentUC.CustomFieldValues(c.Context(), companyID, req.CustomFields)
+	if err != nil {
+		return extrasError(c, companyID, err)
+	}
+	tags, err := shipment.NormalizeTags(req.Tags)
+	if err != nil {
+		return extrasError(c, companyID, err)
+	}
+
+	profile, err := h.shipmentUC.ScheduleProfile(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Failed to load scheduling profile")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load scheduling profile"})
+	}
+	if req.ServiceLevel != "" && !profile.HasService(req.ServiceLevel) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":     "Unknown service level: " + req.ServiceLevel,
+			"available": profile.ServiceNames(),
+		})
+	}
+	calendar, err := h.shipmentUC.HolidayCalendar(c.Context(), companyID)
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Scheduling without holiday calendar")
+	}
+	scheduler := &shipment.Calculator{Profile: profile, Level: req.ServiceLevel, Calendar: calendar}
+
+	// Price from the rate card unless the client set the cost explicitly
+	if req.Cost == 0 {
+		req.Cost = h.shipmentUC.EstimateCost(c.Context(), companyID, models.QuoteRequest{
+			Origin:      req.SenderCountry,
+			Destination: req.ReceiverCountry,
+			Weight:      req.Weight,
+			CargoType:   req.CargoType,
+			Pieces:      req.Pieces,
+		})
+	}
+
 	var trackingID string
 	var insertErr error
 	var params db.CreateShipmentParams
 
 	for attempts := 0; attempts < 5; attempts++ {
-		prefix := "AWB"
-		if company.TrackingPrefix.Valid && company.TrackingPrefix.String != "" {
-			prefix = company.TrackingPrefix.String
-		}
-		trackingID, err = utils.GenerateTrackingID(prefix)
+		trackingID, err = h.shipmentUC.NewTrackingID(c.Context(), companyID, company.TrackingPrefix.String)
 		if err != nil {
-			logger.Error().Err(err).Msg("Failed to generate random ID")
+			logger.Error().Err(err).Msg("Failed to generate tracking ID")
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate ID"})
 		}
 		now := time.Now()
 
-		// Use industrial status calculation algorithms instead of hardcoded defaults
-		departure := h.shipmentUC.Service.CalculateDeparture(now, "Africa/Lagos") // Default origin TZ
-		arrival, outForDelivery := h.shipmentUC.Service.CalculateArrival(departure, req.SenderCountry, req.ReceiverCountry)
+		// Schedule from the company's profile (hours, cut-off, transit days per service level)
+		departure := scheduler.CalculateDeparture(now, "Africa/Lagos") // Default origin TZ
+		arrival, outForDelivery := scheduler.CalculateArrival(departure, req.SenderCountry, req.ReceiverCountry)
 
 		params = db.CreateShipmentParams{
 			TrackingID:           trackingID,
@@ -165,7 +251,7 @@ func (h *ShipmentHandler) Create(c *fiber.Ctx) error {
 			OutfordeliveryTime:   dbutil.ToNullTime(outForDelivery),
 			ExpectedDeliveryTime: dbutil.ToNullTime(arrival),
 			SenderTimezone:       dbutil.ToNullString("Africa/Lagos"),
-			RecipientTimezone:    dbutil.ToNullString(h.shipmentUC.Service.ResolveTimezone(req.ReceiverCountry)),
+			RecipientTimezone:    dbutil.ToNullString(scheduler.ResolveTimezone(req.ReceiverCountry)),
 			SenderName:           dbutil.ToNullString(req.SenderName),
 			SenderPhone:          dbutil.ToNullString(req.SenderPhone),
 			Origin:               dbutil.ToNullString(req.SenderCountry),
@@ -178,15 +264,20 @@ func (h *ShipmentHandler) Create(c *fiber.Ctx) error {
 			Weight:               dbutil.ToNullFloat64(req.Weight),
 			Cost:                 dbutil.ToNullFloat64(req.Cost),
 			UpdatedAt:            dbutil.ToNullTime(now),
+			ServiceLevel:         dbutil.ToNullString(scheduler.ServiceLevel()),
+			CodAmount:            req.CodAmount,
+			CodCurrency:          dbutil.ToNullString(req.CodCurrency),
+			CustomFields:         customFields,
+			Tags:                 tags,
 		}
 
-		insertErr = h.shipmentUC.Create(c.Context(), companyID, params)
+		insertErr = h.shipmentUC.Create(sourceContext(c), companyID, params)
 		if insertErr == nil {
 			break // Success
 		}
 
 		// If the error isn't a unique constraint violation, abort
-		if !strings.Contains(insertErr.Error(), "duplicate key value violates unique constraint") && !strings.Contains(insertErr.Error(), "23505") {
+		if !dbutil.IsUniqueViolation(insertErr) {
 			break
 		}
 		logger.Warn().Str("trackingID", trackingID).Msg("Tracking ID collision detected, retrying...")
@@ -198,6 +289,12 @@ func (h *ShipmentHandler) Create(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create shipment"})
 	}
 
+	if len(req.Pieces) > 0 {
+		if err := h.shipmentUC.SavePieces(c.Context(), companyID, trackingID, req.Pieces); err != nil {
+			logger.Error().Err(err).Str("tracking_id", trackingID).Msg("Failed to save shipment pieces")
+		}
+	}
+
 	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_create_success", []byte(fmt.Sprintf(`{"id": "%s"}`, trackingID)))
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"tracking_id": trackingID})
 }
@@ -215,7 +312,10 @@ func (h *ShipmentHandler) UpdateStatus(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
 	}
 
-	id := c.Params("id")
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
 	var req UpdateStatusRequest
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
@@ -233,8 +333,26 @@ func (h *ShipmentHandler) UpdateStatus(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
 	}
+	// Without If-Match the update still only applies to the version read above
+	version, pinned, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid If-Match header"})
+	}
+	if pinned && version != ship.Version {
+		return versionConflict(c, ship.Version)
+	}
 
-	if err := h.shipmentUC.UpdateStatus(c.Context(), companyID, id, req.Status, req.Destination); err != nil {
+	if err := h.shipmentUC.UpdateStatus(sourceContext(c), companyID, id, req.Status, req.Destination, ship.Version); err != nil {
+		if errors.Is(err, shipment.ErrVersionConflict) {
+			return versionConflict(c, 0)
+		}
+		if errors.Is(err, shipment.ErrOTPRequired) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
+		var te *shipment.TransitionError
+		if errors.As(err, &te) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": te.Error(), "from": te.From, "to": te.To, "allowed": te.Allowed()})
+		}
 		logger.Error().Err(err).Str("id", id).Msg("Update status error")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update status"})
 	}
@@ -242,22 +360,436 @@ func (h *ShipmentHandler) UpdateStatus(c *fiber.Ctx) error {
 	// Send instant alert for manual admin overrides
 	if h.bots != nil {
 		if bot, err := h.bots.GetBot(companyID); err == nil {
-			notif.SendStatusAlertAsync(bot.GetWAClient(), h.cfg, bot.GetCompanyName(), ship.UserJid, ship.TrackingID, req.Status, ship.RecipientEmail.String)
+			notif.SendStatusAlertAsync(bot.GetWAClient(), h.cfg, bot.GetCompanyName(), ship.UserJid, ship.TrackingID, req.Status, ship.RecipientEmail.String, shipment.FormatCOD(ship.CodAmount, ship.CodCurrency.String))
 		}
 	}
 
-	return c.JSON(fiber.Map{"success": true})
+	c.Set(fiber.HeaderETag, etag(ship.Version+1))
+	return c.JSON(fiber.Map{"success": true, "version": ship.Version + 1})
+}
+
+// UpdateExtrasRequest changes custom field values and tags
+type UpdateExtrasRequest struct {
+	// Merged into the current values; null or "" clears a field
+	CustomFields map[string]any `json:"custom_fields"`
+	// Replaces the tags when present
+	Tags []string `json:"tags"`
+}
+
+// UpdateExtras - PATCH /api/admin/shipments/:id/custom_fields
+func (h *ShipmentHandler) UpdateExtras(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	var req UpdateExtrasRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	ship, err := h.shipmentUC.Track(c.Context(), companyID, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	version, pinned, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid If-Match header"})
+	}
+	if pinned && version != ship.Version {
+		return versionConflict(c, ship.Version)
+	}
+
+	if err := h.shipmentUC.UpdateExtras(sourceContext(c), companyID, id, req.CustomFields, req.Tags, ship.Version); err != nil {
+		if errors.Is(err, shipment.ErrVersionConflict) {
+			return versionConflict(c, 0)
+		}
+		return extrasError(c, companyID, err)
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_custom_fields_update", nil)
+	c.Set(fiber.HeaderETag, etag(ship.Version+1))
+	return c.JSON(fiber.Map{"success": true, "version": ship.Version + 1})
+}
+
+// ReturnRequest sends a shipment back to its sender
+type ReturnRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Return - POST /api/admin/shipments/:id/return
+// Creates the linked return shipment and marks the original returned.
+func (h *ShipmentHandler) Return(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	var req ReturnRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	ship, err := h.shipmentUC.Track(c.Context(), companyID, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	version, pinned, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid If-Match header"})
+	}
+	if pinned && version != ship.Version {
+		return versionConflict(c, ship.Version)
+	}
+	company, err := h.configUC.GetCompanyByID(c.Context(), companyID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to look up company"})
+	}
+
+	returnID, err := h.shipmentUC.CreateReturn(sourceContext(c), companyID, id, req.Reason, company.TrackingPrefix.String, ship.Version)
+	if err != nil {
+		var te *shipment.TransitionError
+		switch {
+		case errors.Is(err, shipment.ErrVersionConflict):
+			return versionConflict(c, 0)
+		case errors.Is(err, shipment.ErrAlreadyReturned):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Shipment already has a return", "return_id": ship.ReturnID.String})
+		case errors.Is(err, shipment.ErrInvalidReturn):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		case errors.As(err, &te):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": te.Error(), "from": te.From, "to": te.To, "allowed": te.Allowed()})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Create return error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create return"})
+	}
+
+	// Let the original sender know the parcel is coming back
+	if h.bots != nil {
+		if bot, err := h.bots.GetBot(companyID); err == nil {
+			notif.SendReturnAlertAsync(bot.GetWAClient(), h.cfg, ship.UserJid, id, returnID, strings.TrimSpace(req.Reason))
+		}
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_shipment_return", nil)
+	c.Set(fiber.HeaderETag, etag(ship.Version+1))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"success": true, "tracking_id": id, "return_id": returnID, "version": ship.Version + 1})
+}
+
+// AttemptRequest records a failed delivery attempt
+type AttemptRequest struct {
+	Reason string `json:"reason"`
+	Note   string `json:"note"`
+}
+
+// Attempts - GET /api/admin/shipments/:id/attempts
+func (h *ShipmentHandler) Attempts(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	if _, err := h.shipmentUC.Track(c.Context(), companyID, id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	attempts, err := h.shipmentUC.ListAttempts(c.Context(), companyID, id)
+	if err != nil {
+		logger.Error().Err(err).Str("id", id).Msg("List attempts error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load delivery attempts"})
+	}
+	policy, err := h.shipmentUC.AttemptPolicy(c.Context(), companyID)
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Using default delivery attempt policy")
+	}
+	return c.JSON(fiber.Map{"tracking_id": id, "attempts": attempts, "max_attempts": policy.MaxAttempts})
+}
+
+// RecordAttempt - POST /api/admin/shipments/:id/attempts
+// Logs a failed delivery. The shipment is rescheduled until the company's
+// max attempts are used up, then left in delivery_failed.
+func (h *ShipmentHandler) RecordAttempt(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	var req AttemptRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	ship, err := h.shipmentUC.Track(c.Context(), companyID, id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	version, pinned, err := ifMatchVersion(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid If-Match header"})
+	}
+	if pinned && version != ship.Version {
+		return versionConflict(c, ship.Version)
+	}
+
+	res, err := h.shipmentUC.RecordFailedAttempt(sourceContext(c), companyID, id, req.Reason, req.Note, ship.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, shipment.ErrVersionConflict):
+			return versionConflict(c, 0)
+		case errors.Is(err, shipment.ErrInvalidAttempt):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error(), "reasons": shipment.AttemptReasons})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Record attempt error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to record delivery attempt"})
+	}
+
+	if h.bots != nil {
+		if bot, err := h.bots.GetBot(companyID); err == nil {
+			lang, _ := h.configUC.GetUserLanguage(c.Context(), companyID, ship.UserJid)
+			notif.SendAttemptAlertAsync(bot.GetWAClient(), h.cfg, lang, id, res)
+		}
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_delivery_attempt", nil)
+	c.Set(fiber.HeaderETag, etag(ship.Version+1))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"attempt":      res.Attempt,
+		"max_attempts": res.MaxAttempts,
+		"final":        res.Final,
+		"status":       res.Status,
+		"version":      ship.Version + 1,
+	})
+}
+
+// extrasError answers a rejected custom field or tag value.
+func extrasError(c *fiber.Ctx, companyID uuid.UUID, err error) error {
+	if errors.Is(err, shipment.ErrInvalidCustomField) || errors.Is(err, shipment.ErrInvalidTag) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Custom fields error")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save custom fields"})
+}
+
+// etag renders a shipment version as a strong ETag.
+func etag(version int32) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatchVersion reads the shipment version pinned by an If-Match header.
+// pinned is false when the header is absent or "*".
+func ifMatchVersion(c *fiber.Ctx) (version int32, pinned bool, err error) {
+	h := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if h == "" || h == "*" {
+		return 0, false, nil
+	}
+	v, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(h, "W/"), `"`), 10, 32)
+	if err != nil || v <= 0 {
+		return 0, false, fmt.Errorf("invalid If-Match %q", h)
+	}
+	return int32(v), true, nil
+}
+
+// versionConflict answers a write that lost the race; current is included
+// when known so the dashboard can tell how stale it was.
+func versionConflict(c *fiber.Ctx, current int32) error {
+	body := fiber.Map{"error": "Shipment was changed by someone else. Reload it and try again."}
+	if current != 0 {
+		body["version"] = current
+	}
+	return c.Status(fiber.StatusConflict).JSON(body)
+}
+
+// Timeline - GET /api/admin/shipments/:id/timeline
+func (h *ShipmentHandler) Timeline(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	timeline, err := h.shipmentUC.Timeline(c.Context(), companyID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Timeline error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load timeline"})
+	}
+
+	// Last known position from a shared location, null when there is none
+	last, err := h.shipmentUC.LastCheckpoint(c.Context(), companyID, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Warn().Err(err).Str("id", id).Msg("Timeline without last position")
+	}
+	return c.JSON(fiber.Map{"tracking_id": id, "events": timeline, "last_position": last})
+}
+
+// Changes - GET /api/admin/shipments/:id/changes
+// Field-level edit history, newest first. Paginate with limit and offset.
+func (h *ShipmentHandler) Changes(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	changes, err := h.shipmentUC.Changes(c.Context(), companyID, id, int32(limit), int32(offset))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Changes error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load changes"})
+	}
+	return c.JSON(fiber.Map{"tracking_id": id, "changes": changes, "limit": limit, "offset": offset})
+}
+
+// List - GET /api/admin/shipments
+// Filters: status, from, to (RFC3339 or YYYY-MM-DD; "to" is inclusive for dates),
+// destination, user_jid, q (free text). Paginate with limit and cursor=next_cursor.
+func (h *ShipmentHandler) List(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	filter := shipment.SearchFilter{
+		Status:      c.Query("status"),
+		Destination: c.Query("destination"),
+		UserJID:     c.Query("user_jid"),
+		Query:       c.Query("q"),
+		Cursor:      c.Query("cursor"),
+	}
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = shipment.SplitTags(tags)
+	}
+	// Custom fields filter as cf.<name>=value
+	for key, value := range c.Queries() {
+		if name, ok := strings.CutPrefix(key, "cf."); ok {
+			if filter.CustomFields == nil {
+				filter.CustomFields = make(map[string]string)
+			}
+			filter.CustomFields[name] = value
+		}
+	}
+	if filter.Status != "" && !shipment.IsKnownStatus(filter.Status) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Unknown status %q", filter.Status)})
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit <= 0 || limit > shipment.MaxSearchLimit {
+		limit = 50
+	}
+	filter.Limit = limit
+
+	var err error
+	if filter.CreatedFrom, err = parseDateParam(c.Query("from"), false); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid from date"})
+	}
+	if filter.CreatedTo, err = parseDateParam(c.Query("to"), true); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid to date"})
+	}
+
+	page, err := h.shipmentUC.Search(c.Context(), companyID, filter)
+	if err != nil {
+		if errors.Is(err, shipment.ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
+		}
+		if errors.Is(err, shipment.ErrInvalidCustomField) || errors.Is(err, shipment.ErrInvalidTag) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("List shipments error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to list shipments"})
+	}
+	return c.JSON(fiber.Map{"shipments": page.Shipments, "next_cursor": page.NextCursor, "limit": limit})
+}
+
+// parseDateParam accepts RFC3339 or YYYY-MM-DD. With endOfDay, a bare date
+// becomes the start of the next day so it can be used as an exclusive bound.
+func parseDateParam(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// Get - GET /api/admin/shipments/:id
+func (h *ShipmentHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	s, err := h.shipmentUC.Detail(c.Context(), companyID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Get shipment error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load shipment"})
+	}
+	c.Set(fiber.HeaderETag, etag(s.Version))
+	return c.JSON(s)
 }
 
 // Delete - DELETE /api/admin/shipments/:id
+// Moves the shipment to the trash; it can be restored until it is purged.
 func (h *ShipmentHandler) Delete(c *fiber.Ctx) error {
 	companyID := getCompanyID(c)
 	if companyID == uuid.Nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
 	}
 
-	id := c.Params("id")
-	if err := h.shipmentUC.Delete(c.Context(), companyID, id); err != nil {
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	if err := h.shipmentUC.Delete(sourceContext(c), companyID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+		}
 		logger.Error().Err(err).Str("id", id).Msg("Delete error")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to delete shipment"})
 	}
@@ -271,10 +803,58 @@ func (h *ShipmentHandler) DeleteDelivered(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
 	}
 
-	if err := h.shipmentUC.DeleteDelivered(c.Context(), companyID); err != nil {
+	deleted, err := h.shipmentUC.DeleteDelivered(sourceContext(c), companyID)
+	if err != nil {
 		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Cleanup error")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to cleanup"})
 	}
+	return c.JSON(fiber.Map{"success": true, "deleted": deleted})
+}
+
+// Trash - GET /api/admin/shipments/trash?limit=50&offset=0
+func (h *ShipmentHandler) Trash(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset, _ := strconv.Atoi(c.Query("offset", "0"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	shipments, err := h.shipmentUC.Trash(c.Context(), companyID, int32(limit), int32(offset))
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Trash error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load trash"})
+	}
+	return c.JSON(fiber.Map{"shipments": shipments, "retention_days": h.cfg.TrashRetentionDays, "limit": limit, "offset": offset})
+}
+
+// Restore - POST /api/admin/shipments/:id/restore
+func (h *ShipmentHandler) Restore(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	id, err := h.shipmentUC.ParseTrackingID(c.Context(), companyID, c.Params("id"))
+	if err != nil {
+		return trackingIDError(c, err)
+	}
+	if err := h.shipmentUC.Restore(sourceContext(c), companyID, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment is not in the trash"})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Restore error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to restore shipment"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_restore", nil)
 	return c.JSON(fiber.Map{"success": true})
 }
 
@@ -362,30 +942,67 @@ func (h *ShipmentHandler) BulkCreateCSV(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "No shipments found in CSV"})
 	}
 
+	profile, err := h.shipmentUC.ScheduleProfile(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Failed to load scheduling profile")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load scheduling profile"})
+	}
+	calendar, err := h.shipmentUC.HolidayCalendar(c.Context(), companyID)
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Scheduling without holiday calendar")
+	}
+	defs, err := h.shipmentUC.CustomFields(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Failed to load custom fields")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load custom fields"})
+	}
+
 	createdIds := []string{}
 	failed := 0
+	var rowErrors []string
+
+	for i, m := range manifests {
+		if m.ServiceLevel != "" && !profile.HasService(m.ServiceLevel) {
+			logger.Warn().Str("service_level", m.ServiceLevel).Msg("Bulk create skipped row with unknown service level")
+			failed++
+			continue
+		}
+		// Columns naming a custom field are validated like the API's customFields
+		customFields, err := shipment.CheckCustomFields(defs, shipment.MatchCustomFields(defs, m.CustomFields), false)
+		if err == nil {
+			m.Tags, err = shipment.NormalizeTags(m.Tags)
+		}
+		if err != nil {
+			// Row 1 is the header
+			rowErrors = append(rowErrors, fmt.Sprintf("row %d: %v", i+2, err))
+			failed++
+			continue
+		}
+		scheduler := &shipment.Calculator{Profile: profile, Level: m.ServiceLevel, Calendar: calendar}
+		cost := h.shipmentUC.EstimateCost(c.Context(), companyID, models.QuoteRequest{
+			Origin:      m.SenderCountry,
+			Destination: m.ReceiverCountry,
+			Weight:      m.Weight,
+			CargoType:   m.CargoType,
+			Pieces:      m.Pieces,
+		})
 
-	for _, m := range manifests {
 		var trackingID string
 		var insertErr error
 
 		for attempts := 0; attempts < 5; attempts++ {
-			prefix := "AWB"
-			if company.TrackingPrefix.Valid && company.TrackingPrefix.String != "" {
-				prefix = company.TrackingPrefix.String
-			}
-			trackingID, err = utils.GenerateTrackingID(prefix)
+			trackingID, err = h.shipmentUC.NewTrackingID(c.Context(), companyID, company.TrackingPrefix.String)
 			if err != nil {
-				logger.Error().Err(err).Msg("Failed to generate random ID in bulk")
+				logger.Error().Err(err).Msg("Failed to generate tracking ID in bulk")
 				insertErr = err
 				break
 			}
 			now := time.Now()
 
-			departure := h.shipmentUC.Service.CalculateDeparture(now, "Africa/Lagos")
-			arrival, outForDelivery := h.shipmentUC.Service.CalculateArrival(departure, m.SenderCountry, m.ReceiverCountry)
+			departure := scheduler.CalculateDeparture(now, "Africa/Lagos")
+			arrival, outForDelivery := scheduler.CalculateArrival(departure, m.SenderCountry, m.ReceiverCountry)
 
-			insertErr = h.shipmentUC.Create(c.Context(), companyID, db.CreateShipmentParams{
+			insertErr = h.shipmentUC.Create(sourceContextAs(c, utils.SourceCSV), companyID, db.CreateShipmentParams{
 				TrackingID:           trackingID,
 				UserJid:              "admin_portal",
 				Status:               dbutil.ToNullString("pending"),
@@ -394,7 +1011,7 @@ func (h *ShipmentHandler) BulkCreateCSV(c *fiber.Ctx) error {
 				OutfordeliveryTime:   dbutil.ToNullTime(outForDelivery),
 				ExpectedDeliveryTime: dbutil.ToNullTime(arrival),
 				SenderTimezone:       dbutil.ToNullString("Africa/Lagos"),
-				RecipientTimezone:    dbutil.ToNullString(h.shipmentUC.Service.ResolveTimezone(m.ReceiverCountry)),
+				RecipientTimezone:    dbutil.ToNullString(scheduler.ResolveTimezone(m.ReceiverCountry)),
 				SenderName:           dbutil.ToNullString(m.SenderName),
 				Origin:               dbutil.ToNullString(m.SenderCountry),
 				RecipientName:        dbutil.ToNullString(m.ReceiverName),
@@ -404,14 +1021,20 @@ func (h *ShipmentHandler) BulkCreateCSV(c *fiber.Ctx) error {
 				Destination:          dbutil.ToNullString(m.ReceiverCountry),
 				CargoType:            dbutil.ToNullString(m.CargoType),
 				Weight:               dbutil.ToNullFloat64(m.Weight),
+				Cost:                 dbutil.ToNullFloat64(cost),
 				UpdatedAt:            dbutil.ToNullTime(now),
+				ServiceLevel:         dbutil.ToNullString(scheduler.ServiceLevel()),
+				CodAmount:            m.CodAmount,
+				CodCurrency:          dbutil.ToNullString(m.CodCurrency),
+				CustomFields:         shipment.EncodeCustomFields(customFields),
+				Tags:                 m.Tags,
 			})
 
 			if insertErr == nil {
 				break // Success
 			}
 
-			if !strings.Contains(insertErr.Error(), "duplicate key value violates unique constraint") && !strings.Contains(insertErr.Error(), "23505") {
+			if !dbutil.IsUniqueViolation(insertErr) {
 				break // Not a collision error, abort
 			}
 		}
@@ -419,9 +1042,14 @@ func (h *ShipmentHandler) BulkCreateCSV(c *fiber.Ctx) error {
 		if insertErr != nil {
 			logger.Error().Err(insertErr).Msg("Bulk create error")
 			failed++
-		} else {
-			createdIds = append(createdIds, trackingID)
+			continue
+		}
+		if len(m.Pieces) > 0 {
+			if err := h.shipmentUC.SavePieces(c.Context(), companyID, trackingID, m.Pieces); err != nil {
+				logger.Error().Err(err).Str("tracking_id", trackingID).Msg("Failed to save shipment pieces")
+			}
 		}
+		createdIds = append(createdIds, trackingID)
 	}
 
 	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_bulk_csv", []byte(fmt.Sprintf(`{"created": %d, "failed": %d}`, len(createdIds), failed)))
@@ -431,6 +1059,7 @@ func (h *ShipmentHandler) BulkCreateCSV(c *fiber.Ctx) error {
 		"created": len(createdIds),
 		"failed":  failed,
 		"ids":     createdIds,
+		"errors":  rowErrors,
 	})
 }
 
@@ -456,7 +1085,18 @@ func (h *ShipmentHandler) BulkUpdateStatus(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	if err := h.shipmentUC.BulkUpdateStatus(c.Context(), companyID, req.IDs, req.Status); err != nil {
+	if err := h.shipmentUC.BulkUpdateStatus(sourceContext(c), companyID, req.IDs, req.Status); err != nil {
+		if errors.Is(err, shipment.ErrOTPRequired) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": err.Error()})
+		}
+		var te *shipment.TransitionError
+		if errors.As(err, &te) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"error": te.Error(), "tracking_id": te.TrackingID, "from": te.From, "to": te.To, "allowed": te.Allowed()})
+		}
+		var conflict *shipment.BulkConflictError
+		if errors.As(err, &conflict) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Some shipments were changed by someone else. Nothing was updated; reload and try again.", "conflicts": conflict.IDs})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
@@ -475,7 +1115,7 @@ func (h *ShipmentHandler) BulkUpdateStatus(c *fiber.Ctx) error {
 				defer cancel()
 				for _, id := range ids {
 					if ship, err := shipUC.Track(ctx, companyID, id); err == nil {
-						notif.SendStatusAlert(ctx, bot.GetWAClient(), cfg, bot.GetCompanyName(), ship.UserJid, ship.TrackingID, status, ship.RecipientEmail.String)
+						notif.SendStatusAlert(ctx, bot.GetWAClient(), cfg, bot.GetCompanyName(), ship.UserJid, ship.TrackingID, status, ship.RecipientEmail.String, shipment.FormatCOD(ship.CodAmount, ship.CodCurrency.String))
 					}
 				}
 			}()
@@ -502,7 +1142,7 @@ func (h *ShipmentHandler) BulkDelete(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
 	}
 
-	deleted, err := h.shipmentUC.BulkDelete(c.Context(), companyID, req.IDs)
+	deleted, err := h.shipmentUC.BulkDelete(sourceContext(c), companyID, req.IDs)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
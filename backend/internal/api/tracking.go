@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/trackingid"
+)
+
+// TrackingHandler serves the unauthenticated public tracking endpoints
+type TrackingHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewTrackingHandler injects the Usecase
+func NewTrackingHandler(shipmentUC *shipment.Usecase) *TrackingHandler {
+	return &TrackingHandler{shipmentUC: shipmentUC}
+}
+
+func (h *TrackingHandler) RegisterRoutes(router fiber.Router) {
+	// Public routes (whitelisted in JWTAuth) — rate-limited per IP
+	track := router.Group("/api/track", limiter.New(limiter.Config{
+		Max:               60,
+		Expiration:        1 * time.Minute,
+		LimiterMiddleware: limiter.SlidingWindow{},
+	}))
+
+	// Anti-enumeration: only lookups that miss count, so a customer refreshing
+	// their own shipment is never blocked but guessing tracking IDs is.
+	missLimiter := limiter.New(limiter.Config{
+		Max:                    10,
+		Expiration:             10 * time.Minute,
+		SkipSuccessfulRequests: true,
+		LimiterMiddleware:      limiter.SlidingWindow{},
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many unknown tracking numbers. Please try again later.",
+			})
+		},
+	})
+
+	// Four digits are easy to brute-force from many IPs, so failed checks are
+	// counted per shipment rather than per caller.
+	verifyLimiter := limiter.New(limiter.Config{
+		Max:        5,
+		Expiration: 15 * time.Minute,
+		// Per client and shipment, with the same normalization as the
+		// handler so "awb-1" and "*AWB-1*" share a bucket
+		KeyGenerator: func(c *fiber.Ctx) string {
+			id, err := trackingid.Parse(c.Params("id"))
+			if err != nil {
+				id = c.Params("id")
+			}
+			return c.IP() + ":" + id
+		},
+		SkipSuccessfulRequests: true,
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many verification attempts. Please try again later.",
+			})
+		},
+	})
+
+	track.Get("/:id/timeline", missLimiter, h.Timeline)
+	track.Get("/:id", missLimiter, h.Track)
+	track.Post("/:id/verify", missLimiter, verifyLimiter, h.Verify)
+}
+
+// Track - GET /api/track/:id
+// Returns the redacted public view of a shipment with its timeline.
+func (h *TrackingHandler) Track(c *fiber.Ctx) error {
+	return h.track(c, "")
+}
+
+// VerifyRequest carries the last digits of the recipient's phone
+type VerifyRequest struct {
+	PhoneLast4 string `json:"phone_last4"`
+}
+
+// Verify - POST /api/track/:id/verify
+// Returns the full shipment details when the recipient's phone digits match.
+func (h *TrackingHandler) Verify(c *fiber.Ctx) error {
+	var req VerifyRequest
+	if err := c.BodyParser(&req); err != nil || len(req.PhoneLast4) != shipment.PhoneVerifyDigits {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": fmt.Sprintf("Enter the last %d digits of the recipient's phone", shipment.PhoneVerifyDigits)})
+	}
+	return h.track(c, req.PhoneLast4)
+}
+
+func (h *TrackingHandler) track(c *fiber.Ctx, phoneDigits string) error {
+	// Malformed IDs never reach the database but still count as misses
+	id, err := trackingid.Parse(c.Params("id"))
+	if err != nil {
+		return trackMiss(c, phoneDigits)
+	}
+
+	view, err := h.shipmentUC.PublicTrack(c.Context(), id, phoneDigits)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, shipment.ErrPhoneMismatch) {
+			return trackMiss(c, phoneDigits)
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Public tracking error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load shipment"})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(view)
+}
+
+// trackMiss answers a lookup that found nothing to show. Unknown and trashed
+// shipments look the same to the caller, and so does a failed phone check:
+// otherwise /verify would tell which tracking IDs exist.
+func trackMiss(c *fiber.Ctx, phoneDigits string) error {
+	if phoneDigits != "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Shipment not found or phone number does not match"})
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+}
+
+// Timeline - GET /api/track/:id/timeline
+func (h *TrackingHandler) Timeline(c *fiber.Ctx) error {
+	id, err := trackingid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+	}
+	timeline, err := h.shipmentUC.PublicTimeline(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Shipment not found"})
+		}
+		logger.Error().Err(err).Str("id", id).Msg("Public timeline error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load timeline"})
+	}
+
+	return c.JSON(fiber.Map{"tracking_id": id, "events": timeline})
+}
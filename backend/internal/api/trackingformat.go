@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/trackingid"
+)
+
+// TrackingFormatHandler manages how each company's tracking IDs are generated
+type TrackingFormatHandler struct {
+	shipmentUC *shipment.Usecase
+}
+
+// NewTrackingFormatHandler injects the Usecase
+func NewTrackingFormatHandler(shipmentUC *shipment.Usecase) *TrackingFormatHandler {
+	return &TrackingFormatHandler{shipmentUC: shipmentUC}
+}
+
+func (h *TrackingFormatHandler) RegisterRoutes(router fiber.Router) {
+	format := router.Group("/api/admin/tracking_format")
+	format.Get("/", h.Get)
+	format.Put("/", h.Update)
+}
+
+// Get - GET /api/admin/tracking_format
+// Returns the format together with a sample ID so the dashboard can preview it.
+func (h *TrackingFormatHandler) Get(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	format, err := h.shipmentUC.TrackingFormat(c.Context(), companyID)
+	if err != nil {
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Get tracking format error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load tracking format"})
+	}
+	return c.JSON(fiber.Map{"format": format, "example": format.Build("", time.Now(), 42)})
+}
+
+// Update - PUT /api/admin/tracking_format
+// Only new shipments use the new format; existing IDs keep working.
+func (h *TrackingFormatHandler) Update(c *fiber.Ctx) error {
+	companyID := getCompanyID(c)
+	if companyID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Missing or invalid company_id"})
+	}
+
+	var format trackingid.Format
+	if err := c.BodyParser(&format); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid payload"})
+	}
+
+	if err := h.shipmentUC.SetTrackingFormat(c.Context(), companyID, format); err != nil {
+		if errors.Is(err, trackingid.ErrInvalidFormat) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		logger.Error().Err(err).Str("company_id", companyID.String()).Msg("Update tracking format error")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save tracking format"})
+	}
+
+	h.shipmentUC.RecordEvent(c.Context(), companyID, "admin_tracking_format_update", nil)
+	return c.JSON(fiber.Map{"format": format, "example": format.Build("", time.Now(), 42)})
+}
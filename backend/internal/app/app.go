@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"database/sql"
+	"webtracker-bot/internal/blob"
 	"webtracker-bot/internal/config"
 	"webtracker-bot/internal/database"
 	"webtracker-bot/internal/database/db"
 	"webtracker-bot/internal/logger"
 	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/notif"
 	"webtracker-bot/internal/receipt"
 	"webtracker-bot/internal/scheduler"
 	"webtracker-bot/internal/shipment"
@@ -65,6 +67,10 @@ func (a *App) Init() error {
 	querier := db.New(a.SqlPool)
 	shipService := &shipment.Calculator{}
 	a.ShipmentUC = shipment.NewUsecase(querier, shipService)
+	a.ShipmentUC.Pool = a.SqlPool
+	if a.Cfg.BlobDir != "" {
+		a.ShipmentUC.Blobs = blob.NewLocalStorage(a.Cfg.BlobDir)
+	}
 	a.ConfigUC = config.NewUsecase(querier, a.SqlPool)
 
 	dbUrl := a.Cfg.DirectURL
@@ -78,6 +84,7 @@ func (a *App) Init() error {
 	a.WAStore = store
 
 	a.BotManager = whatsapp.NewManager(a.Context, a.Cfg, a.ShipmentUC, a.ConfigUC, a.WAStore, &a.WG)
+	a.ShipmentUC.SendOTP = a.sendDeliveryOTP
 
 	companies, err := a.ConfigUC.GetAllActiveCompanies(context.Background())
 	if err != nil {
@@ -206,6 +213,18 @@ func (a *App) Shutdown() error {
 
 // BotProvider Implementation (Delegation to BotManager)
 
+// sendDeliveryOTP sends a delivery code through the company's bot; the
+// email still goes out while the bot is offline.
+func (a *App) sendDeliveryOTP(ctx context.Context, companyID uuid.UUID, ship db.Shipment, code string) {
+	bot, err := a.BotManager.GetBot(companyID)
+	if err != nil {
+		notif.SendDeliveryOTPAsync(nil, a.Cfg, "", "", &ship, code)
+		return
+	}
+	lang, _ := a.ConfigUC.GetUserLanguage(ctx, companyID, ship.UserJid)
+	notif.SendDeliveryOTPAsync(bot.GetWAClient(), a.Cfg, bot.GetCompanyName(), lang, &ship, code)
+}
+
 func (a *App) GetBot(companyID uuid.UUID) (models.BotInstance, error) {
 	return a.BotManager.GetBot(companyID)
 }
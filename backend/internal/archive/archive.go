@@ -0,0 +1,278 @@
+// Package archive keeps shipments that are about to be pruned as
+// gzip-compressed JSONL files, one file per company and month.
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+
+	"github.com/google/uuid"
+)
+
+// BatchSize is how many shipments are archived and purged per round-trip.
+const BatchSize = 500
+
+// Record is one archived shipment together with the rows that would be
+// cascaded away with it. Each Record is one JSONL line.
+//
+// Rows only needed while a shipment is moving are not kept: rider
+// assignments, check-in sessions, delivery OTPs (secret hashes) and bag
+// membership. Proof-of-delivery photos are personal data and are deleted
+// with the shipment rather than archived. COD ledger entries outlive the
+// shipment (their tracking_id is set to NULL), so they need no copy here.
+type Record struct {
+	Shipment     db.Shipment             `json:"shipment"`
+	Events       []db.ShipmentEvent      `json:"events,omitempty"`
+	Pieces       []db.ShipmentPiece      `json:"pieces,omitempty"`
+	Customs      *db.CustomsDeclaration  `json:"customs,omitempty"`
+	CustomsItems []db.CustomsItem        `json:"customs_items,omitempty"`
+	Changes      []db.ShipmentChange     `json:"changes,omitempty"`
+	Attempts     []db.DeliveryAttempt    `json:"attempts,omitempty"`
+	Checkpoints  []db.ShipmentCheckpoint `json:"checkpoints,omitempty"`
+	ArchivedAt   time.Time               `json:"archived_at"`
+}
+
+// Source loads and purges the shipments being archived (shipment.Usecase).
+type Source interface {
+	AgedShipments(ctx context.Context, companyID uuid.UUID, deliveredOlderThan, createdOlderThan time.Time, limit int32) ([]db.Shipment, error)
+	ExpiredTrash(ctx context.Context, companyID uuid.UUID, deletedBefore time.Time, limit int32) ([]db.Shipment, error)
+	EventsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentEvent, error)
+	PiecesForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentPiece, error)
+	CustomsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.CustomsDeclaration, []db.CustomsItem, error)
+	ChangesForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentChange, error)
+	AttemptsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.DeliveryAttempt, error)
+	CheckpointsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentCheckpoint, error)
+	PurgeShipments(ctx context.Context, companyID uuid.UUID, ids []string) (int64, error)
+}
+
+// Archiver writes records to Storage and only then deletes them from the database.
+type Archiver struct {
+	store Storage
+	src   Source
+	now   func() time.Time
+}
+
+// NewArchiver creates an Archiver that reads from src and writes to store.
+func NewArchiver(store Storage, src Source) *Archiver {
+	return &Archiver{store: store, src: src, now: time.Now}
+}
+
+// PartitionKey is the archive file a shipment belongs to, by creation month (UTC).
+func PartitionKey(companyID uuid.UUID, createdAt time.Time) string {
+	return fmt.Sprintf("%s/%s.jsonl.gz", companyID, createdAt.UTC().Format("2006-01"))
+}
+
+// ArchiveAged archives and then purges the shipments RunAgedCleanup would
+// delete, in batches. A batch is only purged once its archive files are
+// written, so a storage failure leaves the rows in place for the next run.
+// Returns the number of shipments purged.
+func (a *Archiver) ArchiveAged(ctx context.Context, companyID uuid.UUID, deliveredOlderThan, createdOlderThan time.Time) (int64, error) {
+	return a.archiveBatches(ctx, companyID, func() ([]db.Shipment, error) {
+		return a.src.AgedShipments(ctx, companyID, deliveredOlderThan, createdOlderThan, BatchSize)
+	})
+}
+
+// ArchiveTrash is ArchiveAged for the shipments PurgeTrash would delete:
+// those trashed before deletedBefore.
+func (a *Archiver) ArchiveTrash(ctx context.Context, companyID uuid.UUID, deletedBefore time.Time) (int64, error) {
+	return a.archiveBatches(ctx, companyID, func() ([]db.Shipment, error) {
+		return a.src.ExpiredTrash(ctx, companyID, deletedBefore, BatchSize)
+	})
+}
+
+// archiveBatches archives and purges the batches next returns until one
+// comes back short.
+func (a *Archiver) archiveBatches(ctx context.Context, companyID uuid.UUID, next func() ([]db.Shipment, error)) (int64, error) {
+	var total int64
+	for {
+		rows, err := next()
+		if err != nil {
+			return total, err
+		}
+		if len(rows) == 0 {
+			return total, nil
+		}
+
+		ids := make([]string, len(rows))
+		for i, r := range rows {
+			ids[i] = r.TrackingID
+		}
+		b, err := a.load(ctx, companyID, ids)
+		if err != nil {
+			return total, err
+		}
+
+		if err := a.Write(ctx, companyID, buildRecords(rows, b, a.now().UTC())); err != nil {
+			return total, err
+		}
+
+		purged, err := a.src.PurgeShipments(ctx, companyID, ids)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+		// Nothing purged means the same batch would come back forever.
+		if purged == 0 || len(rows) < BatchSize {
+			return total, nil
+		}
+	}
+}
+
+// batch holds the dependent rows of one batch of shipments.
+type batch struct {
+	events       []db.ShipmentEvent
+	pieces       []db.ShipmentPiece
+	customs      []db.CustomsDeclaration
+	customsItems []db.CustomsItem
+	changes      []db.ShipmentChange
+	attempts     []db.DeliveryAttempt
+	checkpoints  []db.ShipmentCheckpoint
+}
+
+func (a *Archiver) load(ctx context.Context, companyID uuid.UUID, ids []string) (batch, error) {
+	var b batch
+	var err error
+	if b.events, err = a.src.EventsForShipments(ctx, companyID, ids); err != nil {
+		return b, err
+	}
+	if b.pieces, err = a.src.PiecesForShipments(ctx, companyID, ids); err != nil {
+		return b, err
+	}
+	if b.customs, b.customsItems, err = a.src.CustomsForShipments(ctx, companyID, ids); err != nil {
+		return b, err
+	}
+	if b.changes, err = a.src.ChangesForShipments(ctx, companyID, ids); err != nil {
+		return b, err
+	}
+	if b.attempts, err = a.src.AttemptsForShipments(ctx, companyID, ids); err != nil {
+		return b, err
+	}
+	if b.checkpoints, err = a.src.CheckpointsForShipments(ctx, companyID, ids); err != nil {
+		return b, err
+	}
+	return b, nil
+}
+
+// groupBy indexes rows by the tracking ID key returns, keeping their order.
+func groupBy[T any](rows []T, key func(T) string) map[string][]T {
+	out := make(map[string][]T)
+	for _, r := range rows {
+		out[key(r)] = append(out[key(r)], r)
+	}
+	return out
+}
+
+func buildRecords(rows []db.Shipment, b batch, archivedAt time.Time) []Record {
+	eventsByID := groupBy(b.events, func(e db.ShipmentEvent) string { return e.TrackingID })
+	piecesByID := groupBy(b.pieces, func(p db.ShipmentPiece) string { return p.TrackingID })
+	itemsByID := groupBy(b.customsItems, func(i db.CustomsItem) string { return i.TrackingID })
+	changesByID := groupBy(b.changes, func(c db.ShipmentChange) string { return c.TrackingID })
+	attemptsByID := groupBy(b.attempts, func(a db.DeliveryAttempt) string { return a.TrackingID })
+	checkpointsByID := groupBy(b.checkpoints, func(c db.ShipmentCheckpoint) string { return c.TrackingID })
+	customsByID := make(map[string]*db.CustomsDeclaration, len(b.customs))
+	for i := range b.customs {
+		customsByID[b.customs[i].TrackingID] = &b.customs[i]
+	}
+
+	records := make([]Record, 0, len(rows))
+	for _, r := range rows {
+		records = append(records, Record{
+			Shipment:     r,
+			Events:       eventsByID[r.TrackingID],
+			Pieces:       piecesByID[r.TrackingID],
+			Customs:      customsByID[r.TrackingID],
+			CustomsItems: itemsByID[r.TrackingID],
+			Changes:      changesByID[r.TrackingID],
+			Attempts:     attemptsByID[r.TrackingID],
+			Checkpoints:  checkpointsByID[r.TrackingID],
+			ArchivedAt:   archivedAt,
+		})
+	}
+	return records
+}
+
+// Write appends records to their company/month partitions. Each call adds
+// one gzip member per partition; readers see the concatenation as one stream.
+func (a *Archiver) Write(ctx context.Context, companyID uuid.UUID, records []Record) error {
+	partitions := make(map[string][]Record)
+	for _, r := range records {
+		created := r.ArchivedAt
+		if r.Shipment.CreatedAt.Valid {
+			created = r.Shipment.CreatedAt.Time
+		}
+		key := PartitionKey(companyID, created)
+		partitions[key] = append(partitions[key], r)
+	}
+
+	keys := make([]string, 0, len(partitions))
+	for k := range partitions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// Compress in memory so the file only ever receives a complete member.
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		enc := json.NewEncoder(gz)
+		for _, r := range partitions[key] {
+			if err := enc.Encode(r); err != nil {
+				return fmt.Errorf("failed to encode archive record: %w", err)
+			}
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress archive: %w", err)
+		}
+
+		w, err := a.store.Append(ctx, key)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			w.Close()
+			return fmt.Errorf("failed to write archive %s: %w", key, err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("failed to close archive %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// Read decodes every record in the archive at key, in write order.
+func Read(ctx context.Context, store Storage, key string, fn func(Record) error) error {
+	rc, err := store.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %s: %w", key, err)
+	}
+	defer gz.Close()
+
+	dec := json.NewDecoder(gz)
+	for {
+		var r Record
+		if err := dec.Decode(&r); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode archive %s: %w", key, err)
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+}
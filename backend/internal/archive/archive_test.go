@@ -0,0 +1,332 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webtracker-bot/internal/database/db"
+)
+
+// fakeSource serves shipments from memory and removes them on purge.
+type fakeSource struct {
+	shipments    []db.Shipment
+	events       []db.ShipmentEvent
+	customs      []db.CustomsDeclaration
+	customsItems []db.CustomsItem
+	attempts     []db.DeliveryAttempt
+	purgeErr     error
+}
+
+func (f *fakeSource) AgedShipments(ctx context.Context, companyID uuid.UUID, deliveredOlderThan, createdOlderThan time.Time, limit int32) ([]db.Shipment, error) {
+	if int(limit) < len(f.shipments) {
+		return f.shipments[:limit], nil
+	}
+	return f.shipments, nil
+}
+
+func (f *fakeSource) ExpiredTrash(ctx context.Context, companyID uuid.UUID, deletedBefore time.Time, limit int32) ([]db.Shipment, error) {
+	var out []db.Shipment
+	for _, s := range f.shipments {
+		if s.DeletedAt.Valid && s.DeletedAt.Time.Before(deletedBefore) && len(out) < int(limit) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSource) EventsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentEvent, error) {
+	return f.events, nil
+}
+
+func (f *fakeSource) PiecesForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentPiece, error) {
+	return nil, nil
+}
+
+func (f *fakeSource) CustomsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.CustomsDeclaration, []db.CustomsItem, error) {
+	return f.customs, f.customsItems, nil
+}
+
+func (f *fakeSource) ChangesForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentChange, error) {
+	return nil, nil
+}
+
+func (f *fakeSource) AttemptsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.DeliveryAttempt, error) {
+	return f.attempts, nil
+}
+
+func (f *fakeSource) CheckpointsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentCheckpoint, error) {
+	return nil, nil
+}
+
+func (f *fakeSource) PurgeShipments(ctx context.Context, companyID uuid.UUID, ids []string) (int64, error) {
+	if f.purgeErr != nil {
+		return 0, f.purgeErr
+	}
+	gone := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		gone[id] = true
+	}
+	kept := f.shipments[:0]
+	for _, s := range f.shipments {
+		if !gone[s.TrackingID] {
+			kept = append(kept, s)
+		}
+	}
+	purged := int64(len(f.shipments) - len(kept))
+	f.shipments = kept
+	return purged, nil
+}
+
+func shipmentAt(id string, created time.Time) db.Shipment {
+	return db.Shipment{
+		TrackingID: id,
+		Status:     sql.NullString{String: "delivered", Valid: true},
+		CreatedAt:  sql.NullTime{Time: created, Valid: true},
+	}
+}
+
+func readAll(t *testing.T, store Storage, key string) []Record {
+	var out []Record
+	require.NoError(t, Read(context.Background(), store, key, func(r Record) error {
+		out = append(out, r)
+		return nil
+	}))
+	return out
+}
+
+func TestArchiveAged(t *testing.T) {
+	ctx := context.Background()
+	companyID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	jan := time.Date(2026, 1, 20, 10, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+
+	store := NewLocalStorage(t.TempDir())
+	src := &fakeSource{
+		shipments: []db.Shipment{shipmentAt("AWB-1", jan), shipmentAt("AWB-2", feb)},
+		events:    []db.ShipmentEvent{{TrackingID: "AWB-1", Status: "delivered"}},
+		customs:   []db.CustomsDeclaration{{TrackingID: "AWB-2", Currency: "USD", ExportReason: "gift"}},
+		customsItems: []db.CustomsItem{
+			{TrackingID: "AWB-2", LineNo: 1, Description: "Shoes", HsCode: "6403", Quantity: 2, UnitValue: 40},
+		},
+		attempts: []db.DeliveryAttempt{{TrackingID: "AWB-1", AttemptNo: 1, Reason: "no_one_home"}},
+	}
+	a := NewArchiver(store, src)
+
+	purged, err := a.ArchiveAged(ctx, companyID, time.Now(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.Empty(t, src.shipments)
+
+	objects, err := store.List(ctx, companyID.String())
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, PartitionKey(companyID, jan), objects[0].Key)
+	assert.Equal(t, companyID.String()+"/2026-02.jsonl.gz", objects[1].Key)
+
+	janRecords := readAll(t, store, objects[0].Key)
+	require.Len(t, janRecords, 1)
+	assert.Equal(t, "AWB-1", janRecords[0].Shipment.TrackingID)
+	assert.Equal(t, "delivered", janRecords[0].Shipment.Status.String)
+	require.Len(t, janRecords[0].Events, 1)
+	require.Len(t, janRecords[0].Attempts, 1)
+	assert.Nil(t, janRecords[0].Customs)
+
+	febRecords := readAll(t, store, objects[1].Key)
+	require.Len(t, febRecords, 1)
+	require.NotNil(t, febRecords[0].Customs)
+	assert.Equal(t, "USD", febRecords[0].Customs.Currency)
+	require.Len(t, febRecords[0].CustomsItems, 1)
+	assert.Equal(t, "6403", febRecords[0].CustomsItems[0].HsCode)
+
+	// A second night appends a new gzip member to the same month.
+	src.shipments = []db.Shipment{shipmentAt("AWB-3", jan.AddDate(0, 0, 5))}
+	_, err = a.ArchiveAged(ctx, companyID, time.Now(), time.Now())
+	require.NoError(t, err)
+	janRecords = readAll(t, store, objects[0].Key)
+	require.Len(t, janRecords, 2)
+	assert.Equal(t, "AWB-3", janRecords[1].Shipment.TrackingID)
+	assert.Empty(t, janRecords[1].Events)
+}
+
+func TestArchiveTrash(t *testing.T) {
+	ctx := context.Background()
+	companyID := uuid.New()
+	created := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC)
+	cutoff := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	expired := shipmentAt("AWB-T1", created)
+	expired.DeletedAt = sql.NullTime{Time: cutoff.AddDate(0, 0, -3), Valid: true}
+	recent := shipmentAt("AWB-T2", created)
+	recent.DeletedAt = sql.NullTime{Time: cutoff.AddDate(0, 0, 3), Valid: true}
+	live := shipmentAt("AWB-T3", created)
+
+	store := NewLocalStorage(t.TempDir())
+	src := &fakeSource{shipments: []db.Shipment{expired, recent, live}}
+	purged, err := NewArchiver(store, src).ArchiveTrash(ctx, companyID, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	require.Len(t, src.shipments, 2)
+
+	records := readAll(t, store, PartitionKey(companyID, created))
+	require.Len(t, records, 1)
+	assert.Equal(t, "AWB-T1", records[0].Shipment.TrackingID)
+	assert.True(t, records[0].Shipment.DeletedAt.Valid)
+}
+
+func TestArchiveAged_PurgeFailureKeepsArchive(t *testing.T) {
+	ctx := context.Background()
+	companyID := uuid.New()
+	store := NewLocalStorage(t.TempDir())
+	src := &fakeSource{
+		shipments: []db.Shipment{shipmentAt("AWB-9", time.Now())},
+		purgeErr:  errors.New("db down"),
+	}
+
+	_, err := NewArchiver(store, src).ArchiveAged(ctx, companyID, time.Now(), time.Now())
+	assert.Error(t, err)
+	assert.Len(t, src.shipments, 1)
+
+	objects, err := store.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, objects, 1)
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	store := NewLocalStorage(t.TempDir())
+	for _, key := range []string{"", "../x.jsonl.gz", "a/../../x", "/abs"} {
+		_, err := store.Append(context.Background(), key)
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
+
+// restoreQuerier records what RestoreRecord inserts. The embedded Querier
+// is nil, so any other query panics.
+type restoreQuerier struct {
+	db.Querier
+	created   []db.CreateShipmentParams
+	states    []db.RestoreShipmentStateParams
+	customs   []db.RestoreCustomsDeclarationParams
+	items     []db.InsertCustomsItemParams
+	attempts  []db.RestoreDeliveryAttemptParams
+	createErr error
+}
+
+func (q *restoreQuerier) CreateShipment(ctx context.Context, arg db.CreateShipmentParams) error {
+	q.created = append(q.created, arg)
+	return q.createErr
+}
+
+func (q *restoreQuerier) RestoreShipmentState(ctx context.Context, arg db.RestoreShipmentStateParams) error {
+	q.states = append(q.states, arg)
+	return nil
+}
+
+func (q *restoreQuerier) RestoreShipmentEvent(ctx context.Context, arg db.RestoreShipmentEventParams) error {
+	return nil
+}
+
+func (q *restoreQuerier) InsertShipmentPiece(ctx context.Context, arg db.InsertShipmentPieceParams) error {
+	return nil
+}
+
+func (q *restoreQuerier) RestoreCustomsDeclaration(ctx context.Context, arg db.RestoreCustomsDeclarationParams) error {
+	q.customs = append(q.customs, arg)
+	return nil
+}
+
+func (q *restoreQuerier) InsertCustomsItem(ctx context.Context, arg db.InsertCustomsItemParams) error {
+	q.items = append(q.items, arg)
+	return nil
+}
+
+func (q *restoreQuerier) RestoreShipmentChange(ctx context.Context, arg db.RestoreShipmentChangeParams) error {
+	return nil
+}
+
+func (q *restoreQuerier) RestoreDeliveryAttempt(ctx context.Context, arg db.RestoreDeliveryAttemptParams) error {
+	q.attempts = append(q.attempts, arg)
+	return nil
+}
+
+func (q *restoreQuerier) RestoreCheckpoint(ctx context.Context, arg db.RestoreCheckpointParams) error {
+	return nil
+}
+
+func TestRestoreRecord_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	companyID := uuid.New()
+	created := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	ret := shipmentAt("AWB-R1", created)
+	ret.CustomFields = json.RawMessage(`{"po":"PO-77"}`)
+	ret.Tags = []string{"fragile", "vip"}
+	ret.Version = 7
+	ret.DeletedAt = sql.NullTime{Time: created.Add(time.Hour), Valid: true}
+	ret.DeletedBy = sql.NullString{String: "admin", Valid: true}
+	ret.ReturnOf = sql.NullString{String: "AWB-1", Valid: true}
+	ret.ReturnReason = sql.NullString{String: "damaged", Valid: true}
+	ret.ReturnID = sql.NullString{String: "AWB-R2", Valid: true}
+	legacy := shipmentAt("AWB-OLD", created) // archived before custom fields and tags
+
+	store := NewLocalStorage(t.TempDir())
+	a := NewArchiver(store, &fakeSource{})
+	require.NoError(t, a.Write(ctx, companyID, []Record{
+		{
+			Shipment:     ret,
+			Customs:      &db.CustomsDeclaration{TrackingID: "AWB-R1", Currency: "EUR", ExportReason: "return"},
+			CustomsItems: []db.CustomsItem{{TrackingID: "AWB-R1", LineNo: 1, Description: "Jacket", HsCode: "6201", Quantity: 1, UnitValue: 80}},
+			Attempts:     []db.DeliveryAttempt{{TrackingID: "AWB-R1", AttemptNo: 1, Reason: "refused"}},
+		},
+		{Shipment: legacy},
+	}))
+
+	q := &restoreQuerier{}
+	for _, r := range readAll(t, store, PartitionKey(companyID, created)) {
+		require.NoError(t, RestoreRecord(ctx, q, r))
+	}
+
+	require.Len(t, q.created, 2)
+	got := q.created[0]
+	assert.JSONEq(t, `{"po":"PO-77"}`, string(got.CustomFields))
+	assert.Equal(t, []string{"fragile", "vip"}, got.Tags)
+	assert.Equal(t, "AWB-1", got.ReturnOf.String)
+	assert.Equal(t, "damaged", got.ReturnReason.String)
+	assert.False(t, q.created[1].ReturnOf.Valid)
+
+	assert.JSONEq(t, `{}`, string(q.created[1].CustomFields))
+	assert.NotNil(t, q.created[1].Tags)
+	assert.Empty(t, q.created[1].Tags)
+
+	// The trashed one goes back to the trash at its version; the legacy one
+	// starts at version 1
+	require.Len(t, q.states, 2)
+	assert.Equal(t, int32(7), q.states[0].Version)
+	assert.True(t, q.states[0].DeletedAt.Time.Equal(created.Add(time.Hour)))
+	assert.Equal(t, "admin", q.states[0].DeletedBy.String)
+	assert.Equal(t, "AWB-R2", q.states[0].ReturnID.String)
+	assert.Equal(t, int32(1), q.states[1].Version)
+	assert.False(t, q.states[1].DeletedAt.Valid)
+	assert.False(t, q.states[1].ReturnID.Valid)
+
+	require.Len(t, q.customs, 1)
+	assert.Equal(t, "EUR", q.customs[0].Currency)
+	require.Len(t, q.items, 1)
+	assert.Equal(t, "6201", q.items[0].HsCode)
+	require.Len(t, q.attempts, 1)
+	assert.Equal(t, "refused", q.attempts[0].Reason)
+}
+
+func TestRestoreRecord_Exists(t *testing.T) {
+	q := &restoreQuerier{createErr: &pq.Error{Code: "23505"}}
+	err := RestoreRecord(context.Background(), q, Record{Shipment: shipmentAt("AWB-1", time.Now())})
+	assert.ErrorIs(t, err, ErrExists)
+}
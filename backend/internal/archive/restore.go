@@ -0,0 +1,205 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+)
+
+// ErrExists is returned when the archived shipment is already in the database.
+var ErrExists = errors.New("shipment already exists")
+
+// RestoreRecord re-inserts an archived shipment with the rows archived alongside it.
+// Run it inside a transaction so a failure leaves nothing half-restored.
+// Shipments come back as they were archived: a trashed one returns to the
+// trash, its version carries on so stale edits are still refused, and a
+// returned one keeps the link to its return.
+func RestoreRecord(ctx context.Context, q db.Querier, r Record) error {
+	s := r.Shipment
+	// Archives written before these columns existed carry neither; the
+	// columns are NOT NULL.
+	customFields := s.CustomFields
+	if len(customFields) == 0 || string(customFields) == "null" {
+		customFields = json.RawMessage(`{}`)
+	}
+	tags := s.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	err := q.CreateShipment(ctx, db.CreateShipmentParams{
+		CompanyID:            s.CompanyID,
+		TrackingID:           s.TrackingID,
+		UserJid:              s.UserJid,
+		Status:               s.Status,
+		CreatedAt:            s.CreatedAt,
+		ScheduledTransitTime: s.ScheduledTransitTime,
+		OutfordeliveryTime:   s.OutfordeliveryTime,
+		ExpectedDeliveryTime: s.ExpectedDeliveryTime,
+		SenderTimezone:       s.SenderTimezone,
+		RecipientTimezone:    s.RecipientTimezone,
+		SenderName:           s.SenderName,
+		SenderPhone:          s.SenderPhone,
+		Origin:               s.Origin,
+		RecipientName:        s.RecipientName,
+		RecipientPhone:       s.RecipientPhone,
+		RecipientEmail:       s.RecipientEmail,
+		RecipientID:          s.RecipientID,
+		RecipientAddress:     s.RecipientAddress,
+		Destination:          s.Destination,
+		CargoType:            s.CargoType,
+		Weight:               s.Weight,
+		Cost:                 s.Cost,
+		UpdatedAt:            s.UpdatedAt,
+		ServiceLevel:         s.ServiceLevel,
+		CodAmount:            s.CodAmount,
+		CodCurrency:          s.CodCurrency,
+		CustomFields:         customFields,
+		Tags:                 tags,
+		ReturnOf:             s.ReturnOf,
+		ReturnReason:         s.ReturnReason,
+	})
+	if err != nil {
+		if dbutil.IsUniqueViolation(err) {
+			return ErrExists
+		}
+		return fmt.Errorf("failed to restore shipment %s: %w", s.TrackingID, err)
+	}
+	version := s.Version
+	if version == 0 {
+		// Archived before versions existed
+		version = 1
+	}
+	err = q.RestoreShipmentState(ctx, db.RestoreShipmentStateParams{
+		CompanyID:  s.CompanyID,
+		TrackingID: s.TrackingID,
+		Version:    version,
+		DeletedAt:  s.DeletedAt,
+		DeletedBy:  s.DeletedBy,
+		ReturnID:   s.ReturnID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore state of %s: %w", s.TrackingID, err)
+	}
+
+	for _, e := range r.Events {
+		err := q.RestoreShipmentEvent(ctx, db.RestoreShipmentEventParams{
+			CompanyID:      e.CompanyID,
+			TrackingID:     e.TrackingID,
+			Status:         e.Status,
+			PreviousStatus: e.PreviousStatus,
+			Source:         e.Source,
+			Actor:          e.Actor,
+			Description:    e.Description,
+			CreatedAt:      e.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore events of %s: %w", s.TrackingID, err)
+		}
+	}
+
+	for _, p := range r.Pieces {
+		err := q.InsertShipmentPiece(ctx, db.InsertShipmentPieceParams{
+			CompanyID:   p.CompanyID,
+			TrackingID:  p.TrackingID,
+			PieceNo:     p.PieceNo,
+			Weight:      p.Weight,
+			LengthCm:    p.LengthCm,
+			WidthCm:     p.WidthCm,
+			HeightCm:    p.HeightCm,
+			Description: p.Description,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore pieces of %s: %w", s.TrackingID, err)
+		}
+	}
+
+	if c := r.Customs; c != nil {
+		err := q.RestoreCustomsDeclaration(ctx, db.RestoreCustomsDeclarationParams{
+			TrackingID:    c.TrackingID,
+			CompanyID:     c.CompanyID,
+			Currency:      c.Currency,
+			ExportReason:  c.ExportReason,
+			InvoiceNumber: c.InvoiceNumber,
+			CreatedAt:     c.CreatedAt,
+			UpdatedAt:     c.UpdatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore customs of %s: %w", s.TrackingID, err)
+		}
+		for _, it := range r.CustomsItems {
+			err := q.InsertCustomsItem(ctx, db.InsertCustomsItemParams{
+				CompanyID:     it.CompanyID,
+				TrackingID:    it.TrackingID,
+				LineNo:        it.LineNo,
+				Description:   it.Description,
+				HsCode:        it.HsCode,
+				Quantity:      it.Quantity,
+				UnitValue:     it.UnitValue,
+				Weight:        it.Weight,
+				OriginCountry: it.OriginCountry,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to restore customs items of %s: %w", s.TrackingID, err)
+			}
+		}
+	}
+
+	for _, c := range r.Changes {
+		err := q.RestoreShipmentChange(ctx, db.RestoreShipmentChangeParams{
+			CompanyID:  c.CompanyID,
+			TrackingID: c.TrackingID,
+			BatchID:    c.BatchID,
+			Field:      c.Field,
+			OldValue:   c.OldValue,
+			NewValue:   c.NewValue,
+			Source:     c.Source,
+			Actor:      c.Actor,
+			IsUndo:     c.IsUndo,
+			RevertedAt: c.RevertedAt,
+			CreatedAt:  c.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore changes of %s: %w", s.TrackingID, err)
+		}
+	}
+
+	for _, a := range r.Attempts {
+		err := q.RestoreDeliveryAttempt(ctx, db.RestoreDeliveryAttemptParams{
+			CompanyID:     a.CompanyID,
+			TrackingID:    a.TrackingID,
+			AttemptNo:     a.AttemptNo,
+			Reason:        a.Reason,
+			Note:          a.Note,
+			Source:        a.Source,
+			Actor:         a.Actor,
+			NextAttemptAt: a.NextAttemptAt,
+			CreatedAt:     a.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore delivery attempts of %s: %w", s.TrackingID, err)
+		}
+	}
+
+	for _, c := range r.Checkpoints {
+		err := q.RestoreCheckpoint(ctx, db.RestoreCheckpointParams{
+			CompanyID:  c.CompanyID,
+			TrackingID: c.TrackingID,
+			Latitude:   c.Latitude,
+			Longitude:  c.Longitude,
+			AccuracyM:  c.AccuracyM,
+			Label:      c.Label,
+			Live:       c.Live,
+			Source:     c.Source,
+			Actor:      c.Actor,
+			CreatedAt:  c.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore checkpoints of %s: %w", s.TrackingID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidKey is returned for keys that are empty or escape the storage root.
+var ErrInvalidKey = errors.New("invalid archive key")
+
+// Object describes a stored archive file.
+type Object struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Storage persists archive files under slash-separated keys such as
+// "<company_id>/2026-01.jsonl.gz".
+type Storage interface {
+	// Append opens key for appending, creating it if needed.
+	Append(ctx context.Context, key string) (io.WriteCloser, error)
+	// Open opens key for reading.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// List returns every object whose key starts with prefix, sorted by key.
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// LocalStorage keeps archives on the local filesystem under Root.
+type LocalStorage struct {
+	Root string
+}
+
+// NewLocalStorage creates a filesystem-backed Storage rooted at dir.
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{Root: dir}
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if key == "" || clean == "" || clean != key {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(clean)), nil
+}
+
+// Append opens key in append mode. Close flushes the file to disk.
+func (s *LocalStorage) Append(ctx context.Context, key string) (io.WriteCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create archive dir: %w", err)
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	return syncFile{f}, nil
+}
+
+// Open opens key for reading.
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// List walks the storage root and returns objects whose key has the prefix.
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(s.Root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, Object{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// syncFile fsyncs before closing so an archive is durable before the rows
+// it holds are deleted from the database.
+type syncFile struct {
+	*os.File
+}
+
+func (f syncFile) Close() error {
+	if err := f.File.Sync(); err != nil {
+		f.File.Close()
+		return err
+	}
+	return f.File.Close()
+}
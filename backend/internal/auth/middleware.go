@@ -30,7 +30,8 @@ func JWTAuth(publicKeyPath string) fiber.Handler {
 			path == "/api/auth/forgot-password" ||
 			path == "/api/auth/reset-password" ||
 			path == "/api/billing/plans" ||
-			strings.HasPrefix(path, "/api/webhooks/") {
+			strings.HasPrefix(path, "/api/webhooks/") ||
+			strings.HasPrefix(path, "/api/track/") {
 			return c.Next()
 		}
 
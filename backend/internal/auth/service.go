@@ -3,11 +3,9 @@ package auth
 import (
 	"context"
 	"crypto/ecdsa"
-	"crypto/rand"
 	"database/sql"
 	"errors"
 	"fmt"
-	"math/big"
 	"os"
 	"strings"
 	"time"
@@ -20,6 +18,7 @@ import (
 	"webtracker-bot/internal/database/db"
 	"webtracker-bot/internal/logger"
 	"webtracker-bot/internal/notif"
+	"webtracker-bot/internal/trackingid"
 	"webtracker-bot/internal/utils"
 )
 
@@ -69,25 +68,19 @@ func (s *Service) GenerateOTP(ctx context.Context, companyName, email, password
 	}
 
 	// Generate 6 digit OTP securely
-	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	otp, hashedOTP, err := utils.NewOTP()
 	if err != nil {
-		return "", fmt.Errorf("failed to generate secure OTP: %w", err)
+		return "", err
 	}
-	otp := fmt.Sprintf("%06d", n.Int64())
 	logger.Info().Str("email", email).Msg("Generated OTP, sending verification email")
 	s.mailer.SendAsync(notif.OTPEmail(email, otp))
 
-	// Hash password and OTP
+	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	hashedOTP, err := bcrypt.GenerateFromPassword([]byte(otp), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash OTP: %w", err)
-	}
-
 	// Stateless registration: No DB storage for pending intent.
 	// All necessary data is carried in the signed OTP token.
 
@@ -122,8 +115,7 @@ func (s *Service) VerifyOTP(ctx context.Context, otp string, otpToken string) (*
 
 	// Verify OTP
 	logger.Info().Str("email", claims.Email).Msg("Verifying Stateless OTP")
-	err = bcrypt.CompareHashAndPassword([]byte(claims.HashedOTP), []byte(otp))
-	if err != nil {
+	if !utils.MatchOTP(claims.HashedOTP, otp) {
 		return nil, "", errors.New("incorrect OTP code")
 	}
 
@@ -170,6 +162,9 @@ func (s *Service) SetupCompany(ctx context.Context, companyID uuid.UUID, req Set
 	if prefix == "" {
 		prefix = utils.GenerateAbbreviation(company.Name.String)
 	}
+	if !trackingid.ValidPrefix(prefix) {
+		return nil, "", errors.New("tracking prefix must be 1-5 letters or digits, starting with a letter")
+	}
 
 	err := s.queries.UpdateCompanyOnboarding(ctx, db.UpdateCompanyOnboardingParams{
 		ID:             companyID,
@@ -237,11 +232,10 @@ func (s *Service) InitiatePasswordReset(ctx context.Context, email string) (stri
 	userExists := err == nil && company.ID != uuid.Nil
 
 	// Generate 6 digit OTP securely
-	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	otp, hashedOTP, err := utils.NewOTP()
 	if err != nil {
-		return "", fmt.Errorf("failed to generate secure OTP: %w", err)
+		return "", err
 	}
-	otp := fmt.Sprintf("%06d", n.Int64())
 
 	// Only send the email if the account actually exists
 	if userExists {
@@ -251,11 +245,6 @@ func (s *Service) InitiatePasswordReset(ctx context.Context, email string) (stri
 		logger.Info().Str("email", email).Msg("Password reset requested for non-existent email — returning dummy token")
 	}
 
-	hashedOTP, err := bcrypt.GenerateFromPassword([]byte(otp), bcrypt.DefaultCost)
-	if err != nil {
-		return "", fmt.Errorf("failed to hash OTP: %w", err)
-	}
-
 	// Create Stateless JWT Token
 	claims := ResetPasswordClaims{
 		Email:     email,
@@ -289,8 +278,7 @@ func (s *Service) CompletePasswordReset(ctx context.Context, req ResetPasswordRe
 	// Verify OTP
 	otpCode := strings.TrimSpace(req.OTP)
 	logger.Info().Int("len", len(otpCode)).Msg("Comparing Reset OTP")
-	err = bcrypt.CompareHashAndPassword([]byte(claims.HashedOTP), []byte(otpCode))
-	if err != nil {
+	if !utils.MatchOTP(claims.HashedOTP, otpCode) {
 		return errors.New("incorrect reset code")
 	}
 
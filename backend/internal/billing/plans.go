@@ -21,13 +21,26 @@ type Plan struct {
 	BtnKey       string   `json:"btn_key"`
 	Features     []string `json:"features"`      // Translation keys
 	MaxShipments int64    `json:"max_shipments"`
+
+	// Data retention: default days kept after delivery / after creation,
+	// and the longest retention a tenant on this plan may choose.
+	RetentionDeliveredDays int `json:"retention_delivered_days"`
+	RetentionCreatedDays   int `json:"retention_created_days"`
+	MaxRetentionDays       int `json:"max_retention_days"`
 }
 
+// MinRetentionDays is the shortest retention any plan may choose.
+const MinRetentionDays = 1
+
 var (
 	PlanTrial = Plan{
 		ID:           "trial",
 		Name:         "Trial",
 		MaxShipments: 50,
+
+		RetentionDeliveredDays: 7,
+		RetentionCreatedDays:   14,
+		MaxRetentionDays:       30,
 	}
 	PlanStarter = Plan{
 		ID:           "starter",
@@ -42,6 +55,10 @@ var (
 		BtnKey:       "btnStartTrial",
 		Features:     []string{"feat_50_shipments", "feat_whatsapp", "feat_web_portal", "feat_manual_entry", "feat_community"},
 		MaxShipments: 50,
+
+		RetentionDeliveredDays: 7,
+		RetentionCreatedDays:   14,
+		MaxRetentionDays:       90,
 	}
 	PlanPro = Plan{
 		ID:           "pro",
@@ -55,6 +72,10 @@ var (
 		BtnKey:       "btnUpgradePro",
 		Features:     []string{"feat_250_shipments", "feat_whatsapp", "feat_ai_parser", "feat_csv_upload", "feat_custom_branding", "feat_priority_support"},
 		MaxShipments: 250,
+
+		RetentionDeliveredDays: 30,
+		RetentionCreatedDays:   60,
+		MaxRetentionDays:       180,
 	}
 	PlanScale = Plan{
 		ID:           "enterprise",
@@ -68,6 +89,10 @@ var (
 		BtnKey:       "btnContactSales",
 		Features:     []string{"feat_1000_shipments", "feat_all_pro", "feat_api_webhook", "feat_dedicated_whatsapp", "feat_247_support"},
 		MaxShipments: 1000,
+
+		RetentionDeliveredDays: 90,
+		RetentionCreatedDays:   180,
+		MaxRetentionDays:       730,
 	}
 )
 
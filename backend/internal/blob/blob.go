@@ -0,0 +1,102 @@
+// Package blob stores binary uploads such as proof-of-delivery photos. The
+// rest of the app only sees the Storage interface, so the local disk can be
+// swapped for an object store without touching callers.
+package blob
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrInvalidKey is returned for keys that are empty or escape the storage root.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Storage persists blobs under slash-separated keys such as
+// "<company_id>/pod/<uuid>.jpg".
+type Storage interface {
+	// Put writes data under key, replacing anything already there.
+	Put(ctx context.Context, key string, data []byte) error
+	// Open opens key for reading. A missing key returns an error wrapping
+	// fs.ErrNotExist.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalStorage keeps blobs on the local filesystem under Root.
+type LocalStorage struct {
+	Root string
+}
+
+// NewLocalStorage creates a filesystem-backed Storage rooted at dir.
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{Root: dir}
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if key == "" || clean == "" || clean != key {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(clean)), nil
+}
+
+// Put writes data to a temporary file and renames it into place, so a
+// reader never sees a half-written blob.
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return fmt.Errorf("failed to create blob dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, bytes.NewReader(data)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+	return nil
+}
+
+// Open opens key for reading.
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// Delete removes key from disk.
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
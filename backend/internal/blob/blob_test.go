@@ -0,0 +1,40 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_PutOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStorage(t.TempDir())
+	key := "c1/pod/AWB-1/a.jpg"
+
+	require.NoError(t, store.Put(ctx, key, []byte("first")))
+	require.NoError(t, store.Put(ctx, key, []byte("second")))
+
+	r, err := store.Open(ctx, key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	require.NoError(t, store.Delete(ctx, key))
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Open(ctx, key)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	store := NewLocalStorage(t.TempDir())
+	for _, key := range []string{"", "../x.jpg", "a/../../x", "/abs"} {
+		err := store.Put(context.Background(), key, []byte("x"))
+		assert.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+)
+
+// AssignHandler handles !assign [trackingID] [rider name or phone]
+type AssignHandler struct {
+	Sender models.WhatsAppSender
+}
+
+func (h *AssignHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) < 2 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_ASSIGN_USAGE")}
+	}
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+	ref := strings.Join(args[1:], " ")
+
+	if strings.EqualFold(ref, "none") {
+		err := shipUC.UnassignRider(ctx, companyID, trackingID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+		}
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_RIDER_UNASSIGNED", trackingID)}
+	}
+
+	rider, err := shipUC.FindRider(ctx, companyID, ref)
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, shipment.ErrRiderInactive):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_RIDER_NOT_FOUND", ref)}
+	case errors.Is(err, shipment.ErrInvalidRider):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_ASSIGNMENT", err.Error())}
+	case err != nil:
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	rider, err = shipUC.AssignRider(ctx, companyID, trackingID, rider.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NOT_FOUND")}
+	case errors.Is(err, shipment.ErrInvalidAssignment), errors.Is(err, shipment.ErrRiderInactive):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_ASSIGNMENT", err.Error())}
+	case err != nil:
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	// Brief the rider in their own chat
+	if ship, _ := shipUC.Track(ctx, companyID, trackingID); ship != nil && h.Sender != nil {
+		riderJID := rider.ChatJID()
+		riderLang, _ := configUC.GetUserLanguage(ctx, companyID, riderJID.String())
+		h.Sender.Send(riderJID, i18n.T(i18nLang(riderLang), "MSG_RIDER_NEW_JOB", trackingID,
+			ship.RecipientName.String, ship.RecipientPhone.String, ship.RecipientAddress.String, ship.Destination.String))
+		logger.Info().Str("tracking_id", trackingID).Int32("rider_id", rider.ID).Msg("Rider assigned")
+	}
+
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_RIDER_ASSIGNED", trackingID, rider.Name, rider.Phone)}
+}
@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/notif"
+	"webtracker-bot/internal/shipment"
+)
+
+// BagHandler handles !bag, the consolidations (master AWBs) shipments are
+// grouped into per flight:
+//
+//	!bag                        - recent bags
+//	!bag [MAWB]                 - one bag and what's in it
+//	!bag create [label]         - open a new bag
+//	!bag add [MAWB] [ID] ...    - put shipments in an open bag
+//	!bag remove [MAWB] [ID]     - take one out again
+//	!bag close [MAWB]           - seal it
+//	!bag depart [MAWB]          - everything in it goes in transit
+//	!bag status [MAWB] [status] - move everything in a departed bag
+type BagHandler struct {
+	CompanyPrefix string
+	CompanyName   string
+	Sender        models.WhatsAppSender
+	Cfg           *config.Config
+}
+
+func (h *BagHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) == 0 {
+		return h.list(ctx, shipUC, companyID, lang)
+	}
+
+	sub := strings.ToLower(args[0])
+	switch sub {
+	case "create":
+		c, err := shipUC.CreateConsolidation(ctx, companyID, h.CompanyPrefix, strings.Join(args[1:], " "))
+		if err != nil {
+			return bagError(lang, "", err)
+		}
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_CREATED", c.MasterAWB, bagLabel(c))}
+	case "add", "remove", "close", "depart", "status":
+	default:
+		if len(args) > 1 {
+			return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_USAGE")}
+		}
+		c, err := shipUC.Consolidation(ctx, companyID, args[0])
+		if err != nil {
+			return bagError(lang, args[0], err)
+		}
+		return Result{Message: bagSummary(lang, c)}
+	}
+
+	if len(args) < 2 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_USAGE")}
+	}
+	masterAWB := shipment.ParseMasterAWB(args[1])
+	rest := args[2:]
+
+	switch sub {
+	case "add":
+		if len(rest) == 0 {
+			return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_USAGE")}
+		}
+		ids := make([]string, 0, len(rest))
+		for _, raw := range rest {
+			id, res := parseTrackingID(ctx, shipUC, companyID, raw, lang)
+			if res != nil {
+				return *res
+			}
+			ids = append(ids, id)
+		}
+		c, err := shipUC.AddToConsolidation(ctx, companyID, masterAWB, ids)
+		if err != nil {
+			return bagError(lang, masterAWB, err)
+		}
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_ADDED", len(ids), c.MasterAWB, c.ShipmentCount)}
+
+	case "remove":
+		if len(rest) != 1 {
+			return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_USAGE")}
+		}
+		id, res := parseTrackingID(ctx, shipUC, companyID, rest[0], lang)
+		if res != nil {
+			return *res
+		}
+		err := shipUC.RemoveFromConsolidation(ctx, companyID, masterAWB, id)
+		if errors.Is(err, shipment.ErrNotConsolidated) {
+			return Result{Message: i18n.T(i18nLang(lang), "ERR_NOT_IN_BAG", id, masterAWB)}
+		}
+		if err != nil {
+			return bagError(lang, masterAWB, err)
+		}
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_REMOVED", id, masterAWB)}
+
+	case "close":
+		c, err := shipUC.CloseConsolidation(ctx, companyID, masterAWB)
+		if err != nil {
+			return bagError(lang, masterAWB, err)
+		}
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_CLOSED", c.MasterAWB, c.ShipmentCount)}
+
+	case "depart":
+		res, err := shipUC.DepartConsolidation(ctx, companyID, masterAWB)
+		if err != nil {
+			return bagError(lang, masterAWB, err)
+		}
+		return h.moved(lang, res)
+
+	default: // status
+		if len(rest) != 1 {
+			return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_USAGE")}
+		}
+		res, err := shipUC.SetConsolidationStatus(ctx, companyID, masterAWB, strings.ToLower(rest[0]))
+		if err != nil {
+			return bagError(lang, masterAWB, err)
+		}
+		return h.moved(lang, res)
+	}
+}
+
+func (h *BagHandler) list(ctx context.Context, shipUC models.ShipmentUsecase, companyID uuid.UUID, lang string) Result {
+	bags, err := shipUC.ListConsolidations(ctx, companyID, 10)
+	if err != nil {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+	if len(bags) == 0 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_LIST_EMPTY")}
+	}
+	lines := make([]string, 0, len(bags))
+	for i := range bags {
+		lines = append(lines, fmt.Sprintf("🧳 *%s* · %s · %d%s", bags[i].MasterAWB, bagState(&bags[i]), bags[i].ShipmentCount, bagLabel(&bags[i])))
+	}
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_BAG_LIST", strings.Join(lines, "\n"))}
+}
+
+// moved alerts the customer of every shipment a status change on the bag
+// moved, as if each had been updated on its own. A full bag takes a while,
+// so the alerts go out in the background and the reply doesn't wait.
+func (h *BagHandler) moved(lang string, res *models.ConsolidationResult) Result {
+	if h.Sender != nil && h.Sender.GetWAClient() != nil && len(res.Moved) > 0 {
+		wa, cfg, companyName, moved := h.Sender.GetWAClient(), h.Cfg, h.CompanyName, res.Moved
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+			for _, ship := range moved {
+				notif.SendStatusAlert(ctx, wa, cfg, companyName, ship.UserJid, ship.TrackingID, ship.Status.String, ship.RecipientEmail.String, shipment.FormatCOD(ship.CodAmount, ship.CodCurrency.String))
+			}
+		}()
+	}
+
+	msg := i18n.T(i18nLang(lang), "MSG_BAG_MOVED", res.Consolidation.MasterAWB, strings.ToUpper(res.Consolidation.Status), len(res.Moved))
+	if len(res.Skipped) > 0 {
+		lines := make([]string, 0, len(res.Skipped))
+		for _, s := range res.Skipped {
+			lines = append(lines, "• "+s.TrackingID+": "+s.Reason)
+		}
+		msg += "\n\n" + i18n.T(i18nLang(lang), "MSG_BAG_SKIPPED", strings.Join(lines, "\n"))
+	}
+	return Result{Message: msg}
+}
+
+func bagSummary(lang string, c *models.Consolidation) string {
+	ids := "—"
+	if len(c.TrackingIDs) > 0 {
+		ids = "📦 " + strings.Join(c.TrackingIDs, "\n📦 ")
+	}
+	return i18n.T(i18nLang(lang), "MSG_BAG_INFO", c.MasterAWB, bagLabel(c), bagState(c), c.ShipmentCount, ids)
+}
+
+func bagState(c *models.Consolidation) string {
+	if c.Status != "" {
+		return strings.ToUpper(c.State + " / " + c.Status)
+	}
+	return strings.ToUpper(c.State)
+}
+
+func bagLabel(c *models.Consolidation) string {
+	if c.Label == "" {
+		return ""
+	}
+	return " · _" + c.Label + "_"
+}
+
+// bagError renders the failures the !bag subcommands share.
+func bagError(lang, masterAWB string, err error) Result {
+	var te *shipment.TransitionError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_BAG_NOT_FOUND", shipment.ParseMasterAWB(masterAWB))}
+	case errors.Is(err, shipment.ErrInvalidConsolidation), errors.Is(err, shipment.ErrAlreadyConsolidated):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_BAG", err.Error())}
+	case errors.Is(err, shipment.ErrVersionConflict):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_EDIT_CONFLICT", masterAWB)}
+	case errors.As(err, &te):
+		return Result{Message: transitionMessage(lang, te)}
+	}
+	return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+)
+
+// CodHandler handles !cod (summary) and !cod collect [ID] [amount]
+type CodHandler struct{}
+
+func (h *CodHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) > 0 {
+		if !strings.EqualFold(args[0], "collect") || len(args) < 2 {
+			return Result{Message: i18n.T(i18nLang(lang), "MSG_COD_USAGE")}
+		}
+		return h.collect(ctx, shipUC, companyID, args[1:], lang)
+	}
+
+	summary, err := shipUC.CodSummary(ctx, companyID)
+	if err != nil {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+	if len(summary.Balances) == 0 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_COD_EMPTY")}
+	}
+
+	var b strings.Builder
+	b.WriteString(i18n.T(i18nLang(lang), "MSG_COD_HEADER"))
+	for _, bal := range summary.Balances {
+		b.WriteString("\n\n")
+		b.WriteString(i18n.T(i18nLang(lang), "MSG_COD_BALANCE",
+			currencyLabel(bal.Currency),
+			shipment.FormatMoney(bal.Collected, ""),
+			shipment.FormatMoney(bal.Remitted, ""),
+			shipment.FormatMoney(bal.Adjusted, ""),
+			shipment.FormatMoney(bal.Owed, ""),
+			shipment.FormatMoney(bal.Uncollected, ""), bal.UncollectedCount,
+			shipment.FormatMoney(bal.Pending, ""), bal.PendingCount))
+	}
+	return Result{Message: b.String()}
+}
+
+func (h *CodHandler) collect(ctx context.Context, shipUC models.ShipmentUsecase, companyID uuid.UUID, args []string, lang string) Result {
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+	var amount float64
+	if len(args) > 1 {
+		v, err := strconv.ParseFloat(strings.ReplaceAll(args[1], ",", ""), 64)
+		if err != nil || v <= 0 {
+			return Result{Message: i18n.T(i18nLang(lang), "MSG_COD_USAGE")}
+		}
+		amount = v
+	}
+
+	entry, err := shipUC.CollectCOD(ctx, companyID, trackingID, amount, "")
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Result{Message: "❌ *NOT FOUND*\nCould not find a shipment with that ID."}
+	case errors.Is(err, shipment.ErrNoCOD):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NO_COD", trackingID)}
+	case errors.Is(err, shipment.ErrCODCollected):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_COD_COLLECTED", trackingID)}
+	case err != nil:
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_COD_COLLECTED", trackingID, shipment.FormatMoney(entry.Amount, entry.Currency))}
+}
+
+func currencyLabel(currency string) string {
+	if currency == "" {
+		return "—"
+	}
+	return currency
+}
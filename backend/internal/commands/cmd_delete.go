@@ -2,8 +2,9 @@ package commands
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/google/uuid"
 
@@ -25,13 +26,19 @@ func (h *DeleteHandler) Execute(ctx context.Context, shipUC models.ShipmentUseca
 			return Result{Message: "🗑️ *DELETE SHIPMENT*\n\nUsage: `!delete [TrackingID]`"}
 		}
 	} else {
-		trackingID = strings.ToUpper(args[0])
+		var res *Result
+		if trackingID, res = parseTrackingID(ctx, shipUC, companyID, args[0], lang); res != nil {
+			return *res
+		}
 	}
 
 	err := shipUC.Delete(ctx, companyID, trackingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Result{Message: "❌ *NOT FOUND*\nCould not find a shipment with that ID."}
+	}
 	if err != nil {
 		return Result{Message: fmt.Sprintf("❌ *DELETE FAILED*\n_%v_", err)}
 	}
 
-	return Result{Message: fmt.Sprintf("🗑️ *SHIPMENT DELETED*\n\nThe shipment *%s* has been permanently removed.", trackingID)}
+	return Result{Message: fmt.Sprintf("🗑️ *SHIPMENT DELETED*\n\nThe shipment *%s* has been moved to the trash.\n_Undo with_ `!restore %s`", trackingID, trackingID)}
 }
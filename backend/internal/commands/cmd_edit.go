@@ -2,8 +2,9 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"webtracker-bot/internal/notif"
 	"webtracker-bot/internal/parser"
 	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/trackingid"
 	"webtracker-bot/internal/utils"
 )
 
@@ -33,14 +35,18 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 	}
 
 	jid := utils.GetJID(ctx)
+	// Everything this command changes, auto-syncs included, is undone together
+	ctx = utils.WithChangeBatch(ctx, uuid.NewString())
 	var trackingID string
 	var startIdx int
 
 	// 1. Identify Target Shipment
-	// Pattern: 3 uppercase letters, hyphen, 4 digits
-	idPattern := regexp.MustCompile(`^[A-Z]{3}-\d{4,9}$`)
-	if idPattern.MatchString(args[0]) {
-		trackingID = args[0]
+	// An explicit ID comes first; anything else targets the user's last shipment
+	if trackingid.Looks(args[0]) {
+		var res *Result
+		if trackingID, res = parseTrackingID(ctx, shipUC, companyID, args[0], lang); res != nil {
+			return *res
+		}
 		startIdx = 1
 	} else {
 		// Contextual Lookup: Fetch last shipment for this user
@@ -54,6 +60,15 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 
 	// 2. Parse Updates
 	updateText := strings.Join(args[startIdx:], " ")
+
+	// Custom fields and tags come out first, so "order number: 12" isn't taken for a phone
+	defs, _ := shipUC.CustomFields(ctx, companyID)
+	extras, updateText := parser.ExtractLabeled(updateText, extraLabels(defs))
+	if len(extras) == 0 && len(args[startIdx:]) >= 2 && isExtraLabel(defs, args[startIdx]) {
+		// Old style: !edit order_number 12
+		extras[strings.ToLower(args[startIdx])] = strings.Join(args[startIdx+1:], " ")
+		updateText = ""
+	}
 	updates := parser.ParseEditPairs(updateText)
 
 	// Fallback for single field (e.g., !edit name Mark) if parser didn't find clear anchors
@@ -89,21 +104,28 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 			dbField = "cargo_type"
 		case "weight":
 			dbField = "weight"
+		case "status", "state":
+			dbField = "status"
 		}
 		if dbField != "" {
 			updates[dbField] = value
 		}
 	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && len(extras) == 0 {
 		return Result{Message: "⚠️ *NO UPDATES FOUND*\n_Please specify what you want to change (e.g., 'name: John' or 'departure: tomorrow')._"}
 	}
 
 	// 3. Apply Updates
 	var updatedFields []string
+	var transitionErr *shipment.TransitionError
+	var extrasErr error
+	conflicted := false
+	otpRequired := false
 	departureUpdated := false
 	var newDeparture time.Time
 	arrivalExplicitlyUpdated := false
+	pricingChanged := false
 
 	for field, value := range updates {
 		// Strict Policy: Weight is fixed
@@ -118,6 +140,9 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 		if strings.Contains(field, "phone") && !parser.ValidatePhone(value) {
 			continue
 		}
+		if field == "status" {
+			value = strings.ToLower(strings.TrimSpace(value))
+		}
 
 		// Special Date Parsing
 		if field == "scheduled_transit_time" || field == "expected_delivery_time" || field == "outfordelivery_time" {
@@ -153,6 +178,28 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 		err := shipUC.UpdateField(ctx, companyID, trackingID, field, value)
 		if err == nil {
 			updatedFields = append(updatedFields, strings.ToUpper(strings.ReplaceAll(field, "_", " ")))
+			if field == "origin" || field == "destination" || field == "cargo_type" {
+				pricingChanged = true
+			}
+		} else if errors.Is(err, shipment.ErrVersionConflict) {
+			conflicted = true
+		} else if errors.Is(err, shipment.ErrOTPRequired) {
+			otpRequired = true
+		} else {
+			errors.As(err, &transitionErr)
+		}
+	}
+
+	// Custom fields and tags are saved in one write
+	if len(extras) > 0 {
+		labels, err := applyExtras(ctx, shipUC, companyID, trackingID, defs, extras)
+		switch {
+		case err == nil:
+			updatedFields = append(updatedFields, labels...)
+		case errors.Is(err, shipment.ErrVersionConflict):
+			conflicted = true
+		case errors.Is(err, shipment.ErrInvalidCustomField), errors.Is(err, shipment.ErrInvalidTag):
+			extrasErr = err
 		}
 	}
 
@@ -160,8 +207,9 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 	if departureUpdated && !arrivalExplicitlyUpdated {
 		dbShip, _ := shipUC.Track(ctx, companyID, trackingID)
 		if dbShip != nil {
-			// Recalculate Arrival based on new Departure
-			arrival, outForDelivery := shipUC.GetService().CalculateArrival(newDeparture, dbShip.Origin.String, dbShip.Destination.String)
+			// Recalculate Arrival based on new Departure, using the shipment's service level
+			scheduler := shipUC.ServiceFor(ctx, companyID, dbShip.ServiceLevel.String)
+			arrival, outForDelivery := scheduler.CalculateArrival(newDeparture, dbShip.Origin.String, dbShip.Destination.String)
 
 			_ = shipUC.UpdateField(ctx, companyID, trackingID, "expected_delivery_time", arrival.Format("2006-01-02 15:04:05"))
 			_ = shipUC.UpdateField(ctx, companyID, trackingID, "outfordelivery_time", outForDelivery.Format("2006-01-02 15:04:05"))
@@ -170,13 +218,37 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 		}
 	}
 
+	// Re-price when the lane or cargo changed (no-op without a rate card)
+	if pricingChanged {
+		if _, changed, err := shipUC.RecalculateCost(ctx, companyID, trackingID); err == nil && changed {
+			updatedFields = append(updatedFields, "COST (AUTO-SYNC)")
+		}
+	}
+
+	if transitionErr != nil && len(updatedFields) == 0 {
+		return Result{Message: transitionMessage(lang, transitionErr)}
+	}
+
+	if extrasErr != nil && len(updatedFields) == 0 {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_CUSTOM_FIELD", extrasErr.Error())}
+	}
+
+	if conflicted && len(updatedFields) == 0 {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_EDIT_CONFLICT", trackingID)}
+	}
+
+	if otpRequired && len(updatedFields) == 0 {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_OTP_REQUIRED", trackingID, trackingID)}
+	}
+
 	if len(updatedFields) == 0 {
 		return Result{Message: "⚠️ *UPDATE FAILED*\n_None of the fields could be updated. Check your format (e.g., label: value)._"}
 	}
 
-	// 4. Persistence & Schedule Sync
+	// 4. Persistence & Schedule Sync (skipped when the status was set explicitly)
+	_, statusExplicit := updates["status"]
 	dbShip, _ := shipUC.Track(ctx, companyID, trackingID)
-	if dbShip != nil {
+	if dbShip != nil && !statusExplicit {
 		// Resolve status
 		s := shipment.Shipment{
 			Status: dbShip.Status.String,
@@ -193,20 +265,87 @@ func (h *EditHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 
 		newStatus := s.ResolveStatus(time.Now().UTC())
 		if newStatus != dbShip.Status.String {
-			_ = shipUC.UpdateField(ctx, companyID, trackingID, "status", newStatus)
+			// Only against the version resolved from, so a concurrent override wins
+			err := shipUC.UpdateFieldAt(ctx, companyID, trackingID, "status", newStatus, dbShip.Version)
 
 			// If it transitions, optionally trigger the notification explicitly!
-			if h.Sender != nil && h.Sender.GetWAClient() != nil {
-				notif.SendStatusAlert(ctx, h.Sender.GetWAClient(), h.Cfg, h.CompanyName, dbShip.UserJid, trackingID, newStatus, dbShip.RecipientEmail.String)
+			if err == nil && h.Sender != nil && h.Sender.GetWAClient() != nil {
+				notif.SendStatusAlert(ctx, h.Sender.GetWAClient(), h.Cfg, h.CompanyName, dbShip.UserJid, trackingID, newStatus, dbShip.RecipientEmail.String, shipment.FormatCOD(dbShip.CodAmount, dbShip.CodCurrency.String))
 			}
 		}
 	}
 
 	summary := fmt.Sprintf("✅ *INFORMATION UPDATED*\n\n🆔 *%s*\n\n📝 *FIELDS MODIFIED:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Updates have been successfully persisted to the cloud._",
 		trackingID, strings.Join(updatedFields, "\n• "))
+	if transitionErr != nil {
+		summary += "\n\n" + transitionMessage(lang, transitionErr)
+	}
+	if extrasErr != nil {
+		summary += "\n\n" + i18n.T(i18nLang(lang), "ERR_INVALID_CUSTOM_FIELD", extrasErr.Error())
+	}
+	if otpRequired {
+		summary += "\n\n" + i18n.T(i18nLang(lang), "ERR_OTP_REQUIRED", trackingID, trackingID)
+	}
 
 	return Result{
 		Message: summary,
 		EditID:  trackingID,
 	}
 }
+
+// transitionMessage renders a state machine rejection in the user's language
+func transitionMessage(lang string, te *shipment.TransitionError) string {
+	allowed := strings.Join(te.Allowed(), ", ")
+	if allowed == "" {
+		allowed = "—"
+	}
+	return i18n.T(i18nLang(lang), "ERR_INVALID_TRANSITION", te.From, te.To, allowed)
+}
+
+// extraLabels lists what !edit accepts for custom fields and tags.
+func extraLabels(defs []models.CustomField) []string {
+	labels := []string{"tags", "tag"}
+	for _, f := range defs {
+		labels = append(labels, f.Name, strings.ReplaceAll(f.Name, "_", " "))
+		if f.Label != "" {
+			labels = append(labels, f.Label)
+		}
+	}
+	return labels
+}
+
+func isExtraLabel(defs []models.CustomField, label string) bool {
+	label = strings.ToLower(label)
+	if label == "tags" || label == "tag" {
+		return true
+	}
+	_, ok := shipment.FindCustomField(defs, label)
+	return ok
+}
+
+// applyExtras saves custom field and tag edits, returning the labels of what changed.
+func applyExtras(ctx context.Context, shipUC models.ShipmentUsecase, companyID uuid.UUID, trackingID string, defs []models.CustomField, extras map[string]string) ([]string, error) {
+	fields := make(map[string]any)
+	var tags []string
+	var labels []string
+	for label, value := range extras {
+		if label == "tags" || label == "tag" {
+			current, err := shipUC.Track(ctx, companyID, trackingID)
+			if err != nil {
+				return nil, err
+			}
+			tags = append([]string{}, shipment.EditTags(current.Tags, value)...)
+			labels = append(labels, "TAGS")
+			continue
+		}
+		if f, ok := shipment.FindCustomField(defs, label); ok {
+			fields[f.Name] = value
+			labels = append(labels, strings.ToUpper(f.DisplayName()))
+		}
+	}
+	if err := shipUC.UpdateExtras(ctx, companyID, trackingID, fields, tags, 0); err != nil {
+		return nil, err
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
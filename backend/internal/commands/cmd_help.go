@@ -14,6 +14,7 @@ import (
 type HelpHandler struct {
 	CompanyName   string
 	CompanyPrefix string
+	IsRider       bool
 }
 
 func (h *HelpHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
@@ -29,14 +30,37 @@ func (h *HelpHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 			"📊 `!stats` - Today's operations\n" +
 			"🌡️ `!status` - System health & vitals\n" +
 			"✏️ `!edit [ID] [updates]` - Update shipment\n" +
+			"↩️ `!undo` - Revert your last edit\n" +
 			"🗑️ `!delete [ID]` - Remove shipment\n" +
+			"♻️ `!restore [ID]` - Restore deleted shipment\n" +
+			"🔁 `!return [ID] [reason]` - Send back to sender\n" +
 			"📦 `!info [ID]` - Detailed waybill\n" +
+			"💰 `!quote [origin] [dest] [kg]` - Price estimate\n" +
+			"🧾 `!invoice [ID]` - Commercial invoice (PDF)\n" +
+			"💵 `!cod` - Cash-on-delivery balance (`!cod collect [ID]`)\n" +
+			"🛵 `!assign [ID] [rider]` - Give to a dispatch rider\n" +
+			"📍 `!checkin [ID]` - Pin your next shared location to it\n" +
+			"📸 `!pod [ID]` - Photo caption: proof of delivery\n" +
+			"🧳 `!bag` - Consolidation bags per flight (`!bag create`, `add`, `close`, `depart`)\n" +
 			"🌐 `!lang [en|pt|es|de]` - Switch language\n" +
 			"━━━━━━━━━━━━━━━━━━━━━━━\n" +
 			"_Use these commands strictly within the authorized groups._"
 		return Result{Message: msg}
 	}
 
+	if h.IsRider {
+		msg := fmt.Sprintf("🛵 *%s RIDER COMMANDS*\n\n", company) +
+			"━━━━━━━━━━━━━━━━━━━━━━━\n" +
+			"🚚 `!pickup` - Take out everything assigned to you (`!pickup [ID]` for one)\n" +
+			"✅ `!delivered [ID] [code]` - Handed over to the recipient (code only if they were sent one)\n" +
+			"🚪 `!failed [ID] [reason] [note]` - Could not deliver\n" +
+			"📍 `!checkin [ID]` - Then share your location (or reply to a receipt with it)\n" +
+			"📸 `!pod [ID]` - As a photo caption: proof of delivery (or reply to a receipt with the photo)\n" +
+			"━━━━━━━━━━━━━━━━━━━━━━━\n" +
+			"_Reasons: no_one_home, wrong_address, refused, business_closed, other._"
+		return Result{Message: msg}
+	}
+
 	// Customer Help Menu as Plain Text
 	msg := fmt.Sprintf("📖 *%s CUSTOMER SERVICE*\n\n", company) +
 		"How can we help you today?\n\n" +
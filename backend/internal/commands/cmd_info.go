@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"webtracker-bot/internal/geo"
 	"webtracker-bot/internal/i18n"
 	"webtracker-bot/internal/models"
 	"webtracker-bot/internal/receipt"
@@ -47,7 +48,10 @@ func (h *InfoHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 			return Result{Message: msg}
 		}
 	} else {
-		trackingID = strings.ToUpper(args[0])
+		var res *Result
+		if trackingID, res = parseTrackingID(ctx, shipUC, companyID, args[0], lang); res != nil {
+			return *res
+		}
 	}
 
 	dbShip, err := shipUC.Track(ctx, companyID, trackingID)
@@ -61,7 +65,14 @@ func (h *InfoHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase
 
 	// Map DB model to Domain model for waybill generation
 	s := shipment.ToDomain(*dbShip)
+	s.Pieces, _ = shipUC.ListPieces(ctx, companyID, trackingID)
+	s.LastCheckpoint, _ = shipUC.LastCheckpoint(ctx, companyID, trackingID)
 
 	wb := receipt.GenerateWaybill(s, h.CompanyName)
-	return Result{Message: "```\n" + wb + "\n```"}
+	msg := "```\n" + wb + "\n```"
+	if cp := s.LastCheckpoint; cp != nil {
+		// Links don't work inside the code block
+		msg += "\n📍 " + geo.MapURL(cp.Latitude, cp.Longitude)
+	}
+	return Result{Message: msg}
 }
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/receipt"
+	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/utils"
+)
+
+// InvoiceHandler handles !invoice [ID]
+type InvoiceHandler struct {
+	Sender models.WhatsAppSender
+}
+
+func (h *InvoiceHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	var trackingID string
+	if len(args) < 1 {
+		var err error
+		trackingID, err = shipUC.GetLastForUser(ctx, companyID, utils.GetJID(ctx))
+		if err != nil || trackingID == "" {
+			return Result{Message: "🧾 *COMMERCIAL INVOICE*\n\nUsage: `!invoice [TrackingID]`"}
+		}
+	} else {
+		var res *Result
+		if trackingID, res = parseTrackingID(ctx, shipUC, companyID, args[0], lang); res != nil {
+			return *res
+		}
+	}
+
+	dbShip, err := shipUC.Track(ctx, companyID, trackingID)
+	if err != nil || dbShip == nil {
+		return Result{Message: "❌ *NOT FOUND*\nCould not find a shipment with that ID."}
+	}
+
+	decl, err := shipUC.Customs(ctx, companyID, trackingID)
+	if errors.Is(err, shipment.ErrNoCustoms) {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NO_CUSTOMS", trackingID)}
+	}
+	if err != nil {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	s := shipment.ToDomain(*dbShip)
+	s.Pieces, _ = shipUC.ListPieces(ctx, companyID, trackingID)
+
+	pdf, err := receipt.RenderInvoice(s, *decl, h.Sender.GetCompanyName())
+	if err != nil {
+		return Result{Message: "❌ *RENDER FAILED*", Error: err}
+	}
+
+	return Result{
+		Message:      i18n.T(i18nLang(lang), "MSG_INVOICE_CAPTION", trackingID),
+		Document:     pdf,
+		DocumentName: "invoice-" + trackingID + ".pdf",
+	}
+}
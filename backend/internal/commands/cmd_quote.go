@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+)
+
+// QuoteHandler handles !quote [origin] [destination] [kg]
+type QuoteHandler struct{}
+
+func (h *QuoteHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	origin, destination, weight, ok := parseQuoteArgs(args)
+	if !ok {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_QUOTE_USAGE")}
+	}
+
+	q, err := shipUC.Quote(ctx, companyID, models.QuoteRequest{Origin: origin, Destination: destination, Weight: weight})
+	switch {
+	case errors.Is(err, shipment.ErrNoRateCard):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NO_RATE_CARD")}
+	case errors.Is(err, shipment.ErrNoRate):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NO_RATE", origin, destination)}
+	case err != nil:
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_QUOTE",
+		q.Origin, q.Destination, q.ChargeableWeight,
+		formatAmount(q.Base, q.Currency), formatAmount(q.FuelSurcharge, q.Currency), formatAmount(q.Total, q.Currency))}
+}
+
+// parseQuoteArgs reads "<origin> <destination> <kg>". Multi-word countries are
+// separated with "to" (e.g. !quote South Africa to United Kingdom 5kg).
+func parseQuoteArgs(args []string) (string, string, float64, bool) {
+	if len(args) < 3 {
+		return "", "", 0, false
+	}
+	weight, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(args[len(args)-1]), "kg"), 64)
+	if err != nil || weight <= 0 {
+		return "", "", 0, false
+	}
+
+	places := args[:len(args)-1]
+	for i, a := range places {
+		if strings.EqualFold(a, "to") && i > 0 && i < len(places)-1 {
+			return strings.Join(places[:i], " "), strings.Join(places[i+1:], " "), weight, true
+		}
+	}
+	return places[0], strings.Join(places[1:], " "), weight, true
+}
+
+func formatAmount(v float64, currency string) string {
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", v, currency))
+}
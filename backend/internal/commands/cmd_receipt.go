@@ -2,7 +2,6 @@ package commands
 
 import (
 	"context"
-	"strings"
 
 	"github.com/google/uuid"
 
@@ -33,7 +32,10 @@ func (h *ReceiptHandler) Execute(ctx context.Context, shipUC models.ShipmentUsec
 			return Result{Message: "🧾 *RECEIPT REGENERATION*\n\nUsage: `!receipt [TrackingID]`"}
 		}
 	} else {
-		trackingID = strings.ToUpper(args[0])
+		var res *Result
+		if trackingID, res = parseTrackingID(ctx, shipUC, companyID, args[0], lang); res != nil {
+			return *res
+		}
 	}
 
 	// Fetch shipment to get JID
@@ -44,6 +46,7 @@ func (h *ReceiptHandler) Execute(ctx context.Context, shipUC models.ShipmentUsec
 
 	// Map to Domain Model for Rendering
 	s := shipment.ToDomain(*dbShip)
+	s.Pieces, _ = shipUC.ListPieces(ctx, companyID, trackingID)
 
 	// Render synchronous
 	receiptImg, err := receipt.RenderReceipt(s, h.Sender.GetCompanyName(), i18n.Language(lang))
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+)
+
+// RestoreHandler handles !restore [trackingID]
+type RestoreHandler struct{}
+
+func (h *RestoreHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) < 1 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_RESTORE_USAGE")}
+	}
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+
+	err := shipUC.Restore(ctx, companyID, trackingID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NOT_IN_TRASH", trackingID)}
+	case err != nil:
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_SHIPMENT_RESTORED", trackingID)}
+}
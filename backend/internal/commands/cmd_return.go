@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/notif"
+	"webtracker-bot/internal/shipment"
+)
+
+// ReturnHandler handles !return [trackingID] [reason]
+type ReturnHandler struct {
+	CompanyPrefix string
+	Sender        models.WhatsAppSender
+	Cfg           *config.Config
+}
+
+func (h *ReturnHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) < 2 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_RETURN_USAGE")}
+	}
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+	reason := strings.Join(args[1:], " ")
+
+	returnID, err := shipUC.CreateReturn(ctx, companyID, trackingID, reason, h.CompanyPrefix, 0)
+	var te *shipment.TransitionError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NOT_FOUND")}
+	case errors.Is(err, shipment.ErrAlreadyReturned):
+		existing := ""
+		if orig, _ := shipUC.Track(ctx, companyID, trackingID); orig != nil {
+			existing = orig.ReturnID.String
+		}
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_ALREADY_RETURNED", trackingID, existing)}
+	case errors.Is(err, shipment.ErrInvalidReturn):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_RETURN", err.Error())}
+	case errors.Is(err, shipment.ErrVersionConflict):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_EDIT_CONFLICT", trackingID)}
+	case errors.As(err, &te):
+		return Result{Message: transitionMessage(lang, te)}
+	case err != nil:
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	if orig, _ := shipUC.Track(ctx, companyID, trackingID); orig != nil && h.Sender != nil && h.Sender.GetWAClient() != nil {
+		notif.SendReturnAlert(ctx, h.Sender.GetWAClient(), h.Cfg, orig.UserJid, trackingID, returnID, orig.ReturnReason.String)
+	}
+
+	// EditID makes the worker send the return's receipt
+	return Result{
+		Message: i18n.T(i18nLang(lang), "MSG_SHIPMENT_RETURNED", trackingID, returnID, strings.TrimSpace(reason)),
+		EditID:  returnID,
+	}
+}
@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/notif"
+	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/utils"
+)
+
+// riderCommand is shared by the commands a rider may run from their private
+// chat. Rider is nil when an admin runs them, which lifts the check that the
+// shipment is assigned to the sender.
+type riderCommand struct {
+	Rider       *models.Rider
+	CompanyName string
+	Sender      models.WhatsAppSender
+	Cfg         *config.Config
+}
+
+// statusAlert sends the customer the same alert as any other status change.
+func (h *riderCommand) statusAlert(ctx context.Context, ship *db.Shipment) {
+	if h.Sender == nil || h.Sender.GetWAClient() == nil {
+		return
+	}
+	notif.SendStatusAlert(ctx, h.Sender.GetWAClient(), h.Cfg, h.CompanyName, ship.UserJid, ship.TrackingID, ship.Status.String, ship.RecipientEmail.String, shipment.FormatCOD(ship.CodAmount, ship.CodCurrency.String))
+}
+
+// riderError renders the failures the rider commands share.
+func riderError(lang, trackingID string, err error) Result {
+	var te *shipment.TransitionError
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NOT_FOUND")}
+	case errors.Is(err, shipment.ErrNotAssigned):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_NOT_ASSIGNED", trackingID)}
+	case errors.Is(err, shipment.ErrVersionConflict):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_EDIT_CONFLICT", trackingID)}
+	case errors.Is(err, shipment.ErrOTPRequired):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_OTP_REQUIRED", trackingID, trackingID)}
+	case errors.Is(err, shipment.ErrWrongOTP):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_WRONG_OTP", trackingID)}
+	case errors.Is(err, shipment.ErrOTPLocked):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_OTP_LOCKED", trackingID)}
+	case errors.As(err, &te):
+		return Result{Message: transitionMessage(lang, te)}
+	}
+	return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+}
+
+// PickupHandler handles !pickup [trackingID]
+type PickupHandler struct{ riderCommand }
+
+func (h *PickupHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	trackingID := ""
+	if len(args) > 0 {
+		id, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+		if res != nil {
+			return *res
+		}
+		trackingID = id
+	} else if h.Rider == nil {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_PICKUP_USAGE")}
+	}
+
+	moved, err := shipUC.RiderPickup(ctx, companyID, h.Rider, trackingID)
+	if err != nil {
+		return riderError(lang, trackingID, err)
+	}
+	if len(moved) == 0 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_NOTHING_TO_PICK_UP")}
+	}
+
+	lines := make([]string, 0, len(moved))
+	for i := range moved {
+		h.statusAlert(ctx, &moved[i])
+		lines = append(lines, "📦 "+moved[i].TrackingID+" · "+moved[i].RecipientName.String)
+	}
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_PICKED_UP", strings.Join(lines, "\n"))}
+}
+
+// DeliveredHandler handles !delivered [trackingID] [code]; the code is only
+// needed for shipments whose recipient was sent one
+type DeliveredHandler struct{ riderCommand }
+
+func (h *DeliveredHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) < 1 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_DELIVERED_USAGE")}
+	}
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+
+	code := ""
+	if len(args) > 1 {
+		code = args[1]
+	}
+
+	ship, err := shipUC.RiderDeliver(ctx, companyID, h.Rider, trackingID, code)
+	if err != nil {
+		return riderError(lang, trackingID, err)
+	}
+	h.statusAlert(ctx, ship)
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_MARKED_DELIVERED", trackingID)}
+}
+
+// CheckinHandler handles !checkin [trackingID]: the sender's next
+// location shares are pinned to the shipment
+type CheckinHandler struct{ riderCommand }
+
+func (h *CheckinHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) < 1 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_CHECKIN_USAGE")}
+	}
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+
+	if err := shipUC.Checkin(ctx, companyID, h.Rider, utils.GetSenderPhone(ctx), trackingID); err != nil {
+		return riderError(lang, trackingID, err)
+	}
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_CHECKIN_OPEN", shipment.CheckinMinutes, trackingID)}
+}
+
+// PODHandler handles !pod [trackingID] as the caption of a photo, which is
+// saved as the shipment's proof of delivery. The worker also routes photos
+// sent in reply to a receipt here.
+type PODHandler struct {
+	riderCommand
+	Photo *waE2E.ImageMessage
+}
+
+func (h *PODHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) < 1 || h.Photo == nil {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_POD_USAGE")}
+	}
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+	if h.Photo.GetFileLength() > shipment.MaxPODBytes {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_POD", fmt.Sprintf("%v: the photo is larger than %d MB", shipment.ErrInvalidPOD, shipment.MaxPODBytes>>20))}
+	}
+	if h.Sender == nil || h.Sender.GetWAClient() == nil {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: errors.New("no WhatsApp client to download the photo")}
+	}
+	data, err := h.Sender.GetWAClient().Download(ctx, h.Photo)
+	if err != nil {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: fmt.Errorf("failed to download photo: %w", err)}
+	}
+
+	pod, err := shipUC.RecordPOD(ctx, companyID, h.Rider, trackingID, shipment.PODPhoto, data)
+	switch {
+	case errors.Is(err, shipment.ErrInvalidPOD):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_POD", err.Error())}
+	case errors.Is(err, shipment.ErrPODDisabled):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_POD_DISABLED"), Error: err}
+	case err != nil:
+		return riderError(lang, trackingID, err)
+	}
+
+	if !pod.Delivered {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_POD_ADDED", trackingID)}
+	}
+	notif.SendPODAlert(ctx, h.Sender.GetWAClient(), h.Cfg, h.CompanyName, &pod.Shipment, &notif.InlineImage{ContentType: pod.POD.ContentType, Data: data})
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_POD_DELIVERED", trackingID)}
+}
+
+// FailedHandler handles !failed [trackingID] [reason] [note]
+type FailedHandler struct{ riderCommand }
+
+func (h *FailedHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	if len(args) < 2 {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_FAILED_USAGE", strings.Join(shipment.AttemptReasons, ", "))}
+	}
+	trackingID, res := parseTrackingID(ctx, shipUC, companyID, args[0], lang)
+	if res != nil {
+		return *res
+	}
+	reason := strings.ReplaceAll(args[1], "-", "_")
+	note := strings.Join(args[2:], " ")
+
+	attempt, err := shipUC.RiderFailed(ctx, companyID, h.Rider, trackingID, reason, note)
+	if errors.Is(err, shipment.ErrInvalidAttempt) {
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_ATTEMPT", err.Error())}
+	}
+	if err != nil {
+		return riderError(lang, trackingID, err)
+	}
+
+	if h.Sender != nil && h.Sender.GetWAClient() != nil {
+		customerLang, _ := configUC.GetUserLanguage(ctx, companyID, attempt.UserJID)
+		notif.SendAttemptAlert(ctx, h.Sender.GetWAClient(), h.Cfg, customerLang, trackingID, attempt)
+	}
+
+	if attempt.Final {
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_ATTEMPT_FINAL_RECORDED", attempt.Attempt.AttemptNo, attempt.MaxAttempts, trackingID)}
+	}
+	loc, err := time.LoadLocation(attempt.RecipientTimezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return Result{Message: i18n.T(i18nLang(lang), "MSG_ATTEMPT_RECORDED", attempt.Attempt.AttemptNo, attempt.MaxAttempts, trackingID, attempt.Delivery.In(loc).Format(i18n.GetDateFormat(i18nLang(lang))))}
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/utils"
+)
+
+// UndoHandler handles !undo, reverting the caller's last edit
+type UndoHandler struct{}
+
+func (h *UndoHandler) Execute(ctx context.Context, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, companyID uuid.UUID, args []string, lang string, isAdmin bool) Result {
+	_, actor := utils.GetSource(ctx)
+	trackingID, changes, err := shipUC.UndoLastEdit(ctx, companyID, actor)
+	var te *shipment.TransitionError
+	switch {
+	case errors.Is(err, shipment.ErrNothingToUndo):
+		return Result{Message: i18n.T(i18nLang(lang), "MSG_NOTHING_TO_UNDO")}
+	case errors.Is(err, shipment.ErrUndoStale):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_UNDO_STALE", trackingID)}
+	case errors.Is(err, shipment.ErrVersionConflict):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_EDIT_CONFLICT", trackingID)}
+	case errors.Is(err, shipment.ErrOTPRequired):
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_OTP_REQUIRED", trackingID, trackingID)}
+	case errors.As(err, &te):
+		return Result{Message: transitionMessage(lang, te)}
+	case err != nil:
+		return Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+
+	var fields []string
+	for _, c := range changes {
+		name := strings.ToUpper(strings.ReplaceAll(c.Field, "_", " "))
+		if !slices.Contains(fields, name) {
+			fields = append(fields, name)
+		}
+	}
+
+	return Result{
+		Message: i18n.T(i18nLang(lang), "MSG_UNDO_DONE", trackingID, strings.Join(fields, "\n• ")),
+		EditID:  trackingID,
+	}
+}
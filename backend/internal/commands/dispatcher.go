@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,15 +10,33 @@ import (
 	"webtracker-bot/internal/i18n"
 	"webtracker-bot/internal/logger"
 	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/trackingid"
 	"webtracker-bot/internal/utils"
 
 	"github.com/google/uuid"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
 )
 
 func i18nLang(s string) i18n.Language {
 	return i18n.Language(strings.ToLower(s))
 }
 
+// parseTrackingID validates a tracking ID argument against the company's
+// format. On failure it returns the reply to send instead.
+func parseTrackingID(ctx context.Context, shipUC models.ShipmentUsecase, companyID uuid.UUID, raw, lang string) (string, *Result) {
+	id, err := shipUC.ParseTrackingID(ctx, companyID, raw)
+	switch {
+	case errors.Is(err, trackingid.ErrInvalid):
+		return "", &Result{Message: i18n.T(i18nLang(lang), "ERR_INVALID_TRACKING_ID", raw)}
+	case errors.Is(err, trackingid.ErrCheckDigit):
+		return "", &Result{Message: i18n.T(i18nLang(lang), "ERR_CHECK_DIGIT", strings.ToUpper(raw))}
+	case err != nil:
+		return "", &Result{Message: i18n.T(i18nLang(lang), "ERR_SYSTEM_ERROR"), Error: err}
+	}
+	return id, nil
+}
+
 // Result represents the outcome of a command execution.
 type Result struct {
 	Message  string
@@ -25,6 +44,10 @@ type Result struct {
 	EditID   string
 	Image    []byte
 	Error    error
+
+	// Document is sent as a file attachment (with Message as its caption).
+	Document     []byte
+	DocumentName string
 }
 
 type Handler interface {
@@ -42,6 +65,8 @@ type Dispatcher struct {
 	Tier          string
 	BotPhone      string
 	AdminTimezone string
+	// Photo is the image the command was the caption of, if any (!pod)
+	Photo *waE2E.ImageMessage
 }
 
 func NewDispatcher(cfg *config.Config, shipUC models.ShipmentUsecase, configUC models.ConfigUsecase, sender models.WhatsAppSender, awbCmd string, companyName string, botPhone string, adminTimezone string, tier string) *Dispatcher {
@@ -67,9 +92,22 @@ func (d *Dispatcher) registerDefaults() {
 	d.handlers["help"] = &HelpHandler{}
 	d.handlers["lang"] = &LangHandler{}
 	d.handlers["edit"] = &EditHandler{}
+	d.handlers["undo"] = &UndoHandler{}
 	d.handlers["delete"] = &DeleteHandler{}
+	d.handlers["restore"] = &RestoreHandler{}
+	d.handlers["return"] = &ReturnHandler{}
 	d.handlers["status"] = &StatusHandler{}
 	d.handlers["receipt"] = &ReceiptHandler{}
+	d.handlers["quote"] = &QuoteHandler{}
+	d.handlers["invoice"] = &InvoiceHandler{}
+	d.handlers["cod"] = &CodHandler{}
+	d.handlers["assign"] = &AssignHandler{}
+	d.handlers["pickup"] = &PickupHandler{}
+	d.handlers["delivered"] = &DeliveredHandler{}
+	d.handlers["failed"] = &FailedHandler{}
+	d.handlers["checkin"] = &CheckinHandler{}
+	d.handlers["pod"] = &PODHandler{}
+	d.handlers["bag"] = &BagHandler{}
 }
 
 func (d *Dispatcher) Dispatch(ctx context.Context, companyID uuid.UUID, text string) (*Result, bool) {
@@ -114,10 +152,30 @@ func (d *Dispatcher) Dispatch(ctx context.Context, companyID uuid.UUID, text str
 			h.AdminTimezone = d.AdminTimezone
 			h.Sender = d.sender
 			h.Cfg = d.cfg
+		case *ReturnHandler:
+			h.CompanyPrefix = d.AwbCmd
+			h.Sender = d.sender
+			h.Cfg = d.cfg
 		case *StatusHandler:
 			h.BotPhone = d.BotPhone
 		case *ReceiptHandler:
 			h.Sender = d.sender
+		case *InvoiceHandler:
+			h.Sender = d.sender
+		case *AssignHandler:
+			h.Sender = d.sender
+		case *PickupHandler:
+			h.CompanyName, h.Sender, h.Cfg = d.CompanyName, d.sender, d.cfg
+		case *DeliveredHandler:
+			h.CompanyName, h.Sender, h.Cfg = d.CompanyName, d.sender, d.cfg
+		case *FailedHandler:
+			h.CompanyName, h.Sender, h.Cfg = d.CompanyName, d.sender, d.cfg
+		case *CheckinHandler:
+			h.CompanyName, h.Sender, h.Cfg = d.CompanyName, d.sender, d.cfg
+		case *PODHandler:
+			h.CompanyName, h.Sender, h.Cfg = d.CompanyName, d.sender, d.cfg
+		case *BagHandler:
+			h.CompanyPrefix, h.CompanyName, h.Sender, h.Cfg = d.AwbCmd, d.CompanyName, d.sender, d.cfg
 		}
 
 		lang, _ := d.configUC.GetUserLanguage(ctx, companyID, jid)
@@ -130,8 +188,50 @@ func (d *Dispatcher) Dispatch(ctx context.Context, companyID uuid.UUID, text str
 			}
 		}
 
+		// Riders run their own commands from their private chat; admins run
+		// them for any shipment
+		isRiderCmd := rawCmd == "pickup" || rawCmd == "delivered" || rawCmd == "failed" || rawCmd == "checkin" || rawCmd == "pod"
+		var rider *models.Rider
+		if !isAdmin && (isRiderCmd || rawCmd == "help") && !isGroupChat(ctx) {
+			rider, _ = d.shipUC.RiderByPhone(ctx, companyID, senderPhone)
+		}
+		if isRiderCmd && rider != nil {
+			logger.Info().Str("cmd", rawCmd).Str("sender", senderPhone).Int32("rider_id", rider.ID).Msg("Rider command authorized")
+		} else if isRiderCmd && !isAdmin && isGroupChat(ctx) {
+			if r, _ := d.shipUC.RiderByPhone(ctx, companyID, senderPhone); r != nil {
+				return &Result{Message: i18n.T(i18nLang(lang), "ERR_RIDER_PRIVATE_ONLY")}, true
+			}
+		}
+		// Handlers are shared across chats, so per-sender state goes on a copy
+		switch h := handler.(type) {
+		case *PickupHandler:
+			c := *h
+			c.Rider = rider
+			handler = &c
+		case *DeliveredHandler:
+			c := *h
+			c.Rider = rider
+			handler = &c
+		case *FailedHandler:
+			c := *h
+			c.Rider = rider
+			handler = &c
+		case *CheckinHandler:
+			c := *h
+			c.Rider = rider
+			handler = &c
+		case *PODHandler:
+			c := *h
+			c.Rider, c.Photo = rider, d.Photo
+			handler = &c
+		case *HelpHandler:
+			c := *h
+			c.IsRider = rider != nil
+			handler = &c
+		}
+
 		isPublicCmd := rawCmd == "info" || rawCmd == "help"
-		if !isPublicCmd && !isOwnerOnlyCmd {
+		if !isPublicCmd && !isOwnerOnlyCmd && !(isRiderCmd && rider != nil) {
 			if isAdmin {
 				logger.Info().Str("cmd", rawCmd).Str("sender", senderPhone).Msg("Admin command authorized")
 			} else {
@@ -150,6 +250,11 @@ func (d *Dispatcher) Dispatch(ctx context.Context, companyID uuid.UUID, text str
 	return nil, false
 }
 
+// isGroupChat reports whether the command came from a group rather than a private chat.
+func isGroupChat(ctx context.Context) bool {
+	return strings.HasSuffix(utils.GetChatJID(ctx), "@"+types.GroupServer)
+}
+
 func presentsAsCommand(text string) bool {
 	return len(text) > 1 && (text[0] == '!' || text[0] == '#')
 }
@@ -48,6 +48,15 @@ type Config struct {
 	// Frontend URL for magic links
 	FrontendURL string `env:"FRONTEND_URL" env-default:"http://localhost:3000"`
 
+	// Directory for gzip JSONL archives of pruned shipments (empty = prune without archiving)
+	ArchiveDir string `env:"ARCHIVE_DIR" env-default:"archives"`
+
+	// Directory for uploaded files such as proof-of-delivery photos (empty = uploads disabled)
+	BlobDir string `env:"BLOB_DIR" env-default:"blobs"`
+
+	// Days a deleted shipment stays restorable before it is purged (0 = never purge)
+	TrashRetentionDays int `env:"TRASH_RETENTION_DAYS" env-default:"30"`
+
 	// Super Admin — bypasses all billing, unlimited shipments
 	SuperAdminCompanyEmail string `env:"SUPER_ADMIN_COMPANY_EMAIL"`
 }
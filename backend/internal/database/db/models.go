@@ -7,6 +7,7 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sqlc-dev/pqtype"
@@ -21,6 +22,26 @@ type AuditLog struct {
 	CreatedAt       sql.NullTime          `json:"created_at"`
 }
 
+type CheckinSession struct {
+	CompanyID   uuid.UUID `json:"company_id"`
+	SenderPhone string    `json:"sender_phone"`
+	TrackingID  string    `json:"tracking_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type CodLedger struct {
+	ID         int32          `json:"id"`
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID sql.NullString `json:"tracking_id"`
+	EntryType  string         `json:"entry_type"`
+	Amount     float64        `json:"amount"`
+	Currency   string         `json:"currency"`
+	Reference  sql.NullString `json:"reference"`
+	Note       sql.NullString `json:"note"`
+	Actor      sql.NullString `json:"actor"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+}
+
 type Company struct {
 	ID                 uuid.UUID      `json:"id"`
 	Name               sql.NullString `json:"name"`
@@ -39,6 +60,100 @@ type Company struct {
 	UpdatedAt          sql.NullTime   `json:"updated_at"`
 }
 
+type Consolidation struct {
+	ID         int32          `json:"id"`
+	CompanyID  uuid.UUID      `json:"company_id"`
+	MasterAwb  string         `json:"master_awb"`
+	Label      sql.NullString `json:"label"`
+	State      string         `json:"state"`
+	Status     sql.NullString `json:"status"`
+	CreatedBy  sql.NullString `json:"created_by"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+	ClosedAt   sql.NullTime   `json:"closed_at"`
+	DepartedAt sql.NullTime   `json:"departed_at"`
+}
+
+type ConsolidationShipment struct {
+	CompanyID       uuid.UUID    `json:"company_id"`
+	TrackingID      string       `json:"tracking_id"`
+	ConsolidationID int32        `json:"consolidation_id"`
+	AddedAt         sql.NullTime `json:"added_at"`
+}
+
+type Contact struct {
+	ID            int32          `json:"id"`
+	CompanyID     uuid.UUID      `json:"company_id"`
+	Alias         sql.NullString `json:"alias"`
+	Name          string         `json:"name"`
+	Phone         string         `json:"phone"`
+	PhoneDigits   string         `json:"phone_digits"`
+	Email         sql.NullString `json:"email"`
+	Address       sql.NullString `json:"address"`
+	Country       sql.NullString `json:"country"`
+	IDNumber      sql.NullString `json:"id_number"`
+	ShipmentCount int32          `json:"shipment_count"`
+	LastUsedAt    sql.NullTime   `json:"last_used_at"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+	UpdatedAt     sql.NullTime   `json:"updated_at"`
+}
+
+type CustomFieldDefinition struct {
+	ID        int32          `json:"id"`
+	CompanyID uuid.UUID      `json:"company_id"`
+	Name      string         `json:"name"`
+	Label     sql.NullString `json:"label"`
+	FieldType string         `json:"field_type"`
+	Required  bool           `json:"required"`
+	Pattern   sql.NullString `json:"pattern"`
+	CreatedAt sql.NullTime   `json:"created_at"`
+	UpdatedAt sql.NullTime   `json:"updated_at"`
+}
+
+type CustomsDeclaration struct {
+	TrackingID    string         `json:"tracking_id"`
+	CompanyID     uuid.NullUUID  `json:"company_id"`
+	Currency      string         `json:"currency"`
+	ExportReason  string         `json:"export_reason"`
+	InvoiceNumber sql.NullString `json:"invoice_number"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+	UpdatedAt     sql.NullTime   `json:"updated_at"`
+}
+
+type CustomsItem struct {
+	ID            int32         `json:"id"`
+	CompanyID     uuid.NullUUID `json:"company_id"`
+	TrackingID    string        `json:"tracking_id"`
+	LineNo        int32         `json:"line_no"`
+	Description   string        `json:"description"`
+	HsCode        string        `json:"hs_code"`
+	Quantity      int32         `json:"quantity"`
+	UnitValue     float64       `json:"unit_value"`
+	Weight        float64       `json:"weight"`
+	OriginCountry string        `json:"origin_country"`
+}
+
+type DeliveryAttempt struct {
+	ID            int32          `json:"id"`
+	CompanyID     uuid.NullUUID  `json:"company_id"`
+	TrackingID    string         `json:"tracking_id"`
+	AttemptNo     int32          `json:"attempt_no"`
+	Reason        string         `json:"reason"`
+	Note          sql.NullString `json:"note"`
+	Source        string         `json:"source"`
+	Actor         sql.NullString `json:"actor"`
+	NextAttemptAt sql.NullTime   `json:"next_attempt_at"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+}
+
+type DeliveryOtp struct {
+	CompanyID      uuid.UUID    `json:"company_id"`
+	TrackingID     string       `json:"tracking_id"`
+	CodeHash       string       `json:"code_hash"`
+	FailedAttempts int32        `json:"failed_attempts"`
+	IssuedAt       time.Time    `json:"issued_at"`
+	ConfirmedAt    sql.NullTime `json:"confirmed_at"`
+}
+
 type Groupauthority struct {
 	CompanyID    uuid.UUID    `json:"company_id"`
 	Jid          string       `json:"jid"`
@@ -46,6 +161,15 @@ type Groupauthority struct {
 	UpdatedAt    sql.NullTime `json:"updated_at"`
 }
 
+type Holiday struct {
+	ID          int32          `json:"id"`
+	CompanyID   uuid.UUID      `json:"company_id"`
+	Country     string         `json:"country"`
+	HolidayDate time.Time      `json:"holiday_date"`
+	Name        sql.NullString `json:"name"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+}
+
 type Payment struct {
 	ID        int32           `json:"id"`
 	CompanyID uuid.NullUUID   `json:"company_id"`
@@ -73,6 +197,26 @@ type Plan struct {
 	UpdatedAt   sql.NullTime    `json:"updated_at"`
 }
 
+type Rider struct {
+	ID          int32        `json:"id"`
+	CompanyID   uuid.UUID    `json:"company_id"`
+	Name        string       `json:"name"`
+	Phone       string       `json:"phone"`
+	PhoneDigits string       `json:"phone_digits"`
+	Active      bool         `json:"active"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	UpdatedAt   sql.NullTime `json:"updated_at"`
+}
+
+type RiderAssignment struct {
+	CompanyID  uuid.UUID      `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	RiderID    int32          `json:"rider_id"`
+	AssignedBy sql.NullString `json:"assigned_by"`
+	AssignedAt sql.NullTime   `json:"assigned_at"`
+	PickedUpAt sql.NullTime   `json:"picked_up_at"`
+}
+
 type Shipment struct {
 	TrackingID           string          `json:"tracking_id"`
 	CompanyID            uuid.NullUUID   `json:"company_id"`
@@ -97,6 +241,83 @@ type Shipment struct {
 	Weight               sql.NullFloat64 `json:"weight"`
 	Cost                 sql.NullFloat64 `json:"cost"`
 	UpdatedAt            sql.NullTime    `json:"updated_at"`
+	ServiceLevel         sql.NullString  `json:"service_level"`
+	CodAmount            float64         `json:"cod_amount"`
+	CodCurrency          sql.NullString  `json:"cod_currency"`
+	DeletedAt            sql.NullTime    `json:"deleted_at"`
+	DeletedBy            sql.NullString  `json:"deleted_by"`
+	Version              int32           `json:"version"`
+	CustomFields         json.RawMessage `json:"custom_fields"`
+	Tags                 []string        `json:"tags"`
+	ReturnOf             sql.NullString  `json:"return_of"`
+	ReturnID             sql.NullString  `json:"return_id"`
+	ReturnReason         sql.NullString  `json:"return_reason"`
+}
+
+type ShipmentChange struct {
+	ID         int32          `json:"id"`
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	BatchID    uuid.UUID      `json:"batch_id"`
+	Field      string         `json:"field"`
+	OldValue   sql.NullString `json:"old_value"`
+	NewValue   sql.NullString `json:"new_value"`
+	Source     string         `json:"source"`
+	Actor      sql.NullString `json:"actor"`
+	IsUndo     bool           `json:"is_undo"`
+	RevertedAt sql.NullTime   `json:"reverted_at"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+}
+
+type ShipmentCheckpoint struct {
+	ID         int32          `json:"id"`
+	CompanyID  uuid.UUID      `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	Latitude   float64        `json:"latitude"`
+	Longitude  float64        `json:"longitude"`
+	AccuracyM  sql.NullInt32  `json:"accuracy_m"`
+	Label      string         `json:"label"`
+	Live       bool           `json:"live"`
+	Source     string         `json:"source"`
+	Actor      sql.NullString `json:"actor"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+}
+
+type ShipmentEvent struct {
+	ID             int32          `json:"id"`
+	CompanyID      uuid.NullUUID  `json:"company_id"`
+	TrackingID     string         `json:"tracking_id"`
+	Status         string         `json:"status"`
+	PreviousStatus sql.NullString `json:"previous_status"`
+	Source         string         `json:"source"`
+	Actor          sql.NullString `json:"actor"`
+	Description    sql.NullString `json:"description"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+}
+
+type ShipmentPiece struct {
+	ID          int32          `json:"id"`
+	CompanyID   uuid.NullUUID  `json:"company_id"`
+	TrackingID  string         `json:"tracking_id"`
+	PieceNo     int32          `json:"piece_no"`
+	Weight      float64        `json:"weight"`
+	LengthCm    float64        `json:"length_cm"`
+	WidthCm     float64        `json:"width_cm"`
+	HeightCm    float64        `json:"height_cm"`
+	Description sql.NullString `json:"description"`
+}
+
+type ShipmentPod struct {
+	ID          int32          `json:"id"`
+	CompanyID   uuid.UUID      `json:"company_id"`
+	TrackingID  string         `json:"tracking_id"`
+	Kind        string         `json:"kind"`
+	BlobKey     string         `json:"blob_key"`
+	ContentType string         `json:"content_type"`
+	SizeBytes   int64          `json:"size_bytes"`
+	Source      string         `json:"source"`
+	Actor       sql.NullString `json:"actor"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
 }
 
 type Systemconfig struct {
@@ -114,6 +335,12 @@ type Telemetry struct {
 	CreatedAt sql.NullTime          `json:"created_at"`
 }
 
+type TrackingCounter struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Scope     string    `json:"scope"`
+	Value     int64     `json:"value"`
+}
+
 type Userpreference struct {
 	CompanyID uuid.UUID    `json:"company_id"`
 	Jid       string       `json:"jid"`
@@ -12,50 +12,143 @@ import (
 )
 
 type Querier interface {
+	AddToConsolidation(ctx context.Context, arg AddToConsolidationParams) (sql.Result, error)
+	AssignRider(ctx context.Context, arg AssignRiderParams) error
 	BulkDeleteShipments(ctx context.Context, arg BulkDeleteShipmentsParams) (sql.Result, error)
-	BulkUpdateStatus(ctx context.Context, arg BulkUpdateStatusParams) error
+	BulkUpdateStatus(ctx context.Context, arg BulkUpdateStatusParams) ([]string, error)
+	CloseConsolidation(ctx context.Context, arg CloseConsolidationParams) (sql.Result, error)
+	ConfirmDeliveryOTP(ctx context.Context, arg ConfirmDeliveryOTPParams) error
 	CountAuthorizedGroups(ctx context.Context, companyID uuid.UUID) (int64, error)
 	CountCreatedSince(ctx context.Context, arg CountCreatedSinceParams) (int64, error)
 	CountDeliveredSince(ctx context.Context, arg CountDeliveredSinceParams) (int64, error)
+	CountDeliveryAttempts(ctx context.Context, arg CountDeliveryAttemptsParams) (int64, error)
 	CountShipments(ctx context.Context, companyID uuid.NullUUID) (int64, error)
 	CountShipmentsByStatus(ctx context.Context, companyID uuid.NullUUID) (CountShipmentsByStatusRow, error)
 	CreateCompany(ctx context.Context, arg CreateCompanyParams) (Company, error)
+	CreateConsolidation(ctx context.Context, arg CreateConsolidationParams) (Consolidation, error)
+	CreateContact(ctx context.Context, arg CreateContactParams) (Contact, error)
+	CreateRider(ctx context.Context, arg CreateRiderParams) (Rider, error)
 	CreateShipment(ctx context.Context, arg CreateShipmentParams) error
 	DeleteCompany(ctx context.Context, id uuid.UUID) error
-	DeleteDeliveredShipments(ctx context.Context, companyID uuid.NullUUID) error
-	DeleteShipment(ctx context.Context, arg DeleteShipmentParams) error
+	DeleteContact(ctx context.Context, arg DeleteContactParams) (sql.Result, error)
+	DeleteCustomFieldDefinition(ctx context.Context, arg DeleteCustomFieldDefinitionParams) (sql.Result, error)
+	DeleteCustomsDeclaration(ctx context.Context, arg DeleteCustomsDeclarationParams) (sql.Result, error)
+	DeleteCustomsItems(ctx context.Context, arg DeleteCustomsItemsParams) error
+	DeleteDeliveredShipments(ctx context.Context, arg DeleteDeliveredShipmentsParams) (sql.Result, error)
+	DeleteHoliday(ctx context.Context, arg DeleteHolidayParams) (sql.Result, error)
+	DeleteHolidaysByCountry(ctx context.Context, arg DeleteHolidaysByCountryParams) (sql.Result, error)
+	DeleteRider(ctx context.Context, arg DeleteRiderParams) (sql.Result, error)
+	DeleteShipment(ctx context.Context, arg DeleteShipmentParams) (sql.Result, error)
+	DeleteShipmentPieces(ctx context.Context, arg DeleteShipmentPiecesParams) error
+	DepartConsolidation(ctx context.Context, arg DepartConsolidationParams) (sql.Result, error)
 	FindSimilarShipment(ctx context.Context, arg FindSimilarShipmentParams) (string, error)
 	GetActivePlans(ctx context.Context) ([]GetActivePlansRow, error)
 	GetAllActiveCompanies(ctx context.Context) ([]Company, error)
 	GetAllCompanies(ctx context.Context) ([]uuid.UUID, error)
 	GetAuditLogs(ctx context.Context, arg GetAuditLogsParams) ([]AuditLog, error)
 	GetAuthorizedGroups(ctx context.Context, companyID uuid.UUID) ([]string, error)
+	GetCodCollection(ctx context.Context, arg GetCodCollectionParams) (CodLedger, error)
 	GetCompanyByEmail(ctx context.Context, adminEmail string) (Company, error)
 	GetCompanyByID(ctx context.Context, id uuid.UUID) (Company, error)
 	GetCompanyPayments(ctx context.Context, arg GetCompanyPaymentsParams) ([]Payment, error)
+	GetConsolidation(ctx context.Context, arg GetConsolidationParams) (Consolidation, error)
+	GetContact(ctx context.Context, arg GetContactParams) (Contact, error)
+	GetContactsByAliases(ctx context.Context, arg GetContactsByAliasesParams) ([]Contact, error)
+	GetCustomsDeclaration(ctx context.Context, arg GetCustomsDeclarationParams) (CustomsDeclaration, error)
+	GetDeliveryOTP(ctx context.Context, arg GetDeliveryOTPParams) (DeliveryOtp, error)
 	GetGroupAuthority(ctx context.Context, arg GetGroupAuthorityParams) (GetGroupAuthorityRow, error)
+	GetLastCheckpoint(ctx context.Context, arg GetLastCheckpointParams) (ShipmentCheckpoint, error)
 	GetLastShipmentIDForUser(ctx context.Context, arg GetLastShipmentIDForUserParams) (string, error)
+	GetOpenCheckin(ctx context.Context, arg GetOpenCheckinParams) (string, error)
+	GetPOD(ctx context.Context, arg GetPODParams) (ShipmentPod, error)
 	GetPlanByID(ctx context.Context, id string) (GetPlanByIDRow, error)
 	GetPlatformAnalytics(ctx context.Context) (GetPlatformAnalyticsRow, error)
 	GetRecentEvents(ctx context.Context, arg GetRecentEventsParams) ([]Telemetry, error)
+	GetRider(ctx context.Context, arg GetRiderParams) (Rider, error)
+	GetRiderAssignment(ctx context.Context, arg GetRiderAssignmentParams) (RiderAssignment, error)
+	GetRiderByPhone(ctx context.Context, arg GetRiderByPhoneParams) (Rider, error)
 	GetShipment(ctx context.Context, arg GetShipmentParams) (Shipment, error)
+	GetShipmentByTrackingID(ctx context.Context, trackingID string) (Shipment, error)
+	GetShipmentConsolidation(ctx context.Context, arg GetShipmentConsolidationParams) (string, error)
+	GetShipmentStatuses(ctx context.Context, arg GetShipmentStatusesParams) ([]GetShipmentStatusesRow, error)
 	GetSystemConfig(ctx context.Context, arg GetSystemConfigParams) (string, error)
 	GetTelemetryStats(ctx context.Context, arg GetTelemetryStatsParams) ([]GetTelemetryStatsRow, error)
 	GetUserLanguage(ctx context.Context, arg GetUserLanguageParams) (string, error)
 	HasAuthorizedGroups(ctx context.Context, companyID uuid.UUID) (int64, error)
+	InsertCheckpoint(ctx context.Context, arg InsertCheckpointParams) (ShipmentCheckpoint, error)
+	InsertCodEntry(ctx context.Context, arg InsertCodEntryParams) (CodLedger, error)
+	InsertCustomsItem(ctx context.Context, arg InsertCustomsItemParams) error
+	InsertDeliveryAttempt(ctx context.Context, arg InsertDeliveryAttemptParams) (DeliveryAttempt, error)
+	InsertPOD(ctx context.Context, arg InsertPODParams) (ShipmentPod, error)
+	InsertShipmentChange(ctx context.Context, arg InsertShipmentChangeParams) error
+	InsertShipmentEvent(ctx context.Context, arg InsertShipmentEventParams) error
+	InsertShipmentPiece(ctx context.Context, arg InsertShipmentPieceParams) error
+	IssueDeliveryOTP(ctx context.Context, arg IssueDeliveryOTPParams) error
+	ListAgedPODKeys(ctx context.Context, arg ListAgedPODKeysParams) ([]string, error)
+	ListAgedShipments(ctx context.Context, arg ListAgedShipmentsParams) ([]Shipment, error)
 	ListAllShipments(ctx context.Context, companyID uuid.NullUUID) ([]Shipment, error)
+	ListAssignedShipments(ctx context.Context, arg ListAssignedShipmentsParams) ([]string, error)
+	ListAttemptsForShipments(ctx context.Context, arg ListAttemptsForShipmentsParams) ([]DeliveryAttempt, error)
+	ListChangesForShipments(ctx context.Context, arg ListChangesForShipmentsParams) ([]ShipmentChange, error)
+	ListCheckpointsForShipments(ctx context.Context, arg ListCheckpointsForShipmentsParams) ([]ShipmentCheckpoint, error)
+	ListCodEntries(ctx context.Context, arg ListCodEntriesParams) ([]CodLedger, error)
+	ListConsolidationShipments(ctx context.Context, arg ListConsolidationShipmentsParams) ([]string, error)
+	ListConsolidations(ctx context.Context, arg ListConsolidationsParams) ([]ListConsolidationsRow, error)
+	ListContacts(ctx context.Context, arg ListContactsParams) ([]Contact, error)
+	ListCustomFieldDefinitions(ctx context.Context, companyID uuid.UUID) ([]CustomFieldDefinition, error)
+	ListCustomsForShipments(ctx context.Context, arg ListCustomsForShipmentsParams) ([]CustomsDeclaration, error)
+	ListCustomsItems(ctx context.Context, arg ListCustomsItemsParams) ([]CustomsItem, error)
+	ListCustomsItemsForShipments(ctx context.Context, arg ListCustomsItemsForShipmentsParams) ([]CustomsItem, error)
+	ListDeliveryAttempts(ctx context.Context, arg ListDeliveryAttemptsParams) ([]DeliveryAttempt, error)
+	ListDueTransitions(ctx context.Context, arg ListDueTransitionsParams) ([]Shipment, error)
+	ListEventsForShipments(ctx context.Context, arg ListEventsForShipmentsParams) ([]ShipmentEvent, error)
+	ListExpiredTrash(ctx context.Context, arg ListExpiredTrashParams) ([]Shipment, error)
+	ListHolidays(ctx context.Context, arg ListHolidaysParams) ([]Holiday, error)
+	ListLastChangeBatch(ctx context.Context, arg ListLastChangeBatchParams) ([]ShipmentChange, error)
+	ListPODKeysForShipments(ctx context.Context, arg ListPODKeysForShipmentsParams) ([]string, error)
+	ListPODs(ctx context.Context, arg ListPODsParams) ([]ShipmentPod, error)
+	ListPiecesForShipments(ctx context.Context, arg ListPiecesForShipmentsParams) ([]ShipmentPiece, error)
+	ListRiderPickups(ctx context.Context, arg ListRiderPickupsParams) ([]string, error)
+	ListRiders(ctx context.Context, companyID uuid.UUID) ([]Rider, error)
+	ListShipmentChanges(ctx context.Context, arg ListShipmentChangesParams) ([]ShipmentChange, error)
+	ListShipmentEvents(ctx context.Context, arg ListShipmentEventsParams) ([]ShipmentEvent, error)
+	ListShipmentPieces(ctx context.Context, arg ListShipmentPiecesParams) ([]ShipmentPiece, error)
 	ListShipments(ctx context.Context, arg ListShipmentsParams) ([]Shipment, error)
+	ListTrashedPODKeys(ctx context.Context, arg ListTrashedPODKeysParams) ([]string, error)
+	ListTrashedShipments(ctx context.Context, arg ListTrashedShipmentsParams) ([]Shipment, error)
+	ListUncollectedCod(ctx context.Context, arg ListUncollectedCodParams) ([]ListUncollectedCodRow, error)
 	LogAudit(ctx context.Context, arg LogAuditParams) error
+	MarkChangeBatchReverted(ctx context.Context, arg MarkChangeBatchRevertedParams) error
+	MarkRiderPickedUp(ctx context.Context, arg MarkRiderPickedUpParams) error
+	MarkShipmentReturned(ctx context.Context, arg MarkShipmentReturnedParams) (sql.Result, error)
+	NextTrackingSequence(ctx context.Context, arg NextTrackingSequenceParams) (int64, error)
+	OpenCheckin(ctx context.Context, arg OpenCheckinParams) error
+	PurgeShipments(ctx context.Context, arg PurgeShipmentsParams) (sql.Result, error)
+	PurgeTrashedShipments(ctx context.Context, arg PurgeTrashedShipmentsParams) (sql.Result, error)
 	RecordEvent(ctx context.Context, arg RecordEventParams) error
 	RecordPayment(ctx context.Context, arg RecordPaymentParams) (int32, error)
+	ReleaseDeliveryOTPAttempt(ctx context.Context, arg ReleaseDeliveryOTPAttemptParams) error
+	RemoveFromConsolidation(ctx context.Context, arg RemoveFromConsolidationParams) (sql.Result, error)
+	RescheduleDelivery(ctx context.Context, arg RescheduleDeliveryParams) (sql.Result, error)
+	ReserveDeliveryOTPAttempt(ctx context.Context, arg ReserveDeliveryOTPAttemptParams) (int32, error)
+	RestoreCheckpoint(ctx context.Context, arg RestoreCheckpointParams) error
+	RestoreCustomsDeclaration(ctx context.Context, arg RestoreCustomsDeclarationParams) error
+	RestoreDeliveryAttempt(ctx context.Context, arg RestoreDeliveryAttemptParams) error
+	RestoreShipment(ctx context.Context, arg RestoreShipmentParams) (sql.Result, error)
+	RestoreShipmentChange(ctx context.Context, arg RestoreShipmentChangeParams) error
+	RestoreShipmentEvent(ctx context.Context, arg RestoreShipmentEventParams) error
+	RestoreShipmentState(ctx context.Context, arg RestoreShipmentStateParams) error
 	RunAgedCleanup(ctx context.Context, arg RunAgedCleanupParams) (sql.Result, error)
+	SearchShipments(ctx context.Context, arg SearchShipmentsParams) ([]Shipment, error)
 	SetCompanyPassword(ctx context.Context, arg SetCompanyPasswordParams) error
+	SetConsolidationStatus(ctx context.Context, arg SetConsolidationStatusParams) error
 	SetGroupAuthority(ctx context.Context, arg SetGroupAuthorityParams) error
+	SetShipmentField(ctx context.Context, arg SetShipmentFieldParams) (sql.Result, error)
 	SetSystemConfig(ctx context.Context, arg SetSystemConfigParams) error
 	SetUserLanguage(ctx context.Context, arg SetUserLanguageParams) error
-	TransitionStatusToDelivered(ctx context.Context, arg TransitionStatusToDeliveredParams) ([]TransitionStatusToDeliveredRow, error)
-	TransitionStatusToIntransit(ctx context.Context, arg TransitionStatusToIntransitParams) ([]TransitionStatusToIntransitRow, error)
-	TransitionStatusToOutForDelivery(ctx context.Context, arg TransitionStatusToOutForDeliveryParams) ([]TransitionStatusToOutForDeliveryRow, error)
+	SumCodLedger(ctx context.Context, companyID uuid.NullUUID) ([]SumCodLedgerRow, error)
+	SumOpenCod(ctx context.Context, companyID uuid.NullUUID) ([]SumOpenCodRow, error)
+	UnassignRider(ctx context.Context, arg UnassignRiderParams) (sql.Result, error)
 	UpdateCompanyAuthStatus(ctx context.Context, arg UpdateCompanyAuthStatusParams) error
 	UpdateCompanyOnboarding(ctx context.Context, arg UpdateCompanyOnboardingParams) error
 	UpdateCompanyPlan(ctx context.Context, arg UpdateCompanyPlanParams) error
@@ -64,9 +157,18 @@ type Querier interface {
 	UpdateCompanySubscriptionStatus(ctx context.Context, arg UpdateCompanySubscriptionStatusParams) error
 	UpdateCompanySubscriptionWithPlan(ctx context.Context, arg UpdateCompanySubscriptionWithPlanParams) error
 	UpdateCompanyWhatsAppPhone(ctx context.Context, arg UpdateCompanyWhatsAppPhoneParams) error
+	UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error)
 	UpdatePlanPrice(ctx context.Context, arg UpdatePlanPriceParams) error
-	UpdateShipmentDynamic(ctx context.Context, arg UpdateShipmentDynamicParams) error
-	UpdateShipmentStatus(ctx context.Context, arg UpdateShipmentStatusParams) error
+	UpdateRider(ctx context.Context, arg UpdateRiderParams) (Rider, error)
+	UpdateShipmentCOD(ctx context.Context, arg UpdateShipmentCODParams) (sql.Result, error)
+	UpdateShipmentCost(ctx context.Context, arg UpdateShipmentCostParams) (sql.Result, error)
+	UpdateShipmentDynamic(ctx context.Context, arg UpdateShipmentDynamicParams) (sql.Result, error)
+	UpdateShipmentExtras(ctx context.Context, arg UpdateShipmentExtrasParams) (sql.Result, error)
+	UpdateShipmentStatus(ctx context.Context, arg UpdateShipmentStatusParams) (sql.Result, error)
+	UpsertContactFromShipment(ctx context.Context, arg UpsertContactFromShipmentParams) error
+	UpsertCustomFieldDefinition(ctx context.Context, arg UpsertCustomFieldDefinitionParams) (CustomFieldDefinition, error)
+	UpsertCustomsDeclaration(ctx context.Context, arg UpsertCustomsDeclarationParams) error
+	UpsertHoliday(ctx context.Context, arg UpsertHolidayParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -16,33 +16,127 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+const addToConsolidation = `-- name: AddToConsolidation :execresult
+INSERT INTO consolidation_shipments (company_id, tracking_id, consolidation_id)
+SELECT $1, unnest($2::text[]), $3
+ON CONFLICT (company_id, tracking_id) DO NOTHING
+`
+
+type AddToConsolidationParams struct {
+	CompanyID       uuid.UUID `json:"company_id"`
+	Column2         []string  `json:"column_2"`
+	ConsolidationID int32     `json:"consolidation_id"`
+}
+
+func (q *Queries) AddToConsolidation(ctx context.Context, arg AddToConsolidationParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, addToConsolidation, arg.CompanyID, pq.Array(arg.Column2), arg.ConsolidationID)
+}
+
+const assignRider = `-- name: AssignRider :exec
+INSERT INTO rider_assignments (company_id, tracking_id, rider_id, assigned_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (company_id, tracking_id) DO UPDATE SET
+  rider_id = EXCLUDED.rider_id,
+  assigned_by = EXCLUDED.assigned_by,
+  assigned_at = CURRENT_TIMESTAMP,
+  picked_up_at = NULL
+`
+
+type AssignRiderParams struct {
+	CompanyID  uuid.UUID      `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	RiderID    int32          `json:"rider_id"`
+	AssignedBy sql.NullString `json:"assigned_by"`
+}
+
+func (q *Queries) AssignRider(ctx context.Context, arg AssignRiderParams) error {
+	_, err := q.db.ExecContext(ctx, assignRider, arg.CompanyID, arg.TrackingID, arg.RiderID, arg.AssignedBy)
+	return err
+}
+
 const bulkDeleteShipments = `-- name: BulkDeleteShipments :execresult
-DELETE FROM Shipment WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+UPDATE Shipment SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $3, version = version + 1 WHERE company_id = $1 AND tracking_id = ANY($2::text[]) AND deleted_at IS NULL
 `
 
 type BulkDeleteShipmentsParams struct {
-	CompanyID uuid.NullUUID `json:"company_id"`
-	Column2   []string      `json:"column_2"`
+	CompanyID uuid.NullUUID  `json:"company_id"`
+	Column2   []string       `json:"column_2"`
+	DeletedBy sql.NullString `json:"deleted_by"`
 }
 
 func (q *Queries) BulkDeleteShipments(ctx context.Context, arg BulkDeleteShipmentsParams) (sql.Result, error) {
-	return q.db.ExecContext(ctx, bulkDeleteShipments, arg.CompanyID, pq.Array(arg.Column2))
+	return q.db.ExecContext(ctx, bulkDeleteShipments, arg.CompanyID, pq.Array(arg.Column2), arg.DeletedBy)
 }
 
-const bulkUpdateStatus = `-- name: BulkUpdateStatus :exec
-UPDATE Shipment
-SET status = $3, updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+const bulkUpdateStatus = `-- name: BulkUpdateStatus :many
+UPDATE Shipment s
+SET status = $3, version = s.version + 1, updated_at = CURRENT_TIMESTAMP
+FROM unnest($2::text[], $4::int[]) AS v(tracking_id, version)
+WHERE s.company_id = $1 AND s.tracking_id = v.tracking_id AND s.version = v.version AND s.deleted_at IS NULL
+RETURNING s.tracking_id
 `
 
 type BulkUpdateStatusParams struct {
 	CompanyID uuid.NullUUID  `json:"company_id"`
 	Column2   []string       `json:"column_2"`
 	Status    sql.NullString `json:"status"`
+	Column4   []int32        `json:"column_4"`
+}
+
+func (q *Queries) BulkUpdateStatus(ctx context.Context, arg BulkUpdateStatusParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, bulkUpdateStatus,
+		arg.CompanyID,
+		pq.Array(arg.Column2),
+		arg.Status,
+		pq.Array(arg.Column4),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tracking_id string
+		if err := rows.Scan(&tracking_id); err != nil {
+			return nil, err
+		}
+		items = append(items, tracking_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const closeConsolidation = `-- name: CloseConsolidation :execresult
+UPDATE consolidations SET state = 'closed', closed_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2 AND state = 'open'
+`
+
+type CloseConsolidationParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	ID        int32     `json:"id"`
+}
+
+func (q *Queries) CloseConsolidation(ctx context.Context, arg CloseConsolidationParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, closeConsolidation, arg.CompanyID, arg.ID)
+}
+
+const confirmDeliveryOTP = `-- name: ConfirmDeliveryOTP :exec
+UPDATE delivery_otps SET confirmed_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND confirmed_at IS NULL
+`
+
+type ConfirmDeliveryOTPParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
 }
 
-func (q *Queries) BulkUpdateStatus(ctx context.Context, arg BulkUpdateStatusParams) error {
-	_, err := q.db.ExecContext(ctx, bulkUpdateStatus, arg.CompanyID, pq.Array(arg.Column2), arg.Status)
+func (q *Queries) ConfirmDeliveryOTP(ctx context.Context, arg ConfirmDeliveryOTPParams) error {
+	_, err := q.db.ExecContext(ctx, confirmDeliveryOTP, arg.CompanyID, arg.TrackingID)
 	return err
 }
 
@@ -58,7 +152,7 @@ func (q *Queries) CountAuthorizedGroups(ctx context.Context, companyID uuid.UUID
 }
 
 const countCreatedSince = `-- name: CountCreatedSince :one
-SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND created_at >= $2
+SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND created_at >= $2 AND deleted_at IS NULL
 `
 
 type CountCreatedSinceParams struct {
@@ -74,7 +168,7 @@ func (q *Queries) CountCreatedSince(ctx context.Context, arg CountCreatedSincePa
 }
 
 const countDeliveredSince = `-- name: CountDeliveredSince :one
-SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND status = 'delivered' AND updated_at >= $2
+SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND status = 'delivered' AND updated_at >= $2 AND deleted_at IS NULL
 `
 
 type CountDeliveredSinceParams struct {
@@ -89,8 +183,24 @@ func (q *Queries) CountDeliveredSince(ctx context.Context, arg CountDeliveredSin
 	return count, err
 }
 
+const countDeliveryAttempts = `-- name: CountDeliveryAttempts :one
+SELECT COUNT(*) FROM delivery_attempts WHERE company_id = $1 AND tracking_id = $2
+`
+
+type CountDeliveryAttemptsParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) CountDeliveryAttempts(ctx context.Context, arg CountDeliveryAttemptsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDeliveryAttempts, arg.CompanyID, arg.TrackingID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countShipments = `-- name: CountShipments :one
-SELECT COUNT(*) FROM Shipment WHERE company_id = $1
+SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) CountShipments(ctx context.Context, companyID uuid.NullUUID) (int64, error) {
@@ -108,7 +218,7 @@ SELECT
     COUNT(*) FILTER (WHERE status = 'outfordelivery') AS outfordelivery,
     COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
     COUNT(*) FILTER (WHERE status = 'canceled') AS canceled
-FROM Shipment WHERE company_id = $1
+FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL
 `
 
 type CountShipmentsByStatusRow struct {
@@ -169,11 +279,133 @@ func (q *Queries) CreateCompany(ctx context.Context, arg CreateCompanyParams) (C
 	return i, err
 }
 
+const createConsolidation = `-- name: CreateConsolidation :one
+INSERT INTO consolidations (company_id, master_awb, label, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING id, company_id, master_awb, label, state, status, created_by, created_at, closed_at, departed_at
+`
+
+type CreateConsolidationParams struct {
+	CompanyID uuid.UUID      `json:"company_id"`
+	MasterAwb string         `json:"master_awb"`
+	Label     sql.NullString `json:"label"`
+	CreatedBy sql.NullString `json:"created_by"`
+}
+
+func (q *Queries) CreateConsolidation(ctx context.Context, arg CreateConsolidationParams) (Consolidation, error) {
+	row := q.db.QueryRowContext(ctx, createConsolidation,
+		arg.CompanyID,
+		arg.MasterAwb,
+		arg.Label,
+		arg.CreatedBy,
+	)
+	var i Consolidation
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.MasterAwb,
+		&i.Label,
+		&i.State,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.DepartedAt,
+	)
+	return i, err
+}
+
+const createContact = `-- name: CreateContact :one
+INSERT INTO contacts (company_id, alias, name, phone, phone_digits, email, address, country, id_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, company_id, alias, name, phone, phone_digits, email, address, country, id_number, shipment_count, last_used_at, created_at, updated_at
+`
+
+type CreateContactParams struct {
+	CompanyID   uuid.UUID      `json:"company_id"`
+	Alias       sql.NullString `json:"alias"`
+	Name        string         `json:"name"`
+	Phone       string         `json:"phone"`
+	PhoneDigits string         `json:"phone_digits"`
+	Email       sql.NullString `json:"email"`
+	Address     sql.NullString `json:"address"`
+	Country     sql.NullString `json:"country"`
+	IDNumber    sql.NullString `json:"id_number"`
+}
+
+func (q *Queries) CreateContact(ctx context.Context, arg CreateContactParams) (Contact, error) {
+	row := q.db.QueryRowContext(ctx, createContact,
+		arg.CompanyID,
+		arg.Alias,
+		arg.Name,
+		arg.Phone,
+		arg.PhoneDigits,
+		arg.Email,
+		arg.Address,
+		arg.Country,
+		arg.IDNumber,
+	)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Alias,
+		&i.Name,
+		&i.Phone,
+		&i.PhoneDigits,
+		&i.Email,
+		&i.Address,
+		&i.Country,
+		&i.IDNumber,
+		&i.ShipmentCount,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRider = `-- name: CreateRider :one
+INSERT INTO riders (company_id, name, phone, phone_digits, active)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, company_id, name, phone, phone_digits, active, created_at, updated_at
+`
+
+type CreateRiderParams struct {
+	CompanyID   uuid.UUID `json:"company_id"`
+	Name        string    `json:"name"`
+	Phone       string    `json:"phone"`
+	PhoneDigits string    `json:"phone_digits"`
+	Active      bool      `json:"active"`
+}
+
+func (q *Queries) CreateRider(ctx context.Context, arg CreateRiderParams) (Rider, error) {
+	row := q.db.QueryRowContext(ctx, createRider,
+		arg.CompanyID,
+		arg.Name,
+		arg.Phone,
+		arg.PhoneDigits,
+		arg.Active,
+	)
+	var i Rider
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Name,
+		&i.Phone,
+		&i.PhoneDigits,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createShipment = `-- name: CreateShipment :exec
 INSERT INTO Shipment (
-    company_id, tracking_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at
+    company_id, tracking_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, custom_fields, tags, return_of, return_reason
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30
 )
 `
 
@@ -201,6 +433,13 @@ type CreateShipmentParams struct {
 	Weight               sql.NullFloat64 `json:"weight"`
 	Cost                 sql.NullFloat64 `json:"cost"`
 	UpdatedAt            sql.NullTime    `json:"updated_at"`
+	ServiceLevel         sql.NullString  `json:"service_level"`
+	CodAmount            float64         `json:"cod_amount"`
+	CodCurrency          sql.NullString  `json:"cod_currency"`
+	CustomFields         json.RawMessage `json:"custom_fields"`
+	Tags                 []string        `json:"tags"`
+	ReturnOf             sql.NullString  `json:"return_of"`
+	ReturnReason         sql.NullString  `json:"return_reason"`
 }
 
 func (q *Queries) CreateShipment(ctx context.Context, arg CreateShipmentParams) error {
@@ -228,6 +467,13 @@ func (q *Queries) CreateShipment(ctx context.Context, arg CreateShipmentParams)
 		arg.Weight,
 		arg.Cost,
 		arg.UpdatedAt,
+		arg.ServiceLevel,
+		arg.CodAmount,
+		arg.CodCurrency,
+		arg.CustomFields,
+		pq.Array(arg.Tags),
+		arg.ReturnOf,
+		arg.ReturnReason,
 	)
 	return err
 }
@@ -241,32 +487,156 @@ func (q *Queries) DeleteCompany(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-const deleteDeliveredShipments = `-- name: DeleteDeliveredShipments :exec
-DELETE FROM Shipment WHERE company_id = $1 AND status = 'delivered'
+const deleteContact = `-- name: DeleteContact :execresult
+DELETE FROM contacts WHERE company_id = $1 AND id = $2
+`
+
+type DeleteContactParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	ID        int32     `json:"id"`
+}
+
+func (q *Queries) DeleteContact(ctx context.Context, arg DeleteContactParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteContact, arg.CompanyID, arg.ID)
+}
+
+const deleteCustomFieldDefinition = `-- name: DeleteCustomFieldDefinition :execresult
+DELETE FROM custom_field_definitions WHERE company_id = $1 AND name = $2
+`
+
+type DeleteCustomFieldDefinitionParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Name      string    `json:"name"`
+}
+
+func (q *Queries) DeleteCustomFieldDefinition(ctx context.Context, arg DeleteCustomFieldDefinitionParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteCustomFieldDefinition, arg.CompanyID, arg.Name)
+}
+
+const deleteCustomsDeclaration = `-- name: DeleteCustomsDeclaration :execresult
+DELETE FROM customs_declarations WHERE company_id = $1 AND tracking_id = $2
 `
 
-func (q *Queries) DeleteDeliveredShipments(ctx context.Context, companyID uuid.NullUUID) error {
-	_, err := q.db.ExecContext(ctx, deleteDeliveredShipments, companyID)
+type DeleteCustomsDeclarationParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) DeleteCustomsDeclaration(ctx context.Context, arg DeleteCustomsDeclarationParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteCustomsDeclaration, arg.CompanyID, arg.TrackingID)
+}
+
+const deleteCustomsItems = `-- name: DeleteCustomsItems :exec
+DELETE FROM customs_items WHERE company_id = $1 AND tracking_id = $2
+`
+
+type DeleteCustomsItemsParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) DeleteCustomsItems(ctx context.Context, arg DeleteCustomsItemsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteCustomsItems, arg.CompanyID, arg.TrackingID)
 	return err
 }
 
-const deleteShipment = `-- name: DeleteShipment :exec
-DELETE FROM Shipment WHERE company_id = $1 AND tracking_id = $2
+const deleteDeliveredShipments = `-- name: DeleteDeliveredShipments :execresult
+UPDATE Shipment SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2, version = version + 1 WHERE company_id = $1 AND status = 'delivered' AND deleted_at IS NULL
+`
+
+type DeleteDeliveredShipmentsParams struct {
+	CompanyID uuid.NullUUID  `json:"company_id"`
+	DeletedBy sql.NullString `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteDeliveredShipments(ctx context.Context, arg DeleteDeliveredShipmentsParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteDeliveredShipments, arg.CompanyID, arg.DeletedBy)
+}
+
+const deleteHoliday = `-- name: DeleteHoliday :execresult
+DELETE FROM holidays WHERE company_id = $1 AND id = $2
+`
+
+type DeleteHolidayParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	ID        int32     `json:"id"`
+}
+
+func (q *Queries) DeleteHoliday(ctx context.Context, arg DeleteHolidayParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteHoliday, arg.CompanyID, arg.ID)
+}
+
+const deleteHolidaysByCountry = `-- name: DeleteHolidaysByCountry :execresult
+DELETE FROM holidays WHERE company_id = $1 AND country = $2
+`
+
+type DeleteHolidaysByCountryParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Country   string    `json:"country"`
+}
+
+func (q *Queries) DeleteHolidaysByCountry(ctx context.Context, arg DeleteHolidaysByCountryParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteHolidaysByCountry, arg.CompanyID, arg.Country)
+}
+
+const deleteRider = `-- name: DeleteRider :execresult
+DELETE FROM riders WHERE company_id = $1 AND id = $2
+`
+
+type DeleteRiderParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	ID        int32     `json:"id"`
+}
+
+func (q *Queries) DeleteRider(ctx context.Context, arg DeleteRiderParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteRider, arg.CompanyID, arg.ID)
+}
+
+const deleteShipment = `-- name: DeleteShipment :execresult
+UPDATE Shipment SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $3, version = version + 1 WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NULL
 `
 
 type DeleteShipmentParams struct {
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	DeletedBy  sql.NullString `json:"deleted_by"`
+}
+
+func (q *Queries) DeleteShipment(ctx context.Context, arg DeleteShipmentParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, deleteShipment, arg.CompanyID, arg.TrackingID, arg.DeletedBy)
+}
+
+const deleteShipmentPieces = `-- name: DeleteShipmentPieces :exec
+DELETE FROM shipment_pieces WHERE company_id = $1 AND tracking_id = $2
+`
+
+type DeleteShipmentPiecesParams struct {
 	CompanyID  uuid.NullUUID `json:"company_id"`
 	TrackingID string        `json:"tracking_id"`
 }
 
-func (q *Queries) DeleteShipment(ctx context.Context, arg DeleteShipmentParams) error {
-	_, err := q.db.ExecContext(ctx, deleteShipment, arg.CompanyID, arg.TrackingID)
+func (q *Queries) DeleteShipmentPieces(ctx context.Context, arg DeleteShipmentPiecesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteShipmentPieces, arg.CompanyID, arg.TrackingID)
 	return err
 }
 
+const departConsolidation = `-- name: DepartConsolidation :execresult
+UPDATE consolidations SET state = 'departed', departed_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2 AND state = 'closed'
+`
+
+type DepartConsolidationParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	ID        int32     `json:"id"`
+}
+
+func (q *Queries) DepartConsolidation(ctx context.Context, arg DepartConsolidationParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, departConsolidation, arg.CompanyID, arg.ID)
+}
+
 const findSimilarShipment = `-- name: FindSimilarShipment :one
 SELECT tracking_id FROM Shipment 
-WHERE company_id = $1 AND user_jid = $2 AND recipient_phone = $3 AND $3 != ''
+WHERE company_id = $1 AND user_jid = $2 AND recipient_phone = $3 AND $3 != '' AND deleted_at IS NULL
 ORDER BY created_at DESC LIMIT 1
 `
 
@@ -482,6 +852,33 @@ func (q *Queries) GetAuthorizedGroups(ctx context.Context, companyID uuid.UUID)
 	return items, nil
 }
 
+const getCodCollection = `-- name: GetCodCollection :one
+SELECT id, company_id, tracking_id, entry_type, amount, currency, reference, note, actor, created_at FROM cod_ledger WHERE company_id = $1 AND tracking_id = $2 AND entry_type = 'collection'
+`
+
+type GetCodCollectionParams struct {
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID sql.NullString `json:"tracking_id"`
+}
+
+func (q *Queries) GetCodCollection(ctx context.Context, arg GetCodCollectionParams) (CodLedger, error) {
+	row := q.db.QueryRowContext(ctx, getCodCollection, arg.CompanyID, arg.TrackingID)
+	var i CodLedger
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.EntryType,
+		&i.Amount,
+		&i.Currency,
+		&i.Reference,
+		&i.Note,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getCompanyByEmail = `-- name: GetCompanyByEmail :one
 SELECT id, name, admin_email, admin_password_hash, whatsapp_phone, logo_url, brand_color, auth_status, subscription_status, subscription_expiry, plan_type, setup_token, tracking_prefix, created_at, updated_at FROM companies WHERE admin_email = $1
 `
@@ -576,70 +973,294 @@ func (q *Queries) GetCompanyPayments(ctx context.Context, arg GetCompanyPayments
 	return items, nil
 }
 
-const getGroupAuthority = `-- name: GetGroupAuthority :one
-SELECT is_authorized, updated_at FROM GroupAuthority WHERE company_id = $1 AND jid = $2
+const getConsolidation = `-- name: GetConsolidation :one
+SELECT id, company_id, master_awb, label, state, status, created_by, created_at, closed_at, departed_at FROM consolidations WHERE company_id = $1 AND master_awb = $2
 `
 
-type GetGroupAuthorityParams struct {
+type GetConsolidationParams struct {
 	CompanyID uuid.UUID `json:"company_id"`
-	Jid       string    `json:"jid"`
-}
-
-type GetGroupAuthorityRow struct {
-	IsAuthorized bool         `json:"is_authorized"`
-	UpdatedAt    sql.NullTime `json:"updated_at"`
+	MasterAwb string    `json:"master_awb"`
 }
 
-func (q *Queries) GetGroupAuthority(ctx context.Context, arg GetGroupAuthorityParams) (GetGroupAuthorityRow, error) {
-	row := q.db.QueryRowContext(ctx, getGroupAuthority, arg.CompanyID, arg.Jid)
-	var i GetGroupAuthorityRow
-	err := row.Scan(&i.IsAuthorized, &i.UpdatedAt)
+func (q *Queries) GetConsolidation(ctx context.Context, arg GetConsolidationParams) (Consolidation, error) {
+	row := q.db.QueryRowContext(ctx, getConsolidation, arg.CompanyID, arg.MasterAwb)
+	var i Consolidation
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.MasterAwb,
+		&i.Label,
+		&i.State,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.ClosedAt,
+		&i.DepartedAt,
+	)
 	return i, err
 }
 
-const getLastShipmentIDForUser = `-- name: GetLastShipmentIDForUser :one
-SELECT tracking_id FROM Shipment WHERE company_id = $1 AND user_jid = $2 ORDER BY created_at DESC LIMIT 1
+const getContact = `-- name: GetContact :one
+SELECT id, company_id, alias, name, phone, phone_digits, email, address, country, id_number, shipment_count, last_used_at, created_at, updated_at FROM contacts WHERE company_id = $1 AND id = $2
 `
 
-type GetLastShipmentIDForUserParams struct {
-	CompanyID uuid.NullUUID `json:"company_id"`
-	UserJid   string        `json:"user_jid"`
+type GetContactParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	ID        int32     `json:"id"`
 }
 
-func (q *Queries) GetLastShipmentIDForUser(ctx context.Context, arg GetLastShipmentIDForUserParams) (string, error) {
-	row := q.db.QueryRowContext(ctx, getLastShipmentIDForUser, arg.CompanyID, arg.UserJid)
-	var tracking_id string
-	err := row.Scan(&tracking_id)
-	return tracking_id, err
+func (q *Queries) GetContact(ctx context.Context, arg GetContactParams) (Contact, error) {
+	row := q.db.QueryRowContext(ctx, getContact, arg.CompanyID, arg.ID)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Alias,
+		&i.Name,
+		&i.Phone,
+		&i.PhoneDigits,
+		&i.Email,
+		&i.Address,
+		&i.Country,
+		&i.IDNumber,
+		&i.ShipmentCount,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-const getPlanByID = `-- name: GetPlanByID :one
-SELECT id, name, name_key, desc_key, base_price, currency, interval_key, popular, trial_key, btn_key, features
-FROM plans
-WHERE id = $1 AND is_active = TRUE
+const getContactsByAliases = `-- name: GetContactsByAliases :many
+SELECT id, company_id, alias, name, phone, phone_digits, email, address, country, id_number, shipment_count, last_used_at, created_at, updated_at FROM contacts WHERE company_id = $1 AND alias = ANY($2::text[])
 `
 
-type GetPlanByIDRow struct {
-	ID          string          `json:"id"`
-	Name        string          `json:"name"`
-	NameKey     string          `json:"name_key"`
-	DescKey     string          `json:"desc_key"`
-	BasePrice   int32           `json:"base_price"`
-	Currency    string          `json:"currency"`
-	IntervalKey string          `json:"interval_key"`
-	Popular     sql.NullBool    `json:"popular"`
-	TrialKey    sql.NullString  `json:"trial_key"`
-	BtnKey      string          `json:"btn_key"`
-	Features    json.RawMessage `json:"features"`
+type GetContactsByAliasesParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Column2   []string  `json:"column_2"`
 }
 
-func (q *Queries) GetPlanByID(ctx context.Context, id string) (GetPlanByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getPlanByID, id)
-	var i GetPlanByIDRow
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.NameKey,
+func (q *Queries) GetContactsByAliases(ctx context.Context, arg GetContactsByAliasesParams) ([]Contact, error) {
+	rows, err := q.db.QueryContext(ctx, getContactsByAliases, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contact
+	for rows.Next() {
+		var i Contact
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.Alias,
+			&i.Name,
+			&i.Phone,
+			&i.PhoneDigits,
+			&i.Email,
+			&i.Address,
+			&i.Country,
+			&i.IDNumber,
+			&i.ShipmentCount,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCustomsDeclaration = `-- name: GetCustomsDeclaration :one
+SELECT tracking_id, company_id, currency, export_reason, invoice_number, created_at, updated_at FROM customs_declarations WHERE company_id = $1 AND tracking_id = $2
+`
+
+type GetCustomsDeclarationParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) GetCustomsDeclaration(ctx context.Context, arg GetCustomsDeclarationParams) (CustomsDeclaration, error) {
+	row := q.db.QueryRowContext(ctx, getCustomsDeclaration, arg.CompanyID, arg.TrackingID)
+	var i CustomsDeclaration
+	err := row.Scan(
+		&i.TrackingID,
+		&i.CompanyID,
+		&i.Currency,
+		&i.ExportReason,
+		&i.InvoiceNumber,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDeliveryOTP = `-- name: GetDeliveryOTP :one
+SELECT company_id, tracking_id, code_hash, failed_attempts, issued_at, confirmed_at FROM delivery_otps WHERE company_id = $1 AND tracking_id = $2
+`
+
+type GetDeliveryOTPParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+}
+
+func (q *Queries) GetDeliveryOTP(ctx context.Context, arg GetDeliveryOTPParams) (DeliveryOtp, error) {
+	row := q.db.QueryRowContext(ctx, getDeliveryOTP, arg.CompanyID, arg.TrackingID)
+	var i DeliveryOtp
+	err := row.Scan(
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.CodeHash,
+		&i.FailedAttempts,
+		&i.IssuedAt,
+		&i.ConfirmedAt,
+	)
+	return i, err
+}
+
+const getGroupAuthority = `-- name: GetGroupAuthority :one
+SELECT is_authorized, updated_at FROM GroupAuthority WHERE company_id = $1 AND jid = $2
+`
+
+type GetGroupAuthorityParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Jid       string    `json:"jid"`
+}
+
+type GetGroupAuthorityRow struct {
+	IsAuthorized bool         `json:"is_authorized"`
+	UpdatedAt    sql.NullTime `json:"updated_at"`
+}
+
+func (q *Queries) GetGroupAuthority(ctx context.Context, arg GetGroupAuthorityParams) (GetGroupAuthorityRow, error) {
+	row := q.db.QueryRowContext(ctx, getGroupAuthority, arg.CompanyID, arg.Jid)
+	var i GetGroupAuthorityRow
+	err := row.Scan(&i.IsAuthorized, &i.UpdatedAt)
+	return i, err
+}
+
+const getLastCheckpoint = `-- name: GetLastCheckpoint :one
+SELECT id, company_id, tracking_id, latitude, longitude, accuracy_m, label, live, source, actor, created_at FROM shipment_checkpoints WHERE company_id = $1 AND tracking_id = $2 ORDER BY created_at DESC, id DESC LIMIT 1
+`
+
+type GetLastCheckpointParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+}
+
+func (q *Queries) GetLastCheckpoint(ctx context.Context, arg GetLastCheckpointParams) (ShipmentCheckpoint, error) {
+	row := q.db.QueryRowContext(ctx, getLastCheckpoint, arg.CompanyID, arg.TrackingID)
+	var i ShipmentCheckpoint
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.AccuracyM,
+		&i.Label,
+		&i.Live,
+		&i.Source,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastShipmentIDForUser = `-- name: GetLastShipmentIDForUser :one
+SELECT tracking_id FROM Shipment WHERE company_id = $1 AND user_jid = $2 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT 1
+`
+
+type GetLastShipmentIDForUserParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	UserJid   string        `json:"user_jid"`
+}
+
+func (q *Queries) GetLastShipmentIDForUser(ctx context.Context, arg GetLastShipmentIDForUserParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getLastShipmentIDForUser, arg.CompanyID, arg.UserJid)
+	var tracking_id string
+	err := row.Scan(&tracking_id)
+	return tracking_id, err
+}
+
+const getOpenCheckin = `-- name: GetOpenCheckin :one
+SELECT tracking_id FROM checkin_sessions WHERE company_id = $1 AND sender_phone = $2 AND expires_at > CURRENT_TIMESTAMP
+`
+
+type GetOpenCheckinParams struct {
+	CompanyID   uuid.UUID `json:"company_id"`
+	SenderPhone string    `json:"sender_phone"`
+}
+
+func (q *Queries) GetOpenCheckin(ctx context.Context, arg GetOpenCheckinParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getOpenCheckin, arg.CompanyID, arg.SenderPhone)
+	var tracking_id string
+	err := row.Scan(&tracking_id)
+	return tracking_id, err
+}
+
+const getPOD = `-- name: GetPOD :one
+SELECT id, company_id, tracking_id, kind, blob_key, content_type, size_bytes, source, actor, created_at FROM shipment_pods WHERE company_id = $1 AND tracking_id = $2 AND id = $3
+`
+
+type GetPODParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+	ID         int32     `json:"id"`
+}
+
+func (q *Queries) GetPOD(ctx context.Context, arg GetPODParams) (ShipmentPod, error) {
+	row := q.db.QueryRowContext(ctx, getPOD, arg.CompanyID, arg.TrackingID, arg.ID)
+	var i ShipmentPod
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.Kind,
+		&i.BlobKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Source,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPlanByID = `-- name: GetPlanByID :one
+SELECT id, name, name_key, desc_key, base_price, currency, interval_key, popular, trial_key, btn_key, features
+FROM plans
+WHERE id = $1 AND is_active = TRUE
+`
+
+type GetPlanByIDRow struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	NameKey     string          `json:"name_key"`
+	DescKey     string          `json:"desc_key"`
+	BasePrice   int32           `json:"base_price"`
+	Currency    string          `json:"currency"`
+	IntervalKey string          `json:"interval_key"`
+	Popular     sql.NullBool    `json:"popular"`
+	TrialKey    sql.NullString  `json:"trial_key"`
+	BtnKey      string          `json:"btn_key"`
+	Features    json.RawMessage `json:"features"`
+}
+
+func (q *Queries) GetPlanByID(ctx context.Context, id string) (GetPlanByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getPlanByID, id)
+	var i GetPlanByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.NameKey,
 		&i.DescKey,
 		&i.BasePrice,
 		&i.Currency,
@@ -656,8 +1277,8 @@ const getPlatformAnalytics = `-- name: GetPlatformAnalytics :one
 SELECT 
     (SELECT COUNT(*) FROM companies) as total_tenants,
     (SELECT COUNT(*) FROM companies WHERE created_at >= date_trunc('month', CURRENT_TIMESTAMP)) as new_tenants_this_month,
-    (SELECT COUNT(*) FROM Shipment) as total_shipments,
-    (SELECT COUNT(*) FROM Shipment WHERE created_at >= CURRENT_DATE) as shipments_today,
+    (SELECT COUNT(*) FROM Shipment WHERE deleted_at IS NULL) as total_shipments,
+    (SELECT COUNT(*) FROM Shipment WHERE created_at >= CURRENT_DATE AND deleted_at IS NULL) as shipments_today,
     (SELECT jsonb_object_agg(plan_type, count) FROM (SELECT plan_type, COUNT(*) as count FROM companies GROUP BY plan_type) t) as plan_distribution,
     (SELECT jsonb_object_agg(subscription_status, count) FROM (SELECT subscription_status, COUNT(*) as count FROM companies GROUP BY subscription_status) t) as subscription_distribution
 `
@@ -726,8 +1347,81 @@ func (q *Queries) GetRecentEvents(ctx context.Context, arg GetRecentEventsParams
 	return items, nil
 }
 
+const getRider = `-- name: GetRider :one
+SELECT id, company_id, name, phone, phone_digits, active, created_at, updated_at FROM riders WHERE company_id = $1 AND id = $2
+`
+
+type GetRiderParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	ID        int32     `json:"id"`
+}
+
+func (q *Queries) GetRider(ctx context.Context, arg GetRiderParams) (Rider, error) {
+	row := q.db.QueryRowContext(ctx, getRider, arg.CompanyID, arg.ID)
+	var i Rider
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Name,
+		&i.Phone,
+		&i.PhoneDigits,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRiderAssignment = `-- name: GetRiderAssignment :one
+SELECT company_id, tracking_id, rider_id, assigned_by, assigned_at, picked_up_at FROM rider_assignments WHERE company_id = $1 AND tracking_id = $2
+`
+
+type GetRiderAssignmentParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+}
+
+func (q *Queries) GetRiderAssignment(ctx context.Context, arg GetRiderAssignmentParams) (RiderAssignment, error) {
+	row := q.db.QueryRowContext(ctx, getRiderAssignment, arg.CompanyID, arg.TrackingID)
+	var i RiderAssignment
+	err := row.Scan(
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.RiderID,
+		&i.AssignedBy,
+		&i.AssignedAt,
+		&i.PickedUpAt,
+	)
+	return i, err
+}
+
+const getRiderByPhone = `-- name: GetRiderByPhone :one
+SELECT id, company_id, name, phone, phone_digits, active, created_at, updated_at FROM riders WHERE company_id = $1 AND phone_digits = $2
+`
+
+type GetRiderByPhoneParams struct {
+	CompanyID   uuid.UUID `json:"company_id"`
+	PhoneDigits string    `json:"phone_digits"`
+}
+
+func (q *Queries) GetRiderByPhone(ctx context.Context, arg GetRiderByPhoneParams) (Rider, error) {
+	row := q.db.QueryRowContext(ctx, getRiderByPhone, arg.CompanyID, arg.PhoneDigits)
+	var i Rider
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Name,
+		&i.Phone,
+		&i.PhoneDigits,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getShipment = `-- name: GetShipment :one
-SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at FROM Shipment WHERE company_id = $1 AND tracking_id = $2
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NULL
 `
 
 type GetShipmentParams struct {
@@ -762,10 +1456,123 @@ func (q *Queries) GetShipment(ctx context.Context, arg GetShipmentParams) (Shipm
 		&i.Weight,
 		&i.Cost,
 		&i.UpdatedAt,
+		&i.ServiceLevel,
+		&i.CodAmount,
+		&i.CodCurrency,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Version,
+		&i.CustomFields,
+		pq.Array(&i.Tags),
+		&i.ReturnOf,
+		&i.ReturnID,
+		&i.ReturnReason,
+	)
+	return i, err
+}
+
+const getShipmentByTrackingID = `-- name: GetShipmentByTrackingID :one
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment WHERE tracking_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) GetShipmentByTrackingID(ctx context.Context, trackingID string) (Shipment, error) {
+	row := q.db.QueryRowContext(ctx, getShipmentByTrackingID, trackingID)
+	var i Shipment
+	err := row.Scan(
+		&i.TrackingID,
+		&i.CompanyID,
+		&i.UserJid,
+		&i.Status,
+		&i.CreatedAt,
+		&i.ScheduledTransitTime,
+		&i.OutfordeliveryTime,
+		&i.ExpectedDeliveryTime,
+		&i.SenderTimezone,
+		&i.RecipientTimezone,
+		&i.SenderName,
+		&i.SenderPhone,
+		&i.Origin,
+		&i.RecipientName,
+		&i.RecipientPhone,
+		&i.RecipientEmail,
+		&i.RecipientID,
+		&i.RecipientAddress,
+		&i.Destination,
+		&i.CargoType,
+		&i.Weight,
+		&i.Cost,
+		&i.UpdatedAt,
+		&i.ServiceLevel,
+		&i.CodAmount,
+		&i.CodCurrency,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Version,
+		&i.CustomFields,
+		pq.Array(&i.Tags),
+		&i.ReturnOf,
+		&i.ReturnID,
+		&i.ReturnReason,
 	)
 	return i, err
 }
 
+const getShipmentConsolidation = `-- name: GetShipmentConsolidation :one
+SELECT c.master_awb FROM consolidation_shipments s
+JOIN consolidations c ON c.id = s.consolidation_id
+WHERE s.company_id = $1 AND s.tracking_id = $2
+`
+
+type GetShipmentConsolidationParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+}
+
+func (q *Queries) GetShipmentConsolidation(ctx context.Context, arg GetShipmentConsolidationParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getShipmentConsolidation, arg.CompanyID, arg.TrackingID)
+	var master_awb string
+	err := row.Scan(&master_awb)
+	return master_awb, err
+}
+
+const getShipmentStatuses = `-- name: GetShipmentStatuses :many
+SELECT tracking_id, status, version FROM Shipment WHERE company_id = $1 AND tracking_id = ANY($2::text[]) AND deleted_at IS NULL
+`
+
+type GetShipmentStatusesParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+type GetShipmentStatusesRow struct {
+	TrackingID string         `json:"tracking_id"`
+	Status     sql.NullString `json:"status"`
+	Version    int32          `json:"version"`
+}
+
+func (q *Queries) GetShipmentStatuses(ctx context.Context, arg GetShipmentStatusesParams) ([]GetShipmentStatusesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getShipmentStatuses, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetShipmentStatusesRow
+	for rows.Next() {
+		var i GetShipmentStatusesRow
+		if err := rows.Scan(&i.TrackingID, &i.Status, &i.Version); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getSystemConfig = `-- name: GetSystemConfig :one
 SELECT value FROM SystemConfig WHERE company_id = $1 AND key = $2
 `
@@ -849,12 +1656,1705 @@ func (q *Queries) HasAuthorizedGroups(ctx context.Context, companyID uuid.UUID)
 	return count, err
 }
 
-const listAllShipments = `-- name: ListAllShipments :many
-SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at FROM Shipment WHERE company_id = $1 ORDER BY created_at DESC
+const insertCheckpoint = `-- name: InsertCheckpoint :one
+INSERT INTO shipment_checkpoints (company_id, tracking_id, latitude, longitude, accuracy_m, label, live, source, actor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, company_id, tracking_id, latitude, longitude, accuracy_m, label, live, source, actor, created_at
 `
 
-func (q *Queries) ListAllShipments(ctx context.Context, companyID uuid.NullUUID) ([]Shipment, error) {
-	rows, err := q.db.QueryContext(ctx, listAllShipments, companyID)
+type InsertCheckpointParams struct {
+	CompanyID  uuid.UUID      `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	Latitude   float64        `json:"latitude"`
+	Longitude  float64        `json:"longitude"`
+	AccuracyM  sql.NullInt32  `json:"accuracy_m"`
+	Label      string         `json:"label"`
+	Live       bool           `json:"live"`
+	Source     string         `json:"source"`
+	Actor      sql.NullString `json:"actor"`
+}
+
+func (q *Queries) InsertCheckpoint(ctx context.Context, arg InsertCheckpointParams) (ShipmentCheckpoint, error) {
+	row := q.db.QueryRowContext(ctx, insertCheckpoint,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Latitude,
+		arg.Longitude,
+		arg.AccuracyM,
+		arg.Label,
+		arg.Live,
+		arg.Source,
+		arg.Actor,
+	)
+	var i ShipmentCheckpoint
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.AccuracyM,
+		&i.Label,
+		&i.Live,
+		&i.Source,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertCodEntry = `-- name: InsertCodEntry :one
+INSERT INTO cod_ledger (company_id, tracking_id, entry_type, amount, currency, reference, note, actor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, company_id, tracking_id, entry_type, amount, currency, reference, note, actor, created_at
+`
+
+type InsertCodEntryParams struct {
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID sql.NullString `json:"tracking_id"`
+	EntryType  string         `json:"entry_type"`
+	Amount     float64        `json:"amount"`
+	Currency   string         `json:"currency"`
+	Reference  sql.NullString `json:"reference"`
+	Note       sql.NullString `json:"note"`
+	Actor      sql.NullString `json:"actor"`
+}
+
+func (q *Queries) InsertCodEntry(ctx context.Context, arg InsertCodEntryParams) (CodLedger, error) {
+	row := q.db.QueryRowContext(ctx, insertCodEntry,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.EntryType,
+		arg.Amount,
+		arg.Currency,
+		arg.Reference,
+		arg.Note,
+		arg.Actor,
+	)
+	var i CodLedger
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.EntryType,
+		&i.Amount,
+		&i.Currency,
+		&i.Reference,
+		&i.Note,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertCustomsItem = `-- name: InsertCustomsItem :exec
+INSERT INTO customs_items (company_id, tracking_id, line_no, description, hs_code, quantity, unit_value, weight, origin_country)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type InsertCustomsItemParams struct {
+	CompanyID     uuid.NullUUID `json:"company_id"`
+	TrackingID    string        `json:"tracking_id"`
+	LineNo        int32         `json:"line_no"`
+	Description   string        `json:"description"`
+	HsCode        string        `json:"hs_code"`
+	Quantity      int32         `json:"quantity"`
+	UnitValue     float64       `json:"unit_value"`
+	Weight        float64       `json:"weight"`
+	OriginCountry string        `json:"origin_country"`
+}
+
+func (q *Queries) InsertCustomsItem(ctx context.Context, arg InsertCustomsItemParams) error {
+	_, err := q.db.ExecContext(ctx, insertCustomsItem,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.LineNo,
+		arg.Description,
+		arg.HsCode,
+		arg.Quantity,
+		arg.UnitValue,
+		arg.Weight,
+		arg.OriginCountry,
+	)
+	return err
+}
+
+const insertDeliveryAttempt = `-- name: InsertDeliveryAttempt :one
+INSERT INTO delivery_attempts (company_id, tracking_id, attempt_no, reason, note, source, actor, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, company_id, tracking_id, attempt_no, reason, note, source, actor, next_attempt_at, created_at
+`
+
+type InsertDeliveryAttemptParams struct {
+	CompanyID     uuid.NullUUID  `json:"company_id"`
+	TrackingID    string         `json:"tracking_id"`
+	AttemptNo     int32          `json:"attempt_no"`
+	Reason        string         `json:"reason"`
+	Note          sql.NullString `json:"note"`
+	Source        string         `json:"source"`
+	Actor         sql.NullString `json:"actor"`
+	NextAttemptAt sql.NullTime   `json:"next_attempt_at"`
+}
+
+func (q *Queries) InsertDeliveryAttempt(ctx context.Context, arg InsertDeliveryAttemptParams) (DeliveryAttempt, error) {
+	row := q.db.QueryRowContext(ctx, insertDeliveryAttempt,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.AttemptNo,
+		arg.Reason,
+		arg.Note,
+		arg.Source,
+		arg.Actor,
+		arg.NextAttemptAt,
+	)
+	var i DeliveryAttempt
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.AttemptNo,
+		&i.Reason,
+		&i.Note,
+		&i.Source,
+		&i.Actor,
+		&i.NextAttemptAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertPOD = `-- name: InsertPOD :one
+INSERT INTO shipment_pods (company_id, tracking_id, kind, blob_key, content_type, size_bytes, source, actor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, company_id, tracking_id, kind, blob_key, content_type, size_bytes, source, actor, created_at
+`
+
+type InsertPODParams struct {
+	CompanyID   uuid.UUID      `json:"company_id"`
+	TrackingID  string         `json:"tracking_id"`
+	Kind        string         `json:"kind"`
+	BlobKey     string         `json:"blob_key"`
+	ContentType string         `json:"content_type"`
+	SizeBytes   int64          `json:"size_bytes"`
+	Source      string         `json:"source"`
+	Actor       sql.NullString `json:"actor"`
+}
+
+func (q *Queries) InsertPOD(ctx context.Context, arg InsertPODParams) (ShipmentPod, error) {
+	row := q.db.QueryRowContext(ctx, insertPOD,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Kind,
+		arg.BlobKey,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Source,
+		arg.Actor,
+	)
+	var i ShipmentPod
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.TrackingID,
+		&i.Kind,
+		&i.BlobKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Source,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const insertShipmentChange = `-- name: InsertShipmentChange :exec
+INSERT INTO shipment_changes (company_id, tracking_id, batch_id, field, old_value, new_value, source, actor, is_undo)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type InsertShipmentChangeParams struct {
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	BatchID    uuid.UUID      `json:"batch_id"`
+	Field      string         `json:"field"`
+	OldValue   sql.NullString `json:"old_value"`
+	NewValue   sql.NullString `json:"new_value"`
+	Source     string         `json:"source"`
+	Actor      sql.NullString `json:"actor"`
+	IsUndo     bool           `json:"is_undo"`
+}
+
+func (q *Queries) InsertShipmentChange(ctx context.Context, arg InsertShipmentChangeParams) error {
+	_, err := q.db.ExecContext(ctx, insertShipmentChange,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.BatchID,
+		arg.Field,
+		arg.OldValue,
+		arg.NewValue,
+		arg.Source,
+		arg.Actor,
+		arg.IsUndo,
+	)
+	return err
+}
+
+const insertShipmentEvent = `-- name: InsertShipmentEvent :exec
+INSERT INTO shipment_events (company_id, tracking_id, status, previous_status, source, actor, description)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type InsertShipmentEventParams struct {
+	CompanyID      uuid.NullUUID  `json:"company_id"`
+	TrackingID     string         `json:"tracking_id"`
+	Status         string         `json:"status"`
+	PreviousStatus sql.NullString `json:"previous_status"`
+	Source         string         `json:"source"`
+	Actor          sql.NullString `json:"actor"`
+	Description    sql.NullString `json:"description"`
+}
+
+func (q *Queries) InsertShipmentEvent(ctx context.Context, arg InsertShipmentEventParams) error {
+	_, err := q.db.ExecContext(ctx, insertShipmentEvent,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Status,
+		arg.PreviousStatus,
+		arg.Source,
+		arg.Actor,
+		arg.Description,
+	)
+	return err
+}
+
+const insertShipmentPiece = `-- name: InsertShipmentPiece :exec
+INSERT INTO shipment_pieces (company_id, tracking_id, piece_no, weight, length_cm, width_cm, height_cm, description)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type InsertShipmentPieceParams struct {
+	CompanyID   uuid.NullUUID  `json:"company_id"`
+	TrackingID  string         `json:"tracking_id"`
+	PieceNo     int32          `json:"piece_no"`
+	Weight      float64        `json:"weight"`
+	LengthCm    float64        `json:"length_cm"`
+	WidthCm     float64        `json:"width_cm"`
+	HeightCm    float64        `json:"height_cm"`
+	Description sql.NullString `json:"description"`
+}
+
+func (q *Queries) InsertShipmentPiece(ctx context.Context, arg InsertShipmentPieceParams) error {
+	_, err := q.db.ExecContext(ctx, insertShipmentPiece,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.PieceNo,
+		arg.Weight,
+		arg.LengthCm,
+		arg.WidthCm,
+		arg.HeightCm,
+		arg.Description,
+	)
+	return err
+}
+
+const issueDeliveryOTP = `-- name: IssueDeliveryOTP :exec
+INSERT INTO delivery_otps (company_id, tracking_id, code_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (company_id, tracking_id) DO UPDATE SET
+  code_hash = EXCLUDED.code_hash,
+  failed_attempts = 0,
+  issued_at = CURRENT_TIMESTAMP,
+  confirmed_at = NULL
+`
+
+type IssueDeliveryOTPParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+	CodeHash   string    `json:"code_hash"`
+}
+
+func (q *Queries) IssueDeliveryOTP(ctx context.Context, arg IssueDeliveryOTPParams) error {
+	_, err := q.db.ExecContext(ctx, issueDeliveryOTP, arg.CompanyID, arg.TrackingID, arg.CodeHash)
+	return err
+}
+
+const listAgedPODKeys = `-- name: ListAgedPODKeys :many
+SELECT p.blob_key FROM shipment_pods p
+JOIN Shipment s ON s.company_id = p.company_id AND s.tracking_id = p.tracking_id
+WHERE p.company_id = $1 AND ((s.status = 'delivered' AND s.updated_at < $2) OR (s.created_at < $3))
+`
+
+type ListAgedPODKeysParams struct {
+	CompanyID uuid.UUID    `json:"company_id"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+func (q *Queries) ListAgedPODKeys(ctx context.Context, arg ListAgedPODKeysParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listAgedPODKeys, arg.CompanyID, arg.UpdatedAt, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var blob_key string
+		if err := rows.Scan(&blob_key); err != nil {
+			return nil, err
+		}
+		items = append(items, blob_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAgedShipments = `-- name: ListAgedShipments :many
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment
+WHERE company_id = $1 AND ((status = 'delivered' AND updated_at < $2) OR (created_at < $3))
+ORDER BY created_at ASC
+LIMIT $4
+`
+
+type ListAgedShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	UpdatedAt sql.NullTime  `json:"updated_at"`
+	CreatedAt sql.NullTime  `json:"created_at"`
+	Limit     int32         `json:"limit"`
+}
+
+func (q *Queries) ListAgedShipments(ctx context.Context, arg ListAgedShipmentsParams) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, listAgedShipments, arg.CompanyID, arg.UpdatedAt, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shipment
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.TrackingID,
+			&i.CompanyID,
+			&i.UserJid,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ScheduledTransitTime,
+			&i.OutfordeliveryTime,
+			&i.ExpectedDeliveryTime,
+			&i.SenderTimezone,
+			&i.RecipientTimezone,
+			&i.SenderName,
+			&i.SenderPhone,
+			&i.Origin,
+			&i.RecipientName,
+			&i.RecipientPhone,
+			&i.RecipientEmail,
+			&i.RecipientID,
+			&i.RecipientAddress,
+			&i.Destination,
+			&i.CargoType,
+			&i.Weight,
+			&i.Cost,
+			&i.UpdatedAt,
+			&i.ServiceLevel,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Version,
+			&i.CustomFields,
+			pq.Array(&i.Tags),
+			&i.ReturnOf,
+			&i.ReturnID,
+			&i.ReturnReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllShipments = `-- name: ListAllShipments :many
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC
+`
+
+func (q *Queries) ListAllShipments(ctx context.Context, companyID uuid.NullUUID) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, listAllShipments, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shipment
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.TrackingID,
+			&i.CompanyID,
+			&i.UserJid,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ScheduledTransitTime,
+			&i.OutfordeliveryTime,
+			&i.ExpectedDeliveryTime,
+			&i.SenderTimezone,
+			&i.RecipientTimezone,
+			&i.SenderName,
+			&i.SenderPhone,
+			&i.Origin,
+			&i.RecipientName,
+			&i.RecipientPhone,
+			&i.RecipientEmail,
+			&i.RecipientID,
+			&i.RecipientAddress,
+			&i.Destination,
+			&i.CargoType,
+			&i.Weight,
+			&i.Cost,
+			&i.UpdatedAt,
+			&i.ServiceLevel,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Version,
+			&i.CustomFields,
+			pq.Array(&i.Tags),
+			&i.ReturnOf,
+			&i.ReturnID,
+			&i.ReturnReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAssignedShipments = `-- name: ListAssignedShipments :many
+SELECT tracking_id FROM rider_assignments WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+`
+
+type ListAssignedShipmentsParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Column2   []string  `json:"column_2"`
+}
+
+func (q *Queries) ListAssignedShipments(ctx context.Context, arg ListAssignedShipmentsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listAssignedShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tracking_id string
+		if err := rows.Scan(&tracking_id); err != nil {
+			return nil, err
+		}
+		items = append(items, tracking_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAttemptsForShipments = `-- name: ListAttemptsForShipments :many
+SELECT id, company_id, tracking_id, attempt_no, reason, note, source, actor, next_attempt_at, created_at FROM delivery_attempts
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, attempt_no ASC
+`
+
+type ListAttemptsForShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+func (q *Queries) ListAttemptsForShipments(ctx context.Context, arg ListAttemptsForShipmentsParams) ([]DeliveryAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, listAttemptsForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeliveryAttempt
+	for rows.Next() {
+		var i DeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.AttemptNo,
+			&i.Reason,
+			&i.Note,
+			&i.Source,
+			&i.Actor,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChangesForShipments = `-- name: ListChangesForShipments :many
+SELECT id, company_id, tracking_id, batch_id, field, old_value, new_value, source, actor, is_undo, reverted_at, created_at FROM shipment_changes
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, created_at ASC, id ASC
+`
+
+type ListChangesForShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+func (q *Queries) ListChangesForShipments(ctx context.Context, arg ListChangesForShipmentsParams) ([]ShipmentChange, error) {
+	rows, err := q.db.QueryContext(ctx, listChangesForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentChange
+	for rows.Next() {
+		var i ShipmentChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.BatchID,
+			&i.Field,
+			&i.OldValue,
+			&i.NewValue,
+			&i.Source,
+			&i.Actor,
+			&i.IsUndo,
+			&i.RevertedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCheckpointsForShipments = `-- name: ListCheckpointsForShipments :many
+SELECT id, company_id, tracking_id, latitude, longitude, accuracy_m, label, live, source, actor, created_at FROM shipment_checkpoints
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, created_at ASC, id ASC
+`
+
+type ListCheckpointsForShipmentsParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Column2   []string  `json:"column_2"`
+}
+
+func (q *Queries) ListCheckpointsForShipments(ctx context.Context, arg ListCheckpointsForShipmentsParams) ([]ShipmentCheckpoint, error) {
+	rows, err := q.db.QueryContext(ctx, listCheckpointsForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentCheckpoint
+	for rows.Next() {
+		var i ShipmentCheckpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.AccuracyM,
+			&i.Label,
+			&i.Live,
+			&i.Source,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCodEntries = `-- name: ListCodEntries :many
+SELECT id, company_id, tracking_id, entry_type, amount, currency, reference, note, actor, created_at FROM cod_ledger
+WHERE company_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListCodEntriesParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Limit     int32         `json:"limit"`
+	Offset    int32         `json:"offset"`
+}
+
+func (q *Queries) ListCodEntries(ctx context.Context, arg ListCodEntriesParams) ([]CodLedger, error) {
+	rows, err := q.db.QueryContext(ctx, listCodEntries, arg.CompanyID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CodLedger
+	for rows.Next() {
+		var i CodLedger
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.EntryType,
+			&i.Amount,
+			&i.Currency,
+			&i.Reference,
+			&i.Note,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConsolidationShipments = `-- name: ListConsolidationShipments :many
+SELECT tracking_id FROM consolidation_shipments
+WHERE company_id = $1 AND consolidation_id = $2
+ORDER BY added_at, tracking_id
+`
+
+type ListConsolidationShipmentsParams struct {
+	CompanyID       uuid.UUID `json:"company_id"`
+	ConsolidationID int32     `json:"consolidation_id"`
+}
+
+func (q *Queries) ListConsolidationShipments(ctx context.Context, arg ListConsolidationShipmentsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listConsolidationShipments, arg.CompanyID, arg.ConsolidationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tracking_id string
+		if err := rows.Scan(&tracking_id); err != nil {
+			return nil, err
+		}
+		items = append(items, tracking_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConsolidations = `-- name: ListConsolidations :many
+SELECT c.id, c.company_id, c.master_awb, c.label, c.state, c.status, c.created_by, c.created_at, c.closed_at, c.departed_at, (SELECT COUNT(*) FROM consolidation_shipments s WHERE s.consolidation_id = c.id) AS shipment_count
+FROM consolidations c
+WHERE c.company_id = $1
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT $2
+`
+
+type ListConsolidationsParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListConsolidationsRow struct {
+	ID            int32          `json:"id"`
+	CompanyID     uuid.UUID      `json:"company_id"`
+	MasterAwb     string         `json:"master_awb"`
+	Label         sql.NullString `json:"label"`
+	State         string         `json:"state"`
+	Status        sql.NullString `json:"status"`
+	CreatedBy     sql.NullString `json:"created_by"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+	ClosedAt      sql.NullTime   `json:"closed_at"`
+	DepartedAt    sql.NullTime   `json:"departed_at"`
+	ShipmentCount int64          `json:"shipment_count"`
+}
+
+func (q *Queries) ListConsolidations(ctx context.Context, arg ListConsolidationsParams) ([]ListConsolidationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listConsolidations, arg.CompanyID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListConsolidationsRow
+	for rows.Next() {
+		var i ListConsolidationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.MasterAwb,
+			&i.Label,
+			&i.State,
+			&i.Status,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.ClosedAt,
+			&i.DepartedAt,
+			&i.ShipmentCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listContacts = `-- name: ListContacts :many
+SELECT id, company_id, alias, name, phone, phone_digits, email, address, country, id_number, shipment_count, last_used_at, created_at, updated_at FROM contacts
+WHERE company_id = $1
+  AND ($2::text = '' OR name ILIKE $2 OR alias ILIKE $2
+       OR ($3::text <> '' AND phone_digits LIKE $3))
+ORDER BY shipment_count DESC, last_used_at DESC NULLS LAST, name, id
+LIMIT $4 OFFSET $5
+`
+
+type ListContactsParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Pattern   string    `json:"pattern"`
+	Digits    string    `json:"digits"`
+	RowLimit  int32     `json:"row_limit"`
+	RowOffset int32     `json:"row_offset"`
+}
+
+func (q *Queries) ListContacts(ctx context.Context, arg ListContactsParams) ([]Contact, error) {
+	rows, err := q.db.QueryContext(ctx, listContacts,
+		arg.CompanyID,
+		arg.Pattern,
+		arg.Digits,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contact
+	for rows.Next() {
+		var i Contact
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.Alias,
+			&i.Name,
+			&i.Phone,
+			&i.PhoneDigits,
+			&i.Email,
+			&i.Address,
+			&i.Country,
+			&i.IDNumber,
+			&i.ShipmentCount,
+			&i.LastUsedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomFieldDefinitions = `-- name: ListCustomFieldDefinitions :many
+SELECT id, company_id, name, label, field_type, required, pattern, created_at, updated_at FROM custom_field_definitions WHERE company_id = $1 ORDER BY id
+`
+
+func (q *Queries) ListCustomFieldDefinitions(ctx context.Context, companyID uuid.UUID) ([]CustomFieldDefinition, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomFieldDefinitions, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomFieldDefinition
+	for rows.Next() {
+		var i CustomFieldDefinition
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.Name,
+			&i.Label,
+			&i.FieldType,
+			&i.Required,
+			&i.Pattern,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomsForShipments = `-- name: ListCustomsForShipments :many
+SELECT tracking_id, company_id, currency, export_reason, invoice_number, created_at, updated_at FROM customs_declarations
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id
+`
+
+type ListCustomsForShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+func (q *Queries) ListCustomsForShipments(ctx context.Context, arg ListCustomsForShipmentsParams) ([]CustomsDeclaration, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomsForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomsDeclaration
+	for rows.Next() {
+		var i CustomsDeclaration
+		if err := rows.Scan(
+			&i.TrackingID,
+			&i.CompanyID,
+			&i.Currency,
+			&i.ExportReason,
+			&i.InvoiceNumber,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomsItems = `-- name: ListCustomsItems :many
+SELECT id, company_id, tracking_id, line_no, description, hs_code, quantity, unit_value, weight, origin_country FROM customs_items
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY line_no ASC
+`
+
+type ListCustomsItemsParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) ListCustomsItems(ctx context.Context, arg ListCustomsItemsParams) ([]CustomsItem, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomsItems, arg.CompanyID, arg.TrackingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomsItem
+	for rows.Next() {
+		var i CustomsItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.LineNo,
+			&i.Description,
+			&i.HsCode,
+			&i.Quantity,
+			&i.UnitValue,
+			&i.Weight,
+			&i.OriginCountry,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomsItemsForShipments = `-- name: ListCustomsItemsForShipments :many
+SELECT id, company_id, tracking_id, line_no, description, hs_code, quantity, unit_value, weight, origin_country FROM customs_items
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, line_no ASC
+`
+
+type ListCustomsItemsForShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+func (q *Queries) ListCustomsItemsForShipments(ctx context.Context, arg ListCustomsItemsForShipmentsParams) ([]CustomsItem, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomsItemsForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CustomsItem
+	for rows.Next() {
+		var i CustomsItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.LineNo,
+			&i.Description,
+			&i.HsCode,
+			&i.Quantity,
+			&i.UnitValue,
+			&i.Weight,
+			&i.OriginCountry,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeliveryAttempts = `-- name: ListDeliveryAttempts :many
+SELECT id, company_id, tracking_id, attempt_no, reason, note, source, actor, next_attempt_at, created_at FROM delivery_attempts WHERE company_id = $1 AND tracking_id = $2 ORDER BY attempt_no
+`
+
+type ListDeliveryAttemptsParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) ListDeliveryAttempts(ctx context.Context, arg ListDeliveryAttemptsParams) ([]DeliveryAttempt, error) {
+	rows, err := q.db.QueryContext(ctx, listDeliveryAttempts, arg.CompanyID, arg.TrackingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeliveryAttempt
+	for rows.Next() {
+		var i DeliveryAttempt
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.AttemptNo,
+			&i.Reason,
+			&i.Note,
+			&i.Source,
+			&i.Actor,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueTransitions = `-- name: ListDueTransitions :many
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment
+WHERE company_id = $1 AND deleted_at IS NULL AND (
+  (status = 'pending' AND scheduled_transit_time <= $2::timestamp) OR
+  (status = 'intransit' AND outfordelivery_time <= $2::timestamp) OR
+  (status = 'outfordelivery' AND expected_delivery_time <= $2::timestamp)
+)
+ORDER BY created_at
+`
+
+type ListDueTransitionsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Now       time.Time     `json:"now"`
+}
+
+func (q *Queries) ListDueTransitions(ctx context.Context, arg ListDueTransitionsParams) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, listDueTransitions, arg.CompanyID, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shipment
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.TrackingID,
+			&i.CompanyID,
+			&i.UserJid,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ScheduledTransitTime,
+			&i.OutfordeliveryTime,
+			&i.ExpectedDeliveryTime,
+			&i.SenderTimezone,
+			&i.RecipientTimezone,
+			&i.SenderName,
+			&i.SenderPhone,
+			&i.Origin,
+			&i.RecipientName,
+			&i.RecipientPhone,
+			&i.RecipientEmail,
+			&i.RecipientID,
+			&i.RecipientAddress,
+			&i.Destination,
+			&i.CargoType,
+			&i.Weight,
+			&i.Cost,
+			&i.UpdatedAt,
+			&i.ServiceLevel,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Version,
+			&i.CustomFields,
+			pq.Array(&i.Tags),
+			&i.ReturnOf,
+			&i.ReturnID,
+			&i.ReturnReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsForShipments = `-- name: ListEventsForShipments :many
+SELECT id, company_id, tracking_id, status, previous_status, source, actor, description, created_at FROM shipment_events
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, created_at ASC, id ASC
+`
+
+type ListEventsForShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+func (q *Queries) ListEventsForShipments(ctx context.Context, arg ListEventsForShipmentsParams) ([]ShipmentEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listEventsForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentEvent
+	for rows.Next() {
+		var i ShipmentEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.Status,
+			&i.PreviousStatus,
+			&i.Source,
+			&i.Actor,
+			&i.Description,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredTrash = `-- name: ListExpiredTrash :many
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment
+WHERE company_id = $1 AND deleted_at IS NOT NULL AND deleted_at < $2
+ORDER BY deleted_at ASC
+LIMIT $3
+`
+
+type ListExpiredTrashParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	DeletedAt sql.NullTime  `json:"deleted_at"`
+	Limit     int32         `json:"limit"`
+}
+
+func (q *Queries) ListExpiredTrash(ctx context.Context, arg ListExpiredTrashParams) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredTrash, arg.CompanyID, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shipment
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.TrackingID,
+			&i.CompanyID,
+			&i.UserJid,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ScheduledTransitTime,
+			&i.OutfordeliveryTime,
+			&i.ExpectedDeliveryTime,
+			&i.SenderTimezone,
+			&i.RecipientTimezone,
+			&i.SenderName,
+			&i.SenderPhone,
+			&i.Origin,
+			&i.RecipientName,
+			&i.RecipientPhone,
+			&i.RecipientEmail,
+			&i.RecipientID,
+			&i.RecipientAddress,
+			&i.Destination,
+			&i.CargoType,
+			&i.Weight,
+			&i.Cost,
+			&i.UpdatedAt,
+			&i.ServiceLevel,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Version,
+			&i.CustomFields,
+			pq.Array(&i.Tags),
+			&i.ReturnOf,
+			&i.ReturnID,
+			&i.ReturnReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHolidays = `-- name: ListHolidays :many
+SELECT id, company_id, country, holiday_date, name, created_at FROM holidays
+WHERE company_id = $1 AND holiday_date >= $2 AND holiday_date <= $3
+ORDER BY holiday_date ASC, country ASC
+`
+
+type ListHolidaysParams struct {
+	CompanyID     uuid.UUID `json:"company_id"`
+	HolidayDate   time.Time `json:"holiday_date"`
+	HolidayDate_2 time.Time `json:"holiday_date_2"`
+}
+
+func (q *Queries) ListHolidays(ctx context.Context, arg ListHolidaysParams) ([]Holiday, error) {
+	rows, err := q.db.QueryContext(ctx, listHolidays, arg.CompanyID, arg.HolidayDate, arg.HolidayDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Holiday
+	for rows.Next() {
+		var i Holiday
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.Country,
+			&i.HolidayDate,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLastChangeBatch = `-- name: ListLastChangeBatch :many
+SELECT id, company_id, tracking_id, batch_id, field, old_value, new_value, source, actor, is_undo, reverted_at, created_at FROM shipment_changes
+WHERE company_id = $1 AND batch_id = (
+    SELECT c.batch_id FROM shipment_changes c
+    WHERE c.company_id = $1 AND c.actor = $2 AND c.is_undo = FALSE AND c.reverted_at IS NULL
+    ORDER BY c.created_at DESC, c.id DESC
+    LIMIT 1
+)
+ORDER BY id DESC
+`
+
+type ListLastChangeBatchParams struct {
+	CompanyID uuid.NullUUID  `json:"company_id"`
+	Actor     sql.NullString `json:"actor"`
+}
+
+func (q *Queries) ListLastChangeBatch(ctx context.Context, arg ListLastChangeBatchParams) ([]ShipmentChange, error) {
+	rows, err := q.db.QueryContext(ctx, listLastChangeBatch, arg.CompanyID, arg.Actor)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentChange
+	for rows.Next() {
+		var i ShipmentChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.BatchID,
+			&i.Field,
+			&i.OldValue,
+			&i.NewValue,
+			&i.Source,
+			&i.Actor,
+			&i.IsUndo,
+			&i.RevertedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPODKeysForShipments = `-- name: ListPODKeysForShipments :many
+SELECT blob_key FROM shipment_pods WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+`
+
+type ListPODKeysForShipmentsParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Column2   []string  `json:"column_2"`
+}
+
+func (q *Queries) ListPODKeysForShipments(ctx context.Context, arg ListPODKeysForShipmentsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listPODKeysForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var blob_key string
+		if err := rows.Scan(&blob_key); err != nil {
+			return nil, err
+		}
+		items = append(items, blob_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPODs = `-- name: ListPODs :many
+SELECT id, company_id, tracking_id, kind, blob_key, content_type, size_bytes, source, actor, created_at FROM shipment_pods WHERE company_id = $1 AND tracking_id = $2 ORDER BY created_at, id
+`
+
+type ListPODsParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+}
+
+func (q *Queries) ListPODs(ctx context.Context, arg ListPODsParams) ([]ShipmentPod, error) {
+	rows, err := q.db.QueryContext(ctx, listPODs, arg.CompanyID, arg.TrackingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentPod
+	for rows.Next() {
+		var i ShipmentPod
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.Kind,
+			&i.BlobKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Source,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPiecesForShipments = `-- name: ListPiecesForShipments :many
+SELECT id, company_id, tracking_id, piece_no, weight, length_cm, width_cm, height_cm, description FROM shipment_pieces
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, piece_no ASC
+`
+
+type ListPiecesForShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+func (q *Queries) ListPiecesForShipments(ctx context.Context, arg ListPiecesForShipmentsParams) ([]ShipmentPiece, error) {
+	rows, err := q.db.QueryContext(ctx, listPiecesForShipments, arg.CompanyID, pq.Array(arg.Column2))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentPiece
+	for rows.Next() {
+		var i ShipmentPiece
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.PieceNo,
+			&i.Weight,
+			&i.LengthCm,
+			&i.WidthCm,
+			&i.HeightCm,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRiderPickups = `-- name: ListRiderPickups :many
+SELECT a.tracking_id FROM rider_assignments a
+JOIN shipment s ON s.company_id = a.company_id AND s.tracking_id = a.tracking_id
+WHERE a.company_id = $1 AND a.rider_id = $2 AND s.status = 'intransit' AND s.deleted_at IS NULL
+ORDER BY a.assigned_at
+`
+
+type ListRiderPickupsParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	RiderID   int32     `json:"rider_id"`
+}
+
+func (q *Queries) ListRiderPickups(ctx context.Context, arg ListRiderPickupsParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listRiderPickups, arg.CompanyID, arg.RiderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tracking_id string
+		if err := rows.Scan(&tracking_id); err != nil {
+			return nil, err
+		}
+		items = append(items, tracking_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRiders = `-- name: ListRiders :many
+SELECT id, company_id, name, phone, phone_digits, active, created_at, updated_at FROM riders WHERE company_id = $1 ORDER BY name, id
+`
+
+func (q *Queries) ListRiders(ctx context.Context, companyID uuid.UUID) ([]Rider, error) {
+	rows, err := q.db.QueryContext(ctx, listRiders, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Rider
+	for rows.Next() {
+		var i Rider
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.Name,
+			&i.Phone,
+			&i.PhoneDigits,
+			&i.Active,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShipmentChanges = `-- name: ListShipmentChanges :many
+SELECT id, company_id, tracking_id, batch_id, field, old_value, new_value, source, actor, is_undo, reverted_at, created_at FROM shipment_changes
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $4
+`
+
+type ListShipmentChangesParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+	Limit      int32         `json:"limit"`
+	Offset     int32         `json:"offset"`
+}
+
+func (q *Queries) ListShipmentChanges(ctx context.Context, arg ListShipmentChangesParams) ([]ShipmentChange, error) {
+	rows, err := q.db.QueryContext(ctx, listShipmentChanges, arg.CompanyID, arg.TrackingID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentChange
+	for rows.Next() {
+		var i ShipmentChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.BatchID,
+			&i.Field,
+			&i.OldValue,
+			&i.NewValue,
+			&i.Source,
+			&i.Actor,
+			&i.IsUndo,
+			&i.RevertedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShipmentEvents = `-- name: ListShipmentEvents :many
+SELECT id, company_id, tracking_id, status, previous_status, source, actor, description, created_at FROM shipment_events
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY created_at ASC, id ASC
+`
+
+type ListShipmentEventsParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) ListShipmentEvents(ctx context.Context, arg ListShipmentEventsParams) ([]ShipmentEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listShipmentEvents, arg.CompanyID, arg.TrackingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentEvent
+	for rows.Next() {
+		var i ShipmentEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.Status,
+			&i.PreviousStatus,
+			&i.Source,
+			&i.Actor,
+			&i.Description,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShipmentPieces = `-- name: ListShipmentPieces :many
+SELECT id, company_id, tracking_id, piece_no, weight, length_cm, width_cm, height_cm, description FROM shipment_pieces
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY piece_no ASC
+`
+
+type ListShipmentPiecesParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) ListShipmentPieces(ctx context.Context, arg ListShipmentPiecesParams) ([]ShipmentPiece, error) {
+	rows, err := q.db.QueryContext(ctx, listShipmentPieces, arg.CompanyID, arg.TrackingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShipmentPiece
+	for rows.Next() {
+		var i ShipmentPiece
+		if err := rows.Scan(
+			&i.ID,
+			&i.CompanyID,
+			&i.TrackingID,
+			&i.PieceNo,
+			&i.Weight,
+			&i.LengthCm,
+			&i.WidthCm,
+			&i.HeightCm,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShipments = `-- name: ListShipments :many
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $2 OFFSET $3
+`
+
+type ListShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Limit     int32         `json:"limit"`
+	Offset    int32         `json:"offset"`
+}
+
+func (q *Queries) ListShipments(ctx context.Context, arg ListShipmentsParams) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, listShipments, arg.CompanyID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -886,6 +3386,17 @@ func (q *Queries) ListAllShipments(ctx context.Context, companyID uuid.NullUUID)
 			&i.Weight,
 			&i.Cost,
 			&i.UpdatedAt,
+			&i.ServiceLevel,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Version,
+			&i.CustomFields,
+			pq.Array(&i.Tags),
+			&i.ReturnOf,
+			&i.ReturnID,
+			&i.ReturnReason,
 		); err != nil {
 			return nil, err
 		}
@@ -900,18 +3411,55 @@ func (q *Queries) ListAllShipments(ctx context.Context, companyID uuid.NullUUID)
 	return items, nil
 }
 
-const listShipments = `-- name: ListShipments :many
-SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at FROM Shipment WHERE company_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
+const listTrashedPODKeys = `-- name: ListTrashedPODKeys :many
+SELECT p.blob_key FROM shipment_pods p
+JOIN Shipment s ON s.company_id = p.company_id AND s.tracking_id = p.tracking_id
+WHERE p.company_id = $1 AND s.deleted_at IS NOT NULL AND s.deleted_at < $2
 `
 
-type ListShipmentsParams struct {
+type ListTrashedPODKeysParams struct {
+	CompanyID uuid.UUID    `json:"company_id"`
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+func (q *Queries) ListTrashedPODKeys(ctx context.Context, arg ListTrashedPODKeysParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedPODKeys, arg.CompanyID, arg.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var blob_key string
+		if err := rows.Scan(&blob_key); err != nil {
+			return nil, err
+		}
+		items = append(items, blob_key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTrashedShipments = `-- name: ListTrashedShipments :many
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment
+WHERE company_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListTrashedShipmentsParams struct {
 	CompanyID uuid.NullUUID `json:"company_id"`
 	Limit     int32         `json:"limit"`
 	Offset    int32         `json:"offset"`
 }
 
-func (q *Queries) ListShipments(ctx context.Context, arg ListShipmentsParams) ([]Shipment, error) {
-	rows, err := q.db.QueryContext(ctx, listShipments, arg.CompanyID, arg.Limit, arg.Offset)
+func (q *Queries) ListTrashedShipments(ctx context.Context, arg ListTrashedShipmentsParams) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedShipments, arg.CompanyID, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -943,6 +3491,72 @@ func (q *Queries) ListShipments(ctx context.Context, arg ListShipmentsParams) ([
 			&i.Weight,
 			&i.Cost,
 			&i.UpdatedAt,
+			&i.ServiceLevel,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Version,
+			&i.CustomFields,
+			pq.Array(&i.Tags),
+			&i.ReturnOf,
+			&i.ReturnID,
+			&i.ReturnReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUncollectedCod = `-- name: ListUncollectedCod :many
+SELECT s.tracking_id, s.status, s.recipient_name, s.destination, s.cod_amount, s.cod_currency, s.created_at
+FROM Shipment s
+WHERE s.company_id = $1 AND s.cod_amount > 0 AND s.deleted_at IS NULL AND s.status = 'delivered'
+  AND NOT EXISTS (SELECT 1 FROM cod_ledger l WHERE l.tracking_id = s.tracking_id AND l.entry_type = 'collection')
+ORDER BY s.created_at ASC
+LIMIT $2
+`
+
+type ListUncollectedCodParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Limit     int32         `json:"limit"`
+}
+
+type ListUncollectedCodRow struct {
+	TrackingID    string         `json:"tracking_id"`
+	Status        sql.NullString `json:"status"`
+	RecipientName sql.NullString `json:"recipient_name"`
+	Destination   sql.NullString `json:"destination"`
+	CodAmount     float64        `json:"cod_amount"`
+	CodCurrency   sql.NullString `json:"cod_currency"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) ListUncollectedCod(ctx context.Context, arg ListUncollectedCodParams) ([]ListUncollectedCodRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUncollectedCod, arg.CompanyID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUncollectedCodRow
+	for rows.Next() {
+		var i ListUncollectedCodRow
+		if err := rows.Scan(
+			&i.TrackingID,
+			&i.Status,
+			&i.RecipientName,
+			&i.Destination,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -979,6 +3593,128 @@ func (q *Queries) LogAudit(ctx context.Context, arg LogAuditParams) error {
 	return err
 }
 
+const markChangeBatchReverted = `-- name: MarkChangeBatchReverted :exec
+UPDATE shipment_changes SET reverted_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND batch_id = $2 AND reverted_at IS NULL
+`
+
+type MarkChangeBatchRevertedParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	BatchID   uuid.UUID     `json:"batch_id"`
+}
+
+func (q *Queries) MarkChangeBatchReverted(ctx context.Context, arg MarkChangeBatchRevertedParams) error {
+	_, err := q.db.ExecContext(ctx, markChangeBatchReverted, arg.CompanyID, arg.BatchID)
+	return err
+}
+
+const markRiderPickedUp = `-- name: MarkRiderPickedUp :exec
+UPDATE rider_assignments SET picked_up_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2
+`
+
+type MarkRiderPickedUpParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+}
+
+func (q *Queries) MarkRiderPickedUp(ctx context.Context, arg MarkRiderPickedUpParams) error {
+	_, err := q.db.ExecContext(ctx, markRiderPickedUp, arg.CompanyID, arg.TrackingID)
+	return err
+}
+
+const markShipmentReturned = `-- name: MarkShipmentReturned :execresult
+UPDATE Shipment SET status = 'returned', return_id = $3, return_reason = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND version = $5 AND return_id IS NULL AND deleted_at IS NULL
+`
+
+type MarkShipmentReturnedParams struct {
+	CompanyID    uuid.NullUUID  `json:"company_id"`
+	TrackingID   string         `json:"tracking_id"`
+	ReturnID     sql.NullString `json:"return_id"`
+	ReturnReason sql.NullString `json:"return_reason"`
+	Version      int32          `json:"version"`
+}
+
+func (q *Queries) MarkShipmentReturned(ctx context.Context, arg MarkShipmentReturnedParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, markShipmentReturned,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.ReturnID,
+		arg.ReturnReason,
+		arg.Version,
+	)
+}
+
+const nextTrackingSequence = `-- name: NextTrackingSequence :one
+INSERT INTO tracking_counters (company_id, scope, value)
+VALUES ($1, $2, 1)
+ON CONFLICT (company_id, scope) DO UPDATE SET value = tracking_counters.value + 1
+RETURNING value
+`
+
+type NextTrackingSequenceParams struct {
+	CompanyID uuid.UUID `json:"company_id"`
+	Scope     string    `json:"scope"`
+}
+
+func (q *Queries) NextTrackingSequence(ctx context.Context, arg NextTrackingSequenceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, nextTrackingSequence, arg.CompanyID, arg.Scope)
+	var value int64
+	err := row.Scan(&value)
+	return value, err
+}
+
+const openCheckin = `-- name: OpenCheckin :exec
+INSERT INTO checkin_sessions (company_id, sender_phone, tracking_id, expires_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(mins => $4::int))
+ON CONFLICT (company_id, sender_phone) DO UPDATE SET
+  tracking_id = EXCLUDED.tracking_id,
+  expires_at = EXCLUDED.expires_at
+`
+
+type OpenCheckinParams struct {
+	CompanyID   uuid.UUID `json:"company_id"`
+	SenderPhone string    `json:"sender_phone"`
+	TrackingID  string    `json:"tracking_id"`
+	Minutes     int32     `json:"minutes"`
+}
+
+func (q *Queries) OpenCheckin(ctx context.Context, arg OpenCheckinParams) error {
+	_, err := q.db.ExecContext(ctx, openCheckin,
+		arg.CompanyID,
+		arg.SenderPhone,
+		arg.TrackingID,
+		arg.Minutes,
+	)
+	return err
+}
+
+const purgeShipments = `-- name: PurgeShipments :execresult
+DELETE FROM Shipment WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+`
+
+type PurgeShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	Column2   []string      `json:"column_2"`
+}
+
+func (q *Queries) PurgeShipments(ctx context.Context, arg PurgeShipmentsParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, purgeShipments, arg.CompanyID, pq.Array(arg.Column2))
+}
+
+const purgeTrashedShipments = `-- name: PurgeTrashedShipments :execresult
+DELETE FROM Shipment WHERE company_id = $1 AND deleted_at IS NOT NULL AND deleted_at < $2
+`
+
+type PurgeTrashedShipmentsParams struct {
+	CompanyID uuid.NullUUID `json:"company_id"`
+	DeletedAt sql.NullTime  `json:"deleted_at"`
+}
+
+func (q *Queries) PurgeTrashedShipments(ctx context.Context, arg PurgeTrashedShipmentsParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, purgeTrashedShipments, arg.CompanyID, arg.DeletedAt)
+}
+
 const recordEvent = `-- name: RecordEvent :exec
 INSERT INTO Telemetry (company_id, event_type, metadata, created_at)
 VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
@@ -1002,23 +3738,299 @@ ON CONFLICT (reference) DO NOTHING
 RETURNING id
 `
 
-type RecordPaymentParams struct {
-	CompanyID uuid.NullUUID   `json:"company_id"`
-	Reference string          `json:"reference"`
-	Amount    sql.NullFloat64 `json:"amount"`
-	Status    sql.NullString  `json:"status"`
+type RecordPaymentParams struct {
+	CompanyID uuid.NullUUID   `json:"company_id"`
+	Reference string          `json:"reference"`
+	Amount    sql.NullFloat64 `json:"amount"`
+	Status    sql.NullString  `json:"status"`
+}
+
+func (q *Queries) RecordPayment(ctx context.Context, arg RecordPaymentParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, recordPayment,
+		arg.CompanyID,
+		arg.Reference,
+		arg.Amount,
+		arg.Status,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const releaseDeliveryOTPAttempt = `-- name: ReleaseDeliveryOTPAttempt :exec
+UPDATE delivery_otps SET failed_attempts = failed_attempts - 1
+WHERE company_id = $1 AND tracking_id = $2 AND failed_attempts > 0
+`
+
+type ReleaseDeliveryOTPAttemptParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
+}
+
+func (q *Queries) ReleaseDeliveryOTPAttempt(ctx context.Context, arg ReleaseDeliveryOTPAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, releaseDeliveryOTPAttempt, arg.CompanyID, arg.TrackingID)
+	return err
+}
+
+const removeFromConsolidation = `-- name: RemoveFromConsolidation :execresult
+DELETE FROM consolidation_shipments WHERE company_id = $1 AND consolidation_id = $2 AND tracking_id = $3
+`
+
+type RemoveFromConsolidationParams struct {
+	CompanyID       uuid.UUID `json:"company_id"`
+	ConsolidationID int32     `json:"consolidation_id"`
+	TrackingID      string    `json:"tracking_id"`
+}
+
+func (q *Queries) RemoveFromConsolidation(ctx context.Context, arg RemoveFromConsolidationParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, removeFromConsolidation, arg.CompanyID, arg.ConsolidationID, arg.TrackingID)
+}
+
+const rescheduleDelivery = `-- name: RescheduleDelivery :execresult
+UPDATE Shipment SET status = $1,
+    outfordelivery_time = COALESCE($2, outfordelivery_time),
+    expected_delivery_time = COALESCE($3, expected_delivery_time),
+    version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $4 AND tracking_id = $5 AND version = $6 AND deleted_at IS NULL
+`
+
+type RescheduleDeliveryParams struct {
+	Status               sql.NullString `json:"status"`
+	OutfordeliveryTime   sql.NullTime   `json:"outfordelivery_time"`
+	ExpectedDeliveryTime sql.NullTime   `json:"expected_delivery_time"`
+	CompanyID            uuid.NullUUID  `json:"company_id"`
+	TrackingID           string         `json:"tracking_id"`
+	Version              int32          `json:"version"`
+}
+
+func (q *Queries) RescheduleDelivery(ctx context.Context, arg RescheduleDeliveryParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, rescheduleDelivery,
+		arg.Status,
+		arg.OutfordeliveryTime,
+		arg.ExpectedDeliveryTime,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Version,
+	)
+}
+
+const reserveDeliveryOTPAttempt = `-- name: ReserveDeliveryOTPAttempt :one
+UPDATE delivery_otps SET failed_attempts = failed_attempts + 1
+WHERE company_id = $1 AND tracking_id = $2 AND failed_attempts < $3
+RETURNING failed_attempts
+`
+
+type ReserveDeliveryOTPAttemptParams struct {
+	CompanyID      uuid.UUID `json:"company_id"`
+	TrackingID     string    `json:"tracking_id"`
+	FailedAttempts int32     `json:"failed_attempts"`
+}
+
+func (q *Queries) ReserveDeliveryOTPAttempt(ctx context.Context, arg ReserveDeliveryOTPAttemptParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, reserveDeliveryOTPAttempt, arg.CompanyID, arg.TrackingID, arg.FailedAttempts)
+	var failed_attempts int32
+	err := row.Scan(&failed_attempts)
+	return failed_attempts, err
+}
+
+const restoreCheckpoint = `-- name: RestoreCheckpoint :exec
+INSERT INTO shipment_checkpoints (company_id, tracking_id, latitude, longitude, accuracy_m, label, live, source, actor, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+type RestoreCheckpointParams struct {
+	CompanyID  uuid.UUID      `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	Latitude   float64        `json:"latitude"`
+	Longitude  float64        `json:"longitude"`
+	AccuracyM  sql.NullInt32  `json:"accuracy_m"`
+	Label      string         `json:"label"`
+	Live       bool           `json:"live"`
+	Source     string         `json:"source"`
+	Actor      sql.NullString `json:"actor"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) RestoreCheckpoint(ctx context.Context, arg RestoreCheckpointParams) error {
+	_, err := q.db.ExecContext(ctx, restoreCheckpoint,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Latitude,
+		arg.Longitude,
+		arg.AccuracyM,
+		arg.Label,
+		arg.Live,
+		arg.Source,
+		arg.Actor,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const restoreCustomsDeclaration = `-- name: RestoreCustomsDeclaration :exec
+INSERT INTO customs_declarations (tracking_id, company_id, currency, export_reason, invoice_number, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+type RestoreCustomsDeclarationParams struct {
+	TrackingID    string         `json:"tracking_id"`
+	CompanyID     uuid.NullUUID  `json:"company_id"`
+	Currency      string         `json:"currency"`
+	ExportReason  string         `json:"export_reason"`
+	InvoiceNumber sql.NullString `json:"invoice_number"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+	UpdatedAt     sql.NullTime   `json:"updated_at"`
+}
+
+func (q *Queries) RestoreCustomsDeclaration(ctx context.Context, arg RestoreCustomsDeclarationParams) error {
+	_, err := q.db.ExecContext(ctx, restoreCustomsDeclaration,
+		arg.TrackingID,
+		arg.CompanyID,
+		arg.Currency,
+		arg.ExportReason,
+		arg.InvoiceNumber,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const restoreDeliveryAttempt = `-- name: RestoreDeliveryAttempt :exec
+INSERT INTO delivery_attempts (company_id, tracking_id, attempt_no, reason, note, source, actor, next_attempt_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type RestoreDeliveryAttemptParams struct {
+	CompanyID     uuid.NullUUID  `json:"company_id"`
+	TrackingID    string         `json:"tracking_id"`
+	AttemptNo     int32          `json:"attempt_no"`
+	Reason        string         `json:"reason"`
+	Note          sql.NullString `json:"note"`
+	Source        string         `json:"source"`
+	Actor         sql.NullString `json:"actor"`
+	NextAttemptAt sql.NullTime   `json:"next_attempt_at"`
+	CreatedAt     sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) RestoreDeliveryAttempt(ctx context.Context, arg RestoreDeliveryAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, restoreDeliveryAttempt,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.AttemptNo,
+		arg.Reason,
+		arg.Note,
+		arg.Source,
+		arg.Actor,
+		arg.NextAttemptAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const restoreShipment = `-- name: RestoreShipment :execresult
+UPDATE Shipment SET deleted_at = NULL, deleted_by = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NOT NULL
+`
+
+type RestoreShipmentParams struct {
+	CompanyID  uuid.NullUUID `json:"company_id"`
+	TrackingID string        `json:"tracking_id"`
+}
+
+func (q *Queries) RestoreShipment(ctx context.Context, arg RestoreShipmentParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, restoreShipment, arg.CompanyID, arg.TrackingID)
+}
+
+const restoreShipmentChange = `-- name: RestoreShipmentChange :exec
+INSERT INTO shipment_changes (company_id, tracking_id, batch_id, field, old_value, new_value, source, actor, is_undo, reverted_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+`
+
+type RestoreShipmentChangeParams struct {
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	BatchID    uuid.UUID      `json:"batch_id"`
+	Field      string         `json:"field"`
+	OldValue   sql.NullString `json:"old_value"`
+	NewValue   sql.NullString `json:"new_value"`
+	Source     string         `json:"source"`
+	Actor      sql.NullString `json:"actor"`
+	IsUndo     bool           `json:"is_undo"`
+	RevertedAt sql.NullTime   `json:"reverted_at"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) RestoreShipmentChange(ctx context.Context, arg RestoreShipmentChangeParams) error {
+	_, err := q.db.ExecContext(ctx, restoreShipmentChange,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.BatchID,
+		arg.Field,
+		arg.OldValue,
+		arg.NewValue,
+		arg.Source,
+		arg.Actor,
+		arg.IsUndo,
+		arg.RevertedAt,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const restoreShipmentEvent = `-- name: RestoreShipmentEvent :exec
+INSERT INTO shipment_events (company_id, tracking_id, status, previous_status, source, actor, description, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type RestoreShipmentEventParams struct {
+	CompanyID      uuid.NullUUID  `json:"company_id"`
+	TrackingID     string         `json:"tracking_id"`
+	Status         string         `json:"status"`
+	PreviousStatus sql.NullString `json:"previous_status"`
+	Source         string         `json:"source"`
+	Actor          sql.NullString `json:"actor"`
+	Description    sql.NullString `json:"description"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) RestoreShipmentEvent(ctx context.Context, arg RestoreShipmentEventParams) error {
+	_, err := q.db.ExecContext(ctx, restoreShipmentEvent,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Status,
+		arg.PreviousStatus,
+		arg.Source,
+		arg.Actor,
+		arg.Description,
+		arg.CreatedAt,
+	)
+	return err
+}
+
+const restoreShipmentState = `-- name: RestoreShipmentState :exec
+UPDATE Shipment SET version = $3, deleted_at = $4, deleted_by = $5, return_id = $6
+WHERE company_id = $1 AND tracking_id = $2
+`
+
+type RestoreShipmentStateParams struct {
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	Version    int32          `json:"version"`
+	DeletedAt  sql.NullTime   `json:"deleted_at"`
+	DeletedBy  sql.NullString `json:"deleted_by"`
+	ReturnID   sql.NullString `json:"return_id"`
 }
 
-func (q *Queries) RecordPayment(ctx context.Context, arg RecordPaymentParams) (int32, error) {
-	row := q.db.QueryRowContext(ctx, recordPayment,
+func (q *Queries) RestoreShipmentState(ctx context.Context, arg RestoreShipmentStateParams) error {
+	_, err := q.db.ExecContext(ctx, restoreShipmentState,
 		arg.CompanyID,
-		arg.Reference,
-		arg.Amount,
-		arg.Status,
+		arg.TrackingID,
+		arg.Version,
+		arg.DeletedAt,
+		arg.DeletedBy,
+		arg.ReturnID,
 	)
-	var id int32
-	err := row.Scan(&id)
-	return id, err
+	return err
 }
 
 const runAgedCleanup = `-- name: RunAgedCleanup :execresult
@@ -1036,6 +4048,115 @@ func (q *Queries) RunAgedCleanup(ctx context.Context, arg RunAgedCleanupParams)
 	return q.db.ExecContext(ctx, runAgedCleanup, arg.CompanyID, arg.UpdatedAt, arg.CreatedAt)
 }
 
+const searchShipments = `-- name: SearchShipments :many
+SELECT tracking_id, company_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, deleted_at, deleted_by, version, custom_fields, tags, return_of, return_id, return_reason FROM Shipment
+WHERE company_id = $1 AND deleted_at IS NULL
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::timestamp IS NULL OR created_at >= $3)
+  AND ($4::timestamp IS NULL OR created_at < $4)
+  AND ($5::text IS NULL OR destination ILIKE $5)
+  AND ($6::text IS NULL OR user_jid = $6)
+  AND ($7::text IS NULL
+    OR tracking_id ILIKE $7
+    OR recipient_name ILIKE $7
+    OR recipient_address ILIKE $7
+    OR regexp_replace(recipient_phone, '[^0-9]', '', 'g') LIKE $8)
+  AND ($9::text[] IS NULL OR tags @> $9)
+  AND ($10::jsonb IS NULL OR custom_fields @> $10)
+  AND ($11::timestamp IS NULL
+    OR (created_at, tracking_id) < ($11, $12::text))
+ORDER BY created_at DESC, tracking_id DESC
+LIMIT $13
+`
+
+type SearchShipmentsParams struct {
+	CompanyID        uuid.NullUUID         `json:"company_id"`
+	Status           sql.NullString        `json:"status"`
+	CreatedFrom      sql.NullTime          `json:"created_from"`
+	CreatedTo        sql.NullTime          `json:"created_to"`
+	Destination      sql.NullString        `json:"destination"`
+	UserJid          sql.NullString        `json:"user_jid"`
+	Query            sql.NullString        `json:"query"`
+	PhoneDigits      sql.NullString        `json:"phone_digits"`
+	Tags             []string              `json:"tags"`
+	CustomFields     pqtype.NullRawMessage `json:"custom_fields"`
+	CursorCreatedAt  sql.NullTime          `json:"cursor_created_at"`
+	CursorTrackingID sql.NullString        `json:"cursor_tracking_id"`
+	RowLimit         int32                 `json:"row_limit"`
+}
+
+func (q *Queries) SearchShipments(ctx context.Context, arg SearchShipmentsParams) ([]Shipment, error) {
+	rows, err := q.db.QueryContext(ctx, searchShipments,
+		arg.CompanyID,
+		arg.Status,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.Destination,
+		arg.UserJid,
+		arg.Query,
+		arg.PhoneDigits,
+		pq.Array(arg.Tags),
+		arg.CustomFields,
+		arg.CursorCreatedAt,
+		arg.CursorTrackingID,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Shipment
+	for rows.Next() {
+		var i Shipment
+		if err := rows.Scan(
+			&i.TrackingID,
+			&i.CompanyID,
+			&i.UserJid,
+			&i.Status,
+			&i.CreatedAt,
+			&i.ScheduledTransitTime,
+			&i.OutfordeliveryTime,
+			&i.ExpectedDeliveryTime,
+			&i.SenderTimezone,
+			&i.RecipientTimezone,
+			&i.SenderName,
+			&i.SenderPhone,
+			&i.Origin,
+			&i.RecipientName,
+			&i.RecipientPhone,
+			&i.RecipientEmail,
+			&i.RecipientID,
+			&i.RecipientAddress,
+			&i.Destination,
+			&i.CargoType,
+			&i.Weight,
+			&i.Cost,
+			&i.UpdatedAt,
+			&i.ServiceLevel,
+			&i.CodAmount,
+			&i.CodCurrency,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Version,
+			&i.CustomFields,
+			pq.Array(&i.Tags),
+			&i.ReturnOf,
+			&i.ReturnID,
+			&i.ReturnReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const setCompanyPassword = `-- name: SetCompanyPassword :exec
 UPDATE companies SET admin_password_hash = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1
 `
@@ -1050,6 +4171,21 @@ func (q *Queries) SetCompanyPassword(ctx context.Context, arg SetCompanyPassword
 	return err
 }
 
+const setConsolidationStatus = `-- name: SetConsolidationStatus :exec
+UPDATE consolidations SET status = $3 WHERE company_id = $1 AND id = $2
+`
+
+type SetConsolidationStatusParams struct {
+	CompanyID uuid.UUID      `json:"company_id"`
+	ID        int32          `json:"id"`
+	Status    sql.NullString `json:"status"`
+}
+
+func (q *Queries) SetConsolidationStatus(ctx context.Context, arg SetConsolidationStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setConsolidationStatus, arg.CompanyID, arg.ID, arg.Status)
+	return err
+}
+
 const setGroupAuthority = `-- name: SetGroupAuthority :exec
 INSERT INTO GroupAuthority (company_id, jid, is_authorized, updated_at) 
 VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
@@ -1067,6 +4203,52 @@ func (q *Queries) SetGroupAuthority(ctx context.Context, arg SetGroupAuthorityPa
 	return err
 }
 
+const setShipmentField = `-- name: SetShipmentField :execresult
+UPDATE Shipment
+SET
+  sender_name = CASE WHEN $1::text = 'sender_name' THEN $2::text ELSE sender_name END,
+  sender_phone = CASE WHEN $1 = 'sender_phone' THEN $2 ELSE sender_phone END,
+  origin = CASE WHEN $1 = 'origin' THEN $2 ELSE origin END,
+  recipient_name = CASE WHEN $1 = 'recipient_name' THEN $2 ELSE recipient_name END,
+  recipient_phone = CASE WHEN $1 = 'recipient_phone' THEN $2 ELSE recipient_phone END,
+  recipient_email = CASE WHEN $1 = 'recipient_email' THEN $2 ELSE recipient_email END,
+  recipient_id = CASE WHEN $1 = 'recipient_id' THEN $2 ELSE recipient_id END,
+  recipient_address = CASE WHEN $1 = 'recipient_address' THEN $2 ELSE recipient_address END,
+  destination = CASE WHEN $1 = 'destination' THEN $2 ELSE destination END,
+  cargo_type = CASE WHEN $1 = 'cargo_type' THEN $2 ELSE cargo_type END,
+  scheduled_transit_time = CASE WHEN $1 = 'scheduled_transit_time' THEN $2::timestamp ELSE scheduled_transit_time END,
+  expected_delivery_time = CASE WHEN $1 = 'expected_delivery_time' THEN $2::timestamp ELSE expected_delivery_time END,
+  outfordelivery_time = CASE WHEN $1 = 'outfordelivery_time' THEN $2::timestamp ELSE outfordelivery_time END,
+  cost = CASE WHEN $1 = 'cost' THEN $2::double precision ELSE cost END,
+  status = CASE WHEN $1 = 'status' THEN $2 ELSE status END,
+  custom_fields = CASE WHEN $1 LIKE 'cf.%' THEN
+      CASE WHEN $2 IS NULL THEN custom_fields - substr($1, 4)
+      ELSE jsonb_set(custom_fields, ARRAY[substr($1, 4)], $2::jsonb) END
+    ELSE custom_fields END,
+  tags = CASE WHEN $1 = 'tags' THEN ARRAY(SELECT jsonb_array_elements_text(COALESCE($2::jsonb, '[]'))) ELSE tags END,
+  version = version + 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $3 AND tracking_id = $4 AND version = $5 AND deleted_at IS NULL
+`
+
+type SetShipmentFieldParams struct {
+	Field      string         `json:"field"`
+	Value      sql.NullString `json:"value"`
+	CompanyID  uuid.NullUUID  `json:"company_id"`
+	TrackingID string         `json:"tracking_id"`
+	Version    int32          `json:"version"`
+}
+
+func (q *Queries) SetShipmentField(ctx context.Context, arg SetShipmentFieldParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, setShipmentField,
+		arg.Field,
+		arg.Value,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Version,
+	)
+}
+
 const setSystemConfig = `-- name: SetSystemConfig :exec
 INSERT INTO SystemConfig (company_id, key, value, updated_at) 
 VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
@@ -1101,39 +4283,35 @@ func (q *Queries) SetUserLanguage(ctx context.Context, arg SetUserLanguageParams
 	return err
 }
 
-const transitionStatusToDelivered = `-- name: TransitionStatusToDelivered :many
-UPDATE Shipment
-SET status = 'delivered', updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND status = 'outfordelivery' AND expected_delivery_time <= $2
-RETURNING tracking_id, status AS new_status, user_jid, recipient_email
+const sumCodLedger = `-- name: SumCodLedger :many
+SELECT currency, entry_type, COUNT(*) AS entries, COALESCE(SUM(amount), 0)::float8 AS total
+FROM cod_ledger
+WHERE company_id = $1
+GROUP BY currency, entry_type
+ORDER BY currency, entry_type
 `
 
-type TransitionStatusToDeliveredParams struct {
-	CompanyID            uuid.NullUUID `json:"company_id"`
-	ExpectedDeliveryTime sql.NullTime  `json:"expected_delivery_time"`
-}
-
-type TransitionStatusToDeliveredRow struct {
-	TrackingID     string         `json:"tracking_id"`
-	NewStatus      sql.NullString `json:"new_status"`
-	UserJid        string         `json:"user_jid"`
-	RecipientEmail sql.NullString `json:"recipient_email"`
+type SumCodLedgerRow struct {
+	Currency  string  `json:"currency"`
+	EntryType string  `json:"entry_type"`
+	Entries   int64   `json:"entries"`
+	Total     float64 `json:"total"`
 }
 
-func (q *Queries) TransitionStatusToDelivered(ctx context.Context, arg TransitionStatusToDeliveredParams) ([]TransitionStatusToDeliveredRow, error) {
-	rows, err := q.db.QueryContext(ctx, transitionStatusToDelivered, arg.CompanyID, arg.ExpectedDeliveryTime)
+func (q *Queries) SumCodLedger(ctx context.Context, companyID uuid.NullUUID) ([]SumCodLedgerRow, error) {
+	rows, err := q.db.QueryContext(ctx, sumCodLedger, companyID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TransitionStatusToDeliveredRow
+	var items []SumCodLedgerRow
 	for rows.Next() {
-		var i TransitionStatusToDeliveredRow
+		var i SumCodLedgerRow
 		if err := rows.Scan(
-			&i.TrackingID,
-			&i.NewStatus,
-			&i.UserJid,
-			&i.RecipientEmail,
+			&i.Currency,
+			&i.EntryType,
+			&i.Entries,
+			&i.Total,
 		); err != nil {
 			return nil, err
 		}
@@ -1148,39 +4326,36 @@ func (q *Queries) TransitionStatusToDelivered(ctx context.Context, arg Transitio
 	return items, nil
 }
 
-const transitionStatusToIntransit = `-- name: TransitionStatusToIntransit :many
-UPDATE Shipment
-SET status = 'intransit', updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND status = 'pending' AND scheduled_transit_time <= $2
-RETURNING tracking_id, status AS new_status, user_jid, recipient_email
+const sumOpenCod = `-- name: SumOpenCod :many
+SELECT COALESCE(cod_currency, '')::text AS currency, (status = 'delivered')::bool AS delivered, COUNT(*) AS shipments, COALESCE(SUM(cod_amount), 0)::float8 AS total
+FROM Shipment s
+WHERE s.company_id = $1 AND s.cod_amount > 0 AND s.deleted_at IS NULL AND s.status NOT IN ('canceled', 'returned')
+  AND NOT EXISTS (SELECT 1 FROM cod_ledger l WHERE l.tracking_id = s.tracking_id AND l.entry_type = 'collection')
+GROUP BY 1, 2
+ORDER BY 1, 2
 `
 
-type TransitionStatusToIntransitParams struct {
-	CompanyID            uuid.NullUUID `json:"company_id"`
-	ScheduledTransitTime sql.NullTime  `json:"scheduled_transit_time"`
+type SumOpenCodRow struct {
+	Currency  string  `json:"currency"`
+	Delivered bool    `json:"delivered"`
+	Shipments int64   `json:"shipments"`
+	Total     float64 `json:"total"`
 }
 
-type TransitionStatusToIntransitRow struct {
-	TrackingID     string         `json:"tracking_id"`
-	NewStatus      sql.NullString `json:"new_status"`
-	UserJid        string         `json:"user_jid"`
-	RecipientEmail sql.NullString `json:"recipient_email"`
-}
-
-func (q *Queries) TransitionStatusToIntransit(ctx context.Context, arg TransitionStatusToIntransitParams) ([]TransitionStatusToIntransitRow, error) {
-	rows, err := q.db.QueryContext(ctx, transitionStatusToIntransit, arg.CompanyID, arg.ScheduledTransitTime)
+func (q *Queries) SumOpenCod(ctx context.Context, companyID uuid.NullUUID) ([]SumOpenCodRow, error) {
+	rows, err := q.db.QueryContext(ctx, sumOpenCod, companyID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TransitionStatusToIntransitRow
+	var items []SumOpenCodRow
 	for rows.Next() {
-		var i TransitionStatusToIntransitRow
+		var i SumOpenCodRow
 		if err := rows.Scan(
-			&i.TrackingID,
-			&i.NewStatus,
-			&i.UserJid,
-			&i.RecipientEmail,
+			&i.Currency,
+			&i.Delivered,
+			&i.Shipments,
+			&i.Total,
 		); err != nil {
 			return nil, err
 		}
@@ -1195,51 +4370,17 @@ func (q *Queries) TransitionStatusToIntransit(ctx context.Context, arg Transitio
 	return items, nil
 }
 
-const transitionStatusToOutForDelivery = `-- name: TransitionStatusToOutForDelivery :many
-UPDATE Shipment
-SET status = 'outfordelivery', updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND status = 'intransit' AND outfordelivery_time <= $2
-RETURNING tracking_id, status AS new_status, user_jid, recipient_email
+const unassignRider = `-- name: UnassignRider :execresult
+DELETE FROM rider_assignments WHERE company_id = $1 AND tracking_id = $2
 `
 
-type TransitionStatusToOutForDeliveryParams struct {
-	CompanyID          uuid.NullUUID `json:"company_id"`
-	OutfordeliveryTime sql.NullTime  `json:"outfordelivery_time"`
-}
-
-type TransitionStatusToOutForDeliveryRow struct {
-	TrackingID     string         `json:"tracking_id"`
-	NewStatus      sql.NullString `json:"new_status"`
-	UserJid        string         `json:"user_jid"`
-	RecipientEmail sql.NullString `json:"recipient_email"`
+type UnassignRiderParams struct {
+	CompanyID  uuid.UUID `json:"company_id"`
+	TrackingID string    `json:"tracking_id"`
 }
 
-func (q *Queries) TransitionStatusToOutForDelivery(ctx context.Context, arg TransitionStatusToOutForDeliveryParams) ([]TransitionStatusToOutForDeliveryRow, error) {
-	rows, err := q.db.QueryContext(ctx, transitionStatusToOutForDelivery, arg.CompanyID, arg.OutfordeliveryTime)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []TransitionStatusToOutForDeliveryRow
-	for rows.Next() {
-		var i TransitionStatusToOutForDeliveryRow
-		if err := rows.Scan(
-			&i.TrackingID,
-			&i.NewStatus,
-			&i.UserJid,
-			&i.RecipientEmail,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) UnassignRider(ctx context.Context, arg UnassignRiderParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, unassignRider, arg.CompanyID, arg.TrackingID)
 }
 
 const updateCompanyAuthStatus = `-- name: UpdateCompanyAuthStatus :exec
@@ -1373,6 +4514,60 @@ func (q *Queries) UpdateCompanyWhatsAppPhone(ctx context.Context, arg UpdateComp
 	return err
 }
 
+const updateContact = `-- name: UpdateContact :one
+UPDATE contacts
+SET alias = $3, name = $4, phone = $5, phone_digits = $6, email = $7, address = $8, country = $9, id_number = $10,
+    updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2
+RETURNING id, company_id, alias, name, phone, phone_digits, email, address, country, id_number, shipment_count, last_used_at, created_at, updated_at
+`
+
+type UpdateContactParams struct {
+	CompanyID   uuid.UUID      `json:"company_id"`
+	ID          int32          `json:"id"`
+	Alias       sql.NullString `json:"alias"`
+	Name        string         `json:"name"`
+	Phone       string         `json:"phone"`
+	PhoneDigits string         `json:"phone_digits"`
+	Email       sql.NullString `json:"email"`
+	Address     sql.NullString `json:"address"`
+	Country     sql.NullString `json:"country"`
+	IDNumber    sql.NullString `json:"id_number"`
+}
+
+func (q *Queries) UpdateContact(ctx context.Context, arg UpdateContactParams) (Contact, error) {
+	row := q.db.QueryRowContext(ctx, updateContact,
+		arg.CompanyID,
+		arg.ID,
+		arg.Alias,
+		arg.Name,
+		arg.Phone,
+		arg.PhoneDigits,
+		arg.Email,
+		arg.Address,
+		arg.Country,
+		arg.IDNumber,
+	)
+	var i Contact
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Alias,
+		&i.Name,
+		&i.Phone,
+		&i.PhoneDigits,
+		&i.Email,
+		&i.Address,
+		&i.Country,
+		&i.IDNumber,
+		&i.ShipmentCount,
+		&i.LastUsedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updatePlanPrice = `-- name: UpdatePlanPrice :exec
 UPDATE plans SET base_price = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1
 `
@@ -1387,7 +4582,87 @@ func (q *Queries) UpdatePlanPrice(ctx context.Context, arg UpdatePlanPriceParams
 	return err
 }
 
-const updateShipmentDynamic = `-- name: UpdateShipmentDynamic :exec
+const updateRider = `-- name: UpdateRider :one
+UPDATE riders SET name = $3, phone = $4, phone_digits = $5, active = $6, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2
+RETURNING id, company_id, name, phone, phone_digits, active, created_at, updated_at
+`
+
+type UpdateRiderParams struct {
+	CompanyID   uuid.UUID `json:"company_id"`
+	ID          int32     `json:"id"`
+	Name        string    `json:"name"`
+	Phone       string    `json:"phone"`
+	PhoneDigits string    `json:"phone_digits"`
+	Active      bool      `json:"active"`
+}
+
+func (q *Queries) UpdateRider(ctx context.Context, arg UpdateRiderParams) (Rider, error) {
+	row := q.db.QueryRowContext(ctx, updateRider,
+		arg.CompanyID,
+		arg.ID,
+		arg.Name,
+		arg.Phone,
+		arg.PhoneDigits,
+		arg.Active,
+	)
+	var i Rider
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Name,
+		&i.Phone,
+		&i.PhoneDigits,
+		&i.Active,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateShipmentCOD = `-- name: UpdateShipmentCOD :execresult
+UPDATE Shipment SET cod_amount = $3, cod_currency = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2 AND version = $5 AND deleted_at IS NULL
+`
+
+type UpdateShipmentCODParams struct {
+	CompanyID   uuid.NullUUID  `json:"company_id"`
+	TrackingID  string         `json:"tracking_id"`
+	CodAmount   float64        `json:"cod_amount"`
+	CodCurrency sql.NullString `json:"cod_currency"`
+	Version     int32          `json:"version"`
+}
+
+func (q *Queries) UpdateShipmentCOD(ctx context.Context, arg UpdateShipmentCODParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateShipmentCOD,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.CodAmount,
+		arg.CodCurrency,
+		arg.Version,
+	)
+}
+
+const updateShipmentCost = `-- name: UpdateShipmentCost :execresult
+UPDATE Shipment SET cost = $3, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2 AND version = $4 AND deleted_at IS NULL
+`
+
+type UpdateShipmentCostParams struct {
+	CompanyID  uuid.NullUUID   `json:"company_id"`
+	TrackingID string          `json:"tracking_id"`
+	Cost       sql.NullFloat64 `json:"cost"`
+	Version    int32           `json:"version"`
+}
+
+func (q *Queries) UpdateShipmentCost(ctx context.Context, arg UpdateShipmentCostParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateShipmentCost,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.Cost,
+		arg.Version,
+	)
+}
+
+const updateShipmentDynamic = `-- name: UpdateShipmentDynamic :execresult
 UPDATE Shipment
 SET 
   sender_name = COALESCE(NULLIF($3::text, ''), sender_name),
@@ -1404,8 +4679,9 @@ SET
   expected_delivery_time = COALESCE(NULLIF($14::timestamp, '0001-01-01 00:00:00'::timestamp), expected_delivery_time),
   outfordelivery_time = COALESCE(NULLIF($15::timestamp, '0001-01-01 00:00:00'::timestamp), outfordelivery_time),
   status = COALESCE(NULLIF($16::text, ''), status),
+  version = version + 1,
   updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND tracking_id = $2
+WHERE company_id = $1 AND tracking_id = $2 AND version = $17 AND deleted_at IS NULL
 `
 
 type UpdateShipmentDynamicParams struct {
@@ -1425,10 +4701,11 @@ type UpdateShipmentDynamicParams struct {
 	Column14   time.Time     `json:"column_14"`
 	Column15   time.Time     `json:"column_15"`
 	Column16   string        `json:"column_16"`
+	Version    int32         `json:"version"`
 }
 
-func (q *Queries) UpdateShipmentDynamic(ctx context.Context, arg UpdateShipmentDynamicParams) error {
-	_, err := q.db.ExecContext(ctx, updateShipmentDynamic,
+func (q *Queries) UpdateShipmentDynamic(ctx context.Context, arg UpdateShipmentDynamicParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateShipmentDynamic,
 		arg.CompanyID,
 		arg.TrackingID,
 		arg.Column3,
@@ -1445,12 +4722,35 @@ func (q *Queries) UpdateShipmentDynamic(ctx context.Context, arg UpdateShipmentD
 		arg.Column14,
 		arg.Column15,
 		arg.Column16,
+		arg.Version,
+	)
+}
+
+const updateShipmentExtras = `-- name: UpdateShipmentExtras :execresult
+UPDATE Shipment SET custom_fields = $3, tags = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NULL AND version = $5
+`
+
+type UpdateShipmentExtrasParams struct {
+	CompanyID    uuid.NullUUID   `json:"company_id"`
+	TrackingID   string          `json:"tracking_id"`
+	CustomFields json.RawMessage `json:"custom_fields"`
+	Tags         []string        `json:"tags"`
+	Version      int32           `json:"version"`
+}
+
+func (q *Queries) UpdateShipmentExtras(ctx context.Context, arg UpdateShipmentExtrasParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateShipmentExtras,
+		arg.CompanyID,
+		arg.TrackingID,
+		arg.CustomFields,
+		pq.Array(arg.Tags),
+		arg.Version,
 	)
-	return err
 }
 
-const updateShipmentStatus = `-- name: UpdateShipmentStatus :exec
-UPDATE Shipment SET status = $3, destination = $4, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2
+const updateShipmentStatus = `-- name: UpdateShipmentStatus :execresult
+UPDATE Shipment SET status = $3, destination = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2 AND version = $5 AND deleted_at IS NULL
 `
 
 type UpdateShipmentStatusParams struct {
@@ -1458,14 +4758,146 @@ type UpdateShipmentStatusParams struct {
 	TrackingID  string         `json:"tracking_id"`
 	Status      sql.NullString `json:"status"`
 	Destination sql.NullString `json:"destination"`
+	Version     int32          `json:"version"`
 }
 
-func (q *Queries) UpdateShipmentStatus(ctx context.Context, arg UpdateShipmentStatusParams) error {
-	_, err := q.db.ExecContext(ctx, updateShipmentStatus,
+func (q *Queries) UpdateShipmentStatus(ctx context.Context, arg UpdateShipmentStatusParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateShipmentStatus,
 		arg.CompanyID,
 		arg.TrackingID,
 		arg.Status,
 		arg.Destination,
+		arg.Version,
+	)
+}
+
+const upsertContactFromShipment = `-- name: UpsertContactFromShipment :exec
+INSERT INTO contacts (company_id, name, phone, phone_digits, email, address, country, shipment_count, last_used_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, 1, CURRENT_TIMESTAMP)
+ON CONFLICT (company_id, phone_digits) DO UPDATE SET
+  name = EXCLUDED.name,
+  phone = EXCLUDED.phone,
+  email = COALESCE(NULLIF(EXCLUDED.email, ''), contacts.email),
+  address = COALESCE(NULLIF(EXCLUDED.address, ''), contacts.address),
+  country = COALESCE(NULLIF(EXCLUDED.country, ''), contacts.country),
+  shipment_count = contacts.shipment_count + 1,
+  last_used_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertContactFromShipmentParams struct {
+	CompanyID   uuid.UUID      `json:"company_id"`
+	Name        string         `json:"name"`
+	Phone       string         `json:"phone"`
+	PhoneDigits string         `json:"phone_digits"`
+	Email       sql.NullString `json:"email"`
+	Address     sql.NullString `json:"address"`
+	Country     sql.NullString `json:"country"`
+}
+
+func (q *Queries) UpsertContactFromShipment(ctx context.Context, arg UpsertContactFromShipmentParams) error {
+	_, err := q.db.ExecContext(ctx, upsertContactFromShipment,
+		arg.CompanyID,
+		arg.Name,
+		arg.Phone,
+		arg.PhoneDigits,
+		arg.Email,
+		arg.Address,
+		arg.Country,
+	)
+	return err
+}
+
+const upsertCustomFieldDefinition = `-- name: UpsertCustomFieldDefinition :one
+INSERT INTO custom_field_definitions (company_id, name, label, field_type, required, pattern)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (company_id, name) DO UPDATE SET
+  label = EXCLUDED.label,
+  field_type = EXCLUDED.field_type,
+  required = EXCLUDED.required,
+  pattern = EXCLUDED.pattern,
+  updated_at = CURRENT_TIMESTAMP
+RETURNING id, company_id, name, label, field_type, required, pattern, created_at, updated_at
+`
+
+type UpsertCustomFieldDefinitionParams struct {
+	CompanyID uuid.UUID      `json:"company_id"`
+	Name      string         `json:"name"`
+	Label     sql.NullString `json:"label"`
+	FieldType string         `json:"field_type"`
+	Required  bool           `json:"required"`
+	Pattern   sql.NullString `json:"pattern"`
+}
+
+func (q *Queries) UpsertCustomFieldDefinition(ctx context.Context, arg UpsertCustomFieldDefinitionParams) (CustomFieldDefinition, error) {
+	row := q.db.QueryRowContext(ctx, upsertCustomFieldDefinition,
+		arg.CompanyID,
+		arg.Name,
+		arg.Label,
+		arg.FieldType,
+		arg.Required,
+		arg.Pattern,
+	)
+	var i CustomFieldDefinition
+	err := row.Scan(
+		&i.ID,
+		&i.CompanyID,
+		&i.Name,
+		&i.Label,
+		&i.FieldType,
+		&i.Required,
+		&i.Pattern,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertCustomsDeclaration = `-- name: UpsertCustomsDeclaration :exec
+INSERT INTO customs_declarations (tracking_id, company_id, currency, export_reason, invoice_number)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tracking_id) DO UPDATE
+SET currency = EXCLUDED.currency, export_reason = EXCLUDED.export_reason, invoice_number = EXCLUDED.invoice_number, updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertCustomsDeclarationParams struct {
+	TrackingID    string         `json:"tracking_id"`
+	CompanyID     uuid.NullUUID  `json:"company_id"`
+	Currency      string         `json:"currency"`
+	ExportReason  string         `json:"export_reason"`
+	InvoiceNumber sql.NullString `json:"invoice_number"`
+}
+
+func (q *Queries) UpsertCustomsDeclaration(ctx context.Context, arg UpsertCustomsDeclarationParams) error {
+	_, err := q.db.ExecContext(ctx, upsertCustomsDeclaration,
+		arg.TrackingID,
+		arg.CompanyID,
+		arg.Currency,
+		arg.ExportReason,
+		arg.InvoiceNumber,
+	)
+	return err
+}
+
+const upsertHoliday = `-- name: UpsertHoliday :exec
+INSERT INTO holidays (company_id, country, holiday_date, name)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (company_id, country, holiday_date) DO UPDATE SET name = EXCLUDED.name
+`
+
+type UpsertHolidayParams struct {
+	CompanyID   uuid.UUID      `json:"company_id"`
+	Country     string         `json:"country"`
+	HolidayDate time.Time      `json:"holiday_date"`
+	Name        sql.NullString `json:"name"`
+}
+
+func (q *Queries) UpsertHoliday(ctx context.Context, arg UpsertHolidayParams) error {
+	_, err := q.db.ExecContext(ctx, upsertHoliday,
+		arg.CompanyID,
+		arg.Country,
+		arg.HolidayDate,
+		arg.Name,
 	)
 	return err
 }
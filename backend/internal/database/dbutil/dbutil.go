@@ -2,7 +2,11 @@ package dbutil
 
 import (
 	"database/sql"
+	"errors"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
 )
 
 // ToNullString converts a string to sql.NullString.
@@ -19,3 +23,14 @@ func ToNullTime(t time.Time) sql.NullTime {
 func ToNullFloat64(f float64) sql.NullFloat64 {
 	return sql.NullFloat64{Float64: f, Valid: true}
 }
+
+// IsUniqueViolation reports whether err is Postgres refusing a duplicate key.
+// The app connects through pgx; pq errors come from tools still on lib/pq.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
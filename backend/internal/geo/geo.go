@@ -0,0 +1,101 @@
+// Package geo turns coordinates into place names without calling out to a
+// geocoding service. Labels come from an embedded list of capitals and major
+// cities, which is enough to tell a customer roughly where a parcel is.
+package geo
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// nearRadiusKm is how close a point must be to a city to be labelled as in it.
+const nearRadiusKm = 30
+
+const earthRadiusKm = 6371.0
+
+//go:embed cities.csv
+var citiesCSV string
+
+// City is one entry of the offline dataset.
+type City struct {
+	Name    string
+	Country string
+	Lat     float64
+	Lon     float64
+}
+
+var (
+	loadOnce sync.Once
+	cities   []City
+)
+
+// load parses the embedded dataset once. The file is checked in and covered
+// by tests, so a malformed row is a programming error.
+func load() []City {
+	loadOnce.Do(func() {
+		rows, err := csv.NewReader(strings.NewReader(citiesCSV)).ReadAll()
+		if err != nil {
+			panic(fmt.Sprintf("geo: bad city dataset: %v", err))
+		}
+		for _, r := range rows[1:] {
+			lat, err1 := strconv.ParseFloat(r[2], 64)
+			lon, err2 := strconv.ParseFloat(r[3], 64)
+			if err1 != nil || err2 != nil {
+				panic(fmt.Sprintf("geo: bad coordinates for %s", r[0]))
+			}
+			cities = append(cities, City{Name: r[0], Country: r[1], Lat: lat, Lon: lon})
+		}
+	})
+	return cities
+}
+
+// Valid reports whether lat/lon are real coordinates. WhatsApp sends 0,0
+// when the phone had no fix.
+func Valid(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180 && !(lat == 0 && lon == 0)
+}
+
+// DistanceKm is the great-circle distance between two points.
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// Nearest returns the closest city in the dataset and how far away it is.
+func Nearest(lat, lon float64) (City, float64) {
+	var best City
+	bestKm := math.MaxFloat64
+	for _, c := range load() {
+		if d := DistanceKm(lat, lon, c.Lat, c.Lon); d < bestKm {
+			best, bestKm = c, d
+		}
+	}
+	return best, bestKm
+}
+
+// Label describes a point for people: "Ikeja, Nigeria" when it is within
+// nearRadiusKm of a known city, "85 km from Ibadan, Nigeria" otherwise.
+func Label(lat, lon float64) string {
+	if !Valid(lat, lon) {
+		return ""
+	}
+	c, km := Nearest(lat, lon)
+	if km <= nearRadiusKm {
+		return c.Name + ", " + c.Country
+	}
+	return fmt.Sprintf("%.0f km from %s, %s", km, c.Name, c.Country)
+}
+
+// MapURL links to the point on a map, for chat messages.
+func MapURL(lat, lon float64) string {
+	return fmt.Sprintf("https://maps.google.com/?q=%.6f,%.6f", lat, lon)
+}
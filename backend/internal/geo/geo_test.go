@@ -0,0 +1,33 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatasetLoads(t *testing.T) {
+	all := load()
+	assert.Greater(t, len(all), 500)
+	for _, c := range all {
+		assert.True(t, Valid(c.Lat, c.Lon), c.Name)
+		assert.NotEmpty(t, c.Country, c.Name)
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	// Lagos to Abuja is roughly 525 km as the crow flies
+	assert.InDelta(t, 525, DistanceKm(6.4550, 3.3841, 9.0765, 7.3986), 15)
+	assert.Zero(t, DistanceKm(1, 1, 1, 1))
+}
+
+func TestLabel(t *testing.T) {
+	// Allen Avenue, Ikeja
+	assert.Equal(t, "Ikeja, Nigeria", Label(6.6010, 3.3550))
+	// Oxford Street
+	assert.Equal(t, "London, United Kingdom", Label(51.5154, -0.1410))
+	// Farmland between Abeokuta and Ibadan, outside both
+	assert.Contains(t, Label(7.0500, 3.6500), " km from ")
+	assert.Empty(t, Label(0, 0))
+	assert.Empty(t, Label(91, 10))
+}
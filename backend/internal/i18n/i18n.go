@@ -43,6 +43,89 @@ var Translations = map[Language]map[string]string{
 		"MSG_EDIT_SUCCESS":     "✅ *Shipment Details Updated*\n\n🆔 *%s*\n\n📝 *Modified Fields:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Please wait while we generate your updated digital receipt..._",
 		"MSG_STATS_HEADER":     "📊 *%s System Metrics*",
 		"MSG_STATUS_DASHBOARD": "🖥️ *Operations Dashboard*",
+
+		"ERR_INVALID_TRANSITION":     "🚦 *Status Change Rejected*\n\n_A shipment cannot move from *%s* to *%s*._\n\n✅ *Allowed:* %s",
+		"MSG_QUOTE_USAGE":            "💰 *PRICE ESTIMATE*\n\nUsage: `!quote [origin] [destination] [kg]`\n\n*Example:* `!quote Nigeria UK 5`\n_For multi-word countries use \"to\": `!quote South Africa to United Kingdom 5`_",
+		"MSG_QUOTE":                  "💰 *PRICE ESTIMATE*\n\n📍 *Lane:* %s → %s\n⚖️ *Chargeable weight:* %.2f kg\n\n━━━━━━━━━━━━━━━━━━━━━━━\n• Base rate: %s\n• Fuel surcharge: %s\n━━━━━━━━━━━━━━━━━━━━━━━\n💵 *TOTAL:* %s\n\n_Estimate only. The final price is confirmed at drop-off._",
+		"ERR_NO_RATE_CARD":           "💰 *Pricing Not Configured*\n\n_No rate card has been set up for this company yet. Please configure one in the dashboard._",
+		"ERR_NO_RATE":                "💰 *No Rate Available*\n\n_The rate card has no price for %s → %s._",
+		"receipt_pieces":             "PIECES",
+		"ERR_NO_CUSTOMS":             "🧾 *No Customs Declaration*\n\n_Shipment %s has no customs items on file. Add them in the dashboard first._",
+		"MSG_INVOICE_CAPTION":        "🧾 Commercial invoice for *%s*",
+		"MSG_COD_USAGE":              "💵 *CASH ON DELIVERY*\n\nUsage:\n• `!cod` - Balance per currency\n• `!cod collect [ID] [amount]` - Mark a shipment's COD as collected (defaults to the full amount)",
+		"MSG_COD_EMPTY":              "💵 *CASH ON DELIVERY*\n\n_No COD shipments or ledger entries yet._",
+		"MSG_COD_HEADER":             "💵 *CASH ON DELIVERY*",
+		"MSG_COD_BALANCE":            "*%s*\n• Collected: %s\n• Remitted: %s\n• Adjustments: %s\n• *Owed to merchant: %s*\n• Delivered, not collected: %s (%d)\n• Awaiting delivery: %s (%d)",
+		"MSG_COD_COLLECTED":          "✅ *COD COLLECTED*\n\n%s: *%s* booked to the ledger.",
+		"ERR_NO_COD":                 "💵 *No Cash on Delivery*\n\n_Shipment %s is prepaid._",
+		"ERR_COD_COLLECTED":          "💵 *Already Collected*\n\n_The COD for %s is already in the ledger._",
+		"MSG_RESTORE_USAGE":          "♻️ *RESTORE SHIPMENT*\n\nUsage: `!restore [TrackingID]`",
+		"MSG_SHIPMENT_RESTORED":      "♻️ *SHIPMENT RESTORED*\n\nThe shipment *%s* is back in your active list.",
+		"ERR_NOT_IN_TRASH":           "❌ *NOT IN TRASH*\n\n_Shipment %s is not in the trash. It may never have been deleted, or it has already been purged._",
+		"ERR_INVALID_TRACKING_ID":    "🔢 *Invalid Tracking ID*\n\n_*%s* is not a tracking ID. They look like *AWB-123456789*._",
+		"ERR_CHECK_DIGIT":            "🔢 *Check Digit Mismatch*\n\n_The last digit of *%s* does not match. There is probably a typo — please check the ID and try again._",
+		"MSG_UNDO_DONE":              "↩️ *Edit Undone*\n\n🆔 *%s*\n\n📝 *Restored Fields:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Please wait while we generate your updated digital receipt..._",
+		"MSG_NOTHING_TO_UNDO":        "↩️ *NOTHING TO UNDO*\n\n_You have no recent edits left to revert._",
+		"ERR_EDIT_CONFLICT":          "🔄 *Edit Conflict*\n\n_Shipment *%s* was changed by someone else while you were editing it. Please check the latest details and try again._",
+		"ERR_UNKNOWN_CONTACT":        "📇 *Unknown Contact*\n\n_No saved contact with the alias %s. Check the spelling or add it in the dashboard address book._",
+		"ERR_INVALID_CUSTOM_FIELD":   "⚠️ *Invalid Value*\n\n_%s_",
+		"MSG_RETURN_USAGE":           "↩️ *RETURN TO SENDER*\n\nUsage: `!return [TrackingID] [reason]`\n\n*Example:* `!return AWB-123456789 recipient refused the parcel`",
+		"MSG_SHIPMENT_RETURNED":      "↩️ *RETURN CREATED*\n\n📦 *Original:* %s\n🔁 *Return:* %s\n📝 *Reason:* %s\n\n_The original is marked as returned. The sender has been notified._",
+		"ERR_ALREADY_RETURNED":       "↩️ *Already Returned*\n\n_Shipment %s is already on its way back as *%s*._",
+		"ERR_INVALID_RETURN":         "↩️ *Return Not Possible*\n\n_%s_",
+		"ALERT_ATTEMPT_FAILED":       "🚪 *DELIVERY ATTEMPT FAILED*\n\nTracking ID: *%s*\nAttempt: *%d of %d*\nReason: _%s_\n\nOur courier will try again on *%s*. Please make sure someone is available to receive the shipment.",
+		"ALERT_ATTEMPT_FINAL":        "🚪 *DELIVERY FAILED*\n\nTracking ID: *%s*\nAttempt: *%d of %d*\nReason: _%s_\n\nThis was the last delivery attempt. Please contact us to arrange a new address, collection or a return to the sender.",
+		"attempt_no_one_home":        "No one was available",
+		"attempt_wrong_address":      "Address incorrect or incomplete",
+		"attempt_refused":            "Delivery refused",
+		"attempt_business_closed":    "Business closed",
+		"attempt_other":              "Other",
+		"MSG_ASSIGN_USAGE":           "🛵 *ASSIGN A RIDER*\n\nUsage: `!assign [TrackingID] [rider name or phone]`\nUse `!assign [TrackingID] none` to take the rider off.\n\n*Example:* `!assign AWB-123456789 Musa`",
+		"MSG_RIDER_ASSIGNED":         "🛵 *RIDER ASSIGNED*\n\n📦 *Shipment:* %s\n👤 *Rider:* %s (%s)\n\n_The rider has been sent the delivery details._",
+		"MSG_RIDER_UNASSIGNED":       "🛵 _Shipment %s no longer has a rider. Its status will advance on schedule again._",
+		"ERR_RIDER_NOT_FOUND":        "🛵 *Rider Not Found*\n\n_No active rider matches *%s*. Riders are added in the admin portal._",
+		"ERR_INVALID_ASSIGNMENT":     "🛵 *Assignment Not Possible*\n\n_%s_",
+		"MSG_RIDER_NEW_JOB":          "🛵 *NEW DELIVERY*\n\n📦 *%s*\n👤 %s\n📞 %s\n📍 %s, %s\n\n_Send `!pickup` when you have the parcel, then `!delivered [ID]` or `!failed [ID] [reason]`._",
+		"MSG_PICKED_UP":              "🚚 *OUT FOR DELIVERY*\n\n%s\n\n_The customers have been notified. Safe riding!_",
+		"MSG_NOTHING_TO_PICK_UP":     "🛵 _Nothing is waiting for you to pick up right now._",
+		"MSG_DELIVERED_USAGE":        "✅ Usage: `!delivered [TrackingID] [code]`\n\n_The code is the one sent to the recipient, for shipments that need it._",
+		"MSG_MARKED_DELIVERED":       "✅ *DELIVERED*\n\n_Shipment *%s* is marked as delivered and the customer has been notified._",
+		"MSG_FAILED_USAGE":           "🚪 Usage: `!failed [TrackingID] [reason] [note]`\n\nReasons: %s\n\n*Example:* `!failed AWB-123456789 no_one_home gate locked`",
+		"MSG_ATTEMPT_RECORDED":       "🚪 *ATTEMPT %d OF %d RECORDED*\n\n_Shipment *%s* is rescheduled for %s. The customer has been notified._",
+		"MSG_ATTEMPT_FINAL_RECORDED": "🚪 *ATTEMPT %d OF %d RECORDED*\n\n_No attempts are left: shipment *%s* is now marked as delivery failed. Please bring it back to the office._",
+		"ERR_INVALID_ATTEMPT":        "🚪 *Attempt Not Recorded*\n\n_%s_",
+		"ERR_NOT_ASSIGNED":           "⛔ *Not Your Delivery*\n\n_Shipment *%s* is not assigned to you. Please check the ID or ask the office._",
+		"ERR_RIDER_PRIVATE_ONLY":     "🛵 _Rider commands only work in your private chat with the bot._",
+		"MSG_PICKUP_USAGE":           "🚚 Usage: `!pickup [TrackingID]`\n\n_Riders can send just `!pickup` to take out everything assigned to them._",
+		"MSG_CHECKIN_USAGE":          "📍 Usage: `!checkin [TrackingID]`, then share your location.\n\n_You can also reply to a shipment's receipt with your location._",
+		"MSG_CHECKIN_OPEN":           "📍 *CHECK-IN OPEN*\n\n_Share your location now. Locations you send in the next %d minutes are added to shipment *%s*._",
+		"MSG_CHECKPOINT_SAVED":       "📍 *CHECKPOINT SAVED*\n\n_Shipment *%s* was last seen at %s._\n%s",
+		"MSG_LIVE_CHECKPOINTS":       "📍 *LIVE LOCATION LINKED*\n\n_While you share it, shipment *%s* is updated every few minutes. Now at %s._",
+		"MSG_CHECKPOINT_NO_SHIPMENT": "📍 _Which shipment is this location for? Reply to its receipt with your location, or send `!checkin [TrackingID]` first._",
+		"ERR_INVALID_CHECKPOINT":     "📍 _Your phone didn't send a position. Please turn on location and try again._",
+		"MSG_POD_USAGE":              "📸 Usage: send a photo with the caption `!pod [TrackingID]`.\n\n_You can also reply to a shipment's receipt with the photo._",
+		"MSG_POD_DELIVERED":          "✅ *DELIVERED*\n\n_The photo was saved as proof of delivery for *%s*, the shipment is marked as delivered and the customer has been notified._",
+		"MSG_POD_ADDED":              "📸 _The photo was added to the proof of delivery for *%s*._",
+		"ERR_INVALID_POD":            "📸 *Photo Not Saved*\n\n_%s_",
+		"ERR_POD_DISABLED":           "📸 _Proof of delivery photos are not enabled on this server._",
+		"ERR_OTP_REQUIRED":           "🔐 *Delivery Code Needed*\n\n_Shipment *%s* can only be delivered with the code sent to the recipient. Ask them for it and send `!delivered %s [code]`._",
+		"ERR_WRONG_OTP":              "❌ *Wrong Delivery Code*\n\n_That is not the code for *%s*. The attempt was logged._",
+		"ERR_OTP_LOCKED":             "⛔ *Delivery Code Locked*\n\n_Too many wrong codes for *%s*. The office has to send the recipient a new one._",
+		"MSG_BAG_USAGE":              "🧳 *BAGS*\n\nUsage:\n• `!bag` - Recent bags\n• `!bag [MAWB]` - What's in a bag\n• `!bag create [label]` - Open a new bag (e.g. the flight)\n• `!bag add [MAWB] [ID] ...` - Put shipments in it\n• `!bag remove [MAWB] [ID]` - Take one out\n• `!bag close [MAWB]` - Seal it\n• `!bag depart [MAWB]` - Everything in it goes in transit\n• `!bag status [MAWB] [status]` - Move everything in a departed bag",
+		"MSG_BAG_CREATED":            "🧳 *Bag opened*\n\nMaster AWB: *%s*%s\n\n_Add shipments with_ `!bag add [MAWB] [ID] ...`",
+		"MSG_BAG_INFO":               "🧳 *%s*%s\n\nState: *%s*\nShipments: *%d*\n\n%s",
+		"MSG_BAG_LIST":               "🧳 *RECENT BAGS*\n\n%s",
+		"MSG_BAG_LIST_EMPTY":         "🧳 No bags yet. Open one with `!bag create [label]`.",
+		"MSG_BAG_ADDED":              "✅ %d shipment(s) added to *%s* (%d in the bag).",
+		"MSG_BAG_REMOVED":            "✅ *%s* taken out of *%s*.",
+		"MSG_BAG_CLOSED":             "🔒 *%s* sealed with %d shipment(s).\n\n_Send_ `!bag depart [MAWB]` _when it leaves._",
+		"MSG_BAG_MOVED":              "✈️ *%s* is now *%s*\n\n%d shipment(s) updated and their customers notified.",
+		"MSG_BAG_SKIPPED":            "⚠️ *Not updated:*\n%s",
+		"ERR_BAG_NOT_FOUND":          "❌ *NOT FOUND*\nNo bag with master AWB *%s*.",
+		"ERR_INVALID_BAG":            "⚠️ *Bag not changed*\n\n_%s_",
+		"ERR_UNDO_STALE":             "⚠️ *Cannot Undo*\n\n_Shipment *%s* was changed again after your last edit. Undoing it now would overwrite the newer details._",
+		"ALERT_DELIVERY_OTP":         "🔐 *DELIVERY CODE*\n\nTracking ID: *%s*\nCode: *%s*\n\nYour shipment is out for delivery. Give this code to the courier only once the package is in your hands; it can't be marked delivered without it.",
+		"ERR_NOT_IN_BAG":             "⚠️ *%s* is not in bag *%s*.",
 	},
 	PT: {
 		"receipt_receiver":    "DESTINATÁRIO",
@@ -72,6 +155,89 @@ var Translations = map[Language]map[string]string{
 		"MSG_EDIT_SUCCESS":     "✅ *Detalhes do Envio Atualizados*\n\n🆔 *%s*\n\n📝 *Campos Modificados:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Por favor, aguarde enquanto geramos seu recibo digital atualizado..._",
 		"MSG_STATS_HEADER":     "📊 *Métricas do Sistema %s*",
 		"MSG_STATUS_DASHBOARD": "🖥️ *Painel de Operações*",
+
+		"ERR_INVALID_TRANSITION":     "🚦 *Alteração de Status Rejeitada*\n\n_Um envio não pode passar de *%s* para *%s*._\n\n✅ *Permitido:* %s",
+		"MSG_QUOTE_USAGE":            "💰 *ESTIMATIVA DE PREÇO*\n\nUso: `!quote [origem] [destino] [kg]`\n\n*Exemplo:* `!quote Nigeria UK 5`\n_Para países com várias palavras use \"to\": `!quote South Africa to United Kingdom 5`_",
+		"MSG_QUOTE":                  "💰 *ESTIMATIVA DE PREÇO*\n\n📍 *Rota:* %s → %s\n⚖️ *Peso taxável:* %.2f kg\n\n━━━━━━━━━━━━━━━━━━━━━━━\n• Tarifa base: %s\n• Sobretaxa de combustível: %s\n━━━━━━━━━━━━━━━━━━━━━━━\n💵 *TOTAL:* %s\n\n_Apenas uma estimativa. O preço final é confirmado na entrega no balcão._",
+		"ERR_NO_RATE_CARD":           "💰 *Preços Não Configurados*\n\n_Nenhuma tabela de preços foi configurada para esta empresa. Configure uma no painel._",
+		"ERR_NO_RATE":                "💰 *Tarifa Indisponível*\n\n_A tabela de preços não tem valor para %s → %s._",
+		"receipt_pieces":             "VOLUMES",
+		"ERR_NO_CUSTOMS":             "🧾 *Sem Declaração Aduaneira*\n\n_O envio %s não tem itens aduaneiros registados. Adicione-os primeiro no painel._",
+		"MSG_INVOICE_CAPTION":        "🧾 Fatura comercial de *%s*",
+		"MSG_COD_USAGE":              "💵 *PAGAMENTO NA ENTREGA*\n\nUso:\n• `!cod` - Saldo por moeda\n• `!cod collect [ID] [valor]` - Marcar o COD de um envio como recebido (por padrão o valor total)",
+		"MSG_COD_EMPTY":              "💵 *PAGAMENTO NA ENTREGA*\n\n_Ainda não há envios COD nem lançamentos._",
+		"MSG_COD_HEADER":             "💵 *PAGAMENTO NA ENTREGA*",
+		"MSG_COD_BALANCE":            "*%s*\n• Recebido: %s\n• Repassado: %s\n• Ajustes: %s\n• *Devido ao comerciante: %s*\n• Entregue, não recebido: %s (%d)\n• Aguardando entrega: %s (%d)",
+		"MSG_COD_COLLECTED":          "✅ *COD RECEBIDO*\n\n%s: *%s* lançado no livro-caixa.",
+		"ERR_NO_COD":                 "💵 *Sem Pagamento na Entrega*\n\n_O envio %s é pré-pago._",
+		"ERR_COD_COLLECTED":          "💵 *Já Recebido*\n\n_O COD de %s já está no livro-caixa._",
+		"MSG_RESTORE_USAGE":          "♻️ *RESTAURAR ENVIO*\n\nUso: `!restore [ID de Rastreio]`",
+		"MSG_SHIPMENT_RESTORED":      "♻️ *ENVIO RESTAURADO*\n\nO envio *%s* está de volta à sua lista ativa.",
+		"ERR_NOT_IN_TRASH":           "❌ *NÃO ESTÁ NA LIXEIRA*\n\n_O envio %s não está na lixeira. Talvez nunca tenha sido excluído ou já tenha sido eliminado._",
+		"ERR_INVALID_TRACKING_ID":    "🔢 *ID de Rastreio Inválido*\n\n_*%s* não é um ID de rastreio. Eles são assim: *AWB-123456789*._",
+		"ERR_CHECK_DIGIT":            "🔢 *Dígito de Controlo Inválido*\n\n_O último dígito de *%s* não confere. Provavelmente há um erro de digitação — verifique o ID e tente novamente._",
+		"MSG_UNDO_DONE":              "↩️ *Edição Desfeita*\n\n🆔 *%s*\n\n📝 *Campos Restaurados:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Por favor, aguarde enquanto geramos seu recibo digital atualizado..._",
+		"MSG_NOTHING_TO_UNDO":        "↩️ *NADA PARA DESFAZER*\n\n_Você não tem edições recentes para reverter._",
+		"ERR_EDIT_CONFLICT":          "🔄 *Conflito de Edição*\n\n_O envio *%s* foi alterado por outra pessoa enquanto você o editava. Verifique os dados mais recentes e tente novamente._",
+		"ERR_UNKNOWN_CONTACT":        "📇 *Contato Desconhecido*\n\n_Nenhum contato salvo com o apelido %s. Verifique a grafia ou adicione-o na agenda do painel._",
+		"ERR_INVALID_CUSTOM_FIELD":   "⚠️ *Valor Inválido*\n\n_%s_",
+		"MSG_RETURN_USAGE":           "↩️ *DEVOLVER AO REMETENTE*\n\nUso: `!return [ID de Rastreio] [motivo]`\n\n*Exemplo:* `!return AWB-123456789 destinatário recusou o pacote`",
+		"MSG_SHIPMENT_RETURNED":      "↩️ *DEVOLUÇÃO CRIADA*\n\n📦 *Original:* %s\n🔁 *Devolução:* %s\n📝 *Motivo:* %s\n\n_O envio original foi marcado como devolvido. O remetente foi notificado._",
+		"ERR_ALREADY_RETURNED":       "↩️ *Já Devolvido*\n\n_O envio %s já está a caminho de volta como *%s*._",
+		"ERR_INVALID_RETURN":         "↩️ *Devolução Não Permitida*\n\n_%s_",
+		"ALERT_ATTEMPT_FAILED":       "🚪 *TENTATIVA DE ENTREGA FALHOU*\n\nID de Rastreio: *%s*\nTentativa: *%d de %d*\nMotivo: _%s_\n\nO nosso estafeta tentará novamente em *%s*. Por favor, garanta que alguém esteja disponível para receber o envio.",
+		"ALERT_ATTEMPT_FINAL":        "🚪 *ENTREGA FALHOU*\n\nID de Rastreio: *%s*\nTentativa: *%d de %d*\nMotivo: _%s_\n\nEsta foi a última tentativa de entrega. Contacte-nos para combinar um novo endereço, levantamento ou devolução ao remetente.",
+		"attempt_no_one_home":        "Ninguém estava disponível",
+		"attempt_wrong_address":      "Endereço incorreto ou incompleto",
+		"attempt_refused":            "Entrega recusada",
+		"attempt_business_closed":    "Estabelecimento fechado",
+		"attempt_other":              "Outro",
+		"MSG_ASSIGN_USAGE":           "🛵 *ATRIBUIR ESTAFETA*\n\nUso: `!assign [ID de Rastreio] [nome ou telefone do estafeta]`\nUse `!assign [ID de Rastreio] none` para remover o estafeta.\n\n*Exemplo:* `!assign AWB-123456789 Musa`",
+		"MSG_RIDER_ASSIGNED":         "🛵 *ESTAFETA ATRIBUÍDO*\n\n📦 *Envio:* %s\n👤 *Estafeta:* %s (%s)\n\n_Os detalhes da entrega foram enviados ao estafeta._",
+		"MSG_RIDER_UNASSIGNED":       "🛵 _O envio %s já não tem estafeta. O estado voltará a avançar conforme o horário._",
+		"ERR_RIDER_NOT_FOUND":        "🛵 *Estafeta Não Encontrado*\n\n_Nenhum estafeta ativo corresponde a *%s*. Os estafetas são adicionados no portal de administração._",
+		"ERR_INVALID_ASSIGNMENT":     "🛵 *Atribuição Não Possível*\n\n_%s_",
+		"MSG_RIDER_NEW_JOB":          "🛵 *NOVA ENTREGA*\n\n📦 *%s*\n👤 %s\n📞 %s\n📍 %s, %s\n\n_Envie `!pickup` quando tiver o pacote e depois `!delivered [ID]` ou `!failed [ID] [motivo]`._",
+		"MSG_PICKED_UP":              "🚚 *SAIU PARA ENTREGA*\n\n%s\n\n_Os clientes foram notificados. Boa viagem!_",
+		"MSG_NOTHING_TO_PICK_UP":     "🛵 _Não há nada à sua espera para recolher neste momento._",
+		"MSG_DELIVERED_USAGE":        "✅ Uso: `!delivered [ID de Rastreio] [código]`\n\n_O código é o enviado ao destinatário, para envios que o exigem._",
+		"MSG_MARKED_DELIVERED":       "✅ *ENTREGUE*\n\n_O envio *%s* foi marcado como entregue e o cliente foi notificado._",
+		"MSG_FAILED_USAGE":           "🚪 Uso: `!failed [ID de Rastreio] [motivo] [nota]`\n\nMotivos: %s\n\n*Exemplo:* `!failed AWB-123456789 no_one_home portão fechado`",
+		"MSG_ATTEMPT_RECORDED":       "🚪 *TENTATIVA %d DE %d REGISTADA*\n\n_O envio *%s* foi reagendado para %s. O cliente foi notificado._",
+		"MSG_ATTEMPT_FINAL_RECORDED": "🚪 *TENTATIVA %d DE %d REGISTADA*\n\n_Não restam tentativas: o envio *%s* está agora marcado como entrega falhada. Por favor, traga-o de volta ao escritório._",
+		"ERR_INVALID_ATTEMPT":        "🚪 *Tentativa Não Registada*\n\n_%s_",
+		"ERR_NOT_ASSIGNED":           "⛔ *Entrega Não Atribuída*\n\n_O envio *%s* não está atribuído a si. Verifique o ID ou contacte o escritório._",
+		"ERR_RIDER_PRIVATE_ONLY":     "🛵 _Os comandos de estafeta só funcionam na sua conversa privada com o bot._",
+		"MSG_PICKUP_USAGE":           "🚚 Uso: `!pickup [ID de Rastreio]`\n\n_Os estafetas podem enviar apenas `!pickup` para levar tudo o que lhes está atribuído._",
+		"MSG_CHECKIN_USAGE":          "📍 Uso: `!checkin [ID de Rastreio]` e depois partilhe a sua localização.\n\n_Também pode responder ao recibo de um envio com a sua localização._",
+		"MSG_CHECKIN_OPEN":           "📍 *CHECK-IN ABERTO*\n\n_Partilhe a sua localização agora. As localizações enviadas nos próximos %d minutos são adicionadas ao envio *%s*._",
+		"MSG_CHECKPOINT_SAVED":       "📍 *PONTO DE CONTROLO GUARDADO*\n\n_O envio *%s* foi visto pela última vez em %s._\n%s",
+		"MSG_LIVE_CHECKPOINTS":       "📍 *LOCALIZAÇÃO EM TEMPO REAL LIGADA*\n\n_Enquanto a partilhar, o envio *%s* é atualizado a cada poucos minutos. Agora em %s._",
+		"MSG_CHECKPOINT_NO_SHIPMENT": "📍 _Para que envio é esta localização? Responda ao recibo com a sua localização ou envie primeiro `!checkin [ID de Rastreio]`._",
+		"ERR_INVALID_CHECKPOINT":     "📍 _O seu telemóvel não enviou uma posição. Ative a localização e tente novamente._",
+		"MSG_POD_USAGE":              "📸 Uso: envie uma foto com a legenda `!pod [ID de Rastreio]`.\n\n_Também pode responder ao recibo de um envio com a foto._",
+		"MSG_POD_DELIVERED":          "✅ *ENTREGUE*\n\n_A foto foi guardada como comprovativo de entrega de *%s*, o envio foi marcado como entregue e o cliente foi notificado._",
+		"MSG_POD_ADDED":              "📸 _A foto foi adicionada ao comprovativo de entrega de *%s*._",
+		"ERR_INVALID_POD":            "📸 *Foto Não Guardada*\n\n_%s_",
+		"ERR_POD_DISABLED":           "📸 _As fotos de comprovativo de entrega não estão ativadas neste servidor._",
+		"ERR_OTP_REQUIRED":           "🔐 *Código de Entrega Necessário*\n\n_O envio *%s* só pode ser entregue com o código enviado ao destinatário. Peça-o e envie `!delivered %s [código]`._",
+		"ERR_WRONG_OTP":              "❌ *Código de Entrega Errado*\n\n_Este não é o código de *%s*. A tentativa foi registada._",
+		"ERR_OTP_LOCKED":             "⛔ *Código de Entrega Bloqueado*\n\n_Demasiados códigos errados para *%s*. O escritório tem de enviar um novo ao destinatário._",
+		"MSG_BAG_USAGE":              "🧳 *MALAS*\n\nUso:\n• `!bag` - Malas recentes\n• `!bag [MAWB]` - Conteúdo de uma mala\n• `!bag create [etiqueta]` - Abrir uma nova mala (ex. o voo)\n• `!bag add [MAWB] [ID] ...` - Colocar envios nela\n• `!bag remove [MAWB] [ID]` - Retirar um\n• `!bag close [MAWB]` - Selar\n• `!bag depart [MAWB]` - Tudo nela fica em trânsito\n• `!bag status [MAWB] [estado]` - Mover tudo numa mala já partida",
+		"MSG_BAG_CREATED":            "🧳 *Mala aberta*\n\nMaster AWB: *%s*%s\n\n_Adicione envios com_ `!bag add [MAWB] [ID] ...`",
+		"MSG_BAG_INFO":               "🧳 *%s*%s\n\nEstado: *%s*\nEnvios: *%d*\n\n%s",
+		"MSG_BAG_LIST":               "🧳 *MALAS RECENTES*\n\n%s",
+		"MSG_BAG_LIST_EMPTY":         "🧳 Ainda não há malas. Abra uma com `!bag create [etiqueta]`.",
+		"MSG_BAG_ADDED":              "✅ %d envio(s) adicionado(s) a *%s* (%d na mala).",
+		"MSG_BAG_REMOVED":            "✅ *%s* retirado de *%s*.",
+		"MSG_BAG_CLOSED":             "🔒 *%s* selada com %d envio(s).\n\n_Envie_ `!bag depart [MAWB]` _quando partir._",
+		"MSG_BAG_MOVED":              "✈️ *%s* está agora *%s*\n\n%d envio(s) atualizado(s) e os clientes notificados.",
+		"MSG_BAG_SKIPPED":            "⚠️ *Não atualizados:*\n%s",
+		"ERR_BAG_NOT_FOUND":          "❌ *NÃO ENCONTRADO*\nNenhuma mala com o master AWB *%s*.",
+		"ERR_INVALID_BAG":            "⚠️ *Mala não alterada*\n\n_%s_",
+		"ERR_UNDO_STALE":             "⚠️ *Não É Possível Desfazer*\n\n_O envio *%s* foi alterado novamente após a sua última edição. Desfazer agora substituiria os dados mais recentes._",
+		"ALERT_DELIVERY_OTP":         "🔐 *CÓDIGO DE ENTREGA*\n\nID de Rastreio: *%s*\nCódigo: *%s*\n\nO seu envio saiu para entrega. Dê este código ao estafeta apenas quando tiver o pacote nas mãos; sem ele, o envio não pode ser marcado como entregue.",
+		"ERR_NOT_IN_BAG":             "⚠️ *%s* não está na mala *%s*.",
 	},
 	ES: {
 		"receipt_receiver":    "DESTINATARIO",
@@ -101,6 +267,89 @@ var Translations = map[Language]map[string]string{
 		"MSG_EDIT_SUCCESS":     "✅ *Detalles de Envío Actualizados*\n\n🆔 *%s*\n\n📝 *Campos Modificados:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Por favor, espere mientras generamos su recibo digital actualizado..._",
 		"MSG_STATS_HEADER":     "📊 *Métricas del Sistema %s*",
 		"MSG_STATUS_DASHBOARD": "🖥️ *Panel de Operaciones*",
+
+		"ERR_INVALID_TRANSITION":     "🚦 *Cambio de Estado Rechazado*\n\n_Un envío no puede pasar de *%s* a *%s*._\n\n✅ *Permitido:* %s",
+		"MSG_QUOTE_USAGE":            "💰 *ESTIMACIÓN DE PRECIO*\n\nUso: `!quote [origen] [destino] [kg]`\n\n*Ejemplo:* `!quote Nigeria UK 5`\n_Para países de varias palabras use \"to\": `!quote South Africa to United Kingdom 5`_",
+		"MSG_QUOTE":                  "💰 *ESTIMACIÓN DE PRECIO*\n\n📍 *Ruta:* %s → %s\n⚖️ *Peso facturable:* %.2f kg\n\n━━━━━━━━━━━━━━━━━━━━━━━\n• Tarifa base: %s\n• Recargo por combustible: %s\n━━━━━━━━━━━━━━━━━━━━━━━\n💵 *TOTAL:* %s\n\n_Solo es una estimación. El precio final se confirma en la entrega en mostrador._",
+		"ERR_NO_RATE_CARD":           "💰 *Precios No Configurados*\n\n_Aún no se ha configurado una tarifa para esta empresa. Configure una en el panel._",
+		"ERR_NO_RATE":                "💰 *Tarifa No Disponible*\n\n_La tarifa no tiene precio para %s → %s._",
+		"receipt_pieces":             "BULTOS",
+		"ERR_NO_CUSTOMS":             "🧾 *Sin Declaración Aduanera*\n\n_El envío %s no tiene artículos aduaneros registrados. Agréguelos primero en el panel._",
+		"MSG_INVOICE_CAPTION":        "🧾 Factura comercial de *%s*",
+		"MSG_COD_USAGE":              "💵 *PAGO CONTRA ENTREGA*\n\nUso:\n• `!cod` - Saldo por moneda\n• `!cod collect [ID] [monto]` - Marcar el COD de un envío como cobrado (por defecto el monto total)",
+		"MSG_COD_EMPTY":              "💵 *PAGO CONTRA ENTREGA*\n\n_Todavía no hay envíos COD ni movimientos._",
+		"MSG_COD_HEADER":             "💵 *PAGO CONTRA ENTREGA*",
+		"MSG_COD_BALANCE":            "*%s*\n• Cobrado: %s\n• Remitido: %s\n• Ajustes: %s\n• *Adeudado al comercio: %s*\n• Entregado, no cobrado: %s (%d)\n• Pendiente de entrega: %s (%d)",
+		"MSG_COD_COLLECTED":          "✅ *COD COBRADO*\n\n%s: *%s* registrado en el libro.",
+		"ERR_NO_COD":                 "💵 *Sin Pago Contra Entrega*\n\n_El envío %s está prepagado._",
+		"ERR_COD_COLLECTED":          "💵 *Ya Cobrado*\n\n_El COD de %s ya está en el libro._",
+		"MSG_RESTORE_USAGE":          "♻️ *RESTAURAR ENVÍO*\n\nUso: `!restore [ID de Seguimiento]`",
+		"MSG_SHIPMENT_RESTORED":      "♻️ *ENVÍO RESTAURADO*\n\nEl envío *%s* está de nuevo en su lista activa.",
+		"ERR_NOT_IN_TRASH":           "❌ *NO ESTÁ EN LA PAPELERA*\n\n_El envío %s no está en la papelera. Puede que nunca se haya eliminado o que ya se haya purgado._",
+		"ERR_INVALID_TRACKING_ID":    "🔢 *ID de Seguimiento Inválido*\n\n_*%s* no es un ID de seguimiento. Tienen este formato: *AWB-123456789*._",
+		"ERR_CHECK_DIGIT":            "🔢 *Dígito de Control Incorrecto*\n\n_El último dígito de *%s* no coincide. Probablemente hay un error tipográfico — revise el ID e inténtelo de nuevo._",
+		"MSG_UNDO_DONE":              "↩️ *Edición Deshecha*\n\n🆔 *%s*\n\n📝 *Campos Restaurados:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Por favor, espere mientras generamos su recibo digital actualizado..._",
+		"MSG_NOTHING_TO_UNDO":        "↩️ *NADA QUE DESHACER*\n\n_No tiene ediciones recientes que revertir._",
+		"ERR_EDIT_CONFLICT":          "🔄 *Conflicto de Edición*\n\n_El envío *%s* fue modificado por otra persona mientras lo editaba. Revise los datos más recientes e inténtelo de nuevo._",
+		"ERR_UNKNOWN_CONTACT":        "📇 *Contacto Desconocido*\n\n_No hay ningún contacto guardado con el alias %s. Revise la ortografía o agréguelo en la libreta de direcciones del panel._",
+		"ERR_INVALID_CUSTOM_FIELD":   "⚠️ *Valor No Válido*\n\n_%s_",
+		"MSG_RETURN_USAGE":           "↩️ *DEVOLVER AL REMITENTE*\n\nUso: `!return [ID de Seguimiento] [motivo]`\n\n*Ejemplo:* `!return AWB-123456789 el destinatario rechazó el paquete`",
+		"MSG_SHIPMENT_RETURNED":      "↩️ *DEVOLUCIÓN CREADA*\n\n📦 *Original:* %s\n🔁 *Devolución:* %s\n📝 *Motivo:* %s\n\n_El envío original se marcó como devuelto. Se ha notificado al remitente._",
+		"ERR_ALREADY_RETURNED":       "↩️ *Ya Devuelto*\n\n_El envío %s ya está de regreso como *%s*._",
+		"ERR_INVALID_RETURN":         "↩️ *Devolución No Permitida*\n\n_%s_",
+		"ALERT_ATTEMPT_FAILED":       "🚪 *INTENTO DE ENTREGA FALLIDO*\n\nID de Seguimiento: *%s*\nIntento: *%d de %d*\nMotivo: _%s_\n\nNuestro mensajero lo intentará de nuevo el *%s*. Asegúrese de que haya alguien disponible para recibir el envío.",
+		"ALERT_ATTEMPT_FINAL":        "🚪 *ENTREGA FALLIDA*\n\nID de Seguimiento: *%s*\nIntento: *%d de %d*\nMotivo: _%s_\n\nEste fue el último intento de entrega. Contáctenos para acordar una nueva dirección, la recogida o la devolución al remitente.",
+		"attempt_no_one_home":        "No había nadie disponible",
+		"attempt_wrong_address":      "Dirección incorrecta o incompleta",
+		"attempt_refused":            "Entrega rechazada",
+		"attempt_business_closed":    "Establecimiento cerrado",
+		"attempt_other":              "Otro",
+		"MSG_ASSIGN_USAGE":           "🛵 *ASIGNAR REPARTIDOR*\n\nUso: `!assign [ID de Seguimiento] [nombre o teléfono del repartidor]`\nUse `!assign [ID de Seguimiento] none` para quitar el repartidor.\n\n*Ejemplo:* `!assign AWB-123456789 Musa`",
+		"MSG_RIDER_ASSIGNED":         "🛵 *REPARTIDOR ASIGNADO*\n\n📦 *Envío:* %s\n👤 *Repartidor:* %s (%s)\n\n_Se enviaron los detalles de la entrega al repartidor._",
+		"MSG_RIDER_UNASSIGNED":       "🛵 _El envío %s ya no tiene repartidor. Su estado volverá a avanzar según el horario._",
+		"ERR_RIDER_NOT_FOUND":        "🛵 *Repartidor No Encontrado*\n\n_Ningún repartidor activo coincide con *%s*. Los repartidores se añaden en el portal de administración._",
+		"ERR_INVALID_ASSIGNMENT":     "🛵 *Asignación No Posible*\n\n_%s_",
+		"MSG_RIDER_NEW_JOB":          "🛵 *NUEVA ENTREGA*\n\n📦 *%s*\n👤 %s\n📞 %s\n📍 %s, %s\n\n_Envíe `!pickup` cuando tenga el paquete y luego `!delivered [ID]` o `!failed [ID] [motivo]`._",
+		"MSG_PICKED_UP":              "🚚 *EN REPARTO*\n\n%s\n\n_Los clientes han sido notificados. ¡Buen viaje!_",
+		"MSG_NOTHING_TO_PICK_UP":     "🛵 _No hay nada pendiente de recoger en este momento._",
+		"MSG_DELIVERED_USAGE":        "✅ Uso: `!delivered [ID de Seguimiento] [código]`\n\n_El código es el enviado al destinatario, para envíos que lo requieren._",
+		"MSG_MARKED_DELIVERED":       "✅ *ENTREGADO*\n\n_El envío *%s* se marcó como entregado y el cliente ha sido notificado._",
+		"MSG_FAILED_USAGE":           "🚪 Uso: `!failed [ID de Seguimiento] [motivo] [nota]`\n\nMotivos: %s\n\n*Ejemplo:* `!failed AWB-123456789 no_one_home portón cerrado`",
+		"MSG_ATTEMPT_RECORDED":       "🚪 *INTENTO %d DE %d REGISTRADO*\n\n_El envío *%s* se reprogramó para el %s. El cliente ha sido notificado._",
+		"MSG_ATTEMPT_FINAL_RECORDED": "🚪 *INTENTO %d DE %d REGISTRADO*\n\n_No quedan intentos: el envío *%s* ahora figura como entrega fallida. Por favor, devuélvalo a la oficina._",
+		"ERR_INVALID_ATTEMPT":        "🚪 *Intento No Registrado*\n\n_%s_",
+		"ERR_NOT_ASSIGNED":           "⛔ *Entrega No Asignada*\n\n_El envío *%s* no está asignado a usted. Verifique el ID o consulte con la oficina._",
+		"ERR_RIDER_PRIVATE_ONLY":     "🛵 _Los comandos de repartidor solo funcionan en su chat privado con el bot._",
+		"MSG_PICKUP_USAGE":           "🚚 Uso: `!pickup [ID de Seguimiento]`\n\n_Los repartidores pueden enviar solo `!pickup` para llevar todo lo que tienen asignado._",
+		"MSG_CHECKIN_USAGE":          "📍 Uso: `!checkin [ID de Seguimiento]` y luego comparte tu ubicación.\n\n_También puedes responder al recibo de un envío con tu ubicación._",
+		"MSG_CHECKIN_OPEN":           "📍 *CHECK-IN ABIERTO*\n\n_Comparte tu ubicación ahora. Las ubicaciones que envíes en los próximos %d minutos se añaden al envío *%s*._",
+		"MSG_CHECKPOINT_SAVED":       "📍 *PUNTO DE CONTROL GUARDADO*\n\n_El envío *%s* fue visto por última vez en %s._\n%s",
+		"MSG_LIVE_CHECKPOINTS":       "📍 *UBICACIÓN EN TIEMPO REAL VINCULADA*\n\n_Mientras la compartas, el envío *%s* se actualiza cada pocos minutos. Ahora en %s._",
+		"MSG_CHECKPOINT_NO_SHIPMENT": "📍 _¿Para qué envío es esta ubicación? Responde al recibo con tu ubicación o envía primero `!checkin [ID de Seguimiento]`._",
+		"ERR_INVALID_CHECKPOINT":     "📍 _Tu teléfono no envió una posición. Activa la ubicación e inténtalo de nuevo._",
+		"MSG_POD_USAGE":              "📸 Uso: envía una foto con el texto `!pod [ID de Seguimiento]`.\n\n_También puedes responder al recibo de un envío con la foto._",
+		"MSG_POD_DELIVERED":          "✅ *ENTREGADO*\n\n_La foto se guardó como prueba de entrega de *%s*, el envío se marcó como entregado y el cliente ha sido notificado._",
+		"MSG_POD_ADDED":              "📸 _La foto se añadió a la prueba de entrega de *%s*._",
+		"ERR_INVALID_POD":            "📸 *Foto No Guardada*\n\n_%s_",
+		"ERR_POD_DISABLED":           "📸 _Las fotos de prueba de entrega no están activadas en este servidor._",
+		"ERR_OTP_REQUIRED":           "🔐 *Código de Entrega Requerido*\n\n_El envío *%s* solo puede entregarse con el código enviado al destinatario. Pídaselo y envíe `!delivered %s [código]`._",
+		"ERR_WRONG_OTP":              "❌ *Código de Entrega Incorrecto*\n\n_Ese no es el código de *%s*. El intento quedó registrado._",
+		"ERR_OTP_LOCKED":             "⛔ *Código de Entrega Bloqueado*\n\n_Demasiados códigos incorrectos para *%s*. La oficina debe enviar uno nuevo al destinatario._",
+		"MSG_BAG_USAGE":              "🧳 *SACAS*\n\nUso:\n• `!bag` - Sacas recientes\n• `!bag [MAWB]` - Contenido de una saca\n• `!bag create [etiqueta]` - Abrir una saca nueva (p. ej. el vuelo)\n• `!bag add [MAWB] [ID] ...` - Meter envíos\n• `!bag remove [MAWB] [ID]` - Sacar uno\n• `!bag close [MAWB]` - Cerrarla\n• `!bag depart [MAWB]` - Todo lo que contiene pasa a en tránsito\n• `!bag status [MAWB] [estado]` - Mover todo en una saca ya despachada",
+		"MSG_BAG_CREATED":            "🧳 *Saca abierta*\n\nMaster AWB: *%s*%s\n\n_Añada envíos con_ `!bag add [MAWB] [ID] ...`",
+		"MSG_BAG_INFO":               "🧳 *%s*%s\n\nEstado: *%s*\nEnvíos: *%d*\n\n%s",
+		"MSG_BAG_LIST":               "🧳 *SACAS RECIENTES*\n\n%s",
+		"MSG_BAG_LIST_EMPTY":         "🧳 Aún no hay sacas. Abra una con `!bag create [etiqueta]`.",
+		"MSG_BAG_ADDED":              "✅ %d envío(s) añadido(s) a *%s* (%d en la saca).",
+		"MSG_BAG_REMOVED":            "✅ *%s* sacado de *%s*.",
+		"MSG_BAG_CLOSED":             "🔒 *%s* cerrada con %d envío(s).\n\n_Envíe_ `!bag depart [MAWB]` _cuando salga._",
+		"MSG_BAG_MOVED":              "✈️ *%s* está ahora *%s*\n\n%d envío(s) actualizado(s) y sus clientes notificados.",
+		"MSG_BAG_SKIPPED":            "⚠️ *No actualizados:*\n%s",
+		"ERR_BAG_NOT_FOUND":          "❌ *NO ENCONTRADO*\nNinguna saca con el master AWB *%s*.",
+		"ERR_INVALID_BAG":            "⚠️ *Saca no modificada*\n\n_%s_",
+		"ERR_UNDO_STALE":             "⚠️ *No Se Puede Deshacer*\n\n_El envío *%s* se modificó de nuevo después de su última edición. Deshacerla ahora sobrescribiría los datos más recientes._",
+		"ALERT_DELIVERY_OTP":         "🔐 *CÓDIGO DE ENTREGA*\n\nID de Seguimiento: *%s*\nCódigo: *%s*\n\nSu envío está en reparto. Entregue este código al mensajero solo cuando tenga el paquete en sus manos; sin él, el envío no puede marcarse como entregado.",
+		"ERR_NOT_IN_BAG":             "⚠️ *%s* no está en la saca *%s*.",
 	},
 	DE: {
 		"receipt_receiver":    "EMPFÄNGER",
@@ -130,6 +379,89 @@ var Translations = map[Language]map[string]string{
 		"MSG_EDIT_SUCCESS":     "✅ *Sendungsdetails Aktualisiert*\n\n🆔 *%s*\n\n📝 *Geänderte Felder:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Bitte warten Sie, während wir Ihre aktualisierte digitale Quittung generieren..._",
 		"MSG_STATS_HEADER":     "📊 *%s Systemmetriken*",
 		"MSG_STATUS_DASHBOARD": "🖥️ *Operations-Dashboard*",
+
+		"ERR_INVALID_TRANSITION":     "🚦 *Statusänderung Abgelehnt*\n\n_Eine Sendung kann nicht von *%s* zu *%s* wechseln._\n\n✅ *Erlaubt:* %s",
+		"MSG_QUOTE_USAGE":            "💰 *PREISSCHÄTZUNG*\n\nVerwendung: `!quote [Herkunft] [Ziel] [kg]`\n\n*Beispiel:* `!quote Nigeria UK 5`\n_Für Länder mit mehreren Wörtern \"to\" verwenden: `!quote South Africa to United Kingdom 5`_",
+		"MSG_QUOTE":                  "💰 *PREISSCHÄTZUNG*\n\n📍 *Strecke:* %s → %s\n⚖️ *Abrechnungsgewicht:* %.2f kg\n\n━━━━━━━━━━━━━━━━━━━━━━━\n• Grundpreis: %s\n• Treibstoffzuschlag: %s\n━━━━━━━━━━━━━━━━━━━━━━━\n💵 *GESAMT:* %s\n\n_Nur eine Schätzung. Der Endpreis wird bei der Abgabe bestätigt._",
+		"ERR_NO_RATE_CARD":           "💰 *Preise Nicht Konfiguriert*\n\n_Für dieses Unternehmen wurde noch keine Preisliste eingerichtet. Bitte im Dashboard konfigurieren._",
+		"ERR_NO_RATE":                "💰 *Kein Tarif Verfügbar*\n\n_Die Preisliste enthält keinen Preis für %s → %s._",
+		"receipt_pieces":             "PACKSTÜCKE",
+		"ERR_NO_CUSTOMS":             "🧾 *Keine Zollerklärung*\n\n_Für die Sendung %s sind keine Zollpositionen hinterlegt. Bitte zuerst im Dashboard erfassen._",
+		"MSG_INVOICE_CAPTION":        "🧾 Handelsrechnung für *%s*",
+		"MSG_COD_USAGE":              "💵 *NACHNAHME*\n\nVerwendung:\n• `!cod` - Saldo je Währung\n• `!cod collect [ID] [Betrag]` - Nachnahme einer Sendung als kassiert markieren (Standard: voller Betrag)",
+		"MSG_COD_EMPTY":              "💵 *NACHNAHME*\n\n_Noch keine Nachnahmesendungen oder Buchungen._",
+		"MSG_COD_HEADER":             "💵 *NACHNAHME*",
+		"MSG_COD_BALANCE":            "*%s*\n• Kassiert: %s\n• Ausgezahlt: %s\n• Korrekturen: %s\n• *Offen an Händler: %s*\n• Zugestellt, nicht kassiert: %s (%d)\n• Zustellung ausstehend: %s (%d)",
+		"MSG_COD_COLLECTED":          "✅ *NACHNAHME KASSIERT*\n\n%s: *%s* im Journal gebucht.",
+		"ERR_NO_COD":                 "💵 *Keine Nachnahme*\n\n_Sendung %s ist vorausbezahlt._",
+		"ERR_COD_COLLECTED":          "💵 *Bereits Kassiert*\n\n_Die Nachnahme für %s ist bereits gebucht._",
+		"MSG_RESTORE_USAGE":          "♻️ *SENDUNG WIEDERHERSTELLEN*\n\nVerwendung: `!restore [Sendungsnummer]`",
+		"MSG_SHIPMENT_RESTORED":      "♻️ *SENDUNG WIEDERHERGESTELLT*\n\nDie Sendung *%s* ist wieder in Ihrer aktiven Liste.",
+		"ERR_NOT_IN_TRASH":           "❌ *NICHT IM PAPIERKORB*\n\n_Die Sendung %s ist nicht im Papierkorb. Sie wurde entweder nie gelöscht oder bereits endgültig entfernt._",
+		"ERR_INVALID_TRACKING_ID":    "🔢 *Ungültige Sendungsnummer*\n\n_*%s* ist keine Sendungsnummer. Sie sehen so aus: *AWB-123456789*._",
+		"ERR_CHECK_DIGIT":            "🔢 *Prüfziffer Falsch*\n\n_Die letzte Ziffer von *%s* stimmt nicht. Vermutlich ein Tippfehler — bitte prüfen Sie die Nummer._",
+		"MSG_UNDO_DONE":              "↩️ *Bearbeitung Rückgängig Gemacht*\n\n🆔 *%s*\n\n📝 *Wiederhergestellte Felder:*\n• %s\n\n━━━━━━━━━━━━━━━━━━━━━━━\n_Bitte warten Sie, während wir Ihre aktualisierte digitale Quittung generieren..._",
+		"MSG_NOTHING_TO_UNDO":        "↩️ *NICHTS RÜCKGÄNGIG ZU MACHEN*\n\n_Sie haben keine letzten Änderungen, die zurückgesetzt werden können._",
+		"ERR_EDIT_CONFLICT":          "🔄 *Bearbeitungskonflikt*\n\n_Die Sendung *%s* wurde während Ihrer Bearbeitung von jemand anderem geändert. Bitte prüfen Sie die aktuellen Daten und versuchen Sie es erneut._",
+		"ERR_UNKNOWN_CONTACT":        "📇 *Unbekannter Kontakt*\n\n_Kein gespeicherter Kontakt mit dem Alias %s. Prüfen Sie die Schreibweise oder legen Sie ihn im Adressbuch des Dashboards an._",
+		"ERR_INVALID_CUSTOM_FIELD":   "⚠️ *Ungültiger Wert*\n\n_%s_",
+		"MSG_RETURN_USAGE":           "↩️ *RÜCKSENDUNG AN ABSENDER*\n\nVerwendung: `!return [Sendungsnummer] [Grund]`\n\n*Beispiel:* `!return AWB-123456789 Empfänger hat die Annahme verweigert`",
+		"MSG_SHIPMENT_RETURNED":      "↩️ *RÜCKSENDUNG ERSTELLT*\n\n📦 *Original:* %s\n🔁 *Rücksendung:* %s\n📝 *Grund:* %s\n\n_Die Originalsendung ist als zurückgesandt markiert. Der Absender wurde benachrichtigt._",
+		"ERR_ALREADY_RETURNED":       "↩️ *Bereits Zurückgesandt*\n\n_Die Sendung %s ist bereits als *%s* auf dem Rückweg._",
+		"ERR_INVALID_RETURN":         "↩️ *Rücksendung Nicht Möglich*\n\n_%s_",
+		"ALERT_ATTEMPT_FAILED":       "🚪 *ZUSTELLVERSUCH FEHLGESCHLAGEN*\n\nSendungsnummer: *%s*\nVersuch: *%d von %d*\nGrund: _%s_\n\nUnser Kurier versucht es erneut am *%s*. Bitte stellen Sie sicher, dass jemand die Sendung entgegennehmen kann.",
+		"ALERT_ATTEMPT_FINAL":        "🚪 *ZUSTELLUNG FEHLGESCHLAGEN*\n\nSendungsnummer: *%s*\nVersuch: *%d von %d*\nGrund: _%s_\n\nDies war der letzte Zustellversuch. Bitte kontaktieren Sie uns für eine neue Adresse, Abholung oder Rücksendung an den Absender.",
+		"attempt_no_one_home":        "Niemand war anwesend",
+		"attempt_wrong_address":      "Adresse falsch oder unvollständig",
+		"attempt_refused":            "Annahme verweigert",
+		"attempt_business_closed":    "Geschäft geschlossen",
+		"attempt_other":              "Sonstiges",
+		"MSG_ASSIGN_USAGE":           "🛵 *FAHRER ZUWEISEN*\n\nVerwendung: `!assign [Sendungsnummer] [Name oder Telefon des Fahrers]`\nMit `!assign [Sendungsnummer] none` wird der Fahrer entfernt.\n\n*Beispiel:* `!assign AWB-123456789 Musa`",
+		"MSG_RIDER_ASSIGNED":         "🛵 *FAHRER ZUGEWIESEN*\n\n📦 *Sendung:* %s\n👤 *Fahrer:* %s (%s)\n\n_Der Fahrer hat die Lieferdetails erhalten._",
+		"MSG_RIDER_UNASSIGNED":       "🛵 _Sendung %s hat keinen Fahrer mehr. Der Status wird wieder planmäßig fortgeschrieben._",
+		"ERR_RIDER_NOT_FOUND":        "🛵 *Fahrer Nicht Gefunden*\n\n_Kein aktiver Fahrer passt zu *%s*. Fahrer werden im Admin-Portal angelegt._",
+		"ERR_INVALID_ASSIGNMENT":     "🛵 *Zuweisung Nicht Möglich*\n\n_%s_",
+		"MSG_RIDER_NEW_JOB":          "🛵 *NEUE LIEFERUNG*\n\n📦 *%s*\n👤 %s\n📞 %s\n📍 %s, %s\n\n_Senden Sie `!pickup`, sobald Sie das Paket haben, danach `!delivered [ID]` oder `!failed [ID] [Grund]`._",
+		"MSG_PICKED_UP":              "🚚 *IN ZUSTELLUNG*\n\n%s\n\n_Die Kunden wurden benachrichtigt. Gute Fahrt!_",
+		"MSG_NOTHING_TO_PICK_UP":     "🛵 _Im Moment wartet nichts auf Abholung._",
+		"MSG_DELIVERED_USAGE":        "✅ Verwendung: `!delivered [Sendungsnummer] [Code]`\n\n_Der Code ist der an den Empfänger gesendete, für Sendungen, die ihn erfordern._",
+		"MSG_MARKED_DELIVERED":       "✅ *ZUGESTELLT*\n\n_Sendung *%s* ist als zugestellt markiert und der Kunde wurde benachrichtigt._",
+		"MSG_FAILED_USAGE":           "🚪 Verwendung: `!failed [Sendungsnummer] [Grund] [Notiz]`\n\nGründe: %s\n\n*Beispiel:* `!failed AWB-123456789 no_one_home Tor verschlossen`",
+		"MSG_ATTEMPT_RECORDED":       "🚪 *VERSUCH %d VON %d ERFASST*\n\n_Sendung *%s* ist neu geplant für %s. Der Kunde wurde benachrichtigt._",
+		"MSG_ATTEMPT_FINAL_RECORDED": "🚪 *VERSUCH %d VON %d ERFASST*\n\n_Keine Versuche mehr übrig: Sendung *%s* ist jetzt als Zustellung fehlgeschlagen markiert. Bitte bringen Sie sie ins Büro zurück._",
+		"ERR_INVALID_ATTEMPT":        "🚪 *Versuch Nicht Erfasst*\n\n_%s_",
+		"ERR_NOT_ASSIGNED":           "⛔ *Nicht Ihre Lieferung*\n\n_Sendung *%s* ist Ihnen nicht zugewiesen. Bitte prüfen Sie die Nummer oder fragen Sie im Büro nach._",
+		"ERR_RIDER_PRIVATE_ONLY":     "🛵 _Fahrerbefehle funktionieren nur in Ihrem privaten Chat mit dem Bot._",
+		"MSG_PICKUP_USAGE":           "🚚 Verwendung: `!pickup [Sendungsnummer]`\n\n_Fahrer können einfach `!pickup` senden, um alles Zugewiesene mitzunehmen._",
+		"MSG_CHECKIN_USAGE":          "📍 Verwendung: `!checkin [Sendungsnummer]`, dann deinen Standort teilen.\n\n_Du kannst auch mit deinem Standort auf die Quittung einer Sendung antworten._",
+		"MSG_CHECKIN_OPEN":           "📍 *CHECK-IN GEÖFFNET*\n\n_Teile jetzt deinen Standort. Standorte, die du in den nächsten %d Minuten sendest, werden der Sendung *%s* hinzugefügt._",
+		"MSG_CHECKPOINT_SAVED":       "📍 *CHECKPOINT GESPEICHERT*\n\n_Sendung *%s* wurde zuletzt in %s gesehen._\n%s",
+		"MSG_LIVE_CHECKPOINTS":       "📍 *LIVE-STANDORT VERKNÜPFT*\n\n_Solange du ihn teilst, wird Sendung *%s* alle paar Minuten aktualisiert. Jetzt in %s._",
+		"MSG_CHECKPOINT_NO_SHIPMENT": "📍 _Für welche Sendung ist dieser Standort? Antworte mit deinem Standort auf die Quittung oder sende zuerst `!checkin [Sendungsnummer]`._",
+		"ERR_INVALID_CHECKPOINT":     "📍 _Dein Telefon hat keine Position gesendet. Bitte Standort einschalten und erneut versuchen._",
+		"MSG_POD_USAGE":              "📸 Verwendung: sende ein Foto mit der Bildunterschrift `!pod [Sendungsnummer]`.\n\n_Du kannst auch mit dem Foto auf die Quittung einer Sendung antworten._",
+		"MSG_POD_DELIVERED":          "✅ *ZUGESTELLT*\n\n_Das Foto wurde als Zustellnachweis für *%s* gespeichert, die Sendung ist als zugestellt markiert und der Kunde wurde benachrichtigt._",
+		"MSG_POD_ADDED":              "📸 _Das Foto wurde dem Zustellnachweis für *%s* hinzugefügt._",
+		"ERR_INVALID_POD":            "📸 *Foto Nicht Gespeichert*\n\n_%s_",
+		"ERR_POD_DISABLED":           "📸 _Fotos als Zustellnachweis sind auf diesem Server nicht aktiviert._",
+		"ERR_OTP_REQUIRED":           "🔐 *Liefercode Erforderlich*\n\n_Sendung *%s* kann nur mit dem an den Empfänger gesendeten Code zugestellt werden. Fragen Sie danach und senden Sie `!delivered %s [Code]`._",
+		"ERR_WRONG_OTP":              "❌ *Falscher Liefercode*\n\n_Das ist nicht der Code für *%s*. Der Versuch wurde protokolliert._",
+		"ERR_OTP_LOCKED":             "⛔ *Liefercode Gesperrt*\n\n_Zu viele falsche Codes für *%s*. Das Büro muss dem Empfänger einen neuen senden._",
+		"MSG_BAG_USAGE":              "🧳 *SÄCKE*\n\nVerwendung:\n• `!bag` - Letzte Säcke\n• `!bag [MAWB]` - Inhalt eines Sacks\n• `!bag create [Bezeichnung]` - Neuen Sack öffnen (z. B. der Flug)\n• `!bag add [MAWB] [ID] ...` - Sendungen hineinlegen\n• `!bag remove [MAWB] [ID]` - Eine herausnehmen\n• `!bag close [MAWB]` - Verschließen\n• `!bag depart [MAWB]` - Alles darin geht in Transit\n• `!bag status [MAWB] [Status]` - Alles in einem abgereisten Sack weitersetzen",
+		"MSG_BAG_CREATED":            "🧳 *Sack geöffnet*\n\nMaster-AWB: *%s*%s\n\n_Sendungen hinzufügen mit_ `!bag add [MAWB] [ID] ...`",
+		"MSG_BAG_INFO":               "🧳 *%s*%s\n\nZustand: *%s*\nSendungen: *%d*\n\n%s",
+		"MSG_BAG_LIST":               "🧳 *LETZTE SÄCKE*\n\n%s",
+		"MSG_BAG_LIST_EMPTY":         "🧳 Noch keine Säcke. Öffnen Sie einen mit `!bag create [Bezeichnung]`.",
+		"MSG_BAG_ADDED":              "✅ %d Sendung(en) zu *%s* hinzugefügt (%d im Sack).",
+		"MSG_BAG_REMOVED":            "✅ *%s* aus *%s* entfernt.",
+		"MSG_BAG_CLOSED":             "🔒 *%s* mit %d Sendung(en) verschlossen.\n\n_Senden Sie_ `!bag depart [MAWB]`_, wenn er abgeht._",
+		"MSG_BAG_MOVED":              "✈️ *%s* ist jetzt *%s*\n\n%d Sendung(en) aktualisiert und die Kunden benachrichtigt.",
+		"MSG_BAG_SKIPPED":            "⚠️ *Nicht aktualisiert:*\n%s",
+		"ERR_BAG_NOT_FOUND":          "❌ *NICHT GEFUNDEN*\nKein Sack mit der Master-AWB *%s*.",
+		"ERR_INVALID_BAG":            "⚠️ *Sack nicht geändert*\n\n_%s_",
+		"ERR_UNDO_STALE":             "⚠️ *Rückgängig Nicht Möglich*\n\n_Sendung *%s* wurde nach Ihrer letzten Bearbeitung erneut geändert. Ein Rückgängigmachen würde die neueren Angaben überschreiben._",
+		"ALERT_DELIVERY_OTP":         "🔐 *ZUSTELLCODE*\n\nSendungsnummer: *%s*\nCode: *%s*\n\nIhre Sendung ist in der Zustellung. Geben Sie diesen Code dem Kurier erst, wenn Sie das Paket in den Händen halten; ohne ihn kann die Sendung nicht als zugestellt markiert werden.",
+		"ERR_NOT_IN_BAG":             "⚠️ *%s* ist nicht im Sack *%s*.",
 	},
 }
 
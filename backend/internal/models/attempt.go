@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// DeliveryAttempt is a failed attempt to hand a shipment over to its recipient.
+type DeliveryAttempt struct {
+	AttemptNo     int32      `json:"attempt_no"`
+	Reason        string     `json:"reason"` // no_one_home, wrong_address, refused, business_closed, other
+	Note          string     `json:"note,omitempty"`
+	Source        string     `json:"source"`
+	Actor         string     `json:"actor,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // nil after the last allowed attempt
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// AttemptResult describes a recorded failed attempt and what happens next.
+type AttemptResult struct {
+	Attempt     DeliveryAttempt
+	MaxAttempts int
+	// Final is set when no attempts are left: the shipment is now delivery_failed
+	Final bool
+	// Status is the shipment's new status
+	Status string
+	// OutForDelivery and Delivery are the rescheduled times (zero when Final)
+	OutForDelivery time.Time
+	Delivery       time.Time
+	// For notifications
+	UserJID           string
+	RecipientTimezone string
+}
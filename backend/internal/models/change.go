@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldChange is one entry in a shipment's edit history. Values are nil when
+// the field was empty; timestamps use "2006-01-02 15:04:05" in UTC. Custom
+// fields ("cf.<name>") and "tags" hold their JSON encoding.
+type FieldChange struct {
+	Field    string    `json:"field"`
+	OldValue *string   `json:"old_value"`
+	NewValue *string   `json:"new_value"`
+	Source   string    `json:"source"` // bot, api, csv or system
+	Actor    string    `json:"actor,omitempty"`
+	BatchID  uuid.UUID `json:"batch_id"`
+	// Undo marks entries written by !undo; RevertedAt is set on the entries it reverted.
+	Undo       bool       `json:"undo"`
+	RevertedAt *time.Time `json:"reverted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Checkpoint is a location pinned to a shipment, usually shared by its rider
+// on WhatsApp.
+type Checkpoint struct {
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	AccuracyM int32     `json:"accuracy_m,omitempty"` // 0 when the phone didn't say
+	Label     string    `json:"label"`                // nearest city, from the offline dataset
+	Live      bool      `json:"live"`                 // part of a live location share
+	Source    string    `json:"source"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// CodEntry is one line of a company's cash-on-delivery ledger.
+type CodEntry struct {
+	ID         int32     `json:"id"`
+	TrackingID string    `json:"trackingId,omitempty"`
+	Type       string    `json:"type"` // collection, remittance, adjustment
+	Amount     float64   `json:"amount"`
+	Currency   string    `json:"currency"`
+	Reference  string    `json:"reference,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// CodBalance is the COD position of a company in one currency.
+type CodBalance struct {
+	Currency  string  `json:"currency"`
+	Collected float64 `json:"collected"`
+	Remitted  float64 `json:"remitted"`
+	Adjusted  float64 `json:"adjusted"`
+	// Owed is what the merchant is still owed: collected + adjusted - remitted.
+	Owed float64 `json:"owed"`
+	// Uncollected is COD on delivered shipments not yet marked collected.
+	Uncollected      float64 `json:"uncollected"`
+	UncollectedCount int64   `json:"uncollectedCount"`
+	// Pending is COD on shipments still on their way.
+	Pending      float64 `json:"pending"`
+	PendingCount int64   `json:"pendingCount"`
+}
+
+// CodSummary reconciles the ledger with open COD shipments, one balance per currency.
+type CodSummary struct {
+	Balances []CodBalance `json:"balances"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"webtracker-bot/internal/database/db"
+)
+
+// Consolidation is a master AWB: a bag or container that carries many
+// shipments on one flight.
+type Consolidation struct {
+	ID        int32  `json:"id"`
+	MasterAWB string `json:"master_awb"`
+	Label     string `json:"label,omitempty"`
+	State     string `json:"state"`            // open, closed, departed
+	Status    string `json:"status,omitempty"` // last status applied to its shipments
+	// TrackingIDs is only filled in for a single consolidation
+	TrackingIDs   []string   `json:"tracking_ids,omitempty"`
+	ShipmentCount int        `json:"shipment_count"`
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty"`
+	DepartedAt    *time.Time `json:"departed_at,omitempty"`
+}
+
+// ConsolidationSkip is a shipment a status change on its master AWB could
+// not be applied to, and why.
+type ConsolidationSkip struct {
+	TrackingID string `json:"tracking_id"`
+	Reason     string `json:"reason"`
+}
+
+// ConsolidationResult is what a status change on a master AWB did to the
+// shipments in it.
+type ConsolidationResult struct {
+	Consolidation Consolidation
+	// Moved are the shipments that changed status, as they are now, for the
+	// customer alerts
+	Moved   []db.Shipment
+	Skipped []ConsolidationSkip
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Contact is an address book entry. Contacts are saved automatically from
+// created shipments and deduplicated by the digits of their phone number.
+type Contact struct {
+	ID            int32      `json:"id"`
+	Alias         string     `json:"alias,omitempty"` // referenced as "to: @alias" in a manifest
+	Name          string     `json:"name"`
+	Phone         string     `json:"phone"`
+	Email         string     `json:"email,omitempty"`
+	Address       string     `json:"address,omitempty"`
+	Country       string     `json:"country,omitempty"`
+	IDNumber      string     `json:"id_number,omitempty"`
+	ShipmentCount int32      `json:"shipment_count"`
+	LastUsedAt    *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+// Custom field types
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldDate    = "date" // YYYY-MM-DD
+	CustomFieldBoolean = "boolean"
+)
+
+// CustomField defines a company-specific shipment attribute such as an order
+// number or marketplace. Shipments store the value under Name.
+type CustomField struct {
+	Name     string `json:"name"`            // lowercase key, e.g. order_number
+	Label    string `json:"label,omitempty"` // display name, also accepted by !edit and CSV headers
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+	Pattern  string `json:"pattern,omitempty"` // regex the value must match
+}
+
+// DisplayName is the label, or the name when no label is set.
+func (f CustomField) DisplayName() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return f.Name
+}
@@ -0,0 +1,43 @@
+package models
+
+// CustomsDeclaration is what an international shipment declares to customs:
+// the invoice header plus one line per kind of goods.
+type CustomsDeclaration struct {
+	Currency      string        `json:"currency" validate:"required,len=3"`
+	ExportReason  string        `json:"exportReason"`
+	InvoiceNumber string        `json:"invoiceNumber,omitempty"`
+	Items         []CustomsItem `json:"items" validate:"required,min=1,dive"`
+}
+
+// CustomsItem is a single declared line. Weight is the net kg of the whole line.
+type CustomsItem struct {
+	Description   string  `json:"description" validate:"required"`
+	HSCode        string  `json:"hsCode" validate:"required"`
+	Quantity      int     `json:"quantity" validate:"required,gt=0"`
+	UnitValue     float64 `json:"unitValue" validate:"gt=0"`
+	Weight        float64 `json:"weight" validate:"gte=0"`
+	OriginCountry string  `json:"originCountry" validate:"required"`
+}
+
+// LineValue is quantity × unit value.
+func (i CustomsItem) LineValue() float64 {
+	return float64(i.Quantity) * i.UnitValue
+}
+
+// TotalValue is the declared value of the shipment.
+func (d CustomsDeclaration) TotalValue() float64 {
+	var total float64
+	for _, i := range d.Items {
+		total += i.LineValue()
+	}
+	return total
+}
+
+// TotalWeight is the declared net weight of all lines.
+func (d CustomsDeclaration) TotalWeight() float64 {
+	var total float64
+	for _, i := range d.Items {
+		total += i.Weight
+	}
+	return total
+}
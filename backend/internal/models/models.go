@@ -16,6 +16,7 @@ type WhatsAppSender interface {
 	Reply(chat, sender types.JID, text string, quotedID string, quotedText string)
 	Send(chat types.JID, text string)
 	SendImage(chat, sender types.JID, imageBytes []byte, caption string, quotedID string, quotedText string) error
+	SendDocument(chat, sender types.JID, data []byte, fileName, mimeType, caption string, quotedID string, quotedText string) error
 	SetTyping(chat types.JID, typing bool)
 	GetWAClient() *whatsmeow.Client
 	GetCompanyName() string
@@ -48,10 +49,48 @@ type ShipmentUsecase interface {
 	Create(ctx context.Context, companyID uuid.UUID, params db.CreateShipmentParams) error
 	RecordEvent(ctx context.Context, companyID uuid.UUID, eventType string, metadata []byte) error
 	GetService() ShipmentService
+	ServiceFor(ctx context.Context, companyID uuid.UUID, serviceLevel string) ShipmentService
+	Quote(ctx context.Context, companyID uuid.UUID, req QuoteRequest) (*Quote, error)
+	EstimateCost(ctx context.Context, companyID uuid.UUID, req QuoteRequest) float64
+	RecalculateCost(ctx context.Context, companyID uuid.UUID, trackingID string) (float64, bool, error)
+	SavePieces(ctx context.Context, companyID uuid.UUID, trackingID string, pieces []Piece) error
+	ListPieces(ctx context.Context, companyID uuid.UUID, trackingID string) ([]Piece, error)
+	Customs(ctx context.Context, companyID uuid.UUID, trackingID string) (*CustomsDeclaration, error)
+	CodSummary(ctx context.Context, companyID uuid.UUID) (*CodSummary, error)
+	CollectCOD(ctx context.Context, companyID uuid.UUID, trackingID string, amount float64, reference string) (*CodEntry, error)
 	CountByStatus(ctx context.Context, companyID uuid.UUID) (*db.CountShipmentsByStatusRow, error)
 	GetLastForUser(ctx context.Context, companyID uuid.UUID, jid string) (string, error)
+	ParseTrackingID(ctx context.Context, companyID uuid.UUID, raw string) (string, error)
 	UpdateField(ctx context.Context, companyID uuid.UUID, trackingID, field, value string) error
+	UpdateFieldAt(ctx context.Context, companyID uuid.UUID, trackingID, field, value string, version int32) error
+	UndoLastEdit(ctx context.Context, companyID uuid.UUID, actor string) (string, []FieldChange, error)
+	ExpandContacts(ctx context.Context, companyID uuid.UUID, text string) (string, []string, error)
+	CustomFields(ctx context.Context, companyID uuid.UUID) ([]CustomField, error)
+	UpdateExtras(ctx context.Context, companyID uuid.UUID, trackingID string, fields map[string]any, tags []string, version int32) error
+	CreateReturn(ctx context.Context, companyID uuid.UUID, trackingID, reason, prefix string, version int32) (string, error)
+	FindRider(ctx context.Context, companyID uuid.UUID, ref string) (*Rider, error)
+	RiderByPhone(ctx context.Context, companyID uuid.UUID, phone string) (*Rider, error)
+	AssignRider(ctx context.Context, companyID uuid.UUID, trackingID string, riderID int32) (*Rider, error)
+	UnassignRider(ctx context.Context, companyID uuid.UUID, trackingID string) error
+	RiderPickup(ctx context.Context, companyID uuid.UUID, rider *Rider, trackingID string) ([]db.Shipment, error)
+	RiderDeliver(ctx context.Context, companyID uuid.UUID, rider *Rider, trackingID, code string) (*db.Shipment, error)
+	RiderFailed(ctx context.Context, companyID uuid.UUID, rider *Rider, trackingID, reason, note string) (*AttemptResult, error)
+	Checkin(ctx context.Context, companyID uuid.UUID, rider *Rider, phone, trackingID string) error
+	CheckinTarget(ctx context.Context, companyID uuid.UUID, phone string) (string, error)
+	RecordCheckpoint(ctx context.Context, companyID uuid.UUID, rider *Rider, trackingID string, cp Checkpoint) (*Checkpoint, error)
+	LastCheckpoint(ctx context.Context, companyID uuid.UUID, trackingID string) (*Checkpoint, error)
+	RecordPOD(ctx context.Context, companyID uuid.UUID, rider *Rider, trackingID, kind string, data []byte) (*PODResult, error)
+	ListPODs(ctx context.Context, companyID uuid.UUID, trackingID string) ([]POD, error)
+	CreateConsolidation(ctx context.Context, companyID uuid.UUID, prefix, label string) (*Consolidation, error)
+	Consolidation(ctx context.Context, companyID uuid.UUID, masterAWB string) (*Consolidation, error)
+	ListConsolidations(ctx context.Context, companyID uuid.UUID, limit int32) ([]Consolidation, error)
+	AddToConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB string, trackingIDs []string) (*Consolidation, error)
+	RemoveFromConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB, trackingID string) error
+	CloseConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB string) (*Consolidation, error)
+	DepartConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB string) (*ConsolidationResult, error)
+	SetConsolidationStatus(ctx context.Context, companyID uuid.UUID, masterAWB, status string) (*ConsolidationResult, error)
 	Delete(ctx context.Context, companyID uuid.UUID, trackingID string) error
+	Restore(ctx context.Context, companyID uuid.UUID, trackingID string) error
 	CountCreatedSince(ctx context.Context, companyID uuid.UUID, since time.Time) (int64, error)
 	CreateWithPrefix(ctx context.Context, companyID uuid.UUID, s *db.Shipment, prefix string) (string, error)
 	FindSimilar(ctx context.Context, companyID uuid.UUID, userJid, phone string) (string, error)
@@ -61,7 +100,9 @@ type ShipmentUsecase interface {
 type ShipmentService interface {
 	CalculateDeparture(now time.Time, originTZ string) time.Time
 	CalculateArrival(departure time.Time, senderCountry, receiverCountry string) (time.Time, time.Time)
+	CalculateRedelivery(failedAt time.Time, receiverCountry string) (time.Time, time.Time)
 	ResolveTimezone(country string) string
+	ServiceLevel() string
 }
 
 type ConfigUsecase interface {
@@ -104,8 +145,16 @@ type Manifest struct {
 	SenderCountry   string   `json:"senderCountry"`
 	CargoType       string   `json:"cargoType"`
 	Weight          float64  `json:"weight"`
-	IsAI            bool     `json:"-"`
-	MissingFields   []string `json:"-"`
+	ServiceLevel    string   `json:"serviceLevel"`
+	Pieces          []Piece  `json:"pieces"`
+	CodAmount       float64  `json:"codAmount"`
+	CodCurrency     string   `json:"codCurrency"`
+	// Tags and CustomFields only come from CSV rows; custom field values are
+	// keyed by column header and matched against the company's definitions later.
+	Tags          []string          `json:"tags,omitempty"`
+	CustomFields  map[string]string `json:"customFields,omitempty"`
+	IsAI          bool              `json:"-"`
+	MissingFields []string          `json:"-"`
 }
 
 // Merge combines this manifest with another, only filling in empty fields.
@@ -118,9 +167,17 @@ func (m *Manifest) Merge(other Manifest) {
 	fillIfEmpty(&m.ReceiverID, other.ReceiverID)
 	fillIfEmpty(&m.SenderName, other.SenderName)
 	fillIfEmpty(&m.SenderCountry, other.SenderCountry)
+	fillIfEmpty(&m.ServiceLevel, other.ServiceLevel)
 	if m.Weight == 0 && other.Weight > 0 {
 		m.Weight = other.Weight
 	}
+	if len(m.Pieces) == 0 && len(other.Pieces) > 0 {
+		m.Pieces = other.Pieces
+	}
+	if m.CodAmount == 0 && other.CodAmount > 0 {
+		m.CodAmount = other.CodAmount
+		fillIfEmpty(&m.CodCurrency, other.CodCurrency)
+	}
 }
 
 func fillIfEmpty(target *string, val string) {
@@ -0,0 +1,74 @@
+package models
+
+// Piece is a single box under one airway bill. Weight is in kg, dimensions in cm.
+type Piece struct {
+	Weight      float64 `json:"weight" validate:"gte=0"`
+	LengthCm    float64 `json:"lengthCm,omitempty" validate:"gte=0"`
+	WidthCm     float64 `json:"widthCm,omitempty" validate:"gte=0"`
+	HeightCm    float64 `json:"heightCm,omitempty" validate:"gte=0"`
+	Description string  `json:"description,omitempty"`
+}
+
+// HasDimensions reports whether all three sides were measured.
+func (p Piece) HasDimensions() bool {
+	return p.LengthCm > 0 && p.WidthCm > 0 && p.HeightCm > 0
+}
+
+// VolumetricWeight converts the piece's volume into kilograms (divisor in cm³/kg).
+func (p Piece) VolumetricWeight(divisor float64) float64 {
+	if divisor <= 0 || !p.HasDimensions() {
+		return 0
+	}
+	return p.LengthCm * p.WidthCm * p.HeightCm / divisor
+}
+
+// TotalWeight sums the actual weight of all pieces.
+func TotalWeight(pieces []Piece) float64 {
+	var total float64
+	for _, p := range pieces {
+		total += p.Weight
+	}
+	return total
+}
+
+// TotalVolumetricWeight sums the volumetric weight of all pieces.
+func TotalVolumetricWeight(pieces []Piece, divisor float64) float64 {
+	var total float64
+	for _, p := range pieces {
+		total += p.VolumetricWeight(divisor)
+	}
+	return total
+}
+
+// SplitPieces returns count copies of template sharing totalWeight equally.
+func SplitPieces(count int, totalWeight float64, template Piece) []Piece {
+	if count < 1 {
+		count = 1
+	}
+	pieces := make([]Piece, count)
+	for i := range pieces {
+		pieces[i] = template
+		pieces[i].Weight = totalWeight / float64(count)
+	}
+	return pieces
+}
+
+// FillPieceWeights spreads whatever part of totalWeight the weighed pieces do not
+// account for across the pieces without a weight.
+func FillPieceWeights(pieces []Piece, totalWeight float64) {
+	var unweighed int
+	for _, p := range pieces {
+		if p.Weight <= 0 {
+			unweighed++
+		}
+	}
+	remaining := totalWeight - TotalWeight(pieces)
+	if unweighed == 0 || remaining <= 0 {
+		return
+	}
+	for i := range pieces {
+		if pieces[i].Weight <= 0 {
+			pieces[i].Weight = remaining / float64(unweighed)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"webtracker-bot/internal/database/db"
+)
+
+// POD is a proof-of-delivery image attached to a shipment. The image itself
+// is kept in blob storage.
+type POD struct {
+	ID          int32     `json:"id"`
+	TrackingID  string    `json:"tracking_id"`
+	Kind        string    `json:"kind"` // photo, signature
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	Source      string    `json:"source"`
+	Actor       string    `json:"actor,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PODResult is what RecordPOD saved and what it did to the shipment.
+type PODResult struct {
+	POD POD
+	// Delivered is set when this POD marked the shipment delivered, rather
+	// than being added to one that already was
+	Delivered bool
+	// Shipment as it is now, for the customer alerts
+	Shipment db.Shipment
+}
@@ -0,0 +1,30 @@
+package models
+
+// QuoteRequest describes what to price. Dimensions are optional (cm); when
+// Pieces are given their volumetric weights replace the single L×W×H.
+type QuoteRequest struct {
+	Origin      string  `json:"origin" validate:"required"`
+	Destination string  `json:"destination" validate:"required"`
+	Weight      float64 `json:"weight" validate:"required,gt=0"`
+	LengthCm    float64 `json:"length_cm,omitempty" validate:"gte=0"`
+	WidthCm     float64 `json:"width_cm,omitempty" validate:"gte=0"`
+	HeightCm    float64 `json:"height_cm,omitempty" validate:"gte=0"`
+	CargoType   string  `json:"cargo_type,omitempty"`
+	Pieces      []Piece `json:"pieces,omitempty" validate:"dive"`
+}
+
+// Quote is the itemised price of a shipment.
+type Quote struct {
+	Origin           string  `json:"origin"`
+	Destination      string  `json:"destination"`
+	OriginZone       string  `json:"origin_zone"`
+	DestinationZone  string  `json:"destination_zone"`
+	ActualWeight     float64 `json:"actual_weight"`
+	VolumetricWeight float64 `json:"volumetric_weight"`
+	ChargeableWeight float64 `json:"chargeable_weight"`
+	Base             float64 `json:"base"`
+	CargoAdjustment  float64 `json:"cargo_adjustment"`
+	FuelSurcharge    float64 `json:"fuel_surcharge"`
+	Total            float64 `json:"total"`
+	Currency         string  `json:"currency"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Rider is a dispatch rider who physically carries shipments. The bot
+// recognises a rider by the phone number of their private chat.
+type Rider struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Active    bool      `json:"active"` // inactive riders can't run rider commands
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChatJID is the rider's private WhatsApp chat.
+func (r Rider) ChatJID() types.JID {
+	digits := strings.Map(func(c rune) rune {
+		if c >= '0' && c <= '9' {
+			return c
+		}
+		return -1
+	}, r.Phone)
+	return types.NewJID(digits, types.DefaultUserServer)
+}
@@ -3,8 +3,11 @@ package notif
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/i18n"
 	"webtracker-bot/internal/logger"
 	"webtracker-bot/internal/models"
 	"webtracker-bot/internal/shipment"
@@ -15,7 +18,7 @@ import (
 )
 
 // SendStatusAlert sends a WhatsApp and/or email alert when a shipment transitions.
-func SendStatusAlert(ctx context.Context, wa *whatsmeow.Client, cfg *config.Config, companyName, jidStr, tracking, status, email string) {
+func SendStatusAlert(ctx context.Context, wa *whatsmeow.Client, cfg *config.Config, companyName, jidStr, tracking, status, email, cod string) {
 	if jidStr == "" {
 		return
 	}
@@ -31,6 +34,12 @@ func SendStatusAlert(ctx context.Context, wa *whatsmeow.Client, cfg *config.Conf
 		link = fmt.Sprintf("\n🌐 *Track Here:* %s/track/%s", cfg.FrontendURL, tracking)
 	}
 
+	// COD reminder for the last mile
+	codLine := ""
+	if cod != "" && (status == shipment.StatusOutForDelivery || status == shipment.StatusDelivered) {
+		codLine = fmt.Sprintf("\n\n💵 *Cash on Delivery:* %s\n_Please have the exact amount ready for the courier._", cod)
+	}
+
 	switch status {
 	case shipment.StatusIntransit:
 		msg = fmt.Sprintf("✈️ *SHIPMENT UPDATE*\n\nTracking ID: *%s*\nStatus: *IN TRANSIT*\n\nYour shipment has securely departed the origin facility and is now en route to the destination country.%s", tracking, link)
@@ -43,40 +52,168 @@ func SendStatusAlert(ctx context.Context, wa *whatsmeow.Client, cfg *config.Conf
 			SendDeliveryEmail(cfg, &shipment.Shipment{
 				TrackingID:     tracking,
 				RecipientEmail: email,
-			}, companyName)
+			}, companyName, nil)
 		}
 	default:
 		return
 	}
 
 	// Add Bot Footer
+	msg += codLine + "\n\n_🤖Bot_"
+
+	sendAlert(ctx, wa, jid, msg, status)
+}
+
+// SendPODAlert is SendStatusAlert for a shipment delivered with a proof of
+// delivery: the delivered email carries the image.
+func SendPODAlert(ctx context.Context, wa *whatsmeow.Client, cfg *config.Config, companyName string, ship *db.Shipment, pod *InlineImage) {
+	if wa != nil {
+		// Without the email, which is sent below with the image
+		SendStatusAlert(ctx, wa, cfg, companyName, ship.UserJid, ship.TrackingID, shipment.StatusDelivered, "", shipment.FormatCOD(ship.CodAmount, ship.CodCurrency.String))
+	}
+	if ship.RecipientEmail.String != "" && cfg != nil {
+		SendDeliveryEmail(cfg, &shipment.Shipment{
+			TrackingID:     ship.TrackingID,
+			RecipientName:  ship.RecipientName.String,
+			RecipientEmail: ship.RecipientEmail.String,
+		}, companyName, pod)
+	}
+}
+
+// SendDeliveryOTP sends a shipment's delivery code to the recipient's
+// WhatsApp, in lang, and email, whichever are on file.
+func SendDeliveryOTP(ctx context.Context, wa *whatsmeow.Client, cfg *config.Config, companyName, lang string, ship *db.Shipment, code string) {
+	phone := strings.Map(func(c rune) rune {
+		if c >= '0' && c <= '9' {
+			return c
+		}
+		return -1
+	}, ship.RecipientPhone.String)
+	if wa != nil && phone != "" {
+		msg := i18n.T(i18n.Language(strings.ToLower(lang)), "ALERT_DELIVERY_OTP", ship.TrackingID, code) + "\n\n_🤖Bot_"
+		sendAlert(ctx, wa, types.NewJID(phone, types.DefaultUserServer), msg, shipment.StatusOutForDelivery)
+	}
+	if ship.RecipientEmail.String != "" && cfg != nil {
+		SendDeliveryOTPEmail(cfg, ship.RecipientEmail.String, ship.RecipientName.String, ship.TrackingID, companyName, code)
+	}
+}
+
+// SendReturnAlert tells the original sender that a shipment is coming back
+// to them, and under which tracking ID.
+func SendReturnAlert(ctx context.Context, wa *whatsmeow.Client, cfg *config.Config, jidStr, tracking, returnID, reason string) {
+	if jidStr == "" {
+		return
+	}
+	jid, err := types.ParseJID(jidStr)
+	if err != nil {
+		logger.Warn().Str("jid", jidStr).Msg("Failed to parse JID for return alert")
+		return
+	}
+
+	link := ""
+	if cfg != nil && cfg.FrontendURL != "" {
+		link = fmt.Sprintf("\n🌐 *Track Here:* %s/track/%s", cfg.FrontendURL, returnID)
+	}
+	msg := fmt.Sprintf("↩️ *RETURN TO SENDER*\n\nTracking ID: *%s*\nStatus: *RETURNED*\nReason: _%s_\n\nThe shipment could not be delivered and is on its way back to the sender under the new tracking ID *%s*.%s\n\n_🤖Bot_", tracking, reason, returnID, link)
+
+	sendAlert(ctx, wa, jid, msg, shipment.StatusReturned)
+}
+
+// SendAttemptAlert tells the customer, in their language, that a delivery
+// attempt failed and when the next one is, or that it was the last.
+func SendAttemptAlert(ctx context.Context, wa *whatsmeow.Client, cfg *config.Config, lang, tracking string, res *models.AttemptResult) {
+	if res.UserJID == "" {
+		return
+	}
+	jid, err := types.ParseJID(res.UserJID)
+	if err != nil {
+		logger.Warn().Str("jid", res.UserJID).Msg("Failed to parse JID for attempt alert")
+		return
+	}
+
+	l := i18n.Language(strings.ToLower(lang))
+	reason := i18n.T(l, "attempt_"+res.Attempt.Reason)
+	var msg string
+	if res.Final {
+		msg = i18n.T(l, "ALERT_ATTEMPT_FINAL", tracking, res.Attempt.AttemptNo, res.MaxAttempts, reason)
+	} else {
+		loc, err := time.LoadLocation(res.RecipientTimezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		msg = i18n.T(l, "ALERT_ATTEMPT_FAILED", tracking, res.Attempt.AttemptNo, res.MaxAttempts, reason, res.Delivery.In(loc).Format(i18n.GetDateFormat(l)))
+	}
+	if cfg != nil && cfg.FrontendURL != "" {
+		msg += fmt.Sprintf("\n🌐 *Track Here:* %s/track/%s", cfg.FrontendURL, tracking)
+	}
 	msg += "\n\n_🤖Bot_"
 
+	sendAlert(ctx, wa, jid, msg, res.Status)
+}
+
+// sendAlert delivers msg to every device of jid.
+func sendAlert(ctx context.Context, wa *whatsmeow.Client, jid types.JID, msg, status string) {
 	content := &waProto.Message{
 		Conversation: models.StrPtr(msg),
 	}
 
 	if wa.Store.ID == nil {
-		logger.Warn().Str("chat", jidStr).Msg("Skipping status alert: Bot session not initialized (Store.ID is nil)")
+		logger.Warn().Str("chat", jid.String()).Msg("Skipping status alert: Bot session not initialized (Store.ID is nil)")
 		return
 	}
 
 	// Ensure we send to the bare JID (all devices)
 	bareJid := types.JID{User: jid.User, Server: jid.Server}
 
-	_, err = wa.SendMessage(ctx, bareJid, content)
+	_, err := wa.SendMessage(ctx, bareJid, content)
 	if err != nil {
-		logger.Error().Err(err).Str("chat", jidStr).Msg("Failed to send status alert")
+		logger.Error().Err(err).Str("chat", jid.String()).Msg("Failed to send status alert")
 	} else {
-		logger.Info().Str("chat", jidStr).Str("status", status).Msg("Status alert sent")
+		logger.Info().Str("chat", jid.String()).Str("status", status).Msg("Status alert sent")
 	}
 }
 
 // SendStatusAlertAsync dispatches a status alert in the background with a 15s timeout.
-func SendStatusAlertAsync(wa *whatsmeow.Client, cfg *config.Config, companyName, jidStr, tracking, status, email string) {
+func SendStatusAlertAsync(wa *whatsmeow.Client, cfg *config.Config, companyName, jidStr, tracking, status, email, cod string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		SendStatusAlert(ctx, wa, cfg, companyName, jidStr, tracking, status, email, cod)
+	}()
+}
+
+// SendPODAlertAsync dispatches a proof-of-delivery alert in the background with a 15s timeout.
+func SendPODAlertAsync(wa *whatsmeow.Client, cfg *config.Config, companyName string, ship *db.Shipment, pod *InlineImage) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		SendPODAlert(ctx, wa, cfg, companyName, ship, pod)
+	}()
+}
+
+// SendDeliveryOTPAsync dispatches a delivery code in the background with a 15s timeout.
+func SendDeliveryOTPAsync(wa *whatsmeow.Client, cfg *config.Config, companyName, lang string, ship *db.Shipment, code string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		SendDeliveryOTP(ctx, wa, cfg, companyName, lang, ship, code)
+	}()
+}
+
+// SendReturnAlertAsync dispatches a return alert in the background with a 15s timeout.
+func SendReturnAlertAsync(wa *whatsmeow.Client, cfg *config.Config, jidStr, tracking, returnID, reason string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		SendReturnAlert(ctx, wa, cfg, jidStr, tracking, returnID, reason)
+	}()
+}
+
+// SendAttemptAlertAsync dispatches an attempt alert in the background with a 15s timeout.
+func SendAttemptAlertAsync(wa *whatsmeow.Client, cfg *config.Config, lang, tracking string, res *models.AttemptResult) {
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		defer cancel()
-		SendStatusAlert(ctx, wa, cfg, companyName, jidStr, tracking, status, email)
+		SendAttemptAlert(ctx, wa, cfg, lang, tracking, res)
 	}()
 }
@@ -1,9 +1,13 @@
 package notif
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
+	"mime/multipart"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 
 	"webtracker-bot/internal/config"
@@ -27,6 +31,15 @@ type Email struct {
 	Subject  string
 	HTMLBody string
 	FromName string // optional override; defaults to "WebTracker"
+	// Inline images, referenced from HTMLBody as <img src="cid:...">
+	Inline []InlineImage
+}
+
+// InlineImage is an image embedded in an email body.
+type InlineImage struct {
+	CID         string
+	ContentType string
+	Data        []byte
 }
 
 // Send dispatches an email. Safe to call from a goroutine.
@@ -50,9 +63,7 @@ func (m *Mailer) Send(e Email) {
 		"To: " + e.To + "\r\n" +
 		"Subject: " + e.Subject + "\r\n" +
 		"MIME-Version: 1.0\r\n" +
-		"Content-Type: text/html; charset=\"UTF-8\"\r\n" +
-		"\r\n" +
-		e.HTMLBody
+		body(e)
 
 	addr := fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort)
 
@@ -80,6 +91,36 @@ func (m *Mailer) SendAsync(e Email) {
 	go m.Send(e)
 }
 
+// body renders the Content-Type header and body: plain HTML, or
+// multipart/related when the email carries inline images.
+func body(e Email) string {
+	if len(e.Inline) == 0 {
+		return "Content-Type: text/html; charset=\"UTF-8\"\r\n\r\n" + e.HTMLBody
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	html, _ := w.CreatePart(textproto.MIMEHeader{"Content-Type": {`text/html; charset="UTF-8"`}})
+	html.Write([]byte(e.HTMLBody))
+	for _, img := range e.Inline {
+		part, _ := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {img.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + img.CID + ">"},
+			"Content-Disposition":       {"inline"},
+		})
+		enc := base64.StdEncoding.EncodeToString(img.Data)
+		for len(enc) > 76 {
+			part.Write([]byte(enc[:76] + "\r\n"))
+			enc = enc[76:]
+		}
+		part.Write([]byte(enc + "\r\n"))
+	}
+	w.Close()
+
+	return "Content-Type: multipart/related; boundary=" + w.Boundary() + "\r\n\r\n" + buf.String()
+}
+
 // ---------------------------------------------------------------------------
 // SMTP/S transport (shared, private)
 // ---------------------------------------------------------------------------
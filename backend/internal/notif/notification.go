@@ -26,8 +26,9 @@ func SendSetupLinkEmail(cfg *config.Config, adminEmail, companyName, setupToken
 	m.SendAsync(e)
 }
 
-// SendDeliveryEmail sends a professional email when a shipment is delivered
-func SendDeliveryEmail(cfg *config.Config, s *shipment.Shipment, companyName string) {
+// SendDeliveryEmail sends a professional email when a shipment is delivered,
+// with the proof-of-delivery image when there is one
+func SendDeliveryEmail(cfg *config.Config, s *shipment.Shipment, companyName string, pod *InlineImage) {
 	m := NewMailer(cfg)
 	companyName = strings.ToUpper(companyName)
 	if companyName == "" {
@@ -39,10 +40,21 @@ func SendDeliveryEmail(cfg *config.Config, s *shipment.Shipment, companyName str
 		s.TrackingID,
 		companyName,
 		time.Now().Format("January 02, 2006"),
+		pod,
 	)
 	m.SendAsync(e)
 }
 
+// SendDeliveryOTPEmail sends a recipient the code that confirms delivery
+func SendDeliveryOTPEmail(cfg *config.Config, to, recipientName, trackingID, companyName, code string) {
+	m := NewMailer(cfg)
+	companyName = strings.ToUpper(companyName)
+	if companyName == "" {
+		companyName = "AIRWAYBILL"
+	}
+	m.SendAsync(DeliveryOTPEmail(to, recipientName, trackingID, companyName, code))
+}
+
 // SendOTPEmail sends a 6-digit verification code email
 func SendOTPEmail(cfg *config.Config, email, otp string) {
 	m := NewMailer(cfg)
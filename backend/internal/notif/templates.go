@@ -85,8 +85,9 @@ func SetupLinkEmail(to, companyName, frontendURL, token string) Email {
 
 
 
-// DeliveryEmail builds the shipment-arrival notification email.
-func DeliveryEmail(to, recipientName, trackingID, companyName, arrivalDate string) Email {
+// DeliveryEmail builds the shipment-arrival notification email. pod, when
+// set, is embedded as the proof of delivery.
+func DeliveryEmail(to, recipientName, trackingID, companyName, arrivalDate string, pod *InlineImage) Email {
 	if companyName == "" {
 		companyName = "AIRWAYBILL"
 	}
@@ -94,6 +95,18 @@ func DeliveryEmail(to, recipientName, trackingID, companyName, arrivalDate strin
 		recipientName = "Customer"
 	}
 
+	podBlock := ""
+	var inline []InlineImage
+	if pod != nil {
+		pod.CID = "pod-" + trackingID
+		inline = []InlineImage{*pod}
+		podBlock = fmt.Sprintf(`
+        <div style="margin: 25px 0; text-align: center;">
+            <p style="margin: 0 0 10px 0; font-size: 14px; text-transform: uppercase; letter-spacing: 1px; color: #666;">Proof of Delivery</p>
+            <img src="cid:%s" alt="Proof of delivery" style="max-width: 100%%; border-radius: 8px; border: 1px solid #eee;">
+        </div>`, pod.CID)
+	}
+
 	html := fmt.Sprintf(`<!DOCTYPE html>
 <html>
 <body style="font-family: Arial, sans-serif; background-color: #f4f4f4; margin: 0; padding: 20px;">
@@ -107,7 +120,7 @@ func DeliveryEmail(to, recipientName, trackingID, companyName, arrivalDate strin
             <p style="margin: 0; color: #333;"><strong>Tracking ID:</strong> %s</p>
             <p style="margin: 5px 0 0 0; color: #333;"><strong>Status:</strong> ARRIVED AT DESTINATION</p>
             <p style="margin: 5px 0 0 0; color: #333;"><strong>Arrival Date:</strong> %s</p>
-        </div>
+        </div>%s
         <p style="color: #555; line-height: 1.6;">Thank you for choosing %s. We appreciate your patience during this final transit phase.</p>
         <p style="margin-top: 30px; border-top: 1px solid #eee; padding-top: 20px; color: #888; font-size: 12px;">
             This is an automated message. Please await further contact from our local agent.
@@ -115,13 +128,51 @@ func DeliveryEmail(to, recipientName, trackingID, companyName, arrivalDate strin
         </p>
     </div>
 </body>
-</html>`, companyName, recipientName, trackingID, trackingID, arrivalDate, companyName, companyName)
+</html>`, companyName, recipientName, trackingID, trackingID, arrivalDate, podBlock, companyName, companyName)
 
 	return Email{
 		To:       to,
 		Subject:  fmt.Sprintf("[%s] Package Arrival Notification - %s", companyName, trackingID),
 		HTMLBody: html,
 		FromName: companyName,
+		Inline:   inline,
+	}
+}
+
+// DeliveryOTPEmail builds the email carrying a shipment's delivery code.
+func DeliveryOTPEmail(to, recipientName, trackingID, companyName, code string) Email {
+	if companyName == "" {
+		companyName = "AIRWAYBILL"
+	}
+	if recipientName == "" {
+		recipientName = "Customer"
+	}
+	html := fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; background-color: #f4f4f4; margin: 0; padding: 20px;">
+    <div style="max-width: 600px; margin: auto; background: white; padding: 40px; border-radius: 12px; box-shadow: 0 4px 6px rgba(0,0,0,0.1);">
+        <h1 style="color: #2563eb; font-size: 24px; margin-bottom: 10px;">%s</h1>
+        <h2 style="color: #333; font-size: 18px; margin-bottom: 25px;">Your Delivery Code</h2>
+        <p style="color: #555; line-height: 1.6;">Hello <strong>%s</strong>,</p>
+        <p style="color: #555; line-height: 1.6;">Your package (Tracking ID: <strong>%s</strong>) is out for delivery. The courier will ask for this code before handing it over.</p>
+        <div style="text-align: center; margin: 35px 0; padding: 25px; background: #f8f9fa; border-radius: 8px;">
+            <p style="margin: 0 0 10px 0; font-size: 14px; text-transform: uppercase; letter-spacing: 1px; color: #666;">Delivery Code</p>
+            <div style="font-size: 42px; font-weight: bold; letter-spacing: 8px; color: #000; font-family: 'Courier New', Courier, monospace;">%s</div>
+        </div>
+        <p style="color: #555; line-height: 1.6;">Only share it once the package is in your hands.</p>
+        <p style="margin-top: 30px; border-top: 1px solid #eee; padding-top: 20px; color: #888; font-size: 12px;">
+            This is an automated message.
+            <br>&copy; %d %s. All rights reserved.
+        </p>
+    </div>
+</body>
+</html>`, companyName, recipientName, trackingID, code, time.Now().Year(), companyName)
+
+	return Email{
+		To:       to,
+		Subject:  fmt.Sprintf("[%s] Delivery Code - %s", companyName, trackingID),
+		HTMLBody: html,
+		FromName: companyName,
 	}
 }
 
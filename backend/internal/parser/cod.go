@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	codNumberRe     = regexp.MustCompile(`\d[\d.,' ]*\d|\d`)
+	codCodeBeforeRe = regexp.MustCompile(`(?i)\b([a-z]{3})\s*$`)
+	codCodeAfterRe  = regexp.MustCompile(`(?i)^\s*([a-z]{3})\b`)
+)
+
+// currencySymbols is ordered so that "R$" wins over "$".
+var currencySymbols = []struct{ symbol, code string }{
+	{"R$", "BRL"}, {"₦", "NGN"}, {"₵", "GHS"}, {"$", "USD"}, {"€", "EUR"}, {"£", "GBP"}, {"¥", "JPY"},
+}
+
+// ParseCOD reads a cash-on-delivery amount such as "NGN 15,000", "15.000,50 EUR"
+// or "₦15000". The currency is "" when the text does not name one next to the amount.
+func ParseCOD(s string) (float64, string) {
+	loc := codNumberRe.FindStringIndex(s)
+	if loc == nil {
+		return 0, ""
+	}
+	amount := parseAmount(s[loc[0]:loc[1]])
+	before, after := strings.TrimSpace(s[:loc[0]]), strings.TrimSpace(s[loc[1]:])
+
+	for _, m := range [][]string{codCodeBeforeRe.FindStringSubmatch(before), codCodeAfterRe.FindStringSubmatch(after)} {
+		if m != nil && !strings.EqualFold(m[1], "cod") {
+			return amount, strings.ToUpper(m[1])
+		}
+	}
+	for _, c := range currencySymbols {
+		if strings.HasSuffix(before, c.symbol) || strings.HasPrefix(after, c.symbol) {
+			return amount, c.code
+		}
+	}
+	return amount, ""
+}
+
+// parseAmount accepts both 1,234.56 and 1.234,56. A lone separator followed
+// by exactly three digits is read as a thousands separator.
+func parseAmount(num string) float64 {
+	num = strings.NewReplacer(" ", "", "'", "").Replace(num)
+	lastDot, lastComma := strings.LastIndex(num, "."), strings.LastIndex(num, ",")
+	decimal := -1
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		decimal = max(lastDot, lastComma)
+	case lastDot >= 0 || lastComma >= 0:
+		sep := max(lastDot, lastComma)
+		sepChar := num[sep : sep+1]
+		if strings.Count(num, sepChar) == 1 && len(num)-sep-1 != 3 {
+			decimal = sep
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range num {
+		switch {
+		case i == decimal:
+			b.WriteByte('.')
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	v, _ := strconv.ParseFloat(b.String(), 64)
+	return v
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"webtracker-bot/internal/models"
+)
+
+// contactRefRe matches a manifest line that refers to a saved contact instead
+// of spelling it out, e.g. "to: @mama" or "*From* @lagos-office".
+var contactRefRe = regexp.MustCompile(`(?im)^[ \t*_]*(to|receiver|recipient|consignee|from|sender|shipper)[ \t*_]*(?:[:\-=>]+[ \t*_]*)?@([a-z0-9][a-z0-9_.\-]*)[ \t*_]*$`)
+
+func isSenderRole(role string) bool {
+	switch strings.ToLower(role) {
+	case "from", "sender", "shipper":
+		return true
+	}
+	return false
+}
+
+// ContactAliases returns the lowercase aliases referenced in text, in order
+// and without duplicates.
+func ContactAliases(text string) []string {
+	var aliases []string
+	seen := make(map[string]bool)
+	for _, m := range contactRefRe.FindAllStringSubmatch(text, -1) {
+		alias := strings.ToLower(m[2])
+		if !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// ExpandContactRefs replaces every contact reference line with the labelled
+// fields of the stored contact, so the result parses like a manifest typed
+// out in full. References to aliases missing from contacts are left as they
+// are and returned.
+func ExpandContactRefs(text string, contacts map[string]models.Contact) (string, []string) {
+	var unknown []string
+	expanded := contactRefRe.ReplaceAllStringFunc(text, func(line string) string {
+		m := contactRefRe.FindStringSubmatch(line)
+		alias := strings.ToLower(m[2])
+		c, ok := contacts[alias]
+		if !ok {
+			unknown = append(unknown, alias)
+			return line
+		}
+		if isSenderRole(m[1]) {
+			return senderLines(c)
+		}
+		return receiverLines(c)
+	})
+	return expanded, unknown
+}
+
+func receiverLines(c models.Contact) string {
+	lines := []string{"Receiver Name: " + c.Name, "Receiver Phone: " + c.Phone}
+	if c.Address != "" {
+		lines = append(lines, "Receiver Address: "+c.Address)
+	}
+	if c.Country != "" {
+		lines = append(lines, "Destination: "+c.Country)
+	}
+	if c.Email != "" {
+		lines = append(lines, "Receiver Email: "+c.Email)
+	}
+	if c.IDNumber != "" {
+		lines = append(lines, "Receiver ID: "+c.IDNumber)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// senderLines leaves the phone out: in a WhatsApp manifest the sender phone is
+// always the number the message came from.
+func senderLines(c models.Contact) string {
+	lines := []string{"Sender Name: " + c.Name}
+	if c.Country != "" {
+		lines = append(lines, "Origin: "+c.Country)
+	}
+	return strings.Join(lines, "\n")
+}
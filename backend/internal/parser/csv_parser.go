@@ -11,6 +11,12 @@ import (
 // ParseCSV extracts multiple shipments from a standard CSV payload.
 // Expected Headers (case-insensitive, roughly):
 // SenderName, SenderPhone, Origin, ReceiverName, ReceiverPhone, Destination, CargoType, Weight
+// An optional Service column selects the scheduling service level (e.g. express).
+// Optional Pieces and Dimensions (or Length/Width/Height, cm) columns describe
+// identical boxes sharing the row's weight.
+// A Tags column holds labels separated by ';' or '|'. Any other column is
+// kept as a possible custom field; prefix the header with "cf:" when a custom
+// field's name would otherwise be read as one of the columns above.
 func ParseCSV(payload string) ([]models.Manifest, error) {
 	reader := csv.NewReader(strings.NewReader(payload))
 	reader.TrimLeadingSpace = true
@@ -40,6 +46,8 @@ func ParseCSV(payload string) ([]models.Manifest, error) {
 		}
 
 		m := models.Manifest{}
+		pieceCount := 0
+		var piece models.Piece
 		for i, val := range record {
 			if i >= len(headers) {
 				continue
@@ -47,7 +55,11 @@ func ParseCSV(payload string) ([]models.Manifest, error) {
 			col := headers[i]
 			val = strings.TrimSpace(val)
 
-			if strings.Contains(col, "sender") && strings.Contains(col, "name") {
+			if name, ok := strings.CutPrefix(col, "cf:"); ok {
+				setCustomField(&m, strings.TrimSpace(name), val)
+			} else if col == "tags" || col == "tag" {
+				m.Tags = strings.FieldsFunc(val, func(r rune) bool { return r == ';' || r == '|' })
+			} else if strings.Contains(col, "sender") && strings.Contains(col, "name") {
 				m.SenderName = val
 			} else if strings.Contains(col, "receiver") || strings.Contains(col, "recipient") {
 				if strings.Contains(col, "name") {
@@ -63,10 +75,34 @@ func ParseCSV(payload string) ([]models.Manifest, error) {
 				m.SenderCountry = val
 			} else if strings.Contains(col, "dest") || (strings.Contains(col, "receiver") && strings.Contains(col, "country")) {
 				m.ReceiverCountry = val
+			} else if strings.Contains(col, "piece") || strings.Contains(col, "pcs") || strings.Contains(col, "qty") || strings.Contains(col, "quantity") {
+				fmt.Sscanf(val, "%d", &pieceCount)
+			} else if strings.Contains(col, "dimension") || strings.Contains(col, "dims") || col == "size" {
+				piece, _ = ParseDimensions(val)
+			} else if strings.Contains(col, "length") {
+				fmt.Sscanf(val, "%f", &piece.LengthCm)
+			} else if strings.Contains(col, "width") {
+				fmt.Sscanf(val, "%f", &piece.WidthCm)
+			} else if strings.Contains(col, "height") {
+				fmt.Sscanf(val, "%f", &piece.HeightCm)
+			} else if col == "cod" || strings.HasPrefix(col, "cod_") || strings.HasPrefix(col, "cod ") || strings.Contains(col, "cash on delivery") {
+				if strings.Contains(col, "currency") {
+					m.CodCurrency = strings.ToUpper(val)
+				} else {
+					amount, currency := ParseCOD(val)
+					m.CodAmount = amount
+					if m.CodCurrency == "" {
+						m.CodCurrency = currency
+					}
+				}
+			} else if strings.Contains(col, "service") {
+				m.ServiceLevel = strings.ToLower(val)
 			} else if strings.Contains(col, "cargo") || strings.Contains(col, "type") || strings.Contains(col, "item") {
 				m.CargoType = val
 			} else if strings.Contains(col, "weight") {
 				fmt.Sscanf(val, "%f", &m.Weight)
+			} else {
+				setCustomField(&m, col, val)
 			}
 		}
 
@@ -83,6 +119,12 @@ func ParseCSV(payload string) ([]models.Manifest, error) {
 		if m.Weight == 0 {
 			m.Weight = 15.0
 		}
+		if pieceCount > maxPieces {
+			pieceCount = maxPieces
+		}
+		if pieceCount > 1 || piece.HasDimensions() {
+			m.Pieces = models.SplitPieces(pieceCount, m.Weight, piece)
+		}
 
 		m.Validate()
 		manifests = append(manifests, m)
@@ -90,3 +132,13 @@ func ParseCSV(payload string) ([]models.Manifest, error) {
 
 	return manifests, nil
 }
+
+func setCustomField(m *models.Manifest, column, value string) {
+	if value == "" {
+		return
+	}
+	if m.CustomFields == nil {
+		m.CustomFields = make(map[string]string)
+	}
+	m.CustomFields[column] = value
+}
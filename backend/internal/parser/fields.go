@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// nextPairRe finds where a value ends: at the next "label:" pair.
+var nextPairRe = regexp.MustCompile(`[,;\n][ \t]*[\p{L}][\p{L}\d _.\-]{0,40}[ \t]*:`)
+
+// ExtractLabeled pulls "label: value" pairs for the given labels out of an
+// edit message (case-insensitive) and returns them keyed by the lowercased
+// label, along with the rest of the text. A value runs until the next
+// "label:" pair, so it may contain commas ("tags: vip, fragile, name: Jo").
+func ExtractLabeled(text string, labels []string) (map[string]string, string) {
+	found := make(map[string]string)
+	if len(labels) == 0 {
+		return found, text
+	}
+
+	quoted := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if l = strings.TrimSpace(l); l != "" {
+			quoted = append(quoted, regexp.QuoteMeta(l))
+		}
+	}
+	// Longest first, so "order no" wins over "order"
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	re := regexp.MustCompile(`(?i)(?:^|[,;\n])[ \t]*(` + strings.Join(quoted, "|") + `)[ \t]*[:=]`)
+
+	var rest strings.Builder
+	pos := 0
+	for pos < len(text) {
+		loc := re.FindStringSubmatchIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start, valueStart := pos+loc[0], pos+loc[1]
+		label := strings.ToLower(text[pos+loc[2] : pos+loc[3]])
+
+		end := len(text)
+		if next := nextPairRe.FindStringIndex(text[valueStart:]); next != nil {
+			end = valueStart + next[0]
+		}
+		found[label] = strings.TrimSpace(text[valueStart:end])
+
+		rest.WriteString(text[pos:start])
+		pos = end
+	}
+	rest.WriteString(text[pos:])
+	return found, strings.TrimSpace(strings.Trim(strings.TrimSpace(rest.String()), ",;"))
+}
@@ -70,8 +70,13 @@ func GetLabelMappings() []uncompiledLabelMap{
 		{"SenderCountry", `(?i)\b(?:origin|sender` + possessive + `\s*country|sender` + possessive + `\s*nation|source` + possessive + `\s*country|from` + possessive + `\s*country)\b[\s\-:]*`, 2},
 		{"CargoType", `(?i)\b(?:item|content|cargo|description|type|package|commodity|conteúdo|contenido|inhalt|ware|consignment)\b(?:\s+weight)?[\s\-:]*`, 1},
 		{"Weight", `(?i)\b(?:weight|wgt|mass|gross\s*weight|peso|gewicht|poids)\b[\s\-:]*`, 2},
+		{"Pieces", `(?im)^(?:piece|pc|box|carton|ctn|parcel)[ \t]*#?[ \t]*\d{1,3}\b[\s\-:]*`, 2},
+		{"Pieces", `(?i)\b(?:pieces|pcs|no\.?\s*of\s*(?:pieces|boxes|packages|cartons)|dimensions|dims)\b[\s\-:]*`, 2},
+		{"ServiceLevel", `(?i)\b(?:service(?:\s*(?:level|type))?|servi[cç]o|servicio|versandart)\b[\s\-:]*`, 2},
+		{"COD", `(?i)\b(?:cod|c\.o\.d\.?|cash\s*on\s*delivery|pay(?:ment)?\s*on\s*delivery|amount\s*to\s*collect)\b[\s\-:]*`, 2},
 		{"scheduled_transit_time", `(?i)\b(?:departure|transit\s*time|depart|sent\s*date|start\s*date|transit|partida|salida|abfahrt)\b[\s\-:]*`, 2},
 		{"expected_delivery_time", `(?i)\b(?:arrival|delivery\s*time|arrive|expect|delivery\s*date|delivered\s*on|delivery|chegada|entrega|ankunft|zustellung)\b[\s\-:]*`, 2},
+		{"Status", `(?i)\bstatus\b[\s\-:]*`, 1},
 	}
 }
 
@@ -147,6 +152,21 @@ func ParseRegex(text string) models.Manifest {
 		}
 	}
 
+	// First word only, so "Express (2 days)" still names the service
+	if fields := strings.Fields(results["ServiceLevel"]); len(fields) > 0 {
+		m.ServiceLevel = strings.ToLower(strings.Trim(fields[0], ".,;()"))
+	}
+
+	if codStr, ok := results["COD"]; ok {
+		m.CodAmount, m.CodCurrency = ParseCOD(codStr)
+	}
+
+	// Itemised boxes; their weights stand in for a missing total
+	m.Pieces = ParsePieces(text)
+	if m.Weight == 0 {
+		m.Weight = models.TotalWeight(m.Pieces)
+	}
+
 	receiverZone := text
 	if senderStartIdx != -1 {
 		receiverZone = text[:senderStartIdx]
@@ -214,6 +234,8 @@ func ParseRegex(text string) models.Manifest {
 		}
 	}
 
+	models.FillPieceWeights(m.Pieces, m.Weight)
+
 	m.Validate()
 	return m
 }
@@ -237,6 +259,7 @@ func ParseEditPairs(text string) map[string]string {
 		"Weight":                 "weight",
 		"scheduled_transit_time": "scheduled_transit_time",
 		"expected_delivery_time": "expected_delivery_time",
+		"Status":                 "status",
 	}
 
 	final := make(map[string]string)
@@ -385,7 +408,11 @@ func ParseAI(ctx context.Context, text, apiKey string) (models.Manifest, error)
             "receiverID": string,
             "senderName": string,
             "senderCountry": string,
-            "weight": number
+            "weight": number,
+            "pieces": [{"weight": number, "lengthCm": number, "widthCm": number, "heightCm": number, "description": string}],
+            "codAmount": number,
+            "codCurrency": string,
+            "serviceLevel": string
         }
 
         RULES:
@@ -393,6 +420,9 @@ func ParseAI(ctx context.Context, text, apiKey string) (models.Manifest, error)
         2. If a field is missing, use an empty string "" - DO NOT return null.
         3. Infer countries if city names are well-known (e.g. "Paris" -> "France").
         4. Phone numbers: Extract as is.
+        5. Pieces: one entry per box when the text lists several boxes or gives dimensions (in cm), otherwise [].
+        6. codAmount: the cash to collect on delivery (COD), 0 if not mentioned; codCurrency as an ISO code (e.g. "NGN") or "".
+        7. serviceLevel: the service asked for in lowercase (e.g. "express"), "" if not mentioned.
         
         Extract from this:
         ` + text
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"webtracker-bot/internal/models"
+)
+
+var (
+	// "Box 1: 12kg 40x30x20 shoes", "Piece #2 - 5.5 kg, 30x30x30cm"
+	pieceLineRe = regexp.MustCompile(`(?im)^[ \t]*(?:piece|pc|box|carton|ctn|parcel)[ \t]*#?[ \t]*(\d{1,3})\b(?:[ \t]*of[ \t]*\d{1,3})?[ \t]*[:\-=.)]?[ \t]*(.*)$`)
+	// "Pieces: 3", "No. of boxes - 3", "3 cartons"
+	pieceCountRe  = regexp.MustCompile(`(?i)\b(?:pieces|pcs|no\.?\s*of\s*(?:pieces|boxes|packages|cartons))\b[\s\-:=]*(\d{1,3})\b|\b(\d{1,3})\s*(?:pieces|pcs|boxes|cartons|parcels|packages)\b`)
+	dimensionsRe  = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(?:cm)?\s*[x×*]\s*(\d+(?:[.,]\d+)?)\s*(?:cm)?\s*[x×*]\s*(\d+(?:[.,]\d+)?)\s*(cm|m|in)?\b`)
+	pieceWeightRe = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(?:kg|kgs|kilos|kg's)\b`)
+)
+
+// maxPieces caps the piece count read from free text (guards against typos like "300 boxes").
+const maxPieces = 100
+
+// ParsePieces extracts the boxes of a manifest, either itemised one per line
+// ("Box 1: 12kg 40x30x20 shoes") or as a count with shared dimensions
+// ("Pieces: 3" + "Dimensions: 40x30x20 cm"). Weights that are not stated are
+// left at zero for the caller to distribute.
+func ParsePieces(text string) []models.Piece {
+	var pieces []models.Piece
+	for _, match := range pieceLineRe.FindAllStringSubmatch(text, maxPieces) {
+		pieces = append(pieces, parsePieceLine(match[2]))
+	}
+	if len(pieces) > 0 {
+		return pieces
+	}
+
+	count := 0
+	if match := pieceCountRe.FindStringSubmatch(text); match != nil {
+		n := match[1]
+		if n == "" {
+			n = match[2]
+		}
+		count, _ = strconv.Atoi(n)
+	}
+	template, _ := ParseDimensions(text)
+	if count > maxPieces {
+		count = maxPieces
+	}
+	if count <= 1 && !template.HasDimensions() {
+		return nil
+	}
+	return models.SplitPieces(count, 0, template)
+}
+
+func parsePieceLine(line string) models.Piece {
+	p, dims := ParseDimensions(line)
+	line = strings.Replace(line, dims, " ", 1)
+	if match := pieceWeightRe.FindStringSubmatch(line); match != nil {
+		p.Weight = parseDecimal(match[1])
+		line = strings.Replace(line, match[0], " ", 1)
+	}
+	p.Description = strings.Trim(strings.Join(strings.Fields(line), " "), " ,;|-")
+	return p
+}
+
+// ParseDimensions reads the first "L x W x H" in the text, converting metres and
+// inches to centimetres. It also returns the matched text.
+func ParseDimensions(text string) (models.Piece, string) {
+	match := dimensionsRe.FindStringSubmatch(text)
+	if match == nil {
+		return models.Piece{}, ""
+	}
+	scale := 1.0
+	switch strings.ToLower(match[4]) {
+	case "m":
+		scale = 100
+	case "in":
+		scale = 2.54
+	}
+	return models.Piece{
+		LengthCm: parseDecimal(match[1]) * scale,
+		WidthCm:  parseDecimal(match[2]) * scale,
+		HeightCm: parseDecimal(match[3]) * scale,
+	}, match[0]
+}
+
+func parseDecimal(s string) float64 {
+	v, _ := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+	return v
+}
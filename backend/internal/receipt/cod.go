@@ -0,0 +1,29 @@
+package receipt
+
+import (
+	"webtracker-bot/internal/shipment"
+
+	"github.com/fogleman/gg"
+)
+
+// paymentMode is the ticked option of the PAYMENT selector: COD shipments are paid in cash.
+func paymentMode(s shipment.Shipment) string {
+	if s.CodAmount > 0 {
+		return "CASH"
+	}
+	return "ACCOUNT"
+}
+
+// drawCODAmount prints the amount to collect under the CASH option of the
+// payment selector at (x, y, w).
+func drawCODAmount(dc *gg.Context, x, y, w float64, s shipment.Shipment) {
+	cod := s.COD()
+	if cod == "" {
+		return
+	}
+	if err := LoadFont(dc, FontArialBold, 15); err != nil {
+		return
+	}
+	dc.SetColor(ColorBurgundy)
+	dc.DrawString(fitText(dc, "COD "+cod, w-90), x+80, y+90+40)
+}
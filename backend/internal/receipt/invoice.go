@@ -0,0 +1,337 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"strings"
+	"time"
+
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+
+	"github.com/fogleman/gg"
+)
+
+// Invoice pages are rendered at 150 DPI A4.
+const (
+	InvoiceWidth  = 1240
+	InvoiceHeight = 1754
+
+	invoiceMargin      = 70.0
+	invoiceRowH        = 34.0
+	invoiceRowsPerPage = 20
+)
+
+var exportReasonLabels = map[string]string{
+	shipment.ExportSale:      "SALE OF GOODS",
+	shipment.ExportGift:      "GIFT",
+	shipment.ExportSample:    "COMMERCIAL SAMPLE",
+	shipment.ExportDocuments: "DOCUMENTS",
+	shipment.ExportReturn:    "RETURNED GOODS",
+	shipment.ExportOther:     "OTHER",
+}
+
+type invoiceColumn struct {
+	title string
+	width float64
+	right bool
+}
+
+var invoiceColumns = []invoiceColumn{
+	{"NO", 50, false},
+	{"DESCRIPTION OF GOODS", 370, false},
+	{"HS CODE", 130, false},
+	{"ORIGIN", 150, false},
+	{"QTY", 70, true},
+	{"UNIT VALUE", 130, true},
+	{"TOTAL VALUE", 130, true},
+	{"NET KG", 70, true},
+}
+
+// RenderInvoice produces a commercial invoice / CN23 declaration as a PDF.
+// Item lines beyond one page continue on further pages; totals print on the last.
+func RenderInvoice(s shipment.Shipment, d models.CustomsDeclaration, companyName string) ([]byte, error) {
+	if len(d.Items) == 0 {
+		return nil, fmt.Errorf("customs declaration has no items")
+	}
+
+	pageCount := (len(d.Items) + invoiceRowsPerPage - 1) / invoiceRowsPerPage
+	pages := make([][]byte, 0, pageCount)
+	for p := 0; p < pageCount; p++ {
+		start := p * invoiceRowsPerPage
+		end := min(start+invoiceRowsPerPage, len(d.Items))
+
+		dc := gg.NewContext(InvoiceWidth, InvoiceHeight)
+		dc.SetColor(color.White)
+		dc.Clear()
+
+		drawInvoiceHeader(dc, s, d, companyName)
+		drawInvoiceParties(dc, s)
+		y := drawInvoiceItems(dc, d, start, end)
+		if p == pageCount-1 {
+			drawInvoiceTotals(dc, s, d, y+30)
+			drawInvoiceDeclaration(dc, s)
+		}
+		drawInvoiceFooter(dc, s, p+1, pageCount)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dc.Image(), &jpeg.Options{Quality: 90}); err != nil {
+			return nil, fmt.Errorf("failed to encode invoice page %d: %w", p+1, err)
+		}
+		pages = append(pages, buf.Bytes())
+	}
+	return jpegPDF(pages, InvoiceWidth, InvoiceHeight), nil
+}
+
+func drawInvoiceHeader(dc *gg.Context, s shipment.Shipment, d models.CustomsDeclaration, companyName string) {
+	if companyName == "" {
+		companyName = "AIRWAY BILL LOGISTICS"
+	}
+	if err := LoadFont(dc, FontArialBold, 34); err == nil {
+		dc.SetColor(ColorDarkText)
+		dc.DrawString(strings.ToUpper(companyName), invoiceMargin, 110)
+	}
+	if err := LoadFont(dc, FontArialBold, 36); err == nil {
+		dc.SetColor(ColorBurgundy)
+		dc.DrawStringAnchored("COMMERCIAL INVOICE", InvoiceWidth-invoiceMargin, 95, 1, 0.5)
+	}
+	if err := LoadFont(dc, FontArialBold, 16); err == nil {
+		dc.SetColor(ColorLabel)
+		dc.DrawStringAnchored("CN23 CUSTOMS DECLARATION", InvoiceWidth-invoiceMargin, 135, 1, 0.5)
+	}
+
+	dc.SetColor(ColorBurgundy)
+	dc.DrawRectangle(invoiceMargin, 165, InvoiceWidth-invoiceMargin*2, 4)
+	dc.Fill()
+
+	invoiceNo := d.InvoiceNumber
+	if invoiceNo == "" {
+		invoiceNo = "INV-" + s.TrackingID
+	}
+	date := s.CreatedAt
+	if date.IsZero() {
+		date = time.Now()
+	}
+	reason := exportReasonLabels[d.ExportReason]
+	if reason == "" {
+		reason = strings.ToUpper(d.ExportReason)
+	}
+
+	colW := (InvoiceWidth - invoiceMargin*2) / 3
+	fields := [][2]string{
+		{"INVOICE NO", invoiceNo},
+		{"DATE", date.Format("02 Jan 2006")},
+		{"AIRWAY BILL", s.TrackingID},
+		{"REASON FOR EXPORT", reason},
+		{"CURRENCY", d.Currency},
+		{"PIECES", fmt.Sprintf("%d", s.PieceCount())},
+	}
+	for i, f := range fields {
+		x := invoiceMargin + colW*float64(i%3)
+		y := 215 + float64(i/3)*70
+		drawInvoiceField(dc, x, y, colW-20, f[0], f[1])
+	}
+}
+
+func drawInvoiceField(dc *gg.Context, x, y, w float64, label, value string) {
+	if err := LoadFont(dc, FontArialBold, 13); err == nil {
+		dc.SetColor(ColorLabel)
+		dc.DrawString(label, x, y)
+	}
+	if value == "" {
+		value = "---"
+	}
+	if err := LoadFont(dc, FontArial, 19); err == nil {
+		dc.SetColor(ColorDarkText)
+		dc.DrawString(fitText(dc, value, w), x, y+28)
+	}
+}
+
+func drawInvoiceParties(dc *gg.Context, s shipment.Shipment) {
+	y := 370.0
+	w := (InvoiceWidth - invoiceMargin*2 - 20) / 2
+	h := 230.0
+
+	drawInvoiceParty(dc, invoiceMargin, y, w, h, "SHIPPER / EXPORTER",
+		[]string{s.SenderName, s.Origin, s.SenderPhone})
+	drawInvoiceParty(dc, invoiceMargin+w+20, y, w, h, "CONSIGNEE / IMPORTER",
+		[]string{s.RecipientName, s.RecipientAddress, s.Destination, s.RecipientPhone, s.RecipientEmail})
+}
+
+func drawInvoiceParty(dc *gg.Context, x, y, w, h float64, title string, lines []string) {
+	dc.SetColor(color.Black)
+	dc.SetLineWidth(1.5)
+	dc.DrawRectangle(x, y, w, h)
+	dc.Stroke()
+
+	dc.SetRGBA255(20, 20, 20, 255)
+	dc.DrawRectangle(x, y, w, 36)
+	dc.Fill()
+	if err := LoadFont(dc, FontArialBold, 15); err == nil {
+		dc.SetColor(color.White)
+		dc.DrawString(title, x+16, y+24)
+	}
+
+	if err := LoadFont(dc, FontArial, 18); err != nil {
+		return
+	}
+	dc.SetColor(ColorDarkText)
+	lineY := y + 70
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		for _, wrapped := range dc.WordWrap(line, w-32) {
+			if lineY > y+h-12 {
+				return
+			}
+			dc.DrawString(fitText(dc, wrapped, w-32), x+16, lineY)
+			lineY += 26
+		}
+	}
+}
+
+// drawInvoiceItems draws the item table for lines [start, end) and returns the y below it.
+func drawInvoiceItems(dc *gg.Context, d models.CustomsDeclaration, start, end int) float64 {
+	y := 650.0
+
+	dc.SetRGBA255(20, 20, 20, 255)
+	dc.DrawRectangle(invoiceMargin, y, InvoiceWidth-invoiceMargin*2, invoiceRowH+4)
+	dc.Fill()
+	if err := LoadFont(dc, FontArialBold, 13); err == nil {
+		dc.SetColor(color.White)
+		drawInvoiceRow(dc, y, columnTitles())
+	}
+	y += invoiceRowH + 4
+
+	if err := LoadFont(dc, FontArial, 15); err != nil {
+		return y
+	}
+	for i := start; i < end; i++ {
+		item := d.Items[i]
+		if (i-start)%2 == 1 {
+			dc.SetRGBA255(0, 0, 0, 12)
+			dc.DrawRectangle(invoiceMargin, y, InvoiceWidth-invoiceMargin*2, invoiceRowH)
+			dc.Fill()
+		}
+		dc.SetColor(ColorDarkText)
+		drawInvoiceRow(dc, y, []string{
+			fmt.Sprintf("%d", i+1),
+			item.Description,
+			item.HSCode,
+			item.OriginCountry,
+			fmt.Sprintf("%d", item.Quantity),
+			fmt.Sprintf("%.2f", item.UnitValue),
+			fmt.Sprintf("%.2f", item.LineValue()),
+			fmt.Sprintf("%.2f", item.Weight),
+		})
+		y += invoiceRowH
+	}
+
+	dc.SetColor(color.Black)
+	dc.SetLineWidth(1.5)
+	dc.DrawLine(invoiceMargin, y, InvoiceWidth-invoiceMargin, y)
+	dc.Stroke()
+	return y
+}
+
+func columnTitles() []string {
+	titles := make([]string, len(invoiceColumns))
+	for i, c := range invoiceColumns {
+		titles[i] = c.title
+	}
+	return titles
+}
+
+func drawInvoiceRow(dc *gg.Context, y float64, cells []string) {
+	x := invoiceMargin
+	for i, c := range invoiceColumns {
+		text := fitText(dc, cells[i], c.width-16)
+		if c.right {
+			dc.DrawStringAnchored(text, x+c.width-8, y+invoiceRowH/2, 1, 0.35)
+		} else {
+			dc.DrawStringAnchored(text, x+8, y+invoiceRowH/2, 0, 0.35)
+		}
+		x += c.width
+	}
+}
+
+func drawInvoiceTotals(dc *gg.Context, s shipment.Shipment, d models.CustomsDeclaration, y float64) {
+	rows := [][2]string{
+		{"TOTAL DECLARED VALUE", fmt.Sprintf("%.2f %s", d.TotalValue(), d.Currency)},
+		{"TOTAL NET WEIGHT", fmt.Sprintf("%.2f KG", d.TotalWeight())},
+		{"GROSS WEIGHT", fmt.Sprintf("%.2f KG", s.Weight)},
+	}
+	x := InvoiceWidth - invoiceMargin
+	for i, r := range rows {
+		ry := y + float64(i)*34
+		if err := LoadFont(dc, FontArialBold, 15); err == nil {
+			dc.SetColor(ColorLabel)
+			dc.DrawStringAnchored(r[0], x-230, ry, 1, 0.5)
+		}
+		if err := LoadFont(dc, FontArialBold, 19); err == nil {
+			dc.SetColor(ColorDarkText)
+			dc.DrawStringAnchored(r[1], x, ry, 1, 0.5)
+		}
+	}
+}
+
+func drawInvoiceDeclaration(dc *gg.Context, s shipment.Shipment) {
+	y := 1480.0
+	text := "I, the undersigned, certify that the particulars given in this declaration are correct and that " +
+		"this shipment does not contain any dangerous article prohibited by legislation or by postal or customs regulations."
+	if err := LoadFont(dc, FontArial, 15); err == nil {
+		dc.SetColor(ColorSlate)
+		dc.DrawStringWrapped(text, invoiceMargin, y, 0, 0, InvoiceWidth-invoiceMargin*2, 1.4, gg.AlignLeft)
+	}
+
+	lineY := y + 150
+	dc.SetColor(color.Black)
+	dc.SetLineWidth(1.5)
+	dc.DrawLine(invoiceMargin, lineY, invoiceMargin+420, lineY)
+	dc.DrawLine(InvoiceWidth-invoiceMargin-300, lineY, InvoiceWidth-invoiceMargin, lineY)
+	dc.Stroke()
+
+	if err := LoadFont(dc, FontSignature, 40); err == nil && s.SenderName != "" {
+		dc.SetColor(ColorInkBlue)
+		dc.DrawString(fitText(dc, s.SenderName, 400), invoiceMargin+10, lineY-12)
+	}
+	if err := LoadFont(dc, FontArialBold, 13); err == nil {
+		dc.SetColor(ColorLabel)
+		dc.DrawString("SIGNATURE OF SENDER", invoiceMargin, lineY+24)
+		dc.DrawString("DATE", InvoiceWidth-invoiceMargin-300, lineY+24)
+	}
+	if err := LoadFont(dc, FontArial, 18); err == nil {
+		date := s.CreatedAt
+		if date.IsZero() {
+			date = time.Now()
+		}
+		dc.SetColor(ColorDarkText)
+		dc.DrawString(date.Format("02 Jan 2006"), InvoiceWidth-invoiceMargin-300, lineY-12)
+	}
+}
+
+func drawInvoiceFooter(dc *gg.Context, s shipment.Shipment, page, pageCount int) {
+	if err := LoadFont(dc, FontArialBold, 11); err == nil {
+		dc.SetRGBA255(20, 20, 20, 120)
+		dc.DrawString(fmt.Sprintf("AIRWAY BILL %s | PAGE %d OF %d", s.TrackingID, page, pageCount), invoiceMargin, InvoiceHeight-40)
+	}
+}
+
+// fitText shortens text with an ellipsis until it fits the width in the current font.
+func fitText(dc *gg.Context, text string, width float64) string {
+	if w, _ := dc.MeasureString(text); w <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if w, _ := dc.MeasureString(string(runes) + "…"); w <= width {
+			break
+		}
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,59 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A4 in PDF points (1/72 inch).
+const (
+	a4WidthPt  = 595.28
+	a4HeightPt = 841.89
+)
+
+// jpegPDF wraps full-page JPEG images (all w×h pixels) into a minimal PDF,
+// one image per A4 page. The images are embedded as-is (DCTDecode).
+func jpegPDF(pages [][]byte, w, h int) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	obj := func(body string, stream []byte) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\n", len(offsets), body)
+		if stream != nil {
+			buf.WriteString("stream\n")
+			buf.Write(stream)
+			buf.WriteString("\nendstream\n")
+		}
+		buf.WriteString("endobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and page tree; each page then takes three
+	// consecutive objects: page, content stream, image.
+	kids := make([]byte, 0, len(pages)*8)
+	for i := range pages {
+		kids = fmt.Appendf(kids, "%d 0 R ", 3+i*3)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>", nil)
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids), len(pages)), nil)
+
+	for i, img := range pages {
+		content, image := 4+i*3, 5+i*3
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /XObject << /Im0 %d 0 R >> >> /Contents %d 0 R >>",
+			a4WidthPt, a4HeightPt, image, content), nil)
+		draw := fmt.Sprintf("q %.2f 0 0 %.2f 0 0 cm /Im0 Do Q", a4WidthPt, a4HeightPt)
+		obj(fmt.Sprintf("<< /Length %d >>", len(draw)), []byte(draw))
+		obj(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>",
+			w, h, len(img)), img)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
@@ -0,0 +1,65 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+
+	"github.com/fogleman/gg"
+)
+
+// weightLabel is the WEIGHT cell value: actual weight, piece count for
+// multi-piece shipments and the chargeable weight when volume dominates.
+func weightLabel(s shipment.Shipment) string {
+	label := fmt.Sprintf("%.2f KGS", s.Weight)
+	if n := s.PieceCount(); n > 1 {
+		label += fmt.Sprintf(" / %d PCS", n)
+	}
+	if chg := s.ChargeableWeight(); chg > s.Weight {
+		label += fmt.Sprintf(" (CHG %.2f)", chg)
+	}
+	return label
+}
+
+// pieceLabel formats one row of the piece table, e.g. "1 OF 3 · 5.00 KG · 40x30x20 CM".
+func pieceLabel(i, n int, p models.Piece) string {
+	parts := []string{fmt.Sprintf("%d OF %d", i+1, n), fmt.Sprintf("%.2f KG", p.Weight)}
+	if p.HasDimensions() {
+		parts = append(parts, fmt.Sprintf("%gx%gx%g CM", p.LengthCm, p.WidthCm, p.HeightCm))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// drawPieceTable fills a grid cell with one "1 OF N" row per piece, collapsing
+// the overflow into a "+N MORE" row.
+func drawPieceTable(dc *gg.Context, x, y, w, h float64, label string, pieces []models.Piece) {
+	padding := 24.0
+	if err := LoadFont(dc, FontArialBold, 15); err == nil {
+		dc.SetColor(ColorLabel)
+		dc.DrawString(strings.ToUpper(label), x+padding, y+32)
+	}
+	if len(pieces) == 0 {
+		return
+	}
+
+	lineH := 18.0
+	maxRows := int((h - 45) / lineH)
+	rows := make([]string, 0, maxRows)
+	for i, p := range pieces {
+		if len(rows) == maxRows-1 && len(pieces) > maxRows {
+			rows = append(rows, fmt.Sprintf("+%d MORE", len(pieces)-i))
+			break
+		}
+		rows = append(rows, pieceLabel(i, len(pieces), p))
+	}
+
+	if err := LoadFont(dc, FontArialBold, 14); err != nil {
+		return
+	}
+	dc.SetColor(ColorDarkText)
+	for i, row := range rows {
+		dc.DrawString(row, x+padding, y+45+lineH*float64(i+1)-4)
+	}
+}
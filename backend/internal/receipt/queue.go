@@ -128,6 +128,11 @@ func processReceipt(rj Job) {
 	if dbShip.ExpectedDeliveryTime.Valid {
 		s.ExpectedDeliveryTime = &dbShip.ExpectedDeliveryTime.Time
 	}
+	if pieces, err := rj.ShipmentUC.ListPieces(context.Background(), rj.Msg.CompanyID, rj.TrackingID); err == nil {
+		s.Pieces = pieces
+	} else {
+		logger.Warn().Err(err).Str("tracking_id", rj.TrackingID).Msg("Rendering receipt without piece table")
+	}
 
 	// 3. Render (Memory Intensive Step - Only one at a time)
 	receiptImg, err := RenderReceipt(*s, rj.CompanyName, rj.Language)
@@ -136,8 +141,9 @@ func processReceipt(rj Job) {
 		return
 	}
 
-	// 4. Send
-	err = rj.Sender.SendImage(rj.Msg.ChatJID, rj.Msg.SenderJID, receiptImg, "", rj.Msg.MessageID, rj.Msg.Text)
+	// 4. Send. The caption carries the ID so that replies to the receipt
+	// (e.g. a rider's shared location) can be tied back to the shipment.
+	err = rj.Sender.SendImage(rj.Msg.ChatJID, rj.Msg.SenderJID, receiptImg, "🆔 "+rj.TrackingID, rj.Msg.MessageID, rj.Msg.Text)
 	if err != nil {
 		logger.Warn().Err(err).Str("tracking_id", rj.TrackingID).Msg("Failed to deliver receipt image")
 	}
@@ -125,11 +125,13 @@ func drawV11Grid(dc *gg.Context, shipment shipment.Shipment, lang i18n.Language)
 		cargoType = "Consignment Box"
 	}
 	drawSmartCellV10(dc, gX, gY+rowH*2+nameH, c1W, rowH, i18n.T(lang, "receipt_content"), cargoType)
-	drawSmartCellV10(dc, gX, gY+rowH*3+nameH, c1W, rowH, i18n.T(lang, "receipt_weight"), fmt.Sprintf("%.2f KGS", shipment.Weight))
+	drawSmartCellV10(dc, gX, gY+rowH*3+nameH, c1W, rowH, i18n.T(lang, "receipt_weight"), weightLabel(shipment))
+	drawPieceTable(dc, gX+c1W+c2W+c3W, gY+rowH+nameH, c4W, rowH, i18n.T(lang, "receipt_pieces"), shipment.Pieces)
 
 	selectorH := rowH * 4
 	drawSelectorV10(dc, gX+c1W, gY, c2W, selectorH, i18n.T(lang, "receipt_service"), []string{"EXPRESS", "DIPLOMATIC", "DOMESTIC", "OVERNIGHT"}, "DIPLOMATIC")
-	drawSelectorV10(dc, gX+c1W+c2W, gY, c3W, selectorH, i18n.T(lang, "receipt_payment"), []string{"CASH", "CHEQUE", "ACCOUNT", "BILLED"}, "ACCOUNT")
+	drawSelectorV10(dc, gX+c1W+c2W, gY, c3W, selectorH, i18n.T(lang, "receipt_payment"), []string{"CASH", "CHEQUE", "ACCOUNT", "BILLED"}, paymentMode(shipment))
+	drawCODAmount(dc, gX+c1W+c2W, gY, c3W, shipment)
 
 	// Use stored timestamps from database
 	var depStr, arrStr string
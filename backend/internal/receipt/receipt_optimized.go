@@ -305,7 +305,8 @@ func drawDynamicFields(dc *gg.Context, s shipment.Shipment, companyName string,
 		cargoType = "Consignment Box"
 	}
 	drawSmartCellV10OnlyText(dc, gX, gY+rowH*2+nameH, c1W, rowH, i18n.T(lang, "receipt_content"), cargoType)
-	drawSmartCellV10OnlyText(dc, gX, gY+rowH*3+nameH, c1W, rowH, i18n.T(lang, "receipt_weight"), fmt.Sprintf("%.2f KGS", s.Weight))
+	drawSmartCellV10OnlyText(dc, gX, gY+rowH*3+nameH, c1W, rowH, i18n.T(lang, "receipt_weight"), weightLabel(s))
+	drawPieceTable(dc, gX+c1W+c2W+c3W, gY+rowH+nameH, c4W, rowH, i18n.T(lang, "receipt_pieces"), s.Pieces)
 
 	dateFormat := i18n.GetDateFormat(lang)
 	var depStr, arrStr string
@@ -337,7 +338,8 @@ func drawDynamicFields(dc *gg.Context, s shipment.Shipment, companyName string,
 	drawSmartCellV10OnlyText(dc, gX+c1W+c2W+c3W, gY+624.0, c4W, 200.0, i18n.T(lang, "receipt_phone"), s.RecipientPhone)
 
 	drawSelectorV10OnlyText(dc, gX+c1W, gY, c2W, rowH*4, i18n.T(lang, "receipt_service"), "DIPLOMATIC", []string{"EXPRESS", "DIPLOMATIC", "DOMESTIC", "OVERNIGHT"})
-	drawSelectorV10OnlyText(dc, gX+c1W+c2W, gY, c3W, rowH*4, i18n.T(lang, "receipt_payment"), "ACCOUNT", []string{"CASH", "CHEQUE", "ACCOUNT", "BILLED"})
+	drawSelectorV10OnlyText(dc, gX+c1W+c2W, gY, c3W, rowH*4, i18n.T(lang, "receipt_payment"), paymentMode(s), []string{"CASH", "CHEQUE", "ACCOUNT", "BILLED"})
+	drawCODAmount(dc, gX+c1W+c2W, gY, c3W, s)
 
 	drawAuthSignature(dc, s.SenderName)
 	drawSecurityFooter(dc, s, companyName)
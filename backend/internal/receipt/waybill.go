@@ -25,7 +25,20 @@ func GenerateWaybill(s shipment.Shipment, companyName string) string {
 
 	b.WriteString(fmt.Sprintf("🆔 [TRACKING ID]: %s\n", s.TrackingID))
 	b.WriteString(fmt.Sprintf("📍 [STATUS]: %s\n", strings.ToUpper(s.Status)))
-	b.WriteString(fmt.Sprintf("📅 [DATE]:   %s\n\n", s.CreatedAt.Format("02 Jan 2006, 15:04")))
+	b.WriteString(fmt.Sprintf("📅 [DATE]:   %s\n", s.CreatedAt.Format("02 Jan 2006, 15:04")))
+	if s.ReturnOf != "" {
+		b.WriteString(fmt.Sprintf("🔁 [RETURN OF]: %s\n", s.ReturnOf))
+	}
+	if s.ReturnID != "" {
+		b.WriteString(fmt.Sprintf("🔁 [RETURNED AS]: %s\n", s.ReturnID))
+	}
+	if s.ReturnReason != "" {
+		b.WriteString(fmt.Sprintf("📝 [REASON]: %s\n", s.ReturnReason))
+	}
+	if cp := s.LastCheckpoint; cp != nil {
+		b.WriteString(fmt.Sprintf("📍 [LAST SEEN]: %s, %s\n", cp.Label, cp.CreatedAt.Format("02 Jan 15:04")))
+	}
+	b.WriteString("\n")
 
 	b.WriteString("👤 [SENDER INFORMATION]\n")
 	b.WriteString(fmt.Sprintf("   • Name:    %s\n", s.SenderName))
@@ -41,7 +54,26 @@ func GenerateWaybill(s shipment.Shipment, companyName string) string {
 		b.WriteString(fmt.Sprintf("   • ID/PP:   %s\n", s.RecipientID))
 	}
 	b.WriteString(fmt.Sprintf("   • Destination: %s\n", s.Destination))
-	b.WriteString(fmt.Sprintf("   • Weight:      %.2f KGS\n\n", s.Weight))
+	b.WriteString(fmt.Sprintf("   • Weight:      %.2f KGS\n", s.Weight))
+	if chg := s.ChargeableWeight(); chg > s.Weight {
+		b.WriteString(fmt.Sprintf("   • Chargeable:  %.2f KGS\n", chg))
+	}
+	if cod := s.COD(); cod != "" {
+		b.WriteString(fmt.Sprintf("   • COD:         %s\n", cod))
+	}
+	b.WriteString("\n")
+
+	if len(s.Pieces) > 0 {
+		b.WriteString(fmt.Sprintf("📦 [PIECES]: %d\n", len(s.Pieces)))
+		for i, p := range s.Pieces {
+			row := pieceLabel(i, len(s.Pieces), p)
+			if p.Description != "" {
+				row += " · " + p.Description
+			}
+			b.WriteString("   • " + row + "\n")
+		}
+		b.WriteString("\n")
+	}
 
 	b.WriteString(border + "\n")
 	b.WriteString("  *THANK YOU FOR YOUR PATRONAGE*  \n")
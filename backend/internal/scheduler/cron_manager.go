@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"webtracker-bot/internal/archive"
 	"webtracker-bot/internal/config"
 	"webtracker-bot/internal/logger"
 	"webtracker-bot/internal/models"
@@ -25,6 +26,7 @@ type CronManager struct {
 	shipUC    *shipment.Usecase
 	configUC  *config.Usecase
 	bots      models.BotProvider
+	archiver  *archive.Archiver
 	locks     map[string]*sync.Mutex
 	mu        sync.RWMutex
 }
@@ -32,12 +34,17 @@ type CronManager struct {
 func NewManager(cfg *config.Config, shipUC *shipment.Usecase, configUC *config.Usecase, bots models.BotProvider) *CronManager {
 	// Use seconds precision for robfig/cron/v3
 	c := cron.New(cron.WithSeconds())
+	var archiver *archive.Archiver
+	if cfg.ArchiveDir != "" {
+		archiver = archive.NewArchiver(archive.NewLocalStorage(cfg.ArchiveDir), shipUC)
+	}
 	return &CronManager{
 		scheduler: c,
 		cfg:       cfg,
 		shipUC:    shipUC,
 		configUC:  configUC,
 		bots:      bots,
+		archiver:  archiver,
 		locks:     make(map[string]*sync.Mutex),
 	}
 }
@@ -132,7 +139,7 @@ func (m *CronManager) handlePulse() {
 					continue
 				}
 
-				notif.SendStatusAlertAsync(bot.GetWAClient(), m.cfg, bot.GetCompanyName(), t.UserJID, t.TrackingID, t.NewStatus, t.RecipientEmail)
+				notif.SendStatusAlertAsync(bot.GetWAClient(), m.cfg, bot.GetCompanyName(), t.UserJID, t.TrackingID, t.NewStatus, t.RecipientEmail, shipment.FormatCOD(t.CodAmount, t.CodCurrency))
 			}
 		}
 	}
@@ -199,8 +206,7 @@ func (m *CronManager) handleDailyStats() {
 
 func (m *CronManager) handlePruning() {
 	ctx := context.Background()
-	deliveredCutoff := time.Now().AddDate(0, 0, -7)
-	allCutoff := time.Now().AddDate(0, 0, -14)
+	now := time.Now()
 
 	companies, err := m.configUC.GetAllCompanies(ctx)
 	if err != nil {
@@ -208,14 +214,67 @@ func (m *CronManager) handlePruning() {
 		return
 	}
 
+	var totalDeleted, totalPurged int64
 	for _, companyID := range companies {
-		deleted, err := m.shipUC.RunAgedCleanup(ctx, companyID, deliveredCutoff, allCutoff)
+		deleted, purged, err := m.pruneCompany(ctx, companyID, now)
 		if err != nil {
-			logger.Error().Err(err).Msg("Pruning: Failed to run aged cleanup")
+			logger.Error().Err(err).Str("company", companyID.String()).Msg("Pruning: Failed to prune company")
 			continue
 		}
-		logger.Info().Int64("deleted_count", deleted).Msg("Pruning: Aged cleanup completed successfully")
+		totalDeleted += deleted
+		totalPurged += purged
+	}
+	logger.Info().Int("companies", len(companies)).Int64("deleted_count", totalDeleted).Int64("purged_count", totalPurged).Msg("Pruning: Completed")
+}
+
+// pruneCompany applies one tenant's retention policy and empties its expired trash.
+// Returns the number of aged shipments deleted and trashed shipments purged.
+func (m *CronManager) pruneCompany(ctx context.Context, companyID uuid.UUID, now time.Time) (int64, int64, error) {
+	retention, err := m.shipUC.Retention(ctx, companyID)
+	if err != nil {
+		return 0, 0, err
+	}
+	deliveredCutoff, allCutoff := retention.Policy.Cutoffs(now)
+
+	var deleted int64
+	if m.archiver != nil {
+		deleted, err = m.archiver.ArchiveAged(ctx, companyID, deliveredCutoff, allCutoff)
+	} else {
+		deleted, err = m.shipUC.RunAgedCleanup(ctx, companyID, deliveredCutoff, allCutoff)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("aged cleanup: %w", err)
+	}
+
+	var purged int64
+	if m.cfg.TrashRetentionDays > 0 {
+		deletedBefore := now.AddDate(0, 0, -m.cfg.TrashRetentionDays)
+		if m.archiver != nil {
+			purged, err = m.archiver.ArchiveTrash(ctx, companyID, deletedBefore)
+		} else {
+			purged, err = m.shipUC.PurgeTrash(ctx, companyID, deletedBefore)
+		}
+		if err != nil {
+			return deleted, 0, fmt.Errorf("trash purge: %w", err)
+		}
+	}
+
+	logger.Info().
+		Str("company", companyID.String()).
+		Int("delivered_days", retention.Policy.DeliveredDays).
+		Int("created_days", retention.Policy.CreatedDays).
+		Int64("deleted_count", deleted).
+		Int64("purged_count", purged).
+		Msg("Pruning: Company pruned")
+
+	if deleted > 0 || purged > 0 {
+		meta := []byte(fmt.Sprintf(`{"deleted": %d, "purged": %d, "delivered_days": %d, "created_days": %d}`,
+			deleted, purged, retention.Policy.DeliveredDays, retention.Policy.CreatedDays))
+		if err := m.shipUC.RecordEvent(ctx, companyID, "retention_pruned", meta); err != nil {
+			logger.Warn().Err(err).Str("company", companyID.String()).Msg("Pruning: Failed to record telemetry")
+		}
 	}
+	return deleted, purged, nil
 }
 
 func (m *CronManager) handleHealthCheck() {
@@ -0,0 +1,120 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+
+	"github.com/google/uuid"
+)
+
+// AgedShipments returns up to limit shipments that RunAgedCleanup would delete,
+// oldest first, so they can be archived before they are purged.
+func (u *Usecase) AgedShipments(ctx context.Context, companyID uuid.UUID, deliveredOlderThan, createdOlderThan time.Time, limit int32) ([]db.Shipment, error) {
+	rows, err := u.repo.ListAgedShipments(ctx, db.ListAgedShipmentsParams{
+		CompanyID: toNullUUID(companyID),
+		UpdatedAt: dbutil.ToNullTime(deliveredOlderThan),
+		CreatedAt: dbutil.ToNullTime(createdOlderThan),
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aged shipments: %w", err)
+	}
+	return rows, nil
+}
+
+// ExpiredTrash returns up to limit shipments trashed before deletedBefore,
+// oldest first.
+func (u *Usecase) ExpiredTrash(ctx context.Context, companyID uuid.UUID, deletedBefore time.Time, limit int32) ([]db.Shipment, error) {
+	rows, err := u.repo.ListExpiredTrash(ctx, db.ListExpiredTrashParams{
+		CompanyID: toNullUUID(companyID),
+		DeletedAt: dbutil.ToNullTime(deletedBefore),
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired trash: %w", err)
+	}
+	return rows, nil
+}
+
+// EventsForShipments returns the status history of several shipments at once.
+func (u *Usecase) EventsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentEvent, error) {
+	rows, err := u.repo.ListEventsForShipments(ctx, db.ListEventsForShipmentsParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipment events: %w", err)
+	}
+	return rows, nil
+}
+
+// PiecesForShipments returns the recorded pieces of several shipments at once.
+func (u *Usecase) PiecesForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentPiece, error) {
+	rows, err := u.repo.ListPiecesForShipments(ctx, db.ListPiecesForShipmentsParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipment pieces: %w", err)
+	}
+	return rows, nil
+}
+
+// CustomsForShipments returns the customs declarations of several shipments
+// at once, with their line items.
+func (u *Usecase) CustomsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.CustomsDeclaration, []db.CustomsItem, error) {
+	decls, err := u.repo.ListCustomsForShipments(ctx, db.ListCustomsForShipmentsParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list customs declarations: %w", err)
+	}
+	items, err := u.repo.ListCustomsItemsForShipments(ctx, db.ListCustomsItemsForShipmentsParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list customs items: %w", err)
+	}
+	return decls, items, nil
+}
+
+// ChangesForShipments returns the edit history of several shipments at once.
+func (u *Usecase) ChangesForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentChange, error) {
+	rows, err := u.repo.ListChangesForShipments(ctx, db.ListChangesForShipmentsParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipment changes: %w", err)
+	}
+	return rows, nil
+}
+
+// AttemptsForShipments returns the failed delivery attempts of several shipments at once.
+func (u *Usecase) AttemptsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.DeliveryAttempt, error) {
+	rows, err := u.repo.ListAttemptsForShipments(ctx, db.ListAttemptsForShipmentsParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+	return rows, nil
+}
+
+// CheckpointsForShipments returns the location checkpoints of several shipments at once.
+func (u *Usecase) CheckpointsForShipments(ctx context.Context, companyID uuid.UUID, ids []string) ([]db.ShipmentCheckpoint, error) {
+	rows, err := u.repo.ListCheckpointsForShipments(ctx, db.ListCheckpointsForShipmentsParams{CompanyID: companyID, Column2: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	return rows, nil
+}
+
+// PurgeShipments permanently deletes the given shipments, live or trashed,
+// along with their proof-of-delivery images.
+func (u *Usecase) PurgeShipments(ctx context.Context, companyID uuid.UUID, ids []string) (int64, error) {
+	var keys []string
+	if u.Blobs != nil {
+		var err error
+		keys, err = u.repo.ListPODKeysForShipments(ctx, db.ListPODKeysForShipmentsParams{CompanyID: companyID, Column2: ids})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list proof of delivery: %w", err)
+		}
+	}
+	result, err := u.repo.PurgeShipments(ctx, db.PurgeShipmentsParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge shipments: %w", err)
+	}
+	u.deletePODBlobs(ctx, keys)
+	purged, _ := result.RowsAffected()
+	return purged, nil
+}
@@ -0,0 +1,241 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// AttemptPolicyKey is the SystemConfig key holding a company's delivery attempt policy (JSON).
+const AttemptPolicyKey = "delivery_attempt_policy"
+
+// Delivery attempt bounds. After the last allowed attempt the shipment stays
+// in delivery_failed until it is redelivered by hand or returned.
+const (
+	DefaultMaxAttempts = 3
+	maxAttemptsLimit   = 10
+	maxAttemptNote     = 200
+)
+
+// Failed delivery reason codes.
+const (
+	AttemptNoOneHome      = "no_one_home"
+	AttemptWrongAddress   = "wrong_address"
+	AttemptRefused        = "refused"
+	AttemptBusinessClosed = "business_closed"
+	AttemptOther          = "other"
+)
+
+// AttemptReasons lists the reason codes in display order.
+var AttemptReasons = []string{AttemptNoOneHome, AttemptWrongAddress, AttemptRefused, AttemptBusinessClosed, AttemptOther}
+
+var (
+	// ErrInvalidAttempt wraps reasons a delivery attempt can't be recorded.
+	ErrInvalidAttempt = errors.New("invalid delivery attempt")
+	// ErrInvalidAttemptPolicy wraps validation failures when saving the policy.
+	ErrInvalidAttemptPolicy = errors.New("invalid delivery attempt policy")
+)
+
+// AttemptPolicy controls how often delivery is retried, and which shipments
+// need the recipient's delivery code to be handed over.
+type AttemptPolicy struct {
+	MaxAttempts int `json:"max_attempts"`
+	// OTPMinValue is the COD or declared customs value from which a shipment
+	// needs a delivery code; 0 turns delivery codes off.
+	OTPMinValue float64 `json:"otp_min_value"`
+}
+
+// Validate checks the policy's bounds.
+func (p AttemptPolicy) Validate() error {
+	if p.MaxAttempts < 1 || p.MaxAttempts > maxAttemptsLimit {
+		return fmt.Errorf("%w: max_attempts must be between 1 and %d", ErrInvalidAttemptPolicy, maxAttemptsLimit)
+	}
+	if p.OTPMinValue < 0 {
+		return fmt.Errorf("%w: otp_min_value can't be negative", ErrInvalidAttemptPolicy)
+	}
+	return nil
+}
+
+// IsAttemptReason reports whether code is one of the Attempt* reason codes.
+func IsAttemptReason(code string) bool {
+	for _, r := range AttemptReasons {
+		if r == code {
+			return true
+		}
+	}
+	return false
+}
+
+// AttemptPolicy returns the company's delivery attempt policy, or the default.
+func (u *Usecase) AttemptPolicy(ctx context.Context, companyID uuid.UUID) (AttemptPolicy, error) {
+	p := AttemptPolicy{MaxAttempts: DefaultMaxAttempts}
+	raw, err := u.repo.GetSystemConfig(ctx, db.GetSystemConfigParams{CompanyID: companyID, Key: AttemptPolicyKey})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && raw == "") {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("failed to get delivery attempt policy: %w", err)
+	}
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return p, fmt.Errorf("failed to decode delivery attempt policy: %w", err)
+	}
+	return p, nil
+}
+
+// SetAttemptPolicy validates and stores the company's delivery attempt policy.
+func (u *Usecase) SetAttemptPolicy(ctx context.Context, companyID uuid.UUID, p AttemptPolicy) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode delivery attempt policy: %w", err)
+	}
+	if err := u.repo.SetSystemConfig(ctx, db.SetSystemConfigParams{CompanyID: companyID, Key: AttemptPolicyKey, Value: string(raw)}); err != nil {
+		return fmt.Errorf("failed to save delivery attempt policy: %w", err)
+	}
+	return nil
+}
+
+// RecordFailedAttempt logs a failed delivery of a shipment that is out for
+// delivery. While attempts are left it goes back to intransit and is
+// rescheduled through the shipment's Service, so the pulse sends it out again;
+// the last allowed attempt leaves it in delivery_failed. version works as in
+// UpdateStatus.
+func (u *Usecase) RecordFailedAttempt(ctx context.Context, companyID uuid.UUID, trackingID, reason, note string, version int32) (*models.AttemptResult, error) {
+	reason = strings.ToLower(strings.TrimSpace(reason))
+	if !IsAttemptReason(reason) {
+		return nil, fmt.Errorf("%w: reason must be one of %s", ErrInvalidAttempt, strings.Join(AttemptReasons, ", "))
+	}
+	note = strings.TrimSpace(note)
+	if len(note) > maxAttemptNote {
+		return nil, fmt.Errorf("%w: note is longer than %d characters", ErrInvalidAttempt, maxAttemptNote)
+	}
+
+	current, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	if version != 0 && current.Version != version {
+		return nil, ErrVersionConflict
+	}
+	if current.Status.String != StatusOutForDelivery {
+		return nil, fmt.Errorf("%w: %s is %s, not out for delivery", ErrInvalidAttempt, trackingID, current.Status.String)
+	}
+
+	policy, err := u.AttemptPolicy(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := u.repo.CountDeliveryAttempts(ctx, db.CountDeliveryAttemptsParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count delivery attempts: %w", err)
+	}
+
+	now := time.Now().UTC()
+	res := &models.AttemptResult{
+		MaxAttempts:       policy.MaxAttempts,
+		Final:             int(count)+1 >= policy.MaxAttempts,
+		Status:            StatusDeliveryFailed,
+		UserJID:           current.UserJid,
+		RecipientTimezone: current.RecipientTimezone.String,
+	}
+	updated := current
+	params := db.RescheduleDeliveryParams{
+		CompanyID:  toNullUUID(companyID),
+		TrackingID: trackingID,
+		Version:    current.Version,
+	}
+	if !res.Final {
+		res.Status = StatusIntransit
+		svc := u.ServiceFor(ctx, companyID, current.ServiceLevel.String)
+		res.Delivery, res.OutForDelivery = svc.CalculateRedelivery(now, current.Destination.String)
+		params.OutfordeliveryTime = dbutil.ToNullTime(res.OutForDelivery)
+		params.ExpectedDeliveryTime = dbutil.ToNullTime(res.Delivery)
+		updated.OutfordeliveryTime, updated.ExpectedDeliveryTime = params.OutfordeliveryTime, params.ExpectedDeliveryTime
+	}
+	params.Status = dbutil.ToNullString(res.Status)
+	updated.Status = params.Status
+
+	// The attempt is logged and the shipment rescheduled together, or neither
+	source, actor := utils.GetSource(ctx)
+	var row db.DeliveryAttempt
+	err = u.inTx(ctx, func(tx *Usecase) error {
+		var err error
+		row, err = tx.repo.InsertDeliveryAttempt(ctx, db.InsertDeliveryAttemptParams{
+			CompanyID:     toNullUUID(companyID),
+			TrackingID:    trackingID,
+			AttemptNo:     int32(count) + 1,
+			Reason:        reason,
+			Note:          dbutil.ToNullString(note),
+			Source:        source,
+			Actor:         dbutil.ToNullString(actor),
+			NextAttemptAt: params.ExpectedDeliveryTime,
+		})
+		if err != nil {
+			// A concurrent report took this attempt number
+			if dbutil.IsUniqueViolation(err) {
+				return ErrVersionConflict
+			}
+			return fmt.Errorf("failed to record delivery attempt: %w", err)
+		}
+		if err := applied(tx.repo.RescheduleDelivery(ctx, params)); err != nil {
+			if errors.Is(err, ErrVersionConflict) {
+				return err
+			}
+			return fmt.Errorf("failed to reschedule delivery: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Attempt = attemptFromDB(row)
+
+	for _, field := range []string{"status", "outfordelivery_time", "expected_delivery_time"} {
+		u.recordChange(ctx, companyID, trackingID, field, fieldValue(current, field), fieldValue(updated, field), false)
+	}
+	u.recordStatusEvent(ctx, companyID, trackingID, res.Status, current.Status.String,
+		fmt.Sprintf("Delivery attempt %d of %d failed: %s", res.Attempt.AttemptNo, policy.MaxAttempts, strings.ReplaceAll(reason, "_", " ")))
+	return res, nil
+}
+
+// ListAttempts returns a shipment's failed delivery attempts, oldest first.
+func (u *Usecase) ListAttempts(ctx context.Context, companyID uuid.UUID, trackingID string) ([]models.DeliveryAttempt, error) {
+	rows, err := u.repo.ListDeliveryAttempts(ctx, db.ListDeliveryAttemptsParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery attempts: %w", err)
+	}
+	attempts := make([]models.DeliveryAttempt, 0, len(rows))
+	for _, r := range rows {
+		attempts = append(attempts, attemptFromDB(r))
+	}
+	return attempts, nil
+}
+
+func attemptFromDB(r db.DeliveryAttempt) models.DeliveryAttempt {
+	a := models.DeliveryAttempt{
+		AttemptNo: r.AttemptNo,
+		Reason:    r.Reason,
+		Note:      r.Note.String,
+		Source:    r.Source,
+		Actor:     r.Actor.String,
+		CreatedAt: r.CreatedAt.Time,
+	}
+	if r.NextAttemptAt.Valid {
+		next := r.NextAttemptAt.Time
+		a.NextAttemptAt = &next
+	}
+	return a
+}
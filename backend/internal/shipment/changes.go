@@ -0,0 +1,226 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNothingToUndo is returned by UndoLastEdit when the caller has no edit left to revert.
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrUndoStale is returned by UndoLastEdit when a field was changed
+	// again after the edit, so reverting it would overwrite the newer value.
+	ErrUndoStale = errors.New("shipment changed since the edit")
+)
+
+// changeTimeLayout is how timestamps are stored in the change history.
+const changeTimeLayout = "2006-01-02 15:04:05"
+
+func changeFromDB(c db.ShipmentChange) models.FieldChange {
+	fc := models.FieldChange{
+		Field:     c.Field,
+		Source:    c.Source,
+		Actor:     c.Actor.String,
+		BatchID:   c.BatchID,
+		Undo:      c.IsUndo,
+		CreatedAt: c.CreatedAt.Time,
+	}
+	if c.OldValue.Valid {
+		fc.OldValue = &c.OldValue.String
+	}
+	if c.NewValue.Valid {
+		fc.NewValue = &c.NewValue.String
+	}
+	if c.RevertedAt.Valid {
+		fc.RevertedAt = &c.RevertedAt.Time
+	}
+	return fc
+}
+
+// fieldValue renders a shipment field the way it is stored in the change history.
+func fieldValue(s db.Shipment, field string) sql.NullString {
+	ts := func(v sql.NullTime) sql.NullString {
+		if !v.Valid {
+			return sql.NullString{}
+		}
+		return dbutil.ToNullString(v.Time.UTC().Format(changeTimeLayout))
+	}
+	switch field {
+	case "sender_name":
+		return s.SenderName
+	case "sender_phone":
+		return s.SenderPhone
+	case "origin":
+		return s.Origin
+	case "recipient_name":
+		return s.RecipientName
+	case "recipient_phone":
+		return s.RecipientPhone
+	case "recipient_email":
+		return s.RecipientEmail
+	case "recipient_id":
+		return s.RecipientID
+	case "recipient_address":
+		return s.RecipientAddress
+	case "destination":
+		return s.Destination
+	case "cargo_type":
+		return s.CargoType
+	case "scheduled_transit_time":
+		return ts(s.ScheduledTransitTime)
+	case "expected_delivery_time":
+		return ts(s.ExpectedDeliveryTime)
+	case "outfordelivery_time":
+		return ts(s.OutfordeliveryTime)
+	case "cost":
+		if !s.Cost.Valid {
+			return sql.NullString{}
+		}
+		return dbutil.ToNullString(strconv.FormatFloat(s.Cost.Float64, 'f', -1, 64))
+	case "status":
+		return s.Status
+	case "tags":
+		return tagsValue(s.Tags)
+	}
+	if name, ok := strings.CutPrefix(field, "cf."); ok {
+		return jsonValue(decodeCustomFields(s.CustomFields)[name])
+	}
+	return sql.NullString{}
+}
+
+// changeBatch returns the batch the context's changes belong to. Without
+// one (API calls, background jobs), every change is its own batch.
+func changeBatch(ctx context.Context) uuid.UUID {
+	if id, err := uuid.Parse(utils.GetChangeBatch(ctx)); err == nil {
+		return id
+	}
+	return uuid.New()
+}
+
+// recordChange appends a field change to the shipment's history. Like
+// status events, failures are logged but never block the edit itself.
+func (u *Usecase) recordChange(ctx context.Context, companyID uuid.UUID, trackingID, field string, oldValue, newValue sql.NullString, undo bool) {
+	if oldValue == newValue {
+		return
+	}
+	source, actor := utils.GetSource(ctx)
+	err := u.repo.InsertShipmentChange(ctx, db.InsertShipmentChangeParams{
+		CompanyID:  toNullUUID(companyID),
+		TrackingID: trackingID,
+		BatchID:    changeBatch(ctx),
+		Field:      field,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+		Source:     source,
+		Actor:      dbutil.ToNullString(actor),
+		IsUndo:     undo,
+	})
+	if err != nil {
+		logger.Warn().Err(err).Str("tracking_id", trackingID).Str("field", field).Msg("Failed to record shipment change")
+	}
+}
+
+// Changes returns a shipment's field-level edit history, newest first.
+func (u *Usecase) Changes(ctx context.Context, companyID uuid.UUID, trackingID string, limit, offset int32) ([]models.FieldChange, error) {
+	if _, err := u.Track(ctx, companyID, trackingID); err != nil {
+		return nil, err
+	}
+	rows, err := u.repo.ListShipmentChanges(ctx, db.ListShipmentChangesParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID, Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changes: %w", err)
+	}
+	changes := make([]models.FieldChange, 0, len(rows))
+	for _, r := range rows {
+		changes = append(changes, changeFromDB(r))
+	}
+	return changes, nil
+}
+
+// UndoLastEdit reverts the most recent edit batch made by actor, restoring
+// every field to its previous value. The revert is recorded in the history
+// too, but is never picked up by a later undo — repeated calls walk further
+// back instead. Nothing is reverted when any field no longer holds the value
+// the edit set (ErrUndoStale). Status goes back through the same checks as
+// UpdateStatus. Returns the shipment and the changes that were reverted;
+// on failure the shipment is still returned once it is known.
+func (u *Usecase) UndoLastEdit(ctx context.Context, companyID uuid.UUID, actor string) (string, []models.FieldChange, error) {
+	batch, err := u.repo.ListLastChangeBatch(ctx, db.ListLastChangeBatchParams{CompanyID: toNullUUID(companyID), Actor: dbutil.ToNullString(actor)})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load last edit: %w", err)
+	}
+	if len(batch) == 0 {
+		return "", nil, ErrNothingToUndo
+	}
+
+	trackingID := batch[0].TrackingID
+	err = u.inTx(ctx, func(tx *Usecase) error {
+		current, err := tx.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+		if err != nil {
+			return fmt.Errorf("failed to get shipment: %w", err)
+		}
+
+		// Newest first, so a field changed twice in one batch ends at its
+		// oldest value, and its first row holds the value it should have now.
+		target := make(map[string]sql.NullString)
+		var fields []string
+		for _, c := range batch {
+			if _, seen := target[c.Field]; !seen {
+				if fieldValue(current, c.Field) != c.NewValue {
+					return fmt.Errorf("%w: %s", ErrUndoStale, c.Field)
+				}
+				fields = append(fields, c.Field)
+			}
+			target[c.Field] = c.OldValue
+		}
+
+		undoCtx := utils.WithChangeBatch(ctx, uuid.NewString())
+		// Every write bumps the version; each must land on the one before it
+		version := current.Version
+		for _, field := range fields {
+			if field == "status" {
+				if err := tx.updateStatus(undoCtx, companyID, trackingID, target[field].String, current.Destination.String, version, true); err != nil {
+					return err
+				}
+				version++
+				continue
+			}
+			err := applied(tx.repo.SetShipmentField(ctx, db.SetShipmentFieldParams{
+				Field:      field,
+				Value:      target[field],
+				CompanyID:  toNullUUID(companyID),
+				TrackingID: trackingID,
+				Version:    version,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to revert %s: %w", field, err)
+			}
+			version++
+			tx.recordChange(undoCtx, companyID, trackingID, field, fieldValue(current, field), target[field], true)
+		}
+
+		if err := tx.repo.MarkChangeBatchReverted(ctx, db.MarkChangeBatchRevertedParams{CompanyID: toNullUUID(companyID), BatchID: batch[0].BatchID}); err != nil {
+			return fmt.Errorf("failed to mark edit as undone: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return trackingID, nil, err
+	}
+	reverted := make([]models.FieldChange, 0, len(batch))
+	for _, c := range batch {
+		reverted = append(reverted, changeFromDB(c))
+	}
+	return trackingID, reverted, nil
+}
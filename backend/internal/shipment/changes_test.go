@@ -0,0 +1,26 @@
+package shipment
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"webtracker-bot/internal/database/db"
+)
+
+func TestFieldValue(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	s := db.Shipment{
+		RecipientName:        sql.NullString{String: "Ada", Valid: true},
+		ExpectedDeliveryTime: sql.NullTime{Time: time.Date(2026, 5, 17, 10, 30, 0, 0, lagos), Valid: true},
+		Cost:                 sql.NullFloat64{Float64: 42.5, Valid: true},
+	}
+
+	assert.Equal(t, sql.NullString{String: "Ada", Valid: true}, fieldValue(s, "recipient_name"))
+	assert.Equal(t, sql.NullString{String: "2026-05-17 09:30:00", Valid: true}, fieldValue(s, "expected_delivery_time"))
+	assert.Equal(t, sql.NullString{String: "42.5", Valid: true}, fieldValue(s, "cost"))
+	assert.False(t, fieldValue(s, "outfordelivery_time").Valid)
+	assert.False(t, fieldValue(s, "weight").Valid)
+}
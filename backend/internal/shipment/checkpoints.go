@@ -0,0 +1,125 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/geo"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// CheckinMinutes is how long after !checkin (or the last location sent
+	// under it) a sender's location shares still go to the shipment.
+	CheckinMinutes = 60
+	// liveCheckpointInterval thins out live location updates, which can
+	// arrive every few seconds.
+	liveCheckpointInterval = 5 * time.Minute
+)
+
+// ErrInvalidCheckpoint is returned for locations without a usable fix.
+var ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+
+func checkpointFromDB(r db.ShipmentCheckpoint) models.Checkpoint {
+	return models.Checkpoint{
+		Latitude:  r.Latitude,
+		Longitude: r.Longitude,
+		AccuracyM: r.AccuracyM.Int32,
+		Label:     r.Label,
+		Live:      r.Live,
+		Source:    r.Source,
+		Actor:     r.Actor.String,
+		CreatedAt: r.CreatedAt.Time,
+	}
+}
+
+// Checkin points the sender's location shares for the next hour at a
+// shipment, for riders who aren't replying to its receipt. Sending !checkin
+// again, or another location, keeps the window open. rider works as in
+// RiderDeliver.
+func (u *Usecase) Checkin(ctx context.Context, companyID uuid.UUID, rider *models.Rider, phone, trackingID string) error {
+	if err := u.checkRider(ctx, companyID, rider, trackingID); err != nil {
+		return err
+	}
+	if _, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID}); err != nil {
+		return fmt.Errorf("failed to get shipment: %w", err)
+	}
+	err := u.repo.OpenCheckin(ctx, db.OpenCheckinParams{
+		CompanyID:   companyID,
+		SenderPhone: digitsOnly(phone),
+		TrackingID:  trackingID,
+		Minutes:     CheckinMinutes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open check-in: %w", err)
+	}
+	return nil
+}
+
+// CheckinTarget returns the shipment the sender checked in to, or
+// sql.ErrNoRows when their window has closed.
+func (u *Usecase) CheckinTarget(ctx context.Context, companyID uuid.UUID, phone string) (string, error) {
+	return u.repo.GetOpenCheckin(ctx, db.GetOpenCheckinParams{CompanyID: companyID, SenderPhone: digitsOnly(phone)})
+}
+
+// RecordCheckpoint pins a shared location to a shipment, labelled with the
+// nearest known city. Live updates closer together than
+// liveCheckpointInterval are dropped: the result is nil without an error.
+// rider works as in RiderDeliver.
+func (u *Usecase) RecordCheckpoint(ctx context.Context, companyID uuid.UUID, rider *models.Rider, trackingID string, cp models.Checkpoint) (*models.Checkpoint, error) {
+	if !geo.Valid(cp.Latitude, cp.Longitude) {
+		return nil, fmt.Errorf("%w: no position fix", ErrInvalidCheckpoint)
+	}
+	if err := u.checkRider(ctx, companyID, rider, trackingID); err != nil {
+		return nil, err
+	}
+	if _, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID}); err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+
+	if cp.Live {
+		last, err := u.repo.GetLastCheckpoint(ctx, db.GetLastCheckpointParams{CompanyID: companyID, TrackingID: trackingID})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get last checkpoint: %w", err)
+		}
+		if err == nil && last.Live && time.Since(last.CreatedAt.Time) < liveCheckpointInterval {
+			return nil, nil
+		}
+	}
+
+	source, actor := utils.GetSource(ctx)
+	row, err := u.repo.InsertCheckpoint(ctx, db.InsertCheckpointParams{
+		CompanyID:  companyID,
+		TrackingID: trackingID,
+		Latitude:   cp.Latitude,
+		Longitude:  cp.Longitude,
+		AccuracyM:  sql.NullInt32{Int32: cp.AccuracyM, Valid: cp.AccuracyM > 0},
+		Label:      geo.Label(cp.Latitude, cp.Longitude),
+		Live:       cp.Live,
+		Source:     source,
+		Actor:      dbutil.ToNullString(actor),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	saved := checkpointFromDB(row)
+	return &saved, nil
+}
+
+// LastCheckpoint returns a shipment's last known position, or sql.ErrNoRows.
+func (u *Usecase) LastCheckpoint(ctx context.Context, companyID uuid.UUID, trackingID string) (*models.Checkpoint, error) {
+	row, err := u.repo.GetLastCheckpoint(ctx, db.GetLastCheckpointParams{CompanyID: companyID, TrackingID: trackingID})
+	if err != nil {
+		return nil, err
+	}
+	cp := checkpointFromDB(row)
+	return &cp, nil
+}
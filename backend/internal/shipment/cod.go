@@ -0,0 +1,295 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNoCOD is returned when collecting a shipment that is prepaid.
+	ErrNoCOD = errors.New("shipment has no cash on delivery")
+	// ErrCODCollected is returned when a shipment's COD was already collected.
+	ErrCODCollected = errors.New("cash on delivery already collected")
+	// ErrInvalidCOD wraps validation failures for COD amounts and ledger entries.
+	ErrInvalidCOD = errors.New("invalid cash on delivery")
+)
+
+// COD ledger entry types.
+const (
+	CodCollection = "collection"
+	CodRemittance = "remittance"
+	CodAdjustment = "adjustment"
+)
+
+// FormatCOD renders a COD amount for receipts and messages, e.g. "NGN 15,000.00".
+// It returns "" for prepaid shipments.
+func FormatCOD(amount float64, currency string) string {
+	if amount <= 0 {
+		return ""
+	}
+	return FormatMoney(amount, currency)
+}
+
+// FormatMoney renders an amount with thousands separators, e.g. "NGN -1,250.00".
+func FormatMoney(amount float64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	whole := fmt.Sprintf("%.2f", amount)
+	intPart, frac := whole[:len(whole)-3], whole[len(whole)-3:]
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString(frac)
+	if currency == "" {
+		return b.String()
+	}
+	return currency + " " + b.String()
+}
+
+// COD is the formatted cash-on-delivery amount, "" when prepaid.
+func (s *Shipment) COD() string {
+	return FormatCOD(s.CodAmount, s.CodCurrency)
+}
+
+func normalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" && !currencyRe.MatchString(currency) {
+		return "", fmt.Errorf("currency %q must be a 3-letter ISO code", currency)
+	}
+	return currency, nil
+}
+
+// codCurrency defaults the COD currency of a new shipment to the rate card's.
+func (u *Usecase) codCurrency(ctx context.Context, companyID uuid.UUID, amount float64, currency sql.NullString) sql.NullString {
+	if amount <= 0 || currency.String != "" {
+		return currency
+	}
+	if card, err := u.RateCard(ctx, companyID); err == nil {
+		return dbutil.ToNullString(card.Currency)
+	}
+	return currency
+}
+
+// SetCOD changes the amount to collect on delivery. An amount of 0 makes the shipment prepaid.
+func (u *Usecase) SetCOD(ctx context.Context, companyID uuid.UUID, trackingID string, amount float64, currency string) error {
+	if amount < 0 {
+		return fmt.Errorf("%w: amount must not be negative", ErrInvalidCOD)
+	}
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCOD, err)
+	}
+	if _, err := u.repo.GetCodCollection(ctx, db.GetCodCollectionParams{CompanyID: toNullUUID(companyID), TrackingID: dbutil.ToNullString(trackingID)}); err == nil {
+		return ErrCODCollected
+	}
+	ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to get shipment: %w", err)
+	}
+
+	err = applied(u.repo.UpdateShipmentCOD(ctx, db.UpdateShipmentCODParams{
+		CompanyID:   toNullUUID(companyID),
+		TrackingID:  trackingID,
+		CodAmount:   amount,
+		CodCurrency: u.codCurrency(ctx, companyID, amount, dbutil.ToNullString(currency)),
+		Version:     ship.Version,
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to update cod: %w", err)
+	}
+	return nil
+}
+
+// CollectCOD records that the courier took the cash for a shipment. A zero
+// amount collects the full COD amount on the shipment.
+func (u *Usecase) CollectCOD(ctx context.Context, companyID uuid.UUID, trackingID string, amount float64, reference string) (*models.CodEntry, error) {
+	ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	if ship.CodAmount <= 0 {
+		return nil, ErrNoCOD
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: amount must not be negative", ErrInvalidCOD)
+	}
+	if amount == 0 {
+		amount = ship.CodAmount
+	}
+
+	_, err = u.repo.GetCodCollection(ctx, db.GetCodCollectionParams{CompanyID: toNullUUID(companyID), TrackingID: dbutil.ToNullString(trackingID)})
+	if err == nil {
+		return nil, ErrCODCollected
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to check cod collection: %w", err)
+	}
+
+	return u.insertCodEntry(ctx, companyID, db.InsertCodEntryParams{
+		TrackingID: dbutil.ToNullString(trackingID),
+		EntryType:  CodCollection,
+		Amount:     amount,
+		Currency:   ship.CodCurrency.String,
+		Reference:  dbutil.ToNullString(reference),
+	})
+}
+
+// RecordRemittance books a payout of collected cash to the merchant.
+func (u *Usecase) RecordRemittance(ctx context.Context, companyID uuid.UUID, amount float64, currency, reference, note string) (*models.CodEntry, error) {
+	return u.recordLedgerEntry(ctx, companyID, CodRemittance, amount, currency, reference, note)
+}
+
+// RecordAdjustment books a manual correction; negative amounts reduce what the merchant is owed.
+func (u *Usecase) RecordAdjustment(ctx context.Context, companyID uuid.UUID, amount float64, currency, reference, note string) (*models.CodEntry, error) {
+	return u.recordLedgerEntry(ctx, companyID, CodAdjustment, amount, currency, reference, note)
+}
+
+func (u *Usecase) recordLedgerEntry(ctx context.Context, companyID uuid.UUID, entryType string, amount float64, currency, reference, note string) (*models.CodEntry, error) {
+	switch {
+	case entryType == CodRemittance && amount <= 0:
+		return nil, fmt.Errorf("%w: remittance amount must be positive", ErrInvalidCOD)
+	case entryType == CodAdjustment && amount == 0:
+		return nil, fmt.Errorf("%w: adjustment amount must not be zero", ErrInvalidCOD)
+	}
+	currency, err := normalizeCurrency(currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCOD, err)
+	}
+	if entryType == CodAdjustment && strings.TrimSpace(note) == "" {
+		return nil, fmt.Errorf("%w: adjustments need a note", ErrInvalidCOD)
+	}
+
+	return u.insertCodEntry(ctx, companyID, db.InsertCodEntryParams{
+		EntryType: entryType,
+		Amount:    amount,
+		Currency:  currency,
+		Reference: dbutil.ToNullString(strings.TrimSpace(reference)),
+		Note:      dbutil.ToNullString(strings.TrimSpace(note)),
+	})
+}
+
+func (u *Usecase) insertCodEntry(ctx context.Context, companyID uuid.UUID, params db.InsertCodEntryParams) (*models.CodEntry, error) {
+	_, actor := utils.GetSource(ctx)
+	params.CompanyID = toNullUUID(companyID)
+	params.Actor = dbutil.ToNullString(actor)
+
+	row, err := u.repo.InsertCodEntry(ctx, params)
+	if err != nil {
+		if dbutil.IsUniqueViolation(err) {
+			return nil, ErrCODCollected
+		}
+		return nil, fmt.Errorf("failed to record cod %s: %w", params.EntryType, err)
+	}
+	entry := codEntryFromDB(row)
+	return &entry, nil
+}
+
+func codEntryFromDB(r db.CodLedger) models.CodEntry {
+	return models.CodEntry{
+		ID:         r.ID,
+		TrackingID: r.TrackingID.String,
+		Type:       r.EntryType,
+		Amount:     r.Amount,
+		Currency:   r.Currency,
+		Reference:  r.Reference.String,
+		Note:       r.Note.String,
+		Actor:      r.Actor.String,
+		CreatedAt:  r.CreatedAt.Time,
+	}
+}
+
+// CodLedger lists ledger entries, newest first.
+func (u *Usecase) CodLedger(ctx context.Context, companyID uuid.UUID, limit, offset int32) ([]models.CodEntry, error) {
+	rows, err := u.repo.ListCodEntries(ctx, db.ListCodEntriesParams{CompanyID: toNullUUID(companyID), Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cod ledger: %w", err)
+	}
+	entries := make([]models.CodEntry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, codEntryFromDB(r))
+	}
+	return entries, nil
+}
+
+// UncollectedCOD lists delivered shipments whose cash has not been booked yet.
+func (u *Usecase) UncollectedCOD(ctx context.Context, companyID uuid.UUID, limit int32) ([]db.ListUncollectedCodRow, error) {
+	rows, err := u.repo.ListUncollectedCod(ctx, db.ListUncollectedCodParams{CompanyID: toNullUUID(companyID), Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uncollected cod: %w", err)
+	}
+	return rows, nil
+}
+
+// CodSummary reconciles ledger totals with open COD shipments per currency.
+func (u *Usecase) CodSummary(ctx context.Context, companyID uuid.UUID) (*models.CodSummary, error) {
+	ledger, err := u.repo.SumCodLedger(ctx, toNullUUID(companyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum cod ledger: %w", err)
+	}
+	open, err := u.repo.SumOpenCod(ctx, toNullUUID(companyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum open cod: %w", err)
+	}
+	return BuildCodSummary(ledger, open), nil
+}
+
+// BuildCodSummary folds the ledger and open-shipment aggregates into balances.
+func BuildCodSummary(ledger []db.SumCodLedgerRow, open []db.SumOpenCodRow) *models.CodSummary {
+	balances := map[string]*models.CodBalance{}
+	get := func(currency string) *models.CodBalance {
+		b, ok := balances[currency]
+		if !ok {
+			b = &models.CodBalance{Currency: currency}
+			balances[currency] = b
+		}
+		return b
+	}
+
+	for _, r := range ledger {
+		b := get(r.Currency)
+		switch r.EntryType {
+		case CodCollection:
+			b.Collected += r.Total
+		case CodRemittance:
+			b.Remitted += r.Total
+		case CodAdjustment:
+			b.Adjusted += r.Total
+		}
+	}
+	for _, r := range open {
+		b := get(r.Currency)
+		if r.Delivered {
+			b.Uncollected += r.Total
+			b.UncollectedCount += r.Shipments
+		} else {
+			b.Pending += r.Total
+			b.PendingCount += r.Shipments
+		}
+	}
+
+	summary := &models.CodSummary{Balances: make([]models.CodBalance, 0, len(balances))}
+	for _, b := range balances {
+		b.Owed = b.Collected + b.Adjusted - b.Remitted
+		summary.Balances = append(summary.Balances, *b)
+	}
+	sort.Slice(summary.Balances, func(i, j int) bool { return summary.Balances[i].Currency < summary.Balances[j].Currency })
+	return summary
+}
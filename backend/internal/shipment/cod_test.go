@@ -0,0 +1,47 @@
+package shipment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webtracker-bot/internal/database/db"
+)
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "NGN 15,000.00", FormatMoney(15000, "NGN"))
+	assert.Equal(t, "1,234,567.89", FormatMoney(1234567.891, ""))
+	assert.Equal(t, "USD -950.00", FormatMoney(-950, "USD"))
+	assert.Equal(t, "", FormatCOD(0, "NGN"))
+	assert.Equal(t, "GHS 99.50", FormatCOD(99.5, "GHS"))
+}
+
+func TestBuildCodSummary(t *testing.T) {
+	ledger := []db.SumCodLedgerRow{
+		{Currency: "NGN", EntryType: CodCollection, Entries: 3, Total: 60000},
+		{Currency: "NGN", EntryType: CodRemittance, Entries: 1, Total: 50000},
+		{Currency: "NGN", EntryType: CodAdjustment, Entries: 1, Total: -500},
+		{Currency: "GHS", EntryType: CodCollection, Entries: 1, Total: 300},
+	}
+	open := []db.SumOpenCodRow{
+		{Currency: "NGN", Delivered: true, Shipments: 2, Total: 25000},
+		{Currency: "NGN", Delivered: false, Shipments: 4, Total: 80000},
+		{Currency: "USD", Delivered: false, Shipments: 1, Total: 40},
+	}
+
+	s := BuildCodSummary(ledger, open)
+	require.Len(t, s.Balances, 3)
+	assert.Equal(t, []string{"GHS", "NGN", "USD"}, []string{s.Balances[0].Currency, s.Balances[1].Currency, s.Balances[2].Currency})
+
+	ngn := s.Balances[1]
+	assert.Equal(t, 9500.0, ngn.Owed)
+	assert.Equal(t, 25000.0, ngn.Uncollected)
+	assert.Equal(t, int64(2), ngn.UncollectedCount)
+	assert.Equal(t, 80000.0, ngn.Pending)
+	assert.Equal(t, int64(4), ngn.PendingCount)
+
+	assert.Equal(t, 300.0, s.Balances[0].Owed)
+	assert.Equal(t, 0.0, s.Balances[2].Owed)
+	assert.Equal(t, 40.0, s.Balances[2].Pending)
+}
@@ -0,0 +1,335 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/trackingid"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Consolidation states. Shipments are added while open; a closed bag is
+// sealed and waiting for its flight; departing sends every shipment in it
+// in transit, after which status changes on the master apply to all of them.
+const (
+	BagOpen     = "open"
+	BagClosed   = "closed"
+	BagDeparted = "departed"
+)
+
+const (
+	// masterAWBScope is the tracking_counters scope master AWBs are numbered from.
+	masterAWBScope  = "mawb"
+	maxBagLabel     = 100
+	maxBagShipments = 500
+	// DefaultBagListLimit is how many consolidations are listed without a limit.
+	DefaultBagListLimit = 50
+)
+
+var (
+	// ErrInvalidConsolidation wraps reasons a consolidation can't be changed.
+	ErrInvalidConsolidation = errors.New("invalid consolidation")
+	// ErrAlreadyConsolidated is returned for a shipment that is already in a bag.
+	ErrAlreadyConsolidated = errors.New("shipment is already in a consolidation")
+	// ErrNotConsolidated is returned for a shipment that isn't in the bag it
+	// is being taken out of.
+	ErrNotConsolidated = errors.New("shipment is not in the consolidation")
+)
+
+func consolidationFromDB(r db.Consolidation) models.Consolidation {
+	c := models.Consolidation{
+		ID:        r.ID,
+		MasterAWB: r.MasterAwb,
+		Label:     r.Label.String,
+		State:     r.State,
+		Status:    r.Status.String,
+		CreatedBy: r.CreatedBy.String,
+		CreatedAt: r.CreatedAt.Time,
+	}
+	if r.ClosedAt.Valid {
+		t := r.ClosedAt.Time
+		c.ClosedAt = &t
+	}
+	if r.DepartedAt.Valid {
+		t := r.DepartedAt.Time
+		c.DepartedAt = &t
+	}
+	return c
+}
+
+// ParseMasterAWB normalises user input for a master AWB the way
+// trackingid.Parse does for shipments.
+func ParseMasterAWB(raw string) string {
+	return strings.ToUpper(strings.Trim(strings.TrimSpace(raw), "*_~`\"'"))
+}
+
+// CreateConsolidation opens a new bag under the next master AWB, such as
+// "AWB-M000042". label is free text, typically the flight or container.
+func (u *Usecase) CreateConsolidation(ctx context.Context, companyID uuid.UUID, prefix, label string) (*models.Consolidation, error) {
+	label = strings.TrimSpace(label)
+	if len(label) > maxBagLabel {
+		return nil, fmt.Errorf("%w: label is longer than %d characters", ErrInvalidConsolidation, maxBagLabel)
+	}
+	if prefix == "" {
+		prefix = trackingid.DefaultPrefix
+	}
+	n, err := u.repo.NextTrackingSequence(ctx, db.NextTrackingSequenceParams{CompanyID: companyID, Scope: masterAWBScope})
+	if err != nil {
+		return nil, fmt.Errorf("failed to advance master AWB counter: %w", err)
+	}
+
+	_, actor := utils.GetSource(ctx)
+	row, err := u.repo.CreateConsolidation(ctx, db.CreateConsolidationParams{
+		CompanyID: companyID,
+		MasterAwb: fmt.Sprintf("%s-M%06d", prefix, n),
+		Label:     dbutil.ToNullString(label),
+		CreatedBy: dbutil.ToNullString(actor),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consolidation: %w", err)
+	}
+	c := consolidationFromDB(row)
+	c.TrackingIDs = []string{}
+	return &c, nil
+}
+
+// Consolidation returns a bag with the shipments in it, or sql.ErrNoRows.
+func (u *Usecase) Consolidation(ctx context.Context, companyID uuid.UUID, masterAWB string) (*models.Consolidation, error) {
+	row, err := u.repo.GetConsolidation(ctx, db.GetConsolidationParams{CompanyID: companyID, MasterAwb: ParseMasterAWB(masterAWB)})
+	if err != nil {
+		return nil, err
+	}
+	ids, err := u.repo.ListConsolidationShipments(ctx, db.ListConsolidationShipmentsParams{CompanyID: companyID, ConsolidationID: row.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consolidated shipments: %w", err)
+	}
+	c := consolidationFromDB(row)
+	c.TrackingIDs = append([]string{}, ids...)
+	c.ShipmentCount = len(ids)
+	return &c, nil
+}
+
+// ListConsolidations returns the company's bags, newest first.
+func (u *Usecase) ListConsolidations(ctx context.Context, companyID uuid.UUID, limit int32) ([]models.Consolidation, error) {
+	if limit <= 0 {
+		limit = DefaultBagListLimit
+	}
+	rows, err := u.repo.ListConsolidations(ctx, db.ListConsolidationsParams{CompanyID: companyID, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consolidations: %w", err)
+	}
+	bags := make([]models.Consolidation, 0, len(rows))
+	for _, r := range rows {
+		c := consolidationFromDB(db.Consolidation{
+			ID: r.ID, CompanyID: r.CompanyID, MasterAwb: r.MasterAwb, Label: r.Label, State: r.State, Status: r.Status,
+			CreatedBy: r.CreatedBy, CreatedAt: r.CreatedAt, ClosedAt: r.ClosedAt, DepartedAt: r.DepartedAt,
+		})
+		c.ShipmentCount = int(r.ShipmentCount)
+		bags = append(bags, c)
+	}
+	return bags, nil
+}
+
+// openConsolidation loads a bag that still takes shipments.
+func (u *Usecase) openConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB string) (*models.Consolidation, error) {
+	c, err := u.Consolidation(ctx, companyID, masterAWB)
+	if err != nil {
+		return nil, err
+	}
+	if c.State != BagOpen {
+		return nil, fmt.Errorf("%w: %s is %s", ErrInvalidConsolidation, c.MasterAWB, c.State)
+	}
+	return c, nil
+}
+
+// AddToConsolidation puts shipments in an open bag. Either all of them are
+// added or, when one is missing, finished or in another bag, none are.
+// Shipments already in this bag are left as they are.
+func (u *Usecase) AddToConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB string, trackingIDs []string) (*models.Consolidation, error) {
+	c, err := u.openConsolidation(ctx, companyID, masterAWB)
+	if err != nil {
+		return nil, err
+	}
+	in := make(map[string]bool, len(c.TrackingIDs))
+	for _, id := range c.TrackingIDs {
+		in[id] = true
+	}
+
+	var add []string
+	for _, id := range trackingIDs {
+		if in[id] {
+			continue
+		}
+		ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: id})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: no shipment %s", ErrInvalidConsolidation, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get shipment: %w", err)
+		}
+		if IsTerminalStatus(ship.Status.String) {
+			return nil, fmt.Errorf("%w: %s is already %s", ErrInvalidConsolidation, id, ship.Status.String)
+		}
+		other, err := u.repo.GetShipmentConsolidation(ctx, db.GetShipmentConsolidationParams{CompanyID: companyID, TrackingID: id})
+		if err == nil {
+			return nil, fmt.Errorf("%w: %s is in %s", ErrAlreadyConsolidated, id, other)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("failed to check consolidation: %w", err)
+		}
+		in[id] = true
+		add = append(add, id)
+	}
+	if len(c.TrackingIDs)+len(add) > maxBagShipments {
+		return nil, fmt.Errorf("%w: a consolidation holds at most %d shipments", ErrInvalidConsolidation, maxBagShipments)
+	}
+
+	if len(add) > 0 {
+		err := u.inTx(ctx, func(tx *Usecase) error {
+			res, err := tx.repo.AddToConsolidation(ctx, db.AddToConsolidationParams{CompanyID: companyID, Column2: add, ConsolidationID: c.ID})
+			if err != nil {
+				return fmt.Errorf("failed to add to consolidation: %w", err)
+			}
+			if n, _ := res.RowsAffected(); n != int64(len(add)) {
+				// Some were bagged elsewhere since the check above
+				return fmt.Errorf("%w: %d of %d shipments were bagged meanwhile", ErrAlreadyConsolidated, int64(len(add))-n, len(add))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	c.TrackingIDs = append(c.TrackingIDs, add...)
+	c.ShipmentCount = len(c.TrackingIDs)
+	return c, nil
+}
+
+// RemoveFromConsolidation takes a shipment out of an open bag. A shipment
+// that isn't in it returns ErrNotConsolidated.
+func (u *Usecase) RemoveFromConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB, trackingID string) error {
+	c, err := u.openConsolidation(ctx, companyID, masterAWB)
+	if err != nil {
+		return err
+	}
+	res, err := u.repo.RemoveFromConsolidation(ctx, db.RemoveFromConsolidationParams{CompanyID: companyID, ConsolidationID: c.ID, TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to remove from consolidation: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s is not in %s", ErrNotConsolidated, trackingID, c.MasterAWB)
+	}
+	return nil
+}
+
+// CloseConsolidation seals an open bag: nothing more can be added.
+func (u *Usecase) CloseConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB string) (*models.Consolidation, error) {
+	c, err := u.openConsolidation(ctx, companyID, masterAWB)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.TrackingIDs) == 0 {
+		return nil, fmt.Errorf("%w: %s is empty", ErrInvalidConsolidation, c.MasterAWB)
+	}
+	if err := applied(u.repo.CloseConsolidation(ctx, db.CloseConsolidationParams{CompanyID: companyID, ID: c.ID})); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to close consolidation: %w", err)
+	}
+	return u.Consolidation(ctx, companyID, c.MasterAWB)
+}
+
+// DepartConsolidation marks a closed bag departed and sends every shipment
+// in it in transit; see SetConsolidationStatus.
+func (u *Usecase) DepartConsolidation(ctx context.Context, companyID uuid.UUID, masterAWB string) (*models.ConsolidationResult, error) {
+	c, err := u.Consolidation(ctx, companyID, masterAWB)
+	if err != nil {
+		return nil, err
+	}
+	if c.State != BagClosed {
+		return nil, fmt.Errorf("%w: %s is %s, close it before it departs", ErrInvalidConsolidation, c.MasterAWB, c.State)
+	}
+	if err := applied(u.repo.DepartConsolidation(ctx, db.DepartConsolidationParams{CompanyID: companyID, ID: c.ID})); err != nil {
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to depart consolidation: %w", err)
+	}
+	c.State = BagDeparted
+	return u.cascadeStatus(ctx, companyID, c, StatusIntransit)
+}
+
+// SetConsolidationStatus applies a status to every shipment in a departed
+// bag, each through UpdateStatus. A bag is one physical unit, so a shipment
+// that can't make the move (delivered early, held by a rider's delivery
+// code, ...) is skipped and reported rather than failing the others.
+// Shipments already at status are left alone.
+func (u *Usecase) SetConsolidationStatus(ctx context.Context, companyID uuid.UUID, masterAWB, status string) (*models.ConsolidationResult, error) {
+	if !IsKnownStatus(status) {
+		return nil, &TransitionError{To: status}
+	}
+	c, err := u.Consolidation(ctx, companyID, masterAWB)
+	if err != nil {
+		return nil, err
+	}
+	if c.State != BagDeparted {
+		return nil, fmt.Errorf("%w: %s hasn't departed yet", ErrInvalidConsolidation, c.MasterAWB)
+	}
+	return u.cascadeStatus(ctx, companyID, c, status)
+}
+
+func (u *Usecase) cascadeStatus(ctx context.Context, companyID uuid.UUID, c *models.Consolidation, status string) (*models.ConsolidationResult, error) {
+	res := &models.ConsolidationResult{Skipped: []models.ConsolidationSkip{}}
+	for _, id := range c.TrackingIDs {
+		ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: id})
+		if err != nil {
+			res.Skipped = append(res.Skipped, models.ConsolidationSkip{TrackingID: id, Reason: skipReason(id, err)})
+			continue
+		}
+		if ship.Status.String == status {
+			continue
+		}
+		if err := u.UpdateStatus(ctx, companyID, id, status, ship.Destination.String, ship.Version); err != nil {
+			res.Skipped = append(res.Skipped, models.ConsolidationSkip{TrackingID: id, Reason: skipReason(id, err)})
+			continue
+		}
+		ship.Status = dbutil.ToNullString(status)
+		ship.Version++
+		res.Moved = append(res.Moved, ship)
+	}
+
+	if err := u.repo.SetConsolidationStatus(ctx, db.SetConsolidationStatusParams{CompanyID: companyID, ID: c.ID, Status: dbutil.ToNullString(status)}); err != nil {
+		return nil, fmt.Errorf("failed to save consolidation status: %w", err)
+	}
+	c.Status = status
+	res.Consolidation = *c
+	return res, nil
+}
+
+// skipReason explains why a shipment in a bag didn't move, without passing
+// on database errors; those are logged instead.
+func skipReason(trackingID string, err error) string {
+	var te *TransitionError
+	switch {
+	case errors.As(err, &te):
+		return fmt.Sprintf("can't go from %s to %s", te.From, te.To)
+	case errors.Is(err, ErrVersionConflict):
+		return "changed meanwhile"
+	case errors.Is(err, ErrOTPRequired):
+		return "waiting for its delivery code"
+	case errors.Is(err, sql.ErrNoRows):
+		return "no longer exists"
+	}
+	logger.Error().Err(err).Str("tracking_id", trackingID).Msg("Failed to move consolidated shipment")
+	return "could not be updated"
+}
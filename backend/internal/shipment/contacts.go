@@ -0,0 +1,213 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/parser"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidContact wraps validation failures when saving a contact.
+	ErrInvalidContact = errors.New("invalid contact")
+	// ErrContactExists is returned when another contact already has the alias or phone number.
+	ErrContactExists = errors.New("contact already exists")
+)
+
+var contactAliasRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_.\-]{0,31}$`)
+
+// minPhoneDigits keeps junk like "n/a" or "0" out of the address book.
+const minPhoneDigits = 7
+
+func contactFromDB(c db.Contact) models.Contact {
+	mc := models.Contact{
+		ID:            c.ID,
+		Alias:         c.Alias.String,
+		Name:          c.Name,
+		Phone:         c.Phone,
+		Email:         c.Email.String,
+		Address:       c.Address.String,
+		Country:       c.Country.String,
+		IDNumber:      c.IDNumber.String,
+		ShipmentCount: c.ShipmentCount,
+		CreatedAt:     c.CreatedAt.Time,
+	}
+	if c.LastUsedAt.Valid {
+		mc.LastUsedAt = &c.LastUsedAt.Time
+	}
+	return mc
+}
+
+// normalizeContact trims the contact and checks the fields a manifest needs.
+func normalizeContact(c *models.Contact) error {
+	c.Alias = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(c.Alias), "@"))
+	c.Name = strings.TrimSpace(c.Name)
+	c.Phone = strings.TrimSpace(c.Phone)
+	c.Email = strings.TrimSpace(c.Email)
+	c.Address = strings.TrimSpace(c.Address)
+	c.Country = strings.TrimSpace(c.Country)
+	c.IDNumber = strings.TrimSpace(c.IDNumber)
+
+	if c.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidContact)
+	}
+	if len(digitsOnly(c.Phone)) < minPhoneDigits {
+		return fmt.Errorf("%w: phone must have at least %d digits", ErrInvalidContact, minPhoneDigits)
+	}
+	if c.Alias != "" && !contactAliasRe.MatchString(c.Alias) {
+		return fmt.Errorf("%w: alias must be 1-32 lowercase letters, digits, '.', '_' or '-'", ErrInvalidContact)
+	}
+	return nil
+}
+
+// rememberContact saves or refreshes an address book entry from a created
+// shipment. Like status events, failures are logged but never block the
+// shipment itself. The recipient's ID number is not copied: it would outlive
+// the shipment and the retention policy that prunes it.
+func (u *Usecase) rememberContact(ctx context.Context, companyID uuid.UUID, name, phone, email, address, country sql.NullString) {
+	digits := digitsOnly(phone.String)
+	if strings.TrimSpace(name.String) == "" || len(digits) < minPhoneDigits {
+		return
+	}
+	err := u.repo.UpsertContactFromShipment(ctx, db.UpsertContactFromShipmentParams{
+		CompanyID:   companyID,
+		Name:        strings.TrimSpace(name.String),
+		Phone:       strings.TrimSpace(phone.String),
+		PhoneDigits: digits,
+		Email:       email,
+		Address:     address,
+		Country:     country,
+	})
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Failed to save contact")
+	}
+}
+
+// ListContacts returns the address book, most used first. q filters by name,
+// alias or phone number.
+func (u *Usecase) ListContacts(ctx context.Context, companyID uuid.UUID, q string, limit, offset int32) ([]models.Contact, error) {
+	params := db.ListContactsParams{CompanyID: companyID, RowLimit: limit, RowOffset: offset}
+	if q = strings.TrimPrefix(strings.TrimSpace(q), "@"); q != "" {
+		params.Pattern = containsPattern(q)
+		if digits := digitsOnly(q); len(digits) >= 3 {
+			params.Digits = "%" + digits + "%"
+		}
+	}
+	rows, err := u.repo.ListContacts(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	contacts := make([]models.Contact, 0, len(rows))
+	for _, r := range rows {
+		contacts = append(contacts, contactFromDB(r))
+	}
+	return contacts, nil
+}
+
+// GetContact returns a single contact, or sql.ErrNoRows.
+func (u *Usecase) GetContact(ctx context.Context, companyID uuid.UUID, id int32) (*models.Contact, error) {
+	row, err := u.repo.GetContact(ctx, db.GetContactParams{CompanyID: companyID, ID: id})
+	if err != nil {
+		return nil, err
+	}
+	c := contactFromDB(row)
+	return &c, nil
+}
+
+// CreateContact adds a contact by hand.
+func (u *Usecase) CreateContact(ctx context.Context, companyID uuid.UUID, c models.Contact) (*models.Contact, error) {
+	if err := normalizeContact(&c); err != nil {
+		return nil, err
+	}
+	row, err := u.repo.CreateContact(ctx, db.CreateContactParams{
+		CompanyID:   companyID,
+		Alias:       dbutil.ToNullString(c.Alias),
+		Name:        c.Name,
+		Phone:       c.Phone,
+		PhoneDigits: digitsOnly(c.Phone),
+		Email:       dbutil.ToNullString(c.Email),
+		Address:     dbutil.ToNullString(c.Address),
+		Country:     dbutil.ToNullString(c.Country),
+		IDNumber:    dbutil.ToNullString(c.IDNumber),
+	})
+	if err != nil {
+		if dbutil.IsUniqueViolation(err) {
+			return nil, ErrContactExists
+		}
+		return nil, fmt.Errorf("failed to create contact: %w", err)
+	}
+	created := contactFromDB(row)
+	return &created, nil
+}
+
+// UpdateContact replaces a contact's details. Returns sql.ErrNoRows when it doesn't exist.
+func (u *Usecase) UpdateContact(ctx context.Context, companyID uuid.UUID, id int32, c models.Contact) (*models.Contact, error) {
+	if err := normalizeContact(&c); err != nil {
+		return nil, err
+	}
+	row, err := u.repo.UpdateContact(ctx, db.UpdateContactParams{
+		CompanyID:   companyID,
+		ID:          id,
+		Alias:       dbutil.ToNullString(c.Alias),
+		Name:        c.Name,
+		Phone:       c.Phone,
+		PhoneDigits: digitsOnly(c.Phone),
+		Email:       dbutil.ToNullString(c.Email),
+		Address:     dbutil.ToNullString(c.Address),
+		Country:     dbutil.ToNullString(c.Country),
+		IDNumber:    dbutil.ToNullString(c.IDNumber),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if dbutil.IsUniqueViolation(err) {
+			return nil, ErrContactExists
+		}
+		return nil, fmt.Errorf("failed to update contact: %w", err)
+	}
+	updated := contactFromDB(row)
+	return &updated, nil
+}
+
+// DeleteContact removes a contact. Returns sql.ErrNoRows when it doesn't exist.
+func (u *Usecase) DeleteContact(ctx context.Context, companyID uuid.UUID, id int32) error {
+	res, err := u.repo.DeleteContact(ctx, db.DeleteContactParams{CompanyID: companyID, ID: id})
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ExpandContacts replaces "to: @alias" and "from: @alias" lines in a manifest
+// with the stored contact's details. Aliases with no matching contact are
+// returned so the caller can report them.
+func (u *Usecase) ExpandContacts(ctx context.Context, companyID uuid.UUID, text string) (string, []string, error) {
+	aliases := parser.ContactAliases(text)
+	if len(aliases) == 0 {
+		return text, nil, nil
+	}
+	rows, err := u.repo.GetContactsByAliases(ctx, db.GetContactsByAliasesParams{CompanyID: companyID, Column2: aliases})
+	if err != nil {
+		return text, nil, fmt.Errorf("failed to load contacts: %w", err)
+	}
+	contacts := make(map[string]models.Contact, len(rows))
+	for _, r := range rows {
+		contacts[r.Alias.String] = contactFromDB(r)
+	}
+	expanded, unknown := parser.ExpandContactRefs(text, contacts)
+	return expanded, unknown, nil
+}
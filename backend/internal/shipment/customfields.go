@@ -0,0 +1,427 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidCustomField wraps validation failures for custom field definitions and values.
+	ErrInvalidCustomField = errors.New("invalid custom field")
+	// ErrInvalidTag wraps tags that are too long or too many.
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+const (
+	maxTags      = 20
+	maxTagLength = 32
+)
+
+var customFieldNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// reservedFieldNames can't be custom fields: they are built-in shipment
+// fields or would be ambiguous in !edit.
+var reservedFieldNames = map[string]bool{
+	"tags": true, "tag": true, "status": true, "weight": true, "cost": true,
+	"sender_name": true, "sender_phone": true, "origin": true, "destination": true,
+	"recipient_name": true, "recipient_phone": true, "recipient_email": true,
+	"recipient_id": true, "recipient_address": true, "cargo_type": true,
+}
+
+func customFieldFromDB(d db.CustomFieldDefinition) models.CustomField {
+	return models.CustomField{
+		Name:     d.Name,
+		Label:    d.Label.String,
+		Type:     d.FieldType,
+		Required: d.Required,
+		Pattern:  d.Pattern.String,
+	}
+}
+
+// CustomFields returns the company's custom field definitions in creation order.
+func (u *Usecase) CustomFields(ctx context.Context, companyID uuid.UUID) ([]models.CustomField, error) {
+	rows, err := u.repo.ListCustomFieldDefinitions(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	defs := make([]models.CustomField, 0, len(rows))
+	for _, r := range rows {
+		defs = append(defs, customFieldFromDB(r))
+	}
+	return defs, nil
+}
+
+// SaveCustomField creates or replaces a definition. Values already stored on
+// shipments are left as they are.
+func (u *Usecase) SaveCustomField(ctx context.Context, companyID uuid.UUID, f models.CustomField) (*models.CustomField, error) {
+	f.Name = strings.ToLower(strings.TrimSpace(f.Name))
+	f.Label = strings.TrimSpace(f.Label)
+	f.Type = strings.ToLower(strings.TrimSpace(f.Type))
+	if f.Type == "" {
+		f.Type = models.CustomFieldText
+	}
+
+	if !customFieldNameRe.MatchString(f.Name) {
+		return nil, fmt.Errorf("%w: name must be 1-32 lowercase letters, digits or '_', starting with a letter", ErrInvalidCustomField)
+	}
+	if reservedFieldNames[f.Name] {
+		return nil, fmt.Errorf("%w: %q is a built-in field", ErrInvalidCustomField, f.Name)
+	}
+	switch f.Type {
+	case models.CustomFieldText, models.CustomFieldNumber, models.CustomFieldDate, models.CustomFieldBoolean:
+	default:
+		return nil, fmt.Errorf("%w: type must be text, number, date or boolean", ErrInvalidCustomField)
+	}
+	if f.Pattern != "" {
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return nil, fmt.Errorf("%w: pattern: %v", ErrInvalidCustomField, err)
+		}
+	}
+
+	row, err := u.repo.UpsertCustomFieldDefinition(ctx, db.UpsertCustomFieldDefinitionParams{
+		CompanyID: companyID,
+		Name:      f.Name,
+		Label:     dbutil.ToNullString(f.Label),
+		FieldType: f.Type,
+		Required:  f.Required,
+		Pattern:   dbutil.ToNullString(f.Pattern),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save custom field: %w", err)
+	}
+	saved := customFieldFromDB(row)
+	return &saved, nil
+}
+
+// DeleteCustomField removes a definition. Returns sql.ErrNoRows when it doesn't exist.
+func (u *Usecase) DeleteCustomField(ctx context.Context, companyID uuid.UUID, name string) error {
+	res, err := u.repo.DeleteCustomFieldDefinition(ctx, db.DeleteCustomFieldDefinitionParams{CompanyID: companyID, Name: name})
+	if err != nil {
+		return fmt.Errorf("failed to delete custom field: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// customFieldKey folds a name or label ("Order No.", "order-no") for lookup.
+func customFieldKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer(" ", "_", "-", "_", ".", "").Replace(s)
+	return strings.Trim(s, "_")
+}
+
+// FindCustomField looks a definition up by name or label.
+func FindCustomField(defs []models.CustomField, key string) (models.CustomField, bool) {
+	key = customFieldKey(key)
+	for _, f := range defs {
+		if f.Name == key || (f.Label != "" && customFieldKey(f.Label) == key) {
+			return f, true
+		}
+	}
+	return models.CustomField{}, false
+}
+
+// MatchCustomFields keeps the entries of raw (e.g. CSV columns) whose key names
+// a defined field, keyed by the field's name. Everything else is dropped.
+func MatchCustomFields(defs []models.CustomField, raw map[string]string) map[string]any {
+	values := make(map[string]any)
+	for k, v := range raw {
+		if f, ok := FindCustomField(defs, k); ok {
+			values[f.Name] = v
+		}
+	}
+	return values
+}
+
+// CheckCustomFields validates values against the definitions and converts
+// them to the field's type: numbers and booleans may arrive as text (CSV,
+// !edit). nil or "" means no value. Unless partial, required fields must be
+// present. The result only holds fields that have a value.
+func CheckCustomFields(defs []models.CustomField, values map[string]any, partial bool) (map[string]any, error) {
+	byName := make(map[string]models.CustomField, len(defs))
+	for _, f := range defs {
+		byName[f.Name] = f
+	}
+
+	out := make(map[string]any, len(values))
+	for name, v := range values {
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidCustomField, name)
+		}
+		cv, err := coerceCustomField(f, v)
+		if err != nil {
+			return nil, err
+		}
+		if cv != nil {
+			out[name] = cv
+		}
+	}
+	if !partial {
+		if err := checkRequired(defs, out); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func checkRequired(defs []models.CustomField, values map[string]any) error {
+	for _, f := range defs {
+		if _, ok := values[f.Name]; f.Required && !ok {
+			return fmt.Errorf("%w: %s is required", ErrInvalidCustomField, f.DisplayName())
+		}
+	}
+	return nil
+}
+
+func coerceCustomField(f models.CustomField, v any) (any, error) {
+	text := ""
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		text = strings.TrimSpace(t)
+	case float64:
+		text = strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(t)
+	case json.Number:
+		text = t.String()
+	default:
+		return nil, fmt.Errorf("%w: %s must be a %s", ErrInvalidCustomField, f.DisplayName(), f.Type)
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	if f.Pattern != "" {
+		re, err := regexp.Compile(f.Pattern)
+		if err == nil && !re.MatchString(text) {
+			return nil, fmt.Errorf("%w: %s %q doesn't match the expected format", ErrInvalidCustomField, f.DisplayName(), text)
+		}
+	}
+
+	switch f.Type {
+	case models.CustomFieldNumber:
+		n, err := strconv.ParseFloat(strings.ReplaceAll(text, ",", ""), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidCustomField, f.DisplayName())
+		}
+		return n, nil
+	case models.CustomFieldDate:
+		d, err := time.Parse(time.DateOnly, text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", ErrInvalidCustomField, f.DisplayName())
+		}
+		return d.Format(time.DateOnly), nil
+	case models.CustomFieldBoolean:
+		switch strings.ToLower(text) {
+		case "true", "yes", "y", "1":
+			return true, nil
+		case "false", "no", "n", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("%w: %s must be yes or no", ErrInvalidCustomField, f.DisplayName())
+	}
+	return text, nil
+}
+
+// NormalizeTags trims and lowercases tags, drops a leading '#' and
+// duplicates, and enforces the limits. Never returns nil.
+func NormalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), "#")))
+		if t == "" || seen[t] {
+			continue
+		}
+		if len(t) > maxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTag, t, maxTagLength)
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > maxTags {
+		return nil, fmt.Errorf("%w: at most %d tags", ErrInvalidTag, maxTags)
+	}
+	return out, nil
+}
+
+// SplitTags splits "vip, fragile; export" style input.
+func SplitTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '|' || r == '\n' })
+}
+
+// EditTags applies a !edit tag value to the current tags: "+vip -fragile"
+// style entries add and remove, anything else replaces the list.
+func EditTags(current []string, value string) []string {
+	var tokens []string
+	for _, part := range SplitTags(value) {
+		tokens = append(tokens, strings.Fields(part)...)
+	}
+	incremental := len(tokens) > 0
+	for _, t := range tokens {
+		if !strings.HasPrefix(t, "+") && !strings.HasPrefix(t, "-") {
+			incremental = false
+			break
+		}
+	}
+	if !incremental {
+		return SplitTags(value)
+	}
+
+	tags := append([]string(nil), current...)
+	for _, t := range tokens {
+		name := strings.ToLower(strings.TrimPrefix(t[1:], "#"))
+		tags = removeTag(tags, name)
+		if t[0] == '+' {
+			tags = append(tags, name)
+		}
+	}
+	return tags
+}
+
+func removeTag(tags []string, name string) []string {
+	out := tags[:0]
+	for _, t := range tags {
+		if t != name {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// EncodeCustomFields renders values for the shipment's custom_fields column.
+func EncodeCustomFields(values map[string]any) json.RawMessage {
+	if len(values) == 0 {
+		return json.RawMessage("{}")
+	}
+	raw, err := json.Marshal(values)
+	if err != nil {
+		return json.RawMessage("{}")
+	}
+	return raw
+}
+
+func decodeCustomFields(raw json.RawMessage) map[string]any {
+	values := make(map[string]any)
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &values)
+	}
+	return values
+}
+
+// jsonValue is how custom fields and tags are stored in the change history.
+func jsonValue(v any) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return dbutil.ToNullString(string(raw))
+}
+
+// CustomFieldValues validates a new shipment's custom fields against the
+// company's definitions, returning them ready for CreateShipmentParams.
+func (u *Usecase) CustomFieldValues(ctx context.Context, companyID uuid.UUID, values map[string]any) (json.RawMessage, error) {
+	defs, err := u.CustomFields(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	checked, err := CheckCustomFields(defs, values, false)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeCustomFields(checked), nil
+}
+
+// UpdateExtras merges fields into a shipment's custom fields (nil or ""
+// clears one) and, when tags is non-nil, replaces its tags. version works as
+// in UpdateFieldAt.
+func (u *Usecase) UpdateExtras(ctx context.Context, companyID uuid.UUID, trackingID string, fields map[string]any, tags []string, version int32) error {
+	current, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to get shipment: %w", err)
+	}
+	if version != 0 && current.Version != version {
+		return ErrVersionConflict
+	}
+
+	defs, err := u.CustomFields(ctx, companyID)
+	if err != nil {
+		return err
+	}
+	checked, err := CheckCustomFields(defs, fields, true)
+	if err != nil {
+		return err
+	}
+	oldValues := decodeCustomFields(current.CustomFields)
+	merged := decodeCustomFields(current.CustomFields)
+	for name := range fields {
+		if v, ok := checked[name]; ok {
+			merged[name] = v
+		} else {
+			delete(merged, name)
+		}
+	}
+	if err := checkRequired(defs, merged); err != nil {
+		return err
+	}
+
+	newTags := current.Tags
+	if tags != nil {
+		if newTags, err = NormalizeTags(tags); err != nil {
+			return err
+		}
+	}
+
+	err = applied(u.repo.UpdateShipmentExtras(ctx, db.UpdateShipmentExtrasParams{
+		CompanyID:    toNullUUID(companyID),
+		TrackingID:   trackingID,
+		CustomFields: EncodeCustomFields(merged),
+		Tags:         newTags,
+		Version:      current.Version,
+	}))
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		u.recordChange(ctx, companyID, trackingID, "cf."+name, jsonValue(oldValues[name]), jsonValue(merged[name]), false)
+	}
+	if tags != nil {
+		u.recordChange(ctx, companyID, trackingID, "tags", tagsValue(current.Tags), tagsValue(newTags), false)
+	}
+	return nil
+}
+
+func tagsValue(tags []string) sql.NullString {
+	if tags == nil {
+		tags = []string{}
+	}
+	return jsonValue(tags)
+}
@@ -0,0 +1,58 @@
+package shipment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webtracker-bot/internal/models"
+)
+
+func TestCheckCustomFields(t *testing.T) {
+	defs := []models.CustomField{
+		{Name: "order_no", Label: "Order No.", Type: models.CustomFieldText, Required: true, Pattern: `^[A-Z]{2}\d{4}$`},
+		{Name: "declared", Type: models.CustomFieldNumber},
+		{Name: "ship_by", Type: models.CustomFieldDate},
+		{Name: "insured", Type: models.CustomFieldBoolean},
+	}
+
+	values, err := CheckCustomFields(defs, map[string]any{
+		"order_no": "AB1234",
+		"declared": "1,250.50",
+		"ship_by":  "2026-11-02",
+		"insured":  "Yes",
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"order_no": "AB1234", "declared": 1250.5, "ship_by": "2026-11-02", "insured": true}, values)
+
+	_, err = CheckCustomFields(defs, map[string]any{"declared": 10.0}, false)
+	assert.ErrorIs(t, err, ErrInvalidCustomField, "order_no is required")
+
+	_, err = CheckCustomFields(defs, map[string]any{"declared": 10.0}, true)
+	assert.NoError(t, err, "partial updates skip required fields")
+
+	for name, v := range map[string]any{"order_no": "ab12", "declared": "lots", "ship_by": "02/11/2026", "insured": "maybe", "colour": "red"} {
+		_, err := CheckCustomFields(defs, map[string]any{name: v}, true)
+		assert.ErrorIs(t, err, ErrInvalidCustomField, name)
+	}
+
+	t.Run("Labels match by name or label", func(t *testing.T) {
+		values := MatchCustomFields(defs, map[string]string{"Order No": "AB1234", "Insured": "no", "notes": "x"})
+		assert.Equal(t, map[string]any{"order_no": "AB1234", "insured": "no"}, values)
+	})
+}
+
+func TestTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" VIP", "#fragile", "vip", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vip", "fragile"}, tags)
+
+	_, err = NormalizeTags([]string{"this-tag-is-far-too-long-to-be-a-useful-label"})
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	assert.Equal(t, []string{"vip", "export"}, EditTags([]string{"vip", "fragile"}, "+export -fragile"))
+	tags, err = NormalizeTags(EditTags([]string{"vip"}, "urgent, cod"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent", "cod"}, tags)
+}
@@ -0,0 +1,182 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNoCustoms is returned when a shipment has no customs declaration.
+	ErrNoCustoms = errors.New("no customs declaration")
+	// ErrInvalidCustoms wraps validation failures when saving a declaration.
+	ErrInvalidCustoms = errors.New("invalid customs declaration")
+)
+
+// Reasons for export accepted on the CN23 / commercial invoice.
+const (
+	ExportSale      = "sale"
+	ExportGift      = "gift"
+	ExportSample    = "sample"
+	ExportDocuments = "documents"
+	ExportReturn    = "return"
+	ExportOther     = "other"
+)
+
+var exportReasons = map[string]bool{
+	ExportSale: true, ExportGift: true, ExportSample: true,
+	ExportDocuments: true, ExportReturn: true, ExportOther: true,
+}
+
+// maxCustomsItems keeps the invoice printable.
+const maxCustomsItems = 99
+
+var (
+	currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+	hsCodeRe   = regexp.MustCompile(`^\d{6,10}$`)
+)
+
+// NormalizeCustoms trims the declaration, defaults the reason to a sale and
+// strips HS codes down to their digits ("6109.10" -> "610910").
+func NormalizeCustoms(d *models.CustomsDeclaration) {
+	d.Currency = strings.ToUpper(strings.TrimSpace(d.Currency))
+	d.ExportReason = strings.ToLower(strings.TrimSpace(d.ExportReason))
+	if d.ExportReason == "" {
+		d.ExportReason = ExportSale
+	}
+	d.InvoiceNumber = strings.TrimSpace(d.InvoiceNumber)
+	for i := range d.Items {
+		item := &d.Items[i]
+		item.Description = strings.TrimSpace(item.Description)
+		item.OriginCountry = strings.TrimSpace(item.OriginCountry)
+		item.HSCode = strings.NewReplacer(".", "", " ", "", "-", "").Replace(item.HSCode)
+	}
+}
+
+// ValidateCustoms checks a normalized declaration.
+func ValidateCustoms(d *models.CustomsDeclaration) error {
+	if !currencyRe.MatchString(d.Currency) {
+		return fmt.Errorf("currency %q must be a 3-letter ISO code", d.Currency)
+	}
+	if !exportReasons[d.ExportReason] {
+		return fmt.Errorf("unknown export reason %q", d.ExportReason)
+	}
+	if len(d.Items) == 0 {
+		return fmt.Errorf("at least one item line is required")
+	}
+	if len(d.Items) > maxCustomsItems {
+		return fmt.Errorf("at most %d item lines are allowed", maxCustomsItems)
+	}
+	for i, item := range d.Items {
+		line := i + 1
+		switch {
+		case item.Description == "":
+			return fmt.Errorf("line %d: description is required", line)
+		case !hsCodeRe.MatchString(item.HSCode):
+			return fmt.Errorf("line %d: HS code %q must have 6 to 10 digits", line, item.HSCode)
+		case item.Quantity <= 0:
+			return fmt.Errorf("line %d: quantity must be positive", line)
+		case item.UnitValue <= 0:
+			return fmt.Errorf("line %d: unit value must be positive", line)
+		case item.Weight < 0:
+			return fmt.Errorf("line %d: weight must not be negative", line)
+		case item.OriginCountry == "":
+			return fmt.Errorf("line %d: country of origin is required", line)
+		}
+	}
+	return nil
+}
+
+// Customs loads the declaration of a shipment. ErrNoCustoms means none was filed.
+func (u *Usecase) Customs(ctx context.Context, companyID uuid.UUID, trackingID string) (*models.CustomsDeclaration, error) {
+	header, err := u.repo.GetCustomsDeclaration(ctx, db.GetCustomsDeclarationParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoCustoms
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customs declaration: %w", err)
+	}
+	rows, err := u.repo.ListCustomsItems(ctx, db.ListCustomsItemsParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customs items: %w", err)
+	}
+
+	d := &models.CustomsDeclaration{
+		Currency:      header.Currency,
+		ExportReason:  header.ExportReason,
+		InvoiceNumber: header.InvoiceNumber.String,
+		Items:         make([]models.CustomsItem, 0, len(rows)),
+	}
+	for _, r := range rows {
+		d.Items = append(d.Items, models.CustomsItem{
+			Description:   r.Description,
+			HSCode:        r.HsCode,
+			Quantity:      int(r.Quantity),
+			UnitValue:     r.UnitValue,
+			Weight:        r.Weight,
+			OriginCountry: r.OriginCountry,
+		})
+	}
+	return d, nil
+}
+
+// SetCustoms validates and stores a shipment's declaration, replacing its item
+// lines in one transaction.
+func (u *Usecase) SetCustoms(ctx context.Context, companyID uuid.UUID, trackingID string, d *models.CustomsDeclaration) error {
+	NormalizeCustoms(d)
+	if err := ValidateCustoms(d); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCustoms, err)
+	}
+
+	return u.inTx(ctx, func(tx *Usecase) error {
+		err := tx.repo.UpsertCustomsDeclaration(ctx, db.UpsertCustomsDeclarationParams{
+			TrackingID:    trackingID,
+			CompanyID:     toNullUUID(companyID),
+			Currency:      d.Currency,
+			ExportReason:  d.ExportReason,
+			InvoiceNumber: dbutil.ToNullString(d.InvoiceNumber),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save customs declaration: %w", err)
+		}
+		if err := tx.repo.DeleteCustomsItems(ctx, db.DeleteCustomsItemsParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID}); err != nil {
+			return fmt.Errorf("failed to clear customs items: %w", err)
+		}
+		for i, item := range d.Items {
+			err := tx.repo.InsertCustomsItem(ctx, db.InsertCustomsItemParams{
+				CompanyID:     toNullUUID(companyID),
+				TrackingID:    trackingID,
+				LineNo:        int32(i + 1),
+				Description:   item.Description,
+				HsCode:        item.HSCode,
+				Quantity:      int32(item.Quantity),
+				UnitValue:     item.UnitValue,
+				Weight:        item.Weight,
+				OriginCountry: item.OriginCountry,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save customs line %d: %w", i+1, err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteCustoms removes a shipment's declaration; false when there was none.
+func (u *Usecase) DeleteCustoms(ctx context.Context, companyID uuid.UUID, trackingID string) (bool, error) {
+	res, err := u.repo.DeleteCustomsDeclaration(ctx, db.DeleteCustomsDeclarationParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete customs declaration: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
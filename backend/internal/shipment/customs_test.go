@@ -0,0 +1,53 @@
+package shipment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webtracker-bot/internal/models"
+)
+
+func TestCustomsDeclaration(t *testing.T) {
+	valid := func() models.CustomsDeclaration {
+		return models.CustomsDeclaration{
+			Currency: " usd ",
+			Items: []models.CustomsItem{
+				{Description: " Cotton T-shirts ", HSCode: "6109.10", Quantity: 4, UnitValue: 12.5, Weight: 0.8, OriginCountry: "Nigeria"},
+				{Description: "Leather shoes", HSCode: "6403 99 00", Quantity: 1, UnitValue: 60, Weight: 1.2, OriginCountry: "Italy"},
+			},
+		}
+	}
+
+	t.Run("Normalize", func(t *testing.T) {
+		d := valid()
+		NormalizeCustoms(&d)
+		require.NoError(t, ValidateCustoms(&d))
+		assert.Equal(t, "USD", d.Currency)
+		assert.Equal(t, ExportSale, d.ExportReason)
+		assert.Equal(t, "610910", d.Items[0].HSCode)
+		assert.Equal(t, "64039900", d.Items[1].HSCode)
+		assert.Equal(t, "Cotton T-shirts", d.Items[0].Description)
+		assert.Equal(t, 110.0, d.TotalValue())
+		assert.InDelta(t, 2.0, d.TotalWeight(), 1e-9)
+	})
+
+	cases := map[string]func(d *models.CustomsDeclaration){
+		"bad currency":   func(d *models.CustomsDeclaration) { d.Currency = "DOLLARS" },
+		"bad reason":     func(d *models.CustomsDeclaration) { d.ExportReason = "smuggling" },
+		"no items":       func(d *models.CustomsDeclaration) { d.Items = nil },
+		"short hs code":  func(d *models.CustomsDeclaration) { d.Items[1].HSCode = "6403" },
+		"zero quantity":  func(d *models.CustomsDeclaration) { d.Items[0].Quantity = 0 },
+		"no unit value":  func(d *models.CustomsDeclaration) { d.Items[0].UnitValue = 0 },
+		"missing origin": func(d *models.CustomsDeclaration) { d.Items[1].OriginCountry = " " },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := valid()
+			mutate(&d)
+			NormalizeCustoms(&d)
+			assert.Error(t, ValidateCustoms(&d))
+		})
+	}
+}
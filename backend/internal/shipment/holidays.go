@@ -0,0 +1,287 @@
+package shipment
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+
+	"github.com/google/uuid"
+)
+
+// BranchCalendar is the country key for holidays that close the company's own branch.
+const BranchCalendar = ""
+
+// ErrInvalidHoliday wraps malformed holiday input (bad dates, unparseable imports).
+var ErrInvalidHoliday = errors.New("invalid holiday")
+
+const holidayDateLayout = "2006-01-02"
+
+// HolidayCalendar answers whether a day is closed for the branch or a destination country.
+// A nil calendar has no holidays.
+type HolidayCalendar struct {
+	days map[string]map[string]string // country -> YYYY-MM-DD -> name
+}
+
+// NewHolidayCalendar indexes stored holidays for fast lookup.
+func NewHolidayCalendar(holidays []db.Holiday) *HolidayCalendar {
+	c := &HolidayCalendar{days: make(map[string]map[string]string)}
+	for _, h := range holidays {
+		country := NormalizeCountry(h.Country)
+		if c.days[country] == nil {
+			c.days[country] = make(map[string]string)
+		}
+		c.days[country][h.HolidayDate.Format(holidayDateLayout)] = h.Name.String
+	}
+	return c
+}
+
+// IsHoliday reports whether t's local date is a holiday for the country
+// (BranchCalendar for the company's own branch).
+func (c *HolidayCalendar) IsHoliday(country string, t time.Time) bool {
+	if c == nil {
+		return false
+	}
+	days, ok := c.days[c.countryKey(country)]
+	if !ok {
+		return false
+	}
+	_, closed := days[t.Format(holidayDateLayout)]
+	return closed
+}
+
+// countryKey matches free-text destinations ("Lagos, Nigeria") against calendar countries.
+func (c *HolidayCalendar) countryKey(country string) string {
+	country = NormalizeCountry(country)
+	if _, ok := c.days[country]; ok || country == BranchCalendar {
+		return country
+	}
+	for key := range c.days {
+		if key != BranchCalendar && strings.Contains(country, key) {
+			return key
+		}
+	}
+	return country
+}
+
+// NormalizeCountry is the canonical form of a country stored in the calendar.
+func NormalizeCountry(country string) string {
+	return strings.ToLower(strings.TrimSpace(country))
+}
+
+// HolidayEntry is a single importable holiday.
+type HolidayEntry struct {
+	Date    string `json:"date"` // YYYY-MM-DD
+	Name    string `json:"name"`
+	Country string `json:"country,omitempty"`
+}
+
+// ParseHolidays detects the format (ICS or JSON) and returns the holidays it contains.
+func ParseHolidays(data []byte) ([]HolidayEntry, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("BEGIN:VCALENDAR")) {
+		return ParseHolidaysICS(trimmed)
+	}
+	return ParseHolidaysJSON(trimmed)
+}
+
+// ParseHolidaysJSON accepts either a list of entries or {"holidays": [...]}.
+func ParseHolidaysJSON(data []byte) ([]HolidayEntry, error) {
+	var entries []HolidayEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		var wrapped struct {
+			Holidays []HolidayEntry `json:"holidays"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("%w: expected JSON list of {date, name, country} or an ICS calendar", ErrInvalidHoliday)
+		}
+		entries = wrapped.Holidays
+	}
+	for _, e := range entries {
+		if _, err := time.Parse(holidayDateLayout, e.Date); err != nil {
+			return nil, fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalidHoliday, e.Date)
+		}
+	}
+	return entries, nil
+}
+
+// ParseHolidaysICS reads all-day VEVENTs from an iCalendar file. Multi-day events
+// are expanded (DTEND is exclusive); recurrence rules are not expanded.
+func ParseHolidaysICS(data []byte) ([]HolidayEntry, error) {
+	var (
+		entries []HolidayEntry
+		inEvent bool
+		start   time.Time
+		end     time.Time
+		summary string
+	)
+
+	for _, line := range unfoldICS(data) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		prop, _, _ := strings.Cut(name, ";")
+
+		switch strings.ToUpper(prop) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent, start, end, summary = true, time.Time{}, time.Time{}, ""
+			}
+		case "DTSTART":
+			if inEvent {
+				start, _ = parseICSDate(value)
+			}
+		case "DTEND":
+			if inEvent {
+				end, _ = parseICSDate(value)
+			}
+		case "SUMMARY":
+			if inEvent {
+				summary = unescapeICS(value)
+			}
+		case "END":
+			if !inEvent || !strings.EqualFold(value, "VEVENT") {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				return nil, fmt.Errorf("%w: event %q has no valid DTSTART", ErrInvalidHoliday, summary)
+			}
+			day := start
+			for i := 0; i < maxClosedDays; i++ {
+				entries = append(entries, HolidayEntry{Date: day.Format(holidayDateLayout), Name: summary})
+				day = day.AddDate(0, 0, 1)
+				if !day.Before(end) {
+					break
+				}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// unfoldICS joins continuation lines (RFC 5545 §3.1) and strips CRs.
+func unfoldICS(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICSDate reads the calendar date from a DATE (20261225) or DATE-TIME value.
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+func unescapeICS(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(strings.TrimSpace(s))
+}
+
+// HolidayCalendar loads the holidays relevant for scheduling new shipments (the next year).
+func (u *Usecase) HolidayCalendar(ctx context.Context, companyID uuid.UUID) (*HolidayCalendar, error) {
+	from := time.Now().UTC().AddDate(0, 0, -1)
+	holidays, err := u.ListHolidays(ctx, companyID, from, from.AddDate(1, 0, maxClosedDays))
+	if err != nil {
+		return nil, err
+	}
+	return NewHolidayCalendar(holidays), nil
+}
+
+// ListHolidays returns the company's holidays between two dates (inclusive).
+func (u *Usecase) ListHolidays(ctx context.Context, companyID uuid.UUID, from, to time.Time) ([]db.Holiday, error) {
+	holidays, err := u.repo.ListHolidays(ctx, db.ListHolidaysParams{CompanyID: companyID, HolidayDate: from, HolidayDate_2: to})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holidays: %w", err)
+	}
+	return holidays, nil
+}
+
+// AddHoliday creates or renames a single holiday.
+func (u *Usecase) AddHoliday(ctx context.Context, companyID uuid.UUID, entry HolidayEntry) error {
+	date, err := time.Parse(holidayDateLayout, entry.Date)
+	if err != nil {
+		return fmt.Errorf("%w: date %q must be YYYY-MM-DD", ErrInvalidHoliday, entry.Date)
+	}
+	err = u.repo.UpsertHoliday(ctx, db.UpsertHolidayParams{
+		CompanyID:   companyID,
+		Country:     NormalizeCountry(entry.Country),
+		HolidayDate: date,
+		Name:        dbutil.ToNullString(strings.TrimSpace(entry.Name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save holiday: %w", err)
+	}
+	return nil
+}
+
+// ImportHolidays parses an ICS or JSON calendar and stores its holidays. Entries without
+// a country are assigned to the given one (BranchCalendar when empty). With replace,
+// existing holidays of every imported country are removed first. The import
+// is all or nothing: on error no holiday is added or removed.
+func (u *Usecase) ImportHolidays(ctx context.Context, companyID uuid.UUID, country string, data []byte, replace bool) (int, error) {
+	entries, err := ParseHolidays(data)
+	if err != nil {
+		return 0, err
+	}
+
+	err = u.inTx(ctx, func(tx *Usecase) error {
+		if replace {
+			cleared := make(map[string]bool)
+			for _, e := range entries {
+				c := NormalizeCountry(e.Country)
+				if c == "" {
+					c = NormalizeCountry(country)
+				}
+				if cleared[c] {
+					continue
+				}
+				cleared[c] = true
+				if _, err := tx.repo.DeleteHolidaysByCountry(ctx, db.DeleteHolidaysByCountryParams{CompanyID: companyID, Country: c}); err != nil {
+					return fmt.Errorf("failed to clear holidays: %w", err)
+				}
+			}
+		}
+
+		for _, e := range entries {
+			if e.Country == "" {
+				e.Country = country
+			}
+			if err := tx.AddHoliday(ctx, companyID, e); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// DeleteHoliday removes a single holiday; false when it did not exist.
+func (u *Usecase) DeleteHoliday(ctx context.Context, companyID uuid.UUID, id int32) (bool, error) {
+	res, err := u.repo.DeleteHoliday(ctx, db.DeleteHolidayParams{CompanyID: companyID, ID: id})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete holiday: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
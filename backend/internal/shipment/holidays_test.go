@@ -0,0 +1,60 @@
+package shipment
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webtracker-bot/internal/database/db"
+)
+
+func TestParseHolidaysICS(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20261225\r\nDTEND;VALUE=DATE:20261227\r\nSUMMARY:Christmas\\, Boxing\r\n  Day\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nDTSTART:20260320T000000Z\r\nSUMMARY:Eid al-Fitr (Sallah)\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	entries, err := ParseHolidays([]byte(ics))
+	require.NoError(t, err)
+	assert.Equal(t, []HolidayEntry{
+		{Date: "2026-12-25", Name: "Christmas, Boxing Day"},
+		{Date: "2026-12-26", Name: "Christmas, Boxing Day"},
+		{Date: "2026-03-20", Name: "Eid al-Fitr (Sallah)"},
+	}, entries)
+}
+
+func TestParseHolidaysJSON(t *testing.T) {
+	entries, err := ParseHolidays([]byte(`{"holidays": [{"date": "2026-10-01", "name": "Independence Day", "country": "Nigeria"}]}`))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "Nigeria", entries[0].Country)
+
+	_, err = ParseHolidays([]byte(`[{"date": "01/10/2026"}]`))
+	assert.ErrorIs(t, err, ErrInvalidHoliday)
+}
+
+func TestScheduleSkipsHolidays(t *testing.T) {
+	day := func(s string) time.Time { d, _ := time.Parse(holidayDateLayout, s); return d }
+	cal := NewHolidayCalendar([]db.Holiday{
+		{Country: BranchCalendar, HolidayDate: day("2026-12-25"), Name: sql.NullString{String: "Christmas", Valid: true}},
+		{Country: "germany", HolidayDate: day("2026-12-26")},
+		{Country: "germany", HolidayDate: day("2026-12-27")},
+	})
+	calc := &Calculator{Calendar: cal}
+	lagos, _ := time.LoadLocation("Africa/Lagos")
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	// Created late on Christmas Eve: the branch reopens on the 26th, not Christmas Day
+	departure := calc.CalculateDeparture(time.Date(2026, 12, 24, 22, 0, 0, 0, time.UTC), "Africa/Lagos")
+	assert.Equal(t, "2026-12-26 08:00", departure.In(lagos).Format("2006-01-02 15:04"))
+
+	// Next-day arrival lands on two German holidays and moves past both
+	arrival, ofd := calc.CalculateArrival(departure, "Nigeria", "Berlin, Germany")
+	assert.Equal(t, "2026-12-28", arrival.In(berlin).Format(holidayDateLayout))
+	assert.True(t, ofd.Before(arrival))
+
+	assert.False(t, (*HolidayCalendar)(nil).IsHoliday("germany", day("2026-12-26")))
+}
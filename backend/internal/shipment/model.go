@@ -1,13 +1,15 @@
 package shipment
 
 import (
+	"encoding/json"
 	"time"
 	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/models"
 )
 
 // ToDomain converts a database Shipment model into the domain Shipment struct.
 func ToDomain(dbShip db.Shipment) Shipment {
-	var scheduledTransit, outForDelivery, expectedDelivery *time.Time
+	var scheduledTransit, outForDelivery, expectedDelivery, deletedAt *time.Time
 	if dbShip.ScheduledTransitTime.Valid {
 		scheduledTransit = &dbShip.ScheduledTransitTime.Time
 	}
@@ -17,6 +19,13 @@ func ToDomain(dbShip db.Shipment) Shipment {
 	if dbShip.ExpectedDeliveryTime.Valid {
 		expectedDelivery = &dbShip.ExpectedDeliveryTime.Time
 	}
+	if dbShip.DeletedAt.Valid {
+		deletedAt = &dbShip.DeletedAt.Time
+	}
+	var customFields map[string]any
+	if len(dbShip.CustomFields) > 0 {
+		_ = json.Unmarshal(dbShip.CustomFields, &customFields)
+	}
 
 	return Shipment{
 		TrackingID:           dbShip.TrackingID,
@@ -40,6 +49,17 @@ func ToDomain(dbShip db.Shipment) Shipment {
 		CargoType:            dbShip.CargoType.String,
 		Weight:               dbShip.Weight.Float64,
 		Cost:                 dbShip.Cost.Float64,
+		ServiceLevel:         dbShip.ServiceLevel.String,
+		CodAmount:            dbShip.CodAmount,
+		CodCurrency:          dbShip.CodCurrency.String,
+		DeletedAt:            deletedAt,
+		DeletedBy:            dbShip.DeletedBy.String,
+		Version:              dbShip.Version,
+		CustomFields:         customFields,
+		Tags:                 dbShip.Tags,
+		ReturnOf:             dbShip.ReturnOf.String,
+		ReturnID:             dbShip.ReturnID.String,
+		ReturnReason:         dbShip.ReturnReason.String,
 	}
 }
 
@@ -50,6 +70,12 @@ const (
 	StatusOutForDelivery = "outfordelivery"
 	StatusDelivered      = "delivered"
 	StatusCanceled       = "canceled"
+
+	// Exception states (never advanced by the pulse)
+	StatusOnHold         = "on_hold"
+	StatusCustomsHold    = "customs_hold"
+	StatusDeliveryFailed = "delivery_failed"
+	StatusReturned       = "returned"
 )
 
 // Shipment represents the core data model for a package
@@ -84,12 +110,40 @@ type Shipment struct {
 	CargoType        string  `json:"cargo_type"`
 	Weight           float64 `json:"weight"`
 	Cost             float64 `json:"cost"`
+	ServiceLevel     string  `json:"service_level"`
+
+	// Cash on delivery to collect from the recipient (0 = prepaid)
+	CodAmount   float64 `json:"cod_amount"`
+	CodCurrency string  `json:"cod_currency"`
+
+	// Set while the shipment sits in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty"`
+
+	// Individual boxes (empty for single-box shipments)
+	Pieces []models.Piece `json:"pieces,omitempty"`
+
+	// Last known position, loaded on demand like Pieces
+	LastCheckpoint *models.Checkpoint `json:"last_checkpoint,omitempty"`
+
+	// Bumped on every write; the API exposes it as the ETag
+	Version int32 `json:"version"`
+
+	// Company-defined attributes (keyed by custom field name) and free-form labels
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+	Tags         []string       `json:"tags,omitempty"`
+
+	// Return-to-origin links: ReturnOf is set on a return shipment, ReturnID
+	// on the original it sends back
+	ReturnOf     string `json:"return_of,omitempty"`
+	ReturnID     string `json:"return_id,omitempty"`
+	ReturnReason string `json:"return_reason,omitempty"`
 }
 
 // ResolveStatus returns what the status *should* be right now based on the schedule.
 func (s *Shipment) ResolveStatus(nowUTC time.Time) string {
-	if s.Status == StatusCanceled {
-		return StatusCanceled
+	if IsTerminalStatus(s.Status) || IsExceptionStatus(s.Status) {
+		return s.Status
 	}
 	if s.ExpectedDeliveryTime != nil && !nowUTC.Before(*s.ExpectedDeliveryTime) {
 		return StatusDelivered
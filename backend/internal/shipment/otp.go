@@ -0,0 +1,172 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// MaxOTPAttempts is how many wrong delivery codes lock a shipment until a
+// new code is sent.
+const MaxOTPAttempts = 5
+
+var (
+	// ErrOTPRequired is returned when a shipment can only be delivered with
+	// the recipient's delivery code.
+	ErrOTPRequired = errors.New("delivery code required")
+	// ErrWrongOTP is returned for a delivery code that doesn't match.
+	ErrWrongOTP = errors.New("wrong delivery code")
+	// ErrOTPLocked is returned once MaxOTPAttempts wrong codes were entered.
+	ErrOTPLocked = errors.New("too many wrong delivery codes")
+	// ErrOTPDisabled is returned when no way to send delivery codes is configured.
+	ErrOTPDisabled = errors.New("delivery codes can't be sent")
+)
+
+// DeliveryOTPSender hands a delivery code to the shipment's recipient.
+type DeliveryOTPSender func(ctx context.Context, companyID uuid.UUID, ship db.Shipment, code string)
+
+// requiresOTP reports whether the company's policy asks for a delivery code
+// on ship, going by the larger of its COD amount and declared customs value.
+func (u *Usecase) requiresOTP(ctx context.Context, companyID uuid.UUID, ship db.Shipment) bool {
+	policy, err := u.AttemptPolicy(ctx, companyID)
+	if err != nil {
+		logger.Warn().Err(err).Str("tracking_id", ship.TrackingID).Msg("Failed to load delivery policy, not issuing a delivery code")
+		return false
+	}
+	if policy.OTPMinValue <= 0 {
+		return false
+	}
+	value := ship.CodAmount
+	if d, err := u.Customs(ctx, companyID, ship.TrackingID); err == nil && d.TotalValue() > value {
+		value = d.TotalValue()
+	}
+	return value >= policy.OTPMinValue
+}
+
+// issueDeliveryOTP sends a delivery code for a shipment that just went out
+// for delivery, if its value calls for one. Failures are logged: the status
+// change has already happened.
+func (u *Usecase) issueDeliveryOTP(ctx context.Context, companyID uuid.UUID, ship db.Shipment) {
+	if u.SendOTP == nil || !u.requiresOTP(ctx, companyID, ship) {
+		return
+	}
+	if err := u.sendDeliveryOTP(ctx, companyID, ship); err != nil {
+		logger.Error().Err(err).Str("tracking_id", ship.TrackingID).Msg("Failed to issue delivery code")
+	}
+}
+
+// sendDeliveryOTP stores a new code for ship, replacing any earlier one, and
+// sends it to the recipient. Codes are generated and hashed like the sign-up
+// OTPs in auth.Service; only the hash is kept.
+func (u *Usecase) sendDeliveryOTP(ctx context.Context, companyID uuid.UUID, ship db.Shipment) error {
+	code, hash, err := utils.NewOTP()
+	if err != nil {
+		return err
+	}
+	err = u.repo.IssueDeliveryOTP(ctx, db.IssueDeliveryOTPParams{CompanyID: companyID, TrackingID: ship.TrackingID, CodeHash: hash})
+	if err != nil {
+		return fmt.Errorf("failed to save delivery code: %w", err)
+	}
+	u.SendOTP(ctx, companyID, ship, code)
+	return nil
+}
+
+// ResendDeliveryOTP replaces a shipment's pending delivery code with a new
+// one and sends it again, clearing earlier wrong attempts. Shipments without
+// a pending code return sql.ErrNoRows.
+func (u *Usecase) ResendDeliveryOTP(ctx context.Context, companyID uuid.UUID, trackingID string) error {
+	if u.SendOTP == nil {
+		return ErrOTPDisabled
+	}
+	otp, err := u.repo.GetDeliveryOTP(ctx, db.GetDeliveryOTPParams{CompanyID: companyID, TrackingID: trackingID})
+	if err != nil {
+		return err
+	}
+	if otp.ConfirmedAt.Valid {
+		return sql.ErrNoRows
+	}
+	ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return u.sendDeliveryOTP(ctx, companyID, ship)
+}
+
+// checkDeliveryOTP returns ErrOTPRequired while the shipment has a delivery
+// code that hasn't been confirmed, so the only way to delivered is
+// RiderDeliver with the code.
+func (u *Usecase) checkDeliveryOTP(ctx context.Context, companyID uuid.UUID, trackingID string) error {
+	otp, err := u.repo.GetDeliveryOTP(ctx, db.GetDeliveryOTPParams{CompanyID: companyID, TrackingID: trackingID})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get delivery code: %w", err)
+	}
+	if !otp.ConfirmedAt.Valid {
+		return fmt.Errorf("%w: %s", ErrOTPRequired, trackingID)
+	}
+	return nil
+}
+
+// verifyDeliveryOTP checks code against the shipment's pending delivery
+// code, if it has one, and reports whether there is one to confirm once the
+// shipment is delivered. Each check takes an attempt up front, so concurrent
+// guesses can't get past MaxOTPAttempts; a matching code gives it back.
+// Wrong codes are logged to the shipment timeline.
+func (u *Usecase) verifyDeliveryOTP(ctx context.Context, companyID uuid.UUID, trackingID, code string) (bool, error) {
+	key := db.GetDeliveryOTPParams{CompanyID: companyID, TrackingID: trackingID}
+	otp, err := u.repo.GetDeliveryOTP(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get delivery code: %w", err)
+	}
+	if otp.ConfirmedAt.Valid {
+		return false, nil
+	}
+	if otp.FailedAttempts >= MaxOTPAttempts {
+		return false, fmt.Errorf("%w: %s", ErrOTPLocked, trackingID)
+	}
+	if code == "" {
+		return false, fmt.Errorf("%w: %s", ErrOTPRequired, trackingID)
+	}
+
+	used, err := u.repo.ReserveDeliveryOTPAttempt(ctx, db.ReserveDeliveryOTPAttemptParams{
+		CompanyID:      companyID,
+		TrackingID:     trackingID,
+		FailedAttempts: MaxOTPAttempts,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("%w: %s", ErrOTPLocked, trackingID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to record delivery code attempt: %w", err)
+	}
+
+	if !utils.MatchOTP(otp.CodeHash, code) {
+		status := StatusOutForDelivery
+		if ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID}); err == nil {
+			status = ship.Status.String
+		}
+		u.recordTimelineNote(ctx, companyID, trackingID, status,
+			fmt.Sprintf("Wrong delivery code entered (attempt %d of %d)", used, MaxOTPAttempts))
+		if used >= MaxOTPAttempts {
+			return false, fmt.Errorf("%w: %s", ErrOTPLocked, trackingID)
+		}
+		return false, fmt.Errorf("%w: %d attempts left", ErrWrongOTP, MaxOTPAttempts-used)
+	}
+
+	if err := u.repo.ReleaseDeliveryOTPAttempt(ctx, db.ReleaseDeliveryOTPAttemptParams(key)); err != nil {
+		logger.Warn().Err(err).Str("tracking_id", trackingID).Msg("Failed to release delivery code attempt")
+	}
+	return true, nil
+}
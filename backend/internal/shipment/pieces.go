@@ -0,0 +1,85 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// PiecesFromDB converts stored pieces (ordered by piece number) into the shared model.
+func PiecesFromDB(rows []db.ShipmentPiece) []models.Piece {
+	pieces := make([]models.Piece, 0, len(rows))
+	for _, r := range rows {
+		pieces = append(pieces, models.Piece{
+			Weight:      r.Weight,
+			LengthCm:    r.LengthCm,
+			WidthCm:     r.WidthCm,
+			HeightCm:    r.HeightCm,
+			Description: r.Description.String,
+		})
+	}
+	return pieces
+}
+
+// PieceCount is the number of boxes under the airway bill; shipments without
+// recorded pieces count as one.
+func (s *Shipment) PieceCount() int {
+	if len(s.Pieces) == 0 {
+		return 1
+	}
+	return len(s.Pieces)
+}
+
+// VolumetricWeight derives the volumetric weight from the piece dimensions.
+func (s *Shipment) VolumetricWeight() float64 {
+	return roundTo(models.TotalVolumetricWeight(s.Pieces, DefaultVolumetricDivisor), 2)
+}
+
+// ChargeableWeight is the greater of the actual and volumetric weight.
+func (s *Shipment) ChargeableWeight() float64 {
+	if v := s.VolumetricWeight(); v > s.Weight {
+		return v
+	}
+	return s.Weight
+}
+
+// SavePieces replaces the recorded pieces of a shipment in one transaction.
+func (u *Usecase) SavePieces(ctx context.Context, companyID uuid.UUID, trackingID string, pieces []models.Piece) error {
+	return u.inTx(ctx, func(tx *Usecase) error {
+		err := tx.repo.DeleteShipmentPieces(ctx, db.DeleteShipmentPiecesParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+		if err != nil {
+			return fmt.Errorf("failed to clear pieces: %w", err)
+		}
+		for i, p := range pieces {
+			err := tx.repo.InsertShipmentPiece(ctx, db.InsertShipmentPieceParams{
+				CompanyID:   toNullUUID(companyID),
+				TrackingID:  trackingID,
+				PieceNo:     int32(i + 1),
+				Weight:      p.Weight,
+				LengthCm:    p.LengthCm,
+				WidthCm:     p.WidthCm,
+				HeightCm:    p.HeightCm,
+				Description: dbutil.ToNullString(strings.TrimSpace(p.Description)),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save piece %d: %w", i+1, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListPieces returns the recorded pieces of a shipment (empty for single-box shipments).
+func (u *Usecase) ListPieces(ctx context.Context, companyID uuid.UUID, trackingID string) ([]models.Piece, error) {
+	rows, err := u.repo.ListShipmentPieces(ctx, db.ListShipmentPiecesParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pieces: %w", err)
+	}
+	return PiecesFromDB(rows), nil
+}
@@ -0,0 +1,177 @@
+package shipment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// Proof-of-delivery kinds
+const (
+	PODPhoto     = "photo"
+	PODSignature = "signature"
+)
+
+// MaxPODBytes caps a single proof-of-delivery image.
+const MaxPODBytes = 10 << 20
+
+var (
+	// ErrInvalidPOD is returned for uploads that aren't a usable image.
+	ErrInvalidPOD = errors.New("invalid proof of delivery")
+	// ErrPODDisabled is returned when no blob storage is configured.
+	ErrPODDisabled = errors.New("proof of delivery storage is not configured")
+)
+
+// podTypes are the image formats accepted as POD, by sniffed content type,
+// with the extension they are stored under.
+var podTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+func podFromDB(r db.ShipmentPod) models.POD {
+	return models.POD{
+		ID:          r.ID,
+		TrackingID:  r.TrackingID,
+		Kind:        r.Kind,
+		ContentType: r.ContentType,
+		SizeBytes:   r.SizeBytes,
+		Source:      r.Source,
+		Actor:       r.Actor.String,
+		CreatedAt:   r.CreatedAt.Time,
+	}
+}
+
+// RecordPOD stores a proof-of-delivery image and links it to the shipment,
+// marking the shipment delivered if it isn't yet. A shipment that can't be
+// delivered from its current status, is waiting for its delivery code, or
+// loses a race to another status change is left alone and nothing is
+// stored. kind defaults to a photo; rider works as in RiderDeliver.
+func (u *Usecase) RecordPOD(ctx context.Context, companyID uuid.UUID, rider *models.Rider, trackingID, kind string, data []byte) (*models.PODResult, error) {
+	if u.Blobs == nil {
+		return nil, ErrPODDisabled
+	}
+	if kind == "" {
+		kind = PODPhoto
+	}
+	if kind != PODPhoto && kind != PODSignature {
+		return nil, fmt.Errorf("%w: kind must be %s or %s", ErrInvalidPOD, PODPhoto, PODSignature)
+	}
+	if len(data) == 0 || len(data) > MaxPODBytes {
+		return nil, fmt.Errorf("%w: image must be between 1 byte and %d MB", ErrInvalidPOD, MaxPODBytes>>20)
+	}
+	contentType := http.DetectContentType(data)
+	ext, ok := podTypes[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not a supported image type", ErrInvalidPOD, contentType)
+	}
+
+	if err := u.checkRider(ctx, companyID, rider, trackingID); err != nil {
+		return nil, err
+	}
+	ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	delivering := ship.Status.String != StatusDelivered
+	if delivering {
+		if err := ValidateTransition(trackingID, ship.Status.String, StatusDelivered); err != nil {
+			return nil, err
+		}
+		if err := u.checkDeliveryOTP(ctx, companyID, trackingID); err != nil {
+			return nil, err
+		}
+	}
+
+	key := fmt.Sprintf("%s/pod/%s%s", companyID, uuid.NewString(), ext)
+	if err := u.Blobs.Put(ctx, key, data); err != nil {
+		return nil, fmt.Errorf("failed to store proof of delivery: %w", err)
+	}
+	source, actor := utils.GetSource(ctx)
+	res := &models.PODResult{Shipment: ship}
+	// The row and the status change commit together, so a POD never stays
+	// attached to a shipment that lost the race to be delivered
+	err = u.inTx(ctx, func(tx *Usecase) error {
+		row, err := tx.repo.InsertPOD(ctx, db.InsertPODParams{
+			CompanyID:   companyID,
+			TrackingID:  trackingID,
+			Kind:        kind,
+			BlobKey:     key,
+			ContentType: contentType,
+			SizeBytes:   int64(len(data)),
+			Source:      source,
+			Actor:       dbutil.ToNullString(actor),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save proof of delivery: %w", err)
+		}
+		res.POD = podFromDB(row)
+		if delivering {
+			updated, err := tx.riderTransition(ctx, companyID, trackingID, StatusDelivered)
+			if err != nil {
+				return err
+			}
+			res.Shipment, res.Delivered = *updated, true
+		}
+		return nil
+	})
+	if err != nil {
+		if derr := u.Blobs.Delete(ctx, key); derr != nil {
+			logger.Warn().Err(derr).Str("key", key).Msg("Failed to remove orphaned proof of delivery")
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+// deletePODBlobs removes the images behind purged proof-of-delivery rows. A
+// failure only leaves an orphaned file, so it is logged rather than returned.
+func (u *Usecase) deletePODBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := u.Blobs.Delete(ctx, key); err != nil {
+			logger.Warn().Err(err).Str("key", key).Msg("Failed to remove purged proof of delivery")
+		}
+	}
+}
+
+// ListPODs returns a shipment's proof-of-delivery images, oldest first.
+func (u *Usecase) ListPODs(ctx context.Context, companyID uuid.UUID, trackingID string) ([]models.POD, error) {
+	rows, err := u.repo.ListPODs(ctx, db.ListPODsParams{CompanyID: companyID, TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proof of delivery: %w", err)
+	}
+	pods := make([]models.POD, 0, len(rows))
+	for _, r := range rows {
+		pods = append(pods, podFromDB(r))
+	}
+	return pods, nil
+}
+
+// OpenPOD opens one proof-of-delivery image for reading. The caller closes
+// the reader.
+func (u *Usecase) OpenPOD(ctx context.Context, companyID uuid.UUID, trackingID string, id int32) (*models.POD, io.ReadCloser, error) {
+	if u.Blobs == nil {
+		return nil, nil, ErrPODDisabled
+	}
+	row, err := u.repo.GetPOD(ctx, db.GetPODParams{CompanyID: companyID, TrackingID: trackingID, ID: id})
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := u.Blobs.Open(ctx, row.BlobKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open proof of delivery: %w", err)
+	}
+	pod := podFromDB(row)
+	return &pod, r, nil
+}
@@ -0,0 +1,152 @@
+package shipment
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PhoneVerifyDigits is how many trailing phone digits a recipient enters to unlock full details.
+const PhoneVerifyDigits = 4
+
+// ErrPhoneMismatch is returned when the digits entered don't match the recipient's phone.
+var ErrPhoneMismatch = errors.New("phone verification failed")
+
+// PublicShipment is what anonymous callers of the tracking page see. Contact
+// details are masked and the address is cut down to the city unless the
+// recipient has verified their phone.
+type PublicShipment struct {
+	TrackingID           string     `json:"tracking_id"`
+	Status               string     `json:"status"`
+	ServiceLevel         string     `json:"service_level,omitempty"`
+	Origin               string     `json:"origin"`
+	Destination          string     `json:"destination"`
+	CreatedAt            time.Time  `json:"created_at"`
+	ExpectedDeliveryTime *time.Time `json:"expected_delivery_time,omitempty"`
+	CargoType            string     `json:"cargo_type,omitempty"`
+	Weight               float64    `json:"weight"`
+	Pieces               int        `json:"pieces"`
+
+	SenderName     string `json:"sender_name"`
+	RecipientName  string `json:"recipient_name"`
+	RecipientPhone string `json:"recipient_phone"`
+	RecipientCity  string `json:"recipient_city,omitempty"`
+
+	// Only filled in once the recipient has verified their phone
+	Verified         bool    `json:"verified"`
+	RecipientEmail   string  `json:"recipient_email,omitempty"`
+	RecipientAddress string  `json:"recipient_address,omitempty"`
+	RecipientID      string  `json:"recipient_id,omitempty"`
+	CodAmount        float64 `json:"cod_amount,omitempty"`
+	CodCurrency      string  `json:"cod_currency,omitempty"`
+
+	Timeline []TimelineEvent `json:"timeline"`
+}
+
+// PublicTrack returns the public view of a shipment with its timeline.
+// When phoneDigits is set it must match the last PhoneVerifyDigits digits of
+// the recipient's phone, and the full details are returned.
+func (u *Usecase) PublicTrack(ctx context.Context, trackingID, phoneDigits string) (*PublicShipment, error) {
+	dbShip, err := u.repo.GetShipmentByTrackingID(ctx, trackingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	verified := false
+	if phoneDigits != "" {
+		if !VerifyPhone(dbShip.RecipientPhone.String, phoneDigits) {
+			return nil, ErrPhoneMismatch
+		}
+		verified = true
+	}
+
+	timeline, err := u.buildTimeline(ctx, dbShip, true)
+	if err != nil {
+		return nil, err
+	}
+	pieces, err := u.ListPieces(ctx, dbShip.CompanyID.UUID, dbShip.TrackingID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := ToDomain(dbShip)
+	p := &PublicShipment{
+		TrackingID:           s.TrackingID,
+		Status:               s.Status,
+		ServiceLevel:         s.ServiceLevel,
+		Origin:               s.Origin,
+		Destination:          s.Destination,
+		CreatedAt:            s.CreatedAt,
+		ExpectedDeliveryTime: s.ExpectedDeliveryTime,
+		CargoType:            s.CargoType,
+		Weight:               s.Weight,
+		Pieces:               max(len(pieces), 1),
+		SenderName:           RedactName(s.SenderName),
+		RecipientName:        RedactName(s.RecipientName),
+		RecipientPhone:       MaskPhone(s.RecipientPhone),
+		RecipientCity:        CityFromAddress(s.RecipientAddress, s.Destination),
+		Timeline:             timeline,
+	}
+	if verified {
+		p.Verified = true
+		p.RecipientName = s.RecipientName
+		p.RecipientPhone = s.RecipientPhone
+		p.RecipientEmail = s.RecipientEmail
+		p.RecipientAddress = s.RecipientAddress
+		p.RecipientID = s.RecipientID
+		p.CodAmount = s.CodAmount
+		p.CodCurrency = s.CodCurrency
+	}
+	return p, nil
+}
+
+// VerifyPhone reports whether digits are the last PhoneVerifyDigits digits of phone.
+func VerifyPhone(phone, digits string) bool {
+	stored := digitsOnly(phone)
+	if len(digits) != PhoneVerifyDigits || len(stored) < PhoneVerifyDigits {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(stored[len(stored)-PhoneVerifyDigits:]), []byte(digits)) == 1
+}
+
+// MaskPhone hides every digit: "+351 912 345 678" → "************". The
+// trailing digits are what VerifyPhone checks, so none of them may show.
+func MaskPhone(phone string) string {
+	return strings.Repeat("*", len(digitsOnly(phone)))
+}
+
+// RedactName keeps the first two letters of the first name, like the tracking page does.
+func RedactName(name string) string {
+	first, _, _ := strings.Cut(strings.TrimSpace(name), " ")
+	if first == "" {
+		return ""
+	}
+	r := []rune(first)
+	if len(r) <= 2 {
+		return first + "***"
+	}
+	return string(r[:2]) + "******"
+}
+
+// CityFromAddress picks the city out of a free-text address: the last
+// comma-separated part once postcodes are dropped, skipping the country.
+func CityFromAddress(address, country string) string {
+	parts := strings.FieldsFunc(address, func(r rune) bool { return r == ',' || r == '\n' })
+	for i := len(parts) - 1; i >= 0; i-- {
+		var words []string
+		for _, w := range strings.Fields(parts[i]) {
+			if !strings.ContainsFunc(w, unicode.IsDigit) {
+				words = append(words, w)
+			}
+		}
+		city := strings.Join(words, " ")
+		if city == "" || strings.EqualFold(city, country) {
+			continue
+		}
+		return city
+	}
+	return ""
+}
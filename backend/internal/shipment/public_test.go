@@ -0,0 +1,26 @@
+package shipment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicRedaction(t *testing.T) {
+	assert.Equal(t, "************", MaskPhone("+351 912-345-678"))
+	assert.Equal(t, "***", MaskPhone("123"))
+	assert.Equal(t, "", MaskPhone(""))
+
+	assert.True(t, VerifyPhone("+351 912-345-678", "5678"))
+	assert.False(t, VerifyPhone("+351 912-345-678", "4567"))
+	assert.False(t, VerifyPhone("+351 912-345-678", "678"))
+	assert.False(t, VerifyPhone("", "0000"))
+
+	assert.Equal(t, "Jo******", RedactName("João Silva"))
+	assert.Equal(t, "Al***", RedactName("Al Smith"))
+	assert.Equal(t, "", RedactName("  "))
+
+	assert.Equal(t, "Lisboa", CityFromAddress("Rua Augusta 100, 1100-053 Lisboa", "Portugal"))
+	assert.Equal(t, "Lagos", CityFromAddress("12 Marina Road,\nLagos, Nigeria", "Nigeria"))
+	assert.Equal(t, "", CityFromAddress("100", "Portugal"))
+}
@@ -0,0 +1,312 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// RateCardKey is the SystemConfig key holding a company's rate card (JSON).
+const RateCardKey = "rate_card"
+
+// DefaultVolumetricDivisor is the industry-standard cm³/kg divisor for air freight.
+const DefaultVolumetricDivisor = 5000
+
+var (
+	// ErrNoRateCard is returned when a company has not configured pricing.
+	ErrNoRateCard = errors.New("no rate card configured")
+	// ErrInvalidRateCard wraps validation failures when saving a rate card.
+	ErrInvalidRateCard = errors.New("invalid rate card")
+	// ErrNoRate is returned when no lane covers the requested origin/destination.
+	ErrNoRate = errors.New("no rate for this lane")
+)
+
+// RateCard is a company's price list: per-lane weight bands plus surcharges.
+type RateCard struct {
+	Currency string `json:"currency"`
+	// Zones maps a zone name to the countries it contains.
+	Zones map[string][]string `json:"zones,omitempty"`
+	// VolumetricDivisor converts L×W×H (cm) into volumetric kilograms.
+	VolumetricDivisor float64 `json:"volumetric_divisor"`
+	// FuelSurchargePct is applied on top of the lane price (e.g. 12.5 for 12.5%).
+	FuelSurchargePct float64 `json:"fuel_surcharge_pct"`
+	// MinimumCharge is the floor for any priced shipment.
+	MinimumCharge float64 `json:"minimum_charge"`
+	// CargoMultipliers adjust the price when the cargo type contains the key (e.g. "fragile": 1.2).
+	CargoMultipliers map[string]float64 `json:"cargo_multipliers,omitempty"`
+	Lanes            []RateLane         `json:"lanes"`
+}
+
+// RateLane prices shipments between an origin and destination zone (AnyZone matches all).
+type RateLane struct {
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Bands []WeightBand `json:"bands"`
+}
+
+// WeightBand prices chargeable weights up to MaxKg (0 means no upper bound):
+// Price is a flat amount and PerKg is charged for every chargeable kilogram.
+type WeightBand struct {
+	MaxKg float64 `json:"max_kg"`
+	Price float64 `json:"price"`
+	PerKg float64 `json:"per_kg"`
+}
+
+// normalize fills defaults and sorts the weight bands ascending (open-ended last).
+func (r *RateCard) normalize() {
+	if r.VolumetricDivisor == 0 {
+		r.VolumetricDivisor = DefaultVolumetricDivisor
+	}
+	r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+	for i := range r.Lanes {
+		bands := r.Lanes[i].Bands
+		sort.SliceStable(bands, func(a, b int) bool {
+			if bands[a].MaxKg == 0 || bands[b].MaxKg == 0 {
+				return bands[b].MaxKg == 0 && bands[a].MaxKg != 0
+			}
+			return bands[a].MaxKg < bands[b].MaxKg
+		})
+	}
+	multipliers := make(map[string]float64, len(r.CargoMultipliers))
+	for k, v := range r.CargoMultipliers {
+		multipliers[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	r.CargoMultipliers = multipliers
+}
+
+// Validate checks that the rate card can price shipments.
+func (r *RateCard) Validate() error {
+	if r.Currency == "" {
+		return fmt.Errorf("currency is required")
+	}
+	if r.VolumetricDivisor <= 0 {
+		return fmt.Errorf("volumetric_divisor must be positive")
+	}
+	if r.FuelSurchargePct < 0 || r.MinimumCharge < 0 {
+		return fmt.Errorf("fuel_surcharge_pct and minimum_charge must not be negative")
+	}
+	for k, v := range r.CargoMultipliers {
+		if v <= 0 {
+			return fmt.Errorf("cargo multiplier for %q must be positive", k)
+		}
+	}
+	if len(r.Lanes) == 0 {
+		return fmt.Errorf("at least one lane is required")
+	}
+	for _, l := range r.Lanes {
+		for _, z := range []string{l.From, l.To} {
+			if _, ok := r.Zones[z]; z != AnyZone && !ok {
+				return fmt.Errorf("lane %s -> %s references unknown zone %q", l.From, l.To, z)
+			}
+		}
+		if len(l.Bands) == 0 {
+			return fmt.Errorf("lane %s -> %s has no weight bands", l.From, l.To)
+		}
+		for i, b := range l.Bands {
+			if b.MaxKg < 0 || b.Price < 0 || b.PerKg < 0 {
+				return fmt.Errorf("lane %s -> %s has negative band values", l.From, l.To)
+			}
+			if b.MaxKg == 0 && i != len(l.Bands)-1 {
+				return fmt.Errorf("lane %s -> %s has more than one open-ended band", l.From, l.To)
+			}
+		}
+	}
+	return nil
+}
+
+// ZoneOf returns the pricing zone a country belongs to, or "" when it is not zoned.
+func (r *RateCard) ZoneOf(country string) string {
+	return zoneOf(r.Zones, country)
+}
+
+// lane returns the most specific lane for the zone pair.
+func (r *RateCard) lane(from, to string) (RateLane, bool) {
+	best, found := -1, RateLane{}
+	for _, l := range r.Lanes {
+		score := 0
+		switch {
+		case l.From == from && from != "":
+			score += 2
+		case l.From != AnyZone:
+			continue
+		}
+		switch {
+		case l.To == to && to != "":
+			score++
+		case l.To != AnyZone:
+			continue
+		}
+		if score > best {
+			best, found = score, l
+		}
+	}
+	return found, best >= 0
+}
+
+// Price computes an itemised quote. The chargeable weight is the greater of the
+// actual and volumetric weight; the minimum charge applies after surcharges.
+func (r *RateCard) Price(req models.QuoteRequest) (*models.Quote, error) {
+	q := &models.Quote{
+		Origin:          req.Origin,
+		Destination:     req.Destination,
+		OriginZone:      r.ZoneOf(req.Origin),
+		DestinationZone: r.ZoneOf(req.Destination),
+		ActualWeight:    req.Weight,
+		Currency:        r.Currency,
+	}
+	lane, ok := r.lane(q.OriginZone, q.DestinationZone)
+	if !ok {
+		return nil, ErrNoRate
+	}
+
+	if len(req.Pieces) > 0 {
+		q.ActualWeight = math.Max(q.ActualWeight, models.TotalWeight(req.Pieces))
+		q.VolumetricWeight = roundTo(models.TotalVolumetricWeight(req.Pieces, r.VolumetricDivisor), 2)
+	} else {
+		q.VolumetricWeight = roundTo(req.LengthCm*req.WidthCm*req.HeightCm/r.VolumetricDivisor, 2)
+	}
+	q.ChargeableWeight = math.Max(q.ActualWeight, q.VolumetricWeight)
+
+	band := lane.Bands[len(lane.Bands)-1]
+	for _, b := range lane.Bands {
+		if b.MaxKg == 0 || q.ChargeableWeight <= b.MaxKg {
+			band = b
+			break
+		}
+	}
+	q.Base = roundTo(band.Price+band.PerKg*q.ChargeableWeight, 2)
+
+	if m := r.cargoMultiplier(req.CargoType); m != 1 {
+		q.CargoAdjustment = roundTo(q.Base*(m-1), 2)
+	}
+	q.FuelSurcharge = roundTo((q.Base+q.CargoAdjustment)*r.FuelSurchargePct/100, 2)
+	q.Total = roundTo(math.Max(q.Base+q.CargoAdjustment+q.FuelSurcharge, r.MinimumCharge), 2)
+	return q, nil
+}
+
+// cargoMultiplier returns the adjustment for the first (alphabetical) matching cargo keyword.
+func (r *RateCard) cargoMultiplier(cargoType string) float64 {
+	cargoType = strings.ToLower(cargoType)
+	keys := make([]string, 0, len(r.CargoMultipliers))
+	for k := range r.CargoMultipliers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k != "" && strings.Contains(cargoType, k) {
+			return r.CargoMultipliers[k]
+		}
+	}
+	return 1
+}
+
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// RateCard loads the company's rate card. ErrNoRateCard means pricing is not configured.
+func (u *Usecase) RateCard(ctx context.Context, companyID uuid.UUID) (*RateCard, error) {
+	raw, err := u.repo.GetSystemConfig(ctx, db.GetSystemConfigParams{CompanyID: companyID, Key: RateCardKey})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && raw == "") {
+		return nil, ErrNoRateCard
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate card: %w", err)
+	}
+
+	var r RateCard
+	if err := json.Unmarshal([]byte(raw), &r); err != nil {
+		return nil, fmt.Errorf("failed to decode rate card: %w", err)
+	}
+	r.normalize()
+	return &r, nil
+}
+
+// SetRateCard validates and stores the company's rate card.
+func (u *Usecase) SetRateCard(ctx context.Context, companyID uuid.UUID, r *RateCard) error {
+	r.normalize()
+	if err := r.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRateCard, err)
+	}
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode rate card: %w", err)
+	}
+	if err := u.repo.SetSystemConfig(ctx, db.SetSystemConfigParams{CompanyID: companyID, Key: RateCardKey, Value: string(raw)}); err != nil {
+		return fmt.Errorf("failed to save rate card: %w", err)
+	}
+	return nil
+}
+
+// Quote prices a prospective shipment with the company's rate card.
+func (u *Usecase) Quote(ctx context.Context, companyID uuid.UUID, req models.QuoteRequest) (*models.Quote, error) {
+	card, err := u.RateCard(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	return card.Price(req)
+}
+
+// EstimateCost returns the rate-card price for a new shipment, or 0 when the
+// company has no rate card or no lane covers it (agents then price by hand).
+func (u *Usecase) EstimateCost(ctx context.Context, companyID uuid.UUID, req models.QuoteRequest) float64 {
+	q, err := u.Quote(ctx, companyID, req)
+	if err != nil {
+		if !errors.Is(err, ErrNoRateCard) && !errors.Is(err, ErrNoRate) {
+			logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Failed to estimate shipment cost")
+		}
+		return 0
+	}
+	return q.Total
+}
+
+// RecalculateCost re-prices an existing shipment after its lane or cargo changed.
+// The stored cost is left untouched when no rate applies; changed is false
+// then, and when the new price is the same as the old one.
+func (u *Usecase) RecalculateCost(ctx context.Context, companyID uuid.UUID, trackingID string) (float64, bool, error) {
+	s, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	pieces, err := u.ListPieces(ctx, companyID, trackingID)
+	if err != nil {
+		return s.Cost.Float64, false, err
+	}
+	q, err := u.Quote(ctx, companyID, models.QuoteRequest{
+		Origin:      s.Origin.String,
+		Destination: s.Destination.String,
+		Weight:      s.Weight.Float64,
+		CargoType:   s.CargoType.String,
+		Pieces:      pieces,
+	})
+	if err != nil {
+		return s.Cost.Float64, false, err
+	}
+	if s.Cost.Valid && s.Cost.Float64 == q.Total {
+		return q.Total, false, nil
+	}
+	cost := sql.NullFloat64{Float64: q.Total, Valid: true}
+	err = applied(u.repo.UpdateShipmentCost(ctx, db.UpdateShipmentCostParams{
+		CompanyID:  toNullUUID(companyID),
+		TrackingID: trackingID,
+		Cost:       cost,
+		Version:    s.Version,
+	}))
+	if err != nil {
+		return s.Cost.Float64, false, fmt.Errorf("failed to update cost: %w", err)
+	}
+	u.recordChange(ctx, companyID, trackingID, "cost", fieldValue(s, "cost"), fieldValue(db.Shipment{Cost: cost}, "cost"), false)
+	return q.Total, true, nil
+}
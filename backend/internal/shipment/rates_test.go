@@ -0,0 +1,111 @@
+package shipment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"webtracker-bot/internal/models"
+)
+
+func testRateCard() *RateCard {
+	r := &RateCard{
+		Currency:         "ngn",
+		Zones:            map[string][]string{"ng": {"Nigeria"}, "uk": {"United Kingdom", "UK"}},
+		FuelSurchargePct: 10,
+		MinimumCharge:    5000,
+		CargoMultipliers: map[string]float64{"Fragile": 1.5},
+		Lanes: []RateLane{
+			{From: "ng", To: "uk", Bands: []WeightBand{
+				{MaxKg: 0, Price: 20000, PerKg: 4000},
+				{MaxKg: 5, Price: 15000},
+			}},
+			{From: AnyZone, To: AnyZone, Bands: []WeightBand{{Price: 1000, PerKg: 500}}},
+		},
+	}
+	r.normalize()
+	return r
+}
+
+func TestRateCardPrice(t *testing.T) {
+	r := testRateCard()
+	require.NoError(t, r.Validate())
+
+	t.Run("Flat band", func(t *testing.T) {
+		q, err := r.Price(models.QuoteRequest{Origin: "Lagos, Nigeria", Destination: "UK", Weight: 3})
+		require.NoError(t, err)
+		assert.Equal(t, "NGN", q.Currency)
+		assert.Equal(t, 3.0, q.ChargeableWeight)
+		assert.Equal(t, 15000.0, q.Base)
+		assert.Equal(t, 1500.0, q.FuelSurcharge)
+		assert.Equal(t, 16500.0, q.Total)
+	})
+
+	t.Run("Volumetric weight wins", func(t *testing.T) {
+		// 50×40×30 / 5000 = 12 kg volumetric vs 2 kg actual
+		q, err := r.Price(models.QuoteRequest{Origin: "Nigeria", Destination: "United Kingdom", Weight: 2, LengthCm: 50, WidthCm: 40, HeightCm: 30})
+		require.NoError(t, err)
+		assert.Equal(t, 12.0, q.VolumetricWeight)
+		assert.Equal(t, 12.0, q.ChargeableWeight)
+		assert.Equal(t, 68000.0, q.Base)
+		assert.Equal(t, 74800.0, q.Total)
+	})
+
+	t.Run("Pieces", func(t *testing.T) {
+		// Two 50×40×30 boxes: 24 kg volumetric vs 10 kg actual
+		box := models.Piece{Weight: 5, LengthCm: 50, WidthCm: 40, HeightCm: 30}
+		q, err := r.Price(models.QuoteRequest{Origin: "Nigeria", Destination: "UK", Weight: 10, Pieces: []models.Piece{box, box}})
+		require.NoError(t, err)
+		assert.Equal(t, 10.0, q.ActualWeight)
+		assert.Equal(t, 24.0, q.ChargeableWeight)
+		assert.Equal(t, 116000.0, q.Base)
+	})
+
+	t.Run("Cargo multiplier", func(t *testing.T) {
+		q, err := r.Price(models.QuoteRequest{Origin: "Nigeria", Destination: "UK", Weight: 1, CargoType: "fragile glassware"})
+		require.NoError(t, err)
+		assert.Equal(t, 7500.0, q.CargoAdjustment)
+		assert.Equal(t, 24750.0, q.Total)
+	})
+
+	t.Run("Fallback lane and minimum charge", func(t *testing.T) {
+		q, err := r.Price(models.QuoteRequest{Origin: "Ghana", Destination: "Togo", Weight: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 1500.0, q.Base)
+		assert.Equal(t, 5000.0, q.Total)
+	})
+
+	t.Run("No lane", func(t *testing.T) {
+		r := testRateCard()
+		r.Lanes = r.Lanes[:1]
+		_, err := r.Price(models.QuoteRequest{Origin: "Ghana", Destination: "UK", Weight: 1})
+		assert.ErrorIs(t, err, ErrNoRate)
+	})
+}
+
+func TestRateCardValidate(t *testing.T) {
+	r := testRateCard()
+	r.Lanes = append(r.Lanes, RateLane{From: "eu", To: "ng", Bands: []WeightBand{{Price: 1}}})
+	assert.Error(t, r.Validate())
+
+	r = testRateCard()
+	r.Currency = ""
+	assert.Error(t, r.Validate())
+}
+
+func TestRateCardZoneOf(t *testing.T) {
+	r := testRateCard()
+	assert.Equal(t, "uk", r.ZoneOf("uk"))
+	assert.Equal(t, "uk", r.ZoneOf("London, United Kingdom"))
+	assert.Equal(t, "ng", r.ZoneOf(" lagos - NIGERIA "))
+	assert.Equal(t, "", r.ZoneOf("Ukraine"), "UK must not match inside another name")
+	assert.Equal(t, "", r.ZoneOf("Niger"))
+
+	// Overlapping zones resolve the same way every time
+	overlap := RateCard{Zones: map[string][]string{"west": {"Ghana"}, "africa": {"Nigeria", "Ghana"}, "gh": {"Accra, Ghana"}}}
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, "africa", overlap.ZoneOf("Ghana"))
+		assert.Equal(t, "africa", overlap.ZoneOf("Kumasi, Ghana"))
+	}
+}
@@ -0,0 +1,150 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"webtracker-bot/internal/billing"
+	"webtracker-bot/internal/database/db"
+
+	"github.com/google/uuid"
+)
+
+// RetentionPolicyKey is the SystemConfig key holding a company's retention policy (JSON).
+const RetentionPolicyKey = "retention_policy"
+
+// ErrInvalidRetention wraps validation failures when saving a retention policy.
+var ErrInvalidRetention = errors.New("invalid retention policy")
+
+// RetentionPolicy controls how long shipments are kept before nightly pruning.
+type RetentionPolicy struct {
+	// DeliveredDays is how long a delivered shipment is kept after its last update.
+	DeliveredDays int `json:"delivered_days"`
+	// CreatedDays is how long any shipment is kept after creation, whatever its status.
+	CreatedDays int `json:"created_days"`
+}
+
+// RetentionSettings is a company's effective policy together with what its plan allows.
+type RetentionSettings struct {
+	Policy  RetentionPolicy `json:"policy"`
+	Default RetentionPolicy `json:"default"`
+	Custom  bool            `json:"custom"`
+	Plan    string          `json:"plan"`
+	MinDays int             `json:"min_days"`
+	MaxDays int             `json:"max_days"`
+}
+
+// DefaultRetention is the plan's retention policy when the company has not set one.
+func DefaultRetention(plan billing.Plan) RetentionPolicy {
+	return RetentionPolicy{DeliveredDays: plan.RetentionDeliveredDays, CreatedDays: plan.RetentionCreatedDays}
+}
+
+// Validate checks the policy against the plan's bounds.
+func (p RetentionPolicy) Validate(plan billing.Plan) error {
+	for _, f := range []struct {
+		name string
+		days int
+	}{{"delivered_days", p.DeliveredDays}, {"created_days", p.CreatedDays}} {
+		if f.days < billing.MinRetentionDays || f.days > plan.MaxRetentionDays {
+			return fmt.Errorf("%s must be between %d and %d days on the %s plan", f.name, billing.MinRetentionDays, plan.MaxRetentionDays, plan.Name)
+		}
+	}
+	if p.DeliveredDays > p.CreatedDays {
+		return fmt.Errorf("delivered_days (%d) cannot exceed created_days (%d)", p.DeliveredDays, p.CreatedDays)
+	}
+	return nil
+}
+
+// clamp fits a stored policy into the plan's bounds, e.g. after a downgrade.
+func (p RetentionPolicy) clamp(plan billing.Plan) RetentionPolicy {
+	fit := func(d int) int {
+		return max(billing.MinRetentionDays, min(d, plan.MaxRetentionDays))
+	}
+	p.DeliveredDays, p.CreatedDays = fit(p.DeliveredDays), fit(p.CreatedDays)
+	if p.DeliveredDays > p.CreatedDays {
+		p.DeliveredDays = p.CreatedDays
+	}
+	return p
+}
+
+// Cutoffs returns the timestamps before which shipments are pruned.
+func (p RetentionPolicy) Cutoffs(now time.Time) (deliveredBefore, createdBefore time.Time) {
+	return now.AddDate(0, 0, -p.DeliveredDays), now.AddDate(0, 0, -p.CreatedDays)
+}
+
+// retentionPlan resolves the billing plan that bounds the company's retention.
+func (u *Usecase) retentionPlan(ctx context.Context, companyID uuid.UUID) (billing.Plan, error) {
+	company, err := u.repo.GetCompanyByID(ctx, companyID)
+	if err != nil {
+		return billing.Plan{}, fmt.Errorf("failed to get company: %w", err)
+	}
+	plan, err := billing.GetPlanByID(company.PlanType.String)
+	if err != nil {
+		// Unknown plan — default to starter, like the shipment cap
+		plan = billing.PlanStarter
+	}
+	return plan, nil
+}
+
+// Retention returns the company's effective retention policy and its plan bounds.
+// Stored policies outside the current plan's bounds are clamped, not rejected.
+func (u *Usecase) Retention(ctx context.Context, companyID uuid.UUID) (*RetentionSettings, error) {
+	plan, err := u.retentionPlan(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	settings := &RetentionSettings{
+		Policy:  DefaultRetention(plan),
+		Default: DefaultRetention(plan),
+		Plan:    plan.ID,
+		MinDays: billing.MinRetentionDays,
+		MaxDays: plan.MaxRetentionDays,
+	}
+
+	raw, err := u.repo.GetSystemConfig(ctx, db.GetSystemConfigParams{CompanyID: companyID, Key: RetentionPolicyKey})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && raw == "") {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policy: %w", err)
+	}
+
+	var p RetentionPolicy
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return nil, fmt.Errorf("failed to decode retention policy: %w", err)
+	}
+	settings.Policy = p.clamp(plan)
+	settings.Custom = true
+	return settings, nil
+}
+
+// SetRetention validates the policy against the company's plan and stores it.
+func (u *Usecase) SetRetention(ctx context.Context, companyID uuid.UUID, p RetentionPolicy) (*RetentionSettings, error) {
+	plan, err := u.retentionPlan(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Validate(plan); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRetention, err)
+	}
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode retention policy: %w", err)
+	}
+	if err := u.repo.SetSystemConfig(ctx, db.SetSystemConfigParams{CompanyID: companyID, Key: RetentionPolicyKey, Value: string(raw)}); err != nil {
+		return nil, fmt.Errorf("failed to save retention policy: %w", err)
+	}
+	return u.Retention(ctx, companyID)
+}
+
+// ResetRetention drops the company's policy so it follows its plan's default again.
+func (u *Usecase) ResetRetention(ctx context.Context, companyID uuid.UUID) (*RetentionSettings, error) {
+	if err := u.repo.SetSystemConfig(ctx, db.SetSystemConfigParams{CompanyID: companyID, Key: RetentionPolicyKey, Value: ""}); err != nil {
+		return nil, fmt.Errorf("failed to reset retention policy: %w", err)
+	}
+	return u.Retention(ctx, companyID)
+}
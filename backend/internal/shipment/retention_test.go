@@ -0,0 +1,34 @@
+package shipment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"webtracker-bot/internal/billing"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	// Defaults follow the plan and are always within its bounds.
+	for _, plan := range []billing.Plan{billing.PlanTrial, billing.PlanStarter, billing.PlanPro, billing.PlanScale} {
+		assert.NoError(t, DefaultRetention(plan).Validate(plan), plan.ID)
+	}
+	assert.Equal(t, RetentionPolicy{DeliveredDays: 7, CreatedDays: 14}, DefaultRetention(billing.PlanStarter))
+
+	// Enterprise may keep a year; starter may not.
+	year := RetentionPolicy{DeliveredDays: 365, CreatedDays: 365}
+	assert.NoError(t, year.Validate(billing.PlanScale))
+	assert.ErrorContains(t, year.Validate(billing.PlanStarter), "between 1 and 90 days")
+
+	assert.Error(t, RetentionPolicy{DeliveredDays: 0, CreatedDays: 30}.Validate(billing.PlanPro))
+	assert.ErrorContains(t, RetentionPolicy{DeliveredDays: 60, CreatedDays: 30}.Validate(billing.PlanPro), "cannot exceed")
+
+	// A downgrade clamps the stored policy instead of keeping data past the plan's limit.
+	assert.Equal(t, RetentionPolicy{DeliveredDays: 90, CreatedDays: 90}, year.clamp(billing.PlanStarter))
+
+	now := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	delivered, created := RetentionPolicy{DeliveredDays: 7, CreatedDays: 30}.Cutoffs(now)
+	assert.Equal(t, time.Date(2026, 3, 24, 0, 0, 0, 0, time.UTC), delivered)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), created)
+}
@@ -0,0 +1,144 @@
+package shipment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidReturn wraps reasons a shipment can't be sent back.
+	ErrInvalidReturn = errors.New("invalid return")
+	// ErrAlreadyReturned is returned when the shipment already has a return.
+	ErrAlreadyReturned = errors.New("shipment already has a return")
+)
+
+// maxReturnReasonLength keeps the reason readable on receipts and alerts.
+const maxReturnReasonLength = 200
+
+// CreateReturn sends a shipment back to its sender. The return is a new
+// shipment with its own tracking ID and schedule, going from the original
+// destination to the origin with sender and recipient swapped; the original
+// is marked returned and linked to it. version works as in UpdateStatus.
+// Returns the return's tracking ID.
+func (u *Usecase) CreateReturn(ctx context.Context, companyID uuid.UUID, trackingID, reason, prefix string, version int32) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return "", fmt.Errorf("%w: a reason is required", ErrInvalidReturn)
+	}
+	if len(reason) > maxReturnReasonLength {
+		return "", fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidReturn, maxReturnReasonLength)
+	}
+
+	// One transaction: a lost race or a failed piece copy leaves neither
+	// the return nor the link behind
+	var returnID string
+	err := u.inTx(ctx, func(tx *Usecase) error {
+		orig, err := tx.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+		if err != nil {
+			return fmt.Errorf("failed to get shipment: %w", err)
+		}
+		if version != 0 && orig.Version != version {
+			return ErrVersionConflict
+		}
+		if orig.ReturnID.Valid {
+			return fmt.Errorf("%w: %s", ErrAlreadyReturned, orig.ReturnID.String)
+		}
+		if orig.ReturnOf.Valid {
+			return fmt.Errorf("%w: %s is itself a return of %s", ErrInvalidReturn, trackingID, orig.ReturnOf.String)
+		}
+		if err := ValidateTransition(trackingID, orig.Status.String, StatusReturned); err != nil {
+			return err
+		}
+
+		ret, err := tx.returnShipment(ctx, companyID, orig, reason)
+		if err != nil {
+			return err
+		}
+		if returnID, err = tx.CreateWithPrefix(ctx, companyID, ret, prefix); err != nil {
+			return err
+		}
+
+		params := db.MarkShipmentReturnedParams{
+			CompanyID:    toNullUUID(companyID),
+			TrackingID:   trackingID,
+			ReturnID:     dbutil.ToNullString(returnID),
+			ReturnReason: dbutil.ToNullString(reason),
+			Version:      orig.Version,
+		}
+		if err := applied(tx.repo.MarkShipmentReturned(ctx, params)); err != nil {
+			if errors.Is(err, ErrVersionConflict) {
+				return err
+			}
+			return fmt.Errorf("failed to mark shipment returned: %w", err)
+		}
+
+		pieces, err := tx.ListPieces(ctx, companyID, trackingID)
+		if err != nil {
+			return err
+		}
+		if len(pieces) > 0 {
+			if err := tx.SavePieces(ctx, companyID, returnID, pieces); err != nil {
+				return err
+			}
+		}
+
+		// The reason stays on the shipment; the timeline only links the return
+		tx.recordChange(ctx, companyID, trackingID, "status", orig.Status, dbutil.ToNullString(StatusReturned), false)
+		tx.recordStatusEvent(ctx, companyID, trackingID, StatusReturned, orig.Status.String, fmt.Sprintf("Returned to sender as %s", returnID))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return returnID, nil
+}
+
+// returnShipment builds the return of orig: same goods, reversed route,
+// scheduled from now with the original service level.
+func (u *Usecase) returnShipment(ctx context.Context, companyID uuid.UUID, orig db.Shipment, reason string) (*db.Shipment, error) {
+	profile, err := u.ScheduleProfile(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := u.HolidayCalendar(ctx, companyID)
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Scheduling return without holiday calendar")
+	}
+	scheduler := &Calculator{Profile: profile, Level: orig.ServiceLevel.String, Calendar: calendar}
+
+	// The return departs from the original destination
+	departure := scheduler.CalculateDeparture(time.Now().UTC(), orig.RecipientTimezone.String)
+	arrival, outForDelivery := scheduler.CalculateArrival(departure, orig.Destination.String, orig.Origin.String)
+
+	return &db.Shipment{
+		UserJid:              orig.UserJid,
+		Status:               dbutil.ToNullString(StatusPending),
+		ScheduledTransitTime: dbutil.ToNullTime(departure),
+		OutfordeliveryTime:   dbutil.ToNullTime(outForDelivery),
+		ExpectedDeliveryTime: dbutil.ToNullTime(arrival),
+		SenderTimezone:       orig.RecipientTimezone,
+		RecipientTimezone:    dbutil.ToNullString(scheduler.ResolveTimezone(orig.Origin.String)),
+		SenderName:           orig.RecipientName,
+		SenderPhone:          orig.RecipientPhone,
+		Origin:               orig.Destination,
+		RecipientName:        orig.SenderName,
+		RecipientPhone:       orig.SenderPhone,
+		Destination:          orig.Origin,
+		CargoType:            orig.CargoType,
+		Weight:               orig.Weight,
+		ServiceLevel:         dbutil.ToNullString(scheduler.ServiceLevel()),
+		CustomFields:         orig.CustomFields,
+		Tags:                 orig.Tags,
+		ReturnOf:             dbutil.ToNullString(orig.TrackingID),
+		ReturnReason:         dbutil.ToNullString(reason),
+	}, nil
+}
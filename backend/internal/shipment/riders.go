@@ -0,0 +1,375 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidRider wraps validation failures when saving or looking up a rider.
+	ErrInvalidRider = errors.New("invalid rider")
+	// ErrRiderExists is returned when another rider already has the phone number.
+	ErrRiderExists = errors.New("rider already exists")
+	// ErrRiderInactive is returned for riders that were switched off.
+	ErrRiderInactive = errors.New("rider is inactive")
+	// ErrInvalidAssignment wraps reasons a shipment can't be given to a rider.
+	ErrInvalidAssignment = errors.New("invalid rider assignment")
+	// ErrNotAssigned is returned when a rider acts on a shipment they don't carry.
+	ErrNotAssigned = errors.New("shipment is not assigned to this rider")
+)
+
+func riderFromDB(r db.Rider) models.Rider {
+	return models.Rider{
+		ID:        r.ID,
+		Name:      r.Name,
+		Phone:     r.Phone,
+		Active:    r.Active,
+		CreatedAt: r.CreatedAt.Time,
+	}
+}
+
+// normalizeRider trims the rider and checks the phone can identify a WhatsApp sender.
+func normalizeRider(r *models.Rider) error {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Phone = strings.TrimSpace(r.Phone)
+	if r.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRider)
+	}
+	if len(digitsOnly(r.Phone)) < minPhoneDigits {
+		return fmt.Errorf("%w: phone must have at least %d digits, including the country code", ErrInvalidRider, minPhoneDigits)
+	}
+	return nil
+}
+
+// ListRiders returns the company's riders by name.
+func (u *Usecase) ListRiders(ctx context.Context, companyID uuid.UUID) ([]models.Rider, error) {
+	rows, err := u.repo.ListRiders(ctx, companyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list riders: %w", err)
+	}
+	riders := make([]models.Rider, 0, len(rows))
+	for _, r := range rows {
+		riders = append(riders, riderFromDB(r))
+	}
+	return riders, nil
+}
+
+// GetRider returns a single rider, or sql.ErrNoRows.
+func (u *Usecase) GetRider(ctx context.Context, companyID uuid.UUID, id int32) (*models.Rider, error) {
+	row, err := u.repo.GetRider(ctx, db.GetRiderParams{CompanyID: companyID, ID: id})
+	if err != nil {
+		return nil, err
+	}
+	r := riderFromDB(row)
+	return &r, nil
+}
+
+// CreateRider adds an active rider.
+func (u *Usecase) CreateRider(ctx context.Context, companyID uuid.UUID, r models.Rider) (*models.Rider, error) {
+	if err := normalizeRider(&r); err != nil {
+		return nil, err
+	}
+	row, err := u.repo.CreateRider(ctx, db.CreateRiderParams{
+		CompanyID:   companyID,
+		Name:        r.Name,
+		Phone:       r.Phone,
+		PhoneDigits: digitsOnly(r.Phone),
+		Active:      true,
+	})
+	if err != nil {
+		if dbutil.IsUniqueViolation(err) {
+			return nil, ErrRiderExists
+		}
+		return nil, fmt.Errorf("failed to create rider: %w", err)
+	}
+	created := riderFromDB(row)
+	return &created, nil
+}
+
+// UpdateRider replaces a rider's details. Returns sql.ErrNoRows when it doesn't exist.
+func (u *Usecase) UpdateRider(ctx context.Context, companyID uuid.UUID, id int32, r models.Rider) (*models.Rider, error) {
+	if err := normalizeRider(&r); err != nil {
+		return nil, err
+	}
+	row, err := u.repo.UpdateRider(ctx, db.UpdateRiderParams{
+		CompanyID:   companyID,
+		ID:          id,
+		Name:        r.Name,
+		Phone:       r.Phone,
+		PhoneDigits: digitsOnly(r.Phone),
+		Active:      r.Active,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if dbutil.IsUniqueViolation(err) {
+			return nil, ErrRiderExists
+		}
+		return nil, fmt.Errorf("failed to update rider: %w", err)
+	}
+	updated := riderFromDB(row)
+	return &updated, nil
+}
+
+// DeleteRider removes a rider and their assignments. Returns sql.ErrNoRows
+// when it doesn't exist.
+func (u *Usecase) DeleteRider(ctx context.Context, companyID uuid.UUID, id int32) error {
+	res, err := u.repo.DeleteRider(ctx, db.DeleteRiderParams{CompanyID: companyID, ID: id})
+	if err != nil {
+		return fmt.Errorf("failed to delete rider: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RiderByPhone returns the active rider with this phone number. Returns
+// sql.ErrNoRows when there is none and ErrRiderInactive when they were
+// switched off.
+func (u *Usecase) RiderByPhone(ctx context.Context, companyID uuid.UUID, phone string) (*models.Rider, error) {
+	digits := digitsOnly(phone)
+	if len(digits) < minPhoneDigits {
+		return nil, sql.ErrNoRows
+	}
+	row, err := u.repo.GetRiderByPhone(ctx, db.GetRiderByPhoneParams{CompanyID: companyID, PhoneDigits: digits})
+	if err != nil {
+		return nil, err
+	}
+	if !row.Active {
+		return nil, ErrRiderInactive
+	}
+	r := riderFromDB(row)
+	return &r, nil
+}
+
+// FindRider resolves what an admin typed after !assign: a phone number, or
+// a name (exact, else the start of exactly one rider's name).
+func (u *Usecase) FindRider(ctx context.Context, companyID uuid.UUID, ref string) (*models.Rider, error) {
+	ref = strings.TrimSpace(ref)
+	if len(digitsOnly(ref)) >= minPhoneDigits {
+		return u.RiderByPhone(ctx, companyID, ref)
+	}
+	riders, err := u.ListRiders(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+	var prefixed []models.Rider
+	for _, r := range riders {
+		if !r.Active {
+			continue
+		}
+		if strings.EqualFold(r.Name, ref) {
+			return &r, nil
+		}
+		if ref != "" && strings.HasPrefix(strings.ToLower(r.Name), strings.ToLower(ref)) {
+			prefixed = append(prefixed, r)
+		}
+	}
+	switch len(prefixed) {
+	case 0:
+		return nil, sql.ErrNoRows
+	case 1:
+		return &prefixed[0], nil
+	}
+	names := make([]string, 0, len(prefixed))
+	for _, r := range prefixed {
+		names = append(names, r.Name)
+	}
+	return nil, fmt.Errorf("%w: %q matches %s", ErrInvalidRider, ref, strings.Join(names, ", "))
+}
+
+// AssignRider gives a shipment to a rider, replacing any earlier rider.
+// While assigned, the pulse no longer sends the shipment out for delivery
+// or marks it delivered; the rider does.
+func (u *Usecase) AssignRider(ctx context.Context, companyID uuid.UUID, trackingID string, riderID int32) (*models.Rider, error) {
+	rider, err := u.GetRider(ctx, companyID, riderID)
+	if err != nil {
+		return nil, err
+	}
+	if !rider.Active {
+		return nil, ErrRiderInactive
+	}
+	ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	if len(transitions[ship.Status.String]) == 0 {
+		return nil, fmt.Errorf("%w: %s is already %s", ErrInvalidAssignment, trackingID, ship.Status.String)
+	}
+
+	_, actor := utils.GetSource(ctx)
+	err = u.repo.AssignRider(ctx, db.AssignRiderParams{
+		CompanyID:  companyID,
+		TrackingID: trackingID,
+		RiderID:    rider.ID,
+		AssignedBy: dbutil.ToNullString(actor),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign rider: %w", err)
+	}
+	return rider, nil
+}
+
+// UnassignRider hands a shipment back to the pulse. Returns sql.ErrNoRows
+// when it had no rider.
+func (u *Usecase) UnassignRider(ctx context.Context, companyID uuid.UUID, trackingID string) error {
+	res, err := u.repo.UnassignRider(ctx, db.UnassignRiderParams{CompanyID: companyID, TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to unassign rider: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ShipmentRider returns the rider carrying a shipment, or sql.ErrNoRows.
+func (u *Usecase) ShipmentRider(ctx context.Context, companyID uuid.UUID, trackingID string) (*models.Rider, error) {
+	a, err := u.repo.GetRiderAssignment(ctx, db.GetRiderAssignmentParams{CompanyID: companyID, TrackingID: trackingID})
+	if err != nil {
+		return nil, err
+	}
+	return u.GetRider(ctx, companyID, a.RiderID)
+}
+
+// riderHeld returns which of the due shipments have a rider. On error none
+// are held, so a lookup failure can't stall the pulse.
+func (u *Usecase) riderHeld(ctx context.Context, companyID uuid.UUID, due []db.Shipment) map[string]bool {
+	if len(due) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(due))
+	for _, s := range due {
+		ids = append(ids, s.TrackingID)
+	}
+	assigned, err := u.repo.ListAssignedShipments(ctx, db.ListAssignedShipmentsParams{CompanyID: companyID, Column2: ids})
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Failed to load rider assignments, advancing all due shipments")
+		return nil
+	}
+	held := make(map[string]bool, len(assigned))
+	for _, id := range assigned {
+		held[id] = true
+	}
+	return held
+}
+
+// checkRider makes sure rider carries the shipment. A nil rider is an admin,
+// who may act on any shipment.
+func (u *Usecase) checkRider(ctx context.Context, companyID uuid.UUID, rider *models.Rider, trackingID string) error {
+	if rider == nil {
+		return nil
+	}
+	a, err := u.repo.GetRiderAssignment(ctx, db.GetRiderAssignmentParams{CompanyID: companyID, TrackingID: trackingID})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && a.RiderID != rider.ID) {
+		return fmt.Errorf("%w: %s", ErrNotAssigned, trackingID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get rider assignment: %w", err)
+	}
+	return nil
+}
+
+// RiderPickup sends shipments out for delivery with their rider. With a
+// tracking ID only that shipment is picked up; without one, every shipment
+// assigned to the rider that is waiting in transit. Returns the shipments
+// that moved (with their new status) for the customer alerts.
+func (u *Usecase) RiderPickup(ctx context.Context, companyID uuid.UUID, rider *models.Rider, trackingID string) ([]db.Shipment, error) {
+	ids := []string{trackingID}
+	if trackingID == "" {
+		if rider == nil {
+			return nil, fmt.Errorf("%w: a tracking ID is required", ErrInvalidAssignment)
+		}
+		var err error
+		ids, err = u.repo.ListRiderPickups(ctx, db.ListRiderPickupsParams{CompanyID: companyID, RiderID: rider.ID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pickups: %w", err)
+		}
+	} else if err := u.checkRider(ctx, companyID, rider, trackingID); err != nil {
+		return nil, err
+	}
+
+	var moved []db.Shipment
+	for _, id := range ids {
+		ship, err := u.riderTransition(ctx, companyID, id, StatusOutForDelivery)
+		if err != nil {
+			if trackingID != "" {
+				return nil, err
+			}
+			// Changed since it was listed; the rest of the round still goes out
+			logger.Warn().Err(err).Str("tracking_id", id).Msg("Skipping rider pickup")
+			continue
+		}
+		if err := u.repo.MarkRiderPickedUp(ctx, db.MarkRiderPickedUpParams{CompanyID: companyID, TrackingID: id}); err != nil {
+			logger.Warn().Err(err).Str("tracking_id", id).Msg("Failed to record pickup time")
+		}
+		moved = append(moved, *ship)
+	}
+	return moved, nil
+}
+
+// RiderDeliver marks a shipment delivered by its rider. A shipment that was
+// issued a delivery code needs it as code; see verifyDeliveryOTP.
+func (u *Usecase) RiderDeliver(ctx context.Context, companyID uuid.UUID, rider *models.Rider, trackingID, code string) (*db.Shipment, error) {
+	if err := u.checkRider(ctx, companyID, rider, trackingID); err != nil {
+		return nil, err
+	}
+	confirm, err := u.verifyDeliveryOTP(ctx, companyID, trackingID, strings.TrimSpace(code))
+	if err != nil {
+		return nil, err
+	}
+	// The code is only used up if the shipment really is delivered
+	var ship *db.Shipment
+	err = u.inTx(ctx, func(tx *Usecase) error {
+		if confirm {
+			key := db.ConfirmDeliveryOTPParams{CompanyID: companyID, TrackingID: trackingID}
+			if err := tx.repo.ConfirmDeliveryOTP(ctx, key); err != nil {
+				return fmt.Errorf("failed to confirm delivery code: %w", err)
+			}
+		}
+		var err error
+		ship, err = tx.riderTransition(ctx, companyID, trackingID, StatusDelivered)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ship, nil
+}
+
+// RiderFailed records a failed delivery by the shipment's rider; see
+// RecordFailedAttempt.
+func (u *Usecase) RiderFailed(ctx context.Context, companyID uuid.UUID, rider *models.Rider, trackingID, reason, note string) (*models.AttemptResult, error) {
+	if err := u.checkRider(ctx, companyID, rider, trackingID); err != nil {
+		return nil, err
+	}
+	return u.RecordFailedAttempt(ctx, companyID, trackingID, reason, note, 0)
+}
+
+// riderTransition moves a shipment to status through UpdateStatus, against
+// the version it was read at. Returns the shipment as updated.
+func (u *Usecase) riderTransition(ctx context.Context, companyID uuid.UUID, trackingID, status string) (*db.Shipment, error) {
+	ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	if err := u.UpdateStatus(ctx, companyID, trackingID, status, ship.Destination.String, ship.Version); err != nil {
+		return nil, err
+	}
+	ship.Status = dbutil.ToNullString(status)
+	ship.Version++
+	return &ship, nil
+}
@@ -0,0 +1,447 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ScheduleProfileKey is the SystemConfig key holding a company's scheduling profile (JSON).
+const ScheduleProfileKey = "scheduling_profile"
+
+// DefaultServiceLevel is used when a shipment does not ask for a specific service.
+const DefaultServiceLevel = "standard"
+
+// AnyZone matches every origin or destination in a transit rule.
+const AnyZone = "*"
+
+// ErrInvalidScheduleProfile wraps validation failures when saving a profile.
+var ErrInvalidScheduleProfile = errors.New("invalid scheduling profile")
+
+// maxClosedDays bounds how far the schedule is pushed past closed days and holidays.
+const maxClosedDays = 31
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// ScheduleProfile describes a company's warehouse hours and how long each
+// service level takes between zones. It drives the Calculator.
+type ScheduleProfile struct {
+	// Timezone of the dispatching branch. Empty falls back to the admin timezone.
+	Timezone string `json:"timezone,omitempty"`
+	// HandlingDelayMinutes is the time between creation and departure.
+	HandlingDelayMinutes int `json:"handling_delay_minutes"`
+	// Hours is keyed by lowercase weekday ("monday"). Missing days are closed.
+	Hours map[string]OperatingHours `json:"hours"`
+	// Zones maps a zone name to the countries it contains.
+	Zones          map[string][]string     `json:"zones,omitempty"`
+	DefaultService string                  `json:"default_service"`
+	Services       map[string]ServiceLevel `json:"services"`
+}
+
+// OperatingHours is a single weekday's dispatch window ("HH:MM", branch local time).
+type OperatingHours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+	// Cutoff is the last creation time that still departs the same day. Empty means Close.
+	Cutoff string `json:"cutoff,omitempty"`
+	Closed bool   `json:"closed,omitempty"`
+}
+
+// ServiceLevel defines transit times and the delivery window of a service (e.g. express).
+type ServiceLevel struct {
+	Transit         []TransitRule `json:"transit"`
+	DeliveryStart   string        `json:"delivery_start"` // Recipient local time, "HH:MM"
+	DeliveryEnd     string        `json:"delivery_end"`
+	OFDLeadMinHours int           `json:"ofd_lead_min_hours"` // Out-for-delivery offset before arrival
+	OFDLeadMaxHours int           `json:"ofd_lead_max_hours"`
+	// DeliveryDays limits deliveries to these weekdays (e.g. no Sundays). Empty means every day.
+	DeliveryDays []string `json:"delivery_days,omitempty"`
+}
+
+// TransitRule gives the transit-day range between an origin and a destination zone.
+type TransitRule struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	MinDays int    `json:"min_days"`
+	MaxDays int    `json:"max_days"`
+}
+
+// DefaultScheduleProfile reproduces the historical warehouse behaviour:
+// open 08:00-22:00 every day, one hour handling, next-day delivery 09:00-16:00.
+func DefaultScheduleProfile() *ScheduleProfile {
+	hours := make(map[string]OperatingHours, len(weekdays))
+	for _, d := range weekdays {
+		hours[d] = OperatingHours{Open: "08:00", Close: "22:00"}
+	}
+	return &ScheduleProfile{
+		HandlingDelayMinutes: 60,
+		Hours:                hours,
+		DefaultService:       DefaultServiceLevel,
+		Services: map[string]ServiceLevel{
+			DefaultServiceLevel: {
+				Transit:         []TransitRule{{From: AnyZone, To: AnyZone, MinDays: 1, MaxDays: 1}},
+				DeliveryStart:   "09:00",
+				DeliveryEnd:     "16:00",
+				OFDLeadMinHours: 3,
+				OFDLeadMaxHours: 5,
+			},
+		},
+	}
+}
+
+// normalize lowercases lookup keys and fills service defaults left blank.
+func (p *ScheduleProfile) normalize() {
+	if p.DefaultService == "" {
+		p.DefaultService = DefaultServiceLevel
+	}
+	p.DefaultService = strings.ToLower(p.DefaultService)
+
+	hours := make(map[string]OperatingHours, len(p.Hours))
+	for d, h := range p.Hours {
+		hours[strings.ToLower(strings.TrimSpace(d))] = h
+	}
+	p.Hours = hours
+
+	services := make(map[string]ServiceLevel, len(p.Services))
+	for name, s := range p.Services {
+		if s.DeliveryStart == "" {
+			s.DeliveryStart = "09:00"
+		}
+		if s.DeliveryEnd == "" {
+			s.DeliveryEnd = "16:00"
+		}
+		if s.OFDLeadMinHours == 0 && s.OFDLeadMaxHours == 0 {
+			s.OFDLeadMinHours, s.OFDLeadMaxHours = 3, 5
+		}
+		for i, d := range s.DeliveryDays {
+			s.DeliveryDays[i] = strings.ToLower(strings.TrimSpace(d))
+		}
+		services[strings.ToLower(strings.TrimSpace(name))] = s
+	}
+	p.Services = services
+}
+
+// Validate checks that the profile is internally consistent.
+func (p *ScheduleProfile) Validate() error {
+	if p.Timezone != "" {
+		if _, err := loadLocation(p.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q", p.Timezone)
+		}
+	}
+	if p.HandlingDelayMinutes < 0 {
+		return fmt.Errorf("handling_delay_minutes must not be negative")
+	}
+
+	open := 0
+	for day, h := range p.Hours {
+		if !isWeekday(day) {
+			return fmt.Errorf("unknown weekday %q", day)
+		}
+		if h.Closed {
+			continue
+		}
+		o, err1 := parseClock(h.Open)
+		c, err2 := parseClock(h.Close)
+		if err1 != nil || err2 != nil || o >= c {
+			return fmt.Errorf("invalid hours for %s: open and close must be HH:MM with open before close", day)
+		}
+		if h.Cutoff != "" {
+			cut, err := parseClock(h.Cutoff)
+			if err != nil || cut <= o || cut > c {
+				return fmt.Errorf("invalid cutoff for %s: must fall between open and close", day)
+			}
+		}
+		open++
+	}
+	if open == 0 {
+		return fmt.Errorf("at least one weekday must be open")
+	}
+
+	if _, ok := p.Services[p.DefaultService]; !ok {
+		return fmt.Errorf("default service %q is not defined", p.DefaultService)
+	}
+	for name, s := range p.Services {
+		start, err1 := parseClock(s.DeliveryStart)
+		end, err2 := parseClock(s.DeliveryEnd)
+		if err1 != nil || err2 != nil || start >= end {
+			return fmt.Errorf("invalid delivery window for service %s", name)
+		}
+		if s.OFDLeadMinHours < 0 || s.OFDLeadMaxHours < s.OFDLeadMinHours {
+			return fmt.Errorf("invalid out-for-delivery lead for service %s", name)
+		}
+		for _, d := range s.DeliveryDays {
+			if !isWeekday(d) {
+				return fmt.Errorf("service %s has unknown delivery day %q", name, d)
+			}
+		}
+		if len(s.Transit) == 0 {
+			return fmt.Errorf("service %s has no transit rules", name)
+		}
+		for _, r := range s.Transit {
+			if r.MinDays < 0 || r.MaxDays < r.MinDays {
+				return fmt.Errorf("invalid transit days for service %s (%s -> %s)", name, r.From, r.To)
+			}
+			for _, z := range []string{r.From, r.To} {
+				if _, ok := p.Zones[z]; z != AnyZone && !ok {
+					return fmt.Errorf("service %s references unknown zone %q", name, z)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// HasService reports whether the profile defines the given service level.
+func (p *ScheduleProfile) HasService(level string) bool {
+	_, ok := p.Services[strings.ToLower(strings.TrimSpace(level))]
+	return ok
+}
+
+// ServiceNames lists the defined service levels, sorted.
+func (p *ScheduleProfile) ServiceNames() []string {
+	names := make([]string, 0, len(p.Services))
+	for name := range p.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveService returns the service level name to use, falling back to the default.
+func (p *ScheduleProfile) ResolveService(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if _, ok := p.Services[level]; ok {
+		return level
+	}
+	return p.DefaultService
+}
+
+// ZoneOf returns the zone a country belongs to, or "" when it is not zoned.
+func (p *ScheduleProfile) ZoneOf(country string) string {
+	return zoneOf(p.Zones, country)
+}
+
+// zoneOf finds a country's zone: exact (case-insensitive) match first, then
+// a whole-word match either way, so "Lagos, Nigeria" is in the zone listing
+// "Nigeria" but "Ukraine" is not in the one listing "UK". Zones are tried in
+// name order, so a country listed in two zones always lands in the same one.
+func zoneOf(zones map[string][]string, country string) string {
+	country = zoneWords(country)
+	if country == "" {
+		return ""
+	}
+	names := make([]string, 0, len(zones))
+	for zone := range zones {
+		names = append(names, zone)
+	}
+	sort.Strings(names)
+	for _, zone := range names {
+		for _, c := range zones[zone] {
+			if zoneWords(c) == country {
+				return zone
+			}
+		}
+	}
+	for _, zone := range names {
+		for _, c := range zones[zone] {
+			c = zoneWords(c)
+			if c == "" {
+				continue
+			}
+			if strings.Contains(" "+country+" ", " "+c+" ") || strings.Contains(" "+c+" ", " "+country+" ") {
+				return zone
+			}
+		}
+	}
+	return ""
+}
+
+// zoneWords lowercases s and reduces it to its words separated by single spaces.
+func zoneWords(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// transitDays picks the most specific rule for the zone pair and returns its day range.
+func (s ServiceLevel) transitDays(from, to string) (int, int) {
+	best, minDays, maxDays := -1, 1, 1
+	for _, r := range s.Transit {
+		score := 0
+		switch {
+		case r.From == from && from != "":
+			score += 2
+		case r.From != AnyZone:
+			continue
+		}
+		switch {
+		case r.To == to && to != "":
+			score++
+		case r.To != AnyZone:
+			continue
+		}
+		if score > best {
+			best, minDays, maxDays = score, r.MinDays, r.MaxDays
+		}
+	}
+	return minDays, maxDays
+}
+
+// deliversOn reports whether the service delivers on t's weekday.
+func (s ServiceLevel) deliversOn(t time.Time) bool {
+	if len(s.DeliveryDays) == 0 {
+		return true
+	}
+	day := weekdays[t.Weekday()]
+	for _, d := range s.DeliveryDays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// departure applies the cut-off, opening hours and branch holidays to a creation time.
+func (p *ScheduleProfile) departure(now time.Time, loc *time.Location, cal *HolidayCalendar) time.Time {
+	local := now.In(loc)
+	if h, ok := p.hoursOn(local, cal); ok && h.Cutoff != "" {
+		if cut, _ := parseClock(h.Cutoff); clockOf(local) >= cut {
+			return p.nextOpening(local, 1, cal)
+		}
+	}
+
+	transit := local.Add(time.Duration(p.HandlingDelayMinutes) * time.Minute)
+	h, ok := p.hoursOn(transit, cal)
+	if !ok {
+		return p.nextOpening(transit, 1, cal)
+	}
+	openAt, _ := parseClock(h.Open)
+	closeAt, _ := parseClock(h.Close)
+	switch {
+	case clockOf(transit) >= closeAt:
+		return p.nextOpening(transit, 1, cal)
+	case clockOf(transit) < openAt:
+		return atClock(transit, openAt)
+	}
+	return transit
+}
+
+// hoursOn returns the operating hours for t's weekday, false when closed or a branch holiday.
+func (p *ScheduleProfile) hoursOn(t time.Time, cal *HolidayCalendar) (OperatingHours, bool) {
+	h, ok := p.Hours[weekdays[t.Weekday()]]
+	return h, ok && !h.Closed && !cal.IsHoliday(BranchCalendar, t)
+}
+
+// nextOpening returns the opening time of the first open day at least offset days after t.
+func (p *ScheduleProfile) nextOpening(t time.Time, offset int, cal *HolidayCalendar) time.Time {
+	for i := offset; i < offset+maxClosedDays; i++ {
+		day := t.AddDate(0, 0, i)
+		if h, ok := p.hoursOn(day, cal); ok {
+			openAt, _ := parseClock(h.Open)
+			return atClock(day, openAt)
+		}
+	}
+	return t // Closed for longer than maxClosedDays; leave the schedule untouched
+}
+
+func isWeekday(day string) bool {
+	for _, d := range weekdays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock converts "HH:MM" to minutes after midnight ("24:00" is allowed as a close time).
+func parseClock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d", &h, &m); err != nil {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+func clockOf(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+func atClock(t time.Time, minutes int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), minutes/60, minutes%60, 0, 0, t.Location())
+}
+
+// randomBetween returns a uniformly distributed int in [lo, hi].
+func randomBetween(lo, hi int) int {
+	if hi <= lo {
+		return lo
+	}
+	return lo + rand.IntN(hi-lo+1)
+}
+
+// ScheduleProfile loads the company's scheduling profile, or the default when none is stored.
+func (u *Usecase) ScheduleProfile(ctx context.Context, companyID uuid.UUID) (*ScheduleProfile, error) {
+	raw, err := u.repo.GetSystemConfig(ctx, db.GetSystemConfigParams{CompanyID: companyID, Key: ScheduleProfileKey})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && raw == "") {
+		return DefaultScheduleProfile(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scheduling profile: %w", err)
+	}
+
+	var p ScheduleProfile
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		return nil, fmt.Errorf("failed to decode scheduling profile: %w", err)
+	}
+	p.normalize()
+	return &p, nil
+}
+
+// SetScheduleProfile validates and stores the company's scheduling profile.
+func (u *Usecase) SetScheduleProfile(ctx context.Context, companyID uuid.UUID, p *ScheduleProfile) error {
+	p.normalize()
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidScheduleProfile, err)
+	}
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode scheduling profile: %w", err)
+	}
+	if err := u.repo.SetSystemConfig(ctx, db.SetSystemConfigParams{CompanyID: companyID, Key: ScheduleProfileKey, Value: string(raw)}); err != nil {
+		return fmt.Errorf("failed to save scheduling profile: %w", err)
+	}
+	return nil
+}
+
+// ServiceFor returns a scheduler bound to the company's profile and the requested
+// service level. Unknown levels fall back to the profile's default service.
+func (u *Usecase) ServiceFor(ctx context.Context, companyID uuid.UUID, serviceLevel string) models.ShipmentService {
+	if _, ok := u.Service.(*Calculator); !ok {
+		return u.Service // Custom implementations manage their own schedule
+	}
+	profile, err := u.ScheduleProfile(ctx, companyID)
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Falling back to default scheduling profile")
+		profile = DefaultScheduleProfile()
+	}
+	calendar, err := u.HolidayCalendar(ctx, companyID)
+	if err != nil {
+		logger.Warn().Err(err).Str("company_id", companyID.String()).Msg("Scheduling without holiday calendar")
+	}
+	return &Calculator{Profile: profile, Level: serviceLevel, Calendar: calendar}
+}
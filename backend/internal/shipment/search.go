@@ -0,0 +1,173 @@
+package shipment
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+)
+
+// MaxSearchLimit caps how many shipments a single search page returns.
+const MaxSearchLimit = 200
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// SearchFilter narrows a shipment search. Zero values mean "no filter".
+type SearchFilter struct {
+	Status      string
+	CreatedFrom time.Time // inclusive
+	CreatedTo   time.Time // exclusive
+	Destination string    // substring, case-insensitive
+	UserJID     string    // creator
+	// Query matches tracking ID, recipient name and address as a substring,
+	// and the recipient phone by its digits.
+	Query string
+	// Tags must all be present; CustomFields match exactly, by field name.
+	Tags         []string
+	CustomFields map[string]string
+	Cursor       string
+	Limit        int
+}
+
+// SearchPage is one page of search results, newest first.
+// NextCursor is empty on the last page.
+type SearchPage struct {
+	Shipments  []Shipment `json:"shipments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// Search lists live shipments matching the filter using keyset pagination
+// on (created_at, tracking_id), so pages stay stable while shipments are added.
+func (u *Usecase) Search(ctx context.Context, companyID uuid.UUID, f SearchFilter) (*SearchPage, error) {
+	limit := f.Limit
+	if limit <= 0 || limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	params := db.SearchShipmentsParams{
+		CompanyID:   toNullUUID(companyID),
+		Status:      dbutil.ToNullString(f.Status),
+		CreatedFrom: dbutil.ToNullTime(f.CreatedFrom),
+		CreatedTo:   dbutil.ToNullTime(f.CreatedTo),
+		UserJid:     dbutil.ToNullString(f.UserJID),
+		// One extra row tells us whether there is a next page.
+		RowLimit: int32(limit + 1),
+	}
+	if d := strings.TrimSpace(f.Destination); d != "" {
+		params.Destination = dbutil.ToNullString(containsPattern(d))
+	}
+	if q := strings.TrimSpace(f.Query); q != "" {
+		params.Query = dbutil.ToNullString(containsPattern(q))
+		if digits := digitsOnly(q); len(digits) >= 3 {
+			params.PhoneDigits = dbutil.ToNullString("%" + digits + "%")
+		}
+	}
+	if len(f.Tags) > 0 {
+		tags, err := NormalizeTags(f.Tags)
+		if err != nil {
+			return nil, err
+		}
+		params.Tags = tags
+	}
+	if len(f.CustomFields) > 0 {
+		defs, err := u.CustomFields(ctx, companyID)
+		if err != nil {
+			return nil, err
+		}
+		values := make(map[string]any, len(f.CustomFields))
+		for name, v := range f.CustomFields {
+			values[name] = v
+		}
+		checked, err := CheckCustomFields(defs, values, true)
+		if err != nil {
+			return nil, err
+		}
+		params.CustomFields = pqtype.NullRawMessage{RawMessage: EncodeCustomFields(checked), Valid: len(checked) > 0}
+	}
+	if f.Cursor != "" {
+		createdAt, trackingID, err := DecodeCursor(f.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		params.CursorCreatedAt = dbutil.ToNullTime(createdAt)
+		params.CursorTrackingID = dbutil.ToNullString(trackingID)
+	}
+
+	rows, err := u.repo.SearchShipments(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search shipments: %w", err)
+	}
+
+	page := &SearchPage{Shipments: make([]Shipment, 0, min(len(rows), limit))}
+	for i, r := range rows {
+		if i == limit {
+			last := rows[limit-1]
+			page.NextCursor = EncodeCursor(last.CreatedAt.Time, last.TrackingID)
+			break
+		}
+		page.Shipments = append(page.Shipments, ToDomain(r))
+	}
+	return page, nil
+}
+
+// Detail returns a live shipment with its pieces.
+func (u *Usecase) Detail(ctx context.Context, companyID uuid.UUID, trackingID string) (*Shipment, error) {
+	row, err := u.Track(ctx, companyID, trackingID)
+	if err != nil {
+		return nil, err
+	}
+	s := ToDomain(*row)
+	if s.Pieces, err = u.ListPieces(ctx, companyID, trackingID); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// EncodeCursor builds the opaque cursor pointing just after the given shipment.
+func EncodeCursor(createdAt time.Time, trackingID string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + trackingID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor reverses EncodeCursor.
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	ts, trackingID, ok := strings.Cut(string(raw), "|")
+	if !ok || trackingID == "" {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	return createdAt, trackingID, nil
+}
+
+// containsPattern turns user input into an ILIKE substring pattern,
+// escaping the wildcards so "50%" matches literally.
+func containsPattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(s) + "%"
+}
+
+func digitsOnly(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,27 @@
+package shipment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchCursor(t *testing.T) {
+	created := time.Date(2026, 4, 2, 8, 15, 30, 123456000, time.UTC)
+	createdAt, trackingID, err := DecodeCursor(EncodeCursor(created, "AWB-7|X"))
+	assert.NoError(t, err)
+	assert.True(t, created.Equal(createdAt))
+	assert.Equal(t, "AWB-7|X", trackingID)
+
+	for _, bad := range []string{"%%%", "bm8tc2VwYXJhdG9y", EncodeCursor(created, "")} {
+		_, _, err := DecodeCursor(bad)
+		assert.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+}
+
+func TestContainsPattern(t *testing.T) {
+	assert.Equal(t, "%Rua 5%", containsPattern("Rua 5"))
+	assert.Equal(t, `%50\%\_off\\%`, containsPattern(`50%_off\`))
+	assert.Equal(t, "351912345", digitsOnly("+351 912-345"))
+}
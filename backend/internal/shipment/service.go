@@ -1,7 +1,6 @@
 package shipment
 
 import (
-	"math/rand/v2"
 	"strings"
 	"sync"
 	"time"
@@ -38,10 +37,32 @@ type Service interface {
 	ResolveTimezone(country string) string
 	CalculateDeparture(now time.Time, adminTZ string) time.Time
 	CalculateArrival(departure time.Time, senderCountry, receiverCountry string) (arrival, outfordelivery time.Time)
+	CalculateRedelivery(failedAt time.Time, receiverCountry string) (arrival, outfordelivery time.Time)
+	ServiceLevel() string
 }
 
 // Calculator handles timezone resolution and timeline calculations.
-type Calculator struct{}
+// A nil Profile uses DefaultScheduleProfile; Level selects the service level.
+// Calendar (optional) marks branch and destination holidays as closed days.
+type Calculator struct {
+	Profile  *ScheduleProfile
+	Level    string
+	Calendar *HolidayCalendar
+}
+
+var defaultProfile = DefaultScheduleProfile()
+
+func (c *Calculator) profile() *ScheduleProfile {
+	if c.Profile == nil {
+		return defaultProfile
+	}
+	return c.Profile
+}
+
+// ServiceLevel returns the service level the calculator schedules for.
+func (c *Calculator) ServiceLevel() string {
+	return c.profile().ResolveService(c.Level)
+}
 
 // Ensure Calculator implements Service
 var _ Service = (*Calculator)(nil)
@@ -156,8 +177,13 @@ func (c *Calculator) ResolveTimezone(country string) string {
 }
 
 // CalculateDeparture (Algorithm A) determines when the package officially goes "In Transit".
-// Respects Nigerian Warehouse Hours (8:00 AM - 10:00 PM).
+// Applies the profile's handling delay, cut-off and weekday operating hours
+// in the branch timezone (profile timezone, then adminTZ, then Lagos).
 func (c *Calculator) CalculateDeparture(now time.Time, adminTZ string) time.Time {
+	p := c.profile()
+	if p.Timezone != "" {
+		adminTZ = p.Timezone
+	}
 	if adminTZ == "" {
 		adminTZ = "Africa/Lagos"
 	}
@@ -166,25 +192,17 @@ func (c *Calculator) CalculateDeparture(now time.Time, adminTZ string) time.Time
 		loc = time.FixedZone("WAT", 3600) // Nigeria fallback
 	}
 
-	// Transit starts exactly 1 hour after creation
-	transit := now.In(loc).Add(1 * time.Hour)
-
-	// Boundary Check
-	if transit.Hour() < 8 {
-		// Before 8am: Push to 8:00 AM today
-		transit = time.Date(transit.Year(), transit.Month(), transit.Day(), 8, 0, 0, 0, loc)
-	} else if transit.Hour() >= 22 {
-		// After 10pm: Push to 8:00 AM tomorrow
-		tomorrow := transit.Add(24 * time.Hour)
-		transit = time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 8, 0, 0, 0, loc)
-	}
-
-	return transit.UTC()
+	return p.departure(now, loc, c.Calendar).UTC()
 }
 
 // CalculateArrival determines the final delivery window.
-// Rule: Arrival is always the day following the Departure Date.
+// Transit days come from the service level's rule for the origin/destination
+// zones; arrival lands at a random time inside the delivery window, on the
+// first day the service delivers that is not a holiday in the destination.
 func (c *Calculator) CalculateArrival(departure time.Time, senderCountry, receiverCountry string) (time.Time, time.Time) {
+	p := c.profile()
+	svc := p.Services[p.ResolveService(c.Level)]
+
 	// Resolve destination timezone
 	recipientTZ := c.ResolveTimezone(receiverCountry)
 	loc, err := loadLocation(recipientTZ)
@@ -192,16 +210,47 @@ func (c *Calculator) CalculateArrival(departure time.Time, senderCountry, receiv
 		loc = time.UTC
 	}
 
-	// 1. Set baseline arrival to the day after departure
-	arrivalDate := departure.In(loc).Add(24 * time.Hour)
+	// 1. Move the departure date forward by the transit days for this lane
+	minDays, maxDays := svc.transitDays(p.ZoneOf(senderCountry), p.ZoneOf(receiverCountry))
+	arrivalDate := departure.In(loc).AddDate(0, 0, randomBetween(minDays, maxDays))
+
+	return c.deliverySlot(svc, arrivalDate, receiverCountry)
+}
+
+// CalculateRedelivery schedules the next attempt after a failed delivery:
+// the following day the service delivers that is not a holiday in the
+// destination, inside the same delivery window.
+func (c *Calculator) CalculateRedelivery(failedAt time.Time, receiverCountry string) (time.Time, time.Time) {
+	p := c.profile()
+	svc := p.Services[p.ResolveService(c.Level)]
+
+	loc, err := loadLocation(c.ResolveTimezone(receiverCountry))
+	if err != nil {
+		loc = time.UTC
+	}
+
+	arrival, outfordelivery := c.deliverySlot(svc, failedAt.In(loc).AddDate(0, 0, 1), receiverCountry)
+	if outfordelivery.Before(failedAt) {
+		outfordelivery = failedAt
+	}
+	return arrival, outfordelivery
+}
+
+// deliverySlot picks the delivery time on the first day from date on that the
+// service delivers and that is not a holiday in the destination, and the
+// out-for-delivery time before it.
+func (c *Calculator) deliverySlot(svc ServiceLevel, date time.Time, receiverCountry string) (time.Time, time.Time) {
+	for i := 0; i < maxClosedDays && (!svc.deliversOn(date) || c.Calendar.IsHoliday(receiverCountry, date)); i++ {
+		date = date.AddDate(0, 0, 1)
+	}
 
-	// 2. Set arrival time to a random window between 9:00 AM and 4:00 PM local time
-	hour := 9 + rand.IntN(7) // 9, 10, 11, 12, 13, 14, 15
-	minute := rand.IntN(60)
-	arrival := time.Date(arrivalDate.Year(), arrivalDate.Month(), arrivalDate.Day(), hour, minute, 0, 0, loc).UTC()
+	// Pick a random minute inside the delivery window (recipient local time)
+	start, _ := parseClock(svc.DeliveryStart)
+	end, _ := parseClock(svc.DeliveryEnd)
+	arrival := atClock(date, randomBetween(start, end-1)).UTC()
 
-	// 3. Out for delivery is always 3-6 hours before final arrival
-	outfordelivery := arrival.Add(-time.Duration(3+rand.IntN(3)) * time.Hour)
+	// Out for delivery precedes arrival by the service's lead time
+	outfordelivery := arrival.Add(-time.Duration(randomBetween(svc.OFDLeadMinHours, svc.OFDLeadMaxHours)) * time.Hour)
 
 	return arrival, outfordelivery
 }
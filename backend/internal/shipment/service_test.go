@@ -86,3 +86,120 @@ func TestAlgorithmB_Arrival(t *testing.T) {
 		})
 	}
 }
+
+func testProfile() *ScheduleProfile {
+	weekday := OperatingHours{Open: "09:00", Close: "17:00", Cutoff: "15:00"}
+	return &ScheduleProfile{
+		Timezone:             "Africa/Lagos",
+		HandlingDelayMinutes: 30,
+		Hours: map[string]OperatingHours{
+			"monday": weekday, "tuesday": weekday, "wednesday": weekday, "thursday": weekday, "friday": weekday,
+			"saturday": {Closed: true},
+		},
+		Zones: map[string][]string{
+			"west-africa": {"Nigeria", "Ghana"},
+			"europe":      {"Germany", "UK"},
+		},
+		DefaultService: "economy",
+		Services: map[string]ServiceLevel{
+			"economy": {Transit: []TransitRule{{From: AnyZone, To: AnyZone, MinDays: 4, MaxDays: 6}}},
+			"express": {
+				Transit: []TransitRule{
+					{From: AnyZone, To: AnyZone, MinDays: 1, MaxDays: 1},
+					{From: "west-africa", To: "europe", MinDays: 2, MaxDays: 2},
+				},
+				DeliveryStart:   "10:00",
+				DeliveryEnd:     "12:00",
+				OFDLeadMinHours: 1,
+				OFDLeadMaxHours: 1,
+			},
+		},
+	}
+}
+
+func TestProfileDeparture(t *testing.T) {
+	p := testProfile()
+	p.normalize()
+	assert.NoError(t, p.Validate())
+	calc := &Calculator{Profile: p}
+	loc, _ := time.LoadLocation("Africa/Lagos")
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected string
+	}{
+		{"Within hours", time.Date(2026, 3, 24, 9, 0, 0, 0, time.UTC), "2026-03-24 10:30:00"},
+		{"Before opening", time.Date(2026, 3, 24, 7, 0, 0, 0, time.UTC), "2026-03-24 09:00:00"},
+		{"After cutoff", time.Date(2026, 3, 24, 14, 30, 0, 0, time.UTC), "2026-03-25 09:00:00"},
+		{"Friday after cutoff skips weekend", time.Date(2026, 3, 27, 15, 0, 0, 0, time.UTC), "2026-03-30 09:00:00"},
+		{"Closed Saturday", time.Date(2026, 3, 28, 10, 0, 0, 0, time.UTC), "2026-03-30 09:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := calc.CalculateDeparture(tt.now, "UTC")
+			assert.Equal(t, tt.expected, res.In(loc).Format("2006-01-02 15:04:05"))
+		})
+	}
+}
+
+func TestProfileArrival(t *testing.T) {
+	p := testProfile()
+	p.normalize()
+	departure := time.Date(2026, 3, 24, 10, 0, 0, 0, time.UTC)
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	accra, _ := time.LoadLocation("Africa/Accra")
+
+	express := &Calculator{Profile: p, Level: "EXPRESS"}
+	assert.Equal(t, "express", express.ServiceLevel())
+
+	arrival, ofd := express.CalculateArrival(departure, "Nigeria", "Germany")
+	local := arrival.In(berlin)
+	assert.Equal(t, 26, local.Day(), "zone rule should add two transit days")
+	assert.True(t, local.Hour() >= 10 && local.Hour() < 12, "Arrival at %v should be within 10-12", local)
+	assert.Equal(t, time.Hour, arrival.Sub(ofd))
+
+	arrival, _ = express.CalculateArrival(departure, "Nigeria", "Ghana")
+	assert.Equal(t, 25, arrival.In(accra).Day(), "wildcard rule applies when no zone pair matches")
+
+	economy := &Calculator{Profile: p, Level: "unknown"}
+	assert.Equal(t, "economy", economy.ServiceLevel())
+	arrival, _ = economy.CalculateArrival(departure, "Nigeria", "Ghana")
+	day := arrival.In(accra).Day()
+	assert.True(t, day >= 28 && day <= 30, "economy transit should take 4-6 days, got day %d", day)
+}
+
+func TestProfileRedelivery(t *testing.T) {
+	p := testProfile()
+	p.normalize()
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	express := &Calculator{Profile: p, Level: "express"}
+
+	// Failed Tuesday morning: retried Wednesday inside the delivery window
+	failedAt := time.Date(2026, 3, 24, 9, 30, 0, 0, time.UTC)
+	arrival, ofd := express.CalculateRedelivery(failedAt, "Germany")
+	local := arrival.In(berlin)
+	assert.Equal(t, 25, local.Day())
+	assert.True(t, local.Hour() >= 10 && local.Hour() < 12, "Redelivery at %v should be within 10-12", local)
+	assert.Equal(t, time.Hour, arrival.Sub(ofd))
+
+	// Failed Friday on a weekday-only service: retried Monday
+	svc := p.Services["express"]
+	svc.DeliveryDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday"}
+	p.Services["express"] = svc
+	arrival, _ = express.CalculateRedelivery(time.Date(2026, 3, 27, 15, 0, 0, 0, time.UTC), "Germany")
+	assert.Equal(t, time.Monday, arrival.In(berlin).Weekday())
+}
+
+func TestProfileValidate(t *testing.T) {
+	p := testProfile()
+	p.Services["express"].Transit[1].To = "asia"
+	p.normalize()
+	assert.ErrorContains(t, p.Validate(), "unknown zone")
+
+	p = testProfile()
+	p.Hours["monday"] = OperatingHours{Open: "09:00", Close: "17:00", Cutoff: "18:00"}
+	p.normalize()
+	assert.ErrorContains(t, p.Validate(), "cutoff")
+}
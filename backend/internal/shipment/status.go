@@ -0,0 +1,90 @@
+package shipment
+
+import (
+	"fmt"
+	"sort"
+)
+
+// transitions is the allowed status graph. The scheduled path
+// (pending -> intransit -> outfordelivery -> delivered) may step backwards
+// so that rescheduling via !edit can re-resolve the status; exception states
+// must be resolved explicitly.
+var transitions = map[string][]string{
+	StatusPending:        {StatusIntransit, StatusOnHold, StatusCanceled},
+	StatusIntransit:      {StatusPending, StatusOutForDelivery, StatusDelivered, StatusOnHold, StatusCustomsHold, StatusCanceled},
+	StatusOutForDelivery: {StatusPending, StatusIntransit, StatusDelivered, StatusDeliveryFailed, StatusOnHold},
+	StatusOnHold:         {StatusPending, StatusIntransit, StatusOutForDelivery, StatusReturned, StatusCanceled},
+	StatusCustomsHold:    {StatusIntransit, StatusOnHold, StatusReturned, StatusCanceled},
+	StatusDeliveryFailed: {StatusOutForDelivery, StatusOnHold, StatusReturned},
+	StatusDelivered:      {},
+	StatusCanceled:       {},
+	StatusReturned:       {},
+}
+
+// TransitionError is returned when a status change is not allowed by the state machine.
+type TransitionError struct {
+	TrackingID string
+	From       string
+	To         string
+}
+
+func (e *TransitionError) Error() string {
+	if !IsKnownStatus(e.To) {
+		return fmt.Sprintf("unknown status %q", e.To)
+	}
+	return fmt.Sprintf("invalid status transition for %s: %s -> %s", e.TrackingID, e.From, e.To)
+}
+
+// Allowed lists the statuses reachable from the current one.
+func (e *TransitionError) Allowed() []string {
+	return AllowedTransitions(e.From)
+}
+
+// IsKnownStatus reports whether status is one of the Status* constants.
+func IsKnownStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// IsTerminalStatus reports whether no further transitions are possible.
+func IsTerminalStatus(status string) bool {
+	next, ok := transitions[status]
+	return ok && len(next) == 0
+}
+
+// IsExceptionStatus reports whether the status sits outside the scheduled path.
+// The pulse never advances shipments in an exception state.
+func IsExceptionStatus(status string) bool {
+	switch status {
+	case StatusOnHold, StatusCustomsHold, StatusDeliveryFailed:
+		return true
+	}
+	return false
+}
+
+// AllowedTransitions returns the statuses reachable from the given one, sorted.
+func AllowedTransitions(from string) []string {
+	next := append([]string(nil), transitions[from]...)
+	sort.Strings(next)
+	return next
+}
+
+// ValidateTransition checks a status change. Re-applying the current status is a no-op;
+// an empty current status (legacy rows) is treated as pending.
+func ValidateTransition(trackingID, from, to string) error {
+	if !IsKnownStatus(to) {
+		return &TransitionError{TrackingID: trackingID, From: from, To: to}
+	}
+	if from == "" {
+		from = StatusPending
+	}
+	if from == to {
+		return nil
+	}
+	for _, s := range transitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return &TransitionError{TrackingID: trackingID, From: from, To: to}
+}
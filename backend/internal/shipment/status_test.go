@@ -0,0 +1,45 @@
+package shipment
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		allowed bool
+	}{
+		{"Pulse path pending to intransit", StatusPending, StatusIntransit, true},
+		{"Reschedule intransit back to pending", StatusIntransit, StatusPending, true},
+		{"Customs hold from intransit", StatusIntransit, StatusCustomsHold, true},
+		{"Failed delivery retried", StatusDeliveryFailed, StatusOutForDelivery, true},
+		{"Legacy empty status treated as pending", "", StatusIntransit, true},
+		{"Same status is a no-op", StatusOnHold, StatusOnHold, true},
+		{"Delivered is terminal", StatusDelivered, StatusPending, false},
+		{"Cannot skip to delivery failure", StatusPending, StatusDeliveryFailed, false},
+		{"Unknown status rejected", StatusPending, "foo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTransition("AWB-000000001", tt.from, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			var te *TransitionError
+			assert.True(t, errors.As(err, &te))
+			assert.Equal(t, tt.to, te.To)
+		})
+	}
+}
+
+func TestResolveStatus_KeepsExceptionStates(t *testing.T) {
+	s := Shipment{Status: StatusCustomsHold}
+	assert.Equal(t, StatusCustomsHold, s.ResolveStatus(s.CreatedAt))
+}
@@ -0,0 +1,152 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// statusDescriptions holds the default public wording for each status step
+var statusDescriptions = map[string]string{
+	StatusPending:        "Shipment registered and awaiting dispatch",
+	StatusIntransit:      "Shipment departed the origin facility",
+	StatusOutForDelivery: "Shipment is out for delivery",
+	StatusDelivered:      "Shipment delivered to recipient",
+	StatusCanceled:       "Shipment canceled",
+	StatusOnHold:         "Shipment placed on hold",
+	StatusCustomsHold:    "Shipment held at customs",
+	StatusDeliveryFailed: "Delivery attempt failed",
+	StatusReturned:       "Shipment returned to sender",
+}
+
+// DescribeStatus returns the default timeline description for a status.
+func DescribeStatus(status string) string {
+	if d, ok := statusDescriptions[status]; ok {
+		return d
+	}
+	return "Status updated to " + status
+}
+
+// recordStatusEvent appends a status change to the shipment history.
+// Failures are logged but never block the mutation that triggered them.
+func (u *Usecase) recordStatusEvent(ctx context.Context, companyID uuid.UUID, trackingID, status, previous, description string) {
+	if status == "" || status == previous {
+		return
+	}
+	if description == "" {
+		description = DescribeStatus(status)
+	}
+	u.insertShipmentEvent(ctx, companyID, trackingID, status, previous, description)
+}
+
+// recordTimelineNote adds an entry to the shipment history that leaves its
+// status as it is, such as a rejected delivery code.
+func (u *Usecase) recordTimelineNote(ctx context.Context, companyID uuid.UUID, trackingID, status, description string) {
+	u.insertShipmentEvent(ctx, companyID, trackingID, status, status, description)
+}
+
+func (u *Usecase) insertShipmentEvent(ctx context.Context, companyID uuid.UUID, trackingID, status, previous, description string) {
+	source, actor := utils.GetSource(ctx)
+	err := u.repo.InsertShipmentEvent(ctx, db.InsertShipmentEventParams{
+		CompanyID:      toNullUUID(companyID),
+		TrackingID:     trackingID,
+		Status:         status,
+		PreviousStatus: dbutil.ToNullString(previous),
+		Source:         source,
+		Actor:          dbutil.ToNullString(actor),
+		Description:    dbutil.ToNullString(description),
+	})
+	if err != nil {
+		logger.Warn().Err(err).Str("tracking_id", trackingID).Str("status", status).Msg("Failed to record shipment event")
+	}
+}
+
+// Timeline returns the recorded history of a shipment followed by the
+// remaining scheduled milestones (marked as not completed).
+func (u *Usecase) Timeline(ctx context.Context, companyID uuid.UUID, trackingID string) ([]TimelineEvent, error) {
+	dbShip, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return u.buildTimeline(ctx, dbShip, false)
+}
+
+// PublicTimeline resolves a shipment by tracking ID alone (tracking IDs are
+// globally unique) and returns its timeline for the public tracking page.
+// Free-text notes stay internal; see buildTimeline.
+func (u *Usecase) PublicTimeline(ctx context.Context, trackingID string) ([]TimelineEvent, error) {
+	dbShip, err := u.repo.GetShipmentByTrackingID(ctx, trackingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment: %w", err)
+	}
+	return u.buildTimeline(ctx, dbShip, true)
+}
+
+// buildTimeline merges recorded events with the upcoming milestones. Public
+// timelines skip notes that left the status unchanged and describe each step
+// with its default wording only, since recorded descriptions can carry
+// free text such as return reasons or failed attempt notes.
+func (u *Usecase) buildTimeline(ctx context.Context, dbShip db.Shipment, public bool) ([]TimelineEvent, error) {
+	events, err := u.repo.ListShipmentEvents(ctx, db.ListShipmentEventsParams{CompanyID: dbShip.CompanyID, TrackingID: dbShip.TrackingID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shipment events: %w", err)
+	}
+
+	timeline := make([]TimelineEvent, 0, len(events)+3)
+	for _, e := range events {
+		if public && e.PreviousStatus.Valid && e.PreviousStatus.String == e.Status {
+			continue
+		}
+		desc := e.Description.String
+		if public || desc == "" {
+			desc = DescribeStatus(e.Status)
+		}
+		timeline = append(timeline, TimelineEvent{
+			Status:      e.Status,
+			Timestamp:   e.CreatedAt.Time,
+			Description: desc,
+			IsCompleted: true,
+		})
+	}
+
+	return append(timeline, upcomingMilestones(ToDomain(dbShip))...), nil
+}
+
+// upcomingMilestones projects the scheduled steps the shipment has not reached yet.
+func upcomingMilestones(s Shipment) []TimelineEvent {
+	steps := []struct {
+		status string
+		at     *time.Time
+	}{
+		{StatusIntransit, s.ScheduledTransitTime},
+		{StatusOutForDelivery, s.OutForDeliveryTime},
+		{StatusDelivered, s.ExpectedDeliveryTime},
+	}
+
+	reached := map[string]int{StatusPending: 0, StatusIntransit: 1, StatusOutForDelivery: 2, StatusDelivered: 3}
+	current, ok := reached[s.Status]
+	if !ok {
+		return nil // Terminal or exceptional states have no projected path
+	}
+
+	var upcoming []TimelineEvent
+	for i, step := range steps {
+		if i+1 <= current || step.at == nil {
+			continue
+		}
+		upcoming = append(upcoming, TimelineEvent{
+			Status:      step.status,
+			Timestamp:   *step.at,
+			Description: DescribeStatus(step.status),
+			IsCompleted: false,
+		})
+	}
+	return upcoming
+}
@@ -0,0 +1,90 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/trackingid"
+
+	"github.com/google/uuid"
+)
+
+// TrackingFormatKey is the SystemConfig key holding a company's tracking ID format (JSON).
+const TrackingFormatKey = "tracking_format"
+
+// TrackingFormat returns the company's tracking ID format, or the default
+// random format when none is configured.
+func (u *Usecase) TrackingFormat(ctx context.Context, companyID uuid.UUID) (trackingid.Format, error) {
+	raw, err := u.repo.GetSystemConfig(ctx, db.GetSystemConfigParams{CompanyID: companyID, Key: TrackingFormatKey})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && raw == "") {
+		return trackingid.DefaultFormat(), nil
+	}
+	if err != nil {
+		return trackingid.Format{}, fmt.Errorf("failed to get tracking format: %w", err)
+	}
+
+	var f trackingid.Format
+	if err := json.Unmarshal([]byte(raw), &f); err != nil {
+		return trackingid.Format{}, fmt.Errorf("failed to decode tracking format: %w", err)
+	}
+	return f, nil
+}
+
+// SetTrackingFormat validates and stores the company's tracking ID format.
+// Only new shipments are affected; existing IDs keep working.
+func (u *Usecase) SetTrackingFormat(ctx context.Context, companyID uuid.UUID, f trackingid.Format) error {
+	if err := f.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", trackingid.ErrInvalidFormat, err)
+	}
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to encode tracking format: %w", err)
+	}
+	if err := u.repo.SetSystemConfig(ctx, db.SetSystemConfigParams{CompanyID: companyID, Key: TrackingFormatKey, Value: string(raw)}); err != nil {
+		return fmt.Errorf("failed to save tracking format: %w", err)
+	}
+	return nil
+}
+
+// NewTrackingID issues the next tracking ID in the company's format.
+// Random IDs can collide, so callers still retry the insert on conflict.
+func (u *Usecase) NewTrackingID(ctx context.Context, companyID uuid.UUID, prefix string) (string, error) {
+	f, err := u.TrackingFormat(ctx, companyID)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	var n int64
+	if f.Sequential() {
+		n, err = u.repo.NextTrackingSequence(ctx, db.NextTrackingSequenceParams{CompanyID: companyID, Scope: f.CounterScope(now)})
+		if err != nil {
+			return "", fmt.Errorf("failed to advance tracking counter: %w", err)
+		}
+	} else if n, err = f.Random(); err != nil {
+		return "", err
+	}
+	return f.Build(prefix, now, n), nil
+}
+
+// ParseTrackingID normalises user input and rejects anything that is not a
+// tracking ID or fails the company's check digit.
+func (u *Usecase) ParseTrackingID(ctx context.Context, companyID uuid.UUID, raw string) (string, error) {
+	id, err := trackingid.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	f, err := u.TrackingFormat(ctx, companyID)
+	if err != nil {
+		return "", err
+	}
+	if err := f.Verify(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
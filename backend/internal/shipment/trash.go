@@ -0,0 +1,67 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+
+	"github.com/google/uuid"
+)
+
+// Trash lists deleted shipments that can still be restored, most recent first.
+func (u *Usecase) Trash(ctx context.Context, companyID uuid.UUID, limit, offset int32) ([]Shipment, error) {
+	rows, err := u.repo.ListTrashedShipments(ctx, db.ListTrashedShipmentsParams{CompanyID: toNullUUID(companyID), Limit: limit, Offset: offset})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	shipments := make([]Shipment, 0, len(rows))
+	for _, r := range rows {
+		shipments = append(shipments, ToDomain(r))
+	}
+	return shipments, nil
+}
+
+// Restore moves a shipment out of the trash, noting the actor from ctx on its
+// timeline. Returns sql.ErrNoRows when the shipment is not in the trash (never
+// deleted, or already purged).
+func (u *Usecase) Restore(ctx context.Context, companyID uuid.UUID, trackingID string) error {
+	result, err := u.repo.RestoreShipment(ctx, db.RestoreShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to restore shipment: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	// Noted on the timeline so it shows who brought it back
+	if ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID}); err == nil {
+		u.recordTimelineNote(ctx, companyID, trackingID, ship.Status.String, "Restored from trash")
+	}
+	return nil
+}
+
+// PurgeTrash permanently removes shipments that were trashed before the
+// cutoff, along with their proof-of-delivery images.
+func (u *Usecase) PurgeTrash(ctx context.Context, companyID uuid.UUID, deletedBefore time.Time) (int64, error) {
+	var keys []string
+	if u.Blobs != nil {
+		var err error
+		keys, err = u.repo.ListTrashedPODKeys(ctx, db.ListTrashedPODKeysParams{CompanyID: companyID, DeletedAt: dbutil.ToNullTime(deletedBefore)})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list proof of delivery: %w", err)
+		}
+	}
+	result, err := u.repo.PurgeTrashedShipments(ctx, db.PurgeTrashedShipmentsParams{
+		CompanyID: toNullUUID(companyID),
+		DeletedAt: dbutil.ToNullTime(deletedBefore),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	u.deletePODBlobs(ctx, keys)
+	purged, _ := result.RowsAffected()
+	return purged, nil
+}
@@ -0,0 +1,34 @@
+package shipment
+
+import (
+	"context"
+	"fmt"
+
+	"webtracker-bot/internal/database/db"
+)
+
+// inTx runs fn on a copy of the usecase whose repository is bound to one
+// database transaction, committing when fn returns nil and rolling back
+// otherwise. Without a Pool, or with a repository that can't join a
+// transaction (the tests' mock), fn runs on u itself. Calls nest: inside fn
+// inTx simply runs on the same transaction.
+func (u *Usecase) inTx(ctx context.Context, fn func(tx *Usecase) error) error {
+	q, ok := u.repo.(*db.Queries)
+	if u.Pool == nil || !ok {
+		return fn(u)
+	}
+	sqlTx, err := u.Pool.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	tx := *u
+	tx.repo, tx.Pool = q.WithTx(sqlTx), nil
+	if err := fn(&tx); err != nil {
+		sqlTx.Rollback()
+		return err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -5,9 +5,9 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
+	"webtracker-bot/internal/blob"
 	"webtracker-bot/internal/database/db"
 	"webtracker-bot/internal/database/dbutil"
 	"webtracker-bot/internal/models"
@@ -25,6 +25,13 @@ func toNullUUID(id uuid.UUID) uuid.NullUUID {
 type Usecase struct {
 	repo    db.Querier
 	Service Service
+	// Blobs holds proof-of-delivery images; POD uploads fail while it is nil
+	Blobs blob.Storage
+	// SendOTP delivers recipient delivery codes; none are issued while it is nil
+	SendOTP DeliveryOTPSender
+	// Pool lets multi-statement writes run in one transaction; without it
+	// they run statement by statement
+	Pool *sql.DB
 }
 
 // NewUsecase creates a new usecase layer with the given repository and service.
@@ -54,25 +61,69 @@ func (u *Usecase) Create(ctx context.Context, companyID uuid.UUID, params db.Cre
 		return fmt.Errorf("tracking ID is required")
 	}
 	params.CompanyID = toNullUUID(companyID)
+	params.CodCurrency = u.codCurrency(ctx, companyID, params.CodAmount, params.CodCurrency)
+	if len(params.CustomFields) == 0 {
+		params.CustomFields = EncodeCustomFields(nil)
+	}
+	if params.Tags == nil {
+		params.Tags = []string{}
+	}
 	err := u.repo.CreateShipment(ctx, params)
 	if err != nil {
 		return fmt.Errorf("failed to create shipment: %w", err)
 	}
+	u.recordStatusEvent(ctx, companyID, params.TrackingID, params.Status.String, "", "")
+	u.rememberContact(ctx, companyID, params.RecipientName, params.RecipientPhone, params.RecipientEmail, params.RecipientAddress, params.Destination)
+	u.rememberContact(ctx, companyID, params.SenderName, params.SenderPhone, sql.NullString{}, sql.NullString{}, params.Origin)
 	return nil
 }
 
-// UpdateStatus changes the status and destination of a shipment.
-func (u *Usecase) UpdateStatus(ctx context.Context, companyID uuid.UUID, trackingID, status, destination string) error {
+// UpdateStatus changes the status and destination of a shipment. version is
+// the version the caller last saw, or 0 to accept whatever is current; either
+// way a write that lands in between returns ErrVersionConflict.
+func (u *Usecase) UpdateStatus(ctx context.Context, companyID uuid.UUID, trackingID, status, destination string, version int32) error {
+	return u.updateStatus(ctx, companyID, trackingID, status, destination, version, false)
+}
+
+// updateStatus is UpdateStatus; undo marks the change as UndoLastEdit's, so a
+// later undo skips it.
+func (u *Usecase) updateStatus(ctx context.Context, companyID uuid.UUID, trackingID, status, destination string, version int32, undo bool) error {
+	current, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to get shipment: %w", err)
+	}
+	if version != 0 && current.Version != version {
+		return ErrVersionConflict
+	}
+	if err := ValidateTransition(trackingID, current.Status.String, status); err != nil {
+		return err
+	}
+	if status == StatusDelivered && current.Status.String != StatusDelivered {
+		if err := u.checkDeliveryOTP(ctx, companyID, trackingID); err != nil {
+			return err
+		}
+	}
+
 	params := db.UpdateShipmentStatusParams{
 		CompanyID:   toNullUUID(companyID),
 		TrackingID:  trackingID,
 		Status:      dbutil.ToNullString(status),
 		Destination: dbutil.ToNullString(destination),
+		Version:     current.Version,
 	}
-	err := u.repo.UpdateShipmentStatus(ctx, params)
-	if err != nil {
+	if err := applied(u.repo.UpdateShipmentStatus(ctx, params)); err != nil {
 		return fmt.Errorf("failed to update status: %w", err)
 	}
+	u.recordChange(ctx, companyID, trackingID, "status", current.Status, params.Status, undo)
+	u.recordChange(ctx, companyID, trackingID, "destination", current.Destination, params.Destination, undo)
+	note := ""
+	if undo {
+		note = "Status change undone"
+	}
+	u.recordStatusEvent(ctx, companyID, trackingID, status, current.Status.String, note)
+	if status == StatusOutForDelivery && current.Status.String != StatusOutForDelivery {
+		u.issueDeliveryOTP(ctx, companyID, current)
+	}
 	return nil
 }
 
@@ -90,20 +141,31 @@ func (u *Usecase) ListPaginated(ctx context.Context, companyID uuid.UUID, limit,
 	return shipments, nil
 }
 
-// Delete permanently removes a shipment record.
+// Delete moves a shipment to the trash. Returns sql.ErrNoRows when there is
+// no live shipment with that ID.
 func (u *Usecase) Delete(ctx context.Context, companyID uuid.UUID, trackingID string) error {
-	err := u.repo.DeleteShipment(ctx, db.DeleteShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	_, actor := utils.GetSource(ctx)
+	result, err := u.repo.DeleteShipment(ctx, db.DeleteShipmentParams{
+		CompanyID:  toNullUUID(companyID),
+		TrackingID: trackingID,
+		DeletedBy:  dbutil.ToNullString(actor),
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete shipment: %w", err)
 	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
 	return nil
 }
 
-// BulkDelete removes multiple shipments in a single DB round-trip.
+// BulkDelete moves multiple shipments to the trash in a single DB round-trip.
 func (u *Usecase) BulkDelete(ctx context.Context, companyID uuid.UUID, ids []string) (int64, error) {
+	_, actor := utils.GetSource(ctx)
 	result, err := u.repo.BulkDeleteShipments(ctx, db.BulkDeleteShipmentsParams{
 		CompanyID: toNullUUID(companyID),
 		Column2:   ids,
+		DeletedBy: dbutil.ToNullString(actor),
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to bulk delete shipments: %w", err)
@@ -112,42 +174,41 @@ func (u *Usecase) BulkDelete(ctx context.Context, companyID uuid.UUID, ids []str
 	return deleted, nil
 }
 
-// DeleteDelivered performs bulk cleanup of delivered shipments.
-func (u *Usecase) DeleteDelivered(ctx context.Context, companyID uuid.UUID) error {
-	err := u.repo.DeleteDeliveredShipments(ctx, toNullUUID(companyID))
+// DeleteDelivered moves every delivered shipment to the trash.
+func (u *Usecase) DeleteDelivered(ctx context.Context, companyID uuid.UUID) (int64, error) {
+	_, actor := utils.GetSource(ctx)
+	result, err := u.repo.DeleteDeliveredShipments(ctx, db.DeleteDeliveredShipmentsParams{
+		CompanyID: toNullUUID(companyID),
+		DeletedBy: dbutil.ToNullString(actor),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete delivered shipments: %w", err)
+		return 0, fmt.Errorf("failed to delete delivered shipments: %w", err)
 	}
-	return nil
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
 }
 
 // ProcessMaintenance transitions shipments strictly based on their scheduled times.
 func (u *Usecase) ProcessMaintenance(ctx context.Context, companyID uuid.UUID, now time.Time) (int, error) {
-	totalTransitioned := 0
-
-	transit, err := u.repo.TransitionStatusToIntransit(ctx, db.TransitionStatusToIntransitParams{CompanyID: toNullUUID(companyID), ScheduledTransitTime: dbutil.ToNullTime(now)})
-	if err != nil {
-		return 0, fmt.Errorf("failed to transition to intransit: %w", err)
-	}
-	totalTransitioned += len(transit)
-
-	out, err := u.repo.TransitionStatusToOutForDelivery(ctx, db.TransitionStatusToOutForDeliveryParams{CompanyID: toNullUUID(companyID), OutfordeliveryTime: dbutil.ToNullTime(now)})
-	if err != nil {
-		return totalTransitioned, fmt.Errorf("failed to transition to outfordelivery: %w", err)
-	}
-	totalTransitioned += len(out)
-
-	delivered, err := u.repo.TransitionStatusToDelivered(ctx, db.TransitionStatusToDeliveredParams{CompanyID: toNullUUID(companyID), ExpectedDeliveryTime: dbutil.ToNullTime(now)})
-	if err != nil {
-		return totalTransitioned, fmt.Errorf("failed to transition to delivered: %w", err)
-	}
-	totalTransitioned += len(delivered)
-
-	return totalTransitioned, nil
+	results, err := u.ProcessTransitions(ctx, companyID, now)
+	return len(results), err
 }
 
-// RunAgedCleanup deletes shipments that were delivered very long ago or created very long ago.
+// RunAgedCleanup deletes shipments that were delivered very long ago or created very long ago,
+// along with their proof-of-delivery images.
 func (u *Usecase) RunAgedCleanup(ctx context.Context, companyID uuid.UUID, deliveredOlderThan, createdOlderThan time.Time) (int64, error) {
+	var keys []string
+	if u.Blobs != nil {
+		var err error
+		keys, err = u.repo.ListAgedPODKeys(ctx, db.ListAgedPODKeysParams{
+			CompanyID: companyID,
+			UpdatedAt: dbutil.ToNullTime(deliveredOlderThan),
+			CreatedAt: dbutil.ToNullTime(createdOlderThan),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to list proof of delivery: %w", err)
+		}
+	}
 	params := db.RunAgedCleanupParams{
 		CompanyID: toNullUUID(companyID),
 		UpdatedAt: dbutil.ToNullTime(deliveredOlderThan),
@@ -157,21 +218,18 @@ func (u *Usecase) RunAgedCleanup(ctx context.Context, companyID uuid.UUID, deliv
 	if err != nil {
 		return 0, fmt.Errorf("failed to run aged cleanup: %w", err)
 	}
+	u.deletePODBlobs(ctx, keys)
 	deleted, _ := result.RowsAffected()
 	return deleted, nil
 }
 
 // CreateWithPrefix generates a tracking ID and inserts a new shipment.
 func (u *Usecase) CreateWithPrefix(ctx context.Context, companyID uuid.UUID, s *db.Shipment, prefix string) (string, error) {
-	if prefix == "" {
-		prefix = "AWB"
-	}
-
 	var trackingID string
 	var err error
 
 	for attempts := 0; attempts < 5; attempts++ {
-		trackingID, err = utils.GenerateTrackingID(prefix)
+		trackingID, err = u.NewTrackingID(ctx, companyID, prefix)
 		if err != nil {
 			return "", err
 		}
@@ -200,14 +258,34 @@ func (u *Usecase) CreateWithPrefix(ctx context.Context, companyID uuid.UUID, s *
 			Weight:               s.Weight,
 			Cost:                 s.Cost,
 			UpdatedAt:            sql.NullTime{Time: time.Now(), Valid: true},
+			ServiceLevel:         s.ServiceLevel,
+			CodAmount:            s.CodAmount,
+			CodCurrency:          u.codCurrency(ctx, companyID, s.CodAmount, s.CodCurrency),
+			CustomFields:         s.CustomFields,
+			Tags:                 s.Tags,
+			ReturnOf:             s.ReturnOf,
+			ReturnReason:         s.ReturnReason,
+		}
+		if len(params.CustomFields) == 0 {
+			params.CustomFields = EncodeCustomFields(nil)
+		}
+		if params.Tags == nil {
+			params.Tags = []string{}
 		}
 
 		err = u.repo.CreateShipment(ctx, params)
 		if err == nil {
+			u.recordStatusEvent(ctx, companyID, trackingID, s.Status.String, "", "")
+			// Only the recipient: the bot's sender phone is the staff member's own number.
+			// A return's recipient is the original sender, so there is nothing new to learn.
+			if s.ReturnOf.Valid {
+				return trackingID, nil
+			}
+			u.rememberContact(ctx, companyID, s.RecipientName, s.RecipientPhone, s.RecipientEmail, s.RecipientAddress, s.Destination)
 			return trackingID, nil
 		}
 
-		if !strings.Contains(err.Error(), "duplicate key value") && !strings.Contains(err.Error(), "23505") {
+		if !dbutil.IsUniqueViolation(err) {
 			return "", fmt.Errorf("failed to create shipment: %w", err)
 		}
 	}
@@ -239,10 +317,19 @@ func (u *Usecase) GetLastForUser(ctx context.Context, companyID uuid.UUID, userJ
 
 // UpdateField updates a single field on a shipment by name.
 func (u *Usecase) UpdateField(ctx context.Context, companyID uuid.UUID, trackingID, field, value string) error {
+	return u.UpdateFieldAt(ctx, companyID, trackingID, field, value, 0)
+}
+
+// UpdateFieldAt is UpdateField for a caller that decided on value from an
+// earlier read: it returns ErrVersionConflict unless the shipment is still at
+// version (0 accepts any version).
+func (u *Usecase) UpdateFieldAt(ctx context.Context, companyID uuid.UUID, trackingID, field, value string, version int32) error {
 	params := db.UpdateShipmentDynamicParams{
 		CompanyID:  toNullUUID(companyID),
 		TrackingID: trackingID,
 	}
+	// What ends up in the change history; times are normalised to one layout
+	newValue := dbutil.ToNullString(value)
 
 	switch field {
 	case "sender_name":
@@ -271,25 +358,59 @@ func (u *Usecase) UpdateField(ctx context.Context, companyID uuid.UUID, tracking
 			return fmt.Errorf("invalid time format: %w", err)
 		}
 		params.Column13 = t
+		newValue = dbutil.ToNullString(t.UTC().Format(changeTimeLayout))
 	case "expected_delivery_time":
 		t, err := parseFlexibleTime(value)
 		if err != nil {
 			return fmt.Errorf("invalid time format: %w", err)
 		}
 		params.Column14 = t
+		newValue = dbutil.ToNullString(t.UTC().Format(changeTimeLayout))
 	case "outfordelivery_time":
 		t, err := parseFlexibleTime(value)
 		if err != nil {
 			return fmt.Errorf("invalid time format: %w", err)
 		}
 		params.Column15 = t
+		newValue = dbutil.ToNullString(t.UTC().Format(changeTimeLayout))
 	case "status":
 		params.Column16 = value
 	default:
 		return fmt.Errorf("unsupported field: %s", field)
 	}
 
-	return u.repo.UpdateShipmentDynamic(ctx, params)
+	current, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: trackingID})
+	if err != nil {
+		return fmt.Errorf("failed to get shipment: %w", err)
+	}
+	if version != 0 && current.Version != version {
+		return ErrVersionConflict
+	}
+	params.Version = current.Version
+	if field == "status" {
+		if err := ValidateTransition(trackingID, current.Status.String, value); err != nil {
+			return err
+		}
+		if value == StatusDelivered && current.Status.String != StatusDelivered {
+			if err := u.checkDeliveryOTP(ctx, companyID, trackingID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := applied(u.repo.UpdateShipmentDynamic(ctx, params)); err != nil {
+		return err
+	}
+	// An empty value leaves the column untouched
+	if newValue.Valid {
+		u.recordChange(ctx, companyID, trackingID, field, fieldValue(current, field), newValue, false)
+	}
+	if field == "status" {
+		u.recordStatusEvent(ctx, companyID, trackingID, value, current.Status.String, "")
+		if value == StatusOutForDelivery && current.Status.String != StatusOutForDelivery {
+			u.issueDeliveryOTP(ctx, companyID, current)
+		}
+	}
+	return nil
 }
 
 func parseFlexibleTime(value string) (time.Time, error) {
@@ -340,35 +461,28 @@ type TransitionResult struct {
 	NewStatus      string
 	UserJID        string
 	RecipientEmail string
+	CodAmount      float64
+	CodCurrency    string
 }
 
+// ProcessTransitions advances every shipment whose scheduled times have
+// passed. Shipments that change while being advanced are retried, see advance.
 func (u *Usecase) ProcessTransitions(ctx context.Context, companyID uuid.UUID, now time.Time) ([]TransitionResult, error) {
-	var results []TransitionResult
-
-	transit, err := u.repo.TransitionStatusToIntransit(ctx, db.TransitionStatusToIntransitParams{CompanyID: toNullUUID(companyID), ScheduledTransitTime: dbutil.ToNullTime(now)})
+	due, err := u.repo.ListDueTransitions(ctx, db.ListDueTransitionsParams{CompanyID: toNullUUID(companyID), Now: now})
 	if err != nil {
-		return nil, err
-	}
-	for _, t := range transit {
-		results = append(results, TransitionResult{TrackingID: t.TrackingID, NewStatus: t.NewStatus.String, UserJID: t.UserJid, RecipientEmail: t.RecipientEmail.String})
+		return nil, fmt.Errorf("failed to list due transitions: %w", err)
 	}
 
-	out, err := u.repo.TransitionStatusToOutForDelivery(ctx, db.TransitionStatusToOutForDeliveryParams{CompanyID: toNullUUID(companyID), OutfordeliveryTime: dbutil.ToNullTime(now)})
-	if err != nil {
-		return results, err
-	}
-	for _, t := range out {
-		results = append(results, TransitionResult{TrackingID: t.TrackingID, NewStatus: t.NewStatus.String, UserJID: t.UserJid, RecipientEmail: t.RecipientEmail.String})
-	}
+	held := u.riderHeld(ctx, companyID, due)
 
-	delivered, err := u.repo.TransitionStatusToDelivered(ctx, db.TransitionStatusToDeliveredParams{CompanyID: toNullUUID(companyID), ExpectedDeliveryTime: dbutil.ToNullTime(now)})
-	if err != nil {
-		return results, err
-	}
-	for _, t := range delivered {
-		results = append(results, TransitionResult{TrackingID: t.TrackingID, NewStatus: t.NewStatus.String, UserJID: t.UserJid, RecipientEmail: t.RecipientEmail.String})
+	var results []TransitionResult
+	for _, s := range due {
+		moved, err := u.advance(ctx, companyID, s, now, held[s.TrackingID])
+		results = append(results, moved...)
+		if err != nil {
+			return results, err
+		}
 	}
-
 	return results, nil
 }
 
@@ -401,11 +515,71 @@ func (u *Usecase) GetRecentEvents(ctx context.Context, companyID uuid.UUID, limi
 	return u.repo.GetRecentEvents(ctx, db.GetRecentEventsParams{CompanyID: toNullUUID(companyID), Limit: limit})
 }
 
-// BulkUpdateStatus updates the status for multiple shipments at once.
+// BulkUpdateStatus updates the status for multiple shipments at once. It is
+// all-or-nothing: when a shipment can't make the move, or was written by
+// someone else after it was checked (*BulkConflictError), none change.
 func (u *Usecase) BulkUpdateStatus(ctx context.Context, companyID uuid.UUID, ids []string, status string) error {
-	return u.repo.BulkUpdateStatus(ctx, db.BulkUpdateStatusParams{
-		CompanyID: toNullUUID(companyID),
-		Column2:   ids, // BulkUpdateStatusParams expects Column2
-		Status:    dbutil.ToNullString(status),
+	previous, err := u.repo.GetShipmentStatuses(ctx, db.GetShipmentStatusesParams{CompanyID: toNullUUID(companyID), Column2: ids})
+	if err != nil {
+		return fmt.Errorf("failed to load current statuses: %w", err)
+	}
+	if !IsKnownStatus(status) {
+		return &TransitionError{To: status}
+	}
+	checkedIDs := make([]string, 0, len(previous))
+	versions := make([]int32, 0, len(previous))
+	for _, p := range previous {
+		if err := ValidateTransition(p.TrackingID, p.Status.String, status); err != nil {
+			return err
+		}
+		if status == StatusDelivered && p.Status.String != StatusDelivered {
+			if err := u.checkDeliveryOTP(ctx, companyID, p.TrackingID); err != nil {
+				return err
+			}
+		}
+		checkedIDs = append(checkedIDs, p.TrackingID)
+		versions = append(versions, p.Version)
+	}
+
+	err = u.inTx(ctx, func(tx *Usecase) error {
+		updated, err := tx.repo.BulkUpdateStatus(ctx, db.BulkUpdateStatusParams{
+			CompanyID: toNullUUID(companyID),
+			Column2:   checkedIDs,
+			Status:    dbutil.ToNullString(status),
+			Column4:   versions,
+		})
+		if err != nil {
+			return err
+		}
+		if len(updated) < len(checkedIDs) {
+			done := make(map[string]bool, len(updated))
+			for _, id := range updated {
+				done[id] = true
+			}
+			conflict := &BulkConflictError{}
+			for _, id := range checkedIDs {
+				if !done[id] {
+					conflict.IDs = append(conflict.IDs, id)
+				}
+			}
+			return conflict
+		}
+		for _, p := range previous {
+			tx.recordChange(ctx, companyID, p.TrackingID, "status", p.Status, dbutil.ToNullString(status), false)
+			tx.recordStatusEvent(ctx, companyID, p.TrackingID, status, p.Status.String, "")
+		}
+		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range previous {
+		if status == StatusOutForDelivery && p.Status.String != StatusOutForDelivery && u.SendOTP != nil {
+			if ship, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: p.TrackingID}); err == nil {
+				u.issueDeliveryOTP(ctx, companyID, ship)
+			}
+		}
+	}
+	return nil
 }
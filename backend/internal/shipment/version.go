@@ -0,0 +1,126 @@
+package shipment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/database/dbutil"
+	"webtracker-bot/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+// ErrVersionConflict is returned when a shipment was written by someone else
+// between reading it and updating it.
+var ErrVersionConflict = errors.New("shipment was modified concurrently")
+
+// BulkConflictError lists the shipments of a bulk change that were written
+// by someone else after they were checked. The change is not applied to any
+// of them.
+type BulkConflictError struct {
+	IDs []string
+}
+
+func (e *BulkConflictError) Error() string {
+	return fmt.Sprintf("shipments modified concurrently: %s", strings.Join(e.IDs, ", "))
+}
+
+func (e *BulkConflictError) Unwrap() error { return ErrVersionConflict }
+
+// maxTransitionRetries bounds how often the pulse re-reads a shipment that
+// keeps changing under it before leaving it for the next tick.
+const maxTransitionRetries = 3
+
+// applied turns the result of a version-checked update into ErrVersionConflict
+// when no row matched.
+func applied(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// nextDueStatus is the pulse's next step for s at now, or "" when none is due.
+func nextDueStatus(s db.Shipment, now time.Time) string {
+	due := func(t sql.NullTime) bool { return t.Valid && !t.Time.After(now) }
+	switch s.Status.String {
+	case StatusPending:
+		if due(s.ScheduledTransitTime) {
+			return StatusIntransit
+		}
+	case StatusIntransit:
+		if due(s.OutfordeliveryTime) {
+			return StatusOutForDelivery
+		}
+	case StatusOutForDelivery:
+		if due(s.ExpectedDeliveryTime) {
+			return StatusDelivered
+		}
+	}
+	return ""
+}
+
+// advance moves one shipment through every step that is due. Each step only
+// applies to the version it was resolved from; when the row changed in the
+// meantime (an admin override, a bot edit) it is re-read and re-resolved, so
+// the pulse never writes over a newer change. A shipment held by a rider
+// stops at intransit: going out and being delivered is up to the rider. One
+// with a pending delivery code stops at outfordelivery.
+func (u *Usecase) advance(ctx context.Context, companyID uuid.UUID, s db.Shipment, now time.Time, held bool) ([]TransitionResult, error) {
+	var results []TransitionResult
+	retries := 0
+	for {
+		next := nextDueStatus(s, now)
+		if next == "" || (held && next != StatusIntransit) {
+			return results, nil
+		}
+		if next == StatusDelivered {
+			// Waits out for delivery until the recipient's code is entered
+			if err := u.checkDeliveryOTP(ctx, companyID, s.TrackingID); errors.Is(err, ErrOTPRequired) {
+				return results, nil
+			} else if err != nil {
+				return results, err
+			}
+		}
+		err := applied(u.repo.UpdateShipmentDynamic(ctx, db.UpdateShipmentDynamicParams{
+			CompanyID:  toNullUUID(companyID),
+			TrackingID: s.TrackingID,
+			Column16:   next,
+			Version:    s.Version,
+		}))
+		if errors.Is(err, ErrVersionConflict) {
+			if retries++; retries > maxTransitionRetries {
+				logger.Warn().Str("tracking_id", s.TrackingID).Msg("Transition still conflicting, leaving it for the next tick")
+				return results, nil
+			}
+			fresh, err := u.repo.GetShipment(ctx, db.GetShipmentParams{CompanyID: toNullUUID(companyID), TrackingID: s.TrackingID})
+			if errors.Is(err, sql.ErrNoRows) {
+				return results, nil // deleted in the meantime
+			}
+			if err != nil {
+				return results, fmt.Errorf("failed to reload %s: %w", s.TrackingID, err)
+			}
+			s = fresh
+			continue
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to transition %s to %s: %w", s.TrackingID, next, err)
+		}
+
+		u.recordStatusEvent(ctx, companyID, s.TrackingID, next, s.Status.String, "")
+		if next == StatusOutForDelivery {
+			u.issueDeliveryOTP(ctx, companyID, s)
+		}
+		results = append(results, TransitionResult{TrackingID: s.TrackingID, NewStatus: next, UserJID: s.UserJid, RecipientEmail: s.RecipientEmail.String, CodAmount: s.CodAmount, CodCurrency: s.CodCurrency.String})
+		s.Status = dbutil.ToNullString(next)
+		s.Version++
+	}
+}
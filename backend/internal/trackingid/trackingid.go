@@ -0,0 +1,194 @@
+// Package trackingid generates, parses and validates shipment tracking IDs.
+//
+// Every ID is PREFIX-DIGITS, optionally with a date group (PREFIX-YYMMDD-DIGITS).
+// Companies pick how the digits are produced (random, a running counter or a
+// per-day counter) and can append a UPU S10-style mod-11 check digit.
+package trackingid
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Schemes for the numeric part of a tracking ID
+const (
+	SchemeRandom     = "random"     // AWB-482913075
+	SchemeSequential = "sequential" // AWB-000001234
+	SchemeDate       = "date"       // AWB-260517-0042
+)
+
+// DefaultPrefix is used when a company has no tracking prefix
+const DefaultPrefix = "AWB"
+
+// Digit bounds per scheme; the date scheme's counter resets daily so it needs fewer.
+const (
+	MinDigits     = 4
+	MaxDigits     = 12
+	MaxDateDigits = 8
+)
+
+var (
+	// ErrInvalid is returned for input that is not shaped like a tracking ID.
+	ErrInvalid = errors.New("invalid tracking ID")
+	// ErrCheckDigit is returned when the check digit does not match, usually a typo.
+	ErrCheckDigit = errors.New("tracking ID check digit mismatch")
+	// ErrInvalidFormat wraps validation failures when saving a format.
+	ErrInvalidFormat = errors.New("invalid tracking ID format")
+)
+
+var (
+	prefixRe = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,4}$`)
+	idRe     = regexp.MustCompile(`^([A-Z][A-Z0-9]{0,4})-(?:(\d{6})-)?(\d{4,13})$`)
+)
+
+// Format describes how a company's tracking IDs are generated.
+type Format struct {
+	Scheme string `json:"scheme"`
+	// Digits is the width of the numeric part (zero-padded), without the check digit.
+	Digits     int  `json:"digits"`
+	CheckDigit bool `json:"check_digit"`
+}
+
+// DefaultFormat matches the IDs issued before formats were configurable.
+func DefaultFormat() Format {
+	return Format{Scheme: SchemeRandom, Digits: 9}
+}
+
+// Validate checks the scheme and digit width.
+func (f Format) Validate() error {
+	maxDigits := MaxDigits
+	switch f.Scheme {
+	case SchemeRandom, SchemeSequential:
+	case SchemeDate:
+		maxDigits = MaxDateDigits
+	default:
+		return fmt.Errorf("scheme must be one of %s, %s or %s", SchemeRandom, SchemeSequential, SchemeDate)
+	}
+	if f.Digits < MinDigits || f.Digits > maxDigits {
+		return fmt.Errorf("digits must be between %d and %d for the %s scheme", MinDigits, maxDigits, f.Scheme)
+	}
+	return nil
+}
+
+// Sequential reports whether the format draws its numbers from a counter.
+func (f Format) Sequential() bool {
+	return f.Scheme == SchemeSequential || f.Scheme == SchemeDate
+}
+
+// CounterScope is the counter the next number comes from: one running
+// sequence, or one per day for the date scheme.
+func (f Format) CounterScope(now time.Time) string {
+	if f.Scheme == SchemeDate {
+		return now.Format("060102")
+	}
+	return ""
+}
+
+// Random draws a number that fits the format's digit width.
+func (f Format) Random() (int64, error) {
+	n, err := rand.Int(rand.Reader, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(f.Digits)), nil))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate random ID: %w", err)
+	}
+	return n.Int64(), nil
+}
+
+// Build formats a tracking ID from a prefix and number. Counters that outgrow
+// the digit width simply get longer.
+func (f Format) Build(prefix string, now time.Time, n int64) string {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	number := fmt.Sprintf("%0*d", f.Digits, n)
+	date := ""
+	if f.Scheme == SchemeDate {
+		date = now.Format("060102")
+	}
+	if f.CheckDigit {
+		number += string(rune('0' + CheckDigit(date+number)))
+	}
+	if date != "" {
+		return prefix + "-" + date + "-" + number
+	}
+	return prefix + "-" + number
+}
+
+// Verify checks the check digit of an ID issued in this format. IDs with a
+// different shape — typically issued before the format changed — are accepted.
+func (f Format) Verify(id string) error {
+	if !f.CheckDigit {
+		return nil
+	}
+	m := idRe.FindStringSubmatch(id)
+	if m == nil {
+		return ErrInvalid
+	}
+	date, number := m[2], m[3]
+	if (f.Scheme == SchemeDate) != (date != "") || len(number) != f.Digits+1 {
+		return nil
+	}
+	body, check := date+number[:len(number)-1], number[len(number)-1]
+	if int(check-'0') != CheckDigit(body) {
+		return ErrCheckDigit
+	}
+	return nil
+}
+
+// CheckDigit computes the UPU S10 mod-11 check digit, cycling the S10
+// weights (8 6 4 2 3 5 9 7) over digits of any length.
+func CheckDigit(digits string) int {
+	weights := [...]int{8, 6, 4, 2, 3, 5, 9, 7}
+	sum := 0
+	for i, c := range digits {
+		sum += int(c-'0') * weights[i%len(weights)]
+	}
+	switch check := 11 - sum%11; check {
+	case 10:
+		return 0
+	case 11:
+		return 5
+	default:
+		return check
+	}
+}
+
+// ValidPrefix reports whether p can start a tracking ID: 1-5 letters or
+// digits, starting with a letter.
+func ValidPrefix(p string) bool {
+	return prefixRe.MatchString(p)
+}
+
+// Parse normalises user input (case, surrounding spaces and WhatsApp
+// formatting such as *bold*) and checks it is shaped like a tracking ID.
+func Parse(raw string) (string, error) {
+	id := strings.ToUpper(strings.Trim(strings.TrimSpace(raw), "*_~`\"'"))
+	if !idRe.MatchString(id) {
+		return "", ErrInvalid
+	}
+	return id, nil
+}
+
+// Looks reports whether s is shaped like a tracking ID, e.g. to tell
+// "!edit AWB-123456789 name: X" apart from "!edit name: X".
+func Looks(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// Find returns the first tracking ID in free text, such as a quoted receipt
+// caption or bot reply, or "" if there is none.
+func Find(text string) string {
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return r == ' ' || r == '\n' || r == '\t' || r == ':' || r == ',' || r == '/' || r == '(' || r == ')'
+	}) {
+		if id, err := Parse(word); err == nil {
+			return id
+		}
+	}
+	return ""
+}
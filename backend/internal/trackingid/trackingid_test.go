@@ -0,0 +1,72 @@
+package trackingid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDigit(t *testing.T) {
+	// UPU S10 reference: RR 47312482 9 GB
+	assert.Equal(t, 9, CheckDigit("47312482"))
+	assert.Equal(t, 5, CheckDigit("00000000")) // 11 → 5
+}
+
+func TestBuildAndVerify(t *testing.T) {
+	now := time.Date(2026, 5, 17, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "AWB-000000042", DefaultFormat().Build("", now, 42))
+	assert.Equal(t, "LG-000123", Format{Scheme: SchemeSequential, Digits: 6}.Build("LG", now, 123))
+	assert.Equal(t, "ACME-260517-0042", Format{Scheme: SchemeDate, Digits: 4}.Build("ACME", now, 42))
+
+	for _, f := range []Format{
+		{Scheme: SchemeRandom, Digits: 8, CheckDigit: true},
+		{Scheme: SchemeSequential, Digits: 6, CheckDigit: true},
+		{Scheme: SchemeDate, Digits: 4, CheckDigit: true},
+	} {
+		id := f.Build("XY", now, 4731)
+		assert.NoError(t, f.Verify(id), id)
+		// Change the last digit: every single-digit typo is caught
+		last := id[len(id)-1]
+		typo := id[:len(id)-1] + string('0'+(last-'0'+1)%10)
+		assert.ErrorIs(t, f.Verify(typo), ErrCheckDigit, typo)
+	}
+
+	// IDs issued before the check digit was turned on are left alone
+	f := Format{Scheme: SchemeRandom, Digits: 9, CheckDigit: true}
+	assert.NoError(t, f.Verify("AWB-123456789"))
+}
+
+func TestParse(t *testing.T) {
+	for raw, want := range map[string]string{
+		"awb-123456789":     "AWB-123456789",
+		" *LG-1234* ":       "LG-1234",
+		"ACME5-260517-0042": "ACME5-260517-0042",
+		"A-0001":            "A-0001",
+	} {
+		got, err := Parse(raw)
+		require.NoError(t, err, raw)
+		assert.Equal(t, want, got)
+	}
+	for _, raw := range []string{"", "name:", "AWB123456789", "TOOLONG-1234", "5AB-1234", "AWB-123"} {
+		_, err := Parse(raw)
+		assert.ErrorIs(t, err, ErrInvalid, raw)
+	}
+}
+
+func TestFind(t *testing.T) {
+	assert.Equal(t, "AWB-123456789", Find("📦 *SHIPMENT INFORMATION CREATED*\n\nTracking ID: *AWB-123456789*\n"))
+	assert.Equal(t, "LG-1234", Find("track: https://example.com/track/LG-1234"))
+	assert.Empty(t, Find("no id in here, AWB-12"))
+}
+
+func TestFormatValidate(t *testing.T) {
+	assert.NoError(t, DefaultFormat().Validate())
+	assert.Error(t, Format{Scheme: "uuid", Digits: 9}.Validate())
+	assert.Error(t, Format{Scheme: SchemeRandom, Digits: 3}.Validate())
+	assert.Error(t, Format{Scheme: SchemeDate, Digits: 9}.Validate())
+	assert.True(t, ValidPrefix("LGS12"))
+	assert.False(t, ValidPrefix("LG S"))
+}
@@ -21,6 +21,20 @@ const (
 	MessageIDKey   contextKey = "message_id"
 	// TextKey is the context key for the original message text.
 	TextKey        contextKey = "text"
+	// SourceKey is the context key for the channel that triggered a mutation (bot, api, system).
+	SourceKey      contextKey = "source"
+	// ActorKey is the context key for who triggered a mutation (JID or admin email).
+	ActorKey       contextKey = "actor"
+	// ChangeBatchKey is the context key grouping field changes that are undone together.
+	ChangeBatchKey contextKey = "change_batch"
+)
+
+// Mutation sources recorded alongside shipment history
+const (
+	SourceSystem = "system"
+	SourceBot    = "bot"
+	SourceAPI    = "api"
+	SourceCSV    = "csv"
 )
 
 // GetJID safely extracts the sender's JID from context
@@ -118,3 +132,35 @@ func WithValues(ctx context.Context, jid, phone string, isAdmin bool, chatJid, m
 	ctx = context.WithValue(ctx, TextKey, text)
 	return ctx
 }
+
+// WithSource tags the context with the channel and actor responsible for a mutation
+func WithSource(ctx context.Context, source, actor string) context.Context {
+	ctx = context.WithValue(ctx, SourceKey, source)
+	ctx = context.WithValue(ctx, ActorKey, actor)
+	return ctx
+}
+
+// GetSource returns the mutation source and actor from context.
+// Bot contexts (carrying a sender JID) default to "bot"; anything else is "system".
+func GetSource(ctx context.Context) (string, string) {
+	actor, _ := ctx.Value(ActorKey).(string)
+	if source, ok := ctx.Value(SourceKey).(string); ok && source != "" {
+		return source, actor
+	}
+	if jid, ok := ctx.Value(JIDKey).(string); ok && jid != "" {
+		return SourceBot, jid
+	}
+	return SourceSystem, actor
+}
+
+// WithChangeBatch groups every field change made with this context under one
+// batch, so a single !edit can be undone as a whole
+func WithChangeBatch(ctx context.Context, batch string) context.Context {
+	return context.WithValue(ctx, ChangeBatchKey, batch)
+}
+
+// GetChangeBatch returns the change batch from context, or "" when there is none
+func GetChangeBatch(ctx context.Context) string {
+	batch, _ := ctx.Value(ChangeBatchKey).(string)
+	return batch
+}
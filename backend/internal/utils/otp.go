@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// NewOTP generates a random 6 digit code and its bcrypt hash. Only the hash
+// should be stored; the code goes to the user.
+func NewOTP() (code, hash string, err error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate secure OTP: %w", err)
+	}
+	code = fmt.Sprintf("%06d", n.Int64())
+	h, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash OTP: %w", err)
+	}
+	return code, string(h), nil
+}
+
+// MatchOTP reports whether code is the one hash was made from by NewOTP.
+func MatchOTP(hash, code string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil
+}
@@ -72,7 +72,7 @@ func checkCacheCleanup(bot *BotInstance) {
 }
 
 // HandleEvent processes incoming WhatsApp events.
-func HandleEvent(bot *BotInstance, evt interface{}, queue chan<- models.Job, cfg *config.Config, configUC models.ConfigUsecase) {
+func HandleEvent(bot *BotInstance, evt interface{}, queue chan<- models.Job, cfg *config.Config, configUC models.ConfigUsecase, shipUC models.ShipmentUsecase) {
 	client := bot.WA
 	companyID := bot.CompanyID
 
@@ -92,10 +92,14 @@ func HandleEvent(bot *BotInstance, evt interface{}, queue chan<- models.Job, cfg
 			text = v.Message.GetConversation()
 		} else if v.Message.GetExtendedTextMessage().GetText() != "" {
 			text = v.Message.GetExtendedTextMessage().GetText()
+		} else if v.Message.GetImageMessage().GetCaption() != "" {
+			text = v.Message.GetImageMessage().GetCaption()
 		}
 
 		docMsg := v.Message.GetDocumentMessage()
-		if text == "" && docMsg == nil {
+		isPhoto := v.Message.GetImageMessage() != nil
+		isLocation := v.Message.GetLocationMessage() != nil || v.Message.GetLiveLocationMessage() != nil
+		if text == "" && docMsg == nil && !isPhoto && !isLocation {
 			return
 		}
 
@@ -159,7 +163,7 @@ func HandleEvent(bot *BotInstance, evt interface{}, queue chan<- models.Job, cfg
 			if isAuthorized {
 				if v.Info.IsFromMe {
 					isSenderAdmin = true
-				} else if strings.HasPrefix(text, "!") || strings.HasPrefix(text, "#") {
+				} else if strings.HasPrefix(text, "!") || strings.HasPrefix(text, "#") || isPhoto {
 					// Check Cache First
 					senderBare := utils.GetBarePhone(v.Info.Sender.User)
 					if groupAdmins, ok := bot.ParticipantsCache.Load(chatJID.String()); ok {
@@ -206,6 +210,13 @@ func HandleEvent(bot *BotInstance, evt interface{}, queue chan<- models.Job, cfg
 				hasGroups, _ := configUC.HasAuthorizedGroups(context.Background(), companyID)
 				isAuthorized = !hasGroups // Failover: Allow private if no groups exist
 			}
+			if !isAuthorized && shipUC != nil && (strings.HasPrefix(text, "!") || strings.HasPrefix(text, "#") || isLocation || isPhoto) {
+				// Dispatch riders send their status commands, locations and
+				// delivery photos from private chats
+				if _, err := shipUC.RiderByPhone(context.Background(), companyID, senderPhone); err == nil {
+					isAuthorized = true
+				}
+			}
 
 			if !isAuthorized {
 				logger.Debug().
@@ -259,16 +259,15 @@ func (m *Manager) InitBotForCompany(c db.Company) error {
 
 	m.WG.Add(1)
 	w := &worker.Worker{
-		ID:              int(c.ID.ID()),
-		Jobs:            bot.Jobs,
-		WG:              m.WG,
-		Cfg:             m.Cfg,
-		ShipmentUC:      m.ShipmentUC,
-		ConfigUC:        m.ConfigUC,
-		FrontendURL:     m.Cfg.FrontendURL,
-		ShipmentService: m.ShipmentUC.GetService(),
-		Bots:            m,
-		Context:         m.Context,
+		ID:          int(c.ID.ID()),
+		Jobs:        bot.Jobs,
+		WG:          m.WG,
+		Cfg:         m.Cfg,
+		ShipmentUC:  m.ShipmentUC,
+		ConfigUC:    m.ConfigUC,
+		FrontendURL: m.Cfg.FrontendURL,
+		Bots:        m,
+		Context:     m.Context,
 	}
 	go w.Start()
 
@@ -308,7 +307,7 @@ func (m *Manager) InitBotForCompany(c db.Company) error {
 
 // HandleWAEvent proxies events to the specific bot instance.
 func (m *Manager) HandleWAEvent(bot *BotInstance, evt interface{}) {
-	HandleEvent(bot, evt, bot.Jobs, m.Cfg, m.ConfigUC)
+	HandleEvent(bot, evt, bot.Jobs, m.Cfg, m.ConfigUC, m.ShipmentUC)
 
 	switch evt.(type) {
 	case *events.Connected, *events.PairSuccess:
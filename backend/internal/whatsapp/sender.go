@@ -259,6 +259,56 @@ func (s *Sender) SendImage(chat, sender types.JID, imageBytes []byte, caption st
 	return nil
 }
 
+// SendDocument uploads a file (e.g. a PDF invoice) and queues it as a document
+// message. Like SendImage, the upload happens before enqueueing.
+func (s *Sender) SendDocument(chat, sender types.JID, data []byte, fileName, mimeType, caption string, quotedID string, quotedText string) error {
+	uploadStart := time.Now()
+
+	uploadCtx, uploadCancel := context.WithTimeout(s.ctx, 60*time.Second)
+	uploaded, err := s.Client.Upload(uploadCtx, data, whatsmeow.MediaDocument)
+	uploadCancel()
+
+	if err != nil {
+		senderLog.Error().
+			Err(err).
+			Str("company", s.CompanyName).
+			Str("chat", chat.String()).
+			Str("file", fileName).
+			Int("document_bytes", len(data)).
+			Dur("upload_duration_ms", time.Since(uploadStart)).
+			Msg("Failed to upload document to WhatsApp")
+		return err
+	}
+
+	docMsg := &waProto.DocumentMessage{
+		URL:           models.StrPtr(uploaded.URL),
+		DirectPath:    models.StrPtr(uploaded.DirectPath),
+		MediaKey:      uploaded.MediaKey,
+		Mimetype:      models.StrPtr(mimeType),
+		FileEncSHA256: uploaded.FileEncSHA256,
+		FileSHA256:    uploaded.FileSHA256,
+		FileLength:    models.Uint64Ptr(uint64(len(data))),
+		FileName:      models.StrPtr(fileName),
+		Title:         models.StrPtr(fileName),
+	}
+
+	if caption != "" {
+		docMsg.Caption = models.StrPtr(caption + BotFooter)
+	}
+
+	if quotedID != "" {
+		docMsg.ContextInfo = &waProto.ContextInfo{
+			StanzaID:      models.StrPtr(quotedID),
+			Participant:   models.StrPtr(sender.String()),
+			QuotedMessage: &waProto.Message{Conversation: models.StrPtr(quotedText)},
+		}
+	}
+
+	content := &waProto.Message{DocumentMessage: docMsg}
+	s.enqueue(OutboundMessage{Chat: chat, Content: content, msgType: "document"})
+	return nil
+}
+
 // SetTyping sends a typing presence indicator.
 // Errors are logged but not returned — a failed typing indicator is non-critical.
 func (s *Sender) SetTyping(chat types.JID, typing bool) {
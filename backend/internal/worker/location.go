@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types/events"
+
+	"webtracker-bot/internal/geo"
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/logger"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/trackingid"
+)
+
+// sharedLocation extracts a location share and the text of the message it
+// replies to, if any. first is false for the follow-up updates of a live share.
+func sharedLocation(msg *events.Message) (cp models.Checkpoint, quoted string, first, ok bool) {
+	if msg == nil || msg.Message == nil {
+		return cp, "", false, false
+	}
+	var ctxInfo *waE2E.ContextInfo
+	if loc := msg.Message.GetLocationMessage(); loc != nil {
+		cp = models.Checkpoint{
+			Latitude:  loc.GetDegreesLatitude(),
+			Longitude: loc.GetDegreesLongitude(),
+			AccuracyM: int32(loc.GetAccuracyInMeters()),
+			Live:      loc.GetIsLive(),
+		}
+		ctxInfo, first = loc.GetContextInfo(), true
+	} else if live := msg.Message.GetLiveLocationMessage(); live != nil {
+		cp = models.Checkpoint{
+			Latitude:  live.GetDegreesLatitude(),
+			Longitude: live.GetDegreesLongitude(),
+			AccuracyM: int32(live.GetAccuracyInMeters()),
+			Live:      true,
+		}
+		ctxInfo, first = live.GetContextInfo(), live.GetSequenceNumber() == 0
+	} else {
+		return cp, "", false, false
+	}
+
+	return cp, quotedText(ctxInfo), first, true
+}
+
+// quotedText is the text of the message a reply quotes, so that a tracking
+// ID in a receipt's caption can be found; empty when it isn't a reply.
+func quotedText(ctxInfo *waE2E.ContextInfo) string {
+	q := ctxInfo.GetQuotedMessage()
+	if q == nil {
+		return ""
+	}
+	return strings.Join([]string{
+		q.GetConversation(),
+		q.GetExtendedTextMessage().GetText(),
+		q.GetImageMessage().GetCaption(),
+		q.GetDocumentMessage().GetCaption(),
+	}, "\n")
+}
+
+// recordLocation pins a shared location to the shipment whose receipt (or
+// any bot message naming it) was replied to, else to the sender's open
+// !checkin. Only admins and riders leave checkpoints; locations from anyone
+// else are ignored.
+func (w *Worker) recordLocation(ctx context.Context, sender models.WhatsAppSender, job models.Job, lang i18n.Language, cp models.Checkpoint, quoted string, first bool) {
+	var rider *models.Rider
+	if !job.IsAdmin {
+		r, err := w.ShipmentUC.RiderByPhone(ctx, job.CompanyID, job.SenderPhone)
+		if err != nil {
+			return
+		}
+		rider = r
+	}
+	reply := func(msg string) {
+		if first {
+			sender.Reply(job.ChatJID, job.SenderJID, msg, job.MessageID, job.Text)
+		}
+	}
+
+	trackingID := trackingid.Find(quoted)
+	checkedIn := false
+	if trackingID == "" {
+		id, err := w.ShipmentUC.CheckinTarget(ctx, job.CompanyID, job.SenderPhone)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.Error().Err(err).Str("sender", job.SenderPhone).Msg("Failed to look up check-in")
+			}
+			reply(i18n.T(lang, "MSG_CHECKPOINT_NO_SHIPMENT"))
+			return
+		}
+		trackingID, checkedIn = id, true
+	}
+
+	saved, err := w.ShipmentUC.RecordCheckpoint(ctx, job.CompanyID, rider, trackingID, cp)
+	switch {
+	case errors.Is(err, shipment.ErrInvalidCheckpoint):
+		reply(i18n.T(lang, "ERR_INVALID_CHECKPOINT"))
+		return
+	case errors.Is(err, shipment.ErrNotAssigned):
+		reply(i18n.T(lang, "ERR_NOT_ASSIGNED", trackingID))
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		reply(i18n.T(lang, "ERR_NOT_FOUND"))
+		return
+	case err != nil:
+		logger.Error().Err(err).Str("tracking_id", trackingID).Msg("Failed to record checkpoint")
+		reply(i18n.T(lang, "ERR_SYSTEM_ERROR"))
+		return
+	}
+
+	if checkedIn {
+		// Keep the window open while the rider keeps sending locations
+		if err := w.ShipmentUC.Checkin(ctx, job.CompanyID, rider, job.SenderPhone, trackingID); err != nil {
+			logger.Warn().Err(err).Str("tracking_id", trackingID).Msg("Failed to extend check-in")
+		}
+	}
+	if saved == nil {
+		return // live update too soon after the last one
+	}
+	logger.Info().Str("tracking_id", trackingID).Str("label", saved.Label).Bool("live", saved.Live).Msg("Checkpoint recorded")
+
+	if saved.Live {
+		reply(i18n.T(lang, "MSG_LIVE_CHECKPOINTS", trackingID, saved.Label))
+	} else {
+		reply(i18n.T(lang, "MSG_CHECKPOINT_SAVED", trackingID, saved.Label, geo.MapURL(saved.Latitude, saved.Longitude)))
+	}
+}
@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"strings"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/trackingid"
+)
+
+// podCommand decides what a photo is for. A command caption ("!pod ID") is
+// dispatched as it is; an uncaptioned photo replying to a receipt from an
+// admin or rider becomes "!pod <ID>". Any other photo is ignored (ok false),
+// as it was before photos were read at all.
+func (w *Worker) podCommand(ctx context.Context, job models.Job, photo *waE2E.ImageMessage) (string, bool) {
+	if strings.HasPrefix(job.Text, "!") || strings.HasPrefix(job.Text, "#") {
+		return job.Text, true
+	}
+	if job.Text != "" {
+		return "", false
+	}
+	id := trackingid.Find(quotedText(photo.GetContextInfo()))
+	if id == "" {
+		return "", false
+	}
+	if !job.IsAdmin {
+		// Customers reply to receipts with photos too; only riders' count
+		if _, err := w.ShipmentUC.RiderByPhone(ctx, job.CompanyID, job.SenderPhone); err != nil {
+			return "", false
+		}
+	}
+	return "!pod " + id, true
+}
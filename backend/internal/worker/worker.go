@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"golang.org/x/sync/errgroup"
 
 	"webtracker-bot/internal/commands"
@@ -34,16 +35,15 @@ var (
 
 // Worker processes incoming WhatsApp messages and executes commands.
 type Worker struct {
-	ID              int
-	Bots            models.BotProvider
-	ShipmentUC      models.ShipmentUsecase
-	ConfigUC        models.ConfigUsecase
-	Jobs            <-chan models.Job
-	WG              *sync.WaitGroup
-	Cfg             *config.Config
-	FrontendURL     string
-	ShipmentService shipment.Service
-	Context         context.Context
+	ID          int
+	Bots        models.BotProvider
+	ShipmentUC  models.ShipmentUsecase
+	ConfigUC    models.ConfigUsecase
+	Jobs        <-chan models.Job
+	WG          *sync.WaitGroup
+	Cfg         *config.Config
+	FrontendURL string
+	Context     context.Context
 }
 
 // Start begins processing jobs from the queue.
@@ -130,9 +130,33 @@ func (w *Worker) process(job models.Job) {
 		botPhone = utils.GetBarePhone(wa.Store.ID.User)
 	}
 
+	// Shared locations become checkpoints on a shipment
+	if cp, quoted, first, ok := sharedLocation(job.RawMessage); ok {
+		w.recordLocation(ctx, sender, job, lang, cp, quoted, first)
+		return
+	}
+
+	// Photos are only read as proof of delivery
+	var photo *waE2E.ImageMessage
+	if job.RawMessage != nil {
+		photo = job.RawMessage.Message.GetImageMessage()
+	}
+	if photo != nil {
+		text, ok := w.podCommand(ctx, job, photo)
+		if !ok {
+			return
+		}
+		job.Text = text
+	}
+
 	dispatcher := commands.NewDispatcher(w.Cfg, w.ShipmentUC, w.ConfigUC, sender, bot.GetPrefix(), bot.GetCompanyName(), botPhone, w.Cfg.AdminTimezone, bot.GetTier())
+	dispatcher.Photo = photo
 	if res, ok := dispatcher.Dispatch(ctx, job.CompanyID, job.Text); ok {
-		if len(res.Image) > 0 {
+		if len(res.Document) > 0 {
+			if err := sender.SendDocument(job.ChatJID, job.SenderJID, res.Document, res.DocumentName, "application/pdf", res.Message, job.MessageID, job.Text); err != nil {
+				logger.Error().Err(err).Str("file", res.DocumentName).Msg("Failed to send document")
+			}
+		} else if len(res.Image) > 0 {
 			sender.SendImage(job.ChatJID, job.SenderJID, res.Image, res.Message, job.MessageID, job.Text)
 		} else if res.Message != "" {
 			sender.Reply(job.ChatJID, job.SenderJID, res.Message, job.MessageID, job.Text)
@@ -145,6 +169,9 @@ func (w *Worker) process(job models.Job) {
 		}
 		return
 	}
+	if photo != nil {
+		return
+	}
 
 	// X. Extract Document Text (if any)
 	if job.RawMessage != nil && job.RawMessage.Message.GetDocumentMessage() != nil {
@@ -165,6 +192,17 @@ func (w *Worker) process(job models.Job) {
 		}
 	}
 
+	// Y. Expand Address Book References ("to: @alias")
+	expanded, unknown, err := w.ShipmentUC.ExpandContacts(ctx, job.CompanyID, job.Text)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to expand contact references")
+	} else if len(unknown) > 0 {
+		sender.Reply(job.ChatJID, job.SenderJID, i18n.T(lang, "ERR_UNKNOWN_CONTACT", "@"+strings.Join(unknown, ", @")), job.MessageID, job.Text)
+		return
+	} else {
+		job.Text = expanded
+	}
+
 	// 2. Initial Checks
 	isManifest, isPartial := w.isPotentialManifest(job.Text)
 	if !isManifest && !isPartial {
@@ -216,12 +254,13 @@ func (w *Worker) process(job models.Job) {
 
 	orig := m.SenderCountry
 	dest := m.ReceiverCountry
+	scheduler := w.ShipmentUC.ServiceFor(w.Context, job.CompanyID, m.ServiceLevel)
 
 	newShipment := &shipment.Shipment{
 		UserJID:           job.SenderJID.String(),
 		Status:            shipment.StatusPending,
-		SenderTimezone:    w.ShipmentService.ResolveTimezone(orig),
-		RecipientTimezone: w.ShipmentService.ResolveTimezone(dest),
+		SenderTimezone:    scheduler.ResolveTimezone(orig),
+		RecipientTimezone: scheduler.ResolveTimezone(dest),
 
 		SenderName:       m.SenderName,
 		SenderPhone:      job.SenderPhone,
@@ -235,7 +274,6 @@ func (w *Worker) process(job models.Job) {
 
 		CargoType: m.CargoType,
 		Weight:    m.Weight,
-		Cost:      0.0,
 	}
 
 	if newShipment.CargoType == "" {
@@ -250,6 +288,16 @@ func (w *Worker) process(job models.Job) {
 	if newShipment.Weight <= 0 {
 		newShipment.Weight = 15.0
 	}
+	newShipment.Pieces = m.Pieces
+	newShipment.CodAmount, newShipment.CodCurrency = m.CodAmount, m.CodCurrency
+	models.FillPieceWeights(newShipment.Pieces, newShipment.Weight)
+	newShipment.Cost = w.ShipmentUC.EstimateCost(w.Context, job.CompanyID, models.QuoteRequest{
+		Origin:      newShipment.Origin,
+		Destination: newShipment.Destination,
+		Weight:      newShipment.Weight,
+		CargoType:   newShipment.CargoType,
+		Pieces:      newShipment.Pieces,
+	})
 
 	//  Deduplication & Billing Check in Parallel
 	g, gctx = errgroup.WithContext(w.Context)
@@ -285,8 +333,8 @@ func (w *Worker) process(job models.Job) {
 
 	// Generate schedule dates using the new Smart Anchor Algorithm (A & B)
 	now := time.Now().UTC()
-	departure := w.ShipmentService.CalculateDeparture(now, w.Cfg.AdminTimezone)
-	arrival, outForDelivery := w.ShipmentService.CalculateArrival(departure, newShipment.Origin, newShipment.Destination)
+	departure := scheduler.CalculateDeparture(now, w.Cfg.AdminTimezone)
+	arrival, outForDelivery := scheduler.CalculateArrival(departure, newShipment.Origin, newShipment.Destination)
 
 	dbShip := &db.Shipment{
 		UserJid:              newShipment.UserJID,
@@ -308,6 +356,9 @@ func (w *Worker) process(job models.Job) {
 		CargoType:            sql.NullString{String: newShipment.CargoType, Valid: true},
 		Weight:               sql.NullFloat64{Float64: newShipment.Weight, Valid: true},
 		Cost:                 sql.NullFloat64{Float64: newShipment.Cost, Valid: true},
+		ServiceLevel:         sql.NullString{String: scheduler.ServiceLevel(), Valid: true},
+		CodAmount:            newShipment.CodAmount,
+		CodCurrency:          sql.NullString{String: newShipment.CodCurrency, Valid: newShipment.CodCurrency != ""},
 	}
 
 	trackingID, err := w.ShipmentUC.CreateWithPrefix(w.Context, job.CompanyID, dbShip, bot.GetPrefix())
@@ -319,6 +370,12 @@ func (w *Worker) process(job models.Job) {
 	}
 	logger.GlobalVitals.IncInsertSuccess()
 
+	if len(newShipment.Pieces) > 0 {
+		if err := w.ShipmentUC.SavePieces(w.Context, job.CompanyID, trackingID, newShipment.Pieces); err != nil {
+			logger.Error().Err(err).Str("tracking_id", trackingID).Msg("Failed to save shipment pieces")
+		}
+	}
+
 	// Generate and send receipt
 	w.generateAndSendReceipt(bot, job, trackingID, lang)
 
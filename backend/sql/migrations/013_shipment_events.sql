@@ -0,0 +1,14 @@
+-- Persisted status history for every shipment (pulse, bot edits, admin API)
+CREATE TABLE IF NOT EXISTS shipment_events (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    previous_status TEXT,
+    source TEXT NOT NULL DEFAULT 'system', -- 'system', 'bot', 'api'
+    actor TEXT,                            -- sender JID or admin email
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_events_company_tracking ON shipment_events(company_id, tracking_id, created_at);
@@ -0,0 +1,3 @@
+-- Service level chosen at creation (e.g. 'standard', 'express', 'economy').
+-- Scheduling profiles themselves live in SystemConfig under 'scheduling_profile'.
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS service_level TEXT;
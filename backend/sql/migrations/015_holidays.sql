@@ -0,0 +1,13 @@
+-- Business calendar: days on which the branch (country = '') or a destination
+-- country does not dispatch or deliver. Consulted by the schedule calculator.
+CREATE TABLE IF NOT EXISTS holidays (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    country TEXT NOT NULL DEFAULT '', -- lowercase destination country, '' for the branch itself
+    holiday_date DATE NOT NULL,
+    name TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, country, holiday_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_holidays_company_date ON holidays(company_id, holiday_date);
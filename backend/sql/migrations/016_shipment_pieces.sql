@@ -0,0 +1,16 @@
+-- Individual boxes under one airway bill. shipment.weight stays the total actual
+-- weight; volumetric and chargeable weight are derived from the piece dimensions.
+CREATE TABLE IF NOT EXISTS shipment_pieces (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    piece_no INT NOT NULL,                 -- 1-based, printed as "1 of N"
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    length_cm DOUBLE PRECISION NOT NULL DEFAULT 0,
+    width_cm DOUBLE PRECISION NOT NULL DEFAULT 0,
+    height_cm DOUBLE PRECISION NOT NULL DEFAULT 0,
+    description TEXT,
+    UNIQUE (tracking_id, piece_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_pieces_company_tracking ON shipment_pieces(company_id, tracking_id);
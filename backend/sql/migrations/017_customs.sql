@@ -0,0 +1,27 @@
+-- Customs declaration for international shipments: one header per shipment
+-- plus its item lines. Printed on the commercial invoice / CN23.
+CREATE TABLE IF NOT EXISTS customs_declarations (
+    tracking_id TEXT PRIMARY KEY REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,                       -- ISO 4217, e.g. 'USD'
+    export_reason TEXT NOT NULL DEFAULT 'sale',   -- sale, gift, sample, documents, return, other
+    invoice_number TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS customs_items (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES customs_declarations(tracking_id) ON DELETE CASCADE,
+    line_no INT NOT NULL,
+    description TEXT NOT NULL,
+    hs_code TEXT NOT NULL,                        -- digits only, 6 to 10 long
+    quantity INT NOT NULL,
+    unit_value DOUBLE PRECISION NOT NULL,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0,   -- net kg for the whole line
+    origin_country TEXT NOT NULL,
+    UNIQUE (tracking_id, line_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customs_items_company_tracking ON customs_items(company_id, tracking_id);
@@ -0,0 +1,24 @@
+-- Cash on delivery: the amount the courier collects from the recipient on
+-- behalf of the merchant. 0 means the shipment is prepaid.
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS cod_amount DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS cod_currency TEXT;
+
+-- COD ledger: collections (cash taken from recipients), remittances (cash
+-- paid out to the merchant) and manual adjustments. The balance owed to the
+-- merchant is collections + adjustments - remittances, per currency.
+CREATE TABLE IF NOT EXISTS cod_ledger (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT REFERENCES shipment(tracking_id) ON DELETE SET NULL,
+    entry_type TEXT NOT NULL,                     -- collection, remittance, adjustment
+    amount DOUBLE PRECISION NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    reference TEXT,
+    note TEXT,
+    actor TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A shipment can only be collected once.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cod_ledger_collection ON cod_ledger(tracking_id) WHERE entry_type = 'collection';
+CREATE INDEX IF NOT EXISTS idx_cod_ledger_company_created ON cod_ledger(company_id, created_at DESC);
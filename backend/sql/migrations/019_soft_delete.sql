@@ -0,0 +1,25 @@
+-- Soft delete: deleting a shipment moves it to the trash instead of removing
+-- the row. Trashed shipments are hidden from every list, track and count
+-- query and are purged for good after TRASH_RETENTION_DAYS.
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS deleted_by TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_shipment_company_deleted ON shipment(company_id, deleted_at DESC) WHERE deleted_at IS NOT NULL;
+
+-- Dashboard reads go through RLS; keep trashed rows out of them.
+DROP POLICY IF EXISTS "tenant_read_own_shipments" ON shipment;
+CREATE POLICY "tenant_read_own_shipments" ON shipment
+  FOR SELECT TO authenticated
+  USING (
+    deleted_at IS NULL AND (
+      (company_id = (auth.jwt() ->> 'company_id')::uuid) OR
+      ((auth.jwt() ->> 'role') = 'super_admin')
+    )
+  );
+
+CREATE OR REPLACE FUNCTION get_public_shipment(p_tracking_id TEXT)
+RETURNS SETOF shipment
+LANGUAGE sql SECURITY DEFINER
+AS $$
+  SELECT * FROM shipment WHERE tracking_id = p_tracking_id AND deleted_at IS NULL LIMIT 1;
+$$;
@@ -0,0 +1,14 @@
+-- Shipment list and search for the admin API (GET /api/admin/shipments).
+-- Keyset pagination walks (created_at, tracking_id) newest first; free-text
+-- search uses substring ILIKE, which needs trigram indexes to avoid seq scans.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_shipment_company_cursor ON shipment(company_id, created_at DESC, tracking_id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shipment_company_status_cursor ON shipment(company_id, status, created_at DESC, tracking_id DESC) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_shipment_tracking_trgm ON shipment USING gin (tracking_id gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_recipient_name_trgm ON shipment USING gin (recipient_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_recipient_address_trgm ON shipment USING gin (recipient_address gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_destination_trgm ON shipment USING gin (destination gin_trgm_ops);
+-- Phones are stored as typed ("+351 912-345-678"); search on digits only.
+CREATE INDEX IF NOT EXISTS idx_shipment_recipient_phone_digits_trgm ON shipment USING gin ((regexp_replace(recipient_phone, '[^0-9]', '', 'g')) gin_trgm_ops);
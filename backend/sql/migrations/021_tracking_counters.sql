@@ -0,0 +1,8 @@
+-- Per-company counters for sequential and date-based tracking ID formats.
+-- scope is '' for the running sequence and 'YYMMDD' for per-day sequences.
+CREATE TABLE IF NOT EXISTS tracking_counters (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL DEFAULT '',
+    value BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (company_id, scope)
+);
@@ -0,0 +1,21 @@
+-- Field-level edit history. Every change made through UpdateField (and the
+-- status/cost updates around it) stores the old and new value, who made it
+-- and through which channel. Changes from one !edit share a batch_id so
+-- !undo can revert them together.
+CREATE TABLE IF NOT EXISTS shipment_changes (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    batch_id UUID NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    source TEXT NOT NULL DEFAULT 'system', -- 'system', 'bot', 'api', 'csv'
+    actor TEXT,                            -- sender JID or admin email
+    is_undo BOOLEAN NOT NULL DEFAULT FALSE, -- written by !undo; never undone itself
+    reverted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_changes_company_tracking ON shipment_changes(company_id, tracking_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_shipment_changes_company_actor ON shipment_changes(company_id, actor, created_at DESC) WHERE is_undo = FALSE AND reverted_at IS NULL;
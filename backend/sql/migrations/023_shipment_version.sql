@@ -0,0 +1,5 @@
+-- Optimistic concurrency: every write to a shipment bumps its version, and
+-- the dashboard, the bot and the pulse only update the row they last read.
+-- A writer that loses the race gets a conflict instead of silently
+-- overwriting the other change.
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,23 @@
+-- Address book: senders and recipients saved from created shipments so staff
+-- don't retype them. A contact is identified by the digits of its phone
+-- number; an optional alias lets the bot expand "to: @alias" in a manifest.
+CREATE TABLE IF NOT EXISTS contacts (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    alias TEXT,                                   -- lowercase, unique per company
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL,                          -- as last entered
+    phone_digits TEXT NOT NULL,                   -- dedupe key
+    email TEXT,
+    address TEXT,
+    country TEXT,
+    id_number TEXT,
+    shipment_count INTEGER NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, phone_digits)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contacts_company_alias ON contacts(company_id, alias) WHERE alias IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_contacts_name_trgm ON contacts USING gin (name gin_trgm_ops);
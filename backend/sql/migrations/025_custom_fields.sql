@@ -0,0 +1,22 @@
+-- Tenant-specific shipment attributes (order number, marketplace, account
+-- code, ...) without a schema change per tenant. Each company defines its
+-- fields once; values live in a JSONB column on the shipment, keyed by the
+-- field's name. Tags are free-form labels for filtering.
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,                           -- key in shipment.custom_fields
+    label TEXT,                                   -- display name, also accepted by !edit and CSV
+    field_type TEXT NOT NULL DEFAULT 'text' CHECK (field_type IN ('text', 'number', 'date', 'boolean')),
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    pattern TEXT,                                 -- regex values must match
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, name)
+);
+
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_shipment_custom_fields ON shipment USING gin (custom_fields jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_tags ON shipment USING gin (tags);
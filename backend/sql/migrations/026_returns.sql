@@ -0,0 +1,6 @@
+-- Return-to-origin. A return is an ordinary shipment going back from the
+-- original destination to the sender; the two are linked both ways by
+-- tracking ID so either record can show the other.
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS return_of TEXT;      -- on the return: the original shipment
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS return_id TEXT;      -- on the original: its return shipment
+ALTER TABLE shipment ADD COLUMN IF NOT EXISTS return_reason TEXT;
@@ -0,0 +1,16 @@
+-- Failed delivery attempts. Each failure is recorded with a reason code;
+-- until the company's max-attempts policy is reached the shipment is
+-- rescheduled for another attempt, after that it is left in delivery_failed.
+CREATE TABLE IF NOT EXISTS delivery_attempts (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    attempt_no INTEGER NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('no_one_home', 'wrong_address', 'refused', 'business_closed', 'other')),
+    note TEXT,
+    source TEXT NOT NULL DEFAULT 'system', -- 'system', 'bot', 'api'
+    actor TEXT,                            -- sender JID or admin email
+    next_attempt_at TIMESTAMP,             -- rescheduled delivery; NULL after the last attempt
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, tracking_id, attempt_no)
+);
@@ -0,0 +1,27 @@
+-- Dispatch riders: the people who physically carry packages. A rider is
+-- identified by the digits of their phone number so the bot can recognise
+-- them in a private chat. A shipment has at most one rider; while assigned,
+-- the pulse leaves the last mile (out for delivery, delivered) to the rider.
+CREATE TABLE IF NOT EXISTS riders (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL,                          -- as last entered
+    phone_digits TEXT NOT NULL,                   -- matched against the WhatsApp sender
+    active BOOLEAN NOT NULL DEFAULT TRUE,         -- inactive riders keep their history but can't run commands
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, phone_digits)
+);
+
+CREATE TABLE IF NOT EXISTS rider_assignments (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    rider_id INTEGER NOT NULL REFERENCES riders(id) ON DELETE CASCADE,
+    assigned_by TEXT,                             -- sender JID or admin email
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    picked_up_at TIMESTAMP,                       -- last !pickup
+    PRIMARY KEY (company_id, tracking_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rider_assignments_rider ON rider_assignments(company_id, rider_id);
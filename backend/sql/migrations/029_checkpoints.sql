@@ -0,0 +1,28 @@
+-- Geo checkpoints: locations shared on WhatsApp (replying to a receipt or
+-- after !checkin) pinned to a shipment. label is reverse-geocoded from the
+-- bundled city list when the checkpoint is saved, so it never changes.
+CREATE TABLE IF NOT EXISTS shipment_checkpoints (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    accuracy_m INTEGER,                           -- as reported by the phone, if any
+    label TEXT NOT NULL,                          -- "Ikeja, Nigeria" or "85 km from Ibadan, Nigeria"
+    live BOOLEAN NOT NULL DEFAULT FALSE,          -- from a live location share
+    source TEXT NOT NULL,                         -- whatsapp, api
+    actor TEXT,                                   -- sender JID or admin email
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_checkpoints_tracking ON shipment_checkpoints(company_id, tracking_id, created_at DESC);
+
+-- !checkin ID opens a short window in which the sender's location shares
+-- go to that shipment. One open check-in per sender.
+CREATE TABLE IF NOT EXISTS checkin_sessions (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    sender_phone TEXT NOT NULL,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (company_id, sender_phone)
+);
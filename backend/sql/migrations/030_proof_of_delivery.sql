@@ -0,0 +1,17 @@
+-- Proof of delivery: photos (and signature images uploaded through the API)
+-- attached to a shipment. The image itself lives in blob storage under
+-- blob_key; only its metadata is kept here.
+CREATE TABLE IF NOT EXISTS shipment_pods (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'photo',           -- photo, signature
+    blob_key TEXT NOT NULL,                       -- "<company_id>/pod/<uuid>.jpg"
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    source TEXT NOT NULL,                         -- whatsapp, api
+    actor TEXT,                                   -- sender JID or admin email
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_pods_tracking ON shipment_pods(company_id, tracking_id, created_at);
@@ -0,0 +1,12 @@
+-- Delivery codes for high-value shipments: sent to the recipient when the
+-- shipment goes out for delivery, required to mark it delivered. Only the
+-- bcrypt hash is kept; a resend replaces the code and clears the failures.
+CREATE TABLE IF NOT EXISTS delivery_otps (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at TIMESTAMP,                       -- set once the right code is entered
+    PRIMARY KEY (company_id, tracking_id)
+);
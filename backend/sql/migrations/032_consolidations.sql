@@ -0,0 +1,27 @@
+-- Consolidations: a master air waybill for a bag or container that carries
+-- many shipments on one flight. Shipments are added while it is open; once
+-- closed and departed, status changes on the master are applied to every
+-- shipment in it. A shipment is in at most one consolidation.
+CREATE TABLE IF NOT EXISTS consolidations (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    master_awb TEXT NOT NULL,                     -- "<prefix>-M000042"
+    label TEXT,                                   -- flight, container or any note
+    state TEXT NOT NULL DEFAULT 'open',           -- open, closed, departed
+    status TEXT,                                  -- last shipment status applied to the shipments in it
+    created_by TEXT,                              -- sender JID or admin email
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP,
+    departed_at TIMESTAMP,
+    UNIQUE (company_id, master_awb)
+);
+
+CREATE TABLE IF NOT EXISTS consolidation_shipments (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    consolidation_id INTEGER NOT NULL REFERENCES consolidations(id) ON DELETE CASCADE,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (company_id, tracking_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_consolidation_shipments_bag ON consolidation_shipments(consolidation_id, added_at);
@@ -0,0 +1,41 @@
+-- get_public_shipment is callable by anon and used to return the whole row,
+-- phone, email and address included. Return only what the public tracking
+-- page shows, with names redacted the way the API redacts them.
+DROP FUNCTION IF EXISTS get_public_shipment(TEXT);
+
+-- "Maria Silva" → "Ma******", "Jo" → "Jo***" (shipment.RedactName)
+CREATE OR REPLACE FUNCTION redact_public_name(p_name TEXT)
+RETURNS TEXT
+LANGUAGE sql IMMUTABLE
+AS $$
+  SELECT CASE
+    WHEN coalesce(split_part(btrim(p_name), ' ', 1), '') = '' THEN ''
+    WHEN char_length(split_part(btrim(p_name), ' ', 1)) <= 2 THEN split_part(btrim(p_name), ' ', 1) || '***'
+    ELSE left(split_part(btrim(p_name), ' ', 1), 2) || '******'
+  END;
+$$;
+
+CREATE FUNCTION get_public_shipment(p_tracking_id TEXT)
+RETURNS TABLE (
+    tracking_id TEXT,
+    status TEXT,
+    origin TEXT,
+    destination TEXT,
+    weight DOUBLE PRECISION,
+    sender_name TEXT,
+    recipient_name TEXT,
+    created_at TIMESTAMP,
+    scheduled_transit_time TIMESTAMP,
+    outfordelivery_time TIMESTAMP,
+    expected_delivery_time TIMESTAMP
+)
+LANGUAGE sql SECURITY DEFINER
+SET search_path = public
+AS $$
+  SELECT s.tracking_id, s.status, s.origin, s.destination, s.weight,
+         redact_public_name(s.sender_name), redact_public_name(s.recipient_name),
+         s.created_at, s.scheduled_transit_time, s.outfordelivery_time, s.expected_delivery_time
+  FROM shipment s
+  WHERE s.tracking_id = p_tracking_id AND s.deleted_at IS NULL
+  LIMIT 1;
+$$;
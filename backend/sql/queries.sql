@@ -71,66 +71,57 @@ WHERE company_id = $1 AND ((status = 'delivered' AND updated_at < $2) OR (create
 
 -- name: CreateShipment :exec
 INSERT INTO Shipment (
-    company_id, tracking_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at
+    company_id, tracking_id, user_jid, status, created_at, scheduled_transit_time, outfordelivery_time, expected_delivery_time, sender_timezone, recipient_timezone, sender_name, sender_phone, origin, recipient_name, recipient_phone, recipient_email, recipient_id, recipient_address, destination, cargo_type, weight, cost, updated_at, service_level, cod_amount, cod_currency, custom_fields, tags, return_of, return_reason
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30
 );
 
 -- name: GetShipment :one
-SELECT * FROM Shipment WHERE company_id = $1 AND tracking_id = $2;
+SELECT * FROM Shipment WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NULL;
 
 -- name: ListShipments :many
-SELECT * FROM Shipment WHERE company_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3;
+SELECT * FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $2 OFFSET $3;
 
--- name: UpdateShipmentStatus :exec
-UPDATE Shipment SET status = $3, destination = $4, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2;
+-- name: UpdateShipmentStatus :execresult
+UPDATE Shipment SET status = $3, destination = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2 AND version = $5 AND deleted_at IS NULL;
 
--- name: DeleteShipment :exec
-DELETE FROM Shipment WHERE company_id = $1 AND tracking_id = $2;
+-- name: DeleteShipment :execresult
+UPDATE Shipment SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $3, version = version + 1 WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NULL;
 
 -- name: BulkDeleteShipments :execresult
-DELETE FROM Shipment WHERE company_id = $1 AND tracking_id = ANY($2::text[]);
-
--- name: DeleteDeliveredShipments :exec
-DELETE FROM Shipment WHERE company_id = $1 AND status = 'delivered';
+UPDATE Shipment SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $3, version = version + 1 WHERE company_id = $1 AND tracking_id = ANY($2::text[]) AND deleted_at IS NULL;
 
--- name: TransitionStatusToIntransit :many
-UPDATE Shipment
-SET status = 'intransit', updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND status = 'pending' AND scheduled_transit_time <= $2
-RETURNING tracking_id, status AS new_status, user_jid, recipient_email;
+-- name: DeleteDeliveredShipments :execresult
+UPDATE Shipment SET deleted_at = CURRENT_TIMESTAMP, deleted_by = $2, version = version + 1 WHERE company_id = $1 AND status = 'delivered' AND deleted_at IS NULL;
 
--- name: TransitionStatusToOutForDelivery :many
-UPDATE Shipment
-SET status = 'outfordelivery', updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND status = 'intransit' AND outfordelivery_time <= $2
-RETURNING tracking_id, status AS new_status, user_jid, recipient_email;
-
--- name: TransitionStatusToDelivered :many
-UPDATE Shipment
-SET status = 'delivered', updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND status = 'outfordelivery' AND expected_delivery_time <= $2
-RETURNING tracking_id, status AS new_status, user_jid, recipient_email;
+-- name: ListDueTransitions :many
+SELECT * FROM Shipment
+WHERE company_id = sqlc.arg(company_id) AND deleted_at IS NULL AND (
+  (status = 'pending' AND scheduled_transit_time <= sqlc.arg(now)::timestamp) OR
+  (status = 'intransit' AND outfordelivery_time <= sqlc.arg(now)::timestamp) OR
+  (status = 'outfordelivery' AND expected_delivery_time <= sqlc.arg(now)::timestamp)
+)
+ORDER BY created_at;
 
 -- name: GetLastShipmentIDForUser :one
-SELECT tracking_id FROM Shipment WHERE company_id = $1 AND user_jid = $2 ORDER BY created_at DESC LIMIT 1;
+SELECT tracking_id FROM Shipment WHERE company_id = $1 AND user_jid = $2 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT 1;
 
 -- name: FindSimilarShipment :one
 SELECT tracking_id FROM Shipment 
-WHERE company_id = $1 AND user_jid = $2 AND recipient_phone = $3 AND $3 != ''
+WHERE company_id = $1 AND user_jid = $2 AND recipient_phone = $3 AND $3 != '' AND deleted_at IS NULL
 ORDER BY created_at DESC LIMIT 1;
 
 -- name: CountCreatedSince :one
-SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND created_at >= $2;
+SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND created_at >= $2 AND deleted_at IS NULL;
 
 -- name: CountDeliveredSince :one
-SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND status = 'delivered' AND updated_at >= $2;
+SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND status = 'delivered' AND updated_at >= $2 AND deleted_at IS NULL;
 
 -- name: ListAllShipments :many
-SELECT * FROM Shipment WHERE company_id = $1 ORDER BY created_at DESC;
+SELECT * FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC;
 
 -- name: CountShipments :one
-SELECT COUNT(*) FROM Shipment WHERE company_id = $1;
+SELECT COUNT(*) FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL;
 
 -- name: CountShipmentsByStatus :one
 SELECT
@@ -140,11 +131,11 @@ SELECT
     COUNT(*) FILTER (WHERE status = 'outfordelivery') AS outfordelivery,
     COUNT(*) FILTER (WHERE status = 'delivered') AS delivered,
     COUNT(*) FILTER (WHERE status = 'canceled') AS canceled
-FROM Shipment WHERE company_id = $1;
+FROM Shipment WHERE company_id = $1 AND deleted_at IS NULL;
 
 
 
--- name: UpdateShipmentDynamic :exec
+-- name: UpdateShipmentDynamic :execresult
 UPDATE Shipment
 SET 
   sender_name = COALESCE(NULLIF($3::text, ''), sender_name),
@@ -161,8 +152,9 @@ SET
   expected_delivery_time = COALESCE(NULLIF($14::timestamp, '0001-01-01 00:00:00'::timestamp), expected_delivery_time),
   outfordelivery_time = COALESCE(NULLIF($15::timestamp, '0001-01-01 00:00:00'::timestamp), outfordelivery_time),
   status = COALESCE(NULLIF($16::text, ''), status),
+  version = version + 1,
   updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND tracking_id = $2;
+WHERE company_id = $1 AND tracking_id = $2 AND version = $17 AND deleted_at IS NULL;
 
 -- name: RecordEvent :exec
 INSERT INTO Telemetry (company_id, event_type, metadata, created_at)
@@ -180,10 +172,12 @@ WHERE company_id = $1
 ORDER BY created_at DESC
 LIMIT $2;
 
--- name: BulkUpdateStatus :exec
-UPDATE Shipment
-SET status = $3, updated_at = CURRENT_TIMESTAMP
-WHERE company_id = $1 AND tracking_id = ANY($2::text[]);
+-- name: BulkUpdateStatus :many
+UPDATE Shipment s
+SET status = $3, version = s.version + 1, updated_at = CURRENT_TIMESTAMP
+FROM unnest($2::text[], $4::int[]) AS v(tracking_id, version)
+WHERE s.company_id = $1 AND s.tracking_id = v.tracking_id AND s.version = v.version AND s.deleted_at IS NULL
+RETURNING s.tracking_id;
 
 -- name: GetCompanyByEmail :one
 SELECT * FROM companies WHERE admin_email = $1;
@@ -234,8 +228,8 @@ LIMIT $1 OFFSET $2;
 SELECT 
     (SELECT COUNT(*) FROM companies) as total_tenants,
     (SELECT COUNT(*) FROM companies WHERE created_at >= date_trunc('month', CURRENT_TIMESTAMP)) as new_tenants_this_month,
-    (SELECT COUNT(*) FROM Shipment) as total_shipments,
-    (SELECT COUNT(*) FROM Shipment WHERE created_at >= CURRENT_DATE) as shipments_today,
+    (SELECT COUNT(*) FROM Shipment WHERE deleted_at IS NULL) as total_shipments,
+    (SELECT COUNT(*) FROM Shipment WHERE created_at >= CURRENT_DATE AND deleted_at IS NULL) as shipments_today,
     (SELECT jsonb_object_agg(plan_type, count) FROM (SELECT plan_type, COUNT(*) as count FROM companies GROUP BY plan_type) t) as plan_distribution,
     (SELECT jsonb_object_agg(subscription_status, count) FROM (SELECT subscription_status, COUNT(*) as count FROM companies GROUP BY subscription_status) t) as subscription_distribution;
 
@@ -255,3 +249,522 @@ SET subscription_status = $2,
     plan_type = COALESCE(NULLIF(sqlc.arg(plan_type)::text, ''), plan_type),
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1;
+
+-- name: GetShipmentByTrackingID :one
+SELECT * FROM Shipment WHERE tracking_id = $1 AND deleted_at IS NULL;
+
+-- name: GetShipmentStatuses :many
+SELECT tracking_id, status, version FROM Shipment WHERE company_id = $1 AND tracking_id = ANY($2::text[]) AND deleted_at IS NULL;
+
+-- name: InsertShipmentEvent :exec
+INSERT INTO shipment_events (company_id, tracking_id, status, previous_status, source, actor, description)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListShipmentEvents :many
+SELECT * FROM shipment_events
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY created_at ASC, id ASC;
+
+-- name: UpsertHoliday :exec
+INSERT INTO holidays (company_id, country, holiday_date, name)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (company_id, country, holiday_date) DO UPDATE SET name = EXCLUDED.name;
+
+-- name: ListHolidays :many
+SELECT * FROM holidays
+WHERE company_id = $1 AND holiday_date >= $2 AND holiday_date <= $3
+ORDER BY holiday_date ASC, country ASC;
+
+-- name: DeleteHoliday :execresult
+DELETE FROM holidays WHERE company_id = $1 AND id = $2;
+
+-- name: DeleteHolidaysByCountry :execresult
+DELETE FROM holidays WHERE company_id = $1 AND country = $2;
+
+-- name: UpdateShipmentCost :execresult
+UPDATE Shipment SET cost = $3, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2 AND version = $4 AND deleted_at IS NULL;
+
+-- name: InsertShipmentPiece :exec
+INSERT INTO shipment_pieces (company_id, tracking_id, piece_no, weight, length_cm, width_cm, height_cm, description)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: ListShipmentPieces :many
+SELECT * FROM shipment_pieces
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY piece_no ASC;
+
+-- name: DeleteShipmentPieces :exec
+DELETE FROM shipment_pieces WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: UpsertCustomsDeclaration :exec
+INSERT INTO customs_declarations (tracking_id, company_id, currency, export_reason, invoice_number)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (tracking_id) DO UPDATE
+SET currency = EXCLUDED.currency, export_reason = EXCLUDED.export_reason, invoice_number = EXCLUDED.invoice_number, updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetCustomsDeclaration :one
+SELECT * FROM customs_declarations WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: DeleteCustomsDeclaration :execresult
+DELETE FROM customs_declarations WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: InsertCustomsItem :exec
+INSERT INTO customs_items (company_id, tracking_id, line_no, description, hs_code, quantity, unit_value, weight, origin_country)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListCustomsItems :many
+SELECT * FROM customs_items
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY line_no ASC;
+
+-- name: DeleteCustomsItems :exec
+DELETE FROM customs_items WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: UpdateShipmentCOD :execresult
+UPDATE Shipment SET cod_amount = $3, cod_currency = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2 AND version = $5 AND deleted_at IS NULL;
+
+-- name: InsertCodEntry :one
+INSERT INTO cod_ledger (company_id, tracking_id, entry_type, amount, currency, reference, note, actor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetCodCollection :one
+SELECT * FROM cod_ledger WHERE company_id = $1 AND tracking_id = $2 AND entry_type = 'collection';
+
+-- name: ListCodEntries :many
+SELECT * FROM cod_ledger
+WHERE company_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: SumCodLedger :many
+SELECT currency, entry_type, COUNT(*) AS entries, COALESCE(SUM(amount), 0)::float8 AS total
+FROM cod_ledger
+WHERE company_id = $1
+GROUP BY currency, entry_type
+ORDER BY currency, entry_type;
+
+-- name: SumOpenCod :many
+SELECT COALESCE(cod_currency, '')::text AS currency, (status = 'delivered')::bool AS delivered, COUNT(*) AS shipments, COALESCE(SUM(cod_amount), 0)::float8 AS total
+FROM Shipment s
+WHERE s.company_id = $1 AND s.cod_amount > 0 AND s.deleted_at IS NULL AND s.status NOT IN ('canceled', 'returned')
+  AND NOT EXISTS (SELECT 1 FROM cod_ledger l WHERE l.tracking_id = s.tracking_id AND l.entry_type = 'collection')
+GROUP BY 1, 2
+ORDER BY 1, 2;
+
+-- name: ListUncollectedCod :many
+SELECT s.tracking_id, s.status, s.recipient_name, s.destination, s.cod_amount, s.cod_currency, s.created_at
+FROM Shipment s
+WHERE s.company_id = $1 AND s.cod_amount > 0 AND s.deleted_at IS NULL AND s.status = 'delivered'
+  AND NOT EXISTS (SELECT 1 FROM cod_ledger l WHERE l.tracking_id = s.tracking_id AND l.entry_type = 'collection')
+ORDER BY s.created_at ASC
+LIMIT $2;
+
+-- name: ListTrashedShipments :many
+SELECT * FROM Shipment
+WHERE company_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: RestoreShipment :execresult
+UPDATE Shipment SET deleted_at = NULL, deleted_by = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NOT NULL;
+
+-- name: PurgeTrashedShipments :execresult
+DELETE FROM Shipment WHERE company_id = $1 AND deleted_at IS NOT NULL AND deleted_at < $2;
+
+-- name: ListAgedShipments :many
+SELECT * FROM Shipment
+WHERE company_id = $1 AND ((status = 'delivered' AND updated_at < $2) OR (created_at < $3))
+ORDER BY created_at ASC
+LIMIT $4;
+
+-- name: ListExpiredTrash :many
+SELECT * FROM Shipment
+WHERE company_id = $1 AND deleted_at IS NOT NULL AND deleted_at < $2
+ORDER BY deleted_at ASC
+LIMIT $3;
+
+-- name: ListEventsForShipments :many
+SELECT * FROM shipment_events
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, created_at ASC, id ASC;
+
+-- name: ListPiecesForShipments :many
+SELECT * FROM shipment_pieces
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, piece_no ASC;
+
+-- name: ListCustomsForShipments :many
+SELECT * FROM customs_declarations
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id;
+
+-- name: ListCustomsItemsForShipments :many
+SELECT * FROM customs_items
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, line_no ASC;
+
+-- name: ListChangesForShipments :many
+SELECT * FROM shipment_changes
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, created_at ASC, id ASC;
+
+-- name: ListAttemptsForShipments :many
+SELECT * FROM delivery_attempts
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, attempt_no ASC;
+
+-- name: ListCheckpointsForShipments :many
+SELECT * FROM shipment_checkpoints
+WHERE company_id = $1 AND tracking_id = ANY($2::text[])
+ORDER BY tracking_id, created_at ASC, id ASC;
+
+-- name: PurgeShipments :execresult
+DELETE FROM Shipment WHERE company_id = $1 AND tracking_id = ANY($2::text[]);
+
+-- name: RestoreShipmentState :exec
+UPDATE Shipment SET version = $3, deleted_at = $4, deleted_by = $5, return_id = $6
+WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: RestoreShipmentEvent :exec
+INSERT INTO shipment_events (company_id, tracking_id, status, previous_status, source, actor, description, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: RestoreCustomsDeclaration :exec
+INSERT INTO customs_declarations (tracking_id, company_id, currency, export_reason, invoice_number, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: RestoreShipmentChange :exec
+INSERT INTO shipment_changes (company_id, tracking_id, batch_id, field, old_value, new_value, source, actor, is_undo, reverted_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: RestoreDeliveryAttempt :exec
+INSERT INTO delivery_attempts (company_id, tracking_id, attempt_no, reason, note, source, actor, next_attempt_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: RestoreCheckpoint :exec
+INSERT INTO shipment_checkpoints (company_id, tracking_id, latitude, longitude, accuracy_m, label, live, source, actor, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: SearchShipments :many
+SELECT * FROM Shipment
+WHERE company_id = sqlc.arg(company_id) AND deleted_at IS NULL
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(created_from)::timestamp IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamp IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(destination)::text IS NULL OR destination ILIKE sqlc.narg(destination))
+  AND (sqlc.narg(user_jid)::text IS NULL OR user_jid = sqlc.narg(user_jid))
+  AND (sqlc.narg(query)::text IS NULL
+    OR tracking_id ILIKE sqlc.narg(query)
+    OR recipient_name ILIKE sqlc.narg(query)
+    OR recipient_address ILIKE sqlc.narg(query)
+    OR regexp_replace(recipient_phone, '[^0-9]', '', 'g') LIKE sqlc.narg(phone_digits))
+  AND (sqlc.narg(tags)::text[] IS NULL OR tags @> sqlc.narg(tags))
+  AND (sqlc.narg(custom_fields)::jsonb IS NULL OR custom_fields @> sqlc.narg(custom_fields))
+  AND (sqlc.narg(cursor_created_at)::timestamp IS NULL
+    OR (created_at, tracking_id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_tracking_id)::text))
+ORDER BY created_at DESC, tracking_id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: NextTrackingSequence :one
+INSERT INTO tracking_counters (company_id, scope, value)
+VALUES ($1, $2, 1)
+ON CONFLICT (company_id, scope) DO UPDATE SET value = tracking_counters.value + 1
+RETURNING value;
+
+-- name: InsertShipmentChange :exec
+INSERT INTO shipment_changes (company_id, tracking_id, batch_id, field, old_value, new_value, source, actor, is_undo)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListShipmentChanges :many
+SELECT * FROM shipment_changes
+WHERE company_id = $1 AND tracking_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $4;
+
+-- name: ListLastChangeBatch :many
+SELECT * FROM shipment_changes
+WHERE company_id = $1 AND batch_id = (
+    SELECT c.batch_id FROM shipment_changes c
+    WHERE c.company_id = $1 AND c.actor = $2 AND c.is_undo = FALSE AND c.reverted_at IS NULL
+    ORDER BY c.created_at DESC, c.id DESC
+    LIMIT 1
+)
+ORDER BY id DESC;
+
+-- name: MarkChangeBatchReverted :exec
+UPDATE shipment_changes SET reverted_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND batch_id = $2 AND reverted_at IS NULL;
+
+-- name: SetShipmentField :execresult
+UPDATE Shipment
+SET
+  sender_name = CASE WHEN sqlc.arg(field)::text = 'sender_name' THEN sqlc.narg(value)::text ELSE sender_name END,
+  sender_phone = CASE WHEN sqlc.arg(field) = 'sender_phone' THEN sqlc.narg(value) ELSE sender_phone END,
+  origin = CASE WHEN sqlc.arg(field) = 'origin' THEN sqlc.narg(value) ELSE origin END,
+  recipient_name = CASE WHEN sqlc.arg(field) = 'recipient_name' THEN sqlc.narg(value) ELSE recipient_name END,
+  recipient_phone = CASE WHEN sqlc.arg(field) = 'recipient_phone' THEN sqlc.narg(value) ELSE recipient_phone END,
+  recipient_email = CASE WHEN sqlc.arg(field) = 'recipient_email' THEN sqlc.narg(value) ELSE recipient_email END,
+  recipient_id = CASE WHEN sqlc.arg(field) = 'recipient_id' THEN sqlc.narg(value) ELSE recipient_id END,
+  recipient_address = CASE WHEN sqlc.arg(field) = 'recipient_address' THEN sqlc.narg(value) ELSE recipient_address END,
+  destination = CASE WHEN sqlc.arg(field) = 'destination' THEN sqlc.narg(value) ELSE destination END,
+  cargo_type = CASE WHEN sqlc.arg(field) = 'cargo_type' THEN sqlc.narg(value) ELSE cargo_type END,
+  scheduled_transit_time = CASE WHEN sqlc.arg(field) = 'scheduled_transit_time' THEN sqlc.narg(value)::timestamp ELSE scheduled_transit_time END,
+  expected_delivery_time = CASE WHEN sqlc.arg(field) = 'expected_delivery_time' THEN sqlc.narg(value)::timestamp ELSE expected_delivery_time END,
+  outfordelivery_time = CASE WHEN sqlc.arg(field) = 'outfordelivery_time' THEN sqlc.narg(value)::timestamp ELSE outfordelivery_time END,
+  cost = CASE WHEN sqlc.arg(field) = 'cost' THEN sqlc.narg(value)::double precision ELSE cost END,
+  status = CASE WHEN sqlc.arg(field) = 'status' THEN sqlc.narg(value) ELSE status END,
+  custom_fields = CASE WHEN sqlc.arg(field) LIKE 'cf.%' THEN
+      CASE WHEN sqlc.narg(value) IS NULL THEN custom_fields - substr(sqlc.arg(field), 4)
+      ELSE jsonb_set(custom_fields, ARRAY[substr(sqlc.arg(field), 4)], sqlc.narg(value)::jsonb) END
+    ELSE custom_fields END,
+  tags = CASE WHEN sqlc.arg(field) = 'tags' THEN ARRAY(SELECT jsonb_array_elements_text(COALESCE(sqlc.narg(value)::jsonb, '[]'))) ELSE tags END,
+  version = version + 1,
+  updated_at = CURRENT_TIMESTAMP
+WHERE company_id = sqlc.arg(company_id) AND tracking_id = sqlc.arg(tracking_id) AND version = sqlc.arg(version) AND deleted_at IS NULL;
+
+-- name: UpsertContactFromShipment :exec
+INSERT INTO contacts (company_id, name, phone, phone_digits, email, address, country, shipment_count, last_used_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, 1, CURRENT_TIMESTAMP)
+ON CONFLICT (company_id, phone_digits) DO UPDATE SET
+  name = EXCLUDED.name,
+  phone = EXCLUDED.phone,
+  email = COALESCE(NULLIF(EXCLUDED.email, ''), contacts.email),
+  address = COALESCE(NULLIF(EXCLUDED.address, ''), contacts.address),
+  country = COALESCE(NULLIF(EXCLUDED.country, ''), contacts.country),
+  shipment_count = contacts.shipment_count + 1,
+  last_used_at = CURRENT_TIMESTAMP,
+  updated_at = CURRENT_TIMESTAMP;
+
+-- name: CreateContact :one
+INSERT INTO contacts (company_id, alias, name, phone, phone_digits, email, address, country, id_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetContact :one
+SELECT * FROM contacts WHERE company_id = $1 AND id = $2;
+
+-- name: UpdateContact :one
+UPDATE contacts
+SET alias = $3, name = $4, phone = $5, phone_digits = $6, email = $7, address = $8, country = $9, id_number = $10,
+    updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteContact :execresult
+DELETE FROM contacts WHERE company_id = $1 AND id = $2;
+
+-- name: ListContacts :many
+SELECT * FROM contacts
+WHERE company_id = sqlc.arg(company_id)
+  AND (sqlc.arg(pattern)::text = '' OR name ILIKE sqlc.arg(pattern) OR alias ILIKE sqlc.arg(pattern)
+       OR (sqlc.arg(digits)::text <> '' AND phone_digits LIKE sqlc.arg(digits)))
+ORDER BY shipment_count DESC, last_used_at DESC NULLS LAST, name, id
+LIMIT sqlc.arg(row_limit) OFFSET sqlc.arg(row_offset);
+
+-- name: GetContactsByAliases :many
+SELECT * FROM contacts WHERE company_id = $1 AND alias = ANY($2::text[]);
+
+-- name: ListCustomFieldDefinitions :many
+SELECT * FROM custom_field_definitions WHERE company_id = $1 ORDER BY id;
+
+-- name: UpsertCustomFieldDefinition :one
+INSERT INTO custom_field_definitions (company_id, name, label, field_type, required, pattern)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (company_id, name) DO UPDATE SET
+  label = EXCLUDED.label,
+  field_type = EXCLUDED.field_type,
+  required = EXCLUDED.required,
+  pattern = EXCLUDED.pattern,
+  updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteCustomFieldDefinition :execresult
+DELETE FROM custom_field_definitions WHERE company_id = $1 AND name = $2;
+
+-- name: UpdateShipmentExtras :execresult
+UPDATE Shipment SET custom_fields = $3, tags = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND deleted_at IS NULL AND version = $5;
+
+-- name: MarkShipmentReturned :execresult
+UPDATE Shipment SET status = 'returned', return_id = $3, return_reason = $4, version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND version = $5 AND return_id IS NULL AND deleted_at IS NULL;
+
+-- name: CountDeliveryAttempts :one
+SELECT COUNT(*) FROM delivery_attempts WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: InsertDeliveryAttempt :one
+INSERT INTO delivery_attempts (company_id, tracking_id, attempt_no, reason, note, source, actor, next_attempt_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListDeliveryAttempts :many
+SELECT * FROM delivery_attempts WHERE company_id = $1 AND tracking_id = $2 ORDER BY attempt_no;
+
+-- name: RescheduleDelivery :execresult
+UPDATE Shipment SET status = sqlc.arg(status),
+    outfordelivery_time = COALESCE(sqlc.narg(outfordelivery_time), outfordelivery_time),
+    expected_delivery_time = COALESCE(sqlc.narg(expected_delivery_time), expected_delivery_time),
+    version = version + 1, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = sqlc.arg(company_id) AND tracking_id = sqlc.arg(tracking_id) AND version = sqlc.arg(version) AND deleted_at IS NULL;
+
+-- name: ListRiders :many
+SELECT * FROM riders WHERE company_id = $1 ORDER BY name, id;
+
+-- name: GetRider :one
+SELECT * FROM riders WHERE company_id = $1 AND id = $2;
+
+-- name: GetRiderByPhone :one
+SELECT * FROM riders WHERE company_id = $1 AND phone_digits = $2;
+
+-- name: CreateRider :one
+INSERT INTO riders (company_id, name, phone, phone_digits, active)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateRider :one
+UPDATE riders SET name = $3, phone = $4, phone_digits = $5, active = $6, updated_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteRider :execresult
+DELETE FROM riders WHERE company_id = $1 AND id = $2;
+
+-- name: AssignRider :exec
+INSERT INTO rider_assignments (company_id, tracking_id, rider_id, assigned_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (company_id, tracking_id) DO UPDATE SET
+  rider_id = EXCLUDED.rider_id,
+  assigned_by = EXCLUDED.assigned_by,
+  assigned_at = CURRENT_TIMESTAMP,
+  picked_up_at = NULL;
+
+-- name: UnassignRider :execresult
+DELETE FROM rider_assignments WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: GetRiderAssignment :one
+SELECT * FROM rider_assignments WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: ListRiderPickups :many
+SELECT a.tracking_id FROM rider_assignments a
+JOIN shipment s ON s.company_id = a.company_id AND s.tracking_id = a.tracking_id
+WHERE a.company_id = $1 AND a.rider_id = $2 AND s.status = 'intransit' AND s.deleted_at IS NULL
+ORDER BY a.assigned_at;
+
+-- name: MarkRiderPickedUp :exec
+UPDATE rider_assignments SET picked_up_at = CURRENT_TIMESTAMP WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: ListAssignedShipments :many
+SELECT tracking_id FROM rider_assignments WHERE company_id = $1 AND tracking_id = ANY($2::text[]);
+
+-- name: InsertCheckpoint :one
+INSERT INTO shipment_checkpoints (company_id, tracking_id, latitude, longitude, accuracy_m, label, live, source, actor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetLastCheckpoint :one
+SELECT * FROM shipment_checkpoints WHERE company_id = $1 AND tracking_id = $2 ORDER BY created_at DESC, id DESC LIMIT 1;
+
+-- name: OpenCheckin :exec
+INSERT INTO checkin_sessions (company_id, sender_phone, tracking_id, expires_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(mins => sqlc.arg(minutes)::int))
+ON CONFLICT (company_id, sender_phone) DO UPDATE SET
+  tracking_id = EXCLUDED.tracking_id,
+  expires_at = EXCLUDED.expires_at;
+
+-- name: GetOpenCheckin :one
+SELECT tracking_id FROM checkin_sessions WHERE company_id = $1 AND sender_phone = $2 AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: InsertPOD :one
+INSERT INTO shipment_pods (company_id, tracking_id, kind, blob_key, content_type, size_bytes, source, actor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListPODs :many
+SELECT * FROM shipment_pods WHERE company_id = $1 AND tracking_id = $2 ORDER BY created_at, id;
+
+-- name: GetPOD :one
+SELECT * FROM shipment_pods WHERE company_id = $1 AND tracking_id = $2 AND id = $3;
+
+-- name: ListPODKeysForShipments :many
+SELECT blob_key FROM shipment_pods WHERE company_id = $1 AND tracking_id = ANY($2::text[]);
+
+-- name: ListTrashedPODKeys :many
+SELECT p.blob_key FROM shipment_pods p
+JOIN Shipment s ON s.company_id = p.company_id AND s.tracking_id = p.tracking_id
+WHERE p.company_id = $1 AND s.deleted_at IS NOT NULL AND s.deleted_at < $2;
+
+-- name: ListAgedPODKeys :many
+SELECT p.blob_key FROM shipment_pods p
+JOIN Shipment s ON s.company_id = p.company_id AND s.tracking_id = p.tracking_id
+WHERE p.company_id = $1 AND ((s.status = 'delivered' AND s.updated_at < $2) OR (s.created_at < $3));
+
+-- name: IssueDeliveryOTP :exec
+INSERT INTO delivery_otps (company_id, tracking_id, code_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (company_id, tracking_id) DO UPDATE SET
+  code_hash = EXCLUDED.code_hash,
+  failed_attempts = 0,
+  issued_at = CURRENT_TIMESTAMP,
+  confirmed_at = NULL;
+
+-- name: GetDeliveryOTP :one
+SELECT * FROM delivery_otps WHERE company_id = $1 AND tracking_id = $2;
+
+-- name: ReserveDeliveryOTPAttempt :one
+UPDATE delivery_otps SET failed_attempts = failed_attempts + 1
+WHERE company_id = $1 AND tracking_id = $2 AND failed_attempts < $3
+RETURNING failed_attempts;
+
+-- name: ReleaseDeliveryOTPAttempt :exec
+UPDATE delivery_otps SET failed_attempts = failed_attempts - 1
+WHERE company_id = $1 AND tracking_id = $2 AND failed_attempts > 0;
+
+-- name: ConfirmDeliveryOTP :exec
+UPDATE delivery_otps SET confirmed_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND tracking_id = $2 AND confirmed_at IS NULL;
+
+-- name: CreateConsolidation :one
+INSERT INTO consolidations (company_id, master_awb, label, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetConsolidation :one
+SELECT * FROM consolidations WHERE company_id = $1 AND master_awb = $2;
+
+-- name: ListConsolidations :many
+SELECT c.*, (SELECT COUNT(*) FROM consolidation_shipments s WHERE s.consolidation_id = c.id) AS shipment_count
+FROM consolidations c
+WHERE c.company_id = $1
+ORDER BY c.created_at DESC, c.id DESC
+LIMIT $2;
+
+-- name: CloseConsolidation :execresult
+UPDATE consolidations SET state = 'closed', closed_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2 AND state = 'open';
+
+-- name: DepartConsolidation :execresult
+UPDATE consolidations SET state = 'departed', departed_at = CURRENT_TIMESTAMP
+WHERE company_id = $1 AND id = $2 AND state = 'closed';
+
+-- name: SetConsolidationStatus :exec
+UPDATE consolidations SET status = $3 WHERE company_id = $1 AND id = $2;
+
+-- name: AddToConsolidation :execresult
+INSERT INTO consolidation_shipments (company_id, tracking_id, consolidation_id)
+SELECT $1, unnest($2::text[]), $3
+ON CONFLICT (company_id, tracking_id) DO NOTHING;
+
+-- name: RemoveFromConsolidation :execresult
+DELETE FROM consolidation_shipments WHERE company_id = $1 AND consolidation_id = $2 AND tracking_id = $3;
+
+-- name: ListConsolidationShipments :many
+SELECT tracking_id FROM consolidation_shipments
+WHERE company_id = $1 AND consolidation_id = $2
+ORDER BY added_at, tracking_id;
+
+-- name: GetShipmentConsolidation :one
+SELECT c.master_awb FROM consolidation_shipments s
+JOIN consolidations c ON c.id = s.consolidation_id
+WHERE s.company_id = $1 AND s.tracking_id = $2;
@@ -1,3 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE IF NOT EXISTS companies (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name TEXT,
@@ -67,7 +69,18 @@ CREATE TABLE IF NOT EXISTS shipment (
     weight DOUBLE PRECISION,
     cost DOUBLE PRECISION,
     
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    service_level TEXT,
+    cod_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cod_currency TEXT,
+    deleted_at TIMESTAMP,
+    deleted_by TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    custom_fields JSONB NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    return_of TEXT,
+    return_id TEXT,
+    return_reason TEXT
 );
 
 CREATE TABLE IF NOT EXISTS telemetry (
@@ -85,6 +98,20 @@ CREATE INDEX IF NOT EXISTS idx_telemetry_created_at ON telemetry(created_at);
 CREATE INDEX IF NOT EXISTS idx_shipment_company_created ON shipment(company_id, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_shipment_company_status ON shipment(company_id, status);
 CREATE INDEX IF NOT EXISTS idx_shipment_company_user ON shipment(company_id, user_jid);
+CREATE INDEX IF NOT EXISTS idx_shipment_company_deleted ON shipment(company_id, deleted_at DESC) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_shipment_company_cursor ON shipment(company_id, created_at DESC, tracking_id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shipment_company_status_cursor ON shipment(company_id, status, created_at DESC, tracking_id DESC) WHERE deleted_at IS NULL;
+
+-- Trigram indexes for free-text shipment search
+CREATE INDEX IF NOT EXISTS idx_shipment_tracking_trgm ON shipment USING gin (tracking_id gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_recipient_name_trgm ON shipment USING gin (recipient_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_recipient_address_trgm ON shipment USING gin (recipient_address gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_destination_trgm ON shipment USING gin (destination gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_recipient_phone_digits_trgm ON shipment USING gin ((regexp_replace(recipient_phone, '[^0-9]', '', 'g')) gin_trgm_ops);
+
+-- Custom field and tag filters
+CREATE INDEX IF NOT EXISTS idx_shipment_custom_fields ON shipment USING gin (custom_fields jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_shipment_tags ON shipment USING gin (tags);
 CREATE INDEX IF NOT EXISTS idx_telemetry_company_created ON telemetry(company_id, created_at DESC);
 
 -- Enable RLS
@@ -106,8 +133,10 @@ DROP POLICY IF EXISTS "tenant_read_own_shipments" ON shipment;
 CREATE POLICY "tenant_read_own_shipments" ON shipment 
   FOR SELECT TO authenticated 
   USING (
-    (company_id = (auth.jwt() ->> 'company_id')::uuid) OR
-    ((auth.jwt() ->> 'role') = 'super_admin')
+    deleted_at IS NULL AND (
+      (company_id = (auth.jwt() ->> 'company_id')::uuid) OR
+      ((auth.jwt() ->> 'role') = 'super_admin')
+    )
   );
   
 -- Payments: each tenant reads only their own payments, super_admin sees all
@@ -122,12 +151,45 @@ CREATE POLICY "tenant_read_own_payments" ON payments
 -- Shipments: anon users cannot read shipments directly to prevent enumeration
 DROP POLICY IF EXISTS "anon_read_shipment_by_tracking" ON shipment;
 
--- RPC function for public tracking to force tracking_id requirement
-CREATE OR REPLACE FUNCTION get_public_shipment(p_tracking_id TEXT)
-RETURNS SETOF shipment
+-- RPC function for public tracking to force tracking_id requirement. Returns
+-- only the columns the public tracking page shows, names redacted.
+DROP FUNCTION IF EXISTS get_public_shipment(TEXT);
+
+-- "Maria Silva" → "Ma******", "Jo" → "Jo***" (shipment.RedactName)
+CREATE OR REPLACE FUNCTION redact_public_name(p_name TEXT)
+RETURNS TEXT
+LANGUAGE sql IMMUTABLE
+AS $$
+  SELECT CASE
+    WHEN coalesce(split_part(btrim(p_name), ' ', 1), '') = '' THEN ''
+    WHEN char_length(split_part(btrim(p_name), ' ', 1)) <= 2 THEN split_part(btrim(p_name), ' ', 1) || '***'
+    ELSE left(split_part(btrim(p_name), ' ', 1), 2) || '******'
+  END;
+$$;
+
+CREATE FUNCTION get_public_shipment(p_tracking_id TEXT)
+RETURNS TABLE (
+    tracking_id TEXT,
+    status TEXT,
+    origin TEXT,
+    destination TEXT,
+    weight DOUBLE PRECISION,
+    sender_name TEXT,
+    recipient_name TEXT,
+    created_at TIMESTAMP,
+    scheduled_transit_time TIMESTAMP,
+    outfordelivery_time TIMESTAMP,
+    expected_delivery_time TIMESTAMP
+)
 LANGUAGE sql SECURITY DEFINER
+SET search_path = public
 AS $$
-  SELECT * FROM shipment WHERE tracking_id = p_tracking_id LIMIT 1;
+  SELECT s.tracking_id, s.status, s.origin, s.destination, s.weight,
+         redact_public_name(s.sender_name), redact_public_name(s.recipient_name),
+         s.created_at, s.scheduled_transit_time, s.outfordelivery_time, s.expected_delivery_time
+  FROM shipment s
+  WHERE s.tracking_id = p_tracking_id AND s.deleted_at IS NULL
+  LIMIT 1;
 $$;
 
 -- Add shipment and companies to Realtime publication
@@ -197,3 +259,265 @@ CREATE TABLE IF NOT EXISTS audit_log (
 );
 
 CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at DESC);
+
+CREATE TABLE IF NOT EXISTS shipment_events (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    previous_status TEXT,
+    source TEXT NOT NULL DEFAULT 'system',
+    actor TEXT,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_events_company_tracking ON shipment_events(company_id, tracking_id, created_at);
+
+CREATE TABLE IF NOT EXISTS holidays (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    country TEXT NOT NULL DEFAULT '', -- lowercase destination country, '' for the branch itself
+    holiday_date DATE NOT NULL,
+    name TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, country, holiday_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_holidays_company_date ON holidays(company_id, holiday_date);
+
+CREATE TABLE IF NOT EXISTS shipment_pieces (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    piece_no INT NOT NULL,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    length_cm DOUBLE PRECISION NOT NULL DEFAULT 0,
+    width_cm DOUBLE PRECISION NOT NULL DEFAULT 0,
+    height_cm DOUBLE PRECISION NOT NULL DEFAULT 0,
+    description TEXT,
+    UNIQUE (tracking_id, piece_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_pieces_company_tracking ON shipment_pieces(company_id, tracking_id);
+
+CREATE TABLE IF NOT EXISTS customs_declarations (
+    tracking_id TEXT PRIMARY KEY REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    export_reason TEXT NOT NULL DEFAULT 'sale',
+    invoice_number TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS customs_items (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES customs_declarations(tracking_id) ON DELETE CASCADE,
+    line_no INT NOT NULL,
+    description TEXT NOT NULL,
+    hs_code TEXT NOT NULL,
+    quantity INT NOT NULL,
+    unit_value DOUBLE PRECISION NOT NULL,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    origin_country TEXT NOT NULL,
+    UNIQUE (tracking_id, line_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customs_items_company_tracking ON customs_items(company_id, tracking_id);
+
+CREATE TABLE IF NOT EXISTS cod_ledger (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT REFERENCES shipment(tracking_id) ON DELETE SET NULL,
+    entry_type TEXT NOT NULL,
+    amount DOUBLE PRECISION NOT NULL,
+    currency TEXT NOT NULL DEFAULT '',
+    reference TEXT,
+    note TEXT,
+    actor TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cod_ledger_collection ON cod_ledger(tracking_id) WHERE entry_type = 'collection';
+CREATE INDEX IF NOT EXISTS idx_cod_ledger_company_created ON cod_ledger(company_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS tracking_counters (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    scope TEXT NOT NULL DEFAULT '',
+    value BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (company_id, scope)
+);
+
+CREATE TABLE IF NOT EXISTS shipment_changes (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    batch_id UUID NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    source TEXT NOT NULL DEFAULT 'system',
+    actor TEXT,
+    is_undo BOOLEAN NOT NULL DEFAULT FALSE,
+    reverted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_changes_company_tracking ON shipment_changes(company_id, tracking_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_shipment_changes_company_actor ON shipment_changes(company_id, actor, created_at DESC) WHERE is_undo = FALSE AND reverted_at IS NULL;
+
+-- Address book
+CREATE TABLE IF NOT EXISTS contacts (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    alias TEXT,                                   -- lowercase, unique per company
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL,                          -- as last entered
+    phone_digits TEXT NOT NULL,                   -- dedupe key
+    email TEXT,
+    address TEXT,
+    country TEXT,
+    id_number TEXT,
+    shipment_count INTEGER NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, phone_digits)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contacts_company_alias ON contacts(company_id, alias) WHERE alias IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_contacts_name_trgm ON contacts USING gin (name gin_trgm_ops);
+
+-- Per-company custom shipment fields
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    label TEXT,
+    field_type TEXT NOT NULL DEFAULT 'text' CHECK (field_type IN ('text', 'number', 'date', 'boolean')),
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    pattern TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS delivery_attempts (
+    id SERIAL PRIMARY KEY,
+    company_id UUID REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    attempt_no INTEGER NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('no_one_home', 'wrong_address', 'refused', 'business_closed', 'other')),
+    note TEXT,
+    source TEXT NOT NULL DEFAULT 'system',
+    actor TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, tracking_id, attempt_no)
+);
+
+-- Dispatch riders and their shipments
+CREATE TABLE IF NOT EXISTS riders (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    phone_digits TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (company_id, phone_digits)
+);
+
+CREATE TABLE IF NOT EXISTS rider_assignments (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    rider_id INTEGER NOT NULL REFERENCES riders(id) ON DELETE CASCADE,
+    assigned_by TEXT,
+    assigned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    picked_up_at TIMESTAMP,
+    PRIMARY KEY (company_id, tracking_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rider_assignments_rider ON rider_assignments(company_id, rider_id);
+
+-- Geo checkpoints and open !checkin windows
+CREATE TABLE IF NOT EXISTS shipment_checkpoints (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    accuracy_m INTEGER,                           -- as reported by the phone, if any
+    label TEXT NOT NULL,                          -- "Ikeja, Nigeria" or "85 km from Ibadan, Nigeria"
+    live BOOLEAN NOT NULL DEFAULT FALSE,          -- from a live location share
+    source TEXT NOT NULL,                         -- whatsapp, api
+    actor TEXT,                                   -- sender JID or admin email
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_checkpoints_tracking ON shipment_checkpoints(company_id, tracking_id, created_at DESC);
+
+-- !checkin ID opens a short window in which the sender's location shares
+-- go to that shipment. One open check-in per sender.
+CREATE TABLE IF NOT EXISTS checkin_sessions (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    sender_phone TEXT NOT NULL,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (company_id, sender_phone)
+);
+
+-- Proof of delivery images (stored in blob storage)
+CREATE TABLE IF NOT EXISTS shipment_pods (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'photo',           -- photo, signature
+    blob_key TEXT NOT NULL,                       -- "<company_id>/pod/<uuid>.jpg"
+    content_type TEXT NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    source TEXT NOT NULL,                         -- whatsapp, api
+    actor TEXT,                                   -- sender JID or admin email
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_pods_tracking ON shipment_pods(company_id, tracking_id, created_at);
+
+-- Recipient delivery codes (bcrypt hashes)
+CREATE TABLE IF NOT EXISTS delivery_otps (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at TIMESTAMP,                       -- set once the right code is entered
+    PRIMARY KEY (company_id, tracking_id)
+);
+
+-- Consolidations (master AWBs) and the shipments they carry
+CREATE TABLE IF NOT EXISTS consolidations (
+    id SERIAL PRIMARY KEY,
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    master_awb TEXT NOT NULL,                     -- "<prefix>-M000042"
+    label TEXT,                                   -- flight, container or any note
+    state TEXT NOT NULL DEFAULT 'open',           -- open, closed, departed
+    status TEXT,                                  -- last shipment status applied to the shipments in it
+    created_by TEXT,                              -- sender JID or admin email
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP,
+    departed_at TIMESTAMP,
+    UNIQUE (company_id, master_awb)
+);
+
+CREATE TABLE IF NOT EXISTS consolidation_shipments (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    tracking_id TEXT NOT NULL REFERENCES shipment(tracking_id) ON DELETE CASCADE,
+    consolidation_id INTEGER NOT NULL REFERENCES consolidations(id) ON DELETE CASCADE,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (company_id, tracking_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_consolidation_shipments_bag ON consolidation_shipments(consolidation_id, added_at);
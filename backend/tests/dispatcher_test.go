@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"webtracker-bot/internal/commands"
+	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/i18n"
+	"webtracker-bot/internal/models"
+	"webtracker-bot/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// riderShipUC stubs the rider lookups the dispatcher makes; anything else
+// panics through the nil embedded interface
+type riderShipUC struct {
+	models.ShipmentUsecase
+	mock.Mock
+}
+
+func (m *riderShipUC) RiderByPhone(ctx context.Context, companyID uuid.UUID, phone string) (*models.Rider, error) {
+	args := m.Called(ctx, companyID, phone)
+	r, _ := args.Get(0).(*models.Rider)
+	return r, args.Error(1)
+}
+
+func (m *riderShipUC) RiderPickup(ctx context.Context, companyID uuid.UUID, rider *models.Rider, trackingID string) ([]db.Shipment, error) {
+	args := m.Called(ctx, companyID, rider, trackingID)
+	s, _ := args.Get(0).([]db.Shipment)
+	return s, args.Error(1)
+}
+
+type langConfigUC struct {
+	models.ConfigUsecase
+}
+
+func (langConfigUC) GetUserLanguage(ctx context.Context, companyID uuid.UUID, jid string) (string, error) {
+	return "en", nil
+}
+
+func TestDispatcher_RiderAuthorization(t *testing.T) {
+	companyID := uuid.New()
+	rider := &models.Rider{ID: 7, Name: "Ama", Phone: "233200000001", Active: true}
+
+	dispatch := func(shipUC *riderShipUC, phone, chatJID string) *commands.Result {
+		d := commands.NewDispatcher(nil, shipUC, langConfigUC{}, nil, "AWB", "Test Co", "233200000000", "UTC", "enterprise")
+		ctx := utils.WithValues(context.Background(), phone+"@s.whatsapp.net", phone, false, chatJID, "msg-1", "!pickup")
+		res, handled := d.Dispatch(ctx, companyID, "!pickup")
+		assert.True(t, handled)
+		return res
+	}
+
+	t.Run("RiderInPrivateChat", func(t *testing.T) {
+		shipUC := new(riderShipUC)
+		shipUC.On("RiderByPhone", mock.Anything, companyID, rider.Phone).Return(rider, nil)
+		shipUC.On("RiderPickup", mock.Anything, companyID, rider, "").Return(nil, nil)
+
+		res := dispatch(shipUC, rider.Phone, rider.Phone+"@s.whatsapp.net")
+		assert.Equal(t, i18n.T(i18n.EN, "MSG_NOTHING_TO_PICK_UP"), res.Message)
+		shipUC.AssertExpectations(t)
+	})
+
+	t.Run("NonRiderDenied", func(t *testing.T) {
+		shipUC := new(riderShipUC)
+		shipUC.On("RiderByPhone", mock.Anything, companyID, "233200000002").Return(nil, nil)
+
+		res := dispatch(shipUC, "233200000002", "233200000002@s.whatsapp.net")
+		assert.Equal(t, i18n.T(i18n.EN, "ERR_ACCESS_DENIED"), res.Message)
+		shipUC.AssertNotCalled(t, "RiderPickup", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("RiderInGroupChat", func(t *testing.T) {
+		shipUC := new(riderShipUC)
+		shipUC.On("RiderByPhone", mock.Anything, companyID, rider.Phone).Return(rider, nil)
+
+		res := dispatch(shipUC, rider.Phone, "120363000000000000@g.us")
+		assert.Equal(t, i18n.T(i18n.EN, "ERR_RIDER_PRIVATE_ONLY"), res.Message)
+		shipUC.AssertNotCalled(t, "RiderPickup", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// editShipUC stubs what !edit needs to change a status
+type editShipUC struct {
+	models.ShipmentUsecase
+	mock.Mock
+}
+
+func (m *editShipUC) ParseTrackingID(ctx context.Context, companyID uuid.UUID, raw string) (string, error) {
+	return strings.ToUpper(raw), nil
+}
+
+func (m *editShipUC) CustomFields(ctx context.Context, companyID uuid.UUID) ([]models.CustomField, error) {
+	return nil, nil
+}
+
+func (m *editShipUC) UpdateField(ctx context.Context, companyID uuid.UUID, trackingID, field, value string) error {
+	return m.Called(ctx, companyID, trackingID, field, value).Error(0)
+}
+
+func (m *editShipUC) Track(ctx context.Context, companyID uuid.UUID, trackingID string) (*db.Shipment, error) {
+	return &db.Shipment{TrackingID: trackingID}, nil
+}
+
+func TestEditHandler_StatusLabel(t *testing.T) {
+	companyID := uuid.New()
+	ctx := utils.WithValues(context.Background(), "233200000001@s.whatsapp.net", "233200000001", true, "233200000001@s.whatsapp.net", "msg-1", "!edit AWB-123456789 status: on_hold")
+
+	for _, text := range []string{"status: on_hold", "status On_Hold"} {
+		shipUC := new(editShipUC)
+		shipUC.On("UpdateField", mock.Anything, companyID, "AWB-123456789", "status", "on_hold").Return(nil).Once()
+
+		h := &commands.EditHandler{}
+		res := h.Execute(ctx, shipUC, langConfigUC{}, companyID, append([]string{"AWB-123456789"}, strings.Fields(text)...), "en", true)
+		assert.Equal(t, "AWB-123456789", res.EditID, text)
+		assert.Contains(t, res.Message, "STATUS", text)
+		shipUC.AssertExpectations(t)
+	}
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"webtracker-bot/internal/models"
 	"webtracker-bot/internal/parser"
 )
 
@@ -30,6 +31,20 @@ Weight: 10.5 kg`,
 				"Weight":          10.5,
 			},
 		},
+		{
+			name: "Service Level",
+			input: `Receiver: Alice Smith
+Phone: +2348012345678
+Destination: Nigeria
+Service: Express (2 days)
+Content: Documents`,
+			expected: map[string]interface{}{
+				"ReceiverName":    "Alice Smith",
+				"ReceiverCountry": "Nigeria",
+				"ServiceLevel":    "express",
+				"CargoType":       "Documents",
+			},
+		},
 		{
 			name: "Misspelled & Brutal OCR Noise",
 			input: `*** SHIPPING DOCUMENT ***
@@ -267,6 +282,144 @@ Receiver Phone: 000000`, // Should ignore phone if after footer
 			if val, ok := tt.expected["ReceiverAddress"]; ok {
 				assert.Equal(t, val, m.ReceiverAddress, "ReceiverAddress mismatch")
 			}
+			if val, ok := tt.expected["ServiceLevel"]; ok {
+				assert.Equal(t, val, m.ServiceLevel, "ServiceLevel mismatch")
+			}
 		})
 	}
 }
+
+func TestParsePieces(t *testing.T) {
+	t.Run("Itemised boxes", func(t *testing.T) {
+		m := parser.ParseRegex(`Receiver: Alice Smith
+Phone: +2348012345678
+Address: 12 Allen Avenue, Ikeja
+Destination: Nigeria
+Sender: John Doe
+Box 1: 12kg 40x30x20 shoes
+Box 2: 8.5 kg, 30 x 30 x 30 cm - clothes`)
+
+		assert.Equal(t, "12 Allen Avenue, Ikeja", m.ReceiverAddress)
+		assert.Len(t, m.Pieces, 2)
+		assert.Equal(t, 20.5, m.Weight)
+		assert.Equal(t, 12.0, m.Pieces[0].Weight)
+		assert.Equal(t, 40.0, m.Pieces[0].LengthCm)
+		assert.Equal(t, "shoes", m.Pieces[0].Description)
+		assert.Equal(t, 30.0, m.Pieces[1].HeightCm)
+		assert.Equal(t, "clothes", m.Pieces[1].Description)
+	})
+
+	t.Run("Count with shared dimensions", func(t *testing.T) {
+		m := parser.ParseRegex(`Receiver: Bob Marley
+Phone: 09012223333
+Weight: 30kg
+Pieces: 3
+Dimensions: 0.5 x 0.4 x 0.3 m`)
+
+		assert.Len(t, m.Pieces, 3)
+		assert.Equal(t, 30.0, m.Weight)
+		for _, p := range m.Pieces {
+			assert.Equal(t, 10.0, p.Weight)
+			assert.Equal(t, 50.0, p.LengthCm)
+		}
+	})
+
+	t.Run("Single box without dimensions", func(t *testing.T) {
+		assert.Empty(t, parser.ParsePieces("Weight: 5kg"))
+	})
+
+	t.Run("CSV pieces", func(t *testing.T) {
+		manifests, err := parser.ParseCSV("ReceiverName,Destination,Weight,Pieces,Dimensions\nAlice,UK,20,4,40x30x20\n")
+		assert.NoError(t, err)
+		assert.Len(t, manifests[0].Pieces, 4)
+		assert.Equal(t, 5.0, manifests[0].Pieces[0].Weight)
+		assert.Equal(t, 20.0, manifests[0].Pieces[0].HeightCm)
+	})
+}
+
+func TestParseCOD(t *testing.T) {
+	cases := []struct {
+		in       string
+		amount   float64
+		currency string
+	}{
+		{"NGN 15,000", 15000, "NGN"},
+		{"15.000,50 EUR", 15000.50, "EUR"},
+		{"₦25000", 25000, "NGN"},
+		{"$120.5", 120.5, "USD"},
+		{"R$ 1.250", 1250, "BRL"},
+		{"7500", 7500, ""},
+		{"none", 0, ""},
+	}
+	for _, tc := range cases {
+		amount, currency := parser.ParseCOD(tc.in)
+		assert.Equal(t, tc.amount, amount, tc.in)
+		assert.Equal(t, tc.currency, currency, tc.in)
+	}
+
+	t.Run("Label in manifest", func(t *testing.T) {
+		m := parser.ParseRegex(`Receiver: Alice Smith
+Phone: +2348012345678
+Address: 12 Allen Avenue, Ikeja
+Cash on delivery: NGN 45,500
+Sender: John Doe`)
+
+		assert.Equal(t, 45500.0, m.CodAmount)
+		assert.Equal(t, "NGN", m.CodCurrency)
+		assert.Equal(t, "12 Allen Avenue, Ikeja", m.ReceiverAddress)
+	})
+}
+
+func TestExpandContactRefs(t *testing.T) {
+	contacts := map[string]models.Contact{
+		"mama": {
+			Name:    "Grace Okafor",
+			Phone:   "+2348031234567",
+			Address: "12 Allen Avenue, Ikeja",
+			Country: "Nigeria",
+			Email:   "grace@example.com",
+		},
+		"london": {Name: "Lagos Express UK", Country: "United Kingdom"},
+	}
+	text := `*To:* @Mama
+from - @london
+Content: Shoes
+Weight: 2kg`
+
+	assert.Equal(t, []string{"mama", "london"}, parser.ContactAliases(text))
+
+	expanded, unknown := parser.ExpandContactRefs(text, contacts)
+	assert.Empty(t, unknown)
+
+	m := parser.ParseRegex(expanded)
+	assert.Equal(t, "Grace Okafor", m.ReceiverName)
+	assert.Equal(t, "+2348031234567", m.ReceiverPhone)
+	assert.Equal(t, "12 Allen Avenue, Ikeja", m.ReceiverAddress)
+	assert.Equal(t, "Nigeria", m.ReceiverCountry)
+	assert.Equal(t, "grace@example.com", m.ReceiverEmail)
+	assert.Equal(t, "Lagos Express UK", m.SenderName)
+	assert.Equal(t, "United Kingdom", m.SenderCountry)
+	assert.Equal(t, "Shoes", m.CargoType)
+	assert.Empty(t, m.Validate())
+
+	t.Run("Unknown alias is kept", func(t *testing.T) {
+		out, unknown := parser.ExpandContactRefs("to: @stranger\nEmail me at a@b.co", contacts)
+		assert.Equal(t, []string{"stranger"}, unknown)
+		assert.Equal(t, "to: @stranger\nEmail me at a@b.co", out)
+	})
+}
+
+func TestExtractLabeled(t *testing.T) {
+	found, rest := parser.ExtractLabeled("name: Jo, Order No: AB1234, tags: vip, fragile\nphone: 0803", []string{"order no", "order", "tags"})
+	assert.Equal(t, map[string]string{"order no": "AB1234", "tags": "vip, fragile"}, found)
+	assert.Equal(t, "name: Jo\nphone: 0803", rest)
+
+	t.Run("CSV tags and custom field columns", func(t *testing.T) {
+		manifests, err := parser.ParseCSV("ReceiverName,Destination,Tags,Order No,cf:weight\nAlice,UK,vip;fragile,AB1234,heavy\n")
+		assert.NoError(t, err)
+		if assert.Len(t, manifests, 1) {
+			assert.Equal(t, []string{"vip", "fragile"}, manifests[0].Tags)
+			assert.Equal(t, map[string]string{"order no": "AB1234", "weight": "heavy"}, manifests[0].CustomFields)
+		}
+	})
+}
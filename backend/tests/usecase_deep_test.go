@@ -3,20 +3,42 @@ package tests
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"webtracker-bot/internal/blob"
 	"webtracker-bot/internal/database/db"
+	"webtracker-bot/internal/models"
 	"webtracker-bot/internal/shipment"
+	"webtracker-bot/internal/trackingid"
 	"webtracker-bot/internal/config"
+	"webtracker-bot/internal/utils"
 	)
 
 // MockQuerier is a manually implementation of db.Querier using testify/mock
 type MockQuerier struct {
 	mock.Mock
+	// otps stands in for the delivery_otps table
+	otps map[string]db.DeliveryOtp
+	// events collects the timeline entries written
+	events []db.InsertShipmentEventParams
+	// public stands in for lookups by tracking ID alone
+	public map[string]db.Shipment
+	// bags and bagged stand in for consolidations and consolidation_shipments
+	bags   map[string]db.Consolidation
+	bagged map[string]int32
+	// attemptErr makes InsertDeliveryAttempt fail
+	attemptErr error
 }
 
 func (m *MockQuerier) GetShipment(ctx context.Context, arg db.GetShipmentParams) (db.Shipment, error) {
@@ -29,29 +51,19 @@ func (m *MockQuerier) CreateShipment(ctx context.Context, arg db.CreateShipmentP
 	return args.Error(0)
 }
 
-func (m *MockQuerier) UpdateShipmentStatus(ctx context.Context, arg db.UpdateShipmentStatusParams) error {
+func (m *MockQuerier) UpdateShipmentStatus(ctx context.Context, arg db.UpdateShipmentStatusParams) (sql.Result, error) {
 	args := m.Called(ctx, arg)
-	return args.Error(0)
-}
-
-func (m *MockQuerier) TransitionStatusToIntransit(ctx context.Context, arg db.TransitionStatusToIntransitParams) ([]db.TransitionStatusToIntransitRow, error) {
-	args := m.Called(ctx, arg)
-	return args.Get(0).([]db.TransitionStatusToIntransitRow), args.Error(1)
+	return args.Get(0).(sql.Result), args.Error(1)
 }
 
-func (m *MockQuerier) TransitionStatusToOutForDelivery(ctx context.Context, arg db.TransitionStatusToOutForDeliveryParams) ([]db.TransitionStatusToOutForDeliveryRow, error) {
+func (m *MockQuerier) ListDueTransitions(ctx context.Context, arg db.ListDueTransitionsParams) ([]db.Shipment, error) {
 	args := m.Called(ctx, arg)
-	return args.Get(0).([]db.TransitionStatusToOutForDeliveryRow), args.Error(1)
+	return args.Get(0).([]db.Shipment), args.Error(1)
 }
 
-func (m *MockQuerier) TransitionStatusToDelivered(ctx context.Context, arg db.TransitionStatusToDeliveredParams) ([]db.TransitionStatusToDeliveredRow, error) {
+func (m *MockQuerier) BulkUpdateStatus(ctx context.Context, arg db.BulkUpdateStatusParams) ([]string, error) {
 	args := m.Called(ctx, arg)
-	return args.Get(0).([]db.TransitionStatusToDeliveredRow), args.Error(1)
-}
-
-// Implement remaining methods with no-ops
-func (m *MockQuerier) BulkUpdateStatus(ctx context.Context, arg db.BulkUpdateStatusParams) error {
-	return nil
+	return args.Get(0).([]string), args.Error(1)
 }
 func (m *MockQuerier) BulkDeleteShipments(ctx context.Context, arg db.BulkDeleteShipmentsParams) (sql.Result, error) {
 	return mockResult{}, nil
@@ -71,11 +83,12 @@ func (m *MockQuerier) CountShipments(ctx context.Context, companyID uuid.NullUUI
 func (m *MockQuerier) CountShipmentsByStatus(ctx context.Context, companyID uuid.NullUUID) (db.CountShipmentsByStatusRow, error) {
 	return db.CountShipmentsByStatusRow{}, nil
 }
-func (m *MockQuerier) DeleteDeliveredShipments(ctx context.Context, companyID uuid.NullUUID) error {
-	return nil
+func (m *MockQuerier) DeleteDeliveredShipments(ctx context.Context, arg db.DeleteDeliveredShipmentsParams) (sql.Result, error) {
+	return mockResult{}, nil
 }
-func (m *MockQuerier) DeleteShipment(ctx context.Context, arg db.DeleteShipmentParams) error {
-	return nil
+func (m *MockQuerier) DeleteShipment(ctx context.Context, arg db.DeleteShipmentParams) (sql.Result, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(sql.Result), args.Error(1)
 }
 func (m *MockQuerier) FindSimilarShipment(ctx context.Context, arg db.FindSimilarShipmentParams) (string, error) {
 	return "", nil
@@ -130,8 +143,9 @@ func (m *MockQuerier) SetSystemConfig(ctx context.Context, arg db.SetSystemConfi
 func (m *MockQuerier) SetUserLanguage(ctx context.Context, arg db.SetUserLanguageParams) error {
 	return nil
 }
-func (m *MockQuerier) UpdateShipmentDynamic(ctx context.Context, arg db.UpdateShipmentDynamicParams) error {
-	return nil
+func (m *MockQuerier) UpdateShipmentDynamic(ctx context.Context, arg db.UpdateShipmentDynamicParams) (sql.Result, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(sql.Result), args.Error(1)
 }
 func (m *MockQuerier) CreateCompany(ctx context.Context, arg db.CreateCompanyParams) (db.Company, error) {
 	args := m.Called(ctx, arg)
@@ -193,12 +207,473 @@ func (m *MockQuerier) UpdateCompanyPlan(ctx context.Context, arg db.UpdateCompan
 func (m *MockQuerier) UpdateCompanySubscription(ctx context.Context, arg db.UpdateCompanySubscriptionParams) error {
 	return nil
 }
+func (m *MockQuerier) DeleteCompany(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+func (m *MockQuerier) UpdateCompanySubscriptionWithPlan(ctx context.Context, arg db.UpdateCompanySubscriptionWithPlanParams) error {
+	return nil
+}
+func (m *MockQuerier) UpdateCompanyWhatsAppPhone(ctx context.Context, arg db.UpdateCompanyWhatsAppPhoneParams) error {
+	return nil
+}
+func (m *MockQuerier) GetShipmentByTrackingID(ctx context.Context, trackingID string) (db.Shipment, error) {
+	return m.public[trackingID], nil
+}
+func (m *MockQuerier) GetShipmentStatuses(ctx context.Context, arg db.GetShipmentStatusesParams) ([]db.GetShipmentStatusesRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.GetShipmentStatusesRow), args.Error(1)
+}
+func (m *MockQuerier) InsertShipmentEvent(ctx context.Context, arg db.InsertShipmentEventParams) error {
+	m.events = append(m.events, arg)
+	return nil
+}
+func (m *MockQuerier) ListShipmentEvents(ctx context.Context, arg db.ListShipmentEventsParams) ([]db.ShipmentEvent, error) {
+	var out []db.ShipmentEvent
+	for i, e := range m.events {
+		if e.TrackingID != arg.TrackingID {
+			continue
+		}
+		out = append(out, db.ShipmentEvent{
+			ID: int32(i + 1), CompanyID: e.CompanyID, TrackingID: e.TrackingID, Status: e.Status,
+			PreviousStatus: e.PreviousStatus, Source: e.Source, Actor: e.Actor, Description: e.Description,
+		})
+	}
+	return out, nil
+}
+func (m *MockQuerier) UpsertHoliday(ctx context.Context, arg db.UpsertHolidayParams) error {
+	return nil
+}
+func (m *MockQuerier) ListHolidays(ctx context.Context, arg db.ListHolidaysParams) ([]db.Holiday, error) {
+	return nil, nil
+}
+func (m *MockQuerier) DeleteHoliday(ctx context.Context, arg db.DeleteHolidayParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+func (m *MockQuerier) DeleteHolidaysByCountry(ctx context.Context, arg db.DeleteHolidaysByCountryParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+func (m *MockQuerier) UpdateShipmentCost(ctx context.Context, arg db.UpdateShipmentCostParams) (sql.Result, error) {
+	return mockResult{rows: 1}, nil
+}
+func (m *MockQuerier) InsertShipmentPiece(ctx context.Context, arg db.InsertShipmentPieceParams) error {
+	return nil
+}
+func (m *MockQuerier) ListShipmentPieces(ctx context.Context, arg db.ListShipmentPiecesParams) ([]db.ShipmentPiece, error) {
+	return nil, nil
+}
+func (m *MockQuerier) DeleteShipmentPieces(ctx context.Context, arg db.DeleteShipmentPiecesParams) error {
+	return nil
+}
+func (m *MockQuerier) UpsertCustomsDeclaration(ctx context.Context, arg db.UpsertCustomsDeclarationParams) error {
+	return nil
+}
+func (m *MockQuerier) GetCustomsDeclaration(ctx context.Context, arg db.GetCustomsDeclarationParams) (db.CustomsDeclaration, error) {
+	return db.CustomsDeclaration{}, sql.ErrNoRows
+}
+func (m *MockQuerier) DeleteCustomsDeclaration(ctx context.Context, arg db.DeleteCustomsDeclarationParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+func (m *MockQuerier) InsertCustomsItem(ctx context.Context, arg db.InsertCustomsItemParams) error {
+	return nil
+}
+func (m *MockQuerier) ListCustomsItems(ctx context.Context, arg db.ListCustomsItemsParams) ([]db.CustomsItem, error) {
+	return nil, nil
+}
+func (m *MockQuerier) DeleteCustomsItems(ctx context.Context, arg db.DeleteCustomsItemsParams) error {
+	return nil
+}
+
+func (m *MockQuerier) UpdateShipmentCOD(ctx context.Context, arg db.UpdateShipmentCODParams) (sql.Result, error) {
+	return mockResult{rows: 1}, nil
+}
+func (m *MockQuerier) InsertCodEntry(ctx context.Context, arg db.InsertCodEntryParams) (db.CodLedger, error) {
+	return db.CodLedger{}, nil
+}
+func (m *MockQuerier) GetCodCollection(ctx context.Context, arg db.GetCodCollectionParams) (db.CodLedger, error) {
+	return db.CodLedger{}, sql.ErrNoRows
+}
+func (m *MockQuerier) ListCodEntries(ctx context.Context, arg db.ListCodEntriesParams) ([]db.CodLedger, error) {
+	return nil, nil
+}
+func (m *MockQuerier) SumCodLedger(ctx context.Context, companyID uuid.NullUUID) ([]db.SumCodLedgerRow, error) {
+	return nil, nil
+}
+func (m *MockQuerier) SumOpenCod(ctx context.Context, companyID uuid.NullUUID) ([]db.SumOpenCodRow, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListUncollectedCod(ctx context.Context, arg db.ListUncollectedCodParams) ([]db.ListUncollectedCodRow, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListTrashedShipments(ctx context.Context, arg db.ListTrashedShipmentsParams) ([]db.Shipment, error) {
+	return nil, nil
+}
+func (m *MockQuerier) PurgeTrashedShipments(ctx context.Context, arg db.PurgeTrashedShipmentsParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+func (m *MockQuerier) RestoreShipment(ctx context.Context, arg db.RestoreShipmentParams) (sql.Result, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(sql.Result), args.Error(1)
+}
+
+func (m *MockQuerier) ListAgedShipments(ctx context.Context, arg db.ListAgedShipmentsParams) ([]db.Shipment, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListExpiredTrash(ctx context.Context, arg db.ListExpiredTrashParams) ([]db.Shipment, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListEventsForShipments(ctx context.Context, arg db.ListEventsForShipmentsParams) ([]db.ShipmentEvent, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListPiecesForShipments(ctx context.Context, arg db.ListPiecesForShipmentsParams) ([]db.ShipmentPiece, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListCustomsForShipments(ctx context.Context, arg db.ListCustomsForShipmentsParams) ([]db.CustomsDeclaration, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListCustomsItemsForShipments(ctx context.Context, arg db.ListCustomsItemsForShipmentsParams) ([]db.CustomsItem, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListChangesForShipments(ctx context.Context, arg db.ListChangesForShipmentsParams) ([]db.ShipmentChange, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListAttemptsForShipments(ctx context.Context, arg db.ListAttemptsForShipmentsParams) ([]db.DeliveryAttempt, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListCheckpointsForShipments(ctx context.Context, arg db.ListCheckpointsForShipmentsParams) ([]db.ShipmentCheckpoint, error) {
+	return nil, nil
+}
+func (m *MockQuerier) RestoreCustomsDeclaration(ctx context.Context, arg db.RestoreCustomsDeclarationParams) error {
+	return nil
+}
+func (m *MockQuerier) RestoreShipmentChange(ctx context.Context, arg db.RestoreShipmentChangeParams) error {
+	return nil
+}
+func (m *MockQuerier) RestoreDeliveryAttempt(ctx context.Context, arg db.RestoreDeliveryAttemptParams) error {
+	return nil
+}
+func (m *MockQuerier) RestoreCheckpoint(ctx context.Context, arg db.RestoreCheckpointParams) error {
+	return nil
+}
+func (m *MockQuerier) PurgeShipments(ctx context.Context, arg db.PurgeShipmentsParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+func (m *MockQuerier) RestoreShipmentState(ctx context.Context, arg db.RestoreShipmentStateParams) error {
+	return nil
+}
+func (m *MockQuerier) RestoreShipmentEvent(ctx context.Context, arg db.RestoreShipmentEventParams) error {
+	return nil
+}
+func (m *MockQuerier) SearchShipments(ctx context.Context, arg db.SearchShipmentsParams) ([]db.Shipment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.Shipment), args.Error(1)
+}
+func (m *MockQuerier) NextTrackingSequence(ctx context.Context, arg db.NextTrackingSequenceParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuerier) InsertShipmentChange(ctx context.Context, arg db.InsertShipmentChangeParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) ListShipmentChanges(ctx context.Context, arg db.ListShipmentChangesParams) ([]db.ShipmentChange, error) {
+	return nil, nil
+}
+
+func (m *MockQuerier) ListLastChangeBatch(ctx context.Context, arg db.ListLastChangeBatchParams) ([]db.ShipmentChange, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ShipmentChange), args.Error(1)
+}
+
+func (m *MockQuerier) MarkChangeBatchReverted(ctx context.Context, arg db.MarkChangeBatchRevertedParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockQuerier) SetShipmentField(ctx context.Context, arg db.SetShipmentFieldParams) (sql.Result, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(sql.Result), args.Error(1)
+}
+
+func (m *MockQuerier) UpsertContactFromShipment(ctx context.Context, arg db.UpsertContactFromShipmentParams) error {
+	return nil
+}
+
+func (m *MockQuerier) CreateContact(ctx context.Context, arg db.CreateContactParams) (db.Contact, error) {
+	return db.Contact{}, nil
+}
+
+func (m *MockQuerier) GetContact(ctx context.Context, arg db.GetContactParams) (db.Contact, error) {
+	return db.Contact{}, sql.ErrNoRows
+}
+
+func (m *MockQuerier) UpdateContact(ctx context.Context, arg db.UpdateContactParams) (db.Contact, error) {
+	return db.Contact{}, sql.ErrNoRows
+}
+
+func (m *MockQuerier) DeleteContact(ctx context.Context, arg db.DeleteContactParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+
+func (m *MockQuerier) ListContacts(ctx context.Context, arg db.ListContactsParams) ([]db.Contact, error) {
+	return nil, nil
+}
+
+func (m *MockQuerier) GetContactsByAliases(ctx context.Context, arg db.GetContactsByAliasesParams) ([]db.Contact, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.Contact), args.Error(1)
+}
+
+func (m *MockQuerier) ListCustomFieldDefinitions(ctx context.Context, companyID uuid.UUID) ([]db.CustomFieldDefinition, error) {
+	return nil, nil
+}
+
+func (m *MockQuerier) UpsertCustomFieldDefinition(ctx context.Context, arg db.UpsertCustomFieldDefinitionParams) (db.CustomFieldDefinition, error) {
+	return db.CustomFieldDefinition{}, nil
+}
+
+func (m *MockQuerier) DeleteCustomFieldDefinition(ctx context.Context, arg db.DeleteCustomFieldDefinitionParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+
+func (m *MockQuerier) UpdateShipmentExtras(ctx context.Context, arg db.UpdateShipmentExtrasParams) (sql.Result, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(sql.Result), args.Error(1)
+}
+
+func (m *MockQuerier) MarkShipmentReturned(ctx context.Context, arg db.MarkShipmentReturnedParams) (sql.Result, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(sql.Result), args.Error(1)
+}
+func (m *MockQuerier) CountDeliveryAttempts(ctx context.Context, arg db.CountDeliveryAttemptsParams) (int64, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockQuerier) InsertDeliveryAttempt(ctx context.Context, arg db.InsertDeliveryAttemptParams) (db.DeliveryAttempt, error) {
+	if m.attemptErr != nil {
+		return db.DeliveryAttempt{}, m.attemptErr
+	}
+	return db.DeliveryAttempt{CompanyID: arg.CompanyID, TrackingID: arg.TrackingID, AttemptNo: arg.AttemptNo, Reason: arg.Reason, Note: arg.Note, Source: arg.Source, Actor: arg.Actor, NextAttemptAt: arg.NextAttemptAt}, nil
+}
+func (m *MockQuerier) ListDeliveryAttempts(ctx context.Context, arg db.ListDeliveryAttemptsParams) ([]db.DeliveryAttempt, error) {
+	return nil, nil
+}
+func (m *MockQuerier) RescheduleDelivery(ctx context.Context, arg db.RescheduleDeliveryParams) (sql.Result, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(sql.Result), args.Error(1)
+}
+func (m *MockQuerier) ListRiders(ctx context.Context, companyID uuid.UUID) ([]db.Rider, error) {
+	args := m.Called(ctx, companyID)
+	return args.Get(0).([]db.Rider), args.Error(1)
+}
+func (m *MockQuerier) GetRider(ctx context.Context, arg db.GetRiderParams) (db.Rider, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Rider), args.Error(1)
+}
+func (m *MockQuerier) GetRiderByPhone(ctx context.Context, arg db.GetRiderByPhoneParams) (db.Rider, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.Rider), args.Error(1)
+}
+func (m *MockQuerier) CreateRider(ctx context.Context, arg db.CreateRiderParams) (db.Rider, error) {
+	return db.Rider{}, nil
+}
+func (m *MockQuerier) UpdateRider(ctx context.Context, arg db.UpdateRiderParams) (db.Rider, error) {
+	return db.Rider{}, nil
+}
+func (m *MockQuerier) DeleteRider(ctx context.Context, arg db.DeleteRiderParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+func (m *MockQuerier) AssignRider(ctx context.Context, arg db.AssignRiderParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+func (m *MockQuerier) UnassignRider(ctx context.Context, arg db.UnassignRiderParams) (sql.Result, error) {
+	return mockResult{}, nil
+}
+func (m *MockQuerier) GetRiderAssignment(ctx context.Context, arg db.GetRiderAssignmentParams) (db.RiderAssignment, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.RiderAssignment), args.Error(1)
+}
+func (m *MockQuerier) ListRiderPickups(ctx context.Context, arg db.ListRiderPickupsParams) ([]string, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]string), args.Error(1)
+}
+func (m *MockQuerier) MarkRiderPickedUp(ctx context.Context, arg db.MarkRiderPickedUpParams) error {
+	return nil
+}
+func (m *MockQuerier) ListAssignedShipments(ctx context.Context, arg db.ListAssignedShipmentsParams) ([]string, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]string), args.Error(1)
+}
+func (m *MockQuerier) InsertCheckpoint(ctx context.Context, arg db.InsertCheckpointParams) (db.ShipmentCheckpoint, error) {
+	args := m.Called(ctx, arg)
+	return db.ShipmentCheckpoint{CompanyID: arg.CompanyID, TrackingID: arg.TrackingID, Latitude: arg.Latitude, Longitude: arg.Longitude, AccuracyM: arg.AccuracyM, Label: arg.Label, Live: arg.Live, Source: arg.Source, Actor: arg.Actor}, args.Error(0)
+}
+func (m *MockQuerier) GetLastCheckpoint(ctx context.Context, arg db.GetLastCheckpointParams) (db.ShipmentCheckpoint, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.ShipmentCheckpoint), args.Error(1)
+}
+func (m *MockQuerier) OpenCheckin(ctx context.Context, arg db.OpenCheckinParams) error {
+	return nil
+}
+func (m *MockQuerier) GetOpenCheckin(ctx context.Context, arg db.GetOpenCheckinParams) (string, error) {
+	return "", sql.ErrNoRows
+}
+func (m *MockQuerier) InsertPOD(ctx context.Context, arg db.InsertPODParams) (db.ShipmentPod, error) {
+	args := m.Called(ctx, arg)
+	return db.ShipmentPod{ID: 1, CompanyID: arg.CompanyID, TrackingID: arg.TrackingID, Kind: arg.Kind, BlobKey: arg.BlobKey, ContentType: arg.ContentType, SizeBytes: arg.SizeBytes, Source: arg.Source, Actor: arg.Actor}, args.Error(0)
+}
+func (m *MockQuerier) ListPODs(ctx context.Context, arg db.ListPODsParams) ([]db.ShipmentPod, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]db.ShipmentPod), args.Error(1)
+}
+func (m *MockQuerier) ListPODKeysForShipments(ctx context.Context, arg db.ListPODKeysForShipmentsParams) ([]string, error) {
+	return nil, nil
+}
+func (m *MockQuerier) ListTrashedPODKeys(ctx context.Context, arg db.ListTrashedPODKeysParams) ([]string, error) {
+	args := m.Called(ctx, arg)
+	keys, _ := args.Get(0).([]string)
+	return keys, args.Error(1)
+}
+func (m *MockQuerier) ListAgedPODKeys(ctx context.Context, arg db.ListAgedPODKeysParams) ([]string, error) {
+	return nil, nil
+}
+func (m *MockQuerier) GetPOD(ctx context.Context, arg db.GetPODParams) (db.ShipmentPod, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(db.ShipmentPod), args.Error(1)
+}
+
+func (m *MockQuerier) IssueDeliveryOTP(ctx context.Context, arg db.IssueDeliveryOTPParams) error {
+	if m.otps == nil {
+		m.otps = map[string]db.DeliveryOtp{}
+	}
+	m.otps[arg.TrackingID] = db.DeliveryOtp{CompanyID: arg.CompanyID, TrackingID: arg.TrackingID, CodeHash: arg.CodeHash, IssuedAt: time.Now()}
+	return nil
+}
+func (m *MockQuerier) GetDeliveryOTP(ctx context.Context, arg db.GetDeliveryOTPParams) (db.DeliveryOtp, error) {
+	otp, ok := m.otps[arg.TrackingID]
+	if !ok {
+		return db.DeliveryOtp{}, sql.ErrNoRows
+	}
+	return otp, nil
+}
+func (m *MockQuerier) ReserveDeliveryOTPAttempt(ctx context.Context, arg db.ReserveDeliveryOTPAttemptParams) (int32, error) {
+	otp, ok := m.otps[arg.TrackingID]
+	if !ok || otp.FailedAttempts >= arg.FailedAttempts {
+		return 0, sql.ErrNoRows
+	}
+	otp.FailedAttempts++
+	m.otps[arg.TrackingID] = otp
+	return otp.FailedAttempts, nil
+}
+func (m *MockQuerier) ReleaseDeliveryOTPAttempt(ctx context.Context, arg db.ReleaseDeliveryOTPAttemptParams) error {
+	otp := m.otps[arg.TrackingID]
+	if otp.FailedAttempts > 0 {
+		otp.FailedAttempts--
+	}
+	m.otps[arg.TrackingID] = otp
+	return nil
+}
+func (m *MockQuerier) ConfirmDeliveryOTP(ctx context.Context, arg db.ConfirmDeliveryOTPParams) error {
+	otp := m.otps[arg.TrackingID]
+	otp.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	m.otps[arg.TrackingID] = otp
+	return nil
+}
+
+func (m *MockQuerier) CreateConsolidation(ctx context.Context, arg db.CreateConsolidationParams) (db.Consolidation, error) {
+	if m.bags == nil {
+		m.bags = map[string]db.Consolidation{}
+	}
+	c := db.Consolidation{ID: int32(len(m.bags) + 1), CompanyID: arg.CompanyID, MasterAwb: arg.MasterAwb, Label: arg.Label, State: "open", CreatedBy: arg.CreatedBy, CreatedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+	m.bags[arg.MasterAwb] = c
+	return c, nil
+}
+func (m *MockQuerier) GetConsolidation(ctx context.Context, arg db.GetConsolidationParams) (db.Consolidation, error) {
+	c, ok := m.bags[arg.MasterAwb]
+	if !ok {
+		return db.Consolidation{}, sql.ErrNoRows
+	}
+	return c, nil
+}
+func (m *MockQuerier) ListConsolidations(ctx context.Context, arg db.ListConsolidationsParams) ([]db.ListConsolidationsRow, error) {
+	return nil, nil
+}
+func (m *MockQuerier) bagByID(id int32) (db.Consolidation, bool) {
+	for _, c := range m.bags {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return db.Consolidation{}, false
+}
+func (m *MockQuerier) setBagState(id int32, from, to string) (sql.Result, error) {
+	c, ok := m.bagByID(id)
+	if !ok || c.State != from {
+		return mockResult{rows: 0}, nil
+	}
+	c.State = to
+	m.bags[c.MasterAwb] = c
+	return mockResult{rows: 1}, nil
+}
+func (m *MockQuerier) CloseConsolidation(ctx context.Context, arg db.CloseConsolidationParams) (sql.Result, error) {
+	return m.setBagState(arg.ID, "open", "closed")
+}
+func (m *MockQuerier) DepartConsolidation(ctx context.Context, arg db.DepartConsolidationParams) (sql.Result, error) {
+	return m.setBagState(arg.ID, "closed", "departed")
+}
+func (m *MockQuerier) SetConsolidationStatus(ctx context.Context, arg db.SetConsolidationStatusParams) error {
+	c, _ := m.bagByID(arg.ID)
+	c.Status = arg.Status
+	m.bags[c.MasterAwb] = c
+	return nil
+}
+func (m *MockQuerier) AddToConsolidation(ctx context.Context, arg db.AddToConsolidationParams) (sql.Result, error) {
+	if m.bagged == nil {
+		m.bagged = map[string]int32{}
+	}
+	var n int64
+	for _, id := range arg.Column2 {
+		if _, ok := m.bagged[id]; ok {
+			continue
+		}
+		m.bagged[id] = arg.ConsolidationID
+		n++
+	}
+	return mockResult{rows: n}, nil
+}
+func (m *MockQuerier) RemoveFromConsolidation(ctx context.Context, arg db.RemoveFromConsolidationParams) (sql.Result, error) {
+	if id, ok := m.bagged[arg.TrackingID]; !ok || id != arg.ConsolidationID {
+		return mockResult{rows: 0}, nil
+	}
+	delete(m.bagged, arg.TrackingID)
+	return mockResult{rows: 1}, nil
+}
+func (m *MockQuerier) ListConsolidationShipments(ctx context.Context, arg db.ListConsolidationShipmentsParams) ([]string, error) {
+	var ids []string
+	for id, bag := range m.bagged {
+		if bag == arg.ConsolidationID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+func (m *MockQuerier) GetShipmentConsolidation(ctx context.Context, arg db.GetShipmentConsolidationParams) (string, error) {
+	id, ok := m.bagged[arg.TrackingID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	c, _ := m.bagByID(id)
+	return c.MasterAwb, nil
+}
 
 // mockResult implements sql.Result for mock returns
-type mockResult struct{}
+type mockResult struct{ rows int64 }
 
-func (mockResult) LastInsertId() (int64, error) { return 0, nil }
-func (mockResult) RowsAffected() (int64, error) { return 0, nil }
+func (mockResult) LastInsertId() (int64, error)   { return 0, nil }
+func (r mockResult) RowsAffected() (int64, error) { return r.rows, nil }
 
 // Test Company ID for all tests
 var testCompanyID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
@@ -228,26 +703,27 @@ func TestShipmentUsecase_Deep(t *testing.T) {
 
 	t.Run("ProcessTransitions_DeepFlow", func(t *testing.T) {
 		now := time.Now()
-		nullNow := sql.NullTime{Time: now, Valid: true}
+		past := sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+		future := sql.NullTime{Time: now.Add(time.Hour), Valid: true}
 		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		status := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
 
-		// 1. Pending -> InTransit
-		intransitParams := db.TransitionStatusToIntransitParams{CompanyID: companyNullUUID, ScheduledTransitTime: nullNow}
-		repo.On("TransitionStatusToIntransit", ctx, intransitParams).Return([]db.TransitionStatusToIntransitRow{
-			{TrackingID: "T1", NewStatus: sql.NullString{String: "intransit", Valid: true}, UserJid: "U1"},
-		}, nil).Once()
-
-		// 2. InTransit -> OutForDelivery
-		ofdParams := db.TransitionStatusToOutForDeliveryParams{CompanyID: companyNullUUID, OutfordeliveryTime: nullNow}
-		repo.On("TransitionStatusToOutForDelivery", ctx, ofdParams).Return([]db.TransitionStatusToOutForDeliveryRow{
-			{TrackingID: "T2", NewStatus: sql.NullString{String: "outfordelivery", Valid: true}, UserJid: "U2"},
-		}, nil).Once()
-
-		// 3. OutForDelivery -> Delivered
-		deliveredParams := db.TransitionStatusToDeliveredParams{CompanyID: companyNullUUID, ExpectedDeliveryTime: nullNow}
-		repo.On("TransitionStatusToDelivered", ctx, deliveredParams).Return([]db.TransitionStatusToDeliveredRow{
-			{TrackingID: "T3", NewStatus: sql.NullString{String: "delivered", Valid: true}, UserJid: "U3"},
+		repo.On("ListDueTransitions", ctx, db.ListDueTransitionsParams{CompanyID: companyNullUUID, Now: now}).Return([]db.Shipment{
+			// 1. Pending -> InTransit
+			{TrackingID: "T1", UserJid: "U1", Status: status("pending"), Version: 1, ScheduledTransitTime: past, OutfordeliveryTime: future},
+			// 2. InTransit -> OutForDelivery
+			{TrackingID: "T2", UserJid: "U2", Status: status("intransit"), Version: 4, OutfordeliveryTime: past, ExpectedDeliveryTime: future},
+			// 3. OutForDelivery -> Delivered
+			{TrackingID: "T3", UserJid: "U3", Status: status("outfordelivery"), Version: 2, ExpectedDeliveryTime: past},
 		}, nil).Once()
+		repo.On("ListAssignedShipments", ctx, db.ListAssignedShipmentsParams{CompanyID: testCompanyID, Column2: []string{"T1", "T2", "T3"}}).Return([]string(nil), nil).Once()
+		for _, step := range []struct {
+			id, next string
+			version  int32
+		}{{"T1", "intransit", 1}, {"T2", "outfordelivery", 4}, {"T3", "delivered", 2}} {
+			repo.On("UpdateShipmentDynamic", ctx, db.UpdateShipmentDynamicParams{CompanyID: companyNullUUID, TrackingID: step.id, Column16: step.next, Version: step.version}).
+				Return(mockResult{rows: 1}, nil).Once()
+		}
 
 		results, err := uc.ProcessTransitions(ctx, testCompanyID, now)
 		assert.NoError(t, err)
@@ -256,6 +732,708 @@ func TestShipmentUsecase_Deep(t *testing.T) {
 		assert.Equal(t, "intransit", results[0].NewStatus)
 		repo.AssertExpectations(t)
 	})
+
+	t.Run("ProcessTransitions_RetriesOnConflict", func(t *testing.T) {
+		now := time.Now()
+		past := sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		stale := db.Shipment{TrackingID: "T4", Status: sql.NullString{String: "pending", Valid: true}, Version: 1, ScheduledTransitTime: past}
+		// An admin put the shipment on hold between the pulse's read and write
+		held := stale
+		held.Status = sql.NullString{String: "on_hold", Valid: true}
+		held.Version = 2
+
+		repo.On("ListDueTransitions", ctx, db.ListDueTransitionsParams{CompanyID: companyNullUUID, Now: now}).Return([]db.Shipment{stale}, nil).Once()
+		repo.On("ListAssignedShipments", ctx, db.ListAssignedShipmentsParams{CompanyID: testCompanyID, Column2: []string{"T4"}}).Return([]string(nil), nil).Once()
+		repo.On("UpdateShipmentDynamic", ctx, db.UpdateShipmentDynamicParams{CompanyID: companyNullUUID, TrackingID: "T4", Column16: "intransit", Version: 1}).
+			Return(mockResult{}, nil).Once()
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "T4"}).Return(held, nil).Once()
+
+		results, err := uc.ProcessTransitions(ctx, testCompanyID, now)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("ProcessTransitions_RiderHeld", func(t *testing.T) {
+		now := time.Now()
+		past := sql.NullTime{Time: now.Add(-time.Hour), Valid: true}
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		status := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+
+		// Both are overdue all the way to delivered, but T6 has a rider
+		repo.On("ListDueTransitions", ctx, db.ListDueTransitionsParams{CompanyID: companyNullUUID, Now: now}).Return([]db.Shipment{
+			{TrackingID: "T5", Status: status("pending"), Version: 1, ScheduledTransitTime: past, OutfordeliveryTime: past, ExpectedDeliveryTime: past},
+			{TrackingID: "T6", Status: status("pending"), Version: 1, ScheduledTransitTime: past, OutfordeliveryTime: past, ExpectedDeliveryTime: past},
+		}, nil).Once()
+		repo.On("ListAssignedShipments", ctx, db.ListAssignedShipmentsParams{CompanyID: testCompanyID, Column2: []string{"T5", "T6"}}).Return([]string{"T6"}, nil).Once()
+		for i, next := range []string{"intransit", "outfordelivery", "delivered"} {
+			repo.On("UpdateShipmentDynamic", ctx, db.UpdateShipmentDynamicParams{CompanyID: companyNullUUID, TrackingID: "T5", Column16: next, Version: int32(i + 1)}).
+				Return(mockResult{rows: 1}, nil).Once()
+		}
+		repo.On("UpdateShipmentDynamic", ctx, db.UpdateShipmentDynamicParams{CompanyID: companyNullUUID, TrackingID: "T6", Column16: "intransit", Version: 1}).
+			Return(mockResult{rows: 1}, nil).Once()
+
+		results, err := uc.ProcessTransitions(ctx, testCompanyID, now)
+		assert.NoError(t, err)
+		require.Len(t, results, 4)
+		assert.Equal(t, "T6", results[3].TrackingID)
+		assert.Equal(t, "intransit", results[3].NewStatus)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Delete_MovesToTrash", func(t *testing.T) {
+		adminCtx := utils.WithSource(ctx, utils.SourceAPI, "ops@example.com")
+		delParams := db.DeleteShipmentParams{
+			CompanyID:  uuid.NullUUID{UUID: testCompanyID, Valid: true},
+			TrackingID: "AWB-201",
+			DeletedBy:  sql.NullString{String: "ops@example.com", Valid: true},
+		}
+		repo.On("DeleteShipment", adminCtx, delParams).Return(mockResult{rows: 1}, nil).Once()
+		assert.NoError(t, uc.Delete(adminCtx, testCompanyID, "AWB-201"))
+
+		delParams.TrackingID = "AWB-404"
+		repo.On("DeleteShipment", adminCtx, delParams).Return(mockResult{}, nil).Once()
+		assert.ErrorIs(t, uc.Delete(adminCtx, testCompanyID, "AWB-404"), sql.ErrNoRows)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Restore_NotInTrash", func(t *testing.T) {
+		adminCtx := utils.WithSource(ctx, utils.SourceAPI, "ops@example.com")
+		params := db.RestoreShipmentParams{CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, TrackingID: "AWB-201"}
+		repo.On("RestoreShipment", adminCtx, params).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("GetShipment", adminCtx, db.GetShipmentParams{CompanyID: params.CompanyID, TrackingID: "AWB-201"}).
+			Return(db.Shipment{TrackingID: "AWB-201", Status: sql.NullString{String: "intransit", Valid: true}}, nil).Once()
+		repo.events = nil
+		assert.NoError(t, uc.Restore(adminCtx, testCompanyID, "AWB-201"))
+		require.Len(t, repo.events, 1)
+		assert.Equal(t, "ops@example.com", repo.events[0].Actor.String, "the restore is noted with who did it")
+
+		repo.On("RestoreShipment", ctx, params).Return(mockResult{}, nil).Once()
+		assert.ErrorIs(t, uc.Restore(ctx, testCompanyID, "AWB-201"), sql.ErrNoRows)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Search_CursorPagination", func(t *testing.T) {
+		created := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+		rows := []db.Shipment{
+			{TrackingID: "AWB-303", CreatedAt: sql.NullTime{Time: created, Valid: true}},
+			{TrackingID: "AWB-302", CreatedAt: sql.NullTime{Time: created, Valid: true}},
+			{TrackingID: "AWB-301", CreatedAt: sql.NullTime{Time: created, Valid: true}},
+		}
+		first := db.SearchShipmentsParams{
+			CompanyID:   uuid.NullUUID{UUID: testCompanyID, Valid: true},
+			Query:       sql.NullString{String: `%+351 912\_%`, Valid: true},
+			PhoneDigits: sql.NullString{String: "%351912%", Valid: true},
+			RowLimit:    3,
+		}
+		repo.On("SearchShipments", ctx, first).Return(rows, nil).Once()
+		page, err := uc.Search(ctx, testCompanyID, shipment.SearchFilter{Query: " +351 912_ ", Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Shipments, 2)
+		assert.Equal(t, "AWB-302", page.Shipments[1].TrackingID)
+		assert.Equal(t, shipment.EncodeCursor(created, "AWB-302"), page.NextCursor)
+
+		next := db.SearchShipmentsParams{
+			CompanyID:        uuid.NullUUID{UUID: testCompanyID, Valid: true},
+			CursorCreatedAt:  sql.NullTime{Time: created, Valid: true},
+			CursorTrackingID: sql.NullString{String: "AWB-302", Valid: true},
+			RowLimit:         3,
+		}
+		repo.On("SearchShipments", ctx, next).Return(rows[2:], nil).Once()
+		page, err = uc.Search(ctx, testCompanyID, shipment.SearchFilter{Cursor: page.NextCursor, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Shipments, 1)
+		assert.Empty(t, page.NextCursor)
+
+		_, err = uc.Search(ctx, testCompanyID, shipment.SearchFilter{Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, shipment.ErrInvalidCursor)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("NewTrackingID_Sequential", func(t *testing.T) {
+		cfgParams := db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.TrackingFormatKey}
+		repo.On("GetSystemConfig", ctx, cfgParams).Return(`{"scheme":"sequential","digits":6,"check_digit":true}`, nil).Twice()
+		repo.On("NextTrackingSequence", ctx, db.NextTrackingSequenceParams{CompanyID: testCompanyID, Scope: ""}).Return(int64(7), nil).Once()
+
+		id, err := uc.NewTrackingID(ctx, testCompanyID, "LG")
+		assert.NoError(t, err)
+		assert.Equal(t, "LG-0000079", id)
+
+		_, err = uc.ParseTrackingID(ctx, testCompanyID, "lg-0000078")
+		assert.ErrorIs(t, err, trackingid.ErrCheckDigit)
+		repo.AssertExpectations(t)
+	})
+	t.Run("UndoLastEdit_RevertsBatch", func(t *testing.T) {
+		actor := "2348000000000@s.whatsapp.net"
+		batchID := uuid.New()
+		batch := []db.ShipmentChange{
+			{ID: 2, TrackingID: "AWB-300", BatchID: batchID, Field: "recipient_phone", OldValue: sql.NullString{String: "+2341111", Valid: true}, NewValue: sql.NullString{String: "+2342222", Valid: true}},
+			{ID: 1, TrackingID: "AWB-300", BatchID: batchID, Field: "status", OldValue: sql.NullString{String: "pending", Valid: true}, NewValue: sql.NullString{String: "intransit", Valid: true}},
+		}
+		lastParams := db.ListLastChangeBatchParams{CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, Actor: sql.NullString{String: actor, Valid: true}}
+		repo.On("ListLastChangeBatch", ctx, lastParams).Return(batch, nil).Once()
+		getParams := db.GetShipmentParams{CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, TrackingID: "AWB-300"}
+		current := db.Shipment{
+			TrackingID:     "AWB-300",
+			Status:         sql.NullString{String: "intransit", Valid: true},
+			RecipientPhone: sql.NullString{String: "+2342222", Valid: true},
+			Destination:    sql.NullString{String: "Lisbon", Valid: true},
+			Version:        4,
+		}
+		// Once to check the fields still hold the edit, once by UpdateStatus
+		// after the phone revert
+		repo.On("GetShipment", ctx, getParams).Return(current, nil).Once()
+		reverted := current
+		reverted.RecipientPhone, reverted.Version = batch[0].OldValue, 5
+		repo.On("GetShipment", mock.Anything, getParams).Return(reverted, nil).Once()
+		repo.On("SetShipmentField", ctx, db.SetShipmentFieldParams{Field: "recipient_phone", Value: batch[0].OldValue, CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, TrackingID: "AWB-300", Version: 4}).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("UpdateShipmentStatus", mock.Anything, db.UpdateShipmentStatusParams{
+			CompanyID:   uuid.NullUUID{UUID: testCompanyID, Valid: true},
+			TrackingID:  "AWB-300",
+			Status:      sql.NullString{String: "pending", Valid: true},
+			Destination: sql.NullString{String: "Lisbon", Valid: true},
+			Version:     5,
+		}).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("InsertShipmentChange", mock.Anything, mock.MatchedBy(func(p db.InsertShipmentChangeParams) bool {
+			return p.IsUndo && p.BatchID != batchID
+		})).Return(nil).Twice()
+		repo.On("MarkChangeBatchReverted", ctx, db.MarkChangeBatchRevertedParams{CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, BatchID: batchID}).Return(nil).Once()
+
+		trackingID, changes, err := uc.UndoLastEdit(ctx, testCompanyID, actor)
+		require.NoError(t, err)
+		assert.Equal(t, "AWB-300", trackingID)
+		assert.Len(t, changes, 2)
+
+		repo.On("ListLastChangeBatch", ctx, lastParams).Return([]db.ShipmentChange{}, nil).Once()
+		_, _, err = uc.UndoLastEdit(ctx, testCompanyID, actor)
+		assert.ErrorIs(t, err, shipment.ErrNothingToUndo)
+		repo.AssertExpectations(t)
+	})
+	t.Run("BulkUpdateStatus_ReportsConflicts", func(t *testing.T) {
+		ids := []string{"AWB-401", "AWB-402"}
+		repo.On("GetShipmentStatuses", ctx, db.GetShipmentStatusesParams{CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, Column2: ids}).
+			Return([]db.GetShipmentStatusesRow{
+				{TrackingID: "AWB-401", Status: sql.NullString{String: "pending", Valid: true}, Version: 2},
+				{TrackingID: "AWB-402", Status: sql.NullString{String: "pending", Valid: true}, Version: 7},
+			}, nil).Once()
+		// AWB-402 was edited between the check and the write
+		repo.On("BulkUpdateStatus", ctx, db.BulkUpdateStatusParams{
+			CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true},
+			Column2:   ids,
+			Status:    sql.NullString{String: "intransit", Valid: true},
+			Column4:   []int32{2, 7},
+		}).Return([]string{"AWB-401"}, nil).Once()
+
+		err := uc.BulkUpdateStatus(ctx, testCompanyID, ids, "intransit")
+		var conflict *shipment.BulkConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, []string{"AWB-402"}, conflict.IDs)
+		assert.ErrorIs(t, err, shipment.ErrVersionConflict)
+		repo.AssertExpectations(t)
+	})
+	t.Run("UndoLastEdit_RefusesWhenChangedSince", func(t *testing.T) {
+		actor := "2348000000001@s.whatsapp.net"
+		batch := []db.ShipmentChange{
+			{ID: 5, TrackingID: "AWB-301", BatchID: uuid.New(), Field: "recipient_name", OldValue: sql.NullString{String: "Ada", Valid: true}, NewValue: sql.NullString{String: "Ada Obi", Valid: true}},
+		}
+		repo.On("ListLastChangeBatch", ctx, db.ListLastChangeBatchParams{CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, Actor: sql.NullString{String: actor, Valid: true}}).Return(batch, nil).Once()
+		// Someone renamed the recipient again after the edit
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, TrackingID: "AWB-301"}).
+			Return(db.Shipment{TrackingID: "AWB-301", RecipientName: sql.NullString{String: "Ada N. Obi", Valid: true}}, nil).Once()
+
+		trackingID, _, err := uc.UndoLastEdit(ctx, testCompanyID, actor)
+		assert.ErrorIs(t, err, shipment.ErrUndoStale)
+		assert.Equal(t, "AWB-301", trackingID)
+		repo.AssertNotCalled(t, "SetShipmentField", mock.Anything, mock.MatchedBy(func(p db.SetShipmentFieldParams) bool { return p.TrackingID == "AWB-301" }))
+		repo.AssertExpectations(t)
+	})
+	t.Run("ExpandContacts_ReplacesAlias", func(t *testing.T) {
+		manifest := "To: @mama\nContent: Shoes\nFrom: @nobody"
+		repo.On("GetContactsByAliases", ctx, db.GetContactsByAliasesParams{CompanyID: testCompanyID, Column2: []string{"mama", "nobody"}}).
+			Return([]db.Contact{{
+				Alias:   sql.NullString{String: "mama", Valid: true},
+				Name:    "Grace Okafor",
+				Phone:   "+2348031234567",
+				Address: sql.NullString{String: "12 Allen Avenue, Ikeja", Valid: true},
+				Country: sql.NullString{String: "Nigeria", Valid: true},
+			}}, nil).Once()
+
+		expanded, unknown, err := uc.ExpandContacts(ctx, testCompanyID, manifest)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"nobody"}, unknown)
+		assert.Contains(t, expanded, "Receiver Name: Grace Okafor\nReceiver Phone: +2348031234567")
+		assert.Contains(t, expanded, "From: @nobody")
+		repo.AssertExpectations(t)
+	})
+	t.Run("CreateReturn_SwapsParties", func(t *testing.T) {
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		orig := db.Shipment{
+			TrackingID:        "AWB-400",
+			UserJid:           "2348000000000@s.whatsapp.net",
+			Status:            str("delivery_failed"),
+			Version:           3,
+			SenderName:        str("Lagos Express"),
+			SenderPhone:       str("+2348011111111"),
+			Origin:            str("Nigeria"),
+			RecipientName:     str("Jane Doe"),
+			RecipientPhone:    str("+447700900123"),
+			RecipientTimezone: str("Europe/London"),
+			Destination:       str("United Kingdom"),
+			CargoType:         str("Shoes"),
+			CustomFields:      json.RawMessage(`{}`),
+			Tags:              []string{"vip"},
+		}
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-400"}).Return(orig, nil).Once()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.ScheduleProfileKey}).Return("", nil).Once()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.TrackingFormatKey}).Return("", nil).Once()
+
+		var created db.CreateShipmentParams
+		repo.On("CreateShipment", ctx, mock.AnythingOfType("db.CreateShipmentParams")).Run(func(args mock.Arguments) {
+			created = args.Get(1).(db.CreateShipmentParams)
+		}).Return(nil).Once()
+		repo.On("MarkShipmentReturned", ctx, mock.MatchedBy(func(p db.MarkShipmentReturnedParams) bool {
+			return p.TrackingID == "AWB-400" && p.ReturnID.String == created.TrackingID && p.ReturnReason.String == "refused" && p.Version == 3
+		})).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("InsertShipmentChange", ctx, mock.MatchedBy(func(p db.InsertShipmentChangeParams) bool {
+			return p.TrackingID == "AWB-400" && p.Field == "status" && p.NewValue.String == "returned"
+		})).Return(nil).Once()
+
+		returnID, err := uc.CreateReturn(ctx, testCompanyID, "AWB-400", " refused ", "AWB", 3)
+		require.NoError(t, err)
+		assert.Equal(t, created.TrackingID, returnID)
+		assert.Equal(t, "pending", created.Status.String)
+		assert.Equal(t, "Jane Doe", created.SenderName.String)
+		assert.Equal(t, "United Kingdom", created.Origin.String)
+		assert.Equal(t, "Lagos Express", created.RecipientName.String)
+		assert.Equal(t, "+2348011111111", created.RecipientPhone.String)
+		assert.Equal(t, "Nigeria", created.Destination.String)
+		assert.Equal(t, "AWB-400", created.ReturnOf.String)
+		assert.True(t, created.ExpectedDeliveryTime.Time.After(created.ScheduledTransitTime.Time))
+		last := repo.events[len(repo.events)-1]
+		assert.Equal(t, "returned", last.Status)
+		assert.Equal(t, "Returned to sender as "+returnID, last.Description.String)
+		repo.AssertExpectations(t)
+
+		// Edited meanwhile: the conflict comes back before anything is
+		// recorded on the original
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-400"}).Return(orig, nil).Once()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.ScheduleProfileKey}).Return("", nil).Once()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.TrackingFormatKey}).Return("", nil).Once()
+		repo.On("CreateShipment", ctx, mock.AnythingOfType("db.CreateShipmentParams")).Return(nil).Once()
+		repo.On("MarkShipmentReturned", ctx, mock.AnythingOfType("db.MarkShipmentReturnedParams")).Return(mockResult{rows: 0}, nil).Once()
+		events := len(repo.events)
+		_, err = uc.CreateReturn(ctx, testCompanyID, "AWB-400", "refused", "AWB", 3)
+		assert.ErrorIs(t, err, shipment.ErrVersionConflict)
+		assert.Len(t, repo.events, events+1) // only the return's own creation
+		repo.AssertExpectations(t)
+
+		// Already linked: nothing is created
+		orig.ReturnID = str(returnID)
+		orig.Status = str("returned")
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-400"}).Return(orig, nil).Once()
+		_, err = uc.CreateReturn(ctx, testCompanyID, "AWB-400", "again", "AWB", 0)
+		assert.ErrorIs(t, err, shipment.ErrAlreadyReturned)
+
+		_, err = uc.CreateReturn(ctx, testCompanyID, "AWB-400", "  ", "AWB", 0)
+		assert.ErrorIs(t, err, shipment.ErrInvalidReturn)
+		repo.AssertExpectations(t)
+	})
+	t.Run("RecordFailedAttempt_ReschedulesThenFails", func(t *testing.T) {
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		ofd := time.Now().UTC().Add(-2 * time.Hour)
+		ship := db.Shipment{
+			TrackingID:           "AWB-500",
+			UserJid:              "2348000000000@s.whatsapp.net",
+			Status:               str("outfordelivery"),
+			Version:              5,
+			Destination:          str("Nigeria"),
+			RecipientTimezone:    str("Africa/Lagos"),
+			OutfordeliveryTime:   sql.NullTime{Time: ofd, Valid: true},
+			ExpectedDeliveryTime: sql.NullTime{Time: ofd.Add(time.Hour), Valid: true},
+		}
+		// ServiceFor only builds a per-company Calculator when the usecase has one
+		attemptUC := shipment.NewUsecase(repo, &shipment.Calculator{})
+		key := db.CountDeliveryAttemptsParams{CompanyID: companyNullUUID, TrackingID: "AWB-500"}
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-500"}).Return(ship, nil).Twice()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.AttemptPolicyKey}).Return(`{"max_attempts":2}`, nil).Twice()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.ScheduleProfileKey}).Return("", nil).Once()
+		repo.On("InsertShipmentChange", ctx, mock.MatchedBy(func(p db.InsertShipmentChangeParams) bool {
+			return p.TrackingID == "AWB-500"
+		})).Return(nil)
+
+		// First attempt: back to intransit with a later delivery
+		repo.On("CountDeliveryAttempts", ctx, key).Return(int64(0), nil).Once()
+		repo.On("RescheduleDelivery", ctx, mock.MatchedBy(func(p db.RescheduleDeliveryParams) bool {
+			return p.Status.String == "intransit" && p.Version == 5 && p.ExpectedDeliveryTime.Valid && p.ExpectedDeliveryTime.Time.After(p.OutfordeliveryTime.Time)
+		})).Return(mockResult{rows: 1}, nil).Once()
+		res, err := attemptUC.RecordFailedAttempt(ctx, testCompanyID, "AWB-500", " No_One_Home ", "gate locked", 5)
+		require.NoError(t, err)
+		assert.False(t, res.Final)
+		assert.Equal(t, "intransit", res.Status)
+		assert.Equal(t, int32(1), res.Attempt.AttemptNo)
+		assert.Equal(t, "no_one_home", res.Attempt.Reason)
+		assert.Equal(t, 2, res.MaxAttempts)
+		assert.True(t, res.Delivery.After(time.Now()))
+		assert.False(t, res.OutForDelivery.Before(ofd))
+
+		// Last allowed attempt: delivery_failed, schedule untouched
+		repo.On("CountDeliveryAttempts", ctx, key).Return(int64(1), nil).Once()
+		repo.On("RescheduleDelivery", ctx, mock.MatchedBy(func(p db.RescheduleDeliveryParams) bool {
+			return p.Status.String == "delivery_failed" && !p.OutfordeliveryTime.Valid && !p.ExpectedDeliveryTime.Valid
+		})).Return(mockResult{rows: 1}, nil).Once()
+		res, err = attemptUC.RecordFailedAttempt(ctx, testCompanyID, "AWB-500", "refused", "", 0)
+		require.NoError(t, err)
+		assert.True(t, res.Final)
+		assert.Equal(t, "delivery_failed", res.Status)
+		assert.Equal(t, int32(2), res.Attempt.AttemptNo)
+
+		_, err = attemptUC.RecordFailedAttempt(ctx, testCompanyID, "AWB-500", "dog", "", 0)
+		assert.ErrorIs(t, err, shipment.ErrInvalidAttempt)
+
+		// The log entry can't be written: the request fails before rescheduling
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-500"}).Return(ship, nil).Once()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.AttemptPolicyKey}).Return(`{"max_attempts":2}`, nil).Once()
+		repo.On("CountDeliveryAttempts", ctx, key).Return(int64(1), nil).Once()
+		repo.attemptErr = errors.New("connection reset")
+		defer func() { repo.attemptErr = nil }()
+		_, err = attemptUC.RecordFailedAttempt(ctx, testCompanyID, "AWB-500", "refused", "", 0)
+		assert.ErrorContains(t, err, "failed to record delivery attempt")
+		repo.AssertExpectations(t)
+	})
+	t.Run("RecordCheckpoint_LabelsAndThinsLiveUpdates", func(t *testing.T) {
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		lastKey := db.GetLastCheckpointParams{CompanyID: testCompanyID, TrackingID: "AWB-700"}
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-700"}).Return(db.Shipment{TrackingID: "AWB-700"}, nil).Twice()
+
+		// An admin pins a static location: labelled from the city list
+		repo.On("InsertCheckpoint", ctx, mock.MatchedBy(func(p db.InsertCheckpointParams) bool {
+			return p.TrackingID == "AWB-700" && p.Label == "Ikeja, Nigeria" && !p.Live && p.AccuracyM.Int32 == 12
+		})).Return(nil).Once()
+		cp, err := uc.RecordCheckpoint(ctx, testCompanyID, nil, "AWB-700", models.Checkpoint{Latitude: 6.6010, Longitude: 3.3550, AccuracyM: 12})
+		require.NoError(t, err)
+		assert.Equal(t, "Ikeja, Nigeria", cp.Label)
+
+		// A live update a minute after the last one is dropped
+		repo.On("GetLastCheckpoint", ctx, lastKey).Return(db.ShipmentCheckpoint{Live: true, CreatedAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}}, nil).Once()
+		cp, err = uc.RecordCheckpoint(ctx, testCompanyID, nil, "AWB-700", models.Checkpoint{Latitude: 6.6020, Longitude: 3.3560, Live: true})
+		require.NoError(t, err)
+		assert.Nil(t, cp)
+
+		// No fix: nothing is looked up
+		_, err = uc.RecordCheckpoint(ctx, testCompanyID, nil, "AWB-700", models.Checkpoint{})
+		assert.ErrorIs(t, err, shipment.ErrInvalidCheckpoint)
+		repo.AssertExpectations(t)
+	})
+	t.Run("RecordPOD_StoresImageAndMarksDelivered", func(t *testing.T) {
+		uc.Blobs = blob.NewLocalStorage(t.TempDir())
+		defer func() { uc.Blobs = nil }()
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		photo := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+
+		// Not an image: rejected before anything is looked up
+		_, err := uc.RecordPOD(ctx, testCompanyID, nil, "AWB-800", "", []byte("hello"))
+		assert.ErrorIs(t, err, shipment.ErrInvalidPOD)
+
+		// Out for delivery: the photo is stored and the shipment delivered
+		ship := db.Shipment{TrackingID: "AWB-800", Status: str("outfordelivery"), Destination: str("Lagos"), Version: 2}
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-800"}).Return(ship, nil).Times(3)
+		var key string
+		repo.On("InsertPOD", ctx, mock.MatchedBy(func(p db.InsertPODParams) bool {
+			key = p.BlobKey
+			return p.TrackingID == "AWB-800" && p.Kind == shipment.PODPhoto && p.ContentType == "image/png" && p.SizeBytes == int64(len(photo))
+		})).Return(nil).Once()
+		repo.On("UpdateShipmentStatus", ctx, db.UpdateShipmentStatusParams{
+			CompanyID:   companyNullUUID,
+			TrackingID:  "AWB-800",
+			Status:      str("delivered"),
+			Destination: str("Lagos"),
+			Version:     2,
+		}).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("InsertShipmentChange", ctx, mock.MatchedBy(func(p db.InsertShipmentChangeParams) bool {
+			return p.TrackingID == "AWB-800"
+		})).Return(nil)
+		res, err := uc.RecordPOD(ctx, testCompanyID, nil, "AWB-800", "", photo)
+		require.NoError(t, err)
+		assert.True(t, res.Delivered)
+		assert.Equal(t, "delivered", res.Shipment.Status.String)
+
+		repo.On("GetPOD", ctx, db.GetPODParams{CompanyID: testCompanyID, TrackingID: "AWB-800", ID: 1}).Return(db.ShipmentPod{ID: 1, TrackingID: "AWB-800", BlobKey: key, ContentType: "image/png"}, nil).Once()
+		_, r, err := uc.OpenPOD(ctx, testCompanyID, "AWB-800", 1)
+		require.NoError(t, err)
+		stored, _ := io.ReadAll(r)
+		r.Close()
+		assert.Equal(t, photo, stored)
+
+		// Already delivered: a signature is added without another transition
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-801"}).Return(db.Shipment{TrackingID: "AWB-801", Status: str("delivered")}, nil).Once()
+		repo.On("InsertPOD", ctx, mock.MatchedBy(func(p db.InsertPODParams) bool {
+			return p.TrackingID == "AWB-801" && p.Kind == shipment.PODSignature
+		})).Return(nil).Once()
+		res, err = uc.RecordPOD(ctx, testCompanyID, nil, "AWB-801", shipment.PODSignature, photo)
+		require.NoError(t, err)
+		assert.False(t, res.Delivered)
+
+		// Pending can't be delivered: nothing is stored
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-802"}).Return(db.Shipment{TrackingID: "AWB-802", Status: str("pending")}, nil).Once()
+		_, err = uc.RecordPOD(ctx, testCompanyID, nil, "AWB-802", "", photo)
+		var te *shipment.TransitionError
+		assert.ErrorAs(t, err, &te)
+
+		// Losing the race to deliver: the stored image is removed again
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-803"}).Return(db.Shipment{TrackingID: "AWB-803", Status: str("outfordelivery"), Version: 4}, nil).Times(3)
+		repo.On("InsertPOD", ctx, mock.MatchedBy(func(p db.InsertPODParams) bool {
+			key = p.BlobKey
+			return p.TrackingID == "AWB-803"
+		})).Return(nil).Once()
+		repo.On("UpdateShipmentStatus", ctx, mock.MatchedBy(func(p db.UpdateShipmentStatusParams) bool {
+			return p.TrackingID == "AWB-803"
+		})).Return(mockResult{rows: 0}, nil).Once()
+		_, err = uc.RecordPOD(ctx, testCompanyID, nil, "AWB-803", "", photo)
+		assert.ErrorIs(t, err, shipment.ErrVersionConflict)
+		_, err = uc.Blobs.Open(ctx, key)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		repo.AssertExpectations(t)
+	})
+	t.Run("PurgeTrash_RemovesPODImages", func(t *testing.T) {
+		uc.Blobs = blob.NewLocalStorage(t.TempDir())
+		defer func() { uc.Blobs = nil }()
+		key := testCompanyID.String() + "/pod/purged.png"
+		require.NoError(t, uc.Blobs.Put(ctx, key, []byte("image")))
+		cutoff := time.Now().Add(-30 * 24 * time.Hour)
+
+		repo.On("ListTrashedPODKeys", ctx, db.ListTrashedPODKeysParams{CompanyID: testCompanyID, DeletedAt: sql.NullTime{Time: cutoff, Valid: true}}).Return([]string{key}, nil).Once()
+		_, err := uc.PurgeTrash(ctx, testCompanyID, cutoff)
+		require.NoError(t, err)
+		_, err = uc.Blobs.Open(ctx, key)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		repo.AssertExpectations(t)
+	})
+	t.Run("RiderDeliver_OnlyOwnShipments", func(t *testing.T) {
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		rider := &models.Rider{ID: 7, Name: "Tunde", Phone: "+2348011111111", Active: true}
+		ship := db.Shipment{TrackingID: "AWB-600", Status: str("outfordelivery"), Destination: str("Lagos"), Version: 3}
+		assignKey := db.GetRiderAssignmentParams{CompanyID: testCompanyID, TrackingID: "AWB-600"}
+
+		// Someone else's shipment, then no assignment at all
+		repo.On("GetRiderAssignment", ctx, assignKey).Return(db.RiderAssignment{RiderID: 8}, nil).Once()
+		_, err := uc.RiderDeliver(ctx, testCompanyID, rider, "AWB-600", "")
+		assert.ErrorIs(t, err, shipment.ErrNotAssigned)
+		repo.On("GetRiderAssignment", ctx, assignKey).Return(db.RiderAssignment{}, sql.ErrNoRows).Once()
+		_, err = uc.RiderDeliver(ctx, testCompanyID, rider, "AWB-600", "")
+		assert.ErrorIs(t, err, shipment.ErrNotAssigned)
+
+		repo.On("GetRiderAssignment", ctx, assignKey).Return(db.RiderAssignment{RiderID: 7}, nil).Once()
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-600"}).Return(ship, nil).Twice()
+		repo.On("UpdateShipmentStatus", ctx, db.UpdateShipmentStatusParams{
+			CompanyID:   companyNullUUID,
+			TrackingID:  "AWB-600",
+			Status:      str("delivered"),
+			Destination: str("Lagos"),
+			Version:     3,
+		}).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("InsertShipmentChange", ctx, mock.MatchedBy(func(p db.InsertShipmentChangeParams) bool {
+			return p.TrackingID == "AWB-600"
+		})).Return(nil)
+		updated, err := uc.RiderDeliver(ctx, testCompanyID, rider, "AWB-600", "")
+		require.NoError(t, err)
+		assert.Equal(t, "delivered", updated.Status.String)
+		assert.Equal(t, int32(4), updated.Version)
+		repo.AssertExpectations(t)
+	})
+	t.Run("DeliveryOTP_RequiredForHighValue", func(t *testing.T) {
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		shipKey := db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-900"}
+		ship := db.Shipment{TrackingID: "AWB-900", Status: str("intransit"), Destination: str("Lagos"), CodAmount: 500, Version: 1}
+		otpUC := shipment.NewUsecase(repo, nil)
+		var sent string
+		otpUC.SendOTP = func(ctx context.Context, companyID uuid.UUID, s db.Shipment, code string) { sent = code }
+
+		// Going out for delivery issues a code, the policy threshold being met by the COD amount
+		repo.On("GetShipment", ctx, shipKey).Return(ship, nil).Once()
+		repo.On("GetSystemConfig", ctx, db.GetSystemConfigParams{CompanyID: testCompanyID, Key: shipment.AttemptPolicyKey}).Return(`{"max_attempts":3,"otp_min_value":250}`, nil).Once()
+		repo.On("UpdateShipmentStatus", ctx, mock.MatchedBy(func(p db.UpdateShipmentStatusParams) bool {
+			return p.TrackingID == "AWB-900" && p.Status.String == "outfordelivery"
+		})).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("InsertShipmentChange", ctx, mock.MatchedBy(func(p db.InsertShipmentChangeParams) bool {
+			return p.TrackingID == "AWB-900"
+		})).Return(nil)
+		require.NoError(t, otpUC.UpdateStatus(ctx, testCompanyID, "AWB-900", "outfordelivery", "Lagos", 0))
+		assert.Regexp(t, `^\d{6}$`, sent)
+		assert.NotEqual(t, sent, repo.otps["AWB-900"].CodeHash, "only the hash is stored")
+
+		// Without the code it can't be delivered, by status change or by rider
+		ship.Status, ship.Version = str("outfordelivery"), 2
+		repo.On("GetShipment", ctx, shipKey).Return(ship, nil).Once()
+		err := otpUC.UpdateStatus(ctx, testCompanyID, "AWB-900", "delivered", "Lagos", 0)
+		assert.ErrorIs(t, err, shipment.ErrOTPRequired)
+		_, err = otpUC.RiderDeliver(ctx, testCompanyID, nil, "AWB-900", "")
+		assert.ErrorIs(t, err, shipment.ErrOTPRequired)
+
+		// A wrong code is counted
+		wrong := "000000"
+		if sent == wrong {
+			wrong = "111111"
+		}
+		repo.On("GetShipment", ctx, shipKey).Return(ship, nil).Once()
+		_, err = otpUC.RiderDeliver(ctx, testCompanyID, nil, "AWB-900", wrong)
+		assert.ErrorIs(t, err, shipment.ErrWrongOTP)
+		assert.Equal(t, int32(1), repo.otps["AWB-900"].FailedAttempts)
+
+		// The right one delivers
+		repo.On("GetShipment", ctx, shipKey).Return(ship, nil).Twice()
+		repo.On("UpdateShipmentStatus", ctx, mock.MatchedBy(func(p db.UpdateShipmentStatusParams) bool {
+			return p.TrackingID == "AWB-900" && p.Status.String == "delivered" && p.Version == 2
+		})).Return(mockResult{rows: 1}, nil).Once()
+		updated, err := otpUC.RiderDeliver(ctx, testCompanyID, nil, "AWB-900", " "+sent+" ")
+		require.NoError(t, err)
+		assert.Equal(t, "delivered", updated.Status.String)
+		assert.True(t, repo.otps["AWB-900"].ConfirmedAt.Valid)
+		assert.Equal(t, int32(1), repo.otps["AWB-900"].FailedAttempts, "the right code gives its attempt back")
+
+		// Too many wrong codes lock the shipment
+		otp := repo.otps["AWB-900"]
+		otp.ConfirmedAt, otp.FailedAttempts = sql.NullTime{}, shipment.MaxOTPAttempts
+		repo.otps["AWB-900"] = otp
+		_, err = otpUC.RiderDeliver(ctx, testCompanyID, nil, "AWB-900", sent)
+		assert.ErrorIs(t, err, shipment.ErrOTPLocked)
+		repo.AssertExpectations(t)
+	})
+	t.Run("Consolidation_DepartCascadesToShipments", func(t *testing.T) {
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		a := db.Shipment{TrackingID: "AWB-701", Status: str("pending"), Destination: str("Accra"), Version: 1}
+		b := db.Shipment{TrackingID: "AWB-702", Status: str("pending"), Destination: str("Accra"), Version: 5}
+		done := db.Shipment{TrackingID: "AWB-703", Status: str("delivered"), Version: 2}
+
+		repo.On("NextTrackingSequence", ctx, db.NextTrackingSequenceParams{CompanyID: testCompanyID, Scope: "mawb"}).Return(int64(42), nil).Once()
+		bag, err := uc.CreateConsolidation(ctx, testCompanyID, "LG", " EK 783 ")
+		require.NoError(t, err)
+		assert.Equal(t, "LG-M000042", bag.MasterAWB)
+		assert.Equal(t, "EK 783", bag.Label)
+
+		// A finished shipment can't go in, and then nothing does
+		for _, s := range []db.Shipment{a, b, done} {
+			repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: s.TrackingID}).Return(s, nil).Once()
+		}
+		_, err = uc.AddToConsolidation(ctx, testCompanyID, "lg-m000042", []string{"AWB-701", "AWB-702", "AWB-703"})
+		assert.ErrorIs(t, err, shipment.ErrInvalidConsolidation)
+		assert.Empty(t, repo.bagged)
+
+		// Departing needs a closed bag, closing a non-empty one
+		_, err = uc.CloseConsolidation(ctx, testCompanyID, "LG-M000042")
+		assert.ErrorIs(t, err, shipment.ErrInvalidConsolidation)
+		for _, s := range []db.Shipment{a, b} {
+			repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: s.TrackingID}).Return(s, nil).Once()
+		}
+		bag, err = uc.AddToConsolidation(ctx, testCompanyID, "LG-M000042", []string{"AWB-701", "AWB-702"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"AWB-701", "AWB-702"}, bag.TrackingIDs)
+		err = uc.RemoveFromConsolidation(ctx, testCompanyID, "LG-M000042", "AWB-709")
+		assert.ErrorIs(t, err, shipment.ErrNotConsolidated)
+		_, err = uc.DepartConsolidation(ctx, testCompanyID, "LG-M000042")
+		assert.ErrorIs(t, err, shipment.ErrInvalidConsolidation)
+		bag, err = uc.CloseConsolidation(ctx, testCompanyID, "LG-M000042")
+		require.NoError(t, err)
+		assert.Equal(t, shipment.BagClosed, bag.State)
+		_, err = uc.AddToConsolidation(ctx, testCompanyID, "LG-M000042", []string{"AWB-704"})
+		assert.ErrorIs(t, err, shipment.ErrInvalidConsolidation)
+
+		// Departing moves every shipment through UpdateStatus with its own
+		// version; one that was changed meanwhile is skipped, not fatal
+		for _, s := range []db.Shipment{a, b} {
+			repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: s.TrackingID}).Return(s, nil).Twice()
+		}
+		repo.On("UpdateShipmentStatus", ctx, db.UpdateShipmentStatusParams{
+			CompanyID: companyNullUUID, TrackingID: "AWB-701", Status: str("intransit"), Destination: str("Accra"), Version: 1,
+		}).Return(mockResult{rows: 1}, nil).Once()
+		repo.On("UpdateShipmentStatus", ctx, db.UpdateShipmentStatusParams{
+			CompanyID: companyNullUUID, TrackingID: "AWB-702", Status: str("intransit"), Destination: str("Accra"), Version: 5,
+		}).Return(mockResult{rows: 0}, nil).Once()
+		repo.On("InsertShipmentChange", ctx, mock.MatchedBy(func(p db.InsertShipmentChangeParams) bool {
+			return p.TrackingID == "AWB-701"
+		})).Return(nil)
+		res, err := uc.DepartConsolidation(ctx, testCompanyID, "LG-M000042")
+		require.NoError(t, err)
+		require.Len(t, res.Moved, 1)
+		assert.Equal(t, "AWB-701", res.Moved[0].TrackingID)
+		assert.Equal(t, "intransit", res.Moved[0].Status.String)
+		require.Len(t, res.Skipped, 1)
+		assert.Equal(t, "AWB-702", res.Skipped[0].TrackingID)
+		assert.Equal(t, "changed meanwhile", res.Skipped[0].Reason)
+		assert.Equal(t, shipment.BagDeparted, res.Consolidation.State)
+		assert.Equal(t, "intransit", repo.bags["LG-M000042"].Status.String)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("PublicTimeline_HidesFreeText", func(t *testing.T) {
+		companyNullUUID := uuid.NullUUID{UUID: testCompanyID, Valid: true}
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		ship := db.Shipment{CompanyID: companyNullUUID, TrackingID: "AWB-801", Status: str("returned"), Version: 4}
+		repo.public = map[string]db.Shipment{"AWB-801": ship}
+		for _, e := range []db.InsertShipmentEventParams{
+			{TrackingID: "AWB-801", Status: "outfordelivery", PreviousStatus: str("intransit")},
+			{TrackingID: "AWB-801", Status: "outfordelivery", PreviousStatus: str("outfordelivery"), Description: str("Wrong delivery code entered at the gate by the neighbour")},
+			{TrackingID: "AWB-801", Status: "delivery_failed", PreviousStatus: str("outfordelivery"), Description: str("Delivery attempt 1 of 3 failed: dog in the yard, call 912345678")},
+			{TrackingID: "AWB-801", Status: "returned", PreviousStatus: str("delivery_failed"), Description: str("Returned to sender as RET-1: refused, owes money")},
+		} {
+			e.CompanyID = companyNullUUID
+			repo.events = append(repo.events, e)
+		}
+
+		timeline, err := uc.PublicTimeline(ctx, "AWB-801")
+		require.NoError(t, err)
+		require.Len(t, timeline, 3)
+		for _, e := range timeline {
+			assert.Equal(t, shipment.DescribeStatus(e.Status), e.Description)
+		}
+		res, err := uc.PublicTrack(ctx, "AWB-801", "")
+		require.NoError(t, err)
+		raw, err := json.Marshal(res)
+		require.NoError(t, err)
+		for _, text := range []string{"neighbour", "dog in the yard", "912345678", "refused", "RET-1"} {
+			assert.NotContains(t, string(raw), text)
+		}
+
+		// Staff still see the recorded wording
+		repo.On("GetShipment", ctx, db.GetShipmentParams{CompanyID: companyNullUUID, TrackingID: "AWB-801"}).Return(ship, nil).Once()
+		admin, err := uc.Timeline(ctx, testCompanyID, "AWB-801")
+		require.NoError(t, err)
+		require.Len(t, admin, 4)
+		assert.Contains(t, admin[3].Description, "refused")
+	})
+
+	t.Run("PublicTrack_LeaksNoVerifyDigits", func(t *testing.T) {
+		str := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+		stamp := sql.NullTime{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}
+		phone := "+351 912 345 678"
+		repo.public = map[string]db.Shipment{"AWB-802": {
+			CompanyID: uuid.NullUUID{UUID: testCompanyID, Valid: true}, TrackingID: "AWB-802", Status: str("pending"),
+			RecipientName: str("Maria Santos"), RecipientPhone: str(phone), RecipientAddress: str("Rua Nova 5678, Lisboa"),
+			CreatedAt: stamp, ExpectedDeliveryTime: stamp, Version: 1,
+		}}
+
+		res, err := uc.PublicTrack(ctx, "AWB-802", "")
+		require.NoError(t, err)
+		require.False(t, res.Verified)
+		raw, err := json.Marshal(res)
+		require.NoError(t, err)
+		// No four digits anywhere in the response may unlock the details
+		for _, run := range regexp.MustCompile(`\d{4,}`).FindAllString(string(raw), -1) {
+			for i := 0; i+shipment.PhoneVerifyDigits <= len(run); i++ {
+				window := run[i : i+shipment.PhoneVerifyDigits]
+				assert.False(t, shipment.VerifyPhone(phone, window), "response leaks %q", window)
+			}
+		}
+
+		_, err = uc.PublicTrack(ctx, "AWB-802", "5678")
+		assert.NoError(t, err)
+	})
 }
 
 func TestConfigUsecase_Deep(t *testing.T) {
@@ -72,9 +72,10 @@ export class ShipmentService {
                 status: normalizeStatus(statusStr) as ShipmentStatus,
                 senderName: redactName(data.sender_name),
                 receiverName: redactName(data.recipient_name),
-                receiverPhone: typeof data.recipient_phone === 'string' ? data.recipient_phone : null,
-                receiverEmail: typeof data.recipient_email === 'string' ? data.recipient_email : null,
-                receiverAddress: typeof data.recipient_address === 'string' ? data.recipient_address : null,
+                // get_public_shipment returns no contact details
+                receiverPhone: null,
+                receiverEmail: null,
+                receiverAddress: null,
                 receiverCountry: timelineStr(data.destination) || 'N/A',
                 weight: typeof data.weight === 'number' ? data.weight : (typeof data.weight === 'string' ? parseFloat(data.weight) : 0),
                 senderCountry: timelineStr(data.origin) || 'N/A',